@@ -0,0 +1,93 @@
+package taguchi
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Role is the access level granted by a token (see TokenAuth).
+type Role int
+
+const (
+	// RoleNone grants no access.
+	RoleNone Role = iota
+	// RoleReader may watch progress and browse analyses (GET and HEAD requests only).
+	RoleReader
+	// RoleWriter may additionally submit or modify results.
+	RoleWriter
+)
+
+// String returns the role name.
+func (r Role) String() string {
+	switch r {
+	case RoleReader:
+		return "reader"
+	case RoleWriter:
+		return "writer"
+	default:
+		return "none"
+	}
+}
+
+type roleContextKey struct{}
+
+// RoleFromContext returns the role TokenAuth granted to the request, so
+// handlers can hide controls a reader cannot use. It is RoleNone outside TokenAuth.
+func RoleFromContext(ctx context.Context) Role {
+	role, _ := ctx.Value(roleContextKey{}).(Role)
+	return role
+}
+
+// TokenAuth guards an HTTP handler such as the Progress dashboard with
+// bearer tokens, so stakeholders can be handed a read-only token.
+// Tokens: Role granted by each token.
+// Handler: The guarded handler.
+//
+// The token is taken from the "Authorization: Bearer" header or, for links
+// opened in a browser, the token query parameter. Requests without a known
+// token get 401 Unauthorized; readers get 403 Forbidden for any method other
+// than GET and HEAD.
+type TokenAuth struct {
+	Tokens  map[string]Role
+	Handler http.Handler
+}
+
+// ServeHTTP checks the request's token and forwards permitted requests with
+// the granted role in their context.
+func (a TokenAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	role := a.role(requestToken(r))
+	switch {
+	case role == RoleNone:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="taguchi"`)
+		http.Error(w, "missing or unknown token", http.StatusUnauthorized)
+		return
+	case role < RoleWriter && r.Method != http.MethodGet && r.Method != http.MethodHead:
+		http.Error(w, "read-only token", http.StatusForbidden)
+		return
+	}
+	a.Handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleContextKey{}, role)))
+}
+
+// role looks token up in constant time per configured token.
+func (a TokenAuth) role(token string) Role {
+	if token == "" {
+		return RoleNone
+	}
+	granted := RoleNone
+	for t, role := range a.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			granted = role
+		}
+	}
+	return granted
+}
+
+// requestToken returns the bearer token of r, or its token query parameter.
+func requestToken(r *http.Request) string {
+	if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(auth)
+	}
+	return r.URL.Query().Get("token")
+}
@@ -0,0 +1,41 @@
+package taguchi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenAuth(t *testing.T) {
+	var seen Role
+	auth := TokenAuth{
+		Tokens: map[string]Role{"view": RoleReader, "admin": RoleWriter},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = RoleFromContext(r.Context())
+		}),
+	}
+	tests := []struct {
+		method, target, bearer string
+		wantCode               int
+		wantRole               Role
+	}{
+		{"GET", "/", "", http.StatusUnauthorized, RoleNone},
+		{"GET", "/", "wrong", http.StatusUnauthorized, RoleNone},
+		{"GET", "/", "view", http.StatusOK, RoleReader},
+		{"GET", "/?token=view", "", http.StatusOK, RoleReader},
+		{"POST", "/", "view", http.StatusForbidden, RoleNone},
+		{"POST", "/", "admin", http.StatusOK, RoleWriter},
+	}
+	for _, tt := range tests {
+		seen = RoleNone
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		if tt.bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.bearer)
+		}
+		rec := httptest.NewRecorder()
+		auth.ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode || seen != tt.wantRole {
+			t.Errorf("%s %s with token %q: status %d, role %v; want %d, %v", tt.method, tt.target, tt.bearer, rec.Code, seen, tt.wantCode, tt.wantRole)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package taguchi
+
+import "slices"
+
+// aliasTolerance is the share below which an interaction counts as clear of a main effect.
+const aliasTolerance = 1e-9
+
+// Alias describes what the interaction of two control factors is confounded
+// with under the experiment's array and column assignment.
+// A, B: The interacting factors.
+// Columns: Array columns (zero-based) carrying the interaction; nil when it is spread over the other columns, as in L12.
+// Factors: Factors whose main effects are aliased with the interaction, in factor order.
+// Shares: Fraction of each aliased factor's main-effect variation that lies in the interaction, in the order of
+// Factors; 1 means the factor's effect cannot be told apart from the interaction at all.
+// Interactions: Other two-factor interactions aliased with this one, named as by InteractionName.
+// Declared: Whether the interaction was declared with AddInteraction.
+type Alias struct {
+	A            string
+	B            string
+	Columns      []int
+	Factors      []string
+	Shares       []float64
+	Interactions []string
+	Declared     bool
+}
+
+// Clear reports whether the interaction is free of every main effect, so it
+// cannot bias a main effect estimate.
+func (a Alias) Clear() bool {
+	return len(a.Factors) == 0
+}
+
+// DesignDiagnostics describes what the design can and cannot resolve.
+// Resolution: 3 when some two-factor interaction is aliased with a main effect, 4 when main effects are clear
+// but interactions are aliased with each other, and 5 when all main effects and two-factor interactions are
+// separable.
+// Aliases: Aliasing of every pair of control factors, in factor order.
+// FreeColumns: Array columns (zero-based) carrying neither a factor nor a declared interaction; they estimate error.
+type DesignDiagnostics struct {
+	Resolution  int
+	Aliases     []Alias
+	FreeColumns []int
+}
+
+// DesignDiagnostics reports which two-factor interactions are aliased with
+// which main effects for the experiment's array and factor-to-column
+// assignment. In a saturated L9, for example, the interaction of the factors
+// in columns 1 and 2 lies entirely in columns 3 and 4, so it is
+// indistinguishable from the factors assigned there; in L12 every
+// interaction is spread thinly over all other columns. Interactions that are
+// not clear bias the main effects they are aliased with unless they are
+// negligible, so assign factors likely to interact with AddInteraction or
+// pick a larger array. The shares assume an orthogonal array.
+func (e *Experiment[P]) DesignDiagnostics() DesignDiagnostics {
+	n := len(e.OrthogonalArray)
+	levels := make([][]int, len(e.ControlFactors))
+	for j := range e.ControlFactors {
+		levels[j] = make([]int, n)
+		for i := range e.OrthogonalArray {
+			levels[j][i] = e.levelIndex(i, j)
+		}
+	}
+	declared := map[[2]string]bool{}
+	for _, in := range e.Interactions {
+		declared[[2]string{in.A, in.B}] = true
+		declared[[2]string{in.B, in.A}] = true
+	}
+
+	d := DesignDiagnostics{Resolution: 5}
+	var pairs [][2]int
+	for a := range e.ControlFactors {
+		for b := a + 1; b < len(e.ControlFactors); b++ {
+			pairs = append(pairs, [2]int{a, b})
+		}
+	}
+	for _, p := range pairs {
+		a, b := p[0], p[1]
+		alias := Alias{
+			A:        e.ControlFactors[a].Name,
+			B:        e.ControlFactors[b].Name,
+			Columns:  InteractionColumns(e.OrthogonalArray, e.column(a), e.column(b)),
+			Declared: declared[[2]string{e.ControlFactors[a].Name, e.ControlFactors[b].Name}],
+		}
+		for k, factor := range e.ControlFactors {
+			if k == a || k == b {
+				continue
+			}
+			if share := interactionShare(levels[a], levels[b], levels[k]); share > aliasTolerance {
+				alias.Factors = append(alias.Factors, factor.Name)
+				alias.Shares = append(alias.Shares, share)
+			}
+		}
+		for _, q := range pairs {
+			if q != p && interactionsAliased(levels[a], levels[b], levels[q[0]], levels[q[1]]) {
+				alias.Interactions = append(alias.Interactions, InteractionName(e.ControlFactors[q[0]].Name, e.ControlFactors[q[1]].Name))
+			}
+		}
+		switch {
+		case !alias.Clear():
+			d.Resolution = 3
+		case len(alias.Interactions) > 0:
+			d.Resolution = min(d.Resolution, 4)
+		}
+		d.Aliases = append(d.Aliases, alias)
+	}
+
+	used := map[int]bool{}
+	for j := range e.ControlFactors {
+		used[e.column(j)] = true
+	}
+	for _, in := range e.Interactions {
+		for _, c := range in.Columns {
+			used[c] = true
+		}
+	}
+	if n > 0 {
+		for c := range e.OrthogonalArray[0] {
+			if !used[c] {
+				d.FreeColumns = append(d.FreeColumns, c)
+			}
+		}
+	}
+	return d
+}
+
+// interactionPart projects x onto the interaction space of the factors with
+// row levels a and b: the cell means of x less the additive main effects. The
+// projection is exact when the level pairs of a and b are balanced.
+func interactionPart(a, b []int, x []float64) []float64 {
+	type cell = [2]int
+	sum := func(key func(i int) cell) map[cell]float64 {
+		means, counts := map[cell]float64{}, map[cell]float64{}
+		for i, v := range x {
+			means[key(i)] += v
+			counts[key(i)]++
+		}
+		for k := range means {
+			means[k] /= counts[k]
+		}
+		return means
+	}
+	cells := sum(func(i int) cell { return cell{a[i], b[i]} })
+	rows := sum(func(i int) cell { return cell{a[i], -1} })
+	cols := sum(func(i int) cell { return cell{-1, b[i]} })
+	grand := sum(func(int) cell { return cell{} })[cell{}]
+
+	part := make([]float64, len(x))
+	for i := range x {
+		part[i] = cells[cell{a[i], b[i]}] - rows[cell{a[i], -1}] - cols[cell{-1, b[i]}] + grand
+	}
+	return part
+}
+
+// interactionShare returns the fraction of the main-effect variation of the
+// factor with row levels k that lies in the interaction space of a and b: the
+// mean squared canonical correlation between the two spaces for a balanced k.
+func interactionShare(a, b, k []int) float64 {
+	total, inside := 0.0, 0.0
+	for _, l := range distinct(k) {
+		x := centeredIndicator(k, l)
+		total += dot(x, x)
+		p := interactionPart(a, b, x)
+		inside += dot(p, p)
+	}
+	if total == 0 {
+		return 0
+	}
+	return inside / total
+}
+
+// interactionsAliased reports whether the interaction spaces of (a, b) and
+// (c, d) are not orthogonal.
+func interactionsAliased(a, b, c, d []int) bool {
+	for _, ca := range distinct(c) {
+		for _, db := range distinct(d) {
+			cell := make([]float64, len(c))
+			for i := range c {
+				if c[i] == ca && d[i] == db {
+					cell[i] = 1
+				}
+			}
+			u := interactionPart(c, d, cell)
+			v := interactionPart(a, b, u)
+			if dot(v, v) > aliasTolerance*max(dot(u, u), 1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// centeredIndicator returns the indicator of level l in k, less its mean.
+func centeredIndicator(k []int, l int) []float64 {
+	x := make([]float64, len(k))
+	count := 0.0
+	for i, v := range k {
+		if v == l {
+			x[i] = 1
+			count++
+		}
+	}
+	mean := count / float64(len(k))
+	for i := range x {
+		x[i] -= mean
+	}
+	return x
+}
+
+// distinct returns the distinct values of levels in ascending order.
+func distinct(levels []int) []int {
+	values := slices.Clone(levels)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
@@ -0,0 +1,102 @@
+package taguchi
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func aliasOf(t *testing.T, d DesignDiagnostics, a, b string) Alias {
+	t.Helper()
+	for _, alias := range d.Aliases {
+		if alias.A == a && alias.B == b {
+			return alias
+		}
+	}
+	t.Fatalf("no alias entry for %s", InteractionName(a, b))
+	return Alias{}
+}
+
+func TestDesignDiagnostics(t *testing.T) {
+	two := []float64{1, 2}
+	three := []float64{1, 2, 3}
+
+	t.Run("saturated L9", func(t *testing.T) {
+		factors := []ControlFactor{{Name: "A", Levels: three}, {Name: "B", Levels: three}, {Name: "C", Levels: three}, {Name: "D", Levels: three}}
+		exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, nil)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactors: %v", err)
+		}
+		d := exp.DesignDiagnostics()
+		if d.Resolution != 3 {
+			t.Errorf("Resolution = %d, want 3", d.Resolution)
+		}
+		ab := aliasOf(t, d, "A", "B")
+		if !slices.Equal(ab.Columns, []int{2, 3}) || !slices.Equal(ab.Factors, []string{"C", "D"}) {
+			t.Errorf("A×B: columns %v, factors %v; want columns [2 3] aliased with C and D", ab.Columns, ab.Factors)
+		}
+		for i, s := range ab.Shares {
+			if !almostEqual(s, 1) {
+				t.Errorf("A×B share of %s = %.4f, want 1", ab.Factors[i], s)
+			}
+		}
+
+		var sb strings.Builder
+		if err := exp.WriteDesignReport(&sb); err != nil {
+			t.Fatalf("WriteDesignReport: %v", err)
+		}
+		if !strings.Contains(sb.String(), "A×B: aliased with C 100%, D 100%") {
+			t.Errorf("design report does not show the aliasing:\n%s", sb.String())
+		}
+	})
+
+	t.Run("full factorial in L8", func(t *testing.T) {
+		factors := []ControlFactor{{Name: "A", Levels: two}, {Name: "B", Levels: two}, {Name: "C", Levels: two}}
+		exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactors: %v", err)
+		}
+		if err := exp.SetColumns([]int{0, 1, 3}); err != nil {
+			t.Fatalf("SetColumns: %v", err)
+		}
+		d := exp.DesignDiagnostics()
+		if d.Resolution != 5 {
+			t.Errorf("Resolution = %d, want 5", d.Resolution)
+		}
+		for _, a := range d.Aliases {
+			if !a.Clear() || len(a.Interactions) > 0 {
+				t.Errorf("%s aliased with %v and %v", InteractionName(a.A, a.B), a.Factors, a.Interactions)
+			}
+		}
+		if !slices.Equal(d.FreeColumns, []int{2, 4, 5, 6}) {
+			t.Errorf("FreeColumns = %v, want [2 4 5 6]", d.FreeColumns)
+		}
+	})
+
+	t.Run("L12 spreads interactions", func(t *testing.T) {
+		factors := []ControlFactor{{Name: "A", Levels: two}, {Name: "B", Levels: two}, {Name: "C", Levels: two}}
+		exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L12, nil)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactors: %v", err)
+		}
+		ab := aliasOf(t, exp.DesignDiagnostics(), "A", "B")
+		if ab.Columns != nil || len(ab.Shares) != 1 || ab.Shares[0] <= 0 || ab.Shares[0] >= 1 {
+			t.Errorf("A×B: columns %v, shares %v; want a partial alias with C", ab.Columns, ab.Shares)
+		}
+	})
+
+	t.Run("L18 columns 1 and 2", func(t *testing.T) {
+		factors := []ControlFactor{{Name: "A", Levels: two}, {Name: "B", Levels: three}, {Name: "C", Levels: three}, {Name: "D", Levels: three}}
+		exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L18, nil)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactors: %v", err)
+		}
+		d := exp.DesignDiagnostics()
+		if ab := aliasOf(t, d, "A", "B"); !ab.Clear() {
+			t.Errorf("A×B aliased with %v, want clear", ab.Factors)
+		}
+		if bc := aliasOf(t, d, "B", "C"); bc.Clear() {
+			t.Error("B×C reported clear, want partial aliasing")
+		}
+	})
+}
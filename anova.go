@@ -1,21 +1,38 @@
 package taguchi
 
+import (
+	"math"
+	"sort"
+)
+
 // computeANOVA calculates ANOVA statistics for all factors and returns:
 // - ANOVAResult
 // - mainEffects per factor
 // - SNR per factor (same as mainEffects for convenience)
-func (e *Experiment[P]) computeANOVA(oaSNR []float64, grandMean float64) (ANOVAResult, map[string][]float64, map[string][]float64) {
+//
+// Only rows marked as included take part in the sums of squares. Declared
+// interactions are reported under their InteractionName.
+func (e *Experiment[P]) computeANOVA(rows oaRowSNR) (ANOVAResult, map[string][]float64, map[string][]float64) {
 	oaRows := len(e.OrthogonalArray)
+	oaSNR, grandMean := rows.values, rows.grandMean
 	totalSS := 0.0
-	for _, sn := range oaSNR {
+	includedRows := 0
+	for i, sn := range oaSNR {
+		if !rows.included[i] {
+			continue
+		}
 		totalSS += (sn - grandMean) * (sn - grandMean)
+		includedRows++
 	}
 
 	anova := ANOVAResult{
-		FactorSS: make(map[string]float64),
-		FactorDF: make(map[string]int),
-		FactorMS: make(map[string]float64),
-		FactorF:  make(map[string]float64),
+		FactorSS:    make(map[string]float64),
+		FactorDF:    make(map[string]int),
+		FactorMS:    make(map[string]float64),
+		FactorF:     make(map[string]float64),
+		FactorP:     make(map[string]float64),
+		Significant: make(map[string]bool),
+		Alpha:       e.alpha(),
 	}
 
 	mainEffects := map[string][]float64{}
@@ -26,10 +43,13 @@ func (e *Experiment[P]) computeANOVA(oaSNR []float64, grandMean float64) (ANOVAR
 		levelCounts := make([]int, len(factor.Levels))
 
 		for i := 0; i < oaRows; i++ {
+			if !rows.included[i] {
+				continue
+			}
 			levelIdx := -1
 			for j, f := range e.ControlFactors {
 				if f.Name == factor.Name {
-					levelIdx = e.OrthogonalArray[i][j] - 1
+					levelIdx = e.levelIndex(i, j)
 					break
 				}
 			}
@@ -58,20 +78,77 @@ func (e *Experiment[P]) computeANOVA(oaSNR []float64, grandMean float64) (ANOVAR
 		snrPerFactor[factor.Name] = levelMeans
 	}
 
-	// Calculate error SS, DF, MS
-	errorDF := oaRows - 1
+	// Declared interactions enter the table next to the main effects.
+	for _, in := range e.Interactions {
+		ss, df := e.interactionSS(rows, in, anova)
+		anova.FactorSS[in.Name()] = ss
+		anova.FactorDF[in.Name()] = df
+	}
+
+	// Calculate error SS, DF, MS. Imputed rows carry no information.
+	errorDF := includedRows - 1
+	for _, missing := range rows.missing {
+		if missing {
+			errorDF--
+		}
+	}
 	for _, df := range anova.FactorDF {
 		errorDF -= df
 	}
-	if errorDF < 1 {
-		errorDF = 1
-	}
 
 	errorSS := totalSS
 	for _, ss := range anova.FactorSS {
 		errorSS -= ss
 	}
-	errorMS := errorSS / float64(errorDF)
+
+	// Columns reserved for error estimate it directly; the variation in the
+	// other free columns, e.g. from undeclared interactions, stays out of it.
+	if len(e.ErrorColumns) > 0 {
+		colSS, colDF := 0.0, 0
+		for _, c := range e.ErrorColumns {
+			ss, df := e.columnSS(rows, c)
+			colSS += ss
+			colDF += df
+		}
+		anova.ColumnErrorSS, anova.ColumnErrorDF = colSS, colDF
+		anova.UnassignedSS = max(errorSS-colSS, 0)
+		anova.UnassignedDF = max(errorDF-colDF, 0)
+		for _, missing := range rows.missing {
+			if missing {
+				colDF--
+			}
+		}
+		errorSS, errorDF = colSS, colDF
+	}
+
+	// Replicated rows add pure error, so saturated designs still get a
+	// genuine error term; the residual becomes the lack of fit.
+	if pureSS, pureDF := rows.pureError(); pureDF > 0 {
+		anova.PureErrorSS, anova.PureErrorDF = pureSS, pureDF
+		anova.PureErrorMS = pureSS / float64(pureDF)
+		lackDF := max(errorDF, 0)
+		if lackDF > 0 && anova.PureErrorMS > 0 {
+			anova.LackOfFitF = errorSS / float64(lackDF) / anova.PureErrorMS
+			anova.LackOfFitP = fSurvival(anova.LackOfFitF, lackDF, pureDF)
+		}
+		if lackDF == 0 {
+			errorSS = 0
+		}
+		errorSS += pureSS
+		errorDF = lackDF + pureDF
+	}
+	// A saturated design leaves no degrees of freedom to estimate the error:
+	// F-ratios and p-values are undefined and no factor is significant.
+	// Replicated trials, reserved error columns or a larger array give them
+	// back.
+	saturated := errorDF < 1
+	if saturated {
+		errorDF, errorSS = 0, 0
+	}
+	errorMS := 0.0
+	if !saturated {
+		errorMS = errorSS / float64(errorDF)
+	}
 	anova.ErrorDF = errorDF
 	anova.ErrorSS = errorSS
 	anova.ErrorMS = errorMS
@@ -81,28 +158,140 @@ func (e *Experiment[P]) computeANOVA(oaSNR []float64, grandMean float64) (ANOVAR
 		df := anova.FactorDF[f]
 		ms := ss / float64(df)
 		anova.FactorMS[f] = ms
+		if saturated {
+			anova.FactorF[f], anova.FactorP[f] = math.NaN(), math.NaN()
+			continue
+		}
 		anova.FactorF[f] = ms / errorMS
+		anova.FactorP[f] = fSurvival(anova.FactorF[f], df, errorDF)
+		anova.Significant[f] = anova.FactorP[f] < anova.Alpha
 	}
 
 	return anova, mainEffects, snrPerFactor
 }
 
+// columnSS returns the sum of squares and degrees of freedom of the level
+// means of array column c over the included rows.
+func (e *Experiment[P]) columnSS(rows oaRowSNR, c int) (float64, int) {
+	sums, counts := map[int]float64{}, map[int]int{}
+	for i, row := range e.OrthogonalArray {
+		if rows.included[i] {
+			sums[row[c]] += rows.values[i]
+			counts[row[c]]++
+		}
+	}
+	levels := make([]int, 0, len(counts))
+	for level := range counts {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	ss := 0.0
+	for _, level := range levels {
+		mean := sums[level] / float64(counts[level])
+		ss += float64(counts[level]) * (mean - rows.grandMean) * (mean - rows.grandMean)
+	}
+	return ss, max(len(counts)-1, 0)
+}
+
+// DefaultAlpha is the significance level used when Experiment.Alpha is not set.
+const DefaultAlpha = 0.05
+
+// alpha returns the configured significance level or DefaultAlpha.
+func (e *Experiment[P]) alpha() float64 {
+	if e.Alpha > 0 {
+		return e.Alpha
+	}
+	return DefaultAlpha
+}
+
 // computeContributions calculates the percentage contribution of each factor
-// based on the ratio of its sum of squares to the total factor sum of squares.
-func computeContributions(anova ANOVAResult) map[string]float64 {
-	totalFactorSS := 0.0
+// and of the error to the total sum of squares, so that a design leaving much
+// of the variation unexplained does not appear to explain all of it. A
+// negative error SS from rounding counts as zero. The total includes the
+// variation left out of the error by ErrorColumns.
+func computeContributions(anova ANOVAResult) (map[string]float64, float64) {
+	errorSS := max(anova.ErrorSS, 0)
+	totalSS := errorSS + anova.UnassignedSS
 	for _, ss := range anova.FactorSS {
-		totalFactorSS += ss
+		totalSS += ss
 	}
 	contributions := map[string]float64{}
-	if totalFactorSS > 0 {
-		for f, ss := range anova.FactorSS {
-			contributions[f] = (ss / totalFactorSS) * 100
-		}
-	} else {
+	if totalSS <= 0 {
 		for f := range anova.FactorSS {
 			contributions[f] = 0
 		}
+		return contributions, 0
+	}
+	for f, ss := range anova.FactorSS {
+		contributions[f] = (ss / totalSS) * 100
+	}
+	return contributions, (errorSS / totalSS) * 100
+}
+
+// CostBenefit is the benefit-vs-cost entry of a factor: how much of the
+// variation changing it in production addresses per unit of engineering cost.
+// Factor: Name of the control factor.
+// Contribution: Percentage contribution of the factor (see AnalysisResult.Contributions).
+// Cost: The factor's ChangeCost.
+// PerUnitCost: Contribution divided by Cost.
+// Significant: Whether the factor's effect is significant in the ANOVA.
+type CostBenefit struct {
+	Factor       string
+	Contribution float64
+	Cost         float64
+	PerUnitCost  float64
+	Significant  bool
+}
+
+// computeCostBenefit ranks the factors with a ChangeCost by contribution per
+// unit cost, highest first. Observe-only factors cannot be changed in
+// production and are left out. It returns nil when no factor has a cost.
+func computeCostBenefit(factors []ControlFactor, contributions map[string]float64, anova ANOVAResult) []CostBenefit {
+	var ranking []CostBenefit
+	for _, f := range factors {
+		if f.ChangeCost <= 0 || f.ObserveOnly {
+			continue
+		}
+		c := contributions[f.Name]
+		ranking = append(ranking, CostBenefit{
+			Factor:       f.Name,
+			Contribution: c,
+			Cost:         f.ChangeCost,
+			PerUnitCost:  c / f.ChangeCost,
+			Significant:  anova.Significant[f.Name],
+		})
+	}
+	sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].PerUnitCost > ranking[j].PerUnitCost })
+	return ranking
+}
+
+// UngroupedFactors is the group label under which factors without an explicit
+// Group are aggregated once at least one factor in the experiment is grouped.
+const UngroupedFactors = "(ungrouped)"
+
+// computeGroupContributions aggregates factor contributions by the factors'
+// Group labels. It returns nil maps when no factor carries a group.
+func computeGroupContributions(factors []ControlFactor, contributions map[string]float64) (map[string][]string, map[string]float64) {
+	grouped := false
+	for _, f := range factors {
+		if f.Group != "" {
+			grouped = true
+			break
+		}
+	}
+	if !grouped {
+		return nil, nil
+	}
+
+	groups := map[string][]string{}
+	groupContributions := map[string]float64{}
+	for _, f := range factors {
+		group := f.Group
+		if group == "" {
+			group = UngroupedFactors
+		}
+		groups[group] = append(groups[group], f.Name)
+		groupContributions[group] += contributions[f.Name]
 	}
-	return contributions
+	return groups, groupContributions
 }
@@ -0,0 +1,107 @@
+package taguchi
+
+import "fmt"
+
+// ArrayOptions controls the checks applied to user-supplied arrays by
+// NewExperimentUsingArray and NewExperimentFromFactorsUsingArray.
+// AllowNonOrthogonal: Skip the level and pairwise balance checks, for intentionally non-orthogonal
+// designs such as supersaturated or augmented arrays. The array must still be well-formed.
+type ArrayOptions struct {
+	AllowNonOrthogonal bool
+}
+
+// ValidateArray checks that oa is a well-formed orthogonal array: every row
+// has the same number of columns, each column uses the levels 1..n without
+// gaps, every level occurs equally often within its column, and every pair of
+// columns contains each level combination equally often. The error names the
+// first offending row, column or level combination.
+func ValidateArray(oa [][]int) error {
+	if err := validateArrayShape(oa); err != nil {
+		return err
+	}
+	info := DescribeArray(oa)
+	runs := len(oa)
+	for j, levels := range info.Levels {
+		counts := make([]int, levels)
+		for _, row := range oa {
+			counts[row[j]-1]++
+		}
+		if runs%levels != 0 {
+			return fmt.Errorf("column %d: %d runs cannot be split evenly over %d levels", j+1, runs, levels)
+		}
+		for l, n := range counts {
+			if want := runs / levels; n != want {
+				return fmt.Errorf("column %d: level %d occurs %d times, want %d", j+1, l+1, n, want)
+			}
+		}
+	}
+	for a := range info.Levels {
+		for b := a + 1; b < len(info.Levels); b++ {
+			la, lb := info.Levels[a], info.Levels[b]
+			if runs%(la*lb) != 0 {
+				return fmt.Errorf("columns %d and %d: %d runs cannot balance %d×%d level combinations", a+1, b+1, runs, la, lb)
+			}
+			counts := make([]int, la*lb)
+			for _, row := range oa {
+				counts[(row[a]-1)*lb+row[b]-1]++
+			}
+			for k, n := range counts {
+				if want := runs / (la * lb); n != want {
+					return fmt.Errorf("columns %d and %d: levels (%d, %d) occur together %d times, want %d", a+1, b+1, k/lb+1, k%lb+1, n, want)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateArrayShape checks the structure every array must have, orthogonal
+// or not: at least one row and column, rows of equal length and 1-based
+// levels with no unused level below a column's highest.
+func validateArrayShape(oa [][]int) error {
+	if len(oa) == 0 {
+		return fmt.Errorf("must not be empty")
+	}
+	cols := len(oa[0])
+	if cols == 0 {
+		return fmt.Errorf("must have at least one column")
+	}
+	for i, row := range oa {
+		if len(row) != cols {
+			return fmt.Errorf("row %d has %d columns, want %d", i+1, len(row), cols)
+		}
+		for j, v := range row {
+			if v < 1 {
+				return fmt.Errorf("row %d, column %d: level %d is invalid; levels start at 1", i+1, j+1, v)
+			}
+		}
+	}
+	info := DescribeArray(oa)
+	for j, levels := range info.Levels {
+		seen := make([]bool, levels)
+		for _, row := range oa {
+			seen[row[j]-1] = true
+		}
+		for l, ok := range seen {
+			if !ok {
+				return fmt.Errorf("column %d: level %d is never used but level %d is", j+1, l+1, levels)
+			}
+		}
+	}
+	return nil
+}
+
+// checkArray validates a user-supplied array according to opts; only the
+// first ArrayOptions value is used. Its errors match ErrInvalidArray.
+func checkArray(oa [][]int, opts []ArrayOptions) error {
+	var err error
+	if len(opts) > 0 && opts[0].AllowNonOrthogonal {
+		err = validateArrayShape(oa)
+	} else {
+		err = ValidateArray(oa)
+	}
+	if err != nil {
+		return &kindError{kind: ErrInvalidArray, err: err}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package taguchi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateArray_StandardArrays(t *testing.T) {
+	for _, info := range StandardArrayInfos() {
+		if err := ValidateArray(StandardArrays[info.Name]); err != nil {
+			t.Errorf("%s: %v", info.Name, err)
+		}
+	}
+}
+
+func TestValidateArray_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		oa   [][]int
+		want string
+	}{
+		{"empty", nil, "must not be empty"},
+		{"ragged", [][]int{{1, 1}, {1}}, "row 2 has 1 columns, want 2"},
+		{"zero-based", [][]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}}, "levels start at 1"},
+		{"gap", [][]int{{1}, {3}}, "column 1: level 2 is never used"},
+		{"unbalanced levels", [][]int{{1, 1}, {1, 2}, {1, 1}, {2, 2}}, "column 1: level 1 occurs 3 times, want 2"},
+		{"unbalanced pairs", [][]int{{1, 1}, {1, 1}, {2, 2}, {2, 2}}, "columns 1 and 2: levels (1, 1) occur together 2 times, want 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArray(tt.oa)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateArray = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewExperimentUsingArray_AllowNonOrthogonal(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1, 1}, {1, 1}, {2, 2}, {2, 2}}
+	if _, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, nil); err == nil {
+		t.Fatal("non-orthogonal array accepted without opting out")
+	}
+	if _, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, nil, ArrayOptions{AllowNonOrthogonal: true}); err != nil {
+		t.Fatalf("AllowNonOrthogonal: %v", err)
+	}
+	// Malformed arrays are rejected even when orthogonality is not required.
+	if _, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1, 1}, {2}}, nil, ArrayOptions{AllowNonOrthogonal: true}); err == nil {
+		t.Error("ragged array accepted")
+	}
+}
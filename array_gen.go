@@ -0,0 +1,150 @@
+package taguchi
+
+// primePowerArray generates the p-level orthogonal array with p^k rows and
+// (p^k-1)/(p-1) columns over GF(p), p prime. Rows enumerate the k basic
+// variables with the first one changing slowest. Column order follows
+// Taguchi's convention: each new basic variable x_m is followed by
+// x_m + v for every non-zero combination v of the previous basic variables,
+// which reproduces the standard L4, L8, L9, L16, L27, ... tables.
+func primePowerArray(p, k int) [][]int {
+	runs := 1
+	for i := 0; i < k; i++ {
+		runs *= p
+	}
+
+	// Coefficient vectors over the k basic variables, in column order.
+	var columns [][]int
+	span := 1
+	for m := 0; m < k; m++ {
+		for v := 0; v < span; v++ {
+			coeffs := make([]int, k)
+			rest := v
+			for i := 0; i < m; i++ {
+				coeffs[i] = rest % p
+				rest /= p
+			}
+			coeffs[m] = 1
+			columns = append(columns, coeffs)
+		}
+		span *= p
+	}
+
+	oa := make([][]int, runs)
+	digits := make([]int, k)
+	for r := 0; r < runs; r++ {
+		rest := r
+		for i := k - 1; i >= 0; i-- {
+			digits[i] = rest % p
+			rest /= p
+		}
+		row := make([]int, len(columns))
+		for c, coeffs := range columns {
+			sum := 0
+			for i, a := range coeffs {
+				sum += a * digits[i]
+			}
+			row[c] = sum%p + 1
+		}
+		oa[r] = row
+	}
+	return oa
+}
+
+// Difference schemes D(r, r, s) over Z_s: for any two columns, the
+// differences of their entries contain every element of Z_s equally often.
+var (
+	differenceScheme6x3 = [][]int{
+		{0, 0, 0, 0, 0, 0},
+		{0, 2, 1, 1, 2, 0},
+		{0, 1, 2, 1, 0, 2},
+		{0, 2, 2, 0, 1, 1},
+		{0, 1, 0, 2, 2, 1},
+		{0, 0, 1, 2, 1, 2},
+	}
+	differenceScheme12x3 = [][]int{
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 1, 2, 2, 1, 1, 2, 0, 0, 1, 2, 0},
+		{0, 0, 2, 0, 2, 2, 1, 0, 2, 1, 1, 1},
+		{0, 1, 1, 1, 2, 2, 0, 1, 2, 0, 2, 0},
+		{0, 2, 0, 1, 1, 2, 1, 2, 0, 2, 1, 0},
+		{0, 0, 2, 1, 0, 1, 0, 2, 1, 2, 2, 1},
+		{0, 2, 2, 0, 1, 0, 1, 1, 1, 0, 2, 2},
+		{0, 0, 1, 2, 2, 1, 1, 1, 0, 2, 0, 2},
+		{0, 1, 1, 0, 1, 0, 2, 2, 2, 2, 0, 1},
+		{0, 1, 0, 2, 2, 0, 0, 2, 1, 1, 1, 2},
+		{0, 2, 1, 1, 0, 2, 2, 0, 1, 1, 0, 2},
+		{0, 2, 0, 2, 0, 1, 2, 1, 2, 0, 1, 1},
+	}
+	differenceScheme10x5 = [][]int{
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 1, 4, 4, 3, 3, 2, 1, 2},
+		{0, 1, 3, 3, 2, 0, 4, 2, 4, 1},
+		{0, 2, 2, 0, 3, 3, 1, 1, 4, 4},
+		{0, 4, 3, 4, 1, 2, 1, 3, 2, 0},
+		{0, 2, 4, 3, 1, 4, 2, 0, 1, 3},
+		{0, 3, 1, 2, 3, 4, 0, 4, 2, 1},
+		{0, 4, 2, 1, 2, 1, 3, 4, 0, 3},
+		{0, 3, 4, 1, 0, 2, 4, 1, 3, 2},
+		{0, 1, 0, 2, 4, 1, 2, 3, 3, 4},
+	}
+)
+
+// differenceScheme18x3 builds D(18, 18, 3) as the Kronecker sum of D(6, 6, 3)
+// and the GF(3) multiplication table, which is itself a D(3, 3, 3).
+func differenceScheme18x3() [][]int {
+	d := make([][]int, 18)
+	for i1, row := range differenceScheme6x3 {
+		for i2 := 0; i2 < 3; i2++ {
+			out := make([]int, 18)
+			for j1, v := range row {
+				for j2 := 0; j2 < 3; j2++ {
+					out[j1*3+j2] = (v + i2*j2) % 3
+				}
+			}
+			d[i1*3+i2] = out
+		}
+	}
+	return d
+}
+
+// expandDifferenceScheme builds an s·r-row array from a base array with r rows
+// and a difference scheme D(r, c, s). Row (i, x) repeats the base row i and
+// adds x (mod s) to every entry of row i of the scheme. Base columns stay
+// orthogonal to the expanded ones because x runs over all of Z_s for each i.
+func expandDifferenceScheme(base [][]int, scheme [][]int, s int) [][]int {
+	oa := make([][]int, 0, len(scheme)*s)
+	for i, drow := range scheme {
+		for x := 0; x < s; x++ {
+			row := make([]int, 0, len(base[i])+len(drow))
+			row = append(row, base[i]...)
+			for _, d := range drow {
+				row = append(row, (d+x)%s+1)
+			}
+			oa = append(oa, row)
+		}
+	}
+	return oa
+}
+
+// buildL36 constructs L36 (2^11 × 3^12): the L12 columns, each row repeated
+// three times, followed by the 12 columns expanded from D(12, 12, 3).
+func buildL36() [][]int {
+	return expandDifferenceScheme(l12Rows, differenceScheme12x3, 3)
+}
+
+// buildL50 constructs L50 (2^1 × 5^11): a 2-level and a 5-level column forming a
+// full factorial over the ten scheme rows, followed by the 10 columns expanded
+// from D(10, 10, 5).
+func buildL50() [][]int {
+	base := make([][]int, 10)
+	for i := range base {
+		base[i] = []int{i/5 + 1, i%5 + 1}
+	}
+	return expandDifferenceScheme(base, differenceScheme10x5, 5)
+}
+
+// buildL54 constructs L54 (2^1 × 3^25): the L18 columns, each row repeated three
+// times, followed by the 18 columns expanded from D(18, 18, 3).
+func buildL54() [][]int {
+	return expandDifferenceScheme(l18Rows, differenceScheme18x3(), 3)
+}
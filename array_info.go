@@ -0,0 +1,90 @@
+package taguchi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ArrayInfo describes the capacity of an orthogonal array.
+// Name: Catalog name, empty for custom arrays.
+// Runs: Number of rows (control configurations).
+// Levels: Number of levels of each column.
+// MaxFactors: Maximum number of control factors (one per column).
+// Resolution: Design resolution when every column is used; strength-2 arrays are resolution III.
+type ArrayInfo struct {
+	Name       ArrayType
+	Runs       int
+	Levels     []int
+	MaxFactors int
+	Resolution int
+}
+
+// Notation returns the level structure in the usual exponent form, e.g. "2^1 × 3^7".
+func (a ArrayInfo) Notation() string {
+	counts := a.LevelCounts()
+	levels := make([]int, 0, len(counts))
+	for l := range counts {
+		levels = append(levels, l)
+	}
+	sort.Ints(levels)
+	parts := make([]string, len(levels))
+	for i, l := range levels {
+		parts[i] = fmt.Sprintf("%d^%d", l, counts[l])
+	}
+	return strings.Join(parts, " × ")
+}
+
+// LevelCounts returns the number of columns per level count.
+func (a ArrayInfo) LevelCounts() map[int]int {
+	counts := map[int]int{}
+	for _, l := range a.Levels {
+		counts[l]++
+	}
+	return counts
+}
+
+// DescribeArray computes the ArrayInfo of an arbitrary array with 1-based levels.
+// The number of levels of a column is the largest level it contains.
+func DescribeArray(oa [][]int) ArrayInfo {
+	info := ArrayInfo{Runs: len(oa), Resolution: 3}
+	if len(oa) == 0 {
+		return info
+	}
+	info.Levels = make([]int, len(oa[0]))
+	for _, row := range oa {
+		for j, v := range row {
+			if j < len(info.Levels) && v > info.Levels[j] {
+				info.Levels[j] = v
+			}
+		}
+	}
+	info.MaxFactors = len(info.Levels)
+	return info
+}
+
+// LookupArray returns the ArrayInfo of a standard array so callers can check
+// its capacity before constructing an Experiment.
+func LookupArray(name ArrayType) (ArrayInfo, bool) {
+	oa, ok := StandardArrays[name]
+	if !ok {
+		return ArrayInfo{}, false
+	}
+	info := DescribeArray(oa)
+	info.Name = name
+	return info, true
+}
+
+// StandardArrayInfos returns the ArrayInfo of every standard array, ordered by
+// number of runs.
+func StandardArrayInfos() []ArrayInfo {
+	infos := make([]ArrayInfo, 0, len(StandardArrays))
+	for name := range StandardArrays {
+		info, _ := LookupArray(name)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Runs < infos[j].Runs
+	})
+	return infos
+}
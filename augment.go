@@ -0,0 +1,435 @@
+package taguchi
+
+import (
+	"fmt"
+	"maps"
+	"math"
+)
+
+// Augmentation is a set of follow-up runs that de-aliases one two-factor
+// interaction of a completed experiment (see PlanAugmentation).
+// A, B: Names of the interacting factors.
+// Aliases: Factors occupying the interaction's columns, confounded with it in the original array.
+// Runs: Control configuration of each additional run, in the order they should be measured.
+type Augmentation struct {
+	A       string
+	B       string
+	Aliases []string
+	Runs    []map[string]float64
+}
+
+// AugmentedAnalysis is the combined least-squares analysis of the original
+// array rows and the augmentation runs.
+// Interaction: SS, DF, MS, F and fitted cell means of the interaction. SS is the extra sum of squares
+// over the main-effects model; F is 0 when the fit leaves no error variance.
+// MainEffects: Least-squares mean SNR of each factor level, averaged over the original array rows.
+// OptimalLevels: Best level of each factor, with A and B chosen jointly from the interaction cell means.
+// ErrorDF: Residual degrees of freedom of the combined fit.
+type AugmentedAnalysis struct {
+	Interaction   InteractionEffect
+	MainEffects   map[string][]float64
+	OptimalLevels map[string]float64
+	ErrorDF       int
+}
+
+// rankTolerance is the squared residual norm below which a model row adds no
+// rank to the design.
+const rankTolerance = 1e-9
+
+// PlanAugmentation computes the fewest additional runs that make the
+// interaction of factors a and b estimable next to all main effects, for a
+// completed experiment whose array confounds it (e.g. a saturated L8 with a
+// factor in the A×B column). Candidate runs fold over the array rows on a or
+// b: a row with one of the two factors switched to another level. Every run
+// adds at most one degree of freedom, so the runs are picked greedily until
+// the combined design has full rank. Record their results with AddResult
+// (see AugmentationTrials) and analyze them with AnalyzeAugmented.
+func (e *Experiment[P]) PlanAugmentation(a, b string) (Augmentation, error) {
+	ia, ib := e.factorIndex(a), e.factorIndex(b)
+	if ia < 0 {
+		return Augmentation{}, errorf(ErrUnknownFactor, "unknown factor %s", a)
+	}
+	if ib < 0 {
+		return Augmentation{}, errorf(ErrUnknownFactor, "unknown factor %s", b)
+	}
+	if ia == ib {
+		return Augmentation{}, fmt.Errorf("factor %s cannot interact with itself", a)
+	}
+	for i := range e.OrthogonalArray {
+		if !e.rowHasResults(i) {
+			return Augmentation{}, errorf(ErrMissingResults, "row %d has no results; complete the experiment before augmenting it", i+1)
+		}
+	}
+
+	aug := Augmentation{A: a, B: b}
+	for _, c := range InteractionColumns(e.OrthogonalArray, e.column(ia), e.column(ib)) {
+		for j := range e.ControlFactors {
+			if e.column(j) == c {
+				aug.Aliases = append(aug.Aliases, e.ControlFactors[j].Name)
+			}
+		}
+	}
+
+	model := e.augmentModel(ia, ib)
+	var basis [][]float64
+	seen := map[string]bool{}
+	for i := range e.OrthogonalArray {
+		levels := e.rowLevels(i)
+		seen[fmt.Sprint(levels)] = true
+		basis = addToBasis(basis, model.row(levels, true))
+	}
+	if len(basis) == model.params(true) {
+		return Augmentation{}, fmt.Errorf("interaction %s is already estimable in this array; use AddInteraction", InteractionName(a, b))
+	}
+	for i := range e.OrthogonalArray {
+		for _, j := range []int{ia, ib} {
+			for l := range e.ControlFactors[j].Levels {
+				if len(basis) == model.params(true) {
+					return aug, nil
+				}
+				levels := e.rowLevels(i)
+				if levels[j] == l {
+					continue
+				}
+				levels[j] = l
+				key := fmt.Sprint(levels)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				grown := addToBasis(basis, model.row(levels, true))
+				if len(grown) == len(basis) {
+					continue
+				}
+				basis = grown
+				aug.Runs = append(aug.Runs, e.levelConfig(levels))
+			}
+		}
+	}
+	if len(basis) < model.params(true) {
+		return Augmentation{}, fmt.Errorf("interaction %s cannot be de-aliased by folding over %s or %s", InteractionName(a, b), a, b)
+	}
+	return aug, nil
+}
+
+// AugmentationTrials returns the trials of the augmentation runs, each crossed
+// with the experiment's noise conditions like GenerateTrials. Trial IDs and
+// rows continue after those of the original array.
+func (e *Experiment[P]) AugmentationTrials(aug Augmentation) []Trial {
+	noiseTrials := e.sampledNoise()
+	id := len(e.OrthogonalArray)*len(noiseTrials) + 1
+	var trials []Trial
+	for k, control := range aug.Runs {
+		for _, noiseTrial := range noiseTrials {
+			trials = append(trials, Trial{
+				ID:      id,
+				Row:     len(e.OrthogonalArray) + k,
+				Control: maps.Clone(control),
+				Noise:   noiseTrial.Noise,
+				Key:     TrialKey(control, noiseTrial.Noise),
+			})
+			id++
+		}
+	}
+	return trials
+}
+
+// AnalyzeAugmented fits the main effects and the augmented interaction by
+// least squares to the SNRs of the original array rows and the augmentation
+// runs. Unlike Analyze, whose level means stay confounded with the aliases,
+// the effect of an alias is separated from the interaction here.
+func (e *Experiment[P]) AnalyzeAugmented(aug Augmentation) (AugmentedAnalysis, error) {
+	ia, ib := e.factorIndex(aug.A), e.factorIndex(aug.B)
+	if ia < 0 || ib < 0 || ia == ib {
+		return AugmentedAnalysis{}, fmt.Errorf("augmentation %s does not match the experiment's factors", InteractionName(aug.A, aug.B))
+	}
+
+	configs := make([][]int, 0, len(e.OrthogonalArray)+len(aug.Runs))
+	var results [][]TrialResult
+	for i := range e.OrthogonalArray {
+		configs = append(configs, e.rowLevels(i))
+		var matched []TrialResult
+		for _, r := range e.Results {
+			if e.rowMatches(i, r.Trial) {
+				matched = append(matched, r)
+			}
+		}
+		results = append(results, matched)
+	}
+	for k, control := range aug.Runs {
+		levels, err := e.configLevels(control)
+		if err != nil {
+			return AugmentedAnalysis{}, fmt.Errorf("augmentation run %d: %w", k+1, err)
+		}
+		configs = append(configs, levels)
+		var matched []TrialResult
+		for _, r := range e.Results {
+			if maps.Equal(r.Trial.Control, control) {
+				matched = append(matched, r)
+			}
+		}
+		if len(matched) == 0 {
+			return AugmentedAnalysis{}, errorf(ErrMissingResults, "augmentation run %d has no results", k+1)
+		}
+		results = append(results, matched)
+	}
+
+	rows := oaRowSNR{
+		values:   make([]float64, len(configs)),
+		included: make([]bool, len(configs)),
+		infinite: make([]bool, len(configs)),
+	}
+	for i, matched := range results {
+		rows.values[i] = e.resultsSNR(matched)
+		rows.included[i] = len(matched) > 0
+	}
+	e.applyInfinitePolicy(&rows)
+
+	model := e.augmentModel(ia, ib)
+	var full, reduced [][]float64
+	var y []float64
+	for i, levels := range configs {
+		if !rows.included[i] {
+			continue
+		}
+		full = append(full, model.row(levels, true))
+		reduced = append(reduced, model.row(levels, false))
+		y = append(y, rows.values[i])
+	}
+	coef, rssFull, err := leastSquares(full, y)
+	if err != nil {
+		return AugmentedAnalysis{}, fmt.Errorf("interaction %s is not estimable from the recorded rows: %w", InteractionName(aug.A, aug.B), err)
+	}
+	_, rssReduced, err := leastSquares(reduced, y)
+	if err != nil {
+		return AugmentedAnalysis{}, fmt.Errorf("main effects are not estimable from the recorded rows: %w", err)
+	}
+
+	predict := func(levels []int) float64 {
+		return dot(model.row(levels, true), coef)
+	}
+	// averageOver predicts every original row with some levels overridden and
+	// averages the predictions, giving least-squares means.
+	averageOver := func(set map[int]int) float64 {
+		sum := 0.0
+		for i := range e.OrthogonalArray {
+			levels := e.rowLevels(i)
+			for j, l := range set {
+				levels[j] = l
+			}
+			sum += predict(levels)
+		}
+		return sum / float64(len(e.OrthogonalArray))
+	}
+
+	result := AugmentedAnalysis{
+		MainEffects:   map[string][]float64{},
+		OptimalLevels: map[string]float64{},
+		ErrorDF:       len(y) - model.params(true),
+	}
+	for j, factor := range e.ControlFactors {
+		effects := make([]float64, len(factor.Levels))
+		for l := range effects {
+			effects[l] = averageOver(map[int]int{j: l})
+		}
+		result.MainEffects[factor.Name] = effects
+	}
+	result.OptimalLevels = e.findOptimalLevels(result.MainEffects)
+
+	la, lb := len(e.ControlFactors[ia].Levels), len(e.ControlFactors[ib].Levels)
+	cells := make([][]float64, la)
+	bestX, bestY := 0, 0
+	for x := range cells {
+		cells[x] = make([]float64, lb)
+		for yl := range cells[x] {
+			cells[x][yl] = averageOver(map[int]int{ia: x, ib: yl})
+			if cells[x][yl] > cells[bestX][bestY] {
+				bestX, bestY = x, yl
+			}
+		}
+	}
+	if !e.ControlFactors[ia].ObserveOnly {
+		result.OptimalLevels[aug.A] = e.ControlFactors[ia].Levels[bestX]
+	}
+	if !e.ControlFactors[ib].ObserveOnly {
+		result.OptimalLevels[aug.B] = e.ControlFactors[ib].Levels[bestY]
+	}
+
+	df := (la - 1) * (lb - 1)
+	ss := max(rssReduced-rssFull, 0)
+	in := InteractionEffect{A: aug.A, B: aug.B, SS: ss, DF: df, MS: ss / float64(df), CellMeans: cells}
+	if result.ErrorDF > 0 {
+		if errMS := rssFull / float64(result.ErrorDF); errMS > 0 {
+			in.F = in.MS / errMS
+		}
+	}
+	result.Interaction = in
+	return result, nil
+}
+
+// augmentModel describes the dummy-coded regression model of an augmented
+// analysis: an intercept, one column per non-first level of every factor
+// and, optionally, the products of the non-first levels of factors a and b.
+type augmentModel struct {
+	levels []int
+	a, b   int
+}
+
+func (e *Experiment[P]) augmentModel(a, b int) augmentModel {
+	m := augmentModel{a: a, b: b}
+	for _, f := range e.ControlFactors {
+		m.levels = append(m.levels, len(f.Levels))
+	}
+	return m
+}
+
+// params returns the number of model coefficients.
+func (m augmentModel) params(interaction bool) int {
+	n := 1
+	for _, l := range m.levels {
+		n += l - 1
+	}
+	if interaction {
+		n += (m.levels[m.a] - 1) * (m.levels[m.b] - 1)
+	}
+	return n
+}
+
+// row returns the model row of a configuration given as zero-based levels.
+func (m augmentModel) row(levels []int, interaction bool) []float64 {
+	x := make([]float64, 0, m.params(interaction))
+	x = append(x, 1)
+	for j, n := range m.levels {
+		for l := 1; l < n; l++ {
+			x = append(x, indicator(levels[j] == l))
+		}
+	}
+	if interaction {
+		for la := 1; la < m.levels[m.a]; la++ {
+			for lb := 1; lb < m.levels[m.b]; lb++ {
+				x = append(x, indicator(levels[m.a] == la && levels[m.b] == lb))
+			}
+		}
+	}
+	return x
+}
+
+func indicator(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// rowLevels returns the zero-based level of every control factor in array row i.
+func (e *Experiment[P]) rowLevels(i int) []int {
+	levels := make([]int, len(e.ControlFactors))
+	for j := range levels {
+		levels[j] = e.levelIndex(i, j)
+	}
+	return levels
+}
+
+// levelConfig converts zero-based factor levels into a control configuration.
+func (e *Experiment[P]) levelConfig(levels []int) map[string]float64 {
+	config := make(map[string]float64, len(levels))
+	for j, factor := range e.ControlFactors {
+		config[factor.Name] = factor.Levels[levels[j]]
+	}
+	return config
+}
+
+// configLevels converts a control configuration into zero-based factor levels.
+func (e *Experiment[P]) configLevels(config map[string]float64) ([]int, error) {
+	levels := make([]int, len(e.ControlFactors))
+	for j, factor := range e.ControlFactors {
+		v, ok := config[factor.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing factor %s", factor.Name)
+		}
+		levels[j] = levelPosition(factor, v)
+		if levels[j] < 0 {
+			return nil, errorf(ErrLevelMismatch, "%g is not a level of factor %s", v, factor.Name)
+		}
+	}
+	return levels, nil
+}
+
+// addToBasis orthogonalizes x against an orthonormal basis and returns the
+// basis extended by x when x is linearly independent of it.
+func addToBasis(basis [][]float64, x []float64) [][]float64 {
+	r := append([]float64(nil), x...)
+	for _, q := range basis {
+		d := dot(q, r)
+		for k := range r {
+			r[k] -= d * q[k]
+		}
+	}
+	norm := dot(r, r)
+	if norm < rankTolerance {
+		return basis
+	}
+	norm = math.Sqrt(norm)
+	for k := range r {
+		r[k] /= norm
+	}
+	return append(basis, r)
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for k := range a {
+		s += a[k] * b[k]
+	}
+	return s
+}
+
+// leastSquares solves the normal equations of x·coef ≈ y by Gaussian
+// elimination and returns the coefficients and residual sum of squares. It
+// fails when x does not have full column rank.
+func leastSquares(x [][]float64, y []float64) ([]float64, float64, error) {
+	p := len(x[0])
+	a := make([][]float64, p)
+	for r := range a {
+		a[r] = make([]float64, p+1)
+	}
+	for i, row := range x {
+		for r := 0; r < p; r++ {
+			for c := 0; c < p; c++ {
+				a[r][c] += row[r] * row[c]
+			}
+			a[r][p] += row[r] * y[i]
+		}
+	}
+	for col := 0; col < p; col++ {
+		pivot := col
+		for r := col + 1; r < p; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < rankTolerance {
+			return nil, 0, fmt.Errorf("design matrix is rank deficient")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for r := 0; r < p; r++ {
+			if r == col {
+				continue
+			}
+			f := a[r][col] / a[col][col]
+			for c := col; c <= p; c++ {
+				a[r][c] -= f * a[col][c]
+			}
+		}
+	}
+	coef := make([]float64, p)
+	for r := range coef {
+		coef[r] = a[r][p] / a[r][r]
+	}
+	rss := 0.0
+	for i, row := range x {
+		d := y[i] - dot(row, coef)
+		rss += d * d
+	}
+	return coef, rss, nil
+}
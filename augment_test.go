@@ -0,0 +1,99 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+// augmentResponse is an SNR with a strong A×B interaction and no effect of C,
+// which sits in the A×B column of L8 and so takes the interaction's blame.
+func augmentResponse(control map[string]float64) float64 {
+	y := 10.0
+	if control["A"] == 2 {
+		y += 2
+	}
+	if control["B"] == 2 {
+		y++
+	}
+	if control["A"] == 2 && control["B"] == 2 {
+		y += 3
+	}
+	return y
+}
+
+func TestAugmentation(t *testing.T) {
+	identity := FuncGoal{Name: "identity", SNR: func(obs []float64) float64 { return obs[0] }}
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(identity, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	if _, err := exp.PlanAugmentation("A", "B"); err == nil {
+		t.Error("PlanAugmentation accepted an experiment without results")
+	}
+	for _, trial := range exp.GenerateTrials() {
+		exp.AddResult(trial, []float64{augmentResponse(trial.Control)})
+	}
+	if effects := mustAnalyze(t, exp).MainEffects["C"]; math.Abs(effects[1]-effects[0]) < 1 {
+		t.Fatalf("C main effect %v, want it confounded with A×B", effects)
+	}
+
+	aug, err := exp.PlanAugmentation("A", "B")
+	if err != nil {
+		t.Fatalf("PlanAugmentation: %v", err)
+	}
+	if len(aug.Aliases) != 1 || aug.Aliases[0] != "C" {
+		t.Errorf("Aliases = %v, want [C]", aug.Aliases)
+	}
+	if len(aug.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(aug.Runs))
+	}
+	if _, err := exp.AnalyzeAugmented(aug); err == nil {
+		t.Error("AnalyzeAugmented accepted runs without results")
+	}
+
+	trials := exp.AugmentationTrials(aug)
+	if len(trials) != 1 || trials[0].ID != 9 || trials[0].Row != 8 {
+		t.Fatalf("AugmentationTrials = %+v, want one trial with ID 9 in row 9", trials)
+	}
+	exp.AddResult(trials[0], []float64{augmentResponse(trials[0].Control)})
+
+	result, err := exp.AnalyzeAugmented(aug)
+	if err != nil {
+		t.Fatalf("AnalyzeAugmented: %v", err)
+	}
+	if c := result.MainEffects["C"]; !almostEqual(c[0], c[1]) {
+		t.Errorf("C main effect %v, want none after augmentation", c)
+	}
+	cells := result.Interaction.CellMeans
+	if got := cells[1][1] - cells[1][0] - cells[0][1] + cells[0][0]; !almostEqual(got, 3) {
+		t.Errorf("interaction contrast = %.4f, want 3", got)
+	}
+	if result.Interaction.SS <= 0 || result.Interaction.DF != 1 || result.ErrorDF != 4 {
+		t.Errorf("Interaction = %+v, ErrorDF = %d", result.Interaction, result.ErrorDF)
+	}
+	if result.OptimalLevels["A"] != 2 || result.OptimalLevels["B"] != 2 {
+		t.Errorf("OptimalLevels = %v, want A=2 B=2", result.OptimalLevels)
+	}
+}
+
+func TestPlanAugmentation_AlreadyEstimable(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		exp.AddResult(trial, []float64{1})
+	}
+	if _, err := exp.PlanAugmentation("A", "B"); err == nil {
+		t.Error("PlanAugmentation should report an interaction that is already estimable")
+	}
+}
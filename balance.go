@@ -0,0 +1,191 @@
+package taguchi
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// PairBalance describes how evenly the level combinations of two factors occur.
+// FactorA, FactorB: The factor pair.
+// Counts: Occurrences of each level combination, indexed [levelA][levelB] (zero-based).
+// Imbalance: Largest relative deviation of a combination count from its expected count; 0 means perfectly balanced.
+type PairBalance struct {
+	FactorA   string
+	FactorB   string
+	Counts    [][]int
+	Imbalance float64
+}
+
+// DesignBalance summarizes how balanced the experiment's array is for its factors.
+// LevelCounts: Occurrences of each level per factor, in level order.
+// LevelImbalance: Largest relative deviation of a level count from its expected count, per factor.
+// Pairs: Pairwise balance for every pair of factors, in factor order.
+// Orthogonal: True when every level and every pair of levels is perfectly balanced.
+type DesignBalance struct {
+	LevelCounts    map[string][]int
+	LevelImbalance map[string]float64
+	Pairs          []PairBalance
+	Orthogonal     bool
+}
+
+// Balance computes per-factor level occurrence counts and pairwise balance
+// statistics of the array columns assigned to the control factors, so weakly
+// balanced custom designs are used knowingly rather than treated as orthogonal.
+func (e *Experiment[P]) Balance() DesignBalance {
+	rows := len(e.OrthogonalArray)
+	b := DesignBalance{
+		LevelCounts:    map[string][]int{},
+		LevelImbalance: map[string]float64{},
+		Orthogonal:     true,
+	}
+
+	for j, factor := range e.ControlFactors {
+		counts := make([]int, len(factor.Levels))
+		for i := range e.OrthogonalArray {
+			if l := e.levelIndex(i, j); l >= 0 && l < len(counts) {
+				counts[l]++
+			}
+		}
+		expected := float64(rows) / float64(len(counts))
+		b.LevelCounts[factor.Name] = counts
+		b.LevelImbalance[factor.Name] = relativeDeviation(counts, expected)
+		if b.LevelImbalance[factor.Name] > 0 {
+			b.Orthogonal = false
+		}
+	}
+
+	for a := 0; a < len(e.ControlFactors); a++ {
+		for c := a + 1; c < len(e.ControlFactors); c++ {
+			fa, fc := e.ControlFactors[a], e.ControlFactors[c]
+			counts := make([][]int, len(fa.Levels))
+			for i := range counts {
+				counts[i] = make([]int, len(fc.Levels))
+			}
+			for i := range e.OrthogonalArray {
+				la, lc := e.levelIndex(i, a), e.levelIndex(i, c)
+				if la >= 0 && la < len(fa.Levels) && lc >= 0 && lc < len(fc.Levels) {
+					counts[la][lc]++
+				}
+			}
+			var flat []int
+			for _, r := range counts {
+				flat = append(flat, r...)
+			}
+			expected := float64(rows) / float64(len(flat))
+			pair := PairBalance{
+				FactorA:   fa.Name,
+				FactorB:   fc.Name,
+				Counts:    counts,
+				Imbalance: relativeDeviation(flat, expected),
+			}
+			if pair.Imbalance > 0 {
+				b.Orthogonal = false
+			}
+			b.Pairs = append(b.Pairs, pair)
+		}
+	}
+	return b
+}
+
+// relativeDeviation returns max |count - expected| / expected.
+func relativeDeviation(counts []int, expected float64) float64 {
+	if expected == 0 {
+		return 0
+	}
+	worst := 0.0
+	for _, n := range counts {
+		worst = math.Max(worst, math.Abs(float64(n)-expected)/expected)
+	}
+	return worst
+}
+
+// WriteDesignReport writes a human-readable description of the design: the
+// array, the factor-to-column assignment, and the level and pairwise balance.
+func (e *Experiment[P]) WriteDesignReport(w io.Writer) error {
+	var b strings.Builder
+	info := DescribeArray(e.OrthogonalArray)
+	balance := e.Balance()
+
+	fmt.Fprintln(&b, "========================================")
+	fmt.Fprintln(&b, "        TAGUCHI DESIGN REPORT")
+	fmt.Fprintln(&b, "========================================")
+	fmt.Fprintf(&b, "Array: %d runs, %s\n", info.Runs, info.Notation())
+	fmt.Fprintf(&b, "Noise conditions per run: %d\n", len(e.noiseConditions()))
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "1. Factor Levels")
+	fmt.Fprintln(&b, "----------------")
+	for j, factor := range e.ControlFactors {
+		fmt.Fprintf(&b, "  %s (column %d): occurrences %v", factor.Name, e.column(j)+1, balance.LevelCounts[factor.Name])
+		if imb := balance.LevelImbalance[factor.Name]; imb > 0 {
+			fmt.Fprintf(&b, "  imbalance %.0f%%", imb*100)
+		}
+		fmt.Fprintln(&b)
+	}
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "2. Pairwise Balance")
+	fmt.Fprintln(&b, "-------------------")
+	unbalanced := 0
+	for _, p := range balance.Pairs {
+		if p.Imbalance > 0 {
+			unbalanced++
+			fmt.Fprintf(&b, "  %s × %s: imbalance %.0f%%, counts %v\n", p.FactorA, p.FactorB, p.Imbalance*100, p.Counts)
+		}
+	}
+	if unbalanced == 0 {
+		fmt.Fprintln(&b, "  All factor pairs are balanced.")
+	}
+	if balance.Orthogonal {
+		fmt.Fprintln(&b, "  => The design is orthogonal for the assigned factors.")
+	} else {
+		fmt.Fprintln(&b, "  => The design is NOT orthogonal; main effects are partially confounded.")
+	}
+	fmt.Fprintln(&b)
+
+	diag := e.DesignDiagnostics()
+	fmt.Fprintf(&b, "3. Aliasing (resolution %s)\n", resolutionNumeral(diag.Resolution))
+	fmt.Fprintln(&b, "-------------------------")
+	for _, a := range diag.Aliases {
+		name := InteractionName(a.A, a.B)
+		if a.Declared {
+			name += " (declared)"
+		}
+		if a.Clear() {
+			fmt.Fprintf(&b, "  %s: clear of main effects\n", name)
+			continue
+		}
+		aliased := make([]string, len(a.Factors))
+		for i, f := range a.Factors {
+			aliased[i] = fmt.Sprintf("%s %.0f%%", f, a.Shares[i]*100)
+		}
+		fmt.Fprintf(&b, "  %s: aliased with %s\n", name, strings.Join(aliased, ", "))
+	}
+	if len(diag.FreeColumns) > 0 {
+		free := make([]string, len(diag.FreeColumns))
+		for i, c := range diag.FreeColumns {
+			free[i] = fmt.Sprint(c + 1)
+		}
+		fmt.Fprintf(&b, "  Free columns (error estimate): %s\n", strings.Join(free, ", "))
+	}
+	if diag.Resolution == 3 {
+		fmt.Fprintln(&b, "  => Main effects are only trustworthy if the aliased interactions are negligible.")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// resolutionNumeral returns the conventional Roman numeral of a design resolution.
+func resolutionNumeral(r int) string {
+	switch r {
+	case 3:
+		return "III"
+	case 4:
+		return "IV"
+	default:
+		return "V+"
+	}
+}
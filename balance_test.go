@@ -0,0 +1,62 @@
+package taguchi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBalance_StandardArrayIsOrthogonal(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2, 3}},
+		{Name: "B", Levels: []float64{1, 2, 3}},
+		{Name: "C", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	b := exp.Balance()
+	if !b.Orthogonal {
+		t.Errorf("L9 reported as not orthogonal: %+v", b)
+	}
+	if got := b.LevelCounts["A"]; len(got) != 3 || got[0] != 3 || got[1] != 3 || got[2] != 3 {
+		t.Errorf("LevelCounts[A] = %v, want [3 3 3]", got)
+	}
+	if len(b.Pairs) != 3 {
+		t.Errorf("len(Pairs) = %d, want 3", len(b.Pairs))
+	}
+}
+
+func TestBalance_CustomArrayImbalance(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1, 1}, {1, 1}, {1, 2}, {2, 2}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, nil, ArrayOptions{AllowNonOrthogonal: true})
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	b := exp.Balance()
+	if b.Orthogonal {
+		t.Fatal("unbalanced array reported as orthogonal")
+	}
+	if got := b.LevelImbalance["A"]; !almostEqual(got, 0.5) {
+		t.Errorf("LevelImbalance[A] = %.4f, want 0.5", got)
+	}
+	if got := b.LevelImbalance["B"]; got != 0 {
+		t.Errorf("LevelImbalance[B] = %.4f, want 0", got)
+	}
+	// Expected count per cell is 1; cell (1,1) occurs twice and (2,1) never.
+	if got := b.Pairs[0].Imbalance; !almostEqual(got, 1) {
+		t.Errorf("Pairs[0].Imbalance = %.4f, want 1", got)
+	}
+
+	var sb strings.Builder
+	if err := exp.WriteDesignReport(&sb); err != nil {
+		t.Fatalf("WriteDesignReport: %v", err)
+	}
+	if !strings.Contains(sb.String(), "NOT orthogonal") {
+		t.Errorf("design report does not flag the imbalance:\n%s", sb.String())
+	}
+}
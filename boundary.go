@@ -0,0 +1,59 @@
+package taguchi
+
+// BoundaryOptimum flags a factor whose optimal level is the smallest or largest
+// level explored, which usually means the true optimum lies outside the design
+// and a follow-up experiment should shift the range.
+// Factor: Name of the control factor.
+// Level: The optimal level.
+// AtMax: True when the optimum is the largest explored level, false for the smallest.
+type BoundaryOptimum struct {
+	Factor string
+	Level  float64
+	AtMax  bool
+}
+
+// Edge returns "upper" or "lower" depending on which end of the range the optimum sits.
+func (b BoundaryOptimum) Edge() string {
+	if b.AtMax {
+		return "upper"
+	}
+	return "lower"
+}
+
+// BoundaryMinContribution is the percentage contribution above which a factor
+// that is not significant still has its boundary optimum flagged.
+const BoundaryMinContribution = 10.0
+
+// findBoundaryOptima reports factors whose optimal level is at the edge of the
+// explored range. Two-level factors are skipped since their optimum is always
+// at an edge and the flag would carry no information. So are factors neither
+// significant nor contributing at least BoundaryMinContribution percent: the
+// optimum of a factor without an effect is noise, and following it outside
+// the range would waste a follow-up experiment.
+func (e *Experiment[P]) findBoundaryOptima(optimalLevels map[string]float64, anova ANOVAResult, contributions map[string]float64) []BoundaryOptimum {
+	var boundary []BoundaryOptimum
+	for _, factor := range e.ControlFactors {
+		if len(factor.Levels) < 3 {
+			continue
+		}
+		if !anova.Significant[factor.Name] && contributions[factor.Name] < BoundaryMinContribution {
+			continue
+		}
+		best, ok := optimalLevels[factor.Name]
+		if !ok {
+			continue
+		}
+		lo, hi := factor.Levels[0], factor.Levels[0]
+		for _, l := range factor.Levels {
+			lo = min(lo, l)
+			hi = max(hi, l)
+		}
+		switch best {
+		case hi:
+			boundary = append(boundary, BoundaryOptimum{Factor: factor.Name, Level: best, AtMax: true})
+		case lo:
+			boundary = append(boundary, BoundaryOptimum{Factor: factor.Name, Level: best})
+		}
+	}
+	return boundary
+}
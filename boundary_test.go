@@ -0,0 +1,88 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFindBoundaryOptima verifies that edge optima are flagged only for
+// factors that are significant or contribute at least
+// BoundaryMinContribution percent.
+func TestFindBoundaryOptima(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "Significant", Levels: []float64{1, 2, 3}},
+		{Name: "Contributing", Levels: []float64{1, 2, 3}},
+		{Name: "Weak", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	optimal := map[string]float64{"Significant": 3, "Contributing": 1, "Weak": 3}
+	anova := ANOVAResult{Significant: map[string]bool{"Significant": true}}
+	contributions := map[string]float64{"Significant": 2, "Contributing": BoundaryMinContribution, "Weak": BoundaryMinContribution - 0.1}
+
+	boundary := exp.findBoundaryOptima(optimal, anova, contributions)
+	want := []BoundaryOptimum{
+		{Factor: "Significant", Level: 3, AtMax: true},
+		{Factor: "Contributing", Level: 1},
+	}
+	if len(boundary) != len(want) {
+		t.Fatalf("boundary optima = %+v, want %+v", boundary, want)
+	}
+	for i := range want {
+		if boundary[i] != want[i] {
+			t.Errorf("boundary optimum %d = %+v, want %+v", i, boundary[i], want[i])
+		}
+	}
+
+	// An interior optimum is not flagged however strong the factor.
+	optimal["Significant"] = 2
+	if boundary := exp.findBoundaryOptima(optimal, anova, contributions); len(boundary) != 1 || boundary[0].Factor != "Contributing" {
+		t.Errorf("boundary optima with an interior optimum = %+v, want only Contributing", boundary)
+	}
+}
+
+// TestAnalyze_BoundaryOptima verifies end to end that a strong factor with
+// its optimum at the edge is flagged and warned about, while a factor
+// without an effect is not, wherever its optimum falls.
+func TestAnalyze_BoundaryOptima(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2, 3}},
+		{Name: "B", Levels: []float64{1, 2, 3}},
+		{Name: "C", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		// The response is multiplicative, so the SNR is additive in the
+		// factors. The free fourth column of the L9 carries the error, C has
+		// its optimum inside the range and B a negligible effect with its
+		// optimum at the lower edge.
+		residual := float64(exp.OrthogonalArray[trial.Row][3]) - 2
+		c := trial.Control["C"] - 2
+		y := 100 * math.Pow(0.5, trial.Control["A"]) * math.Pow(1.002, trial.Control["B"]) * math.Pow(1.5, c*c) * math.Pow(1.05, residual)
+		if err := exp.AddResult(trial, []float64{y}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	result := mustAnalyze(t, exp)
+
+	if result.OptimalLevels["B"] != 1 || result.ANOVA.Significant["B"] || result.Contributions["B"] >= BoundaryMinContribution {
+		t.Fatalf("B: optimum %g, significant %v, contribution %g; want a negligible factor optimal at 1",
+			result.OptimalLevels["B"], result.ANOVA.Significant["B"], result.Contributions["B"])
+	}
+	if len(result.BoundaryOptima) != 1 || result.BoundaryOptima[0].Factor != "A" || !result.BoundaryOptima[0].AtMax {
+		t.Errorf("BoundaryOptima = %+v, want A at its upper edge", result.BoundaryOptima)
+	}
+	if !result.HasWarning(WarnBoundaryOptimum) {
+		t.Error("no boundary-optimum warning for A")
+	}
+	for _, w := range result.Warnings {
+		if w.Code == WarnBoundaryOptimum && w.Factor != "A" {
+			t.Errorf("boundary-optimum warning for %s", w.Factor)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Builder constructs an experiment step by step as an alternative to the
+// positional constructors:
+//
+//	exp, opts, err := taguchi.NewBuilder().
+//		Goal(taguchi.SmallerTheBetter{}).
+//		Factor("Threads", 1, 2, 4).
+//		Factor("Cache", 64, 128, 256).
+//		Noise("Load", 10, 100).
+//		Array(taguchi.L9).
+//		Repetitions(3).
+//		Build()
+//
+// Every step is validated as it is added, against the steps before it, so
+// the error names the call at fault, e.g. `Factor("Cache"): level 128
+// repeated`. After the first error the remaining steps are ignored and
+// Build returns that error.
+type Builder struct {
+	goal        OptimizationGoal
+	factors     []ControlFactor
+	noise       []NoiseFactor
+	array       ArrayType
+	alpha       float64
+	repetitions int
+	err         error
+}
+
+// NewBuilder returns an empty builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Goal sets the optimization goal.
+func (b *Builder) Goal(goal OptimizationGoal) *Builder {
+	switch {
+	case b.err != nil:
+	case goal == nil:
+		b.fail(nil, "Goal: goal is nil")
+	case b.goal != nil:
+		b.fail(nil, "Goal(%s): goal already set to %s", goal, b.goal)
+	default:
+		b.goal = goal
+	}
+	return b
+}
+
+// Factor adds a control factor with the given levels.
+func (b *Builder) Factor(name string, levels ...float64) *Builder {
+	return b.ControlFactor(ControlFactor{Name: name, Levels: levels})
+}
+
+// ControlFactor adds a control factor with all its settings, e.g. to mark it
+// Continuous or attach an Apply function.
+func (b *Builder) ControlFactor(f ControlFactor) *Builder {
+	if b.err != nil {
+		return b
+	}
+	call := fmt.Sprintf("Factor(%q)", f.Name)
+	if !b.checkFactor(call, f.Name, f.Levels) {
+		return b
+	}
+	b.factors = append(b.factors, f)
+	if b.array != "" {
+		if _, err := AssignColumns(b.factors, StandardArrays[b.array]); err != nil {
+			info, _ := LookupArray(b.array)
+			b.fail(ErrArrayTooSmall, "%s: array %s (%s) has no free %d-level column left", call, b.array, info.Notation(), len(f.Levels))
+		}
+	}
+	return b
+}
+
+// Noise adds a noise factor with the given levels.
+func (b *Builder) Noise(name string, levels ...float64) *Builder {
+	return b.NoiseFactor(NoiseFactor{Name: name, Levels: levels})
+}
+
+// NoiseFactor adds a noise factor with all its settings, e.g. its Weights.
+func (b *Builder) NoiseFactor(f NoiseFactor) *Builder {
+	if b.err != nil {
+		return b
+	}
+	call := fmt.Sprintf("Noise(%q)", f.Name)
+	if !b.checkFactor(call, f.Name, f.Levels) {
+		return b
+	}
+	if f.Weights != nil && b.goal != nil {
+		if err := checkNoiseWeights(b.goal, []NoiseFactor{f}); err != nil {
+			b.err = fmt.Errorf("builder: %s: %w", call, err)
+			return b
+		}
+	}
+	b.noise = append(b.noise, f)
+	return b
+}
+
+// Array sets the standard orthogonal array. Without it, Build picks the
+// smallest standard array that fits the factors.
+func (b *Builder) Array(name ArrayType) *Builder {
+	if b.err != nil {
+		return b
+	}
+	info, ok := LookupArray(name)
+	switch {
+	case !ok:
+		names := make([]string, 0, len(StandardArrays))
+		for _, info := range StandardArrayInfos() {
+			names = append(names, string(info.Name))
+		}
+		b.fail(ErrUnknownArray, "Array(%s): unknown array; the standard arrays are %s", name, strings.Join(names, ", "))
+	case b.array != "":
+		b.fail(nil, "Array(%s): array already set to %s", name, b.array)
+	default:
+		if _, err := AssignColumns(b.factors, StandardArrays[name]); err != nil {
+			b.fail(ErrArrayTooSmall, "Array(%s): array (%s) cannot hold the factors added so far: %v", name, info.Notation(), err)
+			return b
+		}
+		b.array = name
+	}
+	return b
+}
+
+// Alpha sets the significance level of the ANOVA (see Experiment.Alpha).
+func (b *Builder) Alpha(alpha float64) *Builder {
+	switch {
+	case b.err != nil:
+	case !(alpha > 0 && alpha < 1):
+		b.fail(nil, "Alpha(%g): must be between 0 and 1", alpha)
+	default:
+		b.alpha = alpha
+	}
+	return b
+}
+
+// Repetitions sets the measured runs per trial, returned in the run options
+// of Build.
+func (b *Builder) Repetitions(n int) *Builder {
+	switch {
+	case b.err != nil:
+	case n < 1:
+		b.fail(nil, "Repetitions(%d): must be at least 1", n)
+	default:
+		b.repetitions = n
+	}
+	return b
+}
+
+// Build constructs the experiment and returns it together with the run
+// options set by the builder, to be completed with hooks and the other
+// options of the run.
+func (b *Builder) Build() (*Experiment[struct{}], RunOptions, error) {
+	if b.err != nil {
+		return nil, RunOptions{}, b.err
+	}
+	switch {
+	case b.goal == nil:
+		return nil, RunOptions{}, fmt.Errorf("builder: no goal; call Goal")
+	case len(b.factors) == 0:
+		return nil, RunOptions{}, fmt.Errorf("builder: no control factors; call Factor")
+	}
+	if err := checkNoiseWeights(b.goal, b.noise); err != nil {
+		return nil, RunOptions{}, fmt.Errorf("builder: %w", err)
+	}
+	var e *Experiment[struct{}]
+	var err error
+	if b.array != "" {
+		e, err = NewExperimentFromFactors(b.goal, b.factors, b.array, b.noise)
+	} else {
+		e, err = smallestExperiment(b.goal, b.factors, b.noise)
+	}
+	if err != nil {
+		return nil, RunOptions{}, fmt.Errorf("builder: %w", err)
+	}
+	e.Alpha = b.alpha
+	return e, RunOptions{Repetitions: b.repetitions}, nil
+}
+
+// checkFactor validates the name and levels of a new control or noise factor.
+func (b *Builder) checkFactor(call, name string, levels []float64) bool {
+	switch {
+	case name == "":
+		b.fail(nil, "%s: factor needs a name", call)
+	case slices.ContainsFunc(b.factors, func(f ControlFactor) bool { return f.Name == name }):
+		b.fail(nil, "%s: a control factor with this name was already added", call)
+	case slices.ContainsFunc(b.noise, func(f NoiseFactor) bool { return f.Name == name }):
+		b.fail(nil, "%s: a noise factor with this name was already added", call)
+	case len(levels) < 2:
+		b.fail(ErrLevelMismatch, "%s: needs at least 2 levels, got %d", call, len(levels))
+	}
+	for i, level := range levels {
+		if b.err != nil {
+			break
+		}
+		if math.IsNaN(level) || math.IsInf(level, 0) {
+			b.fail(ErrLevelMismatch, "%s: level %g is not a finite number", call, level)
+		} else if slices.Contains(levels[:i], level) {
+			b.fail(ErrLevelMismatch, "%s: level %g repeated", call, level)
+		}
+	}
+	return b.err == nil
+}
+
+// fail records the first error of the builder, assigned to kind when it is
+// not nil.
+func (b *Builder) fail(kind error, format string, args ...any) {
+	if kind != nil {
+		b.err = errorf(kind, "builder: "+format, args...)
+	} else {
+		b.err = fmt.Errorf("builder: "+format, args...)
+	}
+}
@@ -0,0 +1,67 @@
+package taguchi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	exp, opts, err := NewBuilder().
+		Goal(SmallerTheBetter{}).
+		Factor("A", 1, 2, 3).
+		Factor("B", 10, 20, 30).
+		Noise("N", 0, 1).
+		Array(L9).
+		Repetitions(3).
+		Alpha(0.1).
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if opts.Repetitions != 3 || exp.Alpha != 0.1 || len(exp.OrthogonalArray) != 9 {
+		t.Errorf("repetitions %d, alpha %g, %d rows; want 3, 0.1 and L9", opts.Repetitions, exp.Alpha, len(exp.OrthogonalArray))
+	}
+	if n := len(exp.GenerateTrials()); n != 18 {
+		t.Errorf("%d trials, want 18", n)
+	}
+
+	// Without Array, the smallest fitting array is used.
+	exp, _, err = NewBuilder().Goal(LargerTheBetter{}).Factor("A", 1, 2).Factor("B", 1, 2).Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(exp.OrthogonalArray) != 4 {
+		t.Errorf("%d rows, want L4", len(exp.OrthogonalArray))
+	}
+}
+
+func TestBuilder_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		b    *Builder
+		want string
+		kind error
+	}{
+		{"no goal", NewBuilder().Factor("A", 1, 2), "builder: no goal; call Goal", nil},
+		{"no factors", NewBuilder().Goal(SmallerTheBetter{}), "builder: no control factors", nil},
+		{"goal twice", NewBuilder().Goal(SmallerTheBetter{}).Goal(LargerTheBetter{}), "Goal(Larger-the-Better): goal already set to Smaller-the-Better", nil},
+		{"repeated level", NewBuilder().Goal(SmallerTheBetter{}).Factor("A", 1, 2, 1), `Factor("A"): level 1 repeated`, ErrLevelMismatch},
+		{"one level", NewBuilder().Factor("A", 1), `Factor("A"): needs at least 2 levels, got 1`, ErrLevelMismatch},
+		{"duplicate name", NewBuilder().Factor("A", 1, 2).Noise("A", 0, 1), `Noise("A"): a control factor with this name`, nil},
+		{"unknown array", NewBuilder().Array("L7"), "Array(L7): unknown array; the standard arrays are L4, ", ErrUnknownArray},
+		{"factor past array", NewBuilder().Array(L4).Factor("A", 1, 2).Factor("B", 1, 2).Factor("C", 1, 2).Factor("D", 1, 2),
+			`Factor("D"): array L4 (2^3) has no free 2-level column left`, ErrArrayTooSmall},
+		{"array after factors", NewBuilder().Factor("A", 1, 2, 3).Array(L8), "Array(L8): array (2^7) cannot hold the factors added so far", ErrArrayTooSmall},
+		{"repetitions", NewBuilder().Repetitions(0), "Repetitions(0): must be at least 1", nil},
+		{"first error wins", NewBuilder().Repetitions(0).Factor("A", 1), "Repetitions(0)", nil},
+	} {
+		_, _, err := tc.b.Build()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Build() = %v, want %q", tc.name, err, tc.want)
+		}
+		if tc.kind != nil && !errors.Is(err, tc.kind) {
+			t.Errorf("%s: error %v does not match %v", tc.name, err, tc.kind)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package taguchi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// Bundle file names inside the zip archive written by ExportBundle.
+const (
+	bundleDesign      = "design.json"
+	bundleResults     = "results.json"
+	bundleEnvironment = "environment.json"
+	bundleAnalysis    = "analysis.json"
+	bundleReport      = "report.txt"
+)
+
+// Environment records where an experiment was run.
+// GoVersion, GOOS, GOARCH, NumCPU: Go runtime and platform.
+// Hostname: Name of the machine (empty if unavailable).
+// CreatedAt: When the environment was captured.
+type Environment struct {
+	GoVersion string
+	GOOS      string
+	GOARCH    string
+	NumCPU    int
+	Hostname  string
+	CreatedAt time.Time
+}
+
+// CaptureEnvironment describes the current process environment.
+func CaptureEnvironment() Environment {
+	host, _ := os.Hostname()
+	return Environment{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+		Hostname:  host,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// Bundle is the content of an experiment bundle read by ImportBundle.
+// Design: The experiment definition.
+// Results: Raw trial results.
+// Environment: Where the bundle was exported.
+// Analysis: The analysis as exported; non-finite values (e.g., F-ratios with zero error variance) are zero.
+// Report: The text report as exported.
+type Bundle struct {
+	Design      Design
+	Results     []TrialResult
+	Environment Environment
+	Analysis    AnalysisResult
+	Report      string
+}
+
+// Experiment reconstructs the experiment with its results, so Analyze
+// reproduces the bundled analysis. Results that are not trials of the bundled
+// design are rejected with ErrDesignMismatch.
+func (b *Bundle) Experiment() (*Experiment[struct{}], error) {
+	e, err := b.Design.Experiment()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkResults(b.Results); err != nil {
+		return nil, err
+	}
+	e.Results = b.Results
+	return e, nil
+}
+
+// ExportBundle writes the whole experiment (design, raw results, environment,
+// analysis and text report) as a single zip file, so a colleague can
+// reproduce the analysis from one artifact.
+func (e *Experiment[P]) ExportBundle(w io.Writer) error {
+	analysis, err := e.analyze()
+	if err != nil {
+		return err
+	}
+	var report bytes.Buffer
+	if err := WriteAnalysisReport(&report, analysis, DefaultReportOptions()); err != nil {
+		return err
+	}
+	var results bytes.Buffer
+	if err := e.WriteResults(&results, nil); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data func() ([]byte, error)
+	}{
+		{bundleDesign, func() ([]byte, error) { return json.MarshalIndent(e.Design(), "", "  ") }},
+		{bundleResults, func() ([]byte, error) { return results.Bytes(), nil }},
+		{bundleEnvironment, func() ([]byte, error) { return json.MarshalIndent(CaptureEnvironment(), "", "  ") }},
+		{bundleAnalysis, func() ([]byte, error) { return json.MarshalIndent(finiteJSON(reflect.ValueOf(analysis)), "", "  ") }},
+		{bundleReport, func() ([]byte, error) { return report.Bytes(), nil }},
+	}
+	for _, f := range files {
+		data, err := f.data()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ImportBundle reads a bundle written by ExportBundle. The results checksum is verified.
+func ImportBundle(r io.ReaderAt, size int64) (*Bundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		files[f.Name] = data
+	}
+	for _, name := range []string{bundleDesign, bundleResults, bundleEnvironment, bundleAnalysis, bundleReport} {
+		if _, ok := files[name]; !ok {
+			return nil, fmt.Errorf("bundle is missing %s", name)
+		}
+	}
+
+	b := &Bundle{Report: string(files[bundleReport])}
+	if err := json.Unmarshal(files[bundleDesign], &b.Design); err != nil {
+		return nil, fmt.Errorf("%s: %w", bundleDesign, err)
+	}
+	if b.Results, err = ReadResults(bytes.NewReader(files[bundleResults]), nil); err != nil {
+		return nil, fmt.Errorf("%s: %w", bundleResults, err)
+	}
+	if err := json.Unmarshal(files[bundleEnvironment], &b.Environment); err != nil {
+		return nil, fmt.Errorf("%s: %w", bundleEnvironment, err)
+	}
+	if err := json.Unmarshal(files[bundleAnalysis], &b.Analysis); err != nil {
+		return nil, fmt.Errorf("%s: %w", bundleAnalysis, err)
+	}
+	return b, nil
+}
+
+// finiteJSON converts v into a JSON-encodable tree in which non-finite floats
+// (which encoding/json rejects) become null.
+func finiteJSON(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil
+		}
+		return f
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return v.Interface()
+		}
+		out := map[string]any{}
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() && f.Tag.Get("json") != "-" {
+				out[f.Name] = finiteJSON(v.Field(i))
+			}
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := map[string]any{}
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = finiteJSON(iter.Value())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = finiteJSON(v.Index(i))
+		}
+		return out
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return finiteJSON(v.Elem())
+	}
+	return v.Interface()
+}
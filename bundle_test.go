@@ -0,0 +1,42 @@
+package taguchi
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestBundle_RoundTrip verifies that an imported bundle reproduces the
+// exported analysis.
+func TestBundle_RoundTrip(t *testing.T) {
+	exp := retentionExperiment(t, NominalTheBest{Target: 5}, RetainAll, "")
+	want := mustAnalyze(t, exp)
+
+	var buf bytes.Buffer
+	if err := exp.ExportBundle(&buf); err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+	b, err := ImportBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	if b.Report == "" || b.Environment.GoVersion == "" {
+		t.Error("bundle is missing the report or environment")
+	}
+	if got := b.Analysis.SNR["A"][0]; !almostEqual(got, want.SNR["A"][0]) {
+		t.Errorf("bundled SNR[A][0] = %.4f, want %.4f", got, want.SNR["A"][0])
+	}
+
+	imported, err := b.Experiment()
+	if err != nil {
+		t.Fatalf("Bundle.Experiment: %v", err)
+	}
+	if goal, ok := imported.Goal.(NominalTheBest); !ok || goal.Target != 5 {
+		t.Errorf("imported goal = %#v, want NominalTheBest{Target: 5}", imported.Goal)
+	}
+	got := mustAnalyze(t, imported)
+	for level, w := range want.SNR["A"] {
+		if g := got.SNR["A"][level]; !almostEqual(g, w) {
+			t.Errorf("reproduced SNR[A][%d] = %.4f, want %.4f", level, g, w)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package taguchi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// checkpointVersion is the format version written by SaveJSON.
+const checkpointVersion = 1
+
+// experimentState is the JSON checkpoint of an experiment.
+type experimentState struct {
+	Version    int
+	Design     Design
+	Retention  RetentionPolicy
+	SpillDir   string
+	SampleSize int
+	Results    []TrialResult
+}
+
+// SaveJSON writes the full experiment state (goal, factors, array, analysis
+// settings and collected results) as JSON, so a long-running experiment can
+// be checkpointed and resumed after a crash. Spilled observations are read
+// back and stored inline.
+func (e *Experiment[P]) SaveJSON(w io.Writer) error {
+	results, err := e.portableResults()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(experimentState{
+		Version:    checkpointVersion,
+		Design:     e.Design(),
+		Retention:  e.Retention,
+		SpillDir:   e.SpillDir,
+		SampleSize: e.SampleSize,
+		Results:    results,
+	})
+}
+
+// SaveCheckpoint writes the experiment state like SaveJSON to the file at
+// path. The state is written to a temporary file that then replaces path, so
+// an interruption never leaves a truncated checkpoint behind.
+func (e *Experiment[P]) SaveCheckpoint(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := e.SaveJSON(f); err != nil {
+		f.Close()
+		return fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadExperimentJSON restores an experiment written by SaveJSON, rebuilding
+// it entirely from the file; use RestoreJSON to load the results into an
+// experiment constructed in code instead. Results that are not trials of the
+// saved design are rejected with ErrDesignMismatch. To continue the run, pass
+// RunOptions{SkipCompleted: true} to Run so trials that already have results
+// are not repeated.
+func LoadExperimentJSON(r io.Reader) (*Experiment[struct{}], error) {
+	var state experimentState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("decoding experiment: %w", err)
+	}
+	if state.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported experiment format version %d", state.Version)
+	}
+	e, err := state.Design.Experiment()
+	if err != nil {
+		return nil, err
+	}
+	e.Retention = state.Retention
+	e.SpillDir = state.SpillDir
+	e.SampleSize = state.SampleSize
+	if err := e.checkResults(state.Results); err != nil {
+		return nil, err
+	}
+	e.Results = state.Results
+	return e, nil
+}
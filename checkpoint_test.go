@@ -0,0 +1,120 @@
+package taguchi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveJSON_Resume verifies that an interrupted run can be checkpointed,
+// restored and completed without repeating finished trials.
+func TestSaveJSON_Resume(t *testing.T) {
+	exp := parallelExperiment(t)
+	crash := errors.New("crash")
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		if trial.ID == 5 {
+			return 0, crash
+		}
+		return trial.Control["A"] + trial.Control["B"], nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{}); !errors.Is(err, crash) {
+		t.Fatalf("Run error = %v, want crash", err)
+	}
+
+	var buf bytes.Buffer
+	if err := exp.SaveJSON(&buf); err != nil {
+		t.Fatalf("SaveJSON: %v", err)
+	}
+	restored, err := LoadExperimentJSON(&buf)
+	if err != nil {
+		t.Fatalf("LoadExperimentJSON: %v", err)
+	}
+	if len(restored.Results) != 4 {
+		t.Fatalf("restored %d results, want 4", len(restored.Results))
+	}
+
+	calls := 0
+	resume := func(_ context.Context, trial Trial) (float64, error) {
+		calls++
+		return trial.Control["A"] + trial.Control["B"], nil
+	}
+	if err := restored.Run(context.Background(), resume, RunOptions{SkipCompleted: true}); err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if calls != 4 || len(restored.Results) != 8 {
+		t.Errorf("resume measured %d trials for %d results, want 4 and 8", calls, len(restored.Results))
+	}
+}
+
+// TestRun_InterruptFlushesCheckpoint verifies that cancelling a run finishes
+// the in-flight trial, flushes the results to the checkpoint file and
+// reports how to resume.
+func TestRun_InterruptFlushesCheckpoint(t *testing.T) {
+	exp := parallelExperiment(t)
+	path := filepath.Join(t.TempDir(), "experiment.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		if trial.ID == 3 {
+			cancel() // e.g. SIGINT while the third trial is measured
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return trial.Control["A"] + trial.Control["B"], nil
+	}
+	opts := RunOptions{Repetitions: 2, Checkpoint: path, FinishInFlight: true}
+	err := exp.Run(ctx, measure, opts)
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want an InterruptedError wrapping context.Canceled", err)
+	}
+	if interrupted.Completed != 3 || interrupted.Remaining != 5 || interrupted.Checkpoint != path {
+		t.Errorf("InterruptedError = %+v, want 3 completed and 5 remaining", interrupted)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("checkpoint not written: %v", err)
+	}
+	defer f.Close()
+	restored, err := LoadExperimentJSON(f)
+	if err != nil {
+		t.Fatalf("LoadExperimentJSON: %v", err)
+	}
+	if len(restored.Results) != 3 {
+		t.Fatalf("checkpoint holds %d results, want 3", len(restored.Results))
+	}
+	opts.SkipCompleted = true
+	if err := restored.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if len(restored.Results) != 8 {
+		t.Errorf("resumed run has %d results, want 8", len(restored.Results))
+	}
+}
+
+// TestRun_InterruptAbortsInFlightTrial verifies that without FinishInFlight
+// the interrupted trial is discarded rather than recorded partially.
+func TestRun_InterruptAbortsInFlightTrial(t *testing.T) {
+	exp := parallelExperiment(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		if trial.ID == 3 {
+			cancel()
+		}
+		return 1, ctx.Err()
+	}
+	err := exp.Run(ctx, measure, RunOptions{Repetitions: 2})
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Completed != 2 {
+		t.Fatalf("Run error = %v, want an InterruptedError after 2 trials", err)
+	}
+	if len(exp.Results) != 2 {
+		t.Errorf("len(Results) = %d, want 2", len(exp.Results))
+	}
+}
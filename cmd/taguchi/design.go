@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+func design(args []string) error {
+	fs := flag.NewFlagSet("design", flag.ExitOnError)
+	specPath := fs.String("spec", "", "YAML factor spec")
+	repetitions := fs.Int("repetitions", 0, "observation columns per trial (the spec's repetitions, or 1)")
+	out := fs.String("o", "", "output CSV (standard output when empty)")
+	_ = fs.Parse(args)
+	if *specPath == "" || *repetitions < 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	exp, opts, err := loadDesign(*specPath)
+	if err != nil {
+		return err
+	}
+	if *repetitions == 0 {
+		*repetitions = max(opts.Repetitions, 1)
+	}
+	w, closeOut, err := create(*out)
+	if err != nil {
+		return err
+	}
+	if err := writeTrials(w, exp, *repetitions); err != nil {
+		closeOut()
+		return err
+	}
+	return closeOut()
+}
+
+// writeTrials writes the trial matrix in the layout read back by
+// AddResultsCSV: the control and noise levels of every trial followed by
+// observation columns Obs1, Obs2, ..., at least repetitions of them. The
+// observations of recorded results are filled in; the other cells are left
+// empty.
+func writeTrials(w io.Writer, exp *taguchi.Experiment[struct{}], repetitions int) error {
+	observations := map[int][]float64{}
+	for _, r := range exp.Results {
+		observations[r.Trial.ID] = append(observations[r.Trial.ID], r.Observations...)
+		repetitions = max(repetitions, len(observations[r.Trial.ID]))
+	}
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, f := range exp.ControlFactors {
+		header = append(header, f.Name)
+	}
+	for _, f := range exp.NoiseFactors {
+		header = append(header, f.Name)
+	}
+	for i := 1; i <= repetitions; i++ {
+		header = append(header, fmt.Sprintf("Obs%d", i))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, trial := range exp.GenerateTrials() {
+		var record []string
+		for _, f := range exp.ControlFactors {
+			record = append(record, strconv.FormatFloat(trial.Control[f.Name], 'g', -1, 64))
+		}
+		for _, f := range exp.NoiseFactors {
+			record = append(record, strconv.FormatFloat(trial.Noise[f.Name], 'g', -1, 64))
+		}
+		for i := 0; i < repetitions; i++ {
+			cell := ""
+			if obs := observations[trial.ID]; i < len(obs) {
+				cell = strconv.FormatFloat(obs[i], 'g', -1, 64)
+			}
+			record = append(record, cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
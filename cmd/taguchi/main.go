@@ -0,0 +1,168 @@
+// Command taguchi designs and analyzes Taguchi experiments from the command
+// line, so teams that do not write Go can use the engine.
+//
+// Usage:
+//
+//	taguchi design -spec spec.yaml [-repetitions n] [-o trials.csv]
+//	taguchi analyze -design spec.yaml -results results.csv [-decimals n | -significant n] [-locale name] [-thousands sep] [-allow-incomplete]
+//	taguchi report -design spec.yaml -results results.csv [-o report.html|.md|.xlsx] [-decimals n | -significant n] [-locale name] [-thousands sep] [-allow-incomplete]
+//	taguchi run -spec spec.yaml [-repetitions n] [-pattern re | -json path] [-env NAME=TEMPLATE ...] [-o results.csv] command [args...]
+//
+// The design mode reads a YAML factor spec (see taguchi.Spec) and writes the
+// trial matrix as CSV: one line per trial with a column per control and
+// noise factor, followed by empty observation columns to fill in, one per
+// repetition of the spec unless -repetitions is given. The filled-in file is
+// the results CSV of the analyze and report modes.
+//
+// The analyze mode prints the SNR per level, ANOVA and optimal levels as a
+// text report; report writes the same analysis as a standalone HTML page
+// with main-effects plots, or as Markdown or an Excel workbook when the -o
+// file ends in .md or .xlsx. Both also work on data produced without this
+// library: -design takes either a YAML spec or design.json holding a
+// taguchi.Design, and results.csv has one line per run with a column per
+// control factor followed by observation columns. Designs with unmeasured
+// array rows are rejected unless -allow-incomplete is given, which imputes
+// their SNR and lists them as warnings. The number flags format every
+// number of the report: -decimals n or -significant n digits, and the
+// separators of -locale (plain, english, german, french or swiss) with an
+// optional -thousands separator overriding the locale's.
+//
+// The run mode measures every trial by running an external command, so
+// programs in any language can be tuned. The command's arguments and the
+// -env values are templates over the trial's levels, e.g. -threads={{.Threads}}
+// (see execrunner.Command); the observation is the command's output, or the
+// part of it located by -pattern or -json. The results are written as a
+// filled-in trial CSV for analyze and report. An interrupted run (Ctrl-C)
+// still writes the trials measured so far.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "design":
+		err = design(os.Args[2:])
+	case "analyze":
+		err = analyze(os.Args[2:])
+	case "report":
+		err = report(os.Args[2:])
+	case "run":
+		err = run(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "taguchi:", err)
+		if h := hint(err); h != "" {
+			fmt.Fprintln(os.Stderr, "hint:", h)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage:
+  taguchi design -spec spec.yaml [-repetitions n] [-o trials.csv]
+  taguchi analyze -design spec.yaml -results results.csv [-decimals n | -significant n] [-locale name] [-thousands sep] [-allow-incomplete]
+  taguchi report -design spec.yaml -results results.csv [-o report.html|.md|.xlsx] [-decimals n | -significant n] [-locale name] [-thousands sep] [-allow-incomplete]
+  taguchi run -spec spec.yaml [-repetitions n] [-pattern re | -json path] [-env NAME=TEMPLATE ...] [-o results.csv] command [args...]`)
+	os.Exit(2)
+}
+
+// hint suggests how to fix a failed run, or returns "" when the error has no
+// known cause.
+func hint(err error) string {
+	var missing *taguchi.MissingResultsError
+	switch {
+	case errors.As(err, &missing):
+		return "record results for these rows, or pass -allow-incomplete to impute them"
+	case errors.Is(err, taguchi.ErrUnknownArray):
+		var names []string
+		for _, info := range taguchi.StandardArrayInfos() {
+			names = append(names, string(info.Name))
+		}
+		return "the standard arrays are " + strings.Join(names, ", ")
+	case errors.Is(err, taguchi.ErrArrayTooSmall):
+		return "use a larger array, or leave array out of the spec to pick the smallest that fits"
+	case errors.Is(err, taguchi.ErrTrialNotInDesign), errors.Is(err, taguchi.ErrLevelMismatch):
+		return "the results must use the factors and levels of the design; regenerate the trials with taguchi design"
+	case errors.Is(err, taguchi.ErrInvalidObservations):
+		return "every results line needs at least one finite observation"
+	}
+	return ""
+}
+
+// create opens the output file of a subcommand, or standard output when path
+// is empty. The returned function closes it.
+func create(path string) (io.Writer, func() error, error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// numberFlags defines the number format flags of the report modes on fs. The
+// returned function builds the format from the parsed flags.
+func numberFlags(fs *flag.FlagSet) func() (taguchi.NumberFormat, error) {
+	decimals := fs.Int("decimals", taguchi.DefaultNumberFormat.Decimals, "decimals in the report")
+	significant := fs.Int("significant", 0, "significant digits in the report instead of -decimals")
+	locale := fs.String("locale", "plain", "decimal and thousands separators: plain, english, german, french or swiss")
+	thousands := fs.String("thousands", "", "thousands separator, overriding the one of -locale; empty disables grouping")
+	return func() (taguchi.NumberFormat, error) {
+		l, err := taguchi.ParseLocale(*locale)
+		if err != nil {
+			return taguchi.NumberFormat{}, err
+		}
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "thousands" {
+				l.Thousands = *thousands
+			}
+		})
+		return taguchi.NumberFormat{Decimals: *decimals, SignificantDigits: *significant, Locale: l}, nil
+	}
+}
+
+func analyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	designPath := fs.String("design", "", "YAML spec or JSON taguchi.Design")
+	resultsPath := fs.String("results", "", "results CSV")
+	numbers := numberFlags(fs)
+	allowIncomplete := fs.Bool("allow-incomplete", false, "impute array rows without results instead of failing")
+	_ = fs.Parse(args)
+	if *designPath == "" || *resultsPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	exp, err := loadResults(*designPath, *resultsPath, *allowIncomplete)
+	if err != nil {
+		return err
+	}
+	result, err := exp.Analyze()
+	if err != nil {
+		return err
+	}
+
+	nf, err := numbers()
+	if err != nil {
+		return err
+	}
+	return taguchi.WriteAnalysisReport(os.Stdout, result, taguchi.ReportOptions{Numbers: nf})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+func report(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	designPath := fs.String("design", "", "YAML spec or JSON taguchi.Design")
+	resultsPath := fs.String("results", "", "results CSV")
+	out := fs.String("o", "", "output HTML, or Markdown (.md) or Excel (.xlsx) by extension (HTML to standard output when empty)")
+	numbers := numberFlags(fs)
+	allowIncomplete := fs.Bool("allow-incomplete", false, "impute array rows without results instead of failing")
+	_ = fs.Parse(args)
+	if *designPath == "" || *resultsPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	exp, err := loadResults(*designPath, *resultsPath, *allowIncomplete)
+	if err != nil {
+		return err
+	}
+	result, err := exp.Analyze()
+	if err != nil {
+		return err
+	}
+	nf, err := numbers()
+	if err != nil {
+		return err
+	}
+
+	w, closeOut, err := create(*out)
+	if err != nil {
+		return err
+	}
+	write := func(w io.Writer) error { return exp.WriteHTMLReport(w, result, taguchi.ReportOptions{Numbers: nf}) }
+	switch strings.ToLower(filepath.Ext(*out)) {
+	case ".md":
+		write = func(w io.Writer) error {
+			return taguchi.WriteMarkdownReport(w, result, taguchi.ReportOptions{Numbers: nf})
+		}
+	case ".xlsx":
+		write = func(w io.Writer) error {
+			return taguchi.WriteExcelReport(w, result, taguchi.ReportOptions{Numbers: nf})
+		}
+	}
+	if err := write(w); err != nil {
+		closeOut()
+		return err
+	}
+	return closeOut()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+
+	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/execrunner"
+)
+
+// envFlags collects repeated -env NAME=TEMPLATE flags.
+type envFlags map[string]string
+
+func (e envFlags) String() string { return "" }
+
+func (e envFlags) Set(v string) error {
+	name, value, ok := strings.Cut(v, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("want NAME=TEMPLATE, got %q", v)
+	}
+	e[name] = value
+	return nil
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	specPath := fs.String("spec", "", "YAML spec or JSON taguchi.Design")
+	repetitions := fs.Int("repetitions", 0, "measured runs per trial (the spec's repetitions, or 1)")
+	pattern := fs.String("pattern", "", "regular expression locating the observation in the command's output")
+	jsonPath := fs.String("json", "", "JSON path of the observation in the command's output, e.g. $.latency.p99")
+	env := envFlags{}
+	fs.Var(env, "env", "environment variable template NAME=TEMPLATE, e.g. GOMAXPROCS={{.Threads}} (repeatable)")
+	out := fs.String("o", "", "output results CSV (standard output when empty)")
+	_ = fs.Parse(args)
+	if *specPath == "" || *repetitions < 0 || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	exp, opts, err := loadDesign(*specPath)
+	if err != nil {
+		return err
+	}
+	if *repetitions > 0 {
+		opts.Repetitions = *repetitions
+	}
+	cmd := &execrunner.Command{Path: fs.Arg(0), Args: fs.Args()[1:], Env: env, JSONPath: *jsonPath, Stderr: os.Stderr}
+	if *pattern != "" {
+		if cmd.Pattern, err = regexp.Compile(*pattern); err != nil {
+			return fmt.Errorf("-pattern: %w", err)
+		}
+	}
+	measure, err := cmd.Measure()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	runErr := exp.Run(ctx, measure, opts)
+	var interrupted *taguchi.InterruptedError
+	if runErr != nil && !errors.As(runErr, &interrupted) {
+		return runErr
+	}
+	// The results of an interrupted run are still written, so the measured
+	// trials are not lost.
+	w, closeOut, err := create(*out)
+	if err != nil {
+		return errors.Join(runErr, err)
+	}
+	if err := writeTrials(w, exp, max(opts.Repetitions, 1)); err != nil {
+		closeOut()
+		return errors.Join(runErr, err)
+	}
+	return errors.Join(runErr, closeOut())
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// loadDesign reads the experiment from a YAML spec (.yaml or .yml, see
+// taguchi.Spec) or from a JSON taguchi.Design.
+func loadDesign(path string) (*taguchi.Experiment[struct{}], taguchi.RunOptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, taguchi.RunOptions{}, err
+	}
+	var exp *taguchi.Experiment[struct{}]
+	var opts taguchi.RunOptions
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		exp, opts, err = taguchi.LoadSpec(bytes.NewReader(data))
+	default:
+		var design taguchi.Design
+		if err = json.Unmarshal(data, &design); err == nil {
+			exp, err = design.Experiment()
+		}
+	}
+	if err != nil {
+		return nil, taguchi.RunOptions{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return exp, opts, nil
+}
+
+// loadResults builds the experiment of the design file and records the
+// results CSV into it.
+func loadResults(designPath, resultsPath string, allowIncomplete bool) (*taguchi.Experiment[struct{}], error) {
+	exp, _, err := loadDesign(designPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(resultsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := exp.AddResultsCSV(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", resultsPath, err)
+	}
+	exp.AllowIncomplete = exp.AllowIncomplete || allowIncomplete
+	return exp, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// TestDesignAnalyzeReport runs the CLI workflow: the trial CSV is filled in
+// and read back as results, then rendered as an HTML report.
+func TestDesignAnalyzeReport(t *testing.T) {
+	exp, _, err := taguchi.LoadSpec(strings.NewReader("goal: STB\nfactors:\n  - {name: A, levels: [1, 2]}\n  - {name: B, levels: [10, 20]}\n"))
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	var trials bytes.Buffer
+	if err := writeTrials(&trials, exp, 2); err != nil {
+		t.Fatalf("writeTrials: %v", err)
+	}
+	records, err := csv.NewReader(&trials).ReadAll()
+	if err != nil {
+		t.Fatalf("reading trials: %v", err)
+	}
+	if got := strings.Join(records[0], ","); got != "A,B,Obs1,Obs2" {
+		t.Errorf("header = %s, want A,B,Obs1,Obs2", got)
+	}
+	var filled bytes.Buffer
+	cw := csv.NewWriter(&filled)
+	cw.Write(records[0])
+	for _, r := range records[1:] {
+		cw.Write([]string{r[0], r[1], r[0], r[0] + ".5"})
+	}
+	cw.Flush()
+
+	if err := exp.AddResultsCSV(&filled); err != nil {
+		t.Fatalf("AddResultsCSV: %v", err)
+	}
+	result, err := exp.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	var html bytes.Buffer
+	if err := exp.WriteHTMLReport(&html, result, taguchi.DefaultReportOptions()); err != nil {
+		t.Fatalf("WriteHTMLReport: %v", err)
+	}
+	for _, want := range []string{"<svg", "<td>A</td><td>1</td>", "<h2>ANOVA</h2>", "TAGUCHI ANALYSIS REPORT"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+}
+
+// TestRun measures a design with a shell command and analyzes the written
+// results.
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh in PATH")
+	}
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.yaml")
+	results := filepath.Join(dir, "results.csv")
+	if err := os.WriteFile(spec, []byte("goal: STB\nrepetitions: 2\nfactors:\n  - {name: A, levels: [1, 2]}\n  - {name: B, levels: [10, 20]}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := run([]string{"-spec", spec, "-o", results, "-env", "B={{.B}}", "-pattern", `took (\d+)`,
+		"sh", "-c", `echo "took $(($1 + B))ms"`, "sh", "{{.A}}"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	exp, err := loadResults(spec, results, false)
+	if err != nil {
+		t.Fatalf("loadResults: %v", err)
+	}
+	for _, r := range exp.Results {
+		want := r.Trial.Control["A"] + r.Trial.Control["B"]
+		if len(r.Observations) != 2 || r.Observations[0] != want {
+			t.Errorf("%v: observations %v, want [%g %g]", r.Trial.Control, r.Observations, want, want)
+		}
+	}
+	result, err := exp.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if result.OptimalLevels["A"] != 1 || result.OptimalLevels["B"] != 10 {
+		t.Errorf("optimal levels %v, want A=1 B=10", result.OptimalLevels)
+	}
+}
+
+func TestHint(t *testing.T) {
+	exp, err := taguchi.NewExperimentFromFactors(taguchi.SmallerTheBetter{}, []taguchi.ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}, taguchi.L4, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	_, err = exp.Analyze()
+	if h := hint(err); !strings.Contains(h, "-allow-incomplete") {
+		t.Errorf("hint(%v) = %q, want a pointer to -allow-incomplete", err, h)
+	}
+	_, err = taguchi.NewExperimentFromFactors(taguchi.SmallerTheBetter{}, nil, "L5", nil)
+	if h := hint(err); !strings.Contains(h, "L4, L8") {
+		t.Errorf("hint(%v) = %q, want the standard arrays listed", err, h)
+	}
+	if h := hint(os.ErrNotExist); h != "" {
+		t.Errorf("hint(os.ErrNotExist) = %q, want none", h)
+	}
+}
+
+// TestNumberFlags verifies that the number format flags build the format of
+// the report modes.
+func TestNumberFlags(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want taguchi.NumberFormat
+	}{
+		{nil, taguchi.DefaultNumberFormat},
+		{[]string{"-decimals", "2", "-locale", "german"}, taguchi.NumberFormat{Decimals: 2, Locale: taguchi.LocaleGerman}},
+		{[]string{"-significant", "3", "-locale", "english", "-thousands", ""}, taguchi.NumberFormat{Decimals: 4, SignificantDigits: 3, Locale: taguchi.Locale{Decimal: "."}}},
+		{[]string{"-thousands", "_"}, taguchi.NumberFormat{Decimals: 4, Locale: taguchi.Locale{Decimal: ".", Thousands: "_"}}},
+	} {
+		fs := flag.NewFlagSet("report", flag.ContinueOnError)
+		numbers := numberFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := numbers(); err != nil || got != tt.want {
+			t.Errorf("%q: %+v, %v; want %+v", tt.args, got, err, tt.want)
+		}
+	}
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	numbers := numberFlags(fs)
+	fs.Parse([]string{"-locale", "klingon"})
+	if _, err := numbers(); err == nil {
+		t.Error("-locale klingon: want error")
+	}
+}
@@ -0,0 +1,171 @@
+package taguchi
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AssignColumns maps each control factor to an array column with the same
+// number of levels, so mixed-level arrays such as L18 (2^1 × 3^7) or L36
+// (2^11 × 3^12) can host 2-level and 3-level factors side by side. Factors are
+// assigned in order to the first free compatible column; when the factors
+// already line up with the leading columns the assignment is the identity.
+func AssignColumns(factors []ControlFactor, oa [][]int) ([]int, error) {
+	info := DescribeArray(oa)
+	used := make([]bool, len(info.Levels))
+	columns := make([]int, len(factors))
+	for j, factor := range factors {
+		columns[j] = -1
+		for c, l := range info.Levels {
+			if !used[c] && l == len(factor.Levels) {
+				columns[j] = c
+				used[c] = true
+				break
+			}
+		}
+		if columns[j] < 0 {
+			return nil, errorf(ErrArrayTooSmall, "no free %d-level column for factor %s (array is %s)", len(factor.Levels), factor.Name, info.Notation())
+		}
+	}
+	return columns, nil
+}
+
+// ValidateColumns checks an explicit factor-to-column assignment: every factor
+// needs a distinct column of the array whose level count equals its own.
+func ValidateColumns(factors []ControlFactor, oa [][]int, columns []int) error {
+	if len(columns) != len(factors) {
+		return fmt.Errorf("got %d columns for %d factors", len(columns), len(factors))
+	}
+	info := DescribeArray(oa)
+	owner := map[int]string{}
+	for j, c := range columns {
+		name := factors[j].Name
+		if c < 0 || c >= len(info.Levels) {
+			return fmt.Errorf("factor %s: column %d out of range (array has %d columns)", name, c+1, len(info.Levels))
+		}
+		if prev, ok := owner[c]; ok {
+			return fmt.Errorf("factor %s: column %d already assigned to %s", name, c+1, prev)
+		}
+		owner[c] = name
+		if info.Levels[c] != len(factors[j].Levels) {
+			return errorf(ErrLevelMismatch, "factor %s has %d levels but column %d has %d", name, len(factors[j].Levels), c+1, info.Levels[c])
+		}
+	}
+	return nil
+}
+
+// SetColumns assigns control factors to explicit array columns (zero-based),
+// e.g. to follow a column layout from a linear graph. The layout is checked
+// against the declared interactions and ErrorColumns as in AssignColumn.
+func (e *Experiment[P]) SetColumns(columns []int) error {
+	return e.setLayout(columns)
+}
+
+// AssignColumn assigns the named control factor to array column c
+// (zero-based), instead of the first free column with its number of levels.
+// The column must have as many levels as the factor and must not be used by
+// another factor, a declared interaction or ErrorColumns. The columns of the
+// factor's interactions are looked up again in the array's interaction
+// table. Factors can only move before results are recorded.
+func (e *Experiment[P]) AssignColumn(name string, c int) error {
+	j := e.factorIndex(name)
+	if j < 0 {
+		return errorf(ErrUnknownFactor, "unknown factor %s", name)
+	}
+	columns := e.assignedColumns()
+	columns[j] = c
+	return e.setLayout(columns)
+}
+
+// ReserveErrorColumns reserves array columns (zero-based) for error
+// estimation, keeping them free of factors and interactions.
+func (e *Experiment[P]) ReserveErrorColumns(columns ...int) error {
+	width := len(DescribeArray(e.OrthogonalArray).Levels)
+	for _, c := range columns {
+		if c < 0 || c >= width {
+			return fmt.Errorf("error column %d out of range (array has %d columns)", c+1, width)
+		}
+	}
+	reserved := e.ErrorColumns
+	e.ErrorColumns = append(slices.Clone(reserved), columns...)
+	if _, err := e.layoutInteractions(e.assignedColumns()); err != nil {
+		e.ErrorColumns = reserved
+		return err
+	}
+	return nil
+}
+
+// assignedColumns returns a copy of the column of every control factor.
+func (e *Experiment[P]) assignedColumns() []int {
+	columns := make([]int, len(e.ControlFactors))
+	for j := range columns {
+		columns[j] = e.column(j)
+	}
+	return columns
+}
+
+// setLayout moves the control factors to the given columns and their
+// interactions to the matching interaction columns.
+func (e *Experiment[P]) setLayout(columns []int) error {
+	interactions, err := e.layoutInteractions(columns)
+	if err != nil {
+		return err
+	}
+	if len(e.Results) > 0 && !slices.Equal(columns, e.assignedColumns()) {
+		return fmt.Errorf("factors would move to other columns but results are already recorded")
+	}
+	e.Columns = slices.Clone(columns)
+	if len(interactions) > 0 {
+		e.Interactions = interactions
+	}
+	return nil
+}
+
+// layoutInteractions validates a factor-to-column assignment against the
+// declared interactions and ErrorColumns: every column may be used by one
+// factor, one interaction or the error term only. It returns the
+// interactions with their columns looked up for the new assignment.
+func (e *Experiment[P]) layoutInteractions(columns []int) ([]Interaction, error) {
+	if err := ValidateColumns(e.ControlFactors, e.OrthogonalArray, columns); err != nil {
+		return nil, err
+	}
+	owner := map[int]string{}
+	for j, c := range columns {
+		owner[c] = "factor " + e.ControlFactors[j].Name
+	}
+	for _, c := range e.ErrorColumns {
+		if prev, ok := owner[c]; ok {
+			return nil, fmt.Errorf("error column %d is already used by %s", c+1, prev)
+		}
+		owner[c] = "the error term"
+	}
+	var interactions []Interaction
+	for _, in := range e.Interactions {
+		ca, cb := columns[e.factorIndex(in.A)], columns[e.factorIndex(in.B)]
+		in.Columns = InteractionColumns(e.OrthogonalArray, ca, cb)
+		if len(in.Columns) == 0 {
+			return nil, fmt.Errorf("interaction %s: columns %d and %d have no interaction columns in this array", in.Name(), ca+1, cb+1)
+		}
+		for _, c := range in.Columns {
+			if prev, ok := owner[c]; ok {
+				return nil, fmt.Errorf("interaction %s: column %d is already used by %s", in.Name(), c+1, prev)
+			}
+			owner[c] = "interaction " + in.Name()
+		}
+		interactions = append(interactions, in)
+	}
+	return interactions, nil
+}
+
+// column returns the array column assigned to control factor j.
+func (e *Experiment[P]) column(j int) int {
+	if e.Columns == nil {
+		return j
+	}
+	return e.Columns[j]
+}
+
+// levelIndex returns the zero-based level of control factor j in array row i.
+func (e *Experiment[P]) levelIndex(i, j int) int {
+	return e.OrthogonalArray[i][e.column(j)] - 1
+}
@@ -0,0 +1,221 @@
+package taguchi
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAssignColumns_MixedLevels(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2, 3}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L18, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	want := []int{1, 0, 2}
+	for j, c := range want {
+		if exp.Columns[j] != c {
+			t.Fatalf("Columns = %v, want %v", exp.Columns, want)
+		}
+	}
+	if b := exp.Balance(); !b.Orthogonal {
+		t.Errorf("mixed-level assignment on L18 is not balanced: %+v", b.LevelCounts)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		if v := trial.Control["B"]; v != 1 && v != 2 {
+			t.Errorf("trial %d: B = %v, want a 2-level value", trial.ID, v)
+		}
+	}
+}
+
+func TestAssignColumns_RejectsIncompatible(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2, 3, 4}},
+	}
+	if _, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, nil); err == nil {
+		t.Error("expected error for a 4-level factor on L9")
+	}
+
+	twoLevel := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	if _, err := NewExperimentFromFactors(SmallerTheBetter{}, twoLevel, L18, nil); err == nil {
+		t.Error("expected error for two 2-level factors on L18")
+	}
+}
+
+func TestSetColumns_Validation(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L18, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	if err := exp.SetColumns([]int{0, 5}); err != nil {
+		t.Errorf("SetColumns([0 5]): %v", err)
+	}
+	for _, columns := range [][]int{{1, 2}, {0, 0}, {0, 8}, {0}} {
+		if err := exp.SetColumns(columns); err == nil {
+			t.Errorf("SetColumns(%v): expected error", columns)
+		}
+	}
+}
+
+func TestAssignColumn_Interactions(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	if err := exp.AddInteraction("A", "B"); err != nil {
+		t.Fatalf("AddInteraction: %v", err)
+	}
+	// C was moved off column 3, which carries A×B.
+	if !slices.Equal(exp.Columns, []int{0, 1, 3}) {
+		t.Fatalf("Columns = %v, want [0 1 3]", exp.Columns)
+	}
+	for _, c := range []int{0, 2} {
+		if err := exp.AssignColumn("C", c); err == nil {
+			t.Errorf("AssignColumn(C, %d): expected error for a used column", c)
+		}
+	}
+	if err := exp.AssignColumn("C", 6); err != nil {
+		t.Fatalf("AssignColumn(C, 6): %v", err)
+	}
+	// Columns 1 and 4 of L8 interact in column 5.
+	if err := exp.AssignColumn("B", 3); err != nil {
+		t.Fatalf("AssignColumn(B, 3): %v", err)
+	}
+	if !slices.Equal(exp.Columns, []int{0, 3, 6}) || !slices.Equal(exp.Interactions[0].Columns, []int{4}) {
+		t.Errorf("Columns = %v, A×B in %v; want [0 3 6] and [4]", exp.Columns, exp.Interactions[0].Columns)
+	}
+	if err := exp.AssignColumn("D", 1); err == nil {
+		t.Error("AssignColumn(D): expected error for an unknown factor")
+	}
+
+	trial := exp.GenerateTrials()[0]
+	if err := exp.AddResult(trial, []float64{1}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	if err := exp.AssignColumn("C", 5); err == nil {
+		t.Error("AssignColumn after recording results: expected error")
+	}
+	if err := exp.AssignColumn("C", 6); err != nil {
+		t.Errorf("AssignColumn to the current column: %v", err)
+	}
+}
+
+func TestReserveErrorColumns(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, c := range []int{2, 7, -1} {
+		if err := exp.ReserveErrorColumns(c); err == nil {
+			t.Errorf("ReserveErrorColumns(%d): expected error", c)
+		}
+	}
+	if err := exp.ReserveErrorColumns(5, 6); err != nil {
+		t.Fatalf("ReserveErrorColumns: %v", err)
+	}
+	if err := exp.ReserveErrorColumns(6); err == nil {
+		t.Error("reserving a column twice: expected error")
+	}
+	if !slices.Equal(exp.ErrorColumns, []int{5, 6}) {
+		t.Errorf("ErrorColumns = %v, want [5 6] after the failed reservations", exp.ErrorColumns)
+	}
+	if err := exp.AssignColumn("C", 5); err == nil {
+		t.Error("AssignColumn onto an error column: expected error")
+	}
+	// Columns 2 and 3 of L8 interact in column 6, which is reserved.
+	if err := exp.AssignColumn("C", 3); err != nil {
+		t.Fatalf("AssignColumn(C, 3): %v", err)
+	}
+	if err := exp.AddInteraction("B", "C"); err == nil {
+		t.Error("AddInteraction onto an error column: expected error")
+	}
+	if err := exp.AddInteraction("A", "B"); err != nil {
+		t.Errorf("AddInteraction(A, B): %v", err)
+	}
+
+	reloaded, err := exp.Design().Experiment()
+	if err != nil {
+		t.Fatalf("Design().Experiment(): %v", err)
+	}
+	if !slices.Equal(reloaded.ErrorColumns, exp.ErrorColumns) || !slices.Equal(reloaded.Columns, exp.Columns) {
+		t.Errorf("reloaded columns %v and error columns %v", reloaded.Columns, reloaded.ErrorColumns)
+	}
+}
+
+// TestANOVA_ErrorColumns builds row SNRs from a factor effect, a small effect
+// in reserved column 5 and a large one in the free column 7 of L8, which
+// stays out of the error term.
+func TestANOVA_ErrorColumns(t *testing.T) {
+	var factors []ControlFactor
+	for _, name := range []string{"A", "B", "C", "D"} {
+		factors = append(factors, ControlFactor{Name: name, Levels: []float64{1, 2}})
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		row := exp.OrthogonalArray[trial.Row]
+		snr := 10 + 2*float64(row[0]-1) + 0.5*float64(row[4]-1) + 3*float64(row[6]-1)
+		if err := exp.AddResult(trial, []float64{math.Pow(10, -snr/20)}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	implicit := mustAnalyze(t, exp).ANOVA
+	if implicit.ErrorDF != 3 || !almostEqual(implicit.ErrorSS, 18.5) || implicit.ColumnErrorDF != 0 {
+		t.Fatalf("error without error columns = %g on %d DF, want 18.5 on 3", implicit.ErrorSS, implicit.ErrorDF)
+	}
+
+	if err := exp.ReserveErrorColumns(4, 5); err != nil {
+		t.Fatalf("ReserveErrorColumns: %v", err)
+	}
+	result := mustAnalyze(t, exp)
+	a := result.ANOVA
+	if a.ErrorDF != 2 || !almostEqual(a.ErrorSS, 0.5) || a.ColumnErrorDF != 2 || !almostEqual(a.ColumnErrorSS, 0.5) {
+		t.Errorf("error = %g on %d DF, want the error columns' 0.5 on 2", a.ErrorSS, a.ErrorDF)
+	}
+	if a.UnassignedDF != 1 || !almostEqual(a.UnassignedSS, 18) {
+		t.Errorf("unassigned = %g on %d DF, want 18 on 1", a.UnassignedSS, a.UnassignedDF)
+	}
+	if f := a.FactorF["A"]; !almostEqual(f, 8/0.25) || !a.Significant["A"] {
+		t.Errorf("F(A) = %g, significant %v; want 32 and significant", f, a.Significant["A"])
+	}
+	total := result.ErrorContribution
+	for _, c := range result.Contributions {
+		total += c
+	}
+	if !almostEqual(total+100*18/26.5, 100) {
+		t.Errorf("contributions add up to %g%%, want 100%% less the unassigned share", total)
+	}
+	var report strings.Builder
+	if err := WriteAnalysisReport(&report, result, DefaultReportOptions()); err != nil {
+		t.Fatalf("WriteAnalysisReport: %v", err)
+	}
+	for _, want := range []string{"  Error columns", "Unassigned"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+}
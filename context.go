@@ -0,0 +1,39 @@
+package taguchi
+
+import "context"
+
+// TrialInfo describes the trial currently being executed and is carried in the
+// context handed to measurement code.
+// Trial: The trial configuration being run.
+// Row: Zero-based orthogonal array row the trial belongs to.
+// Metadata: Free-form annotations (e.g., hostname, git SHA) attached by the caller.
+type TrialInfo struct {
+	Trial    Trial
+	Row      int
+	Metadata map[string]string
+}
+
+type trialContextKey struct{}
+
+// ContextWithTrial returns a copy of ctx carrying info, retrievable with TrialFromContext.
+func ContextWithTrial(ctx context.Context, info TrialInfo) context.Context {
+	return context.WithValue(ctx, trialContextKey{}, info)
+}
+
+// TrialFromContext returns the TrialInfo stored in ctx by ContextWithTrial or
+// Experiment.TrialContext, so deeply nested measurement code can tag its logs
+// and metrics with the current configuration.
+func TrialFromContext(ctx context.Context) (TrialInfo, bool) {
+	info, ok := ctx.Value(trialContextKey{}).(TrialInfo)
+	return info, ok
+}
+
+// TrialContext returns a per-trial context derived from ctx carrying the trial,
+// its orthogonal array row and the given metadata.
+func (e *Experiment[P]) TrialContext(ctx context.Context, trial Trial, metadata map[string]string) context.Context {
+	return ContextWithTrial(ctx, TrialInfo{
+		Trial:    trial,
+		Row:      trial.Row,
+		Metadata: metadata,
+	})
+}
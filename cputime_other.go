@@ -0,0 +1,13 @@
+//go:build !unix
+
+package taguchi
+
+import (
+	"errors"
+	"time"
+)
+
+// processCPUTime is not available on this platform.
+func processCPUTime() (time.Duration, error) {
+	return 0, errors.New("CPU time measurement is not supported on this platform")
+}
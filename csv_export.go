@@ -0,0 +1,198 @@
+package taguchi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSVOptions controls the CSV written by TrialsCSV and AnalysisResult.WriteCSV.
+// Comma: Field delimiter (',' when zero); use ';' with locales that write a decimal comma.
+// Numbers: Formatting of numeric values; the zero value writes full precision.
+// Headers: Replacement header names keyed by the default name, e.g. {"SNR": "S/N (dB)"}.
+type CSVOptions struct {
+	Comma   rune
+	Numbers NumberFormat
+	Headers map[string]string
+}
+
+func (o CSVOptions) writer(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	if o.Comma != 0 {
+		cw.Comma = o.Comma
+	}
+	return cw
+}
+
+func (o CSVOptions) header(names ...string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = name
+		if h, ok := o.Headers[name]; ok {
+			out[i] = h
+		}
+	}
+	return out
+}
+
+func (o CSVOptions) format(v float64) string {
+	if o.Numbers == (NumberFormat{}) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return o.Numbers.Format(v)
+}
+
+func (o CSVOptions) formatLevel(v float64) string {
+	if o.Numbers == (NumberFormat{}) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return o.Numbers.FormatLevel(v)
+}
+
+// TrialsCSV writes the trial matrix as CSV for spreadsheets and statistics
+// packages: one line per run with its ID, array row (one-based), control and
+// noise levels, raw observations (Obs1, Obs2, ...), the SNR of its array row
+// and a column per metadata key, named with MetadataColumnPrefix. Recorded
+// results come first, followed by the trials of the design that have no
+// result yet; cells without data are left empty.
+func (e *Experiment[P]) TrialsCSV(w io.Writer, opts CSVOptions) error {
+	type line struct {
+		trial        Trial
+		observations []float64
+		recorded     bool
+		metadata     TrialResult
+	}
+	var lines []line
+	done := map[int]bool{}
+	width := 0
+	for i, r := range e.Results {
+		obs, err := r.RawObservations()
+		if err != nil {
+			return fmt.Errorf("result %d: %w", i, err)
+		}
+		width = max(width, len(obs))
+		lines = append(lines, line{trial: r.Trial, observations: obs, recorded: true, metadata: r})
+		done[r.Trial.ID] = true
+	}
+	for _, t := range e.GenerateTrials() {
+		if !done[t.ID] {
+			lines = append(lines, line{trial: t, metadata: TrialResult{Trial: t}})
+		}
+	}
+
+	names := []string{"Trial", "Row"}
+	for _, f := range e.ControlFactors {
+		names = append(names, f.Name)
+	}
+	for _, f := range e.NoiseFactors {
+		names = append(names, f.Name)
+	}
+	for k := 1; k <= width; k++ {
+		names = append(names, fmt.Sprintf("Obs%d", k))
+	}
+	names = append(names, "SNR")
+	keys := metadataKeys(e.Results)
+	for _, key := range keys {
+		names = append(names, MetadataColumnPrefix+key)
+	}
+
+	rows := e.computeOASNR()
+	cw := opts.writer(w)
+	if err := cw.Write(opts.header(names...)); err != nil {
+		return err
+	}
+	for _, l := range lines {
+		record := []string{strconv.Itoa(l.trial.ID), strconv.Itoa(l.trial.Row + 1)}
+		for _, f := range e.ControlFactors {
+			record = append(record, opts.formatLevel(l.trial.Control[f.Name]))
+		}
+		for _, f := range e.NoiseFactors {
+			record = append(record, opts.formatLevel(l.trial.Noise[f.Name]))
+		}
+		for k := 0; k < width; k++ {
+			cell := ""
+			if k < len(l.observations) {
+				cell = opts.format(l.observations[k])
+			}
+			record = append(record, cell)
+		}
+		snr := ""
+		if row := l.trial.Row; l.recorded && row >= 0 && row < len(rows.values) && rows.included[row] {
+			snr = opts.format(rows.values[row])
+		}
+		record = append(record, snr)
+		for _, key := range keys {
+			v, _ := l.metadata.MetadataValue(key)
+			record = append(record, v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes the main-effects table (mean SNR per factor level) and the
+// ANOVA table as CSV, separated by an empty line. Sources are listed in
+// alphabetical order followed by the error term and, when trials were
+// replicated, the pure error it includes.
+func (r AnalysisResult) WriteCSV(w io.Writer, opts CSVOptions) error {
+	cw := opts.writer(w)
+	if err := cw.Write(opts.header("Factor", "Level", "Mean SNR")); err != nil {
+		return err
+	}
+	for _, factor := range sortedKeys(r.MainEffects) {
+		for i, v := range r.MainEffects[factor] {
+			if err := cw.Write([]string{factor, strconv.Itoa(i + 1), opts.format(v)}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+
+	if err := cw.Write(opts.header("Source", "SS", "DF", "MS", "F", "p", "Significant")); err != nil {
+		return err
+	}
+	for _, source := range sortedKeys(r.ANOVA.FactorSS) {
+		record := []string{
+			source,
+			opts.format(r.ANOVA.FactorSS[source]),
+			strconv.Itoa(r.ANOVA.FactorDF[source]),
+			opts.format(r.ANOVA.FactorMS[source]),
+			opts.format(r.ANOVA.FactorF[source]),
+			opts.format(r.ANOVA.FactorP[source]),
+			strconv.FormatBool(r.ANOVA.Significant[source]),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	errRecord := []string{"Error", opts.format(r.ANOVA.ErrorSS), strconv.Itoa(r.ANOVA.ErrorDF), opts.format(r.ANOVA.ErrorMS), "", "", ""}
+	if err := cw.Write(errRecord); err != nil {
+		return err
+	}
+	if a := r.ANOVA; a.ColumnErrorDF > 0 {
+		columns := []string{"Error columns", opts.format(a.ColumnErrorSS), strconv.Itoa(a.ColumnErrorDF), "", "", "", ""}
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+	if a := r.ANOVA; a.PureErrorDF > 0 {
+		pure := []string{"Pure error", opts.format(a.PureErrorSS), strconv.Itoa(a.PureErrorDF), opts.format(a.PureErrorMS), "", "", ""}
+		if err := cw.Write(pure); err != nil {
+			return err
+		}
+	}
+	if a := r.ANOVA; a.UnassignedDF > 0 {
+		unassigned := []string{"Unassigned", opts.format(a.UnassignedSS), strconv.Itoa(a.UnassignedDF), "", "", "", ""}
+		if err := cw.Write(unassigned); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
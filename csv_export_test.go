@@ -0,0 +1,56 @@
+package taguchi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrialsCSV(t *testing.T) {
+	exp := retentionExperiment(t, SmallerTheBetter{}, RetainAll, "")
+	exp.Results = exp.Results[:3]
+
+	var buf bytes.Buffer
+	opts := CSVOptions{Comma: ';', Headers: map[string]string{"SNR": "S/N (dB)"}}
+	if err := exp.TrialsCSV(&buf, opts); err != nil {
+		t.Fatalf("TrialsCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"Trial;Row;A;N;Obs1;Obs2;S/N (dB)",
+		"1;1;1;0;2;4;" + opts.format(exp.Goal.CalculateSNR([]float64{2, 4, 6, 8})),
+	}
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want header and 4 trials:\n%s", len(lines), buf.String())
+	}
+	for i, w := range want {
+		if lines[i] != w {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i], w)
+		}
+	}
+	if last := lines[4]; last != "4;2;2;1;;;" {
+		t.Errorf("pending trial = %q, want empty observation and SNR cells", last)
+	}
+}
+
+func TestAnalysisResult_WriteCSV(t *testing.T) {
+	result := mustAnalyze(t, retentionExperiment(t, SmallerTheBetter{}, RetainAll, ""))
+
+	var buf bytes.Buffer
+	if err := result.WriteCSV(&buf, CSVOptions{Numbers: NumberFormat{Decimals: 2}}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	sections := strings.Split(buf.String(), "\n\n")
+	if len(sections) != 2 {
+		t.Fatalf("got %d sections, want main effects and ANOVA:\n%s", len(sections), buf.String())
+	}
+	if !strings.HasPrefix(sections[0], "Factor,Level,Mean SNR\nA,1,") {
+		t.Errorf("main effects table = %q", sections[0])
+	}
+	if !strings.HasPrefix(sections[1], "Source,SS,DF,MS,F,p,Significant\nA,") {
+		t.Errorf("ANOVA table = %q", sections[1])
+	}
+	if !strings.Contains(sections[1], "\nError,") {
+		t.Errorf("ANOVA table lacks the error term: %q", sections[1])
+	}
+}
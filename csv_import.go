@@ -0,0 +1,143 @@
+package taguchi
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AddResultsCSV records results produced outside this package, e.g. from a
+// historical experiment. The header row must name every control factor;
+// columns named after noise factors are optional; columns named with
+// MetadataColumnPrefix hold metadata, e.g. "meta:hostname", recorded in the
+// results' Metadata unless empty; every other column holds observations, and
+// empty observation cells are skipped. Each data line must
+// match a configuration of the design and becomes one trial result whose ID
+// is its line number. Lines repeating a configuration are replicates.
+//
+// Every line gets the checks of AddResult, so lines without observations,
+// with an odd count of them for a paired goal, or for a trial that already
+// had a result before the import are errors. The lines are recorded only
+// once all of them pass, so an error records nothing unless writing to an
+// attached store fails, which stops the import at that line.
+func (e *Experiment[P]) AddResultsCSV(r io.Reader) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("csv has no header")
+	}
+
+	header := records[0]
+	control := map[string]int{}
+	noise := map[string]int{}
+	metadata := map[string]int{}
+	var observations []int
+	for c, name := range header {
+		name = strings.TrimSpace(name)
+		switch {
+		case e.factorIndex(name) >= 0:
+			control[name] = c
+		case e.noiseFactorIndex(name) >= 0:
+			noise[name] = c
+		case strings.HasPrefix(name, MetadataColumnPrefix):
+			metadata[strings.TrimPrefix(name, MetadataColumnPrefix)] = c
+		default:
+			observations = append(observations, c)
+		}
+	}
+	for _, f := range e.ControlFactors {
+		if _, ok := control[f.Name]; !ok {
+			return fmt.Errorf("csv has no column for control factor %s", f.Name)
+		}
+	}
+	if len(observations) == 0 {
+		return fmt.Errorf("csv has no observation columns")
+	}
+
+	results := make([]TrialResult, 0, len(records)-1)
+	for n, record := range records[1:] {
+		line := n + 2
+		trial := Trial{ID: line, Row: -1, Control: map[string]float64{}}
+		for name, c := range control {
+			if trial.Control[name], err = parseCSVFloat(record, c); err != nil {
+				return fmt.Errorf("line %d: %s: %w", line, name, err)
+			}
+		}
+		if len(noise) > 0 {
+			trial.Noise = map[string]float64{}
+			for name, c := range noise {
+				if trial.Noise[name], err = parseCSVFloat(record, c); err != nil {
+					return fmt.Errorf("line %d: %s: %w", line, name, err)
+				}
+			}
+		}
+		for i := range e.OrthogonalArray {
+			if e.rowMatches(i, trial) {
+				trial.Row = i
+				break
+			}
+		}
+		if trial.Row < 0 {
+			return errorf(ErrTrialNotInDesign, "line %d: configuration %v is not part of the design", line, trial.Control)
+		}
+
+		var obs []float64
+		for _, c := range observations {
+			if c >= len(record) || strings.TrimSpace(record[c]) == "" {
+				continue
+			}
+			y, err := parseCSVFloat(record, c)
+			if err != nil {
+				return fmt.Errorf("line %d: column %s: %w", line, header[c], err)
+			}
+			obs = append(obs, y)
+		}
+		r := TrialResult{Trial: trial, Observations: obs}
+		for key, c := range metadata {
+			if c >= len(record) || record[c] == "" {
+				continue
+			}
+			if r.Metadata == nil {
+				r.Metadata = map[string]string{}
+			}
+			r.Metadata[key] = record[c]
+		}
+		checked, err := e.checkResult(r, false)
+		if err != nil {
+			var te *TrialError
+			if errors.As(err, &te) {
+				err = te.Err
+			}
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		results = append(results, checked)
+	}
+	for _, r := range results {
+		if err := e.recordResult(r); err != nil {
+			return fmt.Errorf("line %d: %w", r.Trial.ID, err)
+		}
+	}
+	return nil
+}
+
+// noiseFactorIndex returns the position of the named noise factor, or -1.
+func (e *Experiment[P]) noiseFactorIndex(name string) int {
+	for j, f := range e.NoiseFactors {
+		if f.Name == name {
+			return j
+		}
+	}
+	return -1
+}
+
+func parseCSVFloat(record []string, c int) (float64, error) {
+	if c >= len(record) {
+		return 0, fmt.Errorf("missing value")
+	}
+	return strconv.ParseFloat(strings.TrimSpace(record[c]), 64)
+}
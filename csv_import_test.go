@@ -0,0 +1,82 @@
+package taguchi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddResultsCSV(t *testing.T) {
+	design := Design{
+		Goal:  GoalSpec{Name: "STB"},
+		Array: L4,
+		ControlFactors: []ControlFactor{
+			{Name: "A", Levels: []float64{1, 2}},
+			{Name: "B", Levels: []float64{10, 20}},
+		},
+	}
+	exp, err := design.Experiment()
+	if err != nil {
+		t.Fatalf("Design.Experiment: %v", err)
+	}
+
+	data := "A,B,y1,y2\n1,10,3,3.5\n1,20,4,4.2\n2,10,6,\n2,20,8,7\n"
+	if err := exp.AddResultsCSV(strings.NewReader(data)); err != nil {
+		t.Fatalf("AddResultsCSV: %v", err)
+	}
+	if len(exp.Results) != 4 {
+		t.Fatalf("len(Results) = %d, want 4", len(exp.Results))
+	}
+	if r := exp.Results[2]; r.Trial.Row != 2 || len(r.Observations) != 1 {
+		t.Errorf("line 4: Row = %d, observations %v; want row 2 with one observation", r.Trial.Row, r.Observations)
+	}
+	if got := mustAnalyze(t, exp).OptimalLevels["B"]; got != 10 {
+		t.Errorf("OptimalLevels[B] = %v, want 10", got)
+	}
+
+	for _, bad := range []string{
+		"B,y\n10,1\n",        // missing control column
+		"A,B,y\n1,30,1\n",    // configuration outside the design
+		"A,B,y\n1,10,fast\n", // non-numeric observation
+	} {
+		if err := exp.AddResultsCSV(strings.NewReader(bad)); err == nil {
+			t.Errorf("AddResultsCSV(%q): expected error", bad)
+		}
+	}
+}
+
+func TestAddResultsCSV_Atomic(t *testing.T) {
+	exp := newTestExperiment(t, testDesign{Goal: SmallerTheBetter{}})
+	// The configuration of line 3 is valid, but its observation is not.
+	data := "A,B,y\n1,1,3\n1,2,NaN\n2,1,6\n2,2,8\n"
+	err := exp.AddResultsCSV(strings.NewReader(data))
+	if !errors.Is(err, ErrInvalidObservations) || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("AddResultsCSV with a bad middle line = %v, want ErrInvalidObservations at line 3", err)
+	}
+	if len(exp.Results) != 0 {
+		t.Errorf("%d results recorded before the bad line, want none", len(exp.Results))
+	}
+
+	// Lines of a trial recorded before the import are duplicates, while
+	// lines repeating each other are replicates.
+	if err := exp.AddResult(exp.GenerateTrials()[3], []float64{8}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	if err := exp.AddResultsCSV(strings.NewReader("A,B,y\n1,1,3\n2,2,8\n")); !errors.Is(err, ErrDuplicateResult) {
+		t.Errorf("AddResultsCSV of a recorded trial = %v, want ErrDuplicateResult", err)
+	}
+	if err := exp.AddResultsCSV(strings.NewReader("A,B,y\n1,1,3\n1,1,4\n")); err != nil {
+		t.Fatalf("AddResultsCSV with replicates: %v", err)
+	}
+	if len(exp.Results) != 3 {
+		t.Errorf("%d results, want 3", len(exp.Results))
+	}
+
+	paired := newTestExperiment(t, testDesign{Goal: OperatingWindow{}})
+	if err := paired.AddResultsCSV(strings.NewReader("A,B,lo,hi\n1,1,1,2\n1,2,1,\n")); !errors.Is(err, ErrInvalidObservations) {
+		t.Errorf("AddResultsCSV with an odd count of paired observations = %v, want ErrInvalidObservations", err)
+	}
+	if len(paired.Results) != 0 {
+		t.Errorf("%d paired results recorded, want none", len(paired.Results))
+	}
+}
@@ -0,0 +1,146 @@
+package taguchi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GoalSpec is the serializable form of an OptimizationGoal.
+// Name: The goal's String() name, e.g. "Smaller-the-Better".
+// Target: Target value for Nominal-the-Best.
+type GoalSpec struct {
+	Name   string
+	Target float64
+}
+
+// NewGoalSpec describes goal as a GoalSpec.
+func NewGoalSpec(goal OptimizationGoal) GoalSpec {
+	switch g := goal.(type) {
+	case NominalTheBest:
+		return GoalSpec{Name: g.String(), Target: g.Target}
+	case *NominalTheBest:
+		return GoalSpec{Name: g.String(), Target: g.Target}
+	case NominalTheBestTypeI:
+		return GoalSpec{Name: g.String(), Target: g.Target}
+	case *NominalTheBestTypeI:
+		return GoalSpec{Name: g.String(), Target: g.Target}
+	case nil:
+		return GoalSpec{}
+	}
+	return GoalSpec{Name: goal.String()}
+}
+
+// Goal returns the built-in goal named by the spec. Names are matched
+// case-insensitively and the abbreviations STB, LTB, NTB and NTB-I are accepted.
+func (g GoalSpec) Goal() (OptimizationGoal, error) {
+	switch strings.ToLower(g.Name) {
+	case "smaller-the-better", "smallerthebetter", "stb":
+		return SmallerTheBetter{}, nil
+	case "larger-the-better", "largerthebetter", "ltb":
+		return LargerTheBetter{}, nil
+	case "nominal-the-best", "nominalthebest", "ntb":
+		return NominalTheBest{Target: g.Target}, nil
+	case "nominal-the-best-i", "nominalthebesttypei", "ntb-i":
+		return NominalTheBestTypeI{Target: g.Target}, nil
+	case "signed-target", "signedtarget":
+		return SignedTarget{}, nil
+	case "operating-window", "operatingwindow":
+		return OperatingWindow{}, nil
+	case "dynamic":
+		return Dynamic{}, nil
+	case "dynamic-linear":
+		return Dynamic{Linear: true}, nil
+	case "fraction-defective", "fractiondefective":
+		return FractionDefective{}, nil
+	}
+	return nil, fmt.Errorf("unknown optimization goal %q", g.Name)
+}
+
+// Design is the serializable definition of an experiment, everything needed
+// to regenerate its trials and repeat its analysis. Hand-written designs may
+// name a standard Array instead of spelling out OrthogonalArray.
+type Design struct {
+	Goal             GoalSpec
+	Array            ArrayType
+	ControlFactors   []ControlFactor
+	NoiseFactors     []NoiseFactor
+	OrthogonalArray  [][]int
+	Columns          []int
+	Interactions     []Interaction
+	ErrorColumns     []int
+	NoiseSampling    NoiseSampling
+	InfiniteSNR      InfiniteSNRPolicy
+	SNRCeiling       float64
+	RobustSNR        RobustSNRPolicy
+	OutlierThreshold float64
+	Alpha            float64
+	AnalyzeMeans     bool
+	AllowIncomplete  bool
+	RunOrder         RunOrder
+	AnalyzeTimeTrend bool
+	TiePolicy        TiePolicy
+}
+
+// Design returns the serializable definition of the experiment.
+func (e *Experiment[P]) Design() Design {
+	return Design{
+		Goal:             NewGoalSpec(e.Goal),
+		ControlFactors:   e.ControlFactors,
+		NoiseFactors:     e.NoiseFactors,
+		OrthogonalArray:  e.OrthogonalArray,
+		Columns:          e.Columns,
+		Interactions:     e.Interactions,
+		ErrorColumns:     e.ErrorColumns,
+		NoiseSampling:    e.NoiseSampling,
+		InfiniteSNR:      e.InfiniteSNR,
+		SNRCeiling:       e.SNRCeiling,
+		RobustSNR:        e.RobustSNR,
+		OutlierThreshold: e.OutlierThreshold,
+		Alpha:            e.Alpha,
+		AnalyzeMeans:     e.AnalyzeMeans,
+		AllowIncomplete:  e.AllowIncomplete,
+		RunOrder:         e.RunOrder,
+		AnalyzeTimeTrend: e.AnalyzeTimeTrend,
+		TiePolicy:        e.TiePolicy,
+	}
+}
+
+// Experiment constructs an experiment from the design. Only the built-in
+// goals can be reconstructed. The array is not required to be orthogonal, so
+// saved designs of intentionally non-orthogonal experiments load again.
+func (d Design) Experiment() (*Experiment[struct{}], error) {
+	goal, err := d.Goal.Goal()
+	if err != nil {
+		return nil, err
+	}
+	oa := d.OrthogonalArray
+	if len(oa) == 0 && d.Array != "" {
+		var ok bool
+		if oa, ok = StandardArrays[d.Array]; !ok {
+			return nil, errorf(ErrUnknownArray, "orthogonal array %s not defined", d.Array)
+		}
+	}
+	e, err := NewExperimentFromFactorsUsingArray(goal, d.ControlFactors, oa, d.NoiseFactors, ArrayOptions{AllowNonOrthogonal: true})
+	if err != nil {
+		return nil, err
+	}
+	if d.Columns != nil {
+		if err := e.SetColumns(d.Columns); err != nil {
+			return nil, err
+		}
+	}
+	e.Interactions = d.Interactions
+	e.ErrorColumns = d.ErrorColumns
+	e.NoiseSampling = d.NoiseSampling
+	e.InfiniteSNR = d.InfiniteSNR
+	e.SNRCeiling = d.SNRCeiling
+	e.RobustSNR = d.RobustSNR
+	e.OutlierThreshold = d.OutlierThreshold
+	e.Alpha = d.Alpha
+	e.AnalyzeMeans = d.AnalyzeMeans
+	e.AllowIncomplete = d.AllowIncomplete
+	e.RunOrder = d.RunOrder
+	e.AnalyzeTimeTrend = d.AnalyzeTimeTrend
+	e.TiePolicy = d.TiePolicy
+	return e, nil
+}
@@ -0,0 +1,258 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RowResidual is the fit of the additive-effects model at one array row.
+// Row: Array row, zero-based as in Trial.Row.
+// SNR: The row's SNR.
+// Fitted: SNR predicted by the main effects and declared interactions.
+// Residual: SNR minus Fitted.
+// Standardized: Residual divided by its standard error sqrt(V_e·(1 − Leverage)).
+// Studentized: Residual standardized with the error variance estimated without this row (externally studentized).
+// Leverage: Influence of the row's SNR on its own fitted value, the diagonal of the hat matrix.
+// Outlier: Whether Studentized is significant at Alpha after a Bonferroni correction over the rows.
+// HighLeverage: Whether Leverage exceeds twice the average leverage.
+type RowResidual struct {
+	Row          int
+	SNR          float64
+	Fitted       float64
+	Residual     float64
+	Standardized float64
+	Studentized  float64
+	Leverage     float64
+	Outlier      bool
+	HighLeverage bool
+}
+
+// ModelDiagnostics checks the assumptions behind the ANOVA: that the factor
+// effects add up and that the residual variation is normal.
+// Residuals: Fit of every included array row, in row order.
+// ResidualDF: Residual degrees of freedom of the model.
+// AndersonDarling: Anderson–Darling statistic of the residuals, adjusted for estimated mean and variance (A*²).
+// NormalityP: p-value of the normality test; small values mean the residuals are not normal.
+// Normal: Whether NormalityP is at least the experiment's Alpha.
+type ModelDiagnostics struct {
+	Residuals       []RowResidual
+	ResidualDF      int
+	AndersonDarling float64
+	NormalityP      float64
+	Normal          bool
+}
+
+// Diagnostics fits the additive-effects model of the ANOVA (every control
+// factor's main effect plus the declared interactions) to the row SNRs and
+// reports the residual of every row, leverage and outlier flags, and an
+// Anderson–Darling test of the residuals' normality. Large or structured
+// residuals point to interactions the design does not model; outliers to
+// rows worth re-running. Rows without results are left out.
+//
+// Saturated designs fit every row exactly and leave no residuals to check,
+// so they return an error; pool factors by analyzing fewer of them, or use a
+// larger array. The normality test needs at least three rows and has little
+// power below about eight.
+func (e *Experiment[P]) Diagnostics() (ModelDiagnostics, error) {
+	if err := e.checkComplete(); err != nil {
+		return ModelDiagnostics{}, err
+	}
+	rows := e.computeOASNR()
+	var x [][]float64
+	var y []float64
+	var index []int
+	for i := range e.OrthogonalArray {
+		if !rows.included[i] || rows.missing[i] {
+			continue
+		}
+		x = append(x, e.effectCoding(i))
+		y = append(y, rows.values[i])
+		index = append(index, i)
+	}
+	if len(y) == 0 {
+		return ModelDiagnostics{}, errorf(ErrMissingResults, "no results recorded")
+	}
+	n, p := len(y), len(x[0])
+	if n <= p {
+		return ModelDiagnostics{}, fmt.Errorf("the model has %d terms for %d rows and leaves no residuals; analyze fewer factors or use a larger array", p, n)
+	}
+	coef, rss, err := leastSquares(x, y)
+	if err != nil {
+		return ModelDiagnostics{}, fmt.Errorf("the additive model is not estimable: %w", err)
+	}
+	leverage, err := hatDiagonal(x)
+	if err != nil {
+		return ModelDiagnostics{}, err
+	}
+
+	d := ModelDiagnostics{ResidualDF: n - p}
+	alpha := e.alpha()
+	// An exact fit leaves only rounding noise, which must not be standardized
+	// into spurious outliers.
+	mean := meanOf(y)
+	sst := 0.0
+	for _, v := range y {
+		sst += (v - mean) * (v - mean)
+	}
+	if rss <= 1e-12*sst {
+		rss = 0
+	}
+	ms := rss / float64(n-p)
+	// Bonferroni-corrected critical value of the externally studentized residuals.
+	var tCrit float64
+	if n-p > 1 {
+		tCrit = studentTQuantile(1-alpha/(2*float64(n)), float64(n-p-1))
+	}
+	residuals := make([]float64, n)
+	for k, i := range index {
+		r := RowResidual{Row: i, SNR: y[k], Fitted: dot(x[k], coef), Leverage: leverage[k]}
+		r.Residual = r.SNR - r.Fitted
+		if ms > 0 {
+			residuals[k] = r.Residual
+		}
+		if se := math.Sqrt(ms * (1 - r.Leverage)); se > 0 && r.Leverage < 1-1e-9 {
+			r.Standardized = r.Residual / se
+			if n-p > 1 {
+				// Deleting the row removes r²/(1−h) from the residual SS.
+				if rest := float64(n-p) - r.Standardized*r.Standardized; rest > 0 {
+					r.Studentized = r.Standardized * math.Sqrt(float64(n-p-1)/rest)
+				} else {
+					r.Studentized = math.Copysign(math.Inf(1), r.Standardized)
+				}
+				r.Outlier = math.Abs(r.Studentized) > tCrit
+			}
+		}
+		r.HighLeverage = r.Leverage > 2*float64(p)/float64(n)
+		d.Residuals = append(d.Residuals, r)
+	}
+	d.AndersonDarling, d.NormalityP = andersonDarling(residuals)
+	d.Normal = d.NormalityP >= alpha
+	return d, nil
+}
+
+// effectCoding returns the model row of array row i: an intercept, the
+// effect-coded levels of every control factor (1 in the level's column, -1
+// in every column for the last level) and their products for each declared
+// interaction.
+func (e *Experiment[P]) effectCoding(i int) []float64 {
+	x := []float64{1}
+	codes := make([][]float64, len(e.ControlFactors))
+	for j, factor := range e.ControlFactors {
+		li := e.levelIndex(i, j)
+		code := make([]float64, len(factor.Levels)-1)
+		for k := range code {
+			switch {
+			case k == li:
+				code[k] = 1
+			case li == len(code):
+				code[k] = -1
+			}
+		}
+		codes[j] = code
+		x = append(x, code...)
+	}
+	for _, in := range e.Interactions {
+		for _, a := range codes[e.factorIndex(in.A)] {
+			for _, b := range codes[e.factorIndex(in.B)] {
+				x = append(x, a*b)
+			}
+		}
+	}
+	return x
+}
+
+// hatDiagonal returns the leverages x_i·(XᵀX)⁻¹·x_i of the rows of x.
+func hatDiagonal(x [][]float64) ([]float64, error) {
+	p := len(x[0])
+	// Gauss–Jordan inversion of XᵀX, augmented with the identity.
+	a := make([][]float64, p)
+	for r := range a {
+		a[r] = make([]float64, 2*p)
+		a[r][p+r] = 1
+		for _, row := range x {
+			for c := 0; c < p; c++ {
+				a[r][c] += row[r] * row[c]
+			}
+		}
+	}
+	for col := 0; col < p; col++ {
+		pivot := col
+		for r := col + 1; r < p; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < rankTolerance {
+			return nil, fmt.Errorf("design matrix is rank deficient")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		f := a[col][col]
+		for c := range a[col] {
+			a[col][c] /= f
+		}
+		for r := 0; r < p; r++ {
+			if r == col {
+				continue
+			}
+			f := a[r][col]
+			for c := range a[r] {
+				a[r][c] -= f * a[col][c]
+			}
+		}
+	}
+	h := make([]float64, len(x))
+	for i, row := range x {
+		for r := 0; r < p; r++ {
+			for c := 0; c < p; c++ {
+				h[i] += row[r] * a[r][p+c] * row[c]
+			}
+		}
+	}
+	return h, nil
+}
+
+// andersonDarling returns the Anderson–Darling statistic A*² of values
+// against a normal distribution with their own mean and variance, and its
+// p-value from the approximation of D'Agostino and Stephens (1986). With
+// fewer than three values or no spread there is nothing to test: it
+// returns 0 and a p-value of 1.
+func andersonDarling(values []float64) (float64, float64) {
+	n := len(values)
+	if n < 3 {
+		return 0, 1
+	}
+	mean := meanOf(values)
+	ss := 0.0
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	sd := math.Sqrt(ss / float64(n-1))
+	if sd < 1e-12*math.Max(1, math.Abs(mean)) {
+		return 0, 1
+	}
+	z := make([]float64, n)
+	for i, v := range values {
+		z[i] = (v - mean) / sd
+	}
+	sort.Float64s(z)
+	a2 := -float64(n)
+	for i := range z {
+		lo := math.Max(normalCDF(z[i]), 1e-300)
+		hi := math.Max(1-normalCDF(z[n-1-i]), 1e-300)
+		a2 -= float64(2*i+1) / float64(n) * (math.Log(lo) + math.Log(hi))
+	}
+	a := a2 * (1 + 0.75/float64(n) + 2.25/float64(n*n))
+	var pValue float64
+	switch {
+	case a >= 0.6:
+		pValue = math.Exp(1.2937 - 5.709*a + 0.0186*a*a)
+	case a >= 0.34:
+		pValue = math.Exp(0.9177 - 4.279*a - 1.38*a*a)
+	case a >= 0.2:
+		pValue = 1 - math.Exp(-8.318+42.796*a-59.938*a*a)
+	default:
+		pValue = 1 - math.Exp(-13.436+101.14*a-223.73*a*a)
+	}
+	return a, math.Min(math.Max(pValue, 0), 1)
+}
@@ -0,0 +1,87 @@
+package taguchi
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// diagnosticsResponse is additive in dB over two three-level factors A and
+// B, with y multiplied by spike in row 4 (zero-based).
+func diagnosticsResponse(spike float64) func(Trial) [][]float64 {
+	return singleResult(func(trial Trial) []float64 {
+		y := trial.Control["A"] * math.Pow(2, trial.Control["B"])
+		if trial.Row == 4 {
+			y *= spike
+		}
+		return []float64{y}
+	})
+}
+
+func TestDiagnostics_AdditiveFit(t *testing.T) {
+	d, err := newTestExperiment(t, testDesign{
+		Factors:  namedFactors([]float64{1, 2, 3}, "A", "B"),
+		Array:    L9,
+		Response: diagnosticsResponse(1),
+	}).Diagnostics()
+	if err != nil {
+		t.Fatalf("Diagnostics: %v", err)
+	}
+	if len(d.Residuals) != 9 || d.ResidualDF != 4 {
+		t.Fatalf("%d residuals with %d DF, want 9 with 4", len(d.Residuals), d.ResidualDF)
+	}
+	trace := 0.0
+	for _, r := range d.Residuals {
+		if math.Abs(r.Residual) > 1e-9 || r.Outlier || r.HighLeverage {
+			t.Errorf("row %d: %+v, want an exact fit without flags", r.Row, r)
+		}
+		trace += r.Leverage
+	}
+	// The hat matrix has trace p: intercept plus two DF per factor.
+	if !almostEqual(trace, 5) || !almostEqual(d.Residuals[0].Leverage, 5.0/9) {
+		t.Errorf("leverage trace %g and first leverage %g, want 5 and 5/9", trace, d.Residuals[0].Leverage)
+	}
+	if !d.Normal || d.NormalityP != 1 {
+		t.Errorf("exact fit: normality p = %g, want 1", d.NormalityP)
+	}
+}
+
+func TestDiagnostics_Outlier(t *testing.T) {
+	d, err := newTestExperiment(t, testDesign{
+		Factors:  namedFactors([]float64{1, 2, 3}, "A", "B"),
+		Array:    L9,
+		Response: diagnosticsResponse(30),
+	}).Diagnostics()
+	if err != nil {
+		t.Fatalf("Diagnostics: %v", err)
+	}
+	for _, r := range d.Residuals {
+		if want := r.Row == 4; r.Outlier != want {
+			t.Errorf("row %d: outlier = %v (studentized %g), want %v", r.Row, r.Outlier, r.Studentized, want)
+		}
+		if !almostEqual(r.SNR-r.Fitted, r.Residual) {
+			t.Errorf("row %d: residual %g, want SNR − fitted %g", r.Row, r.Residual, r.SNR-r.Fitted)
+		}
+	}
+}
+
+func TestDiagnostics_Saturated(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B", "C")
+	if _, err := exp.Diagnostics(); err == nil || !strings.Contains(err.Error(), "no residuals") {
+		t.Errorf("saturated design: err = %v, want no residuals", err)
+	}
+}
+
+func TestAndersonDarling(t *testing.T) {
+	normal := normalQuantiles(10, 2, 20)
+	if a, p := andersonDarling(normal); p < 0.5 || a > 0.3 {
+		t.Errorf("normal quantiles: A*² = %g, p = %g; want a small statistic and a large p", a, p)
+	}
+	skewed := []float64{1, 1, 1, 1.1, 1, 0.9, 1, 1, 1, 1, 1, 40}
+	if a, p := andersonDarling(skewed); p > 0.01 {
+		t.Errorf("one extreme value: A*² = %g, p = %g; want p below 0.01", a, p)
+	}
+	if _, p := andersonDarling([]float64{1, 2}); p != 1 {
+		t.Errorf("two values: p = %g, want 1", p)
+	}
+}
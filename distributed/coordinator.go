@@ -0,0 +1,358 @@
+// Package distributed runs the trials of an experiment on remote workers,
+// e.g. hardware-in-the-loop test rigs. A Coordinator serves the pending
+// trials of an experiment over HTTP; each Worker leases a trial, measures it
+// with a taguchi.MeasureFunc and posts the observations back:
+//
+//	coord, err := distributed.NewCoordinator(exp, distributed.Options{Repetitions: 3})
+//	go http.ListenAndServe(":8080", taguchi.TokenAuth{Tokens: tokens, Handler: coord})
+//	result, err := coord.Wait(ctx)
+//
+// and on every rig:
+//
+//	w := &distributed.Worker{URL: "http://coordinator:8080", Name: "rig-1", Measure: measure}
+//	err := w.Run(ctx)
+//
+// The API is JSON over HTTP:
+//
+//	POST /lease    lease a pending trial: 200 with an Assignment, 204 when every pending trial is leased, 410 once the experiment is over
+//	POST /result   report the Report of a lease: 204, 400 for invalid observations (the trial is handed out again), 409 when the lease is unknown or expired, or 503 when the result could not be recorded, e.g. in the experiment's store (the trial is handed out again)
+//	GET  /status   the Status of the experiment
+//
+// A Registry hosts the coordinators of several named experiments on one
+// server, under /experiments/{name}; workers name theirs in
+// Worker.Experiment.
+//
+// Behind taguchi.TokenAuth, workers need writer tokens; reader tokens may
+// only watch the status.
+package distributed
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// MetaWorker is the trial metadata key under which a result's worker is recorded.
+const MetaWorker = "worker"
+
+// DefaultLeaseTimeout is the time a worker has to report a trial when
+// Options.LeaseTimeout is zero.
+const DefaultLeaseTimeout = 10 * time.Minute
+
+// DefaultMaxAttempts is the number of failed measurements of a trial after
+// which the experiment fails when Options.MaxAttempts is zero.
+const DefaultMaxAttempts = 3
+
+// Options configures a Coordinator.
+// Repetitions, Warmup: Measured and discarded runs per trial, as in taguchi.RunOptions.
+// LeaseTimeout: Time a worker has to report a leased trial before it is handed out again (DefaultLeaseTimeout when zero).
+// MaxAttempts: Failed measurements of a trial after which the experiment fails (DefaultMaxAttempts when zero).
+type Options struct {
+	Repetitions  int
+	Warmup       int
+	LeaseTimeout time.Duration
+	MaxAttempts  int
+}
+
+// Assignment is a trial leased to a worker.
+// Lease: ID under which the result is reported.
+// Trial: The trial to measure.
+// Repetitions, Warmup: Measured and discarded runs, as in Options.
+// Deadline: When the lease expires and the trial is handed out again.
+type Assignment struct {
+	Lease       string
+	Trial       taguchi.Trial
+	Repetitions int
+	Warmup      int
+	Deadline    time.Time
+}
+
+// Report is a worker's result for a lease.
+// Lease: The lease being reported.
+// Worker: Name of the worker, recorded in the trial metadata under MetaWorker.
+// Observations: The measured runs.
+// Err: Why the measurement failed, empty on success; the trial is then handed out again.
+type Report struct {
+	Lease        string
+	Worker       string
+	Observations []float64
+	Err          string
+}
+
+// Status is a snapshot of a distributed experiment.
+// Total: Trials of the design.
+// Completed, Leased, Pending: Trials with a result, being measured and waiting for a worker.
+// Failures: Failed measurements reported so far.
+// Done: Whether every trial has a result or the experiment failed.
+// Err: Why the experiment failed, empty otherwise.
+type Status struct {
+	Total     int
+	Completed int
+	Leased    int
+	Pending   int
+	Failures  int
+	Done      bool
+	Err       string
+}
+
+// Coordinator hands out the trials of an experiment without results to
+// workers and records their observations with AddResult. Trials whose lease
+// expires, e.g. because their rig crashed, and trials whose measurement
+// failed are handed out again. The experiment must not be modified
+// elsewhere while the coordinator serves it.
+type Coordinator[P any] struct {
+	exp  *taguchi.Experiment[P]
+	opts Options
+
+	mu        sync.Mutex
+	pending   []taguchi.Trial
+	leases    map[string]*lease
+	attempts  map[string]int // failed measurements by trial key
+	total     int
+	completed int
+	failures  int
+	err       error
+	done      chan struct{}
+}
+
+// lease is a trial handed out to a worker.
+type lease struct {
+	trial    taguchi.Trial
+	worker   string
+	deadline time.Time
+}
+
+// NewCoordinator returns a coordinator for the trials of exp that have no
+// result yet, in the experiment's run order. Replicate blocks are not
+// supported: workers measure the trials in any order, so a design with
+// RunOrder.Blocks above one is rejected.
+func NewCoordinator[P any](exp *taguchi.Experiment[P], opts Options) (*Coordinator[P], error) {
+	if exp.RunOrder.Blocks > 1 {
+		return nil, errors.New("replicate blocks are not supported by the coordinator")
+	}
+	c := &Coordinator[P]{
+		exp:      exp,
+		opts:     opts,
+		leases:   map[string]*lease{},
+		attempts: map[string]int{},
+		done:     make(chan struct{}),
+	}
+	c.total = len(exp.GenerateTrials())
+	c.pending = exp.PendingTrials()
+	c.completed = c.total - len(c.pending)
+	if len(c.pending) == 0 {
+		close(c.done)
+	}
+	return c, nil
+}
+
+// ServeHTTP serves the worker API (see the package documentation).
+func (c *Coordinator[P]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/lease":
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct{ Worker string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		a, status := c.lease(req.Worker)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		writeJSON(w, a)
+	case "/result":
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		var rep Report
+		if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if status, err := c.report(rep); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "/status":
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, c.Status())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// lease hands the next pending trial to worker. It returns 204 when every
+// pending trial is leased and 410 once the experiment is over.
+func (c *Coordinator[P]) lease(worker string) (Assignment, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.over() {
+		return Assignment{}, http.StatusGone
+	}
+	now := time.Now()
+	for id, l := range c.leases {
+		if now.After(l.deadline) {
+			delete(c.leases, id)
+			c.pending = append(c.pending, l.trial)
+		}
+	}
+	if len(c.pending) == 0 {
+		return Assignment{}, http.StatusNoContent
+	}
+	trial := c.pending[0]
+	c.pending = c.pending[1:]
+	timeout := c.opts.LeaseTimeout
+	if timeout <= 0 {
+		timeout = DefaultLeaseTimeout
+	}
+	id := newLeaseID()
+	c.leases[id] = &lease{trial: trial, worker: worker, deadline: now.Add(timeout)}
+	return Assignment{
+		Lease:       id,
+		Trial:       trial,
+		Repetitions: max(c.opts.Repetitions, 1),
+		Warmup:      c.opts.Warmup,
+		Deadline:    now.Add(timeout),
+	}, http.StatusOK
+}
+
+// report records the result of a lease, or hands the trial out again when
+// its measurement failed or its result could not be recorded. It returns the
+// HTTP status of a rejected report.
+func (c *Coordinator[P]) report(rep Report) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.leases[rep.Lease]
+	if !ok || c.over() {
+		return http.StatusConflict, fmt.Errorf("lease %q is unknown or expired", rep.Lease)
+	}
+	delete(c.leases, rep.Lease)
+	if rep.Err != "" {
+		c.retry(l, rep.Err)
+		return 0, nil
+	}
+	trial := l.trial
+	trial.Metadata = maps.Clone(trial.Metadata)
+	if trial.Metadata == nil {
+		trial.Metadata = map[string]string{}
+	}
+	trial.Metadata[MetaWorker] = rep.Worker
+	if err := c.exp.AddResult(trial, rep.Observations); err != nil {
+		if !errors.Is(err, taguchi.ErrInvalidObservations) {
+			// Recording failed for reasons of its own, e.g. an unavailable
+			// store, so the trial is handed out again without counting a
+			// failed measurement.
+			c.pending = append([]taguchi.Trial{l.trial}, c.pending...)
+			return http.StatusServiceUnavailable, err
+		}
+		c.retry(l, err.Error())
+		return http.StatusBadRequest, err
+	}
+	c.completed++
+	if c.completed == c.total {
+		close(c.done)
+	}
+	return 0, nil
+}
+
+// retry counts a failed measurement of a leased trial and hands the trial
+// out again, or fails the experiment after too many attempts.
+func (c *Coordinator[P]) retry(l *lease, reason string) {
+	c.failures++
+	c.attempts[l.trial.Key]++
+	limit := c.opts.MaxAttempts
+	if limit <= 0 {
+		limit = DefaultMaxAttempts
+	}
+	if c.attempts[l.trial.Key] >= limit {
+		c.fail(fmt.Errorf("trial %d failed %d times, last on worker %s: %s", l.trial.ID, limit, l.worker, reason))
+		return
+	}
+	c.pending = append(c.pending, l.trial)
+}
+
+// fail ends the experiment with err.
+func (c *Coordinator[P]) fail(err error) {
+	c.err = err
+	close(c.done)
+}
+
+// over reports whether every trial has a result or the experiment failed.
+func (c *Coordinator[P]) over() bool {
+	return c.err != nil || c.completed == c.total
+}
+
+// Status returns a snapshot of the experiment's progress.
+func (c *Coordinator[P]) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := Status{
+		Total:     c.total,
+		Completed: c.completed,
+		Leased:    len(c.leases),
+		Pending:   len(c.pending),
+		Failures:  c.failures,
+		Done:      c.over(),
+	}
+	if c.err != nil {
+		s.Err = c.err.Error()
+	}
+	return s
+}
+
+// Done returns a channel closed once every trial has a result or the
+// experiment failed.
+func (c *Coordinator[P]) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait blocks until every trial has a result and then analyzes the
+// experiment. It returns the error that failed the experiment, or the
+// context's error when ctx ends first.
+func (c *Coordinator[P]) Wait(ctx context.Context) (taguchi.AnalysisResult, error) {
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return taguchi.AnalysisResult{}, ctx.Err()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return taguchi.AnalysisResult{}, c.err
+	}
+	return c.exp.Analyze()
+}
+
+// newLeaseID returns a random lease ID.
+func newLeaseID() string {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
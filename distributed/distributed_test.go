@@ -0,0 +1,352 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/internal/testexp"
+)
+
+func measure(ctx context.Context, trial taguchi.Trial) (float64, error) {
+	return trial.Control["A"] + trial.Control["B"] + trial.Noise["N"], nil
+}
+
+// newCoordinator returns the coordinator of exp, failing the test on error.
+func newCoordinator(t *testing.T, exp *taguchi.Experiment[struct{}], opts Options) *Coordinator[struct{}] {
+	t.Helper()
+	c, err := NewCoordinator(exp, opts)
+	if err != nil {
+		t.Fatalf("NewCoordinator: %v", err)
+	}
+	return c
+}
+
+// leaseTrial requests a trial directly, as a worker that never reports would.
+func leaseTrial(t *testing.T, url string) (Assignment, int) {
+	t.Helper()
+	resp, err := http.Post(url+"/lease", "application/json", strings.NewReader(`{"Worker":"stray"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var a Assignment
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return a, resp.StatusCode
+}
+
+// TestCoordinator verifies that several workers behind TokenAuth complete
+// the experiment, that a failed measurement is retried and that Wait
+// returns the analysis.
+func TestCoordinator(t *testing.T) {
+	exp := testexp.New(t, testexp.Design{})
+	coord := newCoordinator(t, exp, Options{Repetitions: 2, Warmup: 1})
+	tokens := map[string]taguchi.Role{"rig": taguchi.RoleWriter, "viewer": taguchi.RoleReader}
+	srv := httptest.NewServer(taguchi.TokenAuth{Tokens: tokens, Handler: coord})
+	defer srv.Close()
+
+	var once sync.Once
+	flaky := func(ctx context.Context, trial taguchi.Trial) (float64, error) {
+		if info, ok := taguchi.TrialFromContext(ctx); !ok || info.Trial.Key != trial.Key {
+			return 0, errors.New("trial missing from context")
+		}
+		var err error
+		once.Do(func() { err = errors.New("rig overheated") })
+		if err != nil {
+			return 0, err
+		}
+		return measure(ctx, trial)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		w := &Worker{URL: srv.URL, Name: fmt.Sprintf("rig-%d", i+1), Token: "rig", Measure: flaky, PollInterval: time.Millisecond}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = w.Run(ctx)
+		}(i)
+	}
+	result, err := coord.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("worker %d: %v", i+1, err)
+		}
+	}
+
+	trials := exp.GenerateTrials()
+	if len(exp.Results) != len(trials) {
+		t.Fatalf("got %d results, want %d", len(exp.Results), len(trials))
+	}
+	for _, r := range exp.Results {
+		if len(r.Observations) != 2 || !strings.HasPrefix(r.Trial.Metadata[MetaWorker], "rig-") {
+			t.Errorf("result of trial %d = %v from %q, want 2 observations from a rig", r.Trial.ID, r.Observations, r.Trial.Metadata[MetaWorker])
+		}
+	}
+	if result.OptimalLevels["A"] != 1 || result.OptimalLevels["B"] != 10 {
+		t.Errorf("optimal levels = %v, want A=1 B=10", result.OptimalLevels)
+	}
+	if s := coord.Status(); !s.Done || s.Completed != len(trials) || s.Failures != 1 || s.Leased != 0 || s.Pending != 0 {
+		t.Errorf("status = %+v", s)
+	}
+
+	// Readers may watch the status but not lease trials.
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/status?token=viewer", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Status
+	json.NewDecoder(resp.Body).Decode(&s)
+	resp.Body.Close()
+	if s.Total != len(trials) || !s.Done {
+		t.Errorf("GET /status = %+v", s)
+	}
+	resp, err = http.Post(srv.URL+"/lease?token=viewer", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("lease by reader = %s, want 403", resp.Status)
+	}
+}
+
+// TestCoordinator_LeaseExpiry verifies that the trial of a worker that never
+// reports is handed out again once its lease expires, and that the late
+// report is rejected.
+func TestCoordinator_LeaseExpiry(t *testing.T) {
+	exp := testexp.New(t, testexp.Design{})
+	coord := newCoordinator(t, exp, Options{LeaseTimeout: 50 * time.Millisecond})
+	srv := httptest.NewServer(coord)
+	defer srv.Close()
+
+	stray, status := leaseTrial(t, srv.URL)
+	if status != http.StatusOK {
+		t.Fatalf("lease = %d, want 200", status)
+	}
+	time.Sleep(100 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	w := &Worker{URL: srv.URL, Name: "rig", Measure: measure, PollInterval: time.Millisecond}
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, err := coord.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if got := exp.ResultsByKey(stray.Trial.Key); len(got) != 1 || got[0].Trial.Metadata[MetaWorker] != "rig" {
+		t.Errorf("results of the stray trial = %+v, want one from rig", got)
+	}
+
+	body, _ := json.Marshal(Report{Lease: stray.Lease, Worker: "stray", Observations: []float64{1}})
+	resp, err := http.Post(srv.URL+"/result", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("late report = %s, want 409", resp.Status)
+	}
+	if _, status := leaseTrial(t, srv.URL); status != http.StatusGone {
+		t.Errorf("lease after completion = %d, want 410", status)
+	}
+}
+
+// TestCoordinator_MaxAttempts verifies that a trial failing on every attempt
+// fails the experiment.
+func TestCoordinator_MaxAttempts(t *testing.T) {
+	exp := testexp.New(t, testexp.Design{})
+	coord := newCoordinator(t, exp, Options{MaxAttempts: 2})
+	srv := httptest.NewServer(coord)
+	defer srv.Close()
+
+	broken := func(ctx context.Context, trial taguchi.Trial) (float64, error) {
+		if trial.Row == 2 && trial.Noise["N"] == 1 {
+			return 0, errors.New("sensor offline")
+		}
+		return measure(ctx, trial)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	w := &Worker{URL: srv.URL, Name: "rig", Measure: broken, PollInterval: time.Millisecond}
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	_, err := coord.Wait(ctx)
+	if err == nil || !strings.Contains(err.Error(), "failed 2 times, last on worker rig: run 1: sensor offline") {
+		t.Errorf("Wait = %v, want the repeated failure", err)
+	}
+	if s := coord.Status(); !s.Done || s.Err == "" || s.Failures != 2 {
+		t.Errorf("status = %+v", s)
+	}
+}
+
+// TestCoordinator_Resume verifies that trials recorded before the
+// coordinator starts are not handed out again.
+func TestCoordinator_Resume(t *testing.T) {
+	exp := testexp.New(t, testexp.Design{})
+	trials := exp.GenerateTrials()
+	for _, trial := range trials[:len(trials)-1] {
+		y, _ := measure(context.Background(), trial)
+		if err := exp.AddResult(trial, []float64{y}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coord := newCoordinator(t, exp, Options{})
+	if s := coord.Status(); s.Pending != 1 || s.Completed != len(trials)-1 {
+		t.Fatalf("status = %+v, want 1 pending trial", s)
+	}
+	a, status := coord.lease("rig")
+	if status != http.StatusOK || a.Trial.Key != trials[len(trials)-1].Key {
+		t.Fatalf("lease = %d %+v, want the last trial", status, a.Trial)
+	}
+	if _, status := coord.lease("rig"); status != http.StatusNoContent {
+		t.Errorf("second lease = %d, want 204", status)
+	}
+	if _, err := coord.report(Report{Lease: a.Lease, Observations: []float64{1}}); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if _, err := coord.Wait(context.Background()); err != nil {
+		t.Errorf("Wait: %v", err)
+	}
+}
+
+// TestWorker_RejectedReport verifies that a worker moves on when the
+// coordinator rejects a report with a 4xx or 503 status, passing the
+// rejection to Errors, and stops when it fails with another 5xx status.
+func TestWorker_RejectedReport(t *testing.T) {
+	trial := testexp.New(t, testexp.Design{}).GenerateTrials()[0]
+	for _, tt := range []struct {
+		status  int
+		wantErr bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusConflict, false},
+		{http.StatusServiceUnavailable, false},
+		{http.StatusInternalServerError, true},
+	} {
+		var leases, reports int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/lease":
+				if leases++; leases > 2 {
+					w.WriteHeader(http.StatusGone)
+					return
+				}
+				writeJSON(w, Assignment{Lease: fmt.Sprint(leases), Trial: trial, Repetitions: 1, Deadline: time.Now().Add(time.Minute)})
+			case "/result":
+				if reports++; reports == 1 {
+					http.Error(w, "rejected", tt.status)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		var rejected []error
+		w := &Worker{URL: srv.URL, Name: "rig", Measure: measure, Errors: func(err error) { rejected = append(rejected, err) }}
+		err := w.Run(context.Background())
+		srv.Close()
+		switch {
+		case tt.wantErr && err == nil:
+			t.Errorf("%d: Run returned nil, want the error", tt.status)
+		case !tt.wantErr && err != nil:
+			t.Errorf("%d: Run: %v", tt.status, err)
+		case !tt.wantErr && (reports != 2 || len(rejected) != 1):
+			t.Errorf("%d: %d reports, %d passed to Errors, want 2 and 1", tt.status, reports, len(rejected))
+		case tt.wantErr && reports != 1:
+			t.Errorf("%d: %d reports, want the worker to stop after the first", tt.status, reports)
+		}
+	}
+}
+
+// failingStore is a taguchi.Store whose AddResult fails while down is set.
+type failingStore struct {
+	down bool
+}
+
+func (s *failingStore) SaveExperiment(taguchi.Design, []taguchi.TrialResult) error { return nil }
+func (s *failingStore) Load() (taguchi.Design, []taguchi.TrialResult, error) {
+	return taguchi.Design{}, nil, errors.New("not implemented")
+}
+
+func (s *failingStore) AddResult(taguchi.TrialResult) error {
+	if s.down {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+// TestCoordinator_RecordFailure verifies that a result the experiment fails
+// to record is answered with 503 and its trial handed out again, without
+// failing the experiment.
+func TestCoordinator_RecordFailure(t *testing.T) {
+	exp := testexp.New(t, testexp.Design{})
+	store := &failingStore{down: true}
+	if err := exp.AttachStore(store); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	coord := newCoordinator(t, exp, Options{})
+	a, status := coord.lease("rig")
+	if status != http.StatusOK {
+		t.Fatalf("lease = %d, want 200", status)
+	}
+	if status, err := coord.report(Report{Lease: a.Lease, Observations: []float64{1}}); status != http.StatusServiceUnavailable || err == nil {
+		t.Fatalf("report with the store down = %d, %v; want 503", status, err)
+	}
+	if s := coord.Status(); s.Done || s.Failures != 0 || s.Pending != s.Total {
+		t.Fatalf("status after the failed report = %+v, want every trial pending", s)
+	}
+
+	store.down = false
+	again, status := coord.lease("rig")
+	if status != http.StatusOK || again.Trial.Key != a.Trial.Key {
+		t.Fatalf("lease = %d %+v, want the trial of the failed report", status, again.Trial)
+	}
+	if _, err := coord.report(Report{Lease: again.Lease, Observations: []float64{1}}); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if got := exp.ResultsByKey(a.Trial.Key); len(got) != 1 {
+		t.Errorf("%d results of the trial, want 1", len(got))
+	}
+}
+
+// TestCoordinator_Rejects verifies that blocked designs are rejected and
+// that the status is only served to GET requests.
+func TestCoordinator_Rejects(t *testing.T) {
+	exp := testexp.New(t, testexp.Design{})
+	exp.RunOrder.Blocks = 2
+	if _, err := NewCoordinator(exp, Options{}); err == nil {
+		t.Error("NewCoordinator with replicate blocks: want error")
+	}
+
+	srv := httptest.NewServer(newCoordinator(t, testexp.New(t, testexp.Design{}), Options{}))
+	defer srv.Close()
+	resp, err := http.Post(srv.URL+"/status", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /status = %s, want 405", resp.Status)
+	}
+}
@@ -0,0 +1,111 @@
+package distributed
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Served is a coordinator as hosted by a Registry, whatever the parameter
+// type of its experiment.
+type Served interface {
+	http.Handler
+	Status() Status
+}
+
+// Registry hosts the coordinators of several named experiments on one
+// server, so a lab service can run all of a team's ongoing studies. Each
+// coordinator's API is served under /experiments/{name}, and workers pick
+// the experiment with Worker.Experiment:
+//
+//	POST /experiments/{name}/lease    as POST /lease of the named coordinator
+//	POST /experiments/{name}/result   as POST /result
+//	GET  /experiments/{name}/status   as GET /status
+//	GET  /experiments                 the Status of every experiment by name
+//
+// Unknown experiments are answered with 404. Experiments may be added and
+// removed while the registry serves requests.
+type Registry struct {
+	mu           sync.RWMutex
+	coordinators map[string]Served
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{coordinators: map[string]Served{}}
+}
+
+// Add registers the coordinator of the experiment name. It returns an error
+// when the name is empty, contains a slash or is already registered.
+func (reg *Registry) Add(name string, c Served) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid experiment name %q", name)
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if _, ok := reg.coordinators[name]; ok {
+		return fmt.Errorf("experiment %q is already registered", name)
+	}
+	reg.coordinators[name] = c
+	return nil
+}
+
+// Remove unregisters the experiment name, e.g. once its study is analyzed.
+// Workers asking for it afterwards get 404.
+func (reg *Registry) Remove(name string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	delete(reg.coordinators, name)
+}
+
+// Get returns the coordinator of the experiment name.
+func (reg *Registry) Get(name string) (Served, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	c, ok := reg.coordinators[name]
+	return c, ok
+}
+
+// Names returns the registered experiment names in sorted order.
+func (reg *Registry) Names() []string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	names := make([]string, 0, len(reg.coordinators))
+	for name := range reg.coordinators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP routes the requests to the coordinator of the named experiment
+// (see Registry).
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest, ok := strings.CutPrefix(r.URL.Path, "/experiments")
+	switch {
+	case !ok || rest != "" && rest[0] != '/':
+		http.NotFound(w, r)
+	case rest == "" || rest == "/":
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		statuses := map[string]Status{}
+		for _, name := range reg.Names() {
+			if c, ok := reg.Get(name); ok {
+				statuses[name] = c.Status()
+			}
+		}
+		writeJSON(w, statuses)
+	default:
+		name, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+		c, ok := reg.Get(name)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown experiment %q", name), http.StatusNotFound)
+			return
+		}
+		http.StripPrefix("/experiments/"+name, c).ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,110 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/internal/testexp"
+)
+
+// TestRegistry verifies that one server hosts several experiments, that
+// workers only measure the trials of the experiment they name and that
+// unknown experiments are answered with 404.
+func TestRegistry(t *testing.T) {
+	latency := testexp.New(t, testexp.Design{})
+	throughput := testexp.New(t, testexp.Design{Goal: taguchi.LargerTheBetter{}})
+	coords := map[string]*Coordinator[struct{}]{
+		"latency":    newCoordinator(t, latency, Options{}),
+		"throughput": newCoordinator(t, throughput, Options{Repetitions: 2}),
+	}
+	reg := NewRegistry()
+	for name, c := range coords {
+		if err := reg.Add(name, c); err != nil {
+			t.Fatalf("Add(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"latency", "", "a/b"} {
+		if err := reg.Add(name, coords["latency"]); err == nil {
+			t.Errorf("Add(%q) succeeded, want an error", name)
+		}
+	}
+	tokens := map[string]taguchi.Role{"rig": taguchi.RoleWriter}
+	srv := httptest.NewServer(taguchi.TokenAuth{Tokens: tokens, Handler: reg})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	errs := map[string]error{}
+	var mu sync.Mutex
+	for name := range coords {
+		w := &Worker{URL: srv.URL, Experiment: name, Name: "rig-" + name, Token: "rig", Measure: measure, PollInterval: time.Millisecond}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := w.Run(ctx)
+			mu.Lock()
+			errs[name] = err
+			mu.Unlock()
+		}(name)
+	}
+	for name, c := range coords {
+		if _, err := c.Wait(ctx); err != nil {
+			t.Fatalf("%s: Wait: %v", name, err)
+		}
+	}
+	wg.Wait()
+	for name, err := range errs {
+		if err != nil {
+			t.Errorf("worker of %s: %v", name, err)
+		}
+	}
+	for name, exp := range map[string]*taguchi.Experiment[struct{}]{"latency": latency, "throughput": throughput} {
+		for _, r := range exp.Results {
+			if got := r.Trial.Metadata[MetaWorker]; got != "rig-"+name {
+				t.Errorf("%s: trial %d measured by %q", name, r.Trial.ID, got)
+			}
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/experiments?token=rig", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses map[string]Status
+	json.NewDecoder(resp.Body).Decode(&statuses)
+	resp.Body.Close()
+	if len(statuses) != 2 || !statuses["latency"].Done || statuses["throughput"].Completed != len(throughput.GenerateTrials()) {
+		t.Errorf("GET /experiments = %+v", statuses)
+	}
+	resp, err = http.Post(srv.URL+"/experiments?token=rig", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /experiments = %s, want 405", resp.Status)
+	}
+
+	reg.Remove("latency")
+	for _, path := range []string{"/experiments/latency/status", "/experiments/missing/status", "/status", "/experimentsfoo"} {
+		resp, err := http.Get(srv.URL + path + "?token=rig")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s = %s, want 404", path, resp.Status)
+		}
+	}
+	if got := reg.Names(); len(got) != 1 || got[0] != "throughput" {
+		t.Errorf("Names after Remove = %v, want [throughput]", got)
+	}
+}
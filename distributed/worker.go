@@ -0,0 +1,164 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// DefaultPollInterval is the wait before a worker asks for a trial again
+// when Worker.PollInterval is zero and every pending trial is leased.
+const DefaultPollInterval = 5 * time.Second
+
+// Worker leases trials from a Coordinator, measures them and reports the
+// observations. A failed measurement is reported to the coordinator, which
+// hands the trial out again, and the worker moves on to the next trial.
+// URL: Base URL of the coordinator, or of the Registry hosting it.
+// Experiment: Name of the experiment in a Registry; empty for a coordinator served on its own.
+// Name: Worker name, recorded with its results under MetaWorker (the hostname when empty).
+// Token: Bearer token sent with every request, for coordinators behind taguchi.TokenAuth.
+// Measure: Measures one run of a trial; its context carries the trial (see taguchi.TrialFromContext).
+// Client: HTTP client for the requests (http.DefaultClient when nil).
+// PollInterval: Wait before asking again when every pending trial is leased (DefaultPollInterval when zero).
+// Errors: Called with every report the coordinator rejected; nil ignores them.
+type Worker struct {
+	URL          string
+	Experiment   string
+	Name         string
+	Token        string
+	Measure      taguchi.MeasureFunc
+	Client       *http.Client
+	PollInterval time.Duration
+	Errors       func(error)
+}
+
+// Run measures trials until the experiment is over, returning nil, or until
+// ctx ends, the coordinator cannot be reached or it fails with a 5xx status,
+// returning the error. A rejected report, e.g. for an expired lease, with
+// invalid observations or one the coordinator could not record for the
+// moment (503), is passed to w.Errors and the worker moves on.
+func (w *Worker) Run(ctx context.Context) error {
+	name := w.Name
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	poll := w.PollInterval
+	if poll <= 0 {
+		poll = DefaultPollInterval
+	}
+	for {
+		var a Assignment
+		status, err := w.post(ctx, "/lease", struct{ Worker string }{name}, &a)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case http.StatusGone:
+			return nil
+		case http.StatusNoContent:
+			select {
+			case <-time.After(poll):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		rep := Report{Lease: a.Lease, Worker: name}
+		rep.Observations, err = w.measure(ctx, a, name)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			rep.Err = err.Error()
+		}
+		// A report for an expired lease is rejected with 409, the trial having
+		// been handed to another worker in the meantime, and one with invalid
+		// observations with 400 and one that could not be recorded with 503,
+		// the trial being handed out again.
+		if status, err := w.post(ctx, "/result", rep, nil); err != nil {
+			if status == 0 || status >= http.StatusInternalServerError && status != http.StatusServiceUnavailable {
+				return err
+			}
+			if w.Errors != nil {
+				w.Errors(err)
+			}
+		}
+	}
+}
+
+// measure runs the warmup and measured runs of an assignment.
+func (w *Worker) measure(ctx context.Context, a Assignment, name string) ([]float64, error) {
+	ctx, cancel := context.WithDeadline(ctx, a.Deadline)
+	defer cancel()
+	ctx = taguchi.ContextWithTrial(ctx, taguchi.TrialInfo{
+		Trial:    a.Trial,
+		Row:      a.Trial.Row,
+		Metadata: map[string]string{MetaWorker: name},
+	})
+	for i := 0; i < a.Warmup; i++ {
+		if _, err := w.Measure(ctx, a.Trial); err != nil {
+			return nil, fmt.Errorf("warmup run %d: %w", i+1, err)
+		}
+	}
+	observations := make([]float64, 0, a.Repetitions)
+	for i := 0; i < a.Repetitions; i++ {
+		y, err := w.Measure(ctx, a.Trial)
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", i+1, err)
+		}
+		observations = append(observations, y)
+	}
+	return observations, nil
+}
+
+// post sends body as JSON to the coordinator and decodes a 200 response into
+// out. It fails on a transport error and on any status but 200, 204 and 410.
+func (w *Worker) post(ctx context.Context, path string, body, out any) (int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("worker %s: %w", path, err)
+	}
+	base := strings.TrimSuffix(w.URL, "/")
+	if w.Experiment != "" {
+		base += "/experiments/" + url.PathEscape(w.Experiment)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+path, bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("worker %s: %w", path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("worker %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return resp.StatusCode, fmt.Errorf("worker %s: %w", path, err)
+			}
+		}
+		return resp.StatusCode, nil
+	case http.StatusNoContent, http.StatusGone:
+		return resp.StatusCode, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	return resp.StatusCode, fmt.Errorf("worker %s: %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,35 @@
+package taguchi
+
+import "fmt"
+
+// InfeasibleTrial records a trial rejected by a DryRun validation callback.
+// Trial: The trial whose configuration cannot be realized.
+// Err: The reason reported by the validation callback.
+type InfeasibleTrial struct {
+	Trial Trial
+	Err   error
+}
+
+// Error formats the infeasible trial together with its control configuration.
+func (t InfeasibleTrial) Error() string {
+	return fmt.Sprintf("trial %d (row %d, control %v): %v", t.Trial.ID, t.Trial.Row+1, t.Trial.Control, t.Err)
+}
+
+// Unwrap returns the validation error.
+func (t InfeasibleTrial) Unwrap() error {
+	return t.Err
+}
+
+// DryRun walks every trial of the design and invokes validate on it without
+// collecting observations. It returns the trials for which validate reported
+// an error, so unrealizable configurations (e.g., not enough memory for the
+// chosen buffer size) surface before the real, expensive run starts.
+func (e *Experiment[P]) DryRun(validate func(trial Trial) error) []InfeasibleTrial {
+	var infeasible []InfeasibleTrial
+	for _, trial := range e.GenerateTrials() {
+		if err := validate(trial); err != nil {
+			infeasible = append(infeasible, InfeasibleTrial{Trial: trial, Err: err})
+		}
+	}
+	return infeasible
+}
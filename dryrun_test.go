@@ -0,0 +1,59 @@
+package taguchi
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestDryRun verifies that DryRun validates every trial of the design once,
+// reports the rejected ones with their errors and records no results.
+func TestDryRun(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "BufferMB", Levels: []float64{64, 512, 4096}},
+		{Name: "Workers", Levels: []float64{1, 4, 16}},
+	}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+
+	errNoMemory := errors.New("not enough memory")
+	seen := map[int]int{}
+	infeasible := exp.DryRun(func(trial Trial) error {
+		seen[trial.ID]++
+		if trial.Control["BufferMB"]*trial.Control["Workers"] > 8192 {
+			return errNoMemory
+		}
+		return nil
+	})
+
+	if len(seen) != len(exp.GenerateTrials()) {
+		t.Errorf("validated %d trials, want all %d", len(seen), len(exp.GenerateTrials()))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("trial %d validated %d times", id, n)
+		}
+	}
+	// Only BufferMB 4096 with 4 or 16 workers exceeds the budget, under both
+	// noise levels.
+	if len(infeasible) != 4 {
+		t.Fatalf("got %d infeasible trials, want 4: %v", len(infeasible), infeasible)
+	}
+	for _, it := range infeasible {
+		if it.Trial.Control["BufferMB"] != 4096 || it.Trial.Control["Workers"] < 4 {
+			t.Errorf("trial %v reported infeasible", it.Trial.Control)
+		}
+		if !errors.Is(it, errNoMemory) {
+			t.Errorf("infeasible trial error %v does not wrap the validation error", it)
+		}
+	}
+	if len(exp.Results) != 0 {
+		t.Errorf("DryRun recorded %d results", len(exp.Results))
+	}
+
+	if got := exp.DryRun(func(Trial) error { return nil }); got != nil {
+		t.Errorf("DryRun with feasible trials = %v, want nil", got)
+	}
+}
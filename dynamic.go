@@ -0,0 +1,89 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+)
+
+// CalculateSNR computes the dynamic SNR from (signal, response) pairs; a
+// trailing unpaired observation is ignored (AddResult rejects results with
+// one).
+// Formula: 10 * log10(β² / σ²), with β the least-squares slope of the ideal
+// function and σ² the residual variance around it.
+// Too few pairs to estimate σ² yield 0.
+func (d Dynamic) CalculateSNR(obs []float64) float64 {
+	beta, variance, ok := d.fit(obs)
+	if !ok {
+		return 0
+	}
+	if variance <= 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(beta*beta/variance)
+}
+
+// String returns the human-readable name for the Dynamic goal.
+func (d Dynamic) String() string {
+	if d.Linear {
+		return "Dynamic-Linear"
+	}
+	return "Dynamic"
+}
+
+// Sensitivity returns the slope β of the ideal function fitted to the
+// (signal, response) pairs, for adjusting the response to the desired
+// slope once variability is minimized. It returns 0 for too few pairs.
+func (d Dynamic) Sensitivity(obs []float64) float64 {
+	beta, _, _ := d.fit(obs)
+	return beta
+}
+
+// fit returns the slope and residual variance of the ideal function. ok is
+// false when there are too few pairs (or no signal spread) for both.
+func (d Dynamic) fit(obs []float64) (beta, variance float64, ok bool) {
+	pairs := len(obs) / 2
+	var sm, sy, smm, smy float64
+	for i := 0; i < pairs; i++ {
+		m, y := obs[2*i], obs[2*i+1]
+		sm += m
+		sy += y
+		smm += m * m
+		smy += m * y
+	}
+	n := float64(pairs)
+	df := pairs - 1
+	var alpha float64
+	if d.Linear {
+		df = pairs - 2
+		sxx := smm - sm*sm/n
+		if df < 1 || sxx == 0 {
+			return 0, 0, false
+		}
+		beta = (smy - sm*sy/n) / sxx
+		alpha = (sy - beta*sm) / n
+	} else {
+		if df < 1 || smm == 0 {
+			return 0, 0, false
+		}
+		beta = smy / smm
+	}
+	ss := 0.0
+	for i := 0; i < pairs; i++ {
+		r := obs[2*i+1] - alpha - beta*obs[2*i]
+		ss += r * r
+	}
+	return beta, ss / float64(df), true
+}
+
+// AddSignalResponses records a trial of a dynamic experiment: the response
+// measured at each signal level, stored as (signal, response) pairs.
+func (e *Experiment[P]) AddSignalResponses(trial Trial, signals, responses []float64) error {
+	if len(signals) != len(responses) {
+		return fmt.Errorf("got %d signals for %d responses", len(signals), len(responses))
+	}
+	obs := make([]float64, 0, 2*len(signals))
+	for i := range signals {
+		obs = append(obs, signals[i], responses[i])
+	}
+	return e.AddResult(trial, obs)
+}
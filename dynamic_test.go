@@ -0,0 +1,96 @@
+package taguchi
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDynamic(t *testing.T) {
+	// y = 2M exactly except for ±0.1 at the last two points.
+	obs := []float64{1, 2, 2, 4, 3, 6.1, 4, 7.9}
+	d := Dynamic{}
+	beta := d.Sensitivity(obs)
+	if want := (2 + 8 + 18.3 + 31.6) / 30.0; !almostEqual(beta, want) {
+		t.Fatalf("Sensitivity = %.6f, want %.6f", beta, want)
+	}
+	ss := 0.0
+	for i := 0; i < 4; i++ {
+		r := obs[2*i+1] - beta*obs[2*i]
+		ss += r * r
+	}
+	if got, want := d.CalculateSNR(obs), 10*math.Log10(beta*beta/(ss/3)); !almostEqual(got, want) {
+		t.Errorf("SNR = %.4f, want %.4f", got, want)
+	}
+
+	// An offset ruins the zero-point fit but not the linear one.
+	offset := []float64{1, 12, 2, 14, 3, 16, 4, 18}
+	if got := (Dynamic{Linear: true}).CalculateSNR(offset); !math.IsInf(got, 1) {
+		t.Errorf("linear SNR of a perfect line = %v, want +Inf", got)
+	}
+	if got := d.CalculateSNR(offset); math.IsInf(got, 0) {
+		t.Errorf("zero-point SNR of an offset line = %v, want finite", got)
+	}
+	if got := d.CalculateSNR([]float64{1, 2}); got != 0 {
+		t.Errorf("SNR of a single pair = %v, want 0", got)
+	}
+}
+
+// TestAddSignalResponses verifies that a dynamic experiment prefers the
+// configuration that tracks the signal with less noise.
+func TestAddSignalResponses(t *testing.T) {
+	factors := []ControlFactor{{Name: "Gain", Levels: []float64{1, 2}}}
+	exp, err := NewExperimentFromFactorsUsingArray(Dynamic{}, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	signals := []float64{1, 2, 3}
+	trials := exp.GenerateTrials()
+	if err := exp.AddSignalResponses(trials[0], signals, []float64{1.5, 1.5, 3.5}); err != nil {
+		t.Fatalf("AddSignalResponses: %v", err)
+	}
+	if err := exp.AddSignalResponses(trials[1], signals, []float64{2, 4.1, 5.9}); err != nil {
+		t.Fatalf("AddSignalResponses: %v", err)
+	}
+	if got := mustAnalyze(t, exp).OptimalLevels["Gain"]; got != 2 {
+		t.Errorf("OptimalLevels[Gain] = %v, want 2", got)
+	}
+	if err := exp.AddSignalResponses(trials[0], signals, []float64{1}); err == nil {
+		t.Error("AddSignalResponses accepted mismatched lengths")
+	}
+}
+
+// TestDynamic_PairedOptions verifies that unpaired results are rejected and
+// that the options treating observations one by one, which would split the
+// (signal, response) pairs, are reported as unsupported.
+func TestDynamic_PairedOptions(t *testing.T) {
+	newDynamic := func() *Experiment[struct{}] {
+		return newTestExperiment(t, testDesign{Goal: Dynamic{}, Factors: namedFactors([]float64{1, 2}, "A", "B")})
+	}
+	exp := newDynamic()
+	if err := exp.AddResult(exp.GenerateTrials()[0], []float64{1, 2, 2}); !errors.Is(err, ErrInvalidObservations) {
+		t.Errorf("AddResult with an odd count: err = %v, want ErrInvalidObservations", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		set  func(*Experiment[struct{}])
+	}{
+		{"RobustSNR", func(e *Experiment[struct{}]) { e.RobustSNR = WinsorizeOutliers }},
+		{"RetainSample", func(e *Experiment[struct{}]) { e.Retention = RetainSample }},
+		{"AnalyzeMeans", func(e *Experiment[struct{}]) { e.AnalyzeMeans = true }},
+	} {
+		exp := newDynamic()
+		recordResults(t, exp, singleResult(func(trial Trial) []float64 {
+			b := trial.Control["A"] + trial.Control["B"]
+			return []float64{1, b, 2, 2.1 * b, 3, 2.9 * b}
+		}))
+		tt.set(exp)
+		if _, err := exp.Analyze(); !errors.Is(err, ErrUnsupportedGoal) {
+			t.Errorf("%s: Analyze err = %v, want ErrUnsupportedGoal", tt.name, err)
+		}
+		if err := exp.AppendResult(exp.GenerateTrials()[0], []float64{1, 2, 2, 4}); !errors.Is(err, ErrUnsupportedGoal) {
+			t.Errorf("%s: AppendResult err = %v, want ErrUnsupportedGoal", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package taguchi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ArrayEquivalence maps a user-provided array onto a standard array it is
+// isomorphic to (up to row order, column selection and order, and level labels).
+// Array: The matching standard array.
+// Columns: Standard column (zero-based) matched by each user column.
+// LevelMaps: For each user column, the standard level assigned to each user value.
+// RowOrder: Standard row (zero-based) matched by each user row.
+// Normalized: The user array rewritten with standard levels, in standard row order.
+type ArrayEquivalence struct {
+	Array      ArrayType
+	Columns    []int
+	LevelMaps  []map[int]int
+	RowOrder   []int
+	Normalized [][]int
+}
+
+// ErrNoEquivalentArray is returned by MatchStandardArray when the array is not
+// a permutation or relabeling of any standard array.
+var ErrNoEquivalentArray = errors.New("array is not equivalent to a standard array")
+
+// ErrEquivalenceUndecided is returned by MatchStandardArray when the search
+// budget ran out before equivalence could be proven or ruled out. This can
+// happen for small column subsets of the large mixed-level arrays.
+var ErrEquivalenceUndecided = errors.New("array equivalence undecided: search budget exhausted")
+
+// equivalenceSearchBudget bounds the number of row pinnings explored per
+// candidate array.
+const equivalenceSearchBudget = 1_000_000
+
+// MatchStandardArray checks whether oa is a row permutation, column
+// permutation/selection or level relabeling of a standard array, so designs
+// imported from papers map onto the catalog (and its column conventions).
+// Levels may use any integer labels, e.g. 0/1 or -1/+1. It returns
+// ErrNoEquivalentArray when no standard array matches.
+func MatchStandardArray(oa [][]int) (ArrayEquivalence, error) {
+	if len(oa) == 0 || len(oa[0]) == 0 {
+		return ArrayEquivalence{}, fmt.Errorf("array must not be empty")
+	}
+	for i, row := range oa {
+		if len(row) != len(oa[0]) {
+			return ArrayEquivalence{}, fmt.Errorf("row %d has %d columns, want %d", i+1, len(row), len(oa[0]))
+		}
+	}
+
+	userLevels := make([][]int, len(oa[0]))
+	userIndex := make([][]int, len(oa))
+	for i := range userIndex {
+		userIndex[i] = make([]int, len(oa[0]))
+	}
+	for j := range userLevels {
+		userLevels[j] = distinctColumnValues(oa, j)
+		for i, row := range oa {
+			userIndex[i][j] = sort.SearchInts(userLevels[j], row[j])
+		}
+	}
+
+	for _, info := range StandardArrayInfos() {
+		if info.Runs != len(oa) || info.MaxFactors < len(oa[0]) {
+			continue
+		}
+		s := &equivalenceSearch{
+			user:       userIndex,
+			userLevels: userLevels,
+			std:        StandardArrays[info.Name],
+			stdLevels:  info.Levels,
+			budget:     equivalenceSearchBudget,
+		}
+		if eq, ok := s.solve(); ok {
+			eq.Array = info.Name
+			return eq, nil
+		}
+		if s.budget < 0 {
+			return ArrayEquivalence{}, fmt.Errorf("matching %s: %w", info.Name, ErrEquivalenceUndecided)
+		}
+	}
+	return ArrayEquivalence{}, ErrNoEquivalentArray
+}
+
+// columnCandidate is a standard column a user column may correspond to, with
+// the partial relabeling implied by the rows pinned so far. levels[u] is the
+// standard level of the user's u-th distinct value, or 0 when still unknown.
+type columnCandidate struct {
+	column int
+	levels []int
+}
+
+// equivalenceSearch finds a row correspondence between the user array and a
+// standard array. User rows are pinned to standard rows one at a time; every
+// pin narrows the standard columns (and relabelings) each user column can
+// still correspond to, and after each pin the full arrays are compared under
+// a distinct choice of the remaining candidates.
+type equivalenceSearch struct {
+	user       [][]int // user levels as indexes into userLevels
+	userLevels [][]int
+	std        [][]int
+	stdLevels  []int
+	budget     int
+
+	rowOrder []int
+	usedRows []bool
+}
+
+func (s *equivalenceSearch) solve() (ArrayEquivalence, bool) {
+	candidates := make([][]columnCandidate, len(s.userLevels))
+	for j, values := range s.userLevels {
+		for c, l := range s.stdLevels {
+			if l == len(values) {
+				candidates[j] = append(candidates[j], columnCandidate{column: c, levels: make([]int, l)})
+			}
+		}
+	}
+	s.rowOrder = make([]int, len(s.user))
+	s.usedRows = make([]bool, len(s.std))
+	return s.pin(0, candidates)
+}
+
+// pin maps user row t to each unused standard row in turn.
+func (s *equivalenceSearch) pin(t int, candidates [][]columnCandidate) (ArrayEquivalence, bool) {
+	if eq, ok := s.tryComplete(candidates); ok {
+		return eq, true
+	}
+	if t == len(s.user) {
+		return ArrayEquivalence{}, false
+	}
+	for r := range s.std {
+		if s.usedRows[r] {
+			continue
+		}
+		if s.budget--; s.budget < 0 {
+			return ArrayEquivalence{}, false
+		}
+		next, ok := s.narrow(candidates, s.user[t], s.std[r])
+		if !ok {
+			continue
+		}
+		s.usedRows[r] = true
+		s.rowOrder[t] = r
+		if eq, ok := s.pin(t+1, next); ok {
+			return eq, true
+		}
+		s.usedRows[r] = false
+	}
+	return ArrayEquivalence{}, false
+}
+
+// narrow keeps the candidates consistent with userRow corresponding to stdRow.
+// It fails when some user column is left without candidates or when the
+// columns can no longer be matched to distinct standard columns.
+func (s *equivalenceSearch) narrow(candidates [][]columnCandidate, userRow, stdRow []int) ([][]columnCandidate, bool) {
+	next := make([][]columnCandidate, len(candidates))
+	for j, cands := range candidates {
+		u := userRow[j]
+		for _, cand := range cands {
+			v := stdRow[cand.column]
+			if cand.levels[u] != 0 {
+				if cand.levels[u] == v {
+					next[j] = append(next[j], cand)
+				}
+				continue
+			}
+			taken := false
+			for _, l := range cand.levels {
+				if l == v {
+					taken = true
+					break
+				}
+			}
+			if taken {
+				continue
+			}
+			levels := append([]int(nil), cand.levels...)
+			levels[u] = v
+			next[j] = append(next[j], columnCandidate{column: cand.column, levels: levels})
+		}
+		if len(next[j]) == 0 {
+			return nil, false
+		}
+	}
+	if matchColumns(next) == nil {
+		return nil, false
+	}
+	return next, true
+}
+
+// completionLimit bounds how many relabelings tryComplete enumerates for the
+// levels not yet fixed by pinned rows.
+const completionLimit = 256
+
+// tryComplete checks whether the current distinct choice of candidate columns,
+// together with some completion of the partially known relabelings, makes the
+// relabeled user array a row permutation of the projected standard array.
+// Completions are only enumerated when there are at most completionLimit of
+// them; otherwise more rows have to be pinned first.
+func (s *equivalenceSearch) tryComplete(candidates [][]columnCandidate) (ArrayEquivalence, bool) {
+	choice := matchColumns(candidates)
+	if choice == nil {
+		return ArrayEquivalence{}, false
+	}
+
+	columns := make([]int, len(candidates))
+	partial := make([][]int, len(candidates))
+	options := make([][][]int, len(candidates))
+	total := 1
+	for j, cands := range candidates {
+		cand := cands[choice[j]]
+		columns[j] = cand.column
+		partial[j] = cand.levels
+		options[j] = completeLevels(cand.levels)
+		if total *= len(options[j]); total > completionLimit {
+			return ArrayEquivalence{}, false
+		}
+	}
+
+	pending := map[string]int{}
+	for _, row := range s.std {
+		pending[projectKey(row, columns)]++
+	}
+
+	pick := make([]int, len(candidates))
+	levels := make([][]int, len(candidates))
+	for {
+		for j := range levels {
+			levels[j] = options[j][pick[j]]
+		}
+		if s.matches(levels, pending) {
+			return s.equivalence(columns, levels), true
+		}
+		// Advance the odometer over the completion options.
+		j := 0
+		for ; j < len(pick); j++ {
+			if pick[j]++; pick[j] < len(options[j]) {
+				break
+			}
+			pick[j] = 0
+		}
+		if j == len(pick) {
+			return ArrayEquivalence{}, false
+		}
+	}
+}
+
+// matches reports whether the user rows relabeled with levels form exactly the
+// multiset of projected standard rows counted in pending.
+func (s *equivalenceSearch) matches(levels [][]int, pending map[string]int) bool {
+	remaining := make(map[string]int, len(pending))
+	for k, n := range pending {
+		remaining[k] = n
+	}
+	for _, row := range s.user {
+		k := s.userKey(row, levels)
+		if remaining[k]--; remaining[k] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// equivalence builds the result for a verified column choice and relabeling.
+func (s *equivalenceSearch) equivalence(columns []int, levels [][]int) ArrayEquivalence {
+	eq := ArrayEquivalence{
+		Columns:    columns,
+		LevelMaps:  make([]map[int]int, len(columns)),
+		RowOrder:   make([]int, len(s.user)),
+		Normalized: make([][]int, len(s.std)),
+	}
+	for j := range columns {
+		eq.LevelMaps[j] = make(map[int]int, len(levels[j]))
+		for u, l := range levels[j] {
+			eq.LevelMaps[j][s.userLevels[j][u]] = l
+		}
+	}
+
+	rows := map[string][]int{}
+	for r, row := range s.std {
+		k := projectKey(row, columns)
+		rows[k] = append(rows[k], r)
+	}
+	for i, row := range s.user {
+		k := s.userKey(row, levels)
+		eq.RowOrder[i] = rows[k][0]
+		rows[k] = rows[k][1:]
+		normalized := make([]int, len(columns))
+		for j, u := range row {
+			normalized[j] = levels[j][u]
+		}
+		eq.Normalized[eq.RowOrder[i]] = normalized
+	}
+	return eq
+}
+
+// userKey encodes a user row relabeled to standard levels.
+func (s *equivalenceSearch) userKey(row []int, levels [][]int) string {
+	key := make([]byte, len(row))
+	for j, u := range row {
+		key[j] = byte(levels[j][u])
+	}
+	return string(key)
+}
+
+// projectKey encodes a standard row restricted to the given columns.
+func projectKey(row []int, columns []int) string {
+	key := make([]byte, len(columns))
+	for j, c := range columns {
+		key[j] = byte(row[c])
+	}
+	return string(key)
+}
+
+// completeLevels returns every full relabeling extending a partial one, where
+// unknown entries (0) take the standard levels not used yet.
+func completeLevels(partial []int) [][]int {
+	used := make([]bool, len(partial)+1)
+	var unknown []int
+	for u, l := range partial {
+		if l == 0 {
+			unknown = append(unknown, u)
+		} else {
+			used[l] = true
+		}
+	}
+	var free []int
+	for v := 1; v <= len(partial); v++ {
+		if !used[v] {
+			free = append(free, v)
+		}
+	}
+
+	var out [][]int
+	var rec func(i int)
+	rec = func(i int) {
+		if i == len(unknown) {
+			out = append(out, append([]int(nil), partial...))
+			return
+		}
+		for k, v := range free {
+			if v == 0 {
+				continue
+			}
+			partial[unknown[i]] = v
+			free[k] = 0
+			rec(i + 1)
+			free[k] = v
+		}
+		partial[unknown[i]] = 0
+	}
+	if len(unknown) > 0 {
+		rec(0)
+	} else {
+		out = append(out, append([]int(nil), partial...))
+	}
+	return out
+}
+
+// matchColumns picks a distinct standard column for every user column using
+// augmenting paths, returning nil when no such assignment exists.
+func matchColumns(candidates [][]columnCandidate) []int {
+	owner := map[int]int{}
+	choice := make([]int, len(candidates))
+	var augment func(j int, seen map[int]bool) bool
+	augment = func(j int, seen map[int]bool) bool {
+		for i, cand := range candidates[j] {
+			if seen[cand.column] {
+				continue
+			}
+			seen[cand.column] = true
+			prev, taken := owner[cand.column]
+			if !taken || augment(prev, seen) {
+				owner[cand.column] = j
+				choice[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for j := range candidates {
+		if !augment(j, map[int]bool{}) {
+			return nil
+		}
+	}
+	return choice
+}
+
+// distinctColumnValues returns the sorted distinct values of column j.
+func distinctColumnValues(oa [][]int, j int) []int {
+	seen := map[int]bool{}
+	var values []int
+	for _, row := range oa {
+		if !seen[row[j]] {
+			seen[row[j]] = true
+			values = append(values, row[j])
+		}
+	}
+	sort.Ints(values)
+	return values
+}
@@ -0,0 +1,63 @@
+package taguchi
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestMatchStandardArray_PermutedAndRelabeled verifies that a standard array
+// with shuffled rows and columns, dropped columns and 0-based levels is mapped
+// back onto the catalog.
+func TestMatchStandardArray_PermutedAndRelabeled(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for _, name := range []ArrayType{L9, L16, L18} {
+		std := StandardArrays[name]
+		cols := rng.Perm(len(std[0]))[:len(std[0])-1]
+		rows := rng.Perm(len(std))
+
+		user := make([][]int, len(std))
+		for i, r := range rows {
+			user[i] = make([]int, len(cols))
+			for j, c := range cols {
+				user[i][j] = std[r][c] - 1
+			}
+		}
+
+		eq, err := MatchStandardArray(user)
+		if err != nil {
+			t.Fatalf("%s: MatchStandardArray: %v", name, err)
+		}
+		info, _ := LookupArray(eq.Array)
+		if info.Runs != len(std) {
+			t.Errorf("%s: matched %s with %d runs", name, eq.Array, info.Runs)
+		}
+		for i, row := range user {
+			for j, v := range row {
+				if got := eq.Normalized[eq.RowOrder[i]][j]; got != eq.LevelMaps[j][v] {
+					t.Fatalf("%s: normalized[%d][%d] = %d, want %d", name, eq.RowOrder[i], j, got, eq.LevelMaps[j][v])
+				}
+			}
+		}
+		if eq.Array == name {
+			projected := make([][]int, len(std))
+			for i, row := range std {
+				projected[i] = make([]int, len(eq.Columns))
+				for j, c := range eq.Columns {
+					projected[i][j] = row[c]
+				}
+			}
+			if !reflect.DeepEqual(projected, eq.Normalized) {
+				t.Errorf("%s: normalized array does not match the standard columns", name)
+			}
+		}
+	}
+}
+
+// TestMatchStandardArray_NotOrthogonal verifies that a non-orthogonal array is rejected.
+func TestMatchStandardArray_NotOrthogonal(t *testing.T) {
+	oa := [][]int{{1, 1, 1}, {1, 2, 2}, {2, 1, 2}, {2, 2, 2}}
+	if _, err := MatchStandardArray(oa); err != ErrNoEquivalentArray {
+		t.Errorf("MatchStandardArray: got %v, want ErrNoEquivalentArray", err)
+	}
+}
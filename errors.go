@@ -0,0 +1,83 @@
+package taguchi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Error categories of the library, for callers such as CLIs and services that
+// map failures to user messages. Errors keep their descriptive text and match
+// their category under errors.Is, e.g. errors.Is(err, ErrMissingResults).
+var (
+	// ErrUnknownArray: a named orthogonal array is not in StandardArrays.
+	ErrUnknownArray = errors.New("orthogonal array not defined")
+	// ErrInvalidArray: a user-supplied array is malformed or, unless allowed, not orthogonal.
+	ErrInvalidArray = errors.New("invalid orthogonal array")
+	// ErrArrayTooSmall: the array has no free column with the levels a factor needs.
+	ErrArrayTooSmall = errors.New("array too small for the factors")
+	// ErrUnknownFactor: a factor name is not one of the experiment's factors.
+	ErrUnknownFactor = errors.New("unknown factor")
+	// ErrLevelMismatch: a level or level count does not match the factor or array column.
+	ErrLevelMismatch = errors.New("level mismatch")
+	// ErrTrialNotInDesign: a trial's row, factors or noise condition are not part of the design.
+	ErrTrialNotInDesign = errors.New("trial is not part of the design")
+	// ErrInvalidObservations: observations are empty or not finite.
+	ErrInvalidObservations = errors.New("invalid observations")
+	// ErrDuplicateResult: a trial already has a result and replicates were not requested.
+	ErrDuplicateResult = errors.New("trial already has a result")
+	// ErrMissingResults: the analysis needs results that have not been recorded.
+	ErrMissingResults = errors.New("results missing")
+	// ErrUnsupportedGoal: an experiment option cannot be used with the optimization goal.
+	ErrUnsupportedGoal = errors.New("option not supported by the goal")
+)
+
+// kindError assigns an error to one of the categories above. It reports the
+// message of err and matches both kind and err under errors.Is and errors.As.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// errorf formats an error like fmt.Errorf and assigns it to kind.
+func errorf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// MissingResultsError is returned by Analyze when orthogonal array rows have
+// no results and AllowIncomplete is not set. It matches ErrMissingResults.
+// Rows: One-based array rows without results.
+type MissingResultsError struct {
+	Rows []int
+}
+
+func (e *MissingResultsError) Error() string {
+	rows := make([]string, len(e.Rows))
+	for i, r := range e.Rows {
+		rows[i] = strconv.Itoa(r)
+	}
+	if len(rows) == 1 {
+		return fmt.Sprintf("orthogonal array row %s has no results", rows[0])
+	}
+	return fmt.Sprintf("orthogonal array rows %s have no results", strings.Join(rows, ", "))
+}
+
+func (e *MissingResultsError) Unwrap() error { return ErrMissingResults }
+
+// TrialError is returned by AddResult and the other result recorders when a
+// trial is rejected.
+// Trial: ID of the rejected trial.
+// Err: The reason, matching ErrTrialNotInDesign, ErrLevelMismatch, ErrInvalidObservations or ErrDuplicateResult.
+type TrialError struct {
+	Trial int
+	Err   error
+}
+
+func (e *TrialError) Error() string { return fmt.Sprintf("trial %d: %v", e.Trial, e.Err) }
+
+func (e *TrialError) Unwrap() error { return e.Err }
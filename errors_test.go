@@ -0,0 +1,85 @@
+package taguchi
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestErrors_Constructors verifies that unknown and too small arrays and
+// malformed user arrays report their category.
+func TestErrors_Constructors(t *testing.T) {
+	two := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	if _, err := NewExperimentFromFactors(SmallerTheBetter{}, two, "L5", nil); !errors.Is(err, ErrUnknownArray) {
+		t.Errorf("unknown array: err = %v, want ErrUnknownArray", err)
+	}
+	four := append(slices.Clone(two), ControlFactor{Name: "C", Levels: []float64{1, 2}}, ControlFactor{Name: "D", Levels: []float64{1, 2}})
+	if _, err := NewExperimentFromFactors(SmallerTheBetter{}, four, L4, nil); !errors.Is(err, ErrArrayTooSmall) {
+		t.Errorf("too many factors: err = %v, want ErrArrayTooSmall", err)
+	}
+	bad := [][]int{{1, 1}, {1, 2}, {2, 1}, {1, 2}}
+	if _, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, two, bad, nil); !errors.Is(err, ErrInvalidArray) {
+		t.Errorf("unbalanced array: err = %v, want ErrInvalidArray", err)
+	}
+}
+
+// TestErrors_AddResult verifies that rejected results are TrialErrors
+// carrying the trial ID and the category of the rejection.
+func TestErrors_AddResult(t *testing.T) {
+	exp := parallelExperiment(t)
+	trials := exp.GenerateTrials()
+	if err := exp.AddResult(trials[0], []float64{1}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+
+	wrongLevel := trials[1]
+	wrongLevel.Control = map[string]float64{"A": 3 - trials[1].Control["A"], "B": trials[1].Control["B"]}
+	otherNoise := trials[1]
+	otherNoise.Noise = map[string]float64{"N": 7}
+	tests := []struct {
+		name  string
+		trial int
+		err   error
+		kind  error
+	}{
+		{"duplicate", trials[0].ID, exp.AddResult(trials[0], []float64{2}), ErrDuplicateResult},
+		{"level", trials[1].ID, exp.AddResult(wrongLevel, []float64{1}), ErrLevelMismatch},
+		{"noise", trials[1].ID, exp.AddResult(otherNoise, []float64{1}), ErrTrialNotInDesign},
+		{"observations", trials[1].ID, exp.AddResult(trials[1], []float64{math.NaN()}), ErrInvalidObservations},
+	}
+	for _, tc := range tests {
+		if !errors.Is(tc.err, tc.kind) {
+			t.Errorf("%s: err = %v, want %v", tc.name, tc.err, tc.kind)
+		}
+		var te *TrialError
+		if !errors.As(tc.err, &te) || te.Trial != tc.trial {
+			t.Errorf("%s: err = %#v, want a TrialError for trial %d", tc.name, tc.err, tc.trial)
+		}
+	}
+}
+
+// TestErrors_MissingResults verifies that Analyze reports the rows without
+// results as a MissingResultsError.
+func TestErrors_MissingResults(t *testing.T) {
+	exp := parallelExperiment(t)
+	for _, trial := range exp.GenerateTrials() {
+		if trial.Row == 1 || trial.Row == 3 {
+			continue
+		}
+		if err := exp.AddResult(trial, []float64{1 + trial.Control["A"]}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	_, err := exp.Analyze()
+	var missing *MissingResultsError
+	if !errors.As(err, &missing) || !slices.Equal(missing.Rows, []int{2, 4}) {
+		t.Fatalf("Analyze: err = %v, want rows [2 4] missing", err)
+	}
+	if !errors.Is(err, ErrMissingResults) || err.Error() != "orthogonal array rows 2, 4 have no results" {
+		t.Errorf("Analyze: err = %q, want ErrMissingResults with the rows listed", err)
+	}
+}
@@ -0,0 +1,62 @@
+package taguchi
+
+import "time"
+
+// CostFunc estimates the duration and cost of a single repetition of a trial.
+// Level-dependent estimates can inspect trial.Control and trial.Noise.
+type CostFunc func(trial Trial) (duration time.Duration, cost float64)
+
+// DesignEstimate reports the expected effort of running a design.
+// Array: Name of the orthogonal array, empty for custom arrays.
+// Repetitions: Number of repetitions per trial.
+// Trials: Number of trials in the design (control rows × noise combinations).
+// Runs: Total number of executions (Trials × Repetitions).
+// Duration: Total expected runtime.
+// Cost: Total expected cost.
+// Err: Why the design cannot be used for the given factors, if it cannot.
+type DesignEstimate struct {
+	Array       ArrayType
+	Repetitions int
+	Trials      int
+	Runs        int
+	Duration    time.Duration
+	Cost        float64
+	Err         error
+}
+
+// Estimate returns the expected runtime and cost of running every trial of the
+// experiment the given number of times.
+func (e *Experiment[P]) Estimate(repetitions int, costFn CostFunc) DesignEstimate {
+	trials := e.GenerateTrials()
+	est := DesignEstimate{
+		Repetitions: repetitions,
+		Trials:      len(trials),
+		Runs:        len(trials) * repetitions,
+	}
+	for _, trial := range trials {
+		d, c := costFn(trial)
+		est.Duration += d * time.Duration(repetitions)
+		est.Cost += c * float64(repetitions)
+	}
+	return est
+}
+
+// EstimateDesigns compares candidate standard arrays and repetition counts for
+// the same factors, returning one estimate per combination in input order.
+// Arrays that cannot accommodate the factors are reported with Err set.
+func EstimateDesigns(controlFactors []ControlFactor, noiseFactors []NoiseFactor, arrays []ArrayType, repetitions []int, costFn CostFunc) []DesignEstimate {
+	var estimates []DesignEstimate
+	for _, arrayName := range arrays {
+		exp, err := NewExperimentFromFactors(nil, controlFactors, arrayName, noiseFactors)
+		for _, reps := range repetitions {
+			if err != nil {
+				estimates = append(estimates, DesignEstimate{Array: arrayName, Repetitions: reps, Err: err})
+				continue
+			}
+			est := exp.Estimate(reps, costFn)
+			est.Array = arrayName
+			estimates = append(estimates, est)
+		}
+	}
+	return estimates
+}
@@ -0,0 +1,88 @@
+package taguchi
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEstimateDesigns verifies the trial and run counts, duration and cost of
+// candidate arrays and repetition counts, with a level-dependent cost.
+func TestEstimateDesigns(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+	}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	// A run takes A seconds and costs 1 per second.
+	costFn := func(trial Trial) (time.Duration, float64) {
+		return time.Duration(trial.Control["A"]) * time.Second, trial.Control["A"]
+	}
+
+	tests := []struct {
+		array       ArrayType
+		repetitions int
+		trials      int
+		duration    time.Duration
+		fail        bool
+	}{
+		// L4: 4 rows × 2 noise levels, half of the rows at A=1 and half at A=2.
+		{array: L4, repetitions: 1, trials: 8, duration: 12 * time.Second},
+		{array: L4, repetitions: 3, trials: 8, duration: 36 * time.Second},
+		{array: L8, repetitions: 1, trials: 16, duration: 24 * time.Second},
+		{array: L8, repetitions: 5, trials: 16, duration: 120 * time.Second},
+		{array: L9, repetitions: 2, fail: true},
+	}
+	arrays := []ArrayType{L4, L8, L9}
+	repetitions := []int{1, 2, 3, 5}
+	estimates := EstimateDesigns(factors, noise, arrays, repetitions, costFn)
+	if len(estimates) != len(arrays)*len(repetitions) {
+		t.Fatalf("got %d estimates, want one per array and repetition count (%d)", len(estimates), len(arrays)*len(repetitions))
+	}
+	byDesign := map[ArrayType]map[int]DesignEstimate{}
+	for i, est := range estimates {
+		if want := arrays[i/len(repetitions)]; est.Array != want {
+			t.Errorf("estimate %d is for %s, want %s in input order", i, est.Array, want)
+		}
+		if byDesign[est.Array] == nil {
+			byDesign[est.Array] = map[int]DesignEstimate{}
+		}
+		byDesign[est.Array][est.Repetitions] = est
+	}
+
+	for _, tt := range tests {
+		est := byDesign[tt.array][tt.repetitions]
+		if tt.fail {
+			if est.Err == nil {
+				t.Errorf("%s × %d: expected an error for two-level factors", tt.array, tt.repetitions)
+			}
+			continue
+		}
+		if est.Err != nil {
+			t.Errorf("%s × %d: %v", tt.array, tt.repetitions, est.Err)
+			continue
+		}
+		if est.Trials != tt.trials || est.Runs != tt.trials*tt.repetitions {
+			t.Errorf("%s × %d: %d trials, %d runs, want %d and %d", tt.array, tt.repetitions, est.Trials, est.Runs, tt.trials, tt.trials*tt.repetitions)
+		}
+		if est.Duration != tt.duration || est.Cost != tt.duration.Seconds() {
+			t.Errorf("%s × %d: duration %v, cost %g, want %v and %g", tt.array, tt.repetitions, est.Duration, est.Cost, tt.duration, tt.duration.Seconds())
+		}
+	}
+}
+
+// TestEstimateDesigns_WeightedNoise verifies that weighted noise factors,
+// which only some goals support, do not fail estimates made without a goal.
+func TestEstimateDesigns_WeightedNoise(t *testing.T) {
+	factors := namedFactors([]float64{1, 2}, "A", "B")
+	noise := []NoiseFactor{{Name: "Load", Levels: []float64{0, 1}, Weights: []float64{3, 1}}}
+	costFn := func(Trial) (time.Duration, float64) { return time.Second, 1 }
+	for _, est := range EstimateDesigns(factors, noise, []ArrayType{L4}, []int{1}, costFn) {
+		if est.Err != nil {
+			t.Fatalf("%s × %d: %v", est.Array, est.Repetitions, est.Err)
+		}
+		if est.Trials != 8 {
+			t.Errorf("%s: %d trials, want 8", est.Array, est.Trials)
+		}
+	}
+}
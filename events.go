@@ -0,0 +1,209 @@
+package taguchi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType identifies a milestone of an experiment (see EventBus).
+type EventType string
+
+const (
+	// EventTrialCompleted is published whenever a trial result is recorded.
+	EventTrialCompleted EventType = "trial_completed"
+	// EventExperimentCompleted is published when Run, RunService or
+	// ParallelRunner.Run returns, whether the run finished or stopped early.
+	EventExperimentCompleted EventType = "experiment_completed"
+	// EventAnalysisReady is published when Analyze or AnalyzeWith succeeds.
+	EventAnalysisReady EventType = "analysis_ready"
+)
+
+// Event is a milestone published on an experiment's EventBus.
+// Type: The milestone.
+// Time: When it occurred.
+// Result: The recorded result, for EventTrialCompleted.
+// Completed, Remaining: Trials of the design with and without a result, for EventExperimentCompleted.
+// Err: Why the run stopped, for EventExperimentCompleted; empty when it finished.
+// Analysis: The analysis, for EventAnalysisReady.
+type Event struct {
+	Type      EventType
+	Time      time.Time
+	Result    *TrialResult
+	Completed int
+	Remaining int
+	Err       string
+	Analysis  *AnalysisResult
+}
+
+// String summarizes the event in one line, e.g. for a chat message.
+func (ev Event) String() string {
+	switch ev.Type {
+	case EventTrialCompleted:
+		if ev.Result == nil {
+			break
+		}
+		r := ev.Result
+		msg := fmt.Sprintf("trial %d (row %d) completed with %d observations", r.Trial.ID, r.Trial.Row+1, r.Summary.Count)
+		if r.Summary.Count > 0 {
+			msg += fmt.Sprintf(", mean %g", r.Summary.Sum/float64(r.Summary.Count))
+		}
+		return msg
+	case EventExperimentCompleted:
+		total := ev.Completed + ev.Remaining
+		if ev.Err != "" {
+			return fmt.Sprintf("run stopped with %d of %d trials completed: %s", ev.Completed, total, ev.Err)
+		}
+		return fmt.Sprintf("run finished with %d of %d trials completed", ev.Completed, total)
+	case EventAnalysisReady:
+		if ev.Analysis == nil {
+			break
+		}
+		levels := make([]string, 0, len(ev.Analysis.OptimalLevels))
+		for _, name := range sortedKeys(ev.Analysis.OptimalLevels) {
+			levels = append(levels, fmt.Sprintf("%s=%g", name, ev.Analysis.OptimalLevels[name]))
+		}
+		return "analysis ready, optimal levels " + strings.Join(levels, ", ")
+	}
+	return string(ev.Type)
+}
+
+// EventBus delivers the milestones of an experiment to its subscribers, e.g.
+// to post chat notifications during runs lasting hours. Set it in
+// Experiment.Events; a nil EventBus publishes nothing. Handlers are called
+// synchronously, in subscription order, by the goroutine recording the result
+// or returning from the run or analysis, so slow handlers delay the
+// experiment. The zero value is ready to use.
+type EventBus struct {
+	mu   sync.Mutex
+	subs []subscription
+}
+
+// subscription is a handler with the event types it receives, all when empty.
+type subscription struct {
+	types  []EventType
+	handle func(Event)
+}
+
+// Subscribe registers handle for events of the given types, or for every
+// event when no types are given.
+func (b *EventBus) Subscribe(handle func(Event), types ...EventType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, subscription{types: types, handle: handle})
+}
+
+// publish stamps the event with the current time and passes it to the
+// subscribed handlers.
+func (b *EventBus) publish(ev Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	subs := append([]subscription(nil), b.subs...)
+	b.mu.Unlock()
+	ev.Time = time.Now()
+	for _, s := range subs {
+		if len(s.types) == 0 || slices.Contains(s.types, ev.Type) {
+			s.handle(ev)
+		}
+	}
+}
+
+// publishCompleted publishes EventExperimentCompleted for a run that
+// returned err.
+func (e *Experiment[P]) publishCompleted(err error) {
+	if e.Events == nil {
+		return
+	}
+	completed, remaining := e.trialCounts()
+	ev := Event{Type: EventExperimentCompleted, Completed: completed, Remaining: remaining}
+	if err != nil {
+		ev.Err = err.Error()
+	}
+	e.Events.publish(ev)
+}
+
+// publishAnalysis publishes EventAnalysisReady with the analysis.
+func (e *Experiment[P]) publishAnalysis(result AnalysisResult) {
+	if e.Events != nil {
+		e.Events.publish(Event{Type: EventAnalysisReady, Analysis: &result})
+	}
+}
+
+// DefaultWebhookTimeout bounds a webhook delivery when Webhook.Timeout is zero.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// Webhook is an event handler that POSTs every event as JSON, with
+// non-finite numbers written as null. Subscribe its Handle method to an
+// EventBus. A failed delivery never stops the experiment; it is reported to
+// Errors instead.
+// URL: Endpoint the events are posted to.
+// Header: Extra request headers, e.g. Authorization.
+// Client: HTTP client for the requests (http.DefaultClient when nil).
+// Timeout: Limit on each delivery (DefaultWebhookTimeout when zero).
+// Format: Body to send for an event, e.g. {"text": ev.String()} for a Slack incoming webhook; the Event itself when nil.
+// Errors: Called with every failed delivery; nil ignores failures.
+type Webhook struct {
+	URL     string
+	Header  http.Header
+	Client  *http.Client
+	Timeout time.Duration
+	Format  func(Event) any
+	Errors  func(error)
+}
+
+// Handle posts the event, reporting a failure to w.Errors.
+func (w *Webhook) Handle(ev Event) {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := w.Post(ctx, ev); err != nil && w.Errors != nil {
+		w.Errors(err)
+	}
+}
+
+// Post sends the event to w.URL and fails unless the response status is 2xx.
+func (w *Webhook) Post(ctx context.Context, ev Event) error {
+	var body any = ev
+	if w.Format != nil {
+		body = w.Format(ev)
+	}
+	data, err := json.Marshal(finiteJSON(reflect.ValueOf(body)))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", ev.Type, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", ev.Type, err)
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", ev.Type, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s: %s", ev.Type, resp.Status)
+	}
+	return nil
+}
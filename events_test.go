@@ -0,0 +1,139 @@
+package taguchi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEventBus_Run(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	exp.Results = nil
+	exp.Events = &EventBus{}
+	var all, completed []Event
+	exp.Events.Subscribe(func(ev Event) { all = append(all, ev) })
+	exp.Events.Subscribe(func(ev Event) { completed = append(completed, ev) }, EventExperimentCompleted)
+
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		return trial.Control["A"] + trial.Control["B"], nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	trials := len(exp.GenerateTrials())
+	if len(all) != trials+1 || len(completed) != 1 {
+		t.Fatalf("got %d events, %d completions; want %d and 1", len(all), len(completed), trials+1)
+	}
+	for i, ev := range all[:trials] {
+		if ev.Type != EventTrialCompleted || ev.Result == nil || ev.Result.Sequence != i+1 || ev.Time.IsZero() {
+			t.Errorf("event %d = %+v, want the completion of result %d", i, ev, i+1)
+		}
+	}
+	done := completed[0]
+	if done.Completed != trials || done.Remaining != 0 || done.Err != "" {
+		t.Errorf("completion = %+v, want %d trials completed", done, trials)
+	}
+	if got := done.String(); got != "run finished with 4 of 4 trials completed" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// Analyses published by Analyze, not by the methods building on it.
+	all = nil
+	if _, err := exp.Analyze(); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if _, err := exp.PredictOptimal(); err != nil {
+		t.Fatalf("PredictOptimal: %v", err)
+	}
+	if len(all) != 1 || all[0].Type != EventAnalysisReady || all[0].Analysis == nil {
+		t.Fatalf("events after Analyze = %+v, want one analysis", all)
+	}
+	if got := all[0].String(); !strings.HasPrefix(got, "analysis ready, optimal levels A=") {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestEventBus_RunFailure(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	exp.Results = nil
+	exp.Events = &EventBus{}
+	var done Event
+	exp.Events.Subscribe(func(ev Event) { done = ev }, EventExperimentCompleted)
+	failure := errors.New("rig offline")
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		if trial.Row == 1 {
+			return 0, failure
+		}
+		return 1, nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{}); !errors.Is(err, failure) {
+		t.Fatalf("Run = %v, want the measurement error", err)
+	}
+	if done.Type != EventExperimentCompleted || !strings.Contains(done.Err, "rig offline") || done.Completed != 1 || done.Remaining != 3 {
+		t.Errorf("completion = %+v, want the failure after 1 of 4 trials", done)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []map[string]any
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		mu.Lock()
+		bodies = append(bodies, body)
+		auth = append(auth, r.Header.Get("Authorization"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	exp := replicatedExperiment(t, "A", "B")
+	exp.Events = &EventBus{}
+	hook := &Webhook{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer secret"}}}
+	exp.Events.Subscribe(hook.Handle, EventAnalysisReady)
+	slack := &Webhook{URL: srv.URL, Format: func(ev Event) any { return map[string]string{"text": ev.String()} }}
+	exp.Events.Subscribe(slack.Handle, EventAnalysisReady)
+	if _, err := exp.Analyze(); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("got %d posts, want 2", len(bodies))
+	}
+	if bodies[0]["Type"] != string(EventAnalysisReady) || bodies[0]["Analysis"] == nil {
+		t.Errorf("event body = %v", bodies[0])
+	}
+	if text, _ := bodies[1]["text"].(string); !strings.HasPrefix(text, "analysis ready") {
+		t.Errorf("formatted body = %v", bodies[1])
+	}
+	if auth[0] != "Bearer secret" || auth[1] != "" {
+		t.Errorf("Authorization headers = %q, want the one of the first webhook", auth)
+	}
+}
+
+func TestWebhook_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer srv.Close()
+
+	var errs []error
+	hook := &Webhook{URL: srv.URL, Errors: func(err error) { errs = append(errs, err) }}
+	exp := replicatedExperiment(t, "A", "B")
+	exp.Events = &EventBus{}
+	exp.Events.Subscribe(hook.Handle)
+	if err := exp.AppendResult(exp.GenerateTrials()[0], []float64{1}); err != nil {
+		t.Fatalf("AppendResult = %v, want delivery failures to be ignored", err)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "410 Gone") {
+		t.Errorf("errors = %v, want the rejected delivery", errs)
+	}
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"runtime"
 	"time"
 
 	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/noise"
 )
 
 const dataSize = 2_000_000
@@ -26,7 +28,10 @@ func main() {
 	datasets := prepareDatasets(dataSize)
 	runExperiment(exp, datasets)
 
-	results := exp.Analyze()
+	results, err := exp.Analyze()
+	if err != nil {
+		log.Fatal(err)
+	}
 	taguchi.PrintAnalysisReport(results)
 }
 
@@ -39,6 +44,7 @@ func createExperiment() (*taguchi.Experiment[ExperimentFactors], error) {
 
 	noise := []taguchi.NoiseFactor{
 		{Name: "DataPattern", Levels: []float64{0, 1, 2, 3, 4}},
+		{Name: "BackgroundCPU", Levels: []float64{0, 0.5}},
 	}
 
 	return taguchi.NewExperiment[ExperimentFactors, ExperimentFactors](
@@ -61,37 +67,38 @@ func prepareDatasets(size int) map[DataPattern][]int {
 }
 
 func runExperiment(exp *taguchi.Experiment[ExperimentFactors], datasets map[DataPattern][]int) {
-	for _, trial := range exp.GenerateTrials() {
-		tc := trialConfig{trial: trial, datasets: datasets}
-		runTrial(exp, tc)
+	measure := func(_ context.Context, trial taguchi.Trial) (float64, error) {
+		return runTrial(trial, datasets)
+	}
+	inj := noise.NewInjection(map[string]noise.Injector{
+		"BackgroundCPU": noise.CPULoad{Workers: 2},
+	})
+	opts := inj.Options(taguchi.RunOptions{Repetitions: 1})
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		log.Fatal(err)
 	}
 }
 
-type trialConfig struct {
-	trial    taguchi.Trial
-	datasets map[DataPattern][]int
-}
-
-func runTrial(exp *taguchi.Experiment[ExperimentFactors], tc trialConfig) {
-	runtime.GOMAXPROCS(int(tc.trial.Control["GOMAXPROCS"]))
+func runTrial(trial taguchi.Trial, datasets map[DataPattern][]int) (float64, error) {
+	runtime.GOMAXPROCS(int(trial.Control["GOMAXPROCS"]))
 
-	workers := int(tc.trial.Control["MaxWorkers"])
-	alg := SortAlgorithm(tc.trial.Control["Algorithm"])
-	pattern := DataPattern(tc.trial.Noise["DataPattern"])
+	workers := int(trial.Control["MaxWorkers"])
+	alg := SortAlgorithm(trial.Control["Algorithm"])
+	pattern := DataPattern(trial.Noise["DataPattern"])
 
 	data := make([]int, dataSize)
-	copy(data, tc.datasets[pattern])
+	copy(data, datasets[pattern])
 
-	printTrialStart(tc.trial, alg, workers, pattern)
+	printTrialStart(trial, alg, workers, pattern)
 
 	dur := executeSortAlgorithm(alg, data, workers)
 
 	if !isSorted(data) {
-		panic("sorting failed")
+		return 0, fmt.Errorf("trial %d: sorting failed", trial.ID)
 	}
 
-	exp.AddResult(tc.trial, []float64{float64(dur.Microseconds())})
-	printTrialResult(tc.trial, alg, workers, pattern, dur)
+	printTrialResult(trial, alg, workers, pattern, dur)
+	return float64(dur.Microseconds()), nil
 }
 
 func executeSortAlgorithm(alg SortAlgorithm, data []int, workers int) time.Duration {
@@ -108,8 +115,8 @@ func executeSortAlgorithm(alg SortAlgorithm, data []int, workers int) time.Durat
 }
 
 func printTrialStart(trial taguchi.Trial, alg SortAlgorithm, workers int, pattern DataPattern) {
-	fmt.Printf("Trial %d: %s | Workers=%d | GOMAXPROCS=%d | Pattern=%s\n",
-		trial.ID, alg, workers, int(trial.Control["GOMAXPROCS"]), pattern)
+	fmt.Printf("Trial %d: %s | Workers=%d | GOMAXPROCS=%d | Pattern=%s | CPU load=%.0f%%\n",
+		trial.ID, alg, workers, int(trial.Control["GOMAXPROCS"]), pattern, 100*trial.Noise["BackgroundCPU"])
 }
 
 func printTrialResult(trial taguchi.Trial, alg SortAlgorithm, workers int, pattern DataPattern, dur time.Duration) {
@@ -0,0 +1,181 @@
+package taguchi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Exclusion removes questionable data from an analysis without touching the
+// recorded results (see AnalyzeWith).
+// Trial: ID of the trial whose data is excluded.
+// Observation: One-based index of a single observation of the trial, counted across its results in
+// recording order; zero excludes the whole trial.
+// Reason: Why the data is excluded, carried into AnalysisResult.Exclusions for documentation.
+type Exclusion struct {
+	Trial       int
+	Observation int
+	Reason      string
+}
+
+// String describes the exclusion, e.g. "trial 12, observation 3 (sensor glitch)".
+func (x Exclusion) String() string {
+	s := fmt.Sprintf("trial %d", x.Trial)
+	if x.Observation > 0 {
+		s += fmt.Sprintf(", observation %d", x.Observation)
+	}
+	if x.Reason != "" {
+		s += " (" + x.Reason + ")"
+	}
+	return s
+}
+
+// AnalysisOptions configures AnalyzeWith.
+// Exclude: Trials and single observations left out of the analysis.
+// Where: Metadata a result must have to be analyzed, matched against TrialResult.MetadataValue, e.g.
+// {"hostname": "ci-1"}; nil analyzes every result.
+type AnalysisOptions struct {
+	Exclude []Exclusion
+	Where   map[string]string
+}
+
+// AnalyzeWith analyzes the experiment as Analyze does, after applying the
+// exclusions of opts to a copy of the results. The experiment's Results are
+// left unchanged, so the sensitivity of the conclusions to questionable data
+// points can be explored by comparing analyses with and without them. The
+// exclusions are reported in AnalysisResult.Exclusions. The results left
+// are then narrowed down to those whose metadata matches opts.Where, e.g. to
+// analyze the runs of one machine or commit, reported in AnalysisResult.Where.
+//
+// An exclusion that matches no recorded data is an error, as is excluding
+// single observations of a result whose raw observations were not retained.
+// Excluding every result of an array row leaves the row unmeasured, which
+// requires AllowIncomplete.
+func (e *Experiment[P]) AnalyzeWith(opts AnalysisOptions) (AnalysisResult, error) {
+	results, err := e.excludeResults(opts.Exclude)
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	if results, err = filterResults(results, opts.Where); err != nil {
+		return AnalysisResult{}, err
+	}
+	view := *e
+	view.Results = results
+	result, err := view.analyze()
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	result.Exclusions = append([]Exclusion(nil), opts.Exclude...)
+	if len(opts.Where) > 0 {
+		result.Where = make(map[string]string, len(opts.Where))
+		for key, v := range opts.Where {
+			result.Where[key] = v
+		}
+	}
+	e.publishAnalysis(result)
+	return result, nil
+}
+
+// excludeResults returns a copy of the results without the excluded trials
+// and observations. Results losing observations get fresh Observations,
+// Secondary and Summary values; all other results are shared with e.Results.
+func (e *Experiment[P]) excludeResults(exclude []Exclusion) ([]TrialResult, error) {
+	if len(exclude) == 0 {
+		return e.Results, nil
+	}
+	dropTrial := map[int]bool{}
+	dropObs := map[int]map[int]bool{}
+	for _, x := range exclude {
+		switch {
+		case x.Observation < 0:
+			return nil, fmt.Errorf("exclusion %s: observation index must be positive", x)
+		case x.Observation == 0:
+			dropTrial[x.Trial] = true
+		default:
+			if dropObs[x.Trial] == nil {
+				dropObs[x.Trial] = map[int]bool{}
+			}
+			dropObs[x.Trial][x.Observation] = true
+		}
+	}
+
+	found := map[int]bool{}
+	seen := map[int]int{}
+	results := make([]TrialResult, 0, len(e.Results))
+	for _, r := range e.Results {
+		id := r.Trial.ID
+		if dropTrial[id] {
+			found[id] = true
+			continue
+		}
+		drop := dropObs[id]
+		if len(drop) == 0 {
+			results = append(results, r)
+			continue
+		}
+		obs, err := r.RawObservations()
+		if err != nil {
+			return nil, err
+		}
+		if len(obs) < r.Summary.Count {
+			return nil, fmt.Errorf("trial %d: raw observations were not all retained, cannot exclude single observations", id)
+		}
+		first := seen[id]
+		seen[id] += len(obs)
+		var keep []int
+		for i := range obs {
+			if !drop[first+i+1] {
+				keep = append(keep, i)
+			}
+		}
+		if len(keep) == len(obs) {
+			results = append(results, r)
+			continue
+		}
+		if len(keep) == 0 {
+			continue
+		}
+		results = append(results, withObservations(r, obs, keep))
+	}
+
+	var unmatched []string
+	for _, x := range exclude {
+		switch {
+		case x.Observation == 0 && !found[x.Trial]:
+			unmatched = append(unmatched, x.String())
+		case x.Observation > 0 && !dropTrial[x.Trial] && x.Observation > seen[x.Trial]:
+			unmatched = append(unmatched, x.String())
+		}
+	}
+	if len(unmatched) > 0 {
+		return nil, fmt.Errorf("exclusions match no recorded data: %s", strings.Join(unmatched, "; "))
+	}
+	return results, nil
+}
+
+// withObservations returns a copy of r holding only the observations of obs at
+// the indices keep, with the matching repetitions of its secondary responses.
+func withObservations(r TrialResult, obs []float64, keep []int) TrialResult {
+	kept := make([]float64, len(keep))
+	for k, i := range keep {
+		kept[k] = obs[i]
+	}
+	r.Observations = kept
+	r.SpillFile = ""
+	r.Summary = summarize(kept)
+	if r.Secondary != nil {
+		secondary := make(map[string][]float64, len(r.Secondary))
+		for name, values := range r.Secondary {
+			if len(values) != len(obs) {
+				secondary[name] = values
+				continue
+			}
+			filtered := make([]float64, len(keep))
+			for k, i := range keep {
+				filtered[k] = values[i]
+			}
+			secondary[name] = filtered
+		}
+		r.Secondary = secondary
+	}
+	return r
+}
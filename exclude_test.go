@@ -0,0 +1,81 @@
+package taguchi
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestAnalyzeWith_Exclusions verifies that exclusions change the analysis as
+// if the data had never been recorded, without mutating the results.
+func TestAnalyzeWith_Exclusions(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+
+	newExp := func() *Experiment[struct{}] {
+		exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, noise)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+		}
+		return exp
+	}
+	exp, clean := newExp(), newExp()
+	trials := exp.GenerateTrials()
+	for _, trial := range trials {
+		y := trial.Control["A"] + trial.Control["B"] + trial.Noise["N"]
+		obs := []float64{y, y + 1, y + 2}
+		cleanObs := obs
+		switch trial.ID {
+		case trials[2].ID:
+			obs = []float64{y, y + 1, 100} // glitch in observation 3
+			cleanObs = []float64{y, y + 1}
+		case trials[5].ID:
+			obs = []float64{100, 100, 100} // broken trial
+		}
+		if err := exp.AddResult(trial, obs); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+		if trial.ID == trials[5].ID {
+			continue
+		}
+		if err := clean.AddResult(trial, cleanObs); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	before := slices.Clone(exp.Results[2].Observations)
+
+	opts := AnalysisOptions{Exclude: []Exclusion{
+		{Trial: trials[2].ID, Observation: 3, Reason: "sensor glitch"},
+		{Trial: trials[5].ID},
+	}}
+	got, err := exp.AnalyzeWith(opts)
+	if err != nil {
+		t.Fatalf("AnalyzeWith: %v", err)
+	}
+	want := mustAnalyze(t, clean)
+	for _, f := range []string{"A", "B"} {
+		for l := range want.MainEffects[f] {
+			if !almostEqual(got.MainEffects[f][l], want.MainEffects[f][l]) {
+				t.Errorf("MainEffects[%s][%d] = %.4f, want %.4f", f, l, got.MainEffects[f][l], want.MainEffects[f][l])
+			}
+		}
+	}
+	if len(got.Exclusions) != 2 {
+		t.Errorf("Exclusions = %v, want the 2 applied exclusions", got.Exclusions)
+	}
+	if len(exp.Results) != len(trials) || !slices.Equal(exp.Results[2].Observations, before) {
+		t.Error("AnalyzeWith modified the recorded results")
+	}
+	if full := mustAnalyze(t, exp); almostEqual(full.MainEffects["A"][0], got.MainEffects["A"][0]) {
+		t.Error("Analyze after AnalyzeWith did not use the full data")
+	}
+
+	_, err = exp.AnalyzeWith(AnalysisOptions{Exclude: []Exclusion{{Trial: trials[0].ID, Observation: 4}}})
+	if err == nil || !strings.Contains(err.Error(), "observation 4") {
+		t.Errorf("out-of-range observation: err = %v, want an unmatched exclusion error", err)
+	}
+}
@@ -0,0 +1,262 @@
+// Package execrunner measures trials by running an external command, so
+// programs written in any language can be tuned. Factor levels reach the
+// command through argument and environment templates, and the observation
+// is parsed from its standard output:
+//
+//	cmd := &execrunner.Command{
+//		Path:    "./server-bench",
+//		Args:    []string{"-threads={{.Threads}}", "-cache={{.Cache}}"},
+//		Env:     map[string]string{"LOAD": "{{.Load}}"},
+//		Pattern: regexp.MustCompile(`p99=([0-9.]+)ms`),
+//	}
+//	measure, err := cmd.Measure()
+//	...
+//	err = exp.Run(ctx, measure, taguchi.RunOptions{Repetitions: 3})
+package execrunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// stderrTail is the number of bytes of standard error quoted when a command fails.
+const stderrTail = 512
+
+// Command describes the external program run for every measured run of a
+// trial. Args and Env values are text/template templates executed with the
+// trial's control and noise levels keyed by factor name, formatted without
+// exponents (e.g. {{.Threads}} becomes 4, 0.25 or 1000000), and with
+// {{.TrialID}} and {{.Row}} (one-based). Names that are not identifiers are
+// reached with index, as in {{index . "Cache size"}}.
+// Path: Program to run, looked up in PATH when it contains no separator.
+// Args: Argument templates.
+// Env: Environment variable templates, added to the environment of the current process.
+// Dir: Working directory (the current directory when empty).
+// Pattern: Regular expression locating the observation in standard output: its first submatch, or the whole match without one.
+// JSONPath: Path of the observation in a JSON document on standard output, e.g. "$.latency.p99" or "runs[0].ms".
+// Stderr: Receives the standard error of every run; it is only kept for error messages when nil.
+// Without Pattern and JSONPath, the whole standard output must be a number.
+type Command struct {
+	Path     string
+	Args     []string
+	Env      map[string]string
+	Dir      string
+	Pattern  *regexp.Regexp
+	JSONPath string
+	Stderr   io.Writer
+}
+
+// Measure checks the command and compiles its templates, and returns a
+// MeasureFunc that runs it once per call. A run fails when the command exits
+// with a non-zero status, when ctx is cancelled, or when no observation can
+// be parsed from its output.
+func (c *Command) Measure() (taguchi.MeasureFunc, error) {
+	if c.Path == "" {
+		return nil, errors.New("execrunner: no command path")
+	}
+	if c.Pattern != nil && c.JSONPath != "" {
+		return nil, errors.New("execrunner: set either Pattern or JSONPath, not both")
+	}
+	var path []string
+	if c.JSONPath != "" {
+		var err error
+		if path, err = parseJSONPath(c.JSONPath); err != nil {
+			return nil, err
+		}
+	}
+	args := make([]*template.Template, len(c.Args))
+	for i, a := range c.Args {
+		t, err := parseTemplate(fmt.Sprintf("argument %d", i+1), a)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = t
+	}
+	env := map[string]*template.Template{}
+	for name, v := range c.Env {
+		t, err := parseTemplate("environment variable "+name, v)
+		if err != nil {
+			return nil, err
+		}
+		env[name] = t
+	}
+
+	return func(ctx context.Context, trial taguchi.Trial) (float64, error) {
+		data := templateData(trial)
+		cmd := exec.CommandContext(ctx, c.Path)
+		cmd.Dir = c.Dir
+		for _, t := range args {
+			a, err := execute(t, data)
+			if err != nil {
+				return 0, err
+			}
+			cmd.Args = append(cmd.Args, a)
+		}
+		if len(env) > 0 {
+			cmd.Env = os.Environ()
+			for name, t := range env {
+				v, err := execute(t, data)
+				if err != nil {
+					return 0, err
+				}
+				cmd.Env = append(cmd.Env, name+"="+v)
+			}
+		}
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if c.Stderr != nil {
+			cmd.Stderr = io.MultiWriter(&stderr, c.Stderr)
+		}
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			return 0, fmt.Errorf("%s: %w%s", c.Path, err, quoteTail(stderr.Bytes()))
+		}
+		var v float64
+		var err error
+		switch {
+		case c.Pattern != nil:
+			v, err = parsePattern(c.Pattern, stdout.Bytes())
+		case path != nil:
+			v, err = parseJSON(path, stdout.Bytes())
+		default:
+			v, err = parseNumber(strings.TrimSpace(stdout.String()))
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", c.Path, err)
+		}
+		return v, nil
+	}, nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("execrunner: %w", err)
+	}
+	return t, nil
+}
+
+func execute(t *template.Template, data map[string]string) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("execrunner: %w", err)
+	}
+	return b.String(), nil
+}
+
+// templateData returns the values available to the templates of a trial.
+func templateData(trial taguchi.Trial) map[string]string {
+	data := map[string]string{
+		"TrialID": strconv.Itoa(trial.ID),
+		"Row":     strconv.Itoa(trial.Row + 1),
+	}
+	for name, v := range trial.Noise {
+		data[name] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for name, v := range trial.Control {
+		data[name] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return data
+}
+
+// quoteTail returns the end of a command's standard error for an error
+// message, or "" when it is empty.
+func quoteTail(stderr []byte) string {
+	s := strings.TrimSpace(string(stderr))
+	if s == "" {
+		return ""
+	}
+	if len(s) > stderrTail {
+		s = "..." + s[len(s)-stderrTail:]
+	}
+	return ": " + s
+}
+
+func parsePattern(re *regexp.Regexp, out []byte) (float64, error) {
+	m := re.FindSubmatch(out)
+	if m == nil {
+		return 0, fmt.Errorf("output does not match %s", re)
+	}
+	if len(m) > 1 {
+		return parseNumber(string(m[1]))
+	}
+	return parseNumber(string(m[0]))
+}
+
+func parseNumber(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("observation %q is not a number", s)
+	}
+	return v, nil
+}
+
+// parseJSONPath splits a path such as "$.runs[0].ms" or "runs.0.ms" into
+// object keys and array indices.
+func parseJSONPath(path string) ([]string, error) {
+	p := strings.TrimPrefix(path, "$")
+	p = strings.ReplaceAll(p, "[", ".")
+	p = strings.ReplaceAll(p, "]", "")
+	p = strings.TrimPrefix(p, ".")
+	if p == "" {
+		return []string{}, nil
+	}
+	parts := strings.Split(p, ".")
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("execrunner: invalid JSON path %q", path)
+		}
+	}
+	return parts, nil
+}
+
+// parseJSON returns the number at path in the JSON document out. Numeric
+// strings are accepted, as some tools quote their numbers.
+func parseJSON(path []string, out []byte) (float64, error) {
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.UseNumber()
+	var node any
+	if err := dec.Decode(&node); err != nil {
+		return 0, fmt.Errorf("output is not JSON: %w", err)
+	}
+	for i, key := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[key]
+			if !ok {
+				return 0, fmt.Errorf("JSON output has no %s", strings.Join(path[:i+1], "."))
+			}
+			node = v
+		case []any:
+			k, err := strconv.Atoi(key)
+			if err != nil || k < 0 || k >= len(n) {
+				return 0, fmt.Errorf("JSON output has no %s", strings.Join(path[:i+1], "."))
+			}
+			node = n[k]
+		default:
+			return 0, fmt.Errorf("JSON output has no %s", strings.Join(path[:i+1], "."))
+		}
+	}
+	switch v := node.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return parseNumber(v)
+	}
+	return 0, fmt.Errorf("JSON value at %s is not a number", strings.Join(path, "."))
+}
@@ -0,0 +1,124 @@
+package execrunner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/internal/testexp"
+)
+
+// shell returns a Command running script with sh, skipping the test where no
+// shell is available.
+func shell(t *testing.T, script string) *Command {
+	t.Helper()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh in PATH")
+	}
+	return &Command{Path: sh, Args: []string{"-c", script}}
+}
+
+// design has a factor B whose levels need an exponent-free formatting.
+var design = testexp.Design{Factors: []taguchi.ControlFactor{
+	{Name: "A", Levels: []float64{1, 2}},
+	{Name: "B", Levels: []float64{0.5, 1000000}},
+}}
+
+// TestCommand_Run verifies that levels reach the command through arguments
+// and the environment and that each output format yields the observation.
+func TestCommand_Run(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		script string
+		setup  func(*Command)
+	}{
+		{"number", `echo "$1 $2 $N" | awk '{print 10 * $1 + $3 + ($2 == "1000000" ? 100 : 0)}'`, func(*Command) {}},
+		{"pattern", `echo "warming up"; echo "result=$(echo "$1 $2 $N" | awk '{print 10 * $1 + $3 + ($2 == "1000000" ? 100 : 0)}')ms"`, func(c *Command) {
+			c.Pattern = regexp.MustCompile(`result=([0-9.e+-]+)ms`)
+		}},
+		{"json", `echo "{\"runs\": [{\"ms\": $(echo "$1 $2 $N" | awk '{print 10 * $1 + $3 + ($2 == "1000000" ? 100 : 0)}')}]}"`, func(c *Command) {
+			c.JSONPath = "$.runs[0].ms"
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := shell(t, c.script)
+			cmd.Args = append(cmd.Args, "sh", "{{.A}}", "{{.B}}")
+			cmd.Env = map[string]string{"N": "{{.N}}"}
+			c.setup(cmd)
+			measure, err := cmd.Measure()
+			if err != nil {
+				t.Fatalf("Measure: %v", err)
+			}
+			exp := testexp.New(t, design)
+			if err := exp.Run(context.Background(), measure, taguchi.RunOptions{}); err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if len(exp.Results) != 8 {
+				t.Fatalf("recorded %d results, want 8", len(exp.Results))
+			}
+			for _, r := range exp.Results {
+				// B is passed as 1000000, not in exponent notation.
+				want := 10*r.Trial.Control["A"] + r.Trial.Noise["N"]
+				if r.Trial.Control["B"] == 1000000 {
+					want += 100
+				}
+				if got := r.Observations[0]; got != want {
+					t.Errorf("trial %d: observation %g, want %g", r.Trial.ID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommand_Errors(t *testing.T) {
+	trial := testexp.New(t, design).GenerateTrials()[0]
+	for _, c := range []struct {
+		name, script, want string
+		setup              func(*Command)
+	}{
+		{"exit status", "echo broken >&2; exit 3", "broken", func(*Command) {}},
+		{"not a number", "echo fast", `"fast" is not a number`, func(*Command) {}},
+		{"no match", "echo done", "does not match", func(c *Command) { c.Pattern = regexp.MustCompile(`t=(\d+)`) }},
+		{"missing key", `echo '{"a": 1}'`, "no b", func(c *Command) { c.JSONPath = "b" }},
+		{"unknown factor", "echo 1 {{.Missing}}", "Missing", func(c *Command) { c.Args[1] = "echo {{.Missing}}" }},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := shell(t, c.script)
+			c.setup(cmd)
+			measure, err := cmd.Measure()
+			if err != nil {
+				t.Fatalf("Measure: %v", err)
+			}
+			if _, err := measure(context.Background(), trial); err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("err = %v, want it to mention %q", err, c.want)
+			}
+		})
+	}
+
+	cmd := shell(t, "sleep 5")
+	measure, err := cmd.Measure()
+	if err != nil {
+		t.Fatalf("Measure: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := measure(ctx, trial); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled run: err = %v, want context.Canceled", err)
+	}
+
+	for _, bad := range []*Command{
+		{},
+		{Path: "sh", Pattern: regexp.MustCompile("x"), JSONPath: "x"},
+		{Path: "sh", JSONPath: "a..b"},
+		{Path: "sh", Args: []string{"{{.A"}},
+	} {
+		if _, err := bad.Measure(); err == nil {
+			t.Errorf("Measure accepted %+v", bad)
+		}
+	}
+}
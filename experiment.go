@@ -2,10 +2,15 @@ package taguchi
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
 )
 
 // NewExperiment initializes a new generic Taguchi experiment. F is the factors struct type
-// (inferred from the factors argument), P is the params struct type for ControlAs.
+// (inferred from the factors argument), P is the params struct type for Params. Fields of P
+// may carry `taguchi:"FactorName"` tags; a field bound to an unknown factor is an error.
 // arrayName selects a standard orthogonal array (e.g., L4, L8) to generate the trial layout.
 func NewExperiment[F any, P any](goal OptimizationGoal, factors F, arrayName ArrayType, noiseFactors []NoiseFactor) (*Experiment[P], error) {
 	controlFactors, err := factorsFrom(factors)
@@ -14,38 +19,57 @@ func NewExperiment[F any, P any](goal OptimizationGoal, factors F, arrayName Arr
 	}
 	oa, ok := StandardArrays[arrayName]
 	if !ok {
-		return nil, fmt.Errorf("orthogonal array %s not defined", arrayName)
+		return nil, errorf(ErrUnknownArray, "orthogonal array %s not defined", arrayName)
 	}
-	if len(controlFactors) > len(oa[0]) {
-		return nil, fmt.Errorf("orthogonal array %s cannot accommodate %d factors", arrayName, len(controlFactors))
+	columns, err := AssignColumns(controlFactors, oa)
+	if err != nil {
+		return nil, fmt.Errorf("orthogonal array %s: %w", arrayName, err)
+	}
+	if err := checkNoiseWeights(goal, noiseFactors); err != nil {
+		return nil, err
+	}
+	controlAs, err := buildControlAs[P](controlFactors, noiseFactors)
+	if err != nil {
+		return nil, err
 	}
 	return &Experiment[P]{
 		ControlFactors:  controlFactors,
 		NoiseFactors:    noiseFactors,
 		Goal:            goal,
 		OrthogonalArray: oa,
-		controlAs:       buildControlAs[P](),
+		Columns:         columns,
+		controlAs:       controlAs,
 	}, nil
 }
 
 // NewExperimentUsingArray initializes a new generic Taguchi experiment with a user-provided orthogonal array.
-func NewExperimentUsingArray[F any, P any](goal OptimizationGoal, factors F, orthogonalArray [][]int, noiseFactors []NoiseFactor) (*Experiment[P], error) {
+// The array must pass ValidateArray unless opts allows non-orthogonal designs.
+func NewExperimentUsingArray[F any, P any](goal OptimizationGoal, factors F, orthogonalArray [][]int, noiseFactors []NoiseFactor, opts ...ArrayOptions) (*Experiment[P], error) {
 	controlFactors, err := factorsFrom(factors)
 	if err != nil {
 		return nil, err
 	}
-	if len(orthogonalArray) == 0 {
-		return nil, fmt.Errorf("orthogonal array must not be empty")
+	if err := checkArray(orthogonalArray, opts); err != nil {
+		return nil, fmt.Errorf("orthogonal array: %w", err)
 	}
-	if len(controlFactors) > len(orthogonalArray[0]) {
-		return nil, fmt.Errorf("orthogonal array cannot accommodate %d factors", len(controlFactors))
+	columns, err := AssignColumns(controlFactors, orthogonalArray)
+	if err != nil {
+		return nil, fmt.Errorf("orthogonal array: %w", err)
+	}
+	if err := checkNoiseWeights(goal, noiseFactors); err != nil {
+		return nil, err
+	}
+	controlAs, err := buildControlAs[P](controlFactors, noiseFactors)
+	if err != nil {
+		return nil, err
 	}
 	return &Experiment[P]{
 		ControlFactors:  controlFactors,
 		NoiseFactors:    noiseFactors,
 		Goal:            goal,
 		OrthogonalArray: orthogonalArray,
-		controlAs:       buildControlAs[P](),
+		Columns:         columns,
+		controlAs:       controlAs,
 	}, nil
 }
 
@@ -54,110 +78,416 @@ func NewExperimentUsingArray[F any, P any](goal OptimizationGoal, factors F, ort
 func NewExperimentFromFactors(goal OptimizationGoal, controlFactors []ControlFactor, arrayName ArrayType, noiseFactors []NoiseFactor) (*Experiment[struct{}], error) {
 	oa, ok := StandardArrays[arrayName]
 	if !ok {
-		return nil, fmt.Errorf("orthogonal array %s not defined", arrayName)
+		return nil, errorf(ErrUnknownArray, "orthogonal array %s not defined", arrayName)
 	}
-	if len(controlFactors) > len(oa[0]) {
-		return nil, fmt.Errorf("orthogonal array %s cannot accommodate %d factors", arrayName, len(controlFactors))
+	columns, err := AssignColumns(controlFactors, oa)
+	if err != nil {
+		return nil, fmt.Errorf("orthogonal array %s: %w", arrayName, err)
+	}
+	if err := checkNoiseWeights(goal, noiseFactors); err != nil {
+		return nil, err
 	}
 	return &Experiment[struct{}]{
 		ControlFactors:  controlFactors,
 		NoiseFactors:    noiseFactors,
 		Goal:            goal,
 		OrthogonalArray: oa,
+		Columns:         columns,
 	}, nil
 }
 
 // NewExperimentFromFactorsUsingArray initializes a Taguchi experiment from a pre-built []Factor slice
-// with a user-provided orthogonal array.
-func NewExperimentFromFactorsUsingArray(goal OptimizationGoal, controlFactors []ControlFactor, orthogonalArray [][]int, noiseFactors []NoiseFactor) (*Experiment[struct{}], error) {
-	if len(orthogonalArray) == 0 {
-		return nil, fmt.Errorf("orthogonal array must not be empty")
+// with a user-provided orthogonal array. The array must pass ValidateArray unless opts allows
+// non-orthogonal designs.
+func NewExperimentFromFactorsUsingArray(goal OptimizationGoal, controlFactors []ControlFactor, orthogonalArray [][]int, noiseFactors []NoiseFactor, opts ...ArrayOptions) (*Experiment[struct{}], error) {
+	if err := checkArray(orthogonalArray, opts); err != nil {
+		return nil, fmt.Errorf("orthogonal array: %w", err)
 	}
-	if len(controlFactors) > len(orthogonalArray[0]) {
-		return nil, fmt.Errorf("orthogonal array cannot accommodate %d factors", len(controlFactors))
+	columns, err := AssignColumns(controlFactors, orthogonalArray)
+	if err != nil {
+		return nil, fmt.Errorf("orthogonal array: %w", err)
+	}
+	if err := checkNoiseWeights(goal, noiseFactors); err != nil {
+		return nil, err
 	}
 	return &Experiment[struct{}]{
 		ControlFactors:  controlFactors,
 		NoiseFactors:    noiseFactors,
 		Goal:            goal,
 		OrthogonalArray: orthogonalArray,
+		Columns:         columns,
 	}, nil
 }
 
-// Params converts a Trial's Control map into a value of type P using the
-// pre-built converter function. P's exported float64 fields are populated from
-// the corresponding Control map entries (keyed by field name).
-func (e *Experiment[P]) Params(trial Trial) P {
+// Params converts a trial into a value of type P using the pre-built field
+// bindings. Each bound field of P receives the level of its control (or noise)
+// factor, converted to the field's type; fields are bound by their
+// `taguchi:"FactorName"` tag or by name (see NewExperiment). It returns an
+// error when the trial lacks a bound factor or a level does not convert.
+func (e *Experiment[P]) Params(trial Trial) (P, error) {
 	if e.controlAs == nil {
 		var zero P
-		return zero
+		return zero, nil
 	}
 	return e.controlAs(trial)
 }
 
 // AddResult records the observations from a completed trial into the experiment's results.
-func (e *Experiment[P]) AddResult(trial Trial, observations []float64) {
-	e.Results = append(e.Results, TrialResult{
-		Trial:        trial,
-		Observations: observations,
-	})
+// Raw observations are kept according to the experiment's Retention policy.
+// It returns an error, recording nothing, when the trial is not part of the
+// design (see GenerateTrials), already has a result, or the observations are
+// empty, not finite, or an odd count for a goal recording pairs
+// (OperatingWindow and Dynamic), and when an option of the experiment cannot
+// be used with the goal (see checkPairedGoal). Use AppendResult to add
+// replicates to a trial.
+func (e *Experiment[P]) AddResult(trial Trial, observations []float64) error {
+	return e.addResult(TrialResult{Trial: trial, Observations: observations}, false)
+}
+
+// AppendResult records further observations of a trial like AddResult, but
+// accepts trials that already have results; the observations are analyzed as
+// additional replicates of the trial.
+func (e *Experiment[P]) AppendResult(trial Trial, observations []float64) error {
+	return e.addResult(TrialResult{Trial: trial, Observations: observations}, true)
+}
+
+// addResult validates and records a trial result built from its trial,
+// observations and any secondary data. Duplicate results for the same array
+// row and noise condition are rejected unless replicate is set.
+func (e *Experiment[P]) addResult(r TrialResult, replicate bool) error {
+	r, err := e.checkResult(r, replicate)
+	if err != nil {
+		return err
+	}
+	return e.recordResult(r)
+}
+
+// checkResult runs the checks of addResult on a result without recording
+// it, returning the result with its trial resolved (see resolveTrial).
+func (e *Experiment[P]) checkResult(r TrialResult, replicate bool) (TrialResult, error) {
+	r.Trial = e.resolveTrial(r.Trial)
+	trial, observations := r.Trial, r.Observations
+	if err := e.validateTrial(trial); err != nil {
+		return r, &TrialError{Trial: trial.ID, Err: err}
+	}
+	if err := validateObservations(observations); err != nil {
+		return r, &TrialError{Trial: trial.ID, Err: err}
+	}
+	if pairedGoal(e.Goal) && len(observations)%2 != 0 {
+		return r, &TrialError{Trial: trial.ID, Err: errorf(ErrInvalidObservations, "%s observations are pairs, got %d values", e.Goal, len(observations))}
+	}
+	if err := e.checkPairedGoal(); err != nil {
+		return r, &TrialError{Trial: trial.ID, Err: err}
+	}
+	if !replicate {
+		for _, prev := range e.Results {
+			if prev.Trial.Row == trial.Row && maps.Equal(prev.Trial.Noise, trial.Noise) {
+				return r, &TrialError{Trial: trial.ID, Err: errorf(ErrDuplicateResult, "already has a result (trial %d); use AppendResult to add replicates", prev.Trial.ID)}
+			}
+		}
+	}
+	return r, nil
+}
+
+// recordResult appends a result without validation, summarizing its
+// observations and numbering it after the last recorded result. With an
+// attached store the result is written there first, with all its raw
+// observations, and nothing is recorded when that fails.
+func (e *Experiment[P]) recordResult(r TrialResult) error {
+	r.Trial = r.Trial.withKey()
+	r.Summary = summarize(r.Observations)
+	r.Sequence = 1
+	if n := len(e.Results); n > 0 {
+		r.Sequence = e.Results[n-1].Sequence + 1
+	}
+	// The store gets the raw observations, whatever the retention policy.
+	kept := r
+	if err := e.retain(&kept); err != nil {
+		return &TrialError{Trial: r.Trial.ID, Err: fmt.Errorf("spilling observations: %w", err)}
+	}
+	if e.store != nil {
+		if err := e.store.AddResult(r); err != nil {
+			if kept.SpillFile != "" {
+				os.Remove(kept.SpillFile)
+			}
+			return &TrialError{Trial: r.Trial.ID, Err: fmt.Errorf("storing result: %w", err)}
+		}
+	}
+	r = kept
+	e.Results = append(e.Results, r)
+	if e.Events != nil {
+		e.Events.publish(Event{Type: EventTrialCompleted, Result: &r})
+	}
+	return nil
+}
+
+// validateTrial checks that a trial belongs to the design: its control levels
+// match its array row and its noise levels one of the generated noise
+// conditions. Rows past the end of the array are follow-up runs (see
+// AugmentationTrials and SuggestNext), whose control levels need only be
+// levels of their factors or, for continuous factors, within their range.
+func (e *Experiment[P]) validateTrial(trial Trial) error {
+	if trial.Row < 0 {
+		return errorf(ErrTrialNotInDesign, "invalid row index %d", trial.Row)
+	}
+	if len(trial.Control) != len(e.ControlFactors) {
+		return errorf(ErrTrialNotInDesign, "got %d control factors, want %d", len(trial.Control), len(e.ControlFactors))
+	}
+	for j, factor := range e.ControlFactors {
+		level, ok := trial.Control[factor.Name]
+		switch {
+		case !ok:
+			return errorf(ErrTrialNotInDesign, "missing control factor %s", factor.Name)
+		case trial.Row < len(e.OrthogonalArray) && level != factor.Levels[e.levelIndex(trial.Row, j)]:
+			return errorf(ErrLevelMismatch, "control factor %s is %g but row %d sets %g", factor.Name, level, trial.Row+1, factor.Levels[e.levelIndex(trial.Row, j)])
+		case trial.Row >= len(e.OrthogonalArray) && factor.Continuous:
+			if lo, hi := levelRange(factor); level < lo || level > hi {
+				return errorf(ErrLevelMismatch, "control factor %s: %g is outside its range [%g, %g]", factor.Name, level, lo, hi)
+			}
+		case trial.Row >= len(e.OrthogonalArray) && !slices.Contains(factor.Levels, level):
+			return errorf(ErrLevelMismatch, "control factor %s: %g is not one of its levels", factor.Name, level)
+		}
+	}
+	for _, condition := range e.noiseConditions() {
+		if maps.Equal(condition.Noise, trial.Noise) {
+			return nil
+		}
+	}
+	return errorf(ErrTrialNotInDesign, "noise condition %v is not part of the design", trial.Noise)
+}
+
+// validateObservations rejects empty and non-finite observations.
+func validateObservations(observations []float64) error {
+	if len(observations) == 0 {
+		return errorf(ErrInvalidObservations, "no observations")
+	}
+	for i, y := range observations {
+		if math.IsNaN(y) || math.IsInf(y, 0) {
+			return errorf(ErrInvalidObservations, "observation %d is %g", i+1, y)
+		}
+	}
+	return nil
+}
+
+// checkPairedGoal rejects the options that treat the observations of a goal
+// recording (x, y) pairs one by one, which would split or mix the pairs:
+// outlier handling with RobustSNR, RetainSample and AnalyzeMeans.
+func (e *Experiment[P]) checkPairedGoal() error {
+	if !pairedGoal(e.Goal) {
+		return nil
+	}
+	switch {
+	case e.RobustSNR != NoRobustSNR:
+		return errorf(ErrUnsupportedGoal, "RobustSNR %s would split the %s observation pairs", e.RobustSNR, e.Goal)
+	case e.Retention == RetainSample:
+		return errorf(ErrUnsupportedGoal, "RetainSample would split the %s observation pairs", e.Goal)
+	case e.AnalyzeMeans:
+		return errorf(ErrUnsupportedGoal, "AnalyzeMeans would average both values of the %s observation pairs", e.Goal)
+	}
+	return nil
 }
 
 // Analyze performs a full Taguchi analysis on the collected trial results.
-func (e *Experiment[P]) Analyze() AnalysisResult {
-	oaSNR, grandMean := e.computeOASNR()
-	anova, mainEffects, snrPerFactor := e.computeANOVA(oaSNR, grandMean)
+// It returns an error naming the orthogonal array rows without results,
+// unless AllowIncomplete is set (see checkComplete), and an error matching
+// ErrUnsupportedGoal for options that would split the pairs of a goal
+// recording pairs (see checkPairedGoal).
+//
+// When trials were replicated, with AppendResult or replicate blocks, the
+// ANOVA error includes pure error: the spread of the SNRs of a row's complete
+// replicates (the k-th result of every noise condition). Saturated designs
+// then still get genuine error degrees of freedom and F-ratios, and designs
+// with residual degrees of freedom also get a lack-of-fit test.
+//
+// With ErrorColumns, the error is estimated from the sums of squares of those
+// columns instead of the variation left unexplained, which leaves the other
+// free columns, e.g. suspected interactions, out of the F-tests.
+//
+// A successful analysis is published as EventAnalysisReady on e.Events.
+func (e *Experiment[P]) Analyze() (AnalysisResult, error) {
+	result, err := e.analyze()
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	e.publishAnalysis(result)
+	return result, nil
+}
+
+// analyze computes the analysis of Analyze without publishing it, for the
+// methods building on it.
+func (e *Experiment[P]) analyze() (AnalysisResult, error) {
+	if err := e.checkPairedGoal(); err != nil {
+		return AnalysisResult{}, err
+	}
+	if err := e.checkComplete(); err != nil {
+		return AnalysisResult{}, err
+	}
+	rows := e.computeOASNR()
+	anova, mainEffects, snrPerFactor := e.computeANOVA(rows)
 	optimalLevels := e.findOptimalLevels(mainEffects)
-	contributions := computeContributions(anova)
+	ties := e.resolveTies(rows, anova, mainEffects, optimalLevels)
+	contributions, errorContribution := computeContributions(anova)
+	groups, groupContributions := computeGroupContributions(e.ControlFactors, contributions)
+	boundary := e.findBoundaryOptima(optimalLevels, anova, contributions)
+	stability := e.computeStability()
+	outliers := e.outlierObservations()
+	var trend *TimeTrend
+	if e.AnalyzeTimeTrend {
+		trend = e.timeTrend()
+	}
 
 	return AnalysisResult{
-		OptimalLevels: optimalLevels,
-		SNR:           snrPerFactor,
-		MainEffects:   mainEffects,
-		Contributions: contributions,
-		ANOVA:         anova,
+		OptimalLevels:      optimalLevels,
+		Ties:               ties,
+		Comparisons:        e.compareLevels(rows, anova, mainEffects),
+		Interpolated:       e.interpolateOptima(rows, anova, mainEffects, optimalLevels),
+		ObservedFactors:    e.observedFactors(),
+		SNR:                snrPerFactor,
+		MainEffects:        mainEffects,
+		Contributions:      contributions,
+		ErrorContribution:  errorContribution,
+		ANOVA:              anova,
+		Interactions:       e.interactionEffects(rows, anova),
+		Groups:             groups,
+		GroupContributions: groupContributions,
+		CostBenefit:        computeCostBenefit(e.ControlFactors, contributions, anova),
+		BoundaryOptima:     boundary,
+		Stability:          stability,
+		Outliers:           outliers,
+		Warnings:           e.collectWarnings(rows, anova, boundary, stability, ties, outliers, trend),
+		MeanAnalysis:       e.meanAnalysis(rows, anova),
+		TimeTrend:          trend,
+	}, nil
+}
+
+// checkComplete returns an error listing the orthogonal array rows without
+// results. With AllowIncomplete it returns nil: the SNR of such rows is
+// imputed with the mean of the measured rows, one error degree of freedom is
+// given up per imputed row, and each row is reported as a WarnMissingRow
+// warning.
+func (e *Experiment[P]) checkComplete() error {
+	if e.AllowIncomplete {
+		return nil
+	}
+	var missing []int
+	for i := range e.OrthogonalArray {
+		if !e.rowHasResults(i) {
+			missing = append(missing, i+1)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingResultsError{Rows: missing}
 	}
+	return nil
 }
 
 // computeOASNR computes the Signal-to-Noise ratio for each orthogonal array row
 // by collecting all observations across noise conditions and computing SNR once
-// on the combined set. Returns the per-row SNR values and the grand mean.
-func (e *Experiment[P]) computeOASNR() ([]float64, float64) {
+// on the combined set. Rows whose raw observations were not retained are
+// computed from the merged ObservationSummary instead. Infinite row SNRs are
+// handled according to the experiment's InfiniteSNR policy, and rows without
+// results are imputed with the mean SNR of the measured rows.
+func (e *Experiment[P]) computeOASNR() oaRowSNR {
 	oaRows := len(e.OrthogonalArray)
-	oaSNR := make([]float64, oaRows)
-	grandMean := 0.0
+	rows := oaRowSNR{
+		values:     make([]float64, oaRows),
+		included:   make([]bool, oaRows),
+		infinite:   make([]bool, oaRows),
+		missing:    make([]bool, oaRows),
+		replicates: make([][]float64, oaRows),
+	}
 
 	for i := 0; i < oaRows; i++ {
-		var allObs []float64
+		var matched []TrialResult
 		for _, r := range e.Results {
-			match := true
-			for j, factor := range e.ControlFactors {
-				if r.Trial.Control[factor.Name] != factor.Levels[e.OrthogonalArray[i][j]-1] {
-					match = false
-					break
-				}
-			}
-			if match {
-				allObs = append(allObs, r.Observations...)
+			if e.rowMatches(i, r.Trial) {
+				matched = append(matched, r)
 			}
 		}
-		if len(allObs) > 0 {
-			oaSNR[i] = e.Goal.CalculateSNR(allObs)
-		} else {
-			oaSNR[i] = 0
+		rows.values[i] = e.resultsSNR(matched)
+		rows.replicates[i] = e.replicateSNRs(matched)
+		rows.missing[i] = len(matched) == 0
+		rows.included[i] = !rows.missing[i]
+	}
+	e.applyInfinitePolicy(&rows)
+	for i, missing := range rows.missing {
+		if missing {
+			rows.values[i] = rows.grandMean
+			rows.included[i] = true
 		}
-		grandMean += oaSNR[i]
 	}
-	grandMean /= float64(oaRows)
 
-	return oaSNR, grandMean
+	return rows
+}
+
+// resultsSNR computes the SNR of the combined observations of results, falling
+// back to their merged ObservationSummary when raw observations were not
+// retained. Outlier observations are first treated according to the RobustSNR
+// policy. With weighted noise factors, the noise conditions are weighted by
+// their likelihood (see NoiseFactor.Weights). For goals recording pairs, each
+// result contributes its whole pairs, so a stray value never shifts the pairs
+// of the next one. It returns 0 when there are no observations.
+func (e *Experiment[P]) resultsSNR(results []TrialResult) float64 {
+	results, _ = e.robustResults(results)
+	paired := pairedGoal(e.Goal)
+	var allObs []float64
+	var summary ObservationSummary
+	rawComplete := true
+	for _, r := range results {
+		summary.Merge(r.Summary)
+		obs, err := r.RawObservations()
+		if err != nil || len(obs) < r.Summary.Count {
+			rawComplete = false
+			continue
+		}
+		if paired {
+			obs = obs[:len(obs)/2*2]
+		}
+		allObs = append(allObs, obs...)
+	}
+	sg, canSummarize := e.Goal.(SummaryGoal)
+	switch {
+	case canSummarize && summary.Count > 0 && noiseWeighted(e.NoiseFactors):
+		return sg.SNRFromSummary(e.weightedSummary(results))
+	case !rawComplete && canSummarize && summary.Count > 0:
+		return sg.SNRFromSummary(summary)
+	case len(allObs) > 0:
+		return e.Goal.CalculateSNR(allObs)
+	default:
+		return 0
+	}
+}
+
+// rowMatches reports whether a trial's control configuration corresponds to
+// orthogonal array row i.
+func (e *Experiment[P]) rowMatches(i int, trial Trial) bool {
+	for j, factor := range e.ControlFactors {
+		if trial.Control[factor.Name] != factor.Levels[e.levelIndex(i, j)] {
+			return false
+		}
+	}
+	return true
+}
+
+// rowHasResults reports whether at least one result was recorded for row i.
+func (e *Experiment[P]) rowHasResults(i int) bool {
+	for _, r := range e.Results {
+		if e.rowMatches(i, r.Trial) {
+			return true
+		}
+	}
+	return false
 }
 
 // findOptimalLevels determines the best level for each control factor by
-// selecting the level with the highest mean SNR (main effect).
+// selecting the level with the highest mean SNR (main effect). Observe-only
+// factors are skipped.
 func (e *Experiment[P]) findOptimalLevels(mainEffects map[string][]float64) map[string]float64 {
 	optimalLevels := map[string]float64{}
 	for _, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
 		levels := mainEffects[factor.Name]
 		bestLevel := 0
 		maxVal := levels[0]
@@ -171,3 +501,14 @@ func (e *Experiment[P]) findOptimalLevels(mainEffects map[string][]float64) map[
 	}
 	return optimalLevels
 }
+
+// observedFactors returns the names of the observe-only control factors.
+func (e *Experiment[P]) observedFactors() []string {
+	var names []string
+	for _, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			names = append(names, factor.Name)
+		}
+	}
+	return names
+}
@@ -11,6 +11,16 @@ func almostEqual(a, b float64) bool {
 	return math.Abs(a-b) < float64EqualityThreshold
 }
 
+// mustAnalyze analyzes e and fails the test on error.
+func mustAnalyze[P any](t *testing.T, e *Experiment[P]) AnalysisResult {
+	t.Helper()
+	result, err := e.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	return result
+}
+
 // TestAnalyze_SNR_CombinesObservationsAcrossNoise verifies that SNR is computed
 // on the combined observations across all noise conditions for a given OA row,
 // not by averaging per-trial SNRs (which is incorrect due to log10 nonlinearity).
@@ -49,7 +59,7 @@ func TestAnalyze_SNR_CombinesObservationsAcrossNoise(t *testing.T) {
 	// A=2, N=1 → obs [1,1]
 	exp.AddResult(trials[3], []float64{1, 1})
 
-	result := exp.Analyze()
+	result := mustAnalyze(t, exp)
 
 	// Expected SNR for A=1: SNR([2,4,6,8]) = -10*log10(mean(4+16+36+64))
 	//   = -10*log10((4+16+36+64)/4) = -10*log10(30) ≈ -14.771
@@ -106,7 +116,7 @@ func TestAnalyze_SNR_LargerTheBetter(t *testing.T) {
 	// A=2, N=1 → obs [10,10]
 	exp.AddResult(trials[3], []float64{10, 10})
 
-	result := exp.Analyze()
+	result := mustAnalyze(t, exp)
 
 	// A=1 combined: [2,4,6,8]
 	// mean(1/y²) = (1/4 + 1/16 + 1/36 + 1/64)/4
@@ -158,24 +168,26 @@ func TestAnalyze_SNR_NominalTheBest(t *testing.T) {
 	// A=2, N=1 → obs [5,5]
 	exp.AddResult(trials[3], []float64{5, 5})
 
-	result := exp.Analyze()
+	result := mustAnalyze(t, exp)
 
 	// A=1 combined: [3,4,6,7], deviations from target=5: [-2,-1,1,2]
 	// mean((y-5)²) = (4+1+1+4)/4 = 2.5
 	expectedSNR_A1 := -10 * math.Log10(2.5)
 
 	// A=2 combined: [5,5,5,5], all exactly on target
-	// mean((y-5)²) = 0 → SNR = +Inf
-	expectedSNR_A2 := math.Inf(1)
+	// mean((y-5)²) = 0 → SNR = +Inf, capped at DefaultSNRCeiling
+	expectedSNR_A2 := DefaultSNRCeiling
 
 	snrA := result.SNR["A"]
 	if !almostEqual(snrA[0], expectedSNR_A1) {
 		t.Errorf("SNR[A][0]: got %.4f, want %.4f", snrA[0], expectedSNR_A1)
 	}
-	if !math.IsInf(snrA[1], 1) {
-		t.Errorf("SNR[A][1]: got %.4f, want +Inf", snrA[1])
+	if !almostEqual(snrA[1], expectedSNR_A2) {
+		t.Errorf("SNR[A][1]: got %.4f, want %.4f", snrA[1], expectedSNR_A2)
+	}
+	if !result.HasWarning(WarnInfiniteSNR) {
+		t.Errorf("expected %s warning", WarnInfiniteSNR)
 	}
-	_ = expectedSNR_A2
 
 	if result.OptimalLevels["A"] != 2.0 {
 		t.Errorf("OptimalLevels[A]: got %v, want 2.0", result.OptimalLevels["A"])
@@ -206,7 +218,7 @@ func TestAnalyze_SingleTrialPerRow(t *testing.T) {
 	exp.AddResult(trials[0], []float64{2, 4})
 	exp.AddResult(trials[1], []float64{1, 1})
 
-	result := exp.Analyze()
+	result := mustAnalyze(t, exp)
 
 	// A=1: SNR([2,4]) = -10*log10((4+16)/2) = -10*log10(10) = -10
 	expectedSNR_A1 := -10 * math.Log10(10)
@@ -245,7 +257,7 @@ func TestAnalyze_ANOVA_BasicSanity(t *testing.T) {
 	exp.AddResult(trials[2], []float64{6})  // A=2, B=1
 	exp.AddResult(trials[3], []float64{10}) // A=2, B=2
 
-	result := exp.Analyze()
+	result := mustAnalyze(t, exp)
 
 	// Check ANOVA fields exist for both factors
 	for _, name := range []string{"A", "B"} {
@@ -261,6 +273,9 @@ func TestAnalyze_ANOVA_BasicSanity(t *testing.T) {
 		if _, ok := result.ANOVA.FactorF[name]; !ok {
 			t.Errorf("ANOVA.FactorF missing factor %s", name)
 		}
+		if p, ok := result.ANOVA.FactorP[name]; !ok || p < 0 || p > 1 {
+			t.Errorf("ANOVA.FactorP[%s]: got %v, want a probability", name, p)
+		}
 	}
 
 	// DF for 2-level factor should be 1
@@ -271,14 +286,19 @@ func TestAnalyze_ANOVA_BasicSanity(t *testing.T) {
 		t.Errorf("ANOVA.FactorDF[B]: got %d, want 1", result.ANOVA.FactorDF["B"])
 	}
 
-	// Contributions should sum to 100%
-	totalContrib := 0.0
+	// Contributions and the error contribution should sum to 100%
+	totalContrib := result.ErrorContribution
 	for _, c := range result.Contributions {
 		totalContrib += c
 	}
 	if !almostEqual(totalContrib, 100.0) {
 		t.Errorf("Contributions sum: got %.4f, want 100.0", totalContrib)
 	}
+	// The A×B interaction is not modeled, so part of the variation is error.
+	totalSS := result.ANOVA.ErrorSS + result.ANOVA.FactorSS["A"] + result.ANOVA.FactorSS["B"]
+	if want := 100 * result.ANOVA.ErrorSS / totalSS; result.ErrorContribution <= 0 || !almostEqual(result.ErrorContribution, want) {
+		t.Errorf("ErrorContribution: got %.4f, want %.4f", result.ErrorContribution, want)
+	}
 
 	// SS values should be non-negative
 	for name, ss := range result.ANOVA.FactorSS {
@@ -287,3 +307,287 @@ func TestAnalyze_ANOVA_BasicSanity(t *testing.T) {
 		}
 	}
 }
+
+// TestAnalyze_ExcludeInfiniteSNR verifies that excluded perfect rows do not
+// poison the grand mean and sums of squares with Inf/NaN.
+func TestAnalyze_ExcludeInfiniteSNR(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}}
+	noise := []NoiseFactor{
+		{Name: "N", Levels: []float64{0}},
+	}
+
+	exp, err := NewExperimentFromFactorsUsingArray(NominalTheBest{Target: 5}, factors, oa, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	exp.InfiniteSNR = ExcludeInfiniteSNR
+
+	trials := exp.GenerateTrials()
+	// The first row is perfect, so its SNR is +Inf.
+	for i, obs := range [][]float64{{5, 5}, {3, 7}, {4, 6}, {2, 8}} {
+		if err := exp.AddResult(trials[i], obs); err != nil {
+			t.Fatalf("AddResult(trial %d): %v", i, err)
+		}
+	}
+
+	result := mustAnalyze(t, exp)
+
+	for name, ss := range result.ANOVA.FactorSS {
+		if math.IsInf(ss, 0) || math.IsNaN(ss) {
+			t.Errorf("ANOVA.FactorSS[%s] is not finite: %v", name, ss)
+		}
+	}
+	// A=1 is now estimated from row 2 only: SNR([3,7]) = -10*log10(4)
+	if want := -10 * math.Log10(4); !almostEqual(result.SNR["A"][0], want) {
+		t.Errorf("SNR[A][0]: got %.4f, want %.4f", result.SNR["A"][0], want)
+	}
+	if !result.HasWarning(WarnInfiniteSNR) {
+		t.Errorf("expected %s warning", WarnInfiniteSNR)
+	}
+}
+
+// TestAnalyze_ObserveOnlyFactor verifies that an observe-only factor is
+// estimated in the ANOVA but left out of the optimal levels.
+func TestAnalyze_ObserveOnlyFactor(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "Batch", Levels: []float64{1, 2}, ObserveOnly: true},
+	}
+	oa := [][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}}
+	noise := []NoiseFactor{
+		{Name: "N", Levels: []float64{0}},
+	}
+
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	trials := exp.GenerateTrials()
+	for i, y := range []float64{2, 4, 6, 10} {
+		if err := exp.AddResult(trials[i], []float64{y}); err != nil {
+			t.Fatalf("AddResult(trial %d): %v", i, err)
+		}
+	}
+
+	result := mustAnalyze(t, exp)
+
+	if _, ok := result.OptimalLevels["Batch"]; ok {
+		t.Error("OptimalLevels contains observe-only factor Batch")
+	}
+	if _, ok := result.OptimalLevels["A"]; !ok {
+		t.Error("OptimalLevels missing factor A")
+	}
+	if _, ok := result.ANOVA.FactorSS["Batch"]; !ok {
+		t.Error("ANOVA.FactorSS missing observe-only factor Batch")
+	}
+	if len(result.ObservedFactors) != 1 || result.ObservedFactors[0] != "Batch" {
+		t.Errorf("ObservedFactors: got %v, want [Batch]", result.ObservedFactors)
+	}
+}
+
+// TestAnalyze_CostBenefit verifies that factors with a change cost are ranked
+// by contribution per unit cost, leaving out observe-only factors and those
+// without a cost.
+func TestAnalyze_CostBenefit(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}, ChangeCost: 40},
+		{Name: "B", Levels: []float64{1, 2}, ChangeCost: 2},
+		{Name: "Batch", Levels: []float64{1, 2}, ChangeCost: 1, ObserveOnly: true},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}})
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		y := 10 + 8*trial.Control["A"] + 2*trial.Control["B"] + trial.Control["Batch"] + trial.Noise["N"]
+		if err := exp.AddResult(trial, []float64{y}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	result := mustAnalyze(t, exp)
+
+	if len(result.CostBenefit) != 2 || result.CostBenefit[0].Factor != "B" || result.CostBenefit[1].Factor != "A" {
+		t.Fatalf("CostBenefit = %+v, want B then A", result.CostBenefit)
+	}
+	for _, cb := range result.CostBenefit {
+		if want := result.Contributions[cb.Factor] / cb.Cost; !almostEqual(cb.PerUnitCost, want) {
+			t.Errorf("%s: PerUnitCost = %g, want %g", cb.Factor, cb.PerUnitCost, want)
+		}
+	}
+	if result.Contributions["A"] <= result.Contributions["B"] {
+		t.Errorf("contributions A %g, B %g: want A to dominate before costs", result.Contributions["A"], result.Contributions["B"])
+	}
+
+	for i := range exp.ControlFactors {
+		exp.ControlFactors[i].ChangeCost = 0
+	}
+	if result := mustAnalyze(t, exp); result.CostBenefit != nil {
+		t.Errorf("CostBenefit without costs = %+v, want nil", result.CostBenefit)
+	}
+}
+
+func TestAddResult_Validation(t *testing.T) {
+	factors := []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	trials := exp.GenerateTrials()
+
+	bad := []struct {
+		name  string
+		trial Trial
+		obs   []float64
+	}{
+		{"wrong control level", Trial{ID: 1, Row: 0, Control: map[string]float64{"A": 2}, Noise: map[string]float64{"N": 0}}, []float64{1}},
+		{"unknown factor", Trial{ID: 1, Row: 0, Control: map[string]float64{"B": 1}, Noise: map[string]float64{"N": 0}}, []float64{1}},
+		{"unknown noise level", Trial{ID: 1, Row: 0, Control: map[string]float64{"A": 1}, Noise: map[string]float64{"N": 5}}, []float64{1}},
+		{"missing noise", Trial{ID: 1, Row: 0, Control: map[string]float64{"A": 1}}, []float64{1}},
+		{"no observations", trials[0], nil},
+		{"NaN", trials[0], []float64{1, math.NaN()}},
+		{"Inf", trials[0], []float64{math.Inf(1)}},
+	}
+	for _, tt := range bad {
+		if err := exp.AddResult(tt.trial, tt.obs); err == nil {
+			t.Errorf("%s: AddResult accepted the result", tt.name)
+		}
+	}
+	if len(exp.Results) != 0 {
+		t.Fatalf("rejected results were recorded: %d", len(exp.Results))
+	}
+
+	if err := exp.AddResult(trials[0], []float64{1}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	if err := exp.AddResult(trials[0], []float64{2}); err == nil {
+		t.Error("duplicate result accepted")
+	}
+	if err := exp.AppendResult(trials[0], []float64{2}); err != nil {
+		t.Errorf("AppendResult: %v", err)
+	}
+	if len(exp.Results) != 2 {
+		t.Errorf("len(Results) = %d, want 2", len(exp.Results))
+	}
+}
+
+// TestAnalyze_GroupContributions verifies that group contributions are the
+// sums of their factors' contributions, with factors without a group under
+// UngroupedFactors, and that ungrouped experiments report no groups.
+func TestAnalyze_GroupContributions(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}, Group: "runtime"},
+		{Name: "B", Levels: []float64{1, 2}, Group: "runtime"},
+		{Name: "C", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}})
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		y := 10 + 8*trial.Control["A"] + 2*trial.Control["B"] + 4*trial.Control["C"] + trial.Noise["N"]
+		if err := exp.AddResult(trial, []float64{y}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	result := mustAnalyze(t, exp)
+
+	if got := result.Groups["runtime"]; len(got) != 2 || got[0] != "A" || got[1] != "B" {
+		t.Errorf("Groups[runtime] = %v, want [A B]", got)
+	}
+	if got := result.Groups[UngroupedFactors]; len(got) != 1 || got[0] != "C" {
+		t.Errorf("Groups[%s] = %v, want [C]", UngroupedFactors, got)
+	}
+	if len(result.GroupContributions) != 2 {
+		t.Errorf("GroupContributions = %v, want 2 groups", result.GroupContributions)
+	}
+	if got, want := result.GroupContributions["runtime"], result.Contributions["A"]+result.Contributions["B"]; !almostEqual(got, want) {
+		t.Errorf("GroupContributions[runtime] = %g, want %g", got, want)
+	}
+	if got, want := result.GroupContributions[UngroupedFactors], result.Contributions["C"]; !almostEqual(got, want) {
+		t.Errorf("GroupContributions[%s] = %g, want %g", UngroupedFactors, got, want)
+	}
+
+	for i := range exp.ControlFactors {
+		exp.ControlFactors[i].Group = ""
+	}
+	if result := mustAnalyze(t, exp); result.Groups != nil || result.GroupContributions != nil {
+		t.Errorf("ungrouped experiment: Groups = %v, GroupContributions = %v, want nil", result.Groups, result.GroupContributions)
+	}
+}
+
+// TestComputeGroupContributions_UnknownFactors verifies that contributions
+// of names that are not factors are left out of the groups and that a
+// factor without a contribution adds nothing to its group.
+func TestComputeGroupContributions_UnknownFactors(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Group: "g"},
+		{Name: "B", Group: "g"},
+	}
+	contributions := map[string]float64{"A": 30, "Unknown": 50, "A×B": 15}
+	groups, sums := computeGroupContributions(factors, contributions)
+
+	if len(groups) != 1 || len(groups["g"]) != 2 {
+		t.Errorf("groups = %v, want only g with A and B", groups)
+	}
+	if len(sums) != 1 || sums["g"] != 30 {
+		t.Errorf("group contributions = %v, want g: 30", sums)
+	}
+}
+
+// TestAnalyze_ANOVA_Saturated verifies that a saturated L9 with four
+// three-level factors reports undefined F-ratios and p-values instead of
+// inventing an error degree of freedom, and warns about it.
+func TestAnalyze_ANOVA_Saturated(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2, 3}},
+		{Name: "B", Levels: []float64{1, 2, 3}},
+		{Name: "C", Levels: []float64{1, 2, 3}},
+		{Name: "D", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	exp.PostHoc = TukeyHSD
+	for _, trial := range exp.GenerateTrials() {
+		y := 10 + 5*trial.Control["A"] + trial.Control["B"] + 0.5*trial.Control["C"] + 0.1*trial.Control["D"]
+		if err := exp.AddResult(trial, []float64{y}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	result := mustAnalyze(t, exp)
+	a := result.ANOVA
+
+	if a.ErrorDF != 0 {
+		t.Errorf("ErrorDF = %d, want 0", a.ErrorDF)
+	}
+	for _, f := range factors {
+		if !math.IsNaN(a.FactorF[f.Name]) || !math.IsNaN(a.FactorP[f.Name]) {
+			t.Errorf("%s: F = %g, p = %g, want NaN", f.Name, a.FactorF[f.Name], a.FactorP[f.Name])
+		}
+		if a.Significant[f.Name] {
+			t.Errorf("%s marked significant without an error term", f.Name)
+		}
+	}
+	for _, c := range result.Comparisons {
+		if !math.IsNaN(c.P) || c.Significant {
+			t.Errorf("comparison %s: p = %g, significant %v, want NaN and not significant", c, c.P, c.Significant)
+		}
+	}
+	if !result.HasWarning(WarnSaturatedDesign) {
+		t.Errorf("expected %s warning, got %v", WarnSaturatedDesign, result.Warnings)
+	}
+	if result.HasWarning(WarnZeroErrorVariance) {
+		t.Errorf("unexpected %s warning for a design without error DF", WarnZeroErrorVariance)
+	}
+	// The effects themselves are still estimated.
+	if result.OptimalLevels["A"] != 1 || result.Contributions["A"] <= result.Contributions["B"] {
+		t.Errorf("optimal A = %g, contributions %v; want A at 1 and dominant", result.OptimalLevels["A"], result.Contributions)
+	}
+}
@@ -0,0 +1,217 @@
+package taguchi
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// FullFactorial returns the design that crosses every level of every factor,
+// one column per factor in order, with the first factor changing slowest. The
+// layout is compatible with NewExperimentUsingArray; with k factors of n
+// levels it has n^k rows, so it suits few factors or a final confirmation of
+// the most important ones.
+func FullFactorial(factors []ControlFactor) ([][]int, error) {
+	if len(factors) == 0 {
+		return nil, fmt.Errorf("no factors")
+	}
+	runs := 1
+	for _, f := range factors {
+		if len(f.Levels) < 2 {
+			return nil, fmt.Errorf("factor %s: at least 2 levels required, got %d", f.Name, len(f.Levels))
+		}
+		runs *= len(f.Levels)
+	}
+	oa := make([][]int, runs)
+	for r := range oa {
+		row := make([]int, len(factors))
+		rest := r
+		for j := len(factors) - 1; j >= 0; j-- {
+			row[j] = rest%len(factors[j].Levels) + 1
+			rest /= len(factors[j].Levels)
+		}
+		oa[r] = row
+	}
+	return oa, nil
+}
+
+// FractionalFactorial returns the smallest regular two-level 2^(k-p)
+// fractional factorial design of at least the given resolution for k
+// two-level factors, one column per factor in order. The first k-p factors
+// form a full factorial; every other factor is generated as the product of
+// basic factors, chosen so that no word of the defining relation is shorter
+// than the resolution:
+//   - resolution 3: main effects are clear of each other but aliased with two-factor interactions;
+//   - resolution 4: main effects are clear of two-factor interactions, which are aliased with each other;
+//   - resolution 5: main effects and two-factor interactions are all clear of each other.
+//
+// Longer generator words are preferred, which keeps aliasing low.
+func FractionalFactorial(factors []ControlFactor, resolution int) ([][]int, error) {
+	if len(factors) == 0 {
+		return nil, fmt.Errorf("no factors")
+	}
+	if resolution < 3 {
+		return nil, fmt.Errorf("resolution must be at least 3, got %d", resolution)
+	}
+	for _, f := range factors {
+		if len(f.Levels) != 2 {
+			return nil, fmt.Errorf("factor %s has %d levels; fractional factorial designs need two-level factors", f.Name, len(f.Levels))
+		}
+	}
+	k := len(factors)
+	for m := 1; m <= k; m++ {
+		if generators, ok := findGenerators(m, k-m, resolution); ok {
+			return twoLevelDesign(m, generators), nil
+		}
+	}
+	return nil, fmt.Errorf("no design of resolution %d for %d factors", resolution, k)
+}
+
+// findGenerators searches for p generator words over m basic factors, each a
+// bit mask of at least two basic factors, such that every word of the
+// defining relation has at least resolution letters.
+func findGenerators(m, p, resolution int) ([]uint, bool) {
+	if p == 0 {
+		return nil, true
+	}
+	var candidates []uint
+	for mask := uint(1); mask < 1<<m; mask++ {
+		// A generator word has the basic factors of mask plus the generated factor.
+		if bits.OnesCount(mask) >= 2 && bits.OnesCount(mask)+1 >= resolution {
+			candidates = append(candidates, mask)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return bits.OnesCount(candidates[i]) > bits.OnesCount(candidates[j])
+	})
+
+	// words holds the basic-factor mask and generator count of every product
+	// of the chosen generators, the words of the defining relation.
+	type word struct {
+		mask uint
+		gens int
+	}
+	var chosen []uint
+	var search func(start int, words []word) bool
+	search = func(start int, words []word) bool {
+		if len(chosen) == p {
+			return true
+		}
+		for c := start; c < len(candidates); c++ {
+			g := candidates[c]
+			grown := append([]word(nil), words...)
+			ok := true
+			for _, w := range append([]word{{}}, words...) {
+				nw := word{mask: w.mask ^ g, gens: w.gens + 1}
+				if bits.OnesCount(nw.mask)+nw.gens < resolution {
+					ok = false
+					break
+				}
+				grown = append(grown, nw)
+			}
+			if !ok {
+				continue
+			}
+			chosen = append(chosen, g)
+			if search(c+1, grown) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+	if !search(0, nil) {
+		return nil, false
+	}
+	return chosen, true
+}
+
+// twoLevelDesign builds the 2^m-run design of m basic factors followed by one
+// column per generator, the parity of the basic factors in its mask.
+func twoLevelDesign(m int, generators []uint) [][]int {
+	oa := make([][]int, 1<<m)
+	for r := range oa {
+		row := make([]int, 0, m+len(generators))
+		var x uint
+		for j := 0; j < m; j++ {
+			bit := uint(r>>(m-1-j)) & 1
+			x |= bit << j
+			row = append(row, int(bit)+1)
+		}
+		for _, g := range generators {
+			row = append(row, bits.OnesCount(x&g)%2+1)
+		}
+		oa[r] = row
+	}
+	return oa
+}
+
+// plackettBurmanGenerators holds the first rows of the cyclic Plackett-Burman
+// designs, with true for the high level.
+var plackettBurmanGenerators = map[int]string{
+	20: "++--++++-+-+----++-",
+	24: "+++++-+-++--++--+-+----",
+}
+
+// PlackettBurman returns the smallest two-level Plackett-Burman screening
+// design for the factors, one column per factor in order. Its run count is
+// the smallest supported multiple of four above the number of factors (4, 8,
+// 12, 16, 20, 24 or 32 runs), so up to N-1 main effects are estimated in N
+// runs. Two-factor interactions are partially aliased with every main effect.
+func PlackettBurman(factors []ControlFactor) ([][]int, error) {
+	if len(factors) == 0 {
+		return nil, fmt.Errorf("no factors")
+	}
+	for _, f := range factors {
+		if len(f.Levels) != 2 {
+			return nil, fmt.Errorf("factor %s has %d levels; Plackett-Burman designs need two-level factors", f.Name, len(f.Levels))
+		}
+	}
+	k := len(factors)
+	var full [][]int
+	switch {
+	case k <= 3:
+		full = primePowerArray(2, 2)
+	case k <= 7:
+		full = primePowerArray(2, 3)
+	case k <= 11:
+		full = l12Rows
+	case k <= 15:
+		full = primePowerArray(2, 4)
+	case k <= 19:
+		full = cyclicPlackettBurman(plackettBurmanGenerators[20])
+	case k <= 23:
+		full = cyclicPlackettBurman(plackettBurmanGenerators[24])
+	case k <= 31:
+		full = primePowerArray(2, 5)
+	default:
+		return nil, errorf(ErrArrayTooSmall, "%d factors exceed the largest supported Plackett-Burman design (32 runs)", k)
+	}
+	oa := make([][]int, len(full))
+	for r, row := range full {
+		oa[r] = append([]int(nil), row[:k]...)
+	}
+	return oa, nil
+}
+
+// cyclicPlackettBurman builds a Plackett-Burman design from its generator:
+// a row of low levels followed by the N-1 cyclic shifts of the generator.
+func cyclicPlackettBurman(generator string) [][]int {
+	n := len(generator)
+	oa := make([][]int, n+1)
+	oa[0] = make([]int, n)
+	for c := range oa[0] {
+		oa[0][c] = 1
+	}
+	for r := 0; r < n; r++ {
+		row := make([]int, n)
+		for c := range row {
+			row[c] = 1
+			if generator[(c-r+n)%n] == '+' {
+				row[c] = 2
+			}
+		}
+		oa[r+1] = row
+	}
+	return oa
+}
@@ -0,0 +1,87 @@
+package taguchi
+
+import (
+	"fmt"
+	"testing"
+)
+
+func twoLevelFactors(k int) []ControlFactor {
+	factors := make([]ControlFactor, k)
+	for j := range factors {
+		factors[j] = ControlFactor{Name: fmt.Sprintf("F%d", j+1), Levels: []float64{0, 1}}
+	}
+	return factors
+}
+
+func TestFullFactorial(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2, 3}},
+	}
+	oa, err := FullFactorial(factors)
+	if err != nil {
+		t.Fatalf("FullFactorial: %v", err)
+	}
+	if len(oa) != 6 || fmt.Sprint(oa[0], oa[1], oa[5]) != "[1 1] [1 2] [2 3]" {
+		t.Errorf("FullFactorial = %v, want the 6 combinations with A changing slowest", oa)
+	}
+	if err := ValidateArray(oa); err != nil {
+		t.Errorf("ValidateArray: %v", err)
+	}
+	if _, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, nil); err != nil {
+		t.Errorf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+}
+
+func TestFractionalFactorial(t *testing.T) {
+	tests := []struct {
+		k, resolution, runs int
+	}{
+		{3, 3, 4},
+		{4, 4, 8},
+		{5, 5, 16},
+		{7, 3, 8},
+		{8, 4, 16},
+		{6, 4, 16},
+	}
+	for _, tt := range tests {
+		factors := twoLevelFactors(tt.k)
+		oa, err := FractionalFactorial(factors, tt.resolution)
+		if err != nil {
+			t.Fatalf("k=%d R=%d: %v", tt.k, tt.resolution, err)
+		}
+		if len(oa) != tt.runs || len(oa[0]) != tt.k {
+			t.Errorf("k=%d R=%d: %d×%d design, want %d runs", tt.k, tt.resolution, len(oa), len(oa[0]), tt.runs)
+			continue
+		}
+		if err := ValidateArray(oa); err != nil {
+			t.Errorf("k=%d R=%d: ValidateArray: %v", tt.k, tt.resolution, err)
+		}
+		exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, nil)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+		}
+		if got := exp.DesignDiagnostics().Resolution; got < min(tt.resolution, 5) {
+			t.Errorf("k=%d R=%d: DesignDiagnostics resolution %d", tt.k, tt.resolution, got)
+		}
+	}
+
+	if _, err := FractionalFactorial([]ControlFactor{{Name: "A", Levels: []float64{1, 2, 3}}}, 3); err == nil {
+		t.Error("FractionalFactorial accepted a three-level factor")
+	}
+}
+
+func TestPlackettBurman(t *testing.T) {
+	for _, tt := range []struct{ k, runs int }{{3, 4}, {7, 8}, {11, 12}, {19, 20}, {23, 24}, {27, 32}} {
+		oa, err := PlackettBurman(twoLevelFactors(tt.k))
+		if err != nil {
+			t.Fatalf("k=%d: %v", tt.k, err)
+		}
+		if len(oa) != tt.runs || len(oa[0]) != tt.k {
+			t.Errorf("k=%d: %d×%d design, want %d runs", tt.k, len(oa), len(oa[0]), tt.runs)
+		}
+		if err := ValidateArray(oa); err != nil {
+			t.Errorf("k=%d: ValidateArray: %v", tt.k, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package taguchi
+
+import (
+	"slices"
+	"testing"
+)
+
+// testDesign describes an experiment built by newTestExperiment.
+// Goal: Optimization goal (SmallerTheBetter when nil).
+// Factors: Control factors (A and B at levels 1 and 2 when nil).
+// Array: Standard orthogonal array (L4 when empty and Rows is nil).
+// Rows: Custom orthogonal array used instead of Array.
+// Noise: Noise factors.
+// Response: Observations recorded for every generated trial (see recordResults); nothing is recorded when nil.
+type testDesign struct {
+	Goal     OptimizationGoal
+	Factors  []ControlFactor
+	Array    ArrayType
+	Rows     [][]int
+	Noise    []NoiseFactor
+	Response func(trial Trial) [][]float64
+}
+
+// newTestExperiment builds the experiment of d and records its Response,
+// failing the test when the design is invalid or a result is rejected.
+func newTestExperiment(t *testing.T, d testDesign) *Experiment[struct{}] {
+	t.Helper()
+	if d.Goal == nil {
+		d.Goal = SmallerTheBetter{}
+	}
+	if d.Factors == nil {
+		d.Factors = namedFactors([]float64{1, 2}, "A", "B")
+	}
+	var exp *Experiment[struct{}]
+	var err error
+	if d.Rows != nil {
+		exp, err = NewExperimentFromFactorsUsingArray(d.Goal, d.Factors, d.Rows, d.Noise)
+	} else {
+		if d.Array == "" {
+			d.Array = L4
+		}
+		exp, err = NewExperimentFromFactors(d.Goal, d.Factors, d.Array, d.Noise)
+	}
+	if err != nil {
+		t.Fatalf("building test experiment: %v", err)
+	}
+	if d.Response != nil {
+		recordResults(t, exp, d.Response)
+	}
+	return exp
+}
+
+// recordResults records the observations response returns for every
+// generated trial, one result per slice; the slices after the first are
+// recorded as replicates. The test fails on the first rejected result.
+func recordResults(t *testing.T, exp *Experiment[struct{}], response func(trial Trial) [][]float64) {
+	t.Helper()
+	for _, trial := range exp.GenerateTrials() {
+		for i, obs := range response(trial) {
+			record := exp.AddResult
+			if i > 0 {
+				record = exp.AppendResult
+			}
+			if err := record(trial, obs); err != nil {
+				t.Fatalf("recording trial %d: %v", trial.ID, err)
+			}
+		}
+	}
+}
+
+// namedFactors returns factors with the given names and levels.
+func namedFactors(values []float64, names ...string) []ControlFactor {
+	factors := make([]ControlFactor, len(names))
+	for i, name := range names {
+		factors[i] = ControlFactor{Name: name, Levels: slices.Clone(values)}
+	}
+	return factors
+}
+
+// singleResult returns a response recording a single result with the observations of y.
+func singleResult(y func(trial Trial) []float64) func(Trial) [][]float64 {
+	return func(trial Trial) [][]float64 { return [][]float64{y(trial)} }
+}
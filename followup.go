@@ -0,0 +1,201 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// FollowUpKind selects the design generated by GenerateFollowUp.
+type FollowUpKind int
+
+const (
+	// FollowUpFullFactorial crosses three levels of every selected factor
+	// (below, at and above its optimum), 3^k runs for k factors.
+	FollowUpFullFactorial FollowUpKind = iota
+	// FollowUpCentralComposite is a rotatable central composite design: the
+	// 2^k corners of the region, 2k axial runs at ±(2^k)^¼ steps from its
+	// center, and FollowUpCenterRuns center runs for pure error. It fits a
+	// full quadratic response surface in far fewer runs than a 3^k factorial.
+	FollowUpCentralComposite
+)
+
+// FollowUpCenterRuns is the number of center runs of a central composite design.
+const FollowUpCenterRuns = 3
+
+// FollowUp is a second-stage design around the optimum of a screening
+// experiment (see GenerateFollowUp).
+// Kind: The generated design.
+// Factors: The varied factors, with the distinct values they take in Runs as levels.
+// Center, Step: Center of the region (the screening optimum) and its half-width, per varied factor.
+// Fixed: Levels of the other optimizable factors, held at their screening optimum.
+// Runs: Full control configuration of every run, including the fixed factors, in design order.
+type FollowUp struct {
+	Kind    FollowUpKind
+	Factors []ControlFactor
+	Center  map[string]float64
+	Step    map[string]float64
+	Fixed   map[string]float64
+	Runs    []map[string]float64
+}
+
+// Array returns the runs as a one-based level array over Factors, so the
+// follow-up can be run as an experiment of its own with
+// NewExperimentFromFactorsUsingArray. A central composite design is not
+// orthogonal and needs ArrayOptions{AllowNonOrthogonal: true}; the fixed
+// factors must be applied separately.
+func (f FollowUp) Array() [][]int {
+	oa := make([][]int, len(f.Runs))
+	for i, run := range f.Runs {
+		oa[i] = make([]int, len(f.Factors))
+		for j, factor := range f.Factors {
+			oa[i][j] = levelPosition(factor, run[factor.Name]) + 1
+		}
+	}
+	return oa
+}
+
+// GenerateFollowUp builds a design for the second, optimization stage of a
+// screening-then-optimization workflow. It analyzes the experiment and varies
+// the factors that matter: the optimizable factors significant at Alpha or,
+// if none is, the two with the largest contributions. Each varied factor is
+// explored around its optimal level, with a step of half the distance to the
+// nearest explored level, so a boundary optimum is followed outside the
+// screened range. All other factors are held at their optimal levels.
+// Factor values are continuous; round them for discrete factors.
+func (e *Experiment[P]) GenerateFollowUp(kind FollowUpKind) (FollowUp, error) {
+	if kind != FollowUpFullFactorial && kind != FollowUpCentralComposite {
+		return FollowUp{}, fmt.Errorf("unknown follow-up design %d", kind)
+	}
+	result, err := e.analyze()
+	if err != nil {
+		return FollowUp{}, err
+	}
+
+	var candidates []ControlFactor
+	for _, factor := range e.ControlFactors {
+		if !factor.ObserveOnly {
+			candidates = append(candidates, factor)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return result.Contributions[candidates[i].Name] > result.Contributions[candidates[j].Name]
+	})
+	var selected []ControlFactor
+	for _, factor := range candidates {
+		if result.ANOVA.Significant[factor.Name] {
+			selected = append(selected, factor)
+		}
+	}
+	if len(selected) == 0 {
+		selected = candidates[:min(2, len(candidates))]
+	}
+	if len(selected) == 0 {
+		return FollowUp{}, fmt.Errorf("no optimizable factors to follow up")
+	}
+
+	f := FollowUp{
+		Kind:   kind,
+		Center: map[string]float64{},
+		Step:   map[string]float64{},
+		Fixed:  map[string]float64{},
+	}
+	varied := map[string]bool{}
+	for _, factor := range selected {
+		varied[factor.Name] = true
+		opt := result.OptimalLevels[factor.Name]
+		f.Center[factor.Name] = opt
+		f.Step[factor.Name] = nearestGap(factor.Levels, opt) / 2
+	}
+	for _, factor := range candidates {
+		if !varied[factor.Name] {
+			f.Fixed[factor.Name] = result.OptimalLevels[factor.Name]
+		}
+	}
+
+	var points [][]float64
+	if kind == FollowUpFullFactorial {
+		points = fullFactorialPoints(len(selected))
+	} else {
+		points = centralCompositePoints(len(selected))
+	}
+	values := make([][]float64, len(selected))
+	for _, p := range points {
+		run := make(map[string]float64, len(selected)+len(f.Fixed))
+		for name, level := range f.Fixed {
+			run[name] = level
+		}
+		for j, factor := range selected {
+			v := f.Center[factor.Name] + p[j]*f.Step[factor.Name]
+			run[factor.Name] = v
+			values[j] = append(values[j], v)
+		}
+		f.Runs = append(f.Runs, run)
+	}
+	for j, factor := range selected {
+		slices.Sort(values[j])
+		f.Factors = append(f.Factors, ControlFactor{
+			Name:       factor.Name,
+			Levels:     slices.Compact(values[j]),
+			Group:      factor.Group,
+			ChangeCost: factor.ChangeCost,
+			Apply:      factor.Apply,
+		})
+	}
+	return f, nil
+}
+
+// nearestGap returns the distance from level to the nearest other level.
+func nearestGap(levels []float64, level float64) float64 {
+	gap := math.Inf(1)
+	for _, l := range levels {
+		if l != level {
+			gap = math.Min(gap, math.Abs(l-level))
+		}
+	}
+	return gap
+}
+
+// fullFactorialPoints returns the 3^k points of {-1, 0, 1}^k in coded units.
+func fullFactorialPoints(k int) [][]float64 {
+	points := [][]float64{{}}
+	for i := 0; i < k; i++ {
+		var next [][]float64
+		for _, p := range points {
+			for _, v := range []float64{-1, 0, 1} {
+				next = append(next, append(slices.Clone(p), v))
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+// centralCompositePoints returns the corner, axial and center points of a
+// rotatable central composite design in coded units.
+func centralCompositePoints(k int) [][]float64 {
+	var points [][]float64
+	for corner := 0; corner < 1<<k; corner++ {
+		p := make([]float64, k)
+		for j := range p {
+			p[j] = -1
+			if corner&(1<<j) != 0 {
+				p[j] = 1
+			}
+		}
+		points = append(points, p)
+	}
+	alpha := math.Pow(2, float64(k)/4)
+	for j := 0; j < k; j++ {
+		for _, sign := range []float64{-1, 1} {
+			p := make([]float64, k)
+			p[j] = sign * alpha
+			points = append(points, p)
+		}
+	}
+	for i := 0; i < FollowUpCenterRuns; i++ {
+		points = append(points, make([]float64, k))
+	}
+	return points
+}
@@ -0,0 +1,64 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGenerateFollowUp(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{10, 20, 30}},
+		{Name: "B", Levels: []float64{1, 2, 4}},
+		{Name: "C", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	// A and B matter, with optima at A=10 (the lower edge) and B=2; C barely does.
+	jitter := []float64{0.1, -0.2, 0.05, 0.15, -0.1, 0.0, -0.05, 0.2, -0.15}
+	for _, trial := range exp.GenerateTrials() {
+		y := 10 + trial.Control["A"]/2 + 8*math.Abs(trial.Control["B"]-2) + 0.01*trial.Control["C"] + jitter[trial.Row]
+		exp.AddResult(trial, []float64{y})
+	}
+
+	f, err := exp.GenerateFollowUp(FollowUpCentralComposite)
+	if err != nil {
+		t.Fatalf("GenerateFollowUp: %v", err)
+	}
+	if len(f.Factors) != 2 || f.Factors[0].Name != "B" || f.Factors[1].Name != "A" {
+		t.Fatalf("varied factors %v, want B and A by contribution", f.Factors)
+	}
+	if f.Center["A"] != 10 || f.Step["A"] != 5 || f.Center["B"] != 2 || f.Step["B"] != 0.5 {
+		t.Errorf("Center %v, Step %v; want A around 10 by 5 and B around 2 by 0.5", f.Center, f.Step)
+	}
+	if f.Fixed["C"] == 0 || len(f.Fixed) != 1 {
+		t.Errorf("Fixed = %v, want C held at its optimum", f.Fixed)
+	}
+	// 4 corners, 4 axial runs and the center runs.
+	if want := 4 + 4 + FollowUpCenterRuns; len(f.Runs) != want {
+		t.Errorf("len(Runs) = %d, want %d", len(f.Runs), want)
+	}
+	if got := f.Runs[4]["B"]; !almostEqual(got, 2-math.Sqrt2*0.5) {
+		t.Errorf("first axial run B = %g, want 2 - √2·0.5", got)
+	}
+	if low := f.Factors[1].Levels[0]; low >= 10 {
+		t.Errorf("lowest A level %g, want the follow-up to extend below the boundary optimum", low)
+	}
+
+	oa := f.Array()
+	if _, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, f.Factors, oa, nil, ArrayOptions{AllowNonOrthogonal: true}); err != nil {
+		t.Errorf("follow-up array is not usable as an experiment: %v", err)
+	}
+
+	full, err := exp.GenerateFollowUp(FollowUpFullFactorial)
+	if err != nil {
+		t.Fatalf("GenerateFollowUp: %v", err)
+	}
+	if len(full.Runs) != 9 || len(full.Factors[0].Levels) != 3 {
+		t.Errorf("full factorial: %d runs with levels %v, want 9 runs on 3 levels", len(full.Runs), full.Factors[0].Levels)
+	}
+	if err := ValidateArray(full.Array()); err != nil {
+		t.Errorf("full factorial array is not orthogonal: %v", err)
+	}
+}
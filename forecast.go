@@ -0,0 +1,317 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+)
+
+// NoiseDistribution is the expected distribution of a noise factor in a
+// forecast scenario, e.g. "CPU load will average 0.5 next quarter".
+// Mean: Expected level of the noise factor.
+// SD: Standard deviation of the level around Mean; zero for a fixed level.
+type NoiseDistribution struct {
+	Mean float64
+	SD   float64
+}
+
+// Forecast is the predicted performance of a configuration under a noise
+// scenario, from the control-by-noise response model (see Experiment.Forecast).
+// Levels: The configuration the forecast is for.
+// Scenario: Noise distribution used for every noise factor, including the defaults for those the scenario left out.
+// Mean: Expected response.
+// SD: Standard deviation of the response: the noise spread carried through the noise slopes plus the residual variation.
+// SNR: SNR of the experiment's goal for a normally distributed response with Mean and SD.
+// Slopes: Change in the response per unit of each noise factor at this configuration.
+// ResidualSD: Standard deviation of the observations around the model; zero when the model has no residual degrees of freedom.
+// ResidualDF: Residual degrees of freedom of the model.
+type Forecast struct {
+	Levels     map[string]float64
+	Scenario   map[string]NoiseDistribution
+	Mean       float64
+	SD         float64
+	SNR        float64
+	Slopes     map[string]float64
+	ResidualSD float64
+	ResidualDF int
+}
+
+// forecastQuantiles is the number of normal quantiles on which the SNR of a
+// forecast is evaluated.
+const forecastQuantiles = 200
+
+// ForecastOptimal forecasts the optimal levels found by Analyze under the
+// scenario (see Forecast).
+func (e *Experiment[P]) ForecastOptimal(scenario map[string]NoiseDistribution) (Forecast, error) {
+	result, err := e.analyze()
+	if err != nil {
+		return Forecast{}, err
+	}
+	return e.Forecast(result.OptimalLevels, scenario)
+}
+
+// Forecast predicts the response of a configuration under a future noise
+// distribution, for capacity-planning questions answered from the same
+// experiment data. It fits a response model to every observation: the main
+// effects of the control factors, a linear slope for each noise factor, and
+// the change of every noise slope with each control factor's level. The
+// forecast mean and spread follow from the configuration's noise slopes and
+// the scenario; noise factors are taken to vary independently.
+//
+// levels maps each optimizable control factor to one of its levels;
+// observe-only factors are averaged over. Noise factors missing from the
+// scenario keep the distribution of the experiment's own noise conditions:
+// the mean and standard deviation of their levels, weighted by the factor's
+// Weights. An empty scenario thus forecasts performance under the tested
+// conditions.
+//
+// The noise slopes are linear, so forecasts for noise levels outside the
+// tested range are extrapolations. Goals whose observations are pairs
+// (OperatingWindow and Dynamic) are not supported.
+func (e *Experiment[P]) Forecast(levels map[string]float64, scenario map[string]NoiseDistribution) (Forecast, error) {
+	if pairedGoal(e.Goal) {
+		return Forecast{}, fmt.Errorf("forecasts are not supported for %s experiments", e.Goal)
+	}
+	if len(e.Results) == 0 {
+		return Forecast{}, errorf(ErrMissingResults, "no results recorded")
+	}
+	if len(e.NoiseFactors) == 0 {
+		return Forecast{}, fmt.Errorf("forecasts need noise factors")
+	}
+	for name := range scenario {
+		if noiseIndex(e.NoiseFactors, name) < 0 {
+			return Forecast{}, errorf(ErrUnknownFactor, "unknown noise factor %s", name)
+		}
+	}
+	m, err := e.fitNoiseModel()
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	f := Forecast{
+		Levels:     map[string]float64{},
+		Scenario:   map[string]NoiseDistribution{},
+		Slopes:     map[string]float64{},
+		ResidualDF: m.residualDF,
+	}
+	if m.residualDF > 0 {
+		f.ResidualSD = math.Sqrt(m.rss / float64(m.residualDF))
+	}
+	x := make([]float64, len(m.coef))
+	x[0] = 1
+	for j, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
+		level, ok := levels[factor.Name]
+		if !ok {
+			return Forecast{}, fmt.Errorf("no level given for factor %s", factor.Name)
+		}
+		li := levelPosition(factor, level)
+		if li < 0 {
+			return Forecast{}, errorf(ErrLevelMismatch, "factor %s has no level %g", factor.Name, level)
+		}
+		f.Levels[factor.Name] = level
+		m.setLevel(x, j, li)
+	}
+
+	f.Mean = dot(x, m.coef)
+	variance := f.ResidualSD * f.ResidualSD
+	for n, nf := range e.NoiseFactors {
+		d, ok := scenario[nf.Name]
+		if !ok {
+			d = levelDistribution(nf)
+		}
+		f.Scenario[nf.Name] = d
+		slope := m.coef[m.noiseCol(n)]
+		for j, factor := range e.ControlFactors {
+			if factor.ObserveOnly {
+				continue
+			}
+			for k := 0; k < m.cols[j]; k++ {
+				slope += x[m.start[j]+k] * m.coef[m.interactionCol(j, n)+k]
+			}
+		}
+		slope /= m.halfRange[n]
+		f.Slopes[nf.Name] = slope
+		f.Mean += slope * (d.Mean - m.center[n])
+		variance += slope * slope * d.SD * d.SD
+	}
+	f.SD = math.Sqrt(variance)
+	f.SNR = e.Goal.CalculateSNR(normalQuantiles(f.Mean, f.SD, forecastQuantiles))
+	return f, nil
+}
+
+// noiseModel is the least-squares fit behind Forecast. Its columns are the
+// intercept, the effect-coded control factors, the coded noise factors and
+// the products of both; observe-only factors have control columns only.
+type noiseModel struct {
+	cols       []int // control columns per factor: levels - 1
+	start      []int // first control column per factor
+	noiseStart int
+	interStart []int // first interaction column per factor, -1 for observe-only
+	noise      int
+	center     []float64 // noise level coded as 0
+	halfRange  []float64 // noise distance coded as 1
+	coef       []float64
+	rss        float64
+	residualDF int
+}
+
+func (m *noiseModel) noiseCol(n int) int { return m.noiseStart + n }
+
+func (m *noiseModel) interactionCol(j, n int) int { return m.interStart[j] + n*m.cols[j] }
+
+// setLevel writes the effect coding of level li of factor j into x: 1 in the
+// level's column, -1 in every column for the last level.
+func (m *noiseModel) setLevel(x []float64, j, li int) {
+	for k := 0; k < m.cols[j]; k++ {
+		switch {
+		case k == li:
+			x[m.start[j]+k] = 1
+		case li == m.cols[j]:
+			x[m.start[j]+k] = -1
+		default:
+			x[m.start[j]+k] = 0
+		}
+	}
+}
+
+// fitNoiseModel fits the control-by-noise response model to every
+// observation. Results whose control levels are not levels of their factors,
+// such as interpolated follow-up runs, are left out. Results that keep only
+// a summary of their observations contribute their mean.
+func (e *Experiment[P]) fitNoiseModel() (noiseModel, error) {
+	m := noiseModel{noise: len(e.NoiseFactors)}
+	p := 1
+	for _, factor := range e.ControlFactors {
+		m.start = append(m.start, p)
+		m.cols = append(m.cols, len(factor.Levels)-1)
+		p += len(factor.Levels) - 1
+	}
+	m.noiseStart = p
+	p += m.noise
+	for j, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			m.interStart = append(m.interStart, -1)
+			continue
+		}
+		m.interStart = append(m.interStart, p)
+		p += m.noise * m.cols[j]
+	}
+	for _, nf := range e.NoiseFactors {
+		lo, hi := nf.Levels[0], nf.Levels[0]
+		for _, v := range nf.Levels {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		half := (hi - lo) / 2
+		if half == 0 {
+			half = 1
+		}
+		m.center = append(m.center, (lo+hi)/2)
+		m.halfRange = append(m.halfRange, half)
+	}
+
+	var xs [][]float64
+	var ys []float64
+	for _, r := range e.Results {
+		x := make([]float64, p)
+		x[0] = 1
+		valid := true
+		for j, factor := range e.ControlFactors {
+			li := levelPosition(factor, r.Trial.Control[factor.Name])
+			if li < 0 {
+				valid = false
+				break
+			}
+			m.setLevel(x, j, li)
+		}
+		if !valid {
+			continue
+		}
+		for n, nf := range e.NoiseFactors {
+			z := (r.Trial.Noise[nf.Name] - m.center[n]) / m.halfRange[n]
+			x[m.noiseCol(n)] = z
+			for j, factor := range e.ControlFactors {
+				if factor.ObserveOnly {
+					continue
+				}
+				for k := 0; k < m.cols[j]; k++ {
+					x[m.interactionCol(j, n)+k] = x[m.start[j]+k] * z
+				}
+			}
+		}
+		obs := r.Observations
+		if len(obs) < r.Summary.Count {
+			obs = []float64{r.Summary.Sum / float64(r.Summary.Count)}
+		}
+		for _, y := range obs {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	if len(ys) < p {
+		return noiseModel{}, fmt.Errorf("the control-by-noise model has %d terms but only %d observations", p, len(ys))
+	}
+	coef, rss, err := leastSquares(xs, ys)
+	if err != nil {
+		return noiseModel{}, fmt.Errorf("the control-by-noise model is not estimable from the recorded results: %w", err)
+	}
+	m.coef, m.rss, m.residualDF = coef, rss, len(ys)-p
+	return m, nil
+}
+
+// noiseIndex returns the index of the named noise factor, or -1.
+func noiseIndex(factors []NoiseFactor, name string) int {
+	for i, f := range factors {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func meanOf(values []float64) float64 {
+	s := 0.0
+	for _, v := range values {
+		s += v
+	}
+	return s / float64(len(values))
+}
+
+// sdOf returns the population standard deviation of values.
+func sdOf(values []float64) float64 {
+	mean, ss := meanOf(values), 0.0
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(ss / float64(len(values)))
+}
+
+// levelDistribution returns the mean and standard deviation of a noise
+// factor's levels, weighted by its Weights when it has them.
+func levelDistribution(nf NoiseFactor) NoiseDistribution {
+	if nf.Weights == nil {
+		return NoiseDistribution{Mean: meanOf(nf.Levels), SD: sdOf(nf.Levels)}
+	}
+	var total, mean, ss float64
+	for i, v := range nf.Levels {
+		total += nf.Weights[i]
+		mean += nf.Weights[i] * v
+	}
+	mean /= total
+	for i, v := range nf.Levels {
+		ss += nf.Weights[i] * (v - mean) * (v - mean)
+	}
+	return NoiseDistribution{Mean: mean, SD: math.Sqrt(ss / total)}
+}
+
+// normalQuantiles returns n evenly spaced quantiles of a normal distribution,
+// a deterministic stand-in for a sample of it.
+func normalQuantiles(mean, sd float64, n int) []float64 {
+	q := make([]float64, n)
+	for i := range q {
+		p := (float64(i) + 0.5) / float64(n)
+		q[i] = mean + sd*math.Sqrt2*math.Erfinv(2*p-1)
+	}
+	return q
+}
@@ -0,0 +1,78 @@
+package taguchi
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// forecastDesign is an L4 experiment whose response follows
+// y = 10 + 2A + B + s·Load, with a Load slope s of 4 at A=1 and 1 at A=2.
+var forecastDesign = testDesign{
+	Noise: []NoiseFactor{{Name: "Load", Levels: []float64{0, 1}}},
+	Response: singleResult(func(trial Trial) []float64 {
+		a, b, load := trial.Control["A"], trial.Control["B"], trial.Noise["Load"]
+		slope := 4.0
+		if a == 2 {
+			slope = 1
+		}
+		return []float64{10 + 2*a + b + slope*load}
+	}),
+}
+
+func TestForecast_ShiftedNoise(t *testing.T) {
+	exp := newTestExperiment(t, forecastDesign)
+	scenario := map[string]NoiseDistribution{"Load": {Mean: 0.5, SD: 0.1}}
+
+	robust, err := exp.Forecast(map[string]float64{"A": 2, "B": 1}, scenario)
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	sensitive, err := exp.Forecast(map[string]float64{"A": 1, "B": 1}, scenario)
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	for _, c := range []struct {
+		name            string
+		f               Forecast
+		mean, sd, slope float64
+	}{
+		{"A=2", robust, 15.5, 0.1, 1},
+		{"A=1", sensitive, 15, 0.4, 4},
+	} {
+		if !almostEqual(c.f.Mean, c.mean) || !almostEqual(c.f.SD, c.sd) || !almostEqual(c.f.Slopes["Load"], c.slope) {
+			t.Errorf("%s: mean %g, SD %g, slope %g; want %g, %g, %g", c.name, c.f.Mean, c.f.SD, c.f.Slopes["Load"], c.mean, c.sd, c.slope)
+		}
+		want := -10 * math.Log10(c.mean*c.mean+c.sd*c.sd)
+		if math.Abs(c.f.SNR-want) > 0.01 {
+			t.Errorf("%s: SNR %g, want %g", c.name, c.f.SNR, want)
+		}
+	}
+	if robust.ResidualDF != 2 || robust.ResidualSD > 1e-9 {
+		t.Errorf("residual DF %d, SD %g; want 2 and 0 for an exact model", robust.ResidualDF, robust.ResidualSD)
+	}
+
+	// Without a scenario, Load keeps the spread of its tested levels.
+	tested, err := exp.Forecast(map[string]float64{"A": 1, "B": 1}, nil)
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	if d := tested.Scenario["Load"]; d.Mean != 0.5 || d.SD != 0.5 || !almostEqual(tested.SD, 2) {
+		t.Errorf("default scenario %+v with SD %g, want Load 0.5±0.5 and SD 2", d, tested.SD)
+	}
+}
+
+func TestForecast_Errors(t *testing.T) {
+	exp := newTestExperiment(t, forecastDesign)
+	levels := map[string]float64{"A": 1, "B": 1}
+	if _, err := exp.Forecast(levels, map[string]NoiseDistribution{"Heat": {Mean: 1}}); !errors.Is(err, ErrUnknownFactor) {
+		t.Errorf("unknown noise factor: err = %v, want ErrUnknownFactor", err)
+	}
+	if _, err := exp.Forecast(map[string]float64{"A": 3, "B": 1}, nil); !errors.Is(err, ErrLevelMismatch) {
+		t.Errorf("unknown level: err = %v, want ErrLevelMismatch", err)
+	}
+	exp.Goal = Dynamic{}
+	if _, err := exp.Forecast(levels, nil); err == nil {
+		t.Error("Forecast of a dynamic experiment succeeded")
+	}
+}
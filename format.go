@@ -0,0 +1,146 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Locale describes the separators used when rendering numbers in reports.
+// Decimal: Separator between the integer and fractional part (e.g. "." or ",").
+// Thousands: Separator inserted between groups of three integer digits; empty disables grouping.
+type Locale struct {
+	Decimal   string
+	Thousands string
+}
+
+// Predefined locales for common report audiences.
+var (
+	LocalePlain   = Locale{Decimal: "."}
+	LocaleEnglish = Locale{Decimal: ".", Thousands: ","}
+	LocaleGerman  = Locale{Decimal: ",", Thousands: "."}
+	LocaleFrench  = Locale{Decimal: ",", Thousands: " "}
+	LocaleSwiss   = Locale{Decimal: ".", Thousands: "'"}
+)
+
+// ParseLocale returns the predefined locale of the given name: plain,
+// english, german, french or swiss, in any case.
+func ParseLocale(name string) (Locale, error) {
+	switch strings.ToLower(name) {
+	case "plain":
+		return LocalePlain, nil
+	case "english":
+		return LocaleEnglish, nil
+	case "german":
+		return LocaleGerman, nil
+	case "french":
+		return LocaleFrench, nil
+	case "swiss":
+		return LocaleSwiss, nil
+	}
+	return Locale{}, fmt.Errorf("unknown locale %q: want plain, english, german, french or swiss", name)
+}
+
+// NumberFormat controls how floating-point values are rendered in reports.
+// Decimals: Fixed number of digits after the decimal separator.
+// SignificantDigits: When positive, values are rounded to this many significant digits instead of using Decimals.
+// Locale: Decimal and thousands separators.
+type NumberFormat struct {
+	Decimals          int
+	SignificantDigits int
+	Locale            Locale
+}
+
+// DefaultNumberFormat is the format used by PrintAnalysisReport: four decimals, no grouping.
+var DefaultNumberFormat = NumberFormat{Decimals: 4, Locale: LocalePlain}
+
+// Format renders v according to the number format.
+func (f NumberFormat) Format(v float64) string {
+	if s, ok := formatNonFinite(v); ok {
+		return s
+	}
+	decimals := f.Decimals
+	if f.SignificantDigits > 0 {
+		v, decimals = roundSignificant(v, f.SignificantDigits)
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	return f.Locale.apply(strconv.FormatFloat(v, 'f', decimals, 64))
+}
+
+// FormatLevel renders a factor level using the shortest exact representation,
+// so that levels such as 4 or 0.25 are not padded with trailing zeros.
+func (f NumberFormat) FormatLevel(v float64) string {
+	if s, ok := formatNonFinite(v); ok {
+		return s
+	}
+	return f.Locale.apply(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+// FormatPercent renders v followed by a percent sign.
+func (f NumberFormat) FormatPercent(v float64) string {
+	return f.Format(v) + "%"
+}
+
+// roundSignificant rounds v to the given number of significant digits and
+// returns the number of decimals required to display the rounded value.
+func roundSignificant(v float64, digits int) (float64, int) {
+	if v == 0 {
+		return 0, digits - 1
+	}
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'e', digits-1, 64), 64)
+	if err != nil {
+		rounded = v
+	}
+	magnitude := int(math.Floor(math.Log10(math.Abs(rounded))))
+	return rounded, digits - 1 - magnitude
+}
+
+// apply rewrites a plain strconv-formatted number using the locale separators.
+func (l Locale) apply(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+
+	if l.Thousands != "" && len(intPart) > 3 {
+		var b strings.Builder
+		lead := len(intPart) % 3
+		if lead > 0 {
+			b.WriteString(intPart[:lead])
+		}
+		for i := lead; i < len(intPart); i += 3 {
+			if b.Len() > 0 {
+				b.WriteString(l.Thousands)
+			}
+			b.WriteString(intPart[i : i+3])
+		}
+		intPart = b.String()
+	}
+
+	if !hasFrac {
+		return sign + intPart
+	}
+	decimal := l.Decimal
+	if decimal == "" {
+		decimal = "."
+	}
+	return sign + intPart + decimal + fracPart
+}
+
+// formatNonFinite renders infinities and NaN, which strconv would otherwise
+// format without the leading sign used elsewhere in reports.
+func formatNonFinite(v float64) (string, bool) {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf", true
+	case math.IsInf(v, -1):
+		return "-Inf", true
+	case math.IsNaN(v):
+		return "NaN", true
+	}
+	return "", false
+}
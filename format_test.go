@@ -0,0 +1,44 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+// TestNumberFormat_Format verifies fixed decimals, significant digits and
+// locale separators, including values that need thousands grouping.
+func TestNumberFormat_Format(t *testing.T) {
+	tests := []struct {
+		name   string
+		format NumberFormat
+		value  float64
+		want   string
+	}{
+		{"default", DefaultNumberFormat, -14.77121, "-14.7712"},
+		{"english grouping", NumberFormat{Decimals: 2, Locale: LocaleEnglish}, 1234567.891, "1,234,567.89"},
+		{"german grouping", NumberFormat{Decimals: 1, Locale: LocaleGerman}, -9876.54, "-9.876,5"},
+		{"significant small", NumberFormat{SignificantDigits: 3, Locale: LocalePlain}, 0.0123456, "0.0123"},
+		{"significant large", NumberFormat{SignificantDigits: 2, Locale: LocaleEnglish}, 123456, "120,000"},
+		{"significant rounding up", NumberFormat{SignificantDigits: 2, Locale: LocalePlain}, 9.96, "10"},
+		{"infinity", DefaultNumberFormat, math.Inf(1), "+Inf"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.Format(tt.value); got != tt.want {
+			t.Errorf("%s: Format(%v) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+
+	if got := (NumberFormat{Locale: LocaleGerman}).FormatLevel(0.25); got != "0,25" {
+		t.Errorf("FormatLevel(0.25) = %q, want %q", got, "0,25")
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	if l, err := ParseLocale("German"); err != nil || l != LocaleGerman {
+		t.Errorf("ParseLocale(German) = %+v, %v; want LocaleGerman", l, err)
+	}
+	if _, err := ParseLocale("klingon"); err == nil {
+		t.Error("ParseLocale(klingon): want error")
+	}
+}
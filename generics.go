@@ -2,12 +2,18 @@ package taguchi
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // factorsFrom extracts a []Factor from the exported []float64 fields of a
 // struct value. Each field becomes a factor with Name = field name and
-// Levels = the slice value.
+// Levels = the slice value. An optional `group:"..."` struct tag sets the
+// factor's Group, `observe:"true"` marks it ObserveOnly and `continuous:"true"`
+// marks it Continuous.
 func factorsFrom[T any](v T) ([]ControlFactor, error) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Struct {
@@ -16,6 +22,7 @@ func factorsFrom[T any](v T) ([]ControlFactor, error) {
 	t := rv.Type()
 
 	var factors []ControlFactor
+	var err error
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		if !field.IsExported() {
@@ -28,7 +35,19 @@ func factorsFrom[T any](v T) ([]ControlFactor, error) {
 		if len(levels) < 2 {
 			return nil, fmt.Errorf("field %s: at least 2 levels required, got %d", field.Name, len(levels))
 		}
-		factors = append(factors, ControlFactor{Name: field.Name, Levels: levels})
+		observeOnly := false
+		if tag, ok := field.Tag.Lookup("observe"); ok {
+			if observeOnly, err = strconv.ParseBool(tag); err != nil {
+				return nil, fmt.Errorf("field %s: invalid observe tag %q", field.Name, tag)
+			}
+		}
+		continuous := false
+		if tag, ok := field.Tag.Lookup("continuous"); ok {
+			if continuous, err = strconv.ParseBool(tag); err != nil {
+				return nil, fmt.Errorf("field %s: invalid continuous tag %q", field.Name, tag)
+			}
+		}
+		factors = append(factors, ControlFactor{Name: field.Name, Levels: levels, Group: field.Tag.Get("group"), ObserveOnly: observeOnly, Continuous: continuous})
 	}
 
 	if len(factors) == 0 {
@@ -37,41 +56,144 @@ func factorsFrom[T any](v T) ([]ControlFactor, error) {
 	return factors, nil
 }
 
-// buildControlAs pre-computes field indices for type P and returns a closure
-// that converts a Trial's Control map into a value of P.
-func buildControlAs[P any]() func(Trial) P {
+// durationUnits are the unit suffixes accepted in `taguchi:"Name,unit"` tags
+// on time.Duration fields.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// paramField describes how one field of P is populated from a trial.
+// factor: Control or noise factor name the field is bound to.
+// unit: Scale of a time.Duration field (levels are multiplied by it).
+type paramField struct {
+	index  int
+	factor string
+	unit   time.Duration
+}
+
+// buildControlAs pre-computes the field bindings of type P and returns a
+// closure that converts a Trial into a value of P. A field is bound to the
+// factor named by its `taguchi:"Name"` tag, or to the factor with the field's
+// name; `taguchi:"-"` skips the field. Supported field types are floats,
+// integers, bool, string and time.Duration (`taguchi:"Name,ms"` sets the unit
+// of the levels, nanoseconds by default). Untagged fields of other types are
+// ignored. Tags naming unknown factors, untagged scalar fields without a
+// matching factor and tags on unsupported types are reported as errors.
+func buildControlAs[P any](control []ControlFactor, noise []NoiseFactor) (func(Trial) (P, error), error) {
 	var zero P
 	t := reflect.TypeOf(zero)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-	if t.Kind() != reflect.Struct {
-		return func(trial Trial) P {
-			return zero
-		}
+	if t == nil || t.Kind() != reflect.Struct {
+		return func(trial Trial) (P, error) {
+			return zero, nil
+		}, nil
 	}
 
-	type fieldInfo struct {
-		index int
-		name  string
+	known := map[string]bool{}
+	for _, f := range control {
+		known[f.Name] = true
+	}
+	for _, f := range noise {
+		known[f.Name] = true
 	}
-	var fields []fieldInfo
+
+	var fields []paramField
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		if !field.IsExported() || field.Type.Kind() != reflect.Float64 {
+		if !field.IsExported() {
 			continue
 		}
-		fields = append(fields, fieldInfo{index: i, name: field.Name})
+		tag, tagged := field.Tag.Lookup("taguchi")
+		if tag == "-" {
+			continue
+		}
+		if !supportedParamType(field.Type) {
+			if tagged {
+				return nil, fmt.Errorf("field %s: unsupported type %s for factor binding", field.Name, field.Type)
+			}
+			continue
+		}
+		pf := paramField{index: i, factor: field.Name, unit: time.Nanosecond}
+		if tagged {
+			name, unit, hasUnit := strings.Cut(tag, ",")
+			if name != "" {
+				pf.factor = name
+			}
+			if hasUnit {
+				if field.Type != reflect.TypeOf(time.Duration(0)) {
+					return nil, fmt.Errorf("field %s: unit %q is only valid on time.Duration fields", field.Name, unit)
+				}
+				if pf.unit = durationUnits[unit]; pf.unit == 0 {
+					return nil, fmt.Errorf("field %s: unknown duration unit %q", field.Name, unit)
+				}
+			}
+		}
+		if !known[pf.factor] {
+			return nil, errorf(ErrUnknownFactor, "field %s: no factor named %s", field.Name, pf.factor)
+		}
+		fields = append(fields, pf)
 	}
 
-	return func(trial Trial) P {
+	return func(trial Trial) (P, error) {
 		var result P
 		v := reflect.ValueOf(&result).Elem()
 		for _, f := range fields {
-			if val, ok := trial.Control[f.name]; ok {
-				v.Field(f.index).SetFloat(val)
+			level, ok := trial.Control[f.factor]
+			if !ok {
+				level, ok = trial.Noise[f.factor]
+			}
+			if !ok {
+				return zero, fmt.Errorf("trial %d has no value for factor %s", trial.ID, f.factor)
 			}
+			if err := setParamField(v.Field(f.index), level, f.unit); err != nil {
+				return zero, fmt.Errorf("factor %s: %w", f.factor, err)
+			}
+		}
+		return result, nil
+	}, nil
+}
+
+// supportedParamType reports whether a level can be converted to type t.
+func supportedParamType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// setParamField converts a factor level to the field's type and stores it.
+func setParamField(v reflect.Value, level float64, unit time.Duration) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		v.SetInt(int64(level * float64(unit)))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(level)
+	case reflect.Bool:
+		if level != 0 && level != 1 {
+			return fmt.Errorf("level %g is not a boolean (0 or 1)", level)
+		}
+		v.SetBool(level == 1)
+	case reflect.String:
+		v.SetString(strconv.FormatFloat(level, 'g', -1, 64))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if level != math.Trunc(level) || v.OverflowInt(int64(level)) {
+			return fmt.Errorf("level %g does not fit %s", level, v.Type())
+		}
+		v.SetInt(int64(level))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if level < 0 || level != math.Trunc(level) || v.OverflowUint(uint64(level)) {
+			return fmt.Errorf("level %g does not fit %s", level, v.Type())
 		}
-		return result
+		v.SetUint(uint64(level))
 	}
+	return nil
 }
@@ -0,0 +1,74 @@
+package taguchi
+
+import (
+	"testing"
+	"time"
+)
+
+type bindingFactors struct {
+	Workers []float64
+	Mode    []float64
+	Timeout []float64
+	Cache   []float64
+}
+
+type bindingParams struct {
+	Workers int
+	Mode    string        `taguchi:"Mode"`
+	Timeout time.Duration `taguchi:"Timeout,ms"`
+	Enabled bool          `taguchi:"Cache"`
+	Load    float64       `taguchi:"Load"`
+	Note    string        `taguchi:"-"`
+}
+
+func TestParams_TypedBinding(t *testing.T) {
+	factors := bindingFactors{
+		Workers: []float64{4, 8},
+		Mode:    []float64{1, 2},
+		Timeout: []float64{50, 250},
+		Cache:   []float64{0, 1},
+	}
+	noise := []NoiseFactor{{Name: "Load", Levels: []float64{0.5}}}
+	exp, err := NewExperiment[bindingFactors, bindingParams](SmallerTheBetter{}, factors, L8, noise)
+	if err != nil {
+		t.Fatalf("NewExperiment: %v", err)
+	}
+
+	trial := exp.GenerateTrials()[len(exp.GenerateTrials())-1]
+	p, err := exp.Params(trial)
+	if err != nil {
+		t.Fatalf("Params: %v", err)
+	}
+	if p.Workers != int(trial.Control["Workers"]) {
+		t.Errorf("Workers = %d, want %v", p.Workers, trial.Control["Workers"])
+	}
+	if want := time.Duration(trial.Control["Timeout"]) * time.Millisecond; p.Timeout != want {
+		t.Errorf("Timeout = %v, want %v", p.Timeout, want)
+	}
+	if p.Enabled != (trial.Control["Cache"] == 1) {
+		t.Errorf("Enabled = %v, want Cache = %v", p.Enabled, trial.Control["Cache"])
+	}
+	if p.Mode == "" || p.Load != 0.5 {
+		t.Errorf("Mode = %q, Load = %v; want a mode and Load 0.5", p.Mode, p.Load)
+	}
+
+	delete(trial.Control, "Workers")
+	if _, err := exp.Params(trial); err == nil {
+		t.Error("expected error for a trial without a bound factor")
+	}
+}
+
+func TestParams_UnmappedField(t *testing.T) {
+	type params struct {
+		Workers int `taguchi:"Threads"`
+	}
+	factors := bindingFactors{
+		Workers: []float64{4, 8},
+		Mode:    []float64{1, 2},
+		Timeout: []float64{50, 250},
+		Cache:   []float64{0, 1},
+	}
+	if _, err := NewExperiment[bindingFactors, params](SmallerTheBetter{}, factors, L8, nil); err == nil {
+		t.Error("expected error for a field bound to an unknown factor")
+	}
+}
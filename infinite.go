@@ -0,0 +1,70 @@
+package taguchi
+
+import "math"
+
+// InfiniteSNRPolicy selects how rows with an infinite SNR (a perfect row with
+// zero mean squared deviation) are treated. Without a policy a single such row
+// turns the grand mean, sums of squares and F-ratios into Inf/NaN.
+type InfiniteSNRPolicy int
+
+const (
+	// CapInfiniteSNR replaces ±Inf row SNRs with ±SNRCeiling (the default).
+	CapInfiniteSNR InfiniteSNRPolicy = iota
+	// ExcludeInfiniteSNR drops rows with an infinite SNR from the grand mean,
+	// main effects and ANOVA, and reports them as warnings.
+	ExcludeInfiniteSNR
+)
+
+// DefaultSNRCeiling is the ceiling in dB used by CapInfiniteSNR when
+// Experiment.SNRCeiling is not set.
+const DefaultSNRCeiling = 100.0
+
+// oaRowSNR holds the per-row SNR values fed into ANOVA.
+// values: SNR per orthogonal array row after applying the infinite-SNR policy.
+// included: Whether each row takes part in the grand mean and sums of squares.
+// infinite: Whether each row's raw SNR was infinite.
+// missing: Whether each row had no results; with AllowIncomplete its value is imputed with grandMean.
+// grandMean: Mean SNR over the included rows.
+// replicates: Value of every complete replicate of each row, for the pure-error term; nil for rows with fewer than two replicates.
+type oaRowSNR struct {
+	values     []float64
+	included   []bool
+	infinite   []bool
+	missing    []bool
+	grandMean  float64
+	replicates [][]float64
+}
+
+// snrCeiling returns the configured ceiling or DefaultSNRCeiling.
+func (e *Experiment[P]) snrCeiling() float64 {
+	if e.SNRCeiling > 0 {
+		return e.SNRCeiling
+	}
+	return DefaultSNRCeiling
+}
+
+// applyInfinitePolicy caps or excludes infinite row SNRs and computes the grand
+// mean over the rows that remain included.
+func (e *Experiment[P]) applyInfinitePolicy(rows *oaRowSNR) {
+	ceiling := e.snrCeiling()
+	sum, n := 0.0, 0
+	for i, sn := range rows.values {
+		if math.IsInf(sn, 0) {
+			rows.infinite[i] = true
+			if e.InfiniteSNR == ExcludeInfiniteSNR {
+				rows.included[i] = false
+			} else if sn > 0 {
+				rows.values[i] = ceiling
+			} else {
+				rows.values[i] = -ceiling
+			}
+		}
+		if rows.included[i] {
+			sum += rows.values[i]
+			n++
+		}
+	}
+	if n > 0 {
+		rows.grandMean = sum / float64(n)
+	}
+}
@@ -0,0 +1,134 @@
+package taguchi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ErrDesignMismatch is returned when a checkpoint or bundle was written for
+// a different design than the experiment it is restored into, so its results
+// would be analyzed against the wrong factors, levels or array.
+var ErrDesignMismatch = errors.New("saved design does not match the experiment")
+
+// CheckDesign compares the factors, levels, array and column assignment of a
+// saved design with those of the experiment and returns an error wrapping
+// ErrDesignMismatch that lists every difference. Goal and analysis settings
+// are not compared, since reanalyzing saved results with other settings is
+// legitimate.
+func (e *Experiment[P]) CheckDesign(d Design) error {
+	var diffs []string
+	if len(d.ControlFactors) != len(e.ControlFactors) {
+		diffs = append(diffs, fmt.Sprintf("%d control factors, want %d", len(d.ControlFactors), len(e.ControlFactors)))
+	} else {
+		for j, f := range d.ControlFactors {
+			diffs = append(diffs, factorDiffs("control factor", f.Name, f.Levels, e.ControlFactors[j].Name, e.ControlFactors[j].Levels)...)
+		}
+	}
+	if len(d.NoiseFactors) != len(e.NoiseFactors) {
+		diffs = append(diffs, fmt.Sprintf("%d noise factors, want %d", len(d.NoiseFactors), len(e.NoiseFactors)))
+	} else {
+		for j, f := range d.NoiseFactors {
+			diffs = append(diffs, factorDiffs("noise factor", f.Name, f.Levels, e.NoiseFactors[j].Name, e.NoiseFactors[j].Levels)...)
+		}
+	}
+	oa := d.OrthogonalArray
+	if len(oa) == 0 && d.Array != "" {
+		oa = StandardArrays[d.Array]
+	}
+	if !slices.EqualFunc(oa, e.OrthogonalArray, slices.Equal[[]int]) {
+		diffs = append(diffs, fmt.Sprintf("orthogonal array of %d rows differs from the experiment's %d rows", len(oa), len(e.OrthogonalArray)))
+	} else if len(d.ControlFactors) == len(e.ControlFactors) {
+		saved := &Experiment[P]{ControlFactors: d.ControlFactors, Columns: d.Columns}
+		for j, f := range e.ControlFactors {
+			if saved.column(j) != e.column(j) {
+				diffs = append(diffs, fmt.Sprintf("control factor %s: column %d, want %d", f.Name, saved.column(j)+1, e.column(j)+1))
+			}
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("%w: %s", ErrDesignMismatch, strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+// factorDiffs describes the differences between a saved factor and the
+// experiment's factor at the same position.
+func factorDiffs(kind, name string, levels []float64, wantName string, wantLevels []float64) []string {
+	if name != wantName {
+		return []string{fmt.Sprintf("%s %s, want %s", kind, name, wantName)}
+	}
+	if !slices.Equal(levels, wantLevels) {
+		return []string{fmt.Sprintf("%s %s: levels %v, want %v", kind, name, levels, wantLevels)}
+	}
+	return nil
+}
+
+// checkResults verifies that every result belongs to the experiment's design
+// (see validateTrial), so results left over from an older design are rejected.
+func (e *Experiment[P]) checkResults(results []TrialResult) error {
+	for i, r := range results {
+		if err := e.validateTrial(r.Trial); err != nil {
+			return fmt.Errorf("%w: result %d (trial %d): %v", ErrDesignMismatch, i+1, r.Trial.ID, err)
+		}
+	}
+	return nil
+}
+
+// RestoreJSON loads the results of a checkpoint written by SaveJSON into an
+// experiment constructed in code, keeping its type parameter, goal, Apply
+// functions and settings. It returns an error wrapping ErrDesignMismatch,
+// restoring nothing, when the checkpoint was written for another design (see
+// CheckDesign) or holds results that are not trials of this one.
+func (e *Experiment[P]) RestoreJSON(r io.Reader) error {
+	var state experimentState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return fmt.Errorf("decoding experiment: %w", err)
+	}
+	if state.Version != checkpointVersion {
+		return fmt.Errorf("unsupported experiment format version %d", state.Version)
+	}
+	if err := e.CheckDesign(state.Design); err != nil {
+		return err
+	}
+	if err := e.checkResults(state.Results); err != nil {
+		return err
+	}
+	e.Results = state.Results
+	return nil
+}
+
+// RestoreCheckpoint restores the results of the checkpoint file at path like
+// RestoreJSON. A missing file is not an error, so a run can restore its
+// checkpoint unconditionally before it starts.
+func (e *Experiment[P]) RestoreCheckpoint(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := e.RestoreJSON(f); err != nil {
+		return fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	return nil
+}
+
+// RestoreBundle loads the results of a bundle read by ImportBundle into an
+// experiment constructed in code, with the same checks as RestoreJSON.
+func (e *Experiment[P]) RestoreBundle(b *Bundle) error {
+	if err := e.CheckDesign(b.Design); err != nil {
+		return err
+	}
+	if err := e.checkResults(b.Results); err != nil {
+		return err
+	}
+	e.Results = b.Results
+	return nil
+}
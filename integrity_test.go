@@ -0,0 +1,98 @@
+package taguchi
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRestoreJSON_DetectsStaleDesign verifies that results saved for one
+// design load into a matching code-constructed experiment and are refused
+// by one whose levels changed since.
+func TestRestoreJSON_DetectsStaleDesign(t *testing.T) {
+	saved := parallelExperiment(t)
+	for _, trial := range saved.GenerateTrials() {
+		if err := saved.AddResult(trial, []float64{trial.Control["A"] + trial.Control["B"]}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := saved.SaveJSON(&buf); err != nil {
+		t.Fatalf("SaveJSON: %v", err)
+	}
+
+	same := parallelExperiment(t)
+	if err := same.RestoreJSON(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("RestoreJSON: %v", err)
+	}
+	if len(same.Results) != len(saved.Results) {
+		t.Errorf("restored %d results, want %d", len(same.Results), len(saved.Results))
+	}
+
+	changed := parallelExperiment(t)
+	changed.ControlFactors[1].Levels = []float64{1, 3}
+	err := changed.RestoreJSON(bytes.NewReader(buf.Bytes()))
+	if !errors.Is(err, ErrDesignMismatch) || !strings.Contains(err.Error(), "control factor B: levels [1 2], want [1 3]") {
+		t.Errorf("RestoreJSON into a changed design = %v, want a level mismatch for B", err)
+	}
+	if changed.Results != nil {
+		t.Error("results restored despite the mismatch")
+	}
+
+	swapped := parallelExperiment(t)
+	if err := swapped.SetColumns([]int{1, 0}); err != nil {
+		t.Fatalf("SetColumns: %v", err)
+	}
+	if err := swapped.RestoreJSON(bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrDesignMismatch) {
+		t.Errorf("RestoreJSON into reassigned columns = %v, want ErrDesignMismatch", err)
+	}
+
+	if err := same.RestoreCheckpoint(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("RestoreCheckpoint of a missing file = %v, want nil", err)
+	}
+}
+
+// TestLoadExperimentJSON_RejectsForeignResults verifies that a checkpoint
+// whose results do not belong to its own design fails to load.
+func TestLoadExperimentJSON_RejectsForeignResults(t *testing.T) {
+	exp := parallelExperiment(t)
+	trial := exp.GenerateTrials()[0]
+	if err := exp.AddResult(trial, []float64{1}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	exp.Results[0].Trial.Control = map[string]float64{"A": 7, "B": 1}
+	var buf bytes.Buffer
+	if err := exp.SaveJSON(&buf); err != nil {
+		t.Fatalf("SaveJSON: %v", err)
+	}
+	if _, err := LoadExperimentJSON(&buf); !errors.Is(err, ErrDesignMismatch) {
+		t.Errorf("LoadExperimentJSON = %v, want ErrDesignMismatch", err)
+	}
+}
+
+func TestRestoreBundle(t *testing.T) {
+	exp := parallelExperiment(t)
+	for _, trial := range exp.GenerateTrials() {
+		if err := exp.AddResult(trial, []float64{trial.Control["A"]}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := exp.ExportBundle(&buf); err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+	b, err := ImportBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	if err := parallelExperiment(t).RestoreBundle(b); err != nil {
+		t.Errorf("RestoreBundle into the same design: %v", err)
+	}
+	other := parallelExperiment(t)
+	other.NoiseFactors[0].Name = "Load"
+	if err := other.RestoreBundle(b); !errors.Is(err, ErrDesignMismatch) {
+		t.Errorf("RestoreBundle into a renamed noise factor = %v, want ErrDesignMismatch", err)
+	}
+}
@@ -0,0 +1,258 @@
+package taguchi
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Interaction declares a two-factor interaction to be estimated by Analyze.
+// A, B: Names of the interacting control factors.
+// Columns: Array columns (zero-based) that carry the interaction and must stay free of other factors.
+type Interaction struct {
+	A       string
+	B       string
+	Columns []int
+}
+
+// Name returns the label used for the interaction in ANOVA and contribution maps, e.g. "A×B".
+func (in Interaction) Name() string {
+	return InteractionName(in.A, in.B)
+}
+
+// InteractionName returns the ANOVA label of the interaction between factors a and b.
+func InteractionName(a, b string) string {
+	return a + "×" + b
+}
+
+// InteractionEffect holds the analysis of a declared interaction.
+// A, B: Names of the interacting factors.
+// SS, DF, MS, F: Sum of squares, degrees of freedom, mean square and F-ratio of the interaction.
+// CellMeans: Mean SNR of every level combination, indexed [levelA][levelB]; plotting one
+// line per level of B against the levels of A gives the usual interaction plot, where
+// non-parallel lines indicate an interaction.
+type InteractionEffect struct {
+	A         string
+	B         string
+	SS        float64
+	DF        int
+	MS        float64
+	F         float64
+	CellMeans [][]float64
+}
+
+// InteractionColumns looks up the interaction table of an array: it returns
+// the columns (zero-based) that carry the interaction of columns i and j.
+// These are the columns other than i and j whose levels are fully determined
+// by the level pair of i and j, e.g. column 3 for columns 1 and 2 of L8, or
+// columns 3 and 4 for columns 1 and 2 of L9 (a three-level interaction has
+// four degrees of freedom and occupies two columns). Arrays whose interactions
+// are spread over all columns, such as L12 and L18, return no columns.
+func InteractionColumns(oa [][]int, i, j int) []int {
+	if len(oa) == 0 || i == j || i < 0 || j < 0 || i >= len(oa[0]) || j >= len(oa[0]) {
+		return nil
+	}
+	var columns []int
+	for k := range oa[0] {
+		if k == i || k == j {
+			continue
+		}
+		if determinedBy(oa, k, i, j) && !determinedBy(oa, k, i, i) && !determinedBy(oa, k, j, j) {
+			columns = append(columns, k)
+		}
+	}
+	return columns
+}
+
+// determinedBy reports whether column k is a function of the level pair of columns i and j.
+func determinedBy(oa [][]int, k, i, j int) bool {
+	seen := map[[2]int]int{}
+	for _, row := range oa {
+		key := [2]int{row[i], row[j]}
+		if v, ok := seen[key]; ok && v != row[k] {
+			return false
+		}
+		seen[key] = row[k]
+	}
+	return true
+}
+
+// AddInteraction declares a two-factor interaction so Analyze reports its SS,
+// MS, F-ratio and cell means alongside the main effects. The interaction
+// columns are taken from the array's interaction table for the columns of a
+// and b. When other factors occupy those columns they are moved to free
+// compatible columns; this is only possible before results are recorded.
+func (e *Experiment[P]) AddInteraction(a, b string) error {
+	ia, ib := e.factorIndex(a), e.factorIndex(b)
+	if ia < 0 {
+		return errorf(ErrUnknownFactor, "unknown factor %s", a)
+	}
+	if ib < 0 {
+		return errorf(ErrUnknownFactor, "unknown factor %s", b)
+	}
+	if ia == ib {
+		return fmt.Errorf("factor %s cannot interact with itself", a)
+	}
+	for _, in := range e.Interactions {
+		if (in.A == a && in.B == b) || (in.A == b && in.B == a) {
+			return fmt.Errorf("interaction %s already declared", in.Name())
+		}
+	}
+
+	columns := InteractionColumns(e.OrthogonalArray, e.column(ia), e.column(ib))
+	if len(columns) == 0 {
+		return fmt.Errorf("interaction %s: columns %d and %d have no interaction columns in this array; use a regular array such as L8, L16 or L27",
+			InteractionName(a, b), e.column(ia)+1, e.column(ib)+1)
+	}
+	in := Interaction{A: a, B: b, Columns: columns}
+
+	reserved := map[int]bool{}
+	for _, other := range append(slices.Clone(e.Interactions), in) {
+		for _, c := range other.Columns {
+			if reserved[c] {
+				return fmt.Errorf("interaction %s: column %d is already used by another interaction", in.Name(), c+1)
+			}
+			reserved[c] = true
+		}
+	}
+	for _, c := range e.ErrorColumns {
+		if reserved[c] {
+			return fmt.Errorf("interaction %s: column %d is reserved for error", in.Name(), c+1)
+		}
+		reserved[c] = true
+	}
+	if err := e.clearColumns(reserved, in); err != nil {
+		return fmt.Errorf("interaction %s: %w", in.Name(), err)
+	}
+	e.Interactions = append(e.Interactions, in)
+	return nil
+}
+
+// clearColumns moves factors that do not take part in any interaction off the
+// reserved columns, keeping the columns of interacting factors fixed.
+func (e *Experiment[P]) clearColumns(reserved map[int]bool, added Interaction) error {
+	interacting := map[string]bool{added.A: true, added.B: true}
+	for _, in := range e.Interactions {
+		interacting[in.A] = true
+		interacting[in.B] = true
+	}
+
+	columns := make([]int, len(e.ControlFactors))
+	used := map[int]bool{}
+	for j := range e.ControlFactors {
+		columns[j] = e.column(j)
+		if interacting[e.ControlFactors[j].Name] {
+			if reserved[columns[j]] {
+				return fmt.Errorf("column %d of factor %s is needed for an interaction", columns[j]+1, e.ControlFactors[j].Name)
+			}
+			used[columns[j]] = true
+		}
+	}
+
+	levels := DescribeArray(e.OrthogonalArray).Levels
+	moved := false
+	for j, factor := range e.ControlFactors {
+		if interacting[factor.Name] || (!reserved[columns[j]] && !used[columns[j]]) {
+			used[columns[j]] = true
+			continue
+		}
+		free := -1
+		for c, l := range levels {
+			if !reserved[c] && !used[c] && !slices.Contains(columns[j+1:], c) && l == len(factor.Levels) {
+				free = c
+				break
+			}
+		}
+		if free < 0 {
+			return errorf(ErrArrayTooSmall, "no free %d-level column left for factor %s", len(factor.Levels), factor.Name)
+		}
+		columns[j] = free
+		used[free] = true
+		moved = true
+	}
+
+	if moved {
+		if len(e.Results) > 0 {
+			return fmt.Errorf("factors would move to other columns but results are already recorded")
+		}
+		e.Columns = columns
+	}
+	return nil
+}
+
+// factorIndex returns the position of the named control factor, or -1.
+func (e *Experiment[P]) factorIndex(name string) int {
+	for j, f := range e.ControlFactors {
+		if f.Name == name {
+			return j
+		}
+	}
+	return -1
+}
+
+// interactionCells returns the mean SNR and row count of every level
+// combination of an interaction's factors over the included rows.
+func (e *Experiment[P]) interactionCells(rows oaRowSNR, in Interaction) ([][]float64, [][]int) {
+	ia, ib := e.factorIndex(in.A), e.factorIndex(in.B)
+	la, lb := len(e.ControlFactors[ia].Levels), len(e.ControlFactors[ib].Levels)
+	means := make([][]float64, la)
+	counts := make([][]int, la)
+	for x := range means {
+		means[x] = make([]float64, lb)
+		counts[x] = make([]int, lb)
+	}
+	for i := range e.OrthogonalArray {
+		if !rows.included[i] {
+			continue
+		}
+		x, y := e.levelIndex(i, ia), e.levelIndex(i, ib)
+		if x < 0 || x >= la || y < 0 || y >= lb {
+			continue
+		}
+		means[x][y] += rows.values[i]
+		counts[x][y]++
+	}
+	for x := range means {
+		for y := range means[x] {
+			if counts[x][y] > 0 {
+				means[x][y] /= float64(counts[x][y])
+			}
+		}
+	}
+	return means, counts
+}
+
+// interactionSS returns the sum of squares and degrees of freedom of an
+// interaction: the SS of its cell means minus the SS of both main effects.
+func (e *Experiment[P]) interactionSS(rows oaRowSNR, in Interaction, anova ANOVAResult) (float64, int) {
+	means, counts := e.interactionCells(rows, in)
+	cells := 0.0
+	for x := range means {
+		for y := range means[x] {
+			d := means[x][y] - rows.grandMean
+			cells += float64(counts[x][y]) * d * d
+		}
+	}
+	ss := max(cells-anova.FactorSS[in.A]-anova.FactorSS[in.B], 0)
+	df := (len(means) - 1) * (len(means[0]) - 1)
+	return ss, df
+}
+
+// interactionEffects collects the ANOVA statistics and cell means of every
+// declared interaction, in declaration order.
+func (e *Experiment[P]) interactionEffects(rows oaRowSNR, anova ANOVAResult) []InteractionEffect {
+	var effects []InteractionEffect
+	for _, in := range e.Interactions {
+		means, _ := e.interactionCells(rows, in)
+		name := in.Name()
+		effects = append(effects, InteractionEffect{
+			A:         in.A,
+			B:         in.B,
+			SS:        anova.FactorSS[name],
+			DF:        anova.FactorDF[name],
+			MS:        anova.FactorMS[name],
+			F:         anova.FactorF[name],
+			CellMeans: means,
+		})
+	}
+	return effects
+}
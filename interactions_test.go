@@ -0,0 +1,134 @@
+package taguchi
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInteractionColumns(t *testing.T) {
+	cases := []struct {
+		array ArrayType
+		i, j  int
+		want  []int
+	}{
+		{L4, 0, 1, []int{2}},
+		{L8, 0, 1, []int{2}},
+		{L8, 0, 3, []int{4}},
+		{L8, 1, 3, []int{5}},
+		{L8, 2, 3, []int{6}},
+		{L9, 0, 1, []int{2, 3}},
+		{L16, 0, 3, []int{4}},
+		{L12, 0, 1, nil},
+		{L18, 1, 2, nil},
+	}
+	for _, c := range cases {
+		got := InteractionColumns(StandardArrays[c.array], c.i, c.j)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%s: InteractionColumns(%d, %d) = %v, want %v", c.array, c.i, c.j, got, c.want)
+		}
+	}
+}
+
+// TestAnalyze_Interaction verifies that a pure interaction shows up in the
+// interaction SS rather than in the main effects, and that a factor sitting on
+// the interaction column is moved out of the way.
+func TestAnalyze_Interaction(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	if err := exp.AddInteraction("A", "B"); err != nil {
+		t.Fatalf("AddInteraction: %v", err)
+	}
+	if !slices.Equal(exp.Columns, []int{0, 1, 3}) {
+		t.Errorf("Columns = %v, want [0 1 3]", exp.Columns)
+	}
+	if err := exp.AddInteraction("B", "A"); err == nil {
+		t.Error("expected error for a duplicate interaction")
+	}
+
+	for _, trial := range exp.GenerateTrials() {
+		y := 10.0
+		if trial.Control["A"] == trial.Control["B"] {
+			y = 1
+		}
+		exp.AddResult(trial, []float64{y})
+	}
+	result := mustAnalyze(t, exp)
+
+	if len(result.Interactions) != 1 {
+		t.Fatalf("len(Interactions) = %d, want 1", len(result.Interactions))
+	}
+	in := result.Interactions[0]
+	if in.DF != 1 || in.SS <= 0 {
+		t.Errorf("interaction DF = %d, SS = %.4f; want DF 1 and positive SS", in.DF, in.SS)
+	}
+	if !almostEqual(in.SS, result.ANOVA.FactorSS[InteractionName("A", "B")]) {
+		t.Error("interaction SS not reported in the ANOVA table")
+	}
+	for _, name := range []string{"A", "B", "C"} {
+		if ss := result.ANOVA.FactorSS[name]; !almostEqual(ss, 0) {
+			t.Errorf("FactorSS[%s] = %.4f, want 0", name, ss)
+		}
+	}
+	if in.CellMeans[0][0] <= in.CellMeans[0][1] {
+		t.Errorf("CellMeans = %v, want matching levels to score higher", in.CellMeans)
+	}
+}
+
+func TestAddInteraction_NonRegularArray(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L12, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	if err := exp.AddInteraction("A", "B"); err == nil {
+		t.Error("expected error for an interaction on L12")
+	}
+}
+
+// TestOptimalLevelsSubjectTo verifies that pinning one factor of an
+// interaction changes the recommended level of its partner.
+func TestOptimalLevelsSubjectTo(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	if err := exp.AddInteraction("A", "B"); err != nil {
+		t.Fatalf("AddInteraction: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		y := 10.0
+		if trial.Control["A"] == trial.Control["B"] {
+			y = 1
+		}
+		y += trial.Control["C"]
+		exp.AddResult(trial, []float64{y})
+	}
+
+	for _, pin := range []float64{1, 2} {
+		levels, err := exp.OptimalLevelsSubjectTo(map[string]float64{"A": pin})
+		if err != nil {
+			t.Fatalf("OptimalLevelsSubjectTo: %v", err)
+		}
+		if levels["A"] != pin || levels["B"] != pin || levels["C"] != 1 {
+			t.Errorf("A pinned to %v: got %v, want A=B=%v and C=1", pin, levels, pin)
+		}
+	}
+	if _, err := exp.OptimalLevelsSubjectTo(map[string]float64{"A": 3}); err == nil {
+		t.Error("expected error for a pin outside the factor's levels")
+	}
+}
@@ -0,0 +1,46 @@
+// Package testexp builds the small experiments shared by the tests of the
+// subpackages.
+package testexp
+
+import (
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// Design describes an experiment built by New.
+// Goal: Optimization goal (SmallerTheBetter when nil).
+// Factors: Control factors (A {1, 2} and B {10, 20} when nil).
+// Array: Standard orthogonal array (L4 when empty).
+// Noise: Noise factors (N {0, 1} when nil).
+type Design struct {
+	Goal    taguchi.OptimizationGoal
+	Factors []taguchi.ControlFactor
+	Array   taguchi.ArrayType
+	Noise   []taguchi.NoiseFactor
+}
+
+// New builds the experiment of d, failing the test when the design is invalid.
+func New(t testing.TB, d Design) *taguchi.Experiment[struct{}] {
+	t.Helper()
+	if d.Goal == nil {
+		d.Goal = taguchi.SmallerTheBetter{}
+	}
+	if d.Factors == nil {
+		d.Factors = []taguchi.ControlFactor{
+			{Name: "A", Levels: []float64{1, 2}},
+			{Name: "B", Levels: []float64{10, 20}},
+		}
+	}
+	if d.Array == "" {
+		d.Array = taguchi.L4
+	}
+	if d.Noise == nil {
+		d.Noise = []taguchi.NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	}
+	exp, err := taguchi.NewExperimentFromFactors(d.Goal, d.Factors, d.Array, d.Noise)
+	if err != nil {
+		t.Fatalf("building test experiment: %v", err)
+	}
+	return exp
+}
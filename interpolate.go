@@ -0,0 +1,86 @@
+package taguchi
+
+import "math"
+
+// InterpolatedOptimum is the optimum of a continuous three-level factor
+// estimated between its tested levels: the vertex of the quadratic through
+// the factor's main effects. It is an untested setting and should be
+// confirmed with a run before it is adopted.
+// Factor: Name of the control factor.
+// Level: Interpolated optimal setting, strictly inside the tested range.
+// SNR: Main effect predicted by the quadratic at Level.
+// Tested: Best tested level (the factor's OptimalLevels entry).
+// CurvatureP: p-value of the quadratic component of the factor's effect.
+// Caution: The curvature is not significant at Alpha, so the interpolated optimum may be an artifact of noise.
+type InterpolatedOptimum struct {
+	Factor     string
+	Level      float64
+	SNR        float64
+	Tested     float64
+	CurvatureP float64
+	Caution    bool
+}
+
+// interpolateOptima fits a quadratic through the main effects of every
+// optimizable continuous factor with three levels and reports its vertex
+// when the curve has a maximum inside the tested range. The quadratic
+// component of the factor's sum of squares, what remains after a weighted
+// linear fit over the level values, is tested against the ANOVA error.
+func (e *Experiment[P]) interpolateOptima(rows oaRowSNR, anova ANOVAResult, mainEffects map[string][]float64, optimal map[string]float64) []InterpolatedOptimum {
+	var optima []InterpolatedOptimum
+	for j, factor := range e.ControlFactors {
+		if !factor.Continuous || factor.ObserveOnly || len(factor.Levels) != 3 {
+			continue
+		}
+		x, y := factor.Levels, mainEffects[factor.Name]
+		n := make([]float64, 3)
+		for i := range e.OrthogonalArray {
+			if l := e.levelIndex(i, j); rows.included[i] && l >= 0 && l < 3 {
+				n[l]++
+			}
+		}
+		if n[0] == 0 || n[1] == 0 || n[2] == 0 {
+			continue
+		}
+
+		// Quadratic through the three points in Newton form.
+		d01 := (y[1] - y[0]) / (x[1] - x[0])
+		d12 := (y[2] - y[1]) / (x[2] - x[1])
+		a := (d12 - d01) / (x[2] - x[0])
+		if !(a < 0) {
+			continue
+		}
+		b := d01 - a*(x[0]+x[1])
+		vertex := -b / (2 * a)
+		lo, hi := math.Min(x[0], math.Min(x[1], x[2])), math.Max(x[0], math.Max(x[1], x[2]))
+		if !(vertex > lo && vertex < hi) {
+			continue
+		}
+
+		// Quadratic SS: factor SS less the SS of the weighted linear fit.
+		total, mx, my := n[0]+n[1]+n[2], 0.0, 0.0
+		for l := range x {
+			mx += n[l] * x[l] / total
+			my += n[l] * y[l] / total
+		}
+		sxx, sxy := 0.0, 0.0
+		for l := range x {
+			sxx += n[l] * (x[l] - mx) * (x[l] - mx)
+			sxy += n[l] * (x[l] - mx) * (y[l] - my)
+		}
+		quadSS := math.Max(anova.FactorSS[factor.Name]-sxy*sxy/sxx, 0)
+		p := 1.0
+		if anova.ErrorMS > 0 {
+			p = fSurvival(quadSS/anova.ErrorMS, 1, anova.ErrorDF)
+		}
+		optima = append(optima, InterpolatedOptimum{
+			Factor:     factor.Name,
+			Level:      vertex,
+			SNR:        y[0] + (vertex-x[0])*(d01+a*(vertex-x[1])),
+			Tested:     optimal[factor.Name],
+			CurvatureP: p,
+			Caution:    !(p < anova.Alpha),
+		})
+	}
+	return optima
+}
@@ -0,0 +1,59 @@
+package taguchi
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestAnalyze_InterpolatedOptimum verifies that the quadratic through the main
+// effects of a continuous three-level factor yields its vertex, and that
+// categorical factors are left alone.
+func TestAnalyze_InterpolatedOptimum(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "Temp", Levels: []float64{100, 150, 200}, Continuous: true},
+		{Name: "Mode", Levels: []float64{1, 2, 3}},
+		{Name: "C", Levels: []float64{1, 2, 3}},
+	}
+	exp, err := NewExperimentFromFactors(LargerTheBetter{}, factors, L9, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	jitter := []float64{0.01, -0.02, 0.01, 0.02, -0.01, 0, -0.01, 0.02, -0.02}
+	for _, trial := range exp.GenerateTrials() {
+		// Yield peaks at Temp = 160, between the tested 150 and 200.
+		d := trial.Control["Temp"] - 160
+		y := 100 - d*d/50 + 5*trial.Control["Mode"] + jitter[trial.Row]
+		exp.AddResult(trial, []float64{y})
+	}
+
+	result := mustAnalyze(t, exp)
+	if len(result.Interpolated) != 1 {
+		t.Fatalf("Interpolated = %+v, want only Temp", result.Interpolated)
+	}
+	opt := result.Interpolated[0]
+	if opt.Factor != "Temp" || opt.Tested != 150 || opt.Level <= 150 || opt.Level >= 200 {
+		t.Errorf("Interpolated = %+v, want Temp between 150 and 200", opt)
+	}
+	if opt.Caution || opt.CurvatureP >= 0.05 {
+		t.Errorf("CurvatureP = %.4f, Caution = %v; want clear curvature", opt.CurvatureP, opt.Caution)
+	}
+	if opt.SNR < result.MainEffects["Temp"][1] {
+		t.Errorf("SNR at vertex %.4f below the best tested level %.4f", opt.SNR, result.MainEffects["Temp"][1])
+	}
+	// The vertex of the fitted parabola through the three level means.
+	x, y := []float64{100, 150, 200}, result.MainEffects["Temp"]
+	a := ((y[2]-y[1])/50 - (y[1]-y[0])/50) / 100
+	b := (y[1]-y[0])/50 - a*(x[0]+x[1])
+	if want := -b / (2 * a); math.Abs(opt.Level-want) > 1e-9 {
+		t.Errorf("Level = %.4f, want %.4f", opt.Level, want)
+	}
+
+	var sb strings.Builder
+	if err := WriteAnalysisReport(&sb, result, DefaultReportOptions()); err != nil {
+		t.Fatalf("WriteAnalysisReport: %v", err)
+	}
+	if !strings.Contains(sb.String(), "Interpolated Temp") {
+		t.Errorf("report does not show the interpolated optimum:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,73 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// InterruptedError is returned by Run and ParallelRunner.Run when the context
+// is cancelled, e.g. by signal.NotifyContext on SIGINT. The results of every
+// completed trial are kept in the experiment (and in RunOptions.Checkpoint when
+// set); run again with RunOptions{SkipCompleted: true} to measure the rest.
+// Completed, Remaining: Trials of the design with and without a recorded result.
+// Checkpoint: File the results were flushed to, empty without RunOptions.Checkpoint.
+// Err: The context's error.
+type InterruptedError struct {
+	Completed  int
+	Remaining  int
+	Checkpoint string
+	Err        error
+}
+
+func (e *InterruptedError) Error() string {
+	msg := fmt.Sprintf("run interrupted with %d of %d trials completed", e.Completed, e.Completed+e.Remaining)
+	if e.Checkpoint != "" {
+		msg += "; resume from " + e.Checkpoint
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *InterruptedError) Unwrap() error { return e.Err }
+
+// trialContext returns the context in which a trial is measured. With
+// opts.FinishInFlight, cancelling ctx does not reach a trial that has started,
+// so it completes and is recorded before the run stops.
+func trialContext(ctx context.Context, opts RunOptions) context.Context {
+	if opts.FinishInFlight {
+		return context.WithoutCancel(ctx)
+	}
+	return ctx
+}
+
+// finishRun flushes the results to opts.Checkpoint and, when err stems from
+// the cancellation of ctx, turns it into an InterruptedError. It publishes
+// EventExperimentCompleted with the error returned.
+func (e *Experiment[P]) finishRun(ctx context.Context, opts RunOptions, err error) (result error) {
+	defer func() { e.publishCompleted(result) }()
+	if opts.Checkpoint != "" {
+		if cerr := e.SaveCheckpoint(opts.Checkpoint); cerr != nil {
+			return errors.Join(err, cerr)
+		}
+	}
+	if err == nil || ctx.Err() == nil || !errors.Is(err, ctx.Err()) {
+		return err
+	}
+	completed, remaining := e.trialCounts()
+	return &InterruptedError{
+		Completed:  completed,
+		Remaining:  remaining,
+		Checkpoint: opts.Checkpoint,
+		Err:        ctx.Err(),
+	}
+}
+
+// trialCounts returns the number of trials of the design, over all replicate
+// blocks, with and without a recorded result.
+func (e *Experiment[P]) trialCounts() (completed, remaining int) {
+	trials := e.designTrials()
+	for b := 0; b < e.RunOrder.blocks(); b++ {
+		remaining += len(e.pendingTrials(trials, b))
+	}
+	return len(trials)*e.RunOrder.blocks() - remaining, remaining
+}
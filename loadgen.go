@@ -0,0 +1,157 @@
+package taguchi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HTTPLoad is a built-in HTTP load driver for latency experiments.
+// URL: Target URL.
+// Method: HTTP method (GET when empty).
+// Body: Optional request body sent with every request.
+// Concurrency: Number of concurrent clients (1 when zero).
+// Percentile: Latency percentile reported as the observation, e.g. 99; zero reports the mean.
+// Client: HTTP client to use (http.DefaultClient when nil).
+type HTTPLoad struct {
+	URL         string
+	Method      string
+	Body        []byte
+	Concurrency int
+	Percentile  float64
+	Client      *http.Client
+}
+
+// LoadReport summarizes one load run. Responses with status 400 or above
+// count as errors and are excluded from the latencies.
+// Requests: Requests sent.
+// Errors: Failed requests (transport errors and error statuses).
+// Latencies: Latency of every successful request, sorted ascending.
+// Throughput: Successful requests per second.
+type LoadReport struct {
+	Requests   int
+	Errors     int
+	Latencies  []time.Duration
+	Throughput float64
+}
+
+// Mean returns the mean latency of the successful requests.
+func (r LoadReport) Mean() time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, l := range r.Latencies {
+		sum += l
+	}
+	return sum / time.Duration(len(r.Latencies))
+}
+
+// Percentile returns the nearest-rank p-th percentile latency (0 < p <= 100).
+func (r LoadReport) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(r.Latencies))))
+	rank = min(max(rank, 1), len(r.Latencies))
+	return r.Latencies[rank-1]
+}
+
+// Run drives load against the target for duration and reports the latencies.
+func (l HTTPLoad) Run(ctx context.Context, duration time.Duration) (LoadReport, error) {
+	if l.URL == "" {
+		return LoadReport{}, errors.New("http load: no target URL")
+	}
+	method, client := l.Method, l.Client
+	if method == "" {
+		method = http.MethodGet
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		report LoadReport
+		wg     sync.WaitGroup
+	)
+	start := time.Now()
+	for c := 0; c < max(l.Concurrency, 1); c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				req, err := http.NewRequestWithContext(ctx, method, l.URL, bytes.NewReader(l.Body))
+				if err != nil {
+					return
+				}
+				t0 := time.Now()
+				resp, err := client.Do(req)
+				latency := time.Since(t0)
+				if err == nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				if ctx.Err() != nil {
+					// Requests cut off by the end of the run are not counted.
+					return
+				}
+				mu.Lock()
+				report.Requests++
+				if err != nil || resp.StatusCode >= 400 {
+					report.Errors++
+				} else {
+					report.Latencies = append(report.Latencies, latency)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(report.Latencies, func(i, j int) bool { return report.Latencies[i] < report.Latencies[j] })
+	if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+		report.Throughput = float64(len(report.Latencies)) / elapsed
+	}
+	if err := ctx.Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return report, err
+	}
+	return report, nil
+}
+
+// Load returns a LoadFunc for ServiceHarness that reports the configured
+// latency percentile (or the mean) in milliseconds. A run without a single
+// successful request is an error.
+func (l HTTPLoad) Load() LoadFunc {
+	return func(ctx context.Context, trial Trial, duration time.Duration) (float64, error) {
+		report, err := l.Run(ctx, duration)
+		if err != nil {
+			return 0, err
+		}
+		if len(report.Latencies) == 0 {
+			return 0, fmt.Errorf("http load: all %d requests to %s failed", report.Requests, l.URL)
+		}
+		latency := report.Mean()
+		if l.Percentile > 0 {
+			latency = report.Percentile(l.Percentile)
+		}
+		return float64(latency) / float64(time.Millisecond), nil
+	}
+}
+
+// Measure returns a MeasureFunc for Run that drives load for duration per observation.
+func (l HTTPLoad) Measure(duration time.Duration) MeasureFunc {
+	load := l.Load()
+	return func(ctx context.Context, trial Trial) (float64, error) {
+		return load(ctx, trial, duration)
+	}
+}
@@ -0,0 +1,48 @@
+package taguchi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoadReport_Percentile(t *testing.T) {
+	r := LoadReport{}
+	for i := 1; i <= 100; i++ {
+		r.Latencies = append(r.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	if got := r.Percentile(99); got != 99*time.Millisecond {
+		t.Errorf("Percentile(99) = %v, want 99ms", got)
+	}
+	if got := r.Percentile(50); got != 50*time.Millisecond {
+		t.Errorf("Percentile(50) = %v, want 50ms", got)
+	}
+	if got := r.Mean(); got != 50500*time.Microsecond {
+		t.Errorf("Mean() = %v, want 50.5ms", got)
+	}
+}
+
+func TestHTTPLoad(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+	}))
+	defer srv.Close()
+
+	l := HTTPLoad{URL: srv.URL, Concurrency: 4, Percentile: 90}
+	report, err := l.Run(context.Background(), 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Requests == 0 || report.Errors != 0 {
+		t.Errorf("report: %d requests, %d errors; want requests and no errors", report.Requests, report.Errors)
+	}
+	ms, err := l.Load()(context.Background(), Trial{}, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if ms < 1 {
+		t.Errorf("p90 latency = %.3fms, want at least the 1ms handler delay", ms)
+	}
+}
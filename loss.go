@@ -0,0 +1,151 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+)
+
+// QualityLoss is Taguchi's quadratic loss function L(y) = K·(y − m)², which
+// prices the deviation of a response from its ideal value m in cost units,
+// so that improvements can be stated as money saved rather than decibels.
+// K: Loss coefficient, the cost per squared unit of deviation (see LossCoefficient).
+//
+// The ideal value depends on the goal: 0 for SmallerTheBetter (L = K·y²), the
+// goal's Target for NominalTheBest and NominalTheBestTypeI, and infinity for
+// LargerTheBetter, whose loss is L = K/y². SignedTarget carries no target, so
+// its responses are priced by their deviation from 0, as for an offset or a
+// clock skew; pass y − target for other targets. Goals may also be given as
+// pointers.
+type QualityLoss struct {
+	K float64
+}
+
+// LossCoefficient returns the K at which a response at the tolerance limit
+// incurs cost, e.g. the cost of repairing or scrapping a unit: cost/tolerance²,
+// or cost·tolerance² for LargerTheBetter, where tolerance is the smallest
+// acceptable response.
+func LossCoefficient(goal OptimizationGoal, cost, tolerance float64) float64 {
+	if _, ok := lossGoal(goal).(LargerTheBetter); ok {
+		return cost * tolerance * tolerance
+	}
+	return cost / (tolerance * tolerance)
+}
+
+// Loss returns the loss of a single response y under goal.
+func (q QualityLoss) Loss(goal OptimizationGoal, y float64) (float64, error) {
+	switch g := lossGoal(goal).(type) {
+	case SmallerTheBetter, SignedTarget:
+		return q.K * y * y, nil
+	case LargerTheBetter:
+		return q.K / (y * y), nil
+	case NominalTheBest:
+		return q.K * (y - g.Target) * (y - g.Target), nil
+	case NominalTheBestTypeI:
+		return q.K * (y - g.Target) * (y - g.Target), nil
+	default:
+		return 0, fmt.Errorf("quality loss is not defined for the %s goal", goal)
+	}
+}
+
+// ExpectedLoss returns the average loss per unit of a set of observations.
+// For NominalTheBestTypeI and SignedTarget it is the loss remaining once the
+// mean has been moved onto target with an adjustment factor: K·Target²·s²/ȳ²
+// and K·s² respectively.
+func (q QualityLoss) ExpectedLoss(goal OptimizationGoal, observations []float64) (float64, error) {
+	if len(observations) == 0 {
+		return 0, fmt.Errorf("no observations")
+	}
+	return q.fromSNR(goal, goal.CalculateSNR(observations))
+}
+
+// fromSNR converts an SNR into the expected loss per unit. The SNRs of the
+// supported goals are -10·log10 of the mean squared deviation the loss
+// function prices, up to the Target² scale of NominalTheBestTypeI.
+func (q QualityLoss) fromSNR(goal OptimizationGoal, snr float64) (float64, error) {
+	scale := 1.0
+	switch g := lossGoal(goal).(type) {
+	case SmallerTheBetter, LargerTheBetter, NominalTheBest, SignedTarget:
+	case NominalTheBestTypeI:
+		scale = g.Target * g.Target
+	default:
+		return 0, fmt.Errorf("quality loss is not defined for the %s goal", goal)
+	}
+	return q.K * scale * math.Pow(10, -snr/10), nil
+}
+
+// lossGoal returns the value of a pointer to a goal with a quadratic loss, so
+// that the loss functions handle both forms alike. Other goals are returned
+// unchanged.
+func lossGoal(goal OptimizationGoal) OptimizationGoal {
+	switch g := goal.(type) {
+	case *SmallerTheBetter:
+		if g != nil {
+			return *g
+		}
+	case *LargerTheBetter:
+		if g != nil {
+			return *g
+		}
+	case *NominalTheBest:
+		if g != nil {
+			return *g
+		}
+	case *NominalTheBestTypeI:
+		if g != nil {
+			return *g
+		}
+	case *SignedTarget:
+		if g != nil {
+			return *g
+		}
+	}
+	return goal
+}
+
+// LossComparison expresses the improvement of the optimal levels over the
+// current configuration in cost units.
+// CurrentLevels, OptimalLevels: The compared configurations.
+// Current, Optimal: Expected loss per unit, from the SNR predicted for each configuration.
+// Savings: Current − Optimal, the expected saving per unit.
+// GainDB: SNR gain of the optimal over the current configuration; every 3 dB halves the loss.
+type LossComparison struct {
+	CurrentLevels map[string]float64
+	OptimalLevels map[string]float64
+	Current       float64
+	Optimal       float64
+	Savings       float64
+	GainDB        float64
+}
+
+// CompareLoss predicts the expected loss per unit at the current
+// configuration and at the optimal levels found by Analyze. current maps
+// every optimizable control factor to its level in production today. With
+// weighted noise factors, the losses are expectations over the noise
+// conditions weighted by their likelihood.
+func (e *Experiment[P]) CompareLoss(q QualityLoss, current map[string]float64) (LossComparison, error) {
+	result, err := e.analyze()
+	if err != nil {
+		return LossComparison{}, err
+	}
+	before, err := e.Predict(current, DefaultConfidence)
+	if err != nil {
+		return LossComparison{}, fmt.Errorf("current configuration: %w", err)
+	}
+	after, err := e.Predict(result.OptimalLevels, DefaultConfidence)
+	if err != nil {
+		return LossComparison{}, err
+	}
+	c := LossComparison{
+		CurrentLevels: before.Levels,
+		OptimalLevels: after.Levels,
+		GainDB:        after.SNR - before.SNR,
+	}
+	if c.Current, err = q.fromSNR(e.Goal, before.SNR); err != nil {
+		return LossComparison{}, err
+	}
+	if c.Optimal, err = q.fromSNR(e.Goal, after.SNR); err != nil {
+		return LossComparison{}, err
+	}
+	c.Savings = c.Current - c.Optimal
+	return c, nil
+}
@@ -0,0 +1,131 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQualityLoss_ExpectedLoss(t *testing.T) {
+	q := QualityLoss{K: 2}
+	obs := []float64{9, 11, 10, 12}
+
+	tests := []struct {
+		goal OptimizationGoal
+		want float64
+	}{
+		{SmallerTheBetter{}, 2 * (81 + 121 + 100 + 144) / 4.0},
+		{LargerTheBetter{}, 2 * (1/81.0 + 1/121.0 + 1/100.0 + 1/144.0) / 4},
+		{NominalTheBest{Target: 10}, 2 * (1 + 1 + 0 + 4) / 4.0},
+	}
+	for _, tt := range tests {
+		got, err := q.ExpectedLoss(tt.goal, obs)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.goal, err)
+		}
+		if !almostEqual(got, tt.want) {
+			t.Errorf("%s: ExpectedLoss = %.6f, want %.6f", tt.goal, got, tt.want)
+		}
+		// The expected loss is the mean of the single-response losses.
+		sum := 0.0
+		for _, y := range obs {
+			l, err := q.Loss(tt.goal, y)
+			if err != nil {
+				t.Fatalf("%s: Loss: %v", tt.goal, err)
+			}
+			sum += l
+		}
+		if !almostEqual(sum/float64(len(obs)), tt.want) {
+			t.Errorf("%s: mean Loss = %.6f, want %.6f", tt.goal, sum/float64(len(obs)), tt.want)
+		}
+	}
+
+	if _, err := q.ExpectedLoss(FractionDefective{}, obs); err == nil {
+		t.Error("ExpectedLoss accepted a goal without a quadratic loss")
+	}
+	if k := LossCoefficient(NominalTheBest{Target: 10}, 50, 5); k != 2 {
+		t.Errorf("LossCoefficient = %g, want 50/5² = 2", k)
+	}
+}
+
+// TestQualityLoss_GoalForms verifies that goals given as pointers are priced
+// like their values and that SignedTarget is handled by every loss function.
+func TestQualityLoss_GoalForms(t *testing.T) {
+	q := QualityLoss{K: 2}
+	obs := []float64{9, 11, 10, 12}
+	for _, pair := range [][2]OptimizationGoal{
+		{SmallerTheBetter{}, &SmallerTheBetter{}},
+		{LargerTheBetter{}, &LargerTheBetter{}},
+		{NominalTheBest{Target: 10}, &NominalTheBest{Target: 10}},
+		{NominalTheBestTypeI{Target: 10}, &NominalTheBestTypeI{Target: 10}},
+		{SignedTarget{}, &SignedTarget{}},
+	} {
+		value, pointer := pair[0], pair[1]
+		want, err := q.Loss(value, 11)
+		if err != nil {
+			t.Fatalf("%s: Loss: %v", value, err)
+		}
+		if got, err := q.Loss(pointer, 11); err != nil || got != want {
+			t.Errorf("%s: Loss of the pointer = %g, %v; want %g", value, got, err, want)
+		}
+		want, err = q.ExpectedLoss(value, obs)
+		if err != nil {
+			t.Fatalf("%s: ExpectedLoss: %v", value, err)
+		}
+		if got, err := q.ExpectedLoss(pointer, obs); err != nil || got != want {
+			t.Errorf("%s: ExpectedLoss of the pointer = %g, %v; want %g", value, got, err, want)
+		}
+		if got, want := LossCoefficient(pointer, 50, 5), LossCoefficient(value, 50, 5); got != want {
+			t.Errorf("%s: LossCoefficient of the pointer = %g, want %g", value, got, want)
+		}
+	}
+
+	// SignedTarget prices a response by its deviation from 0, and a set of
+	// observations by its variance once the mean is on target.
+	if got, err := q.Loss(SignedTarget{}, -3); err != nil || got != 18 {
+		t.Errorf("Loss(SignedTarget, -3) = %g, %v; want 2·3² = 18", got, err)
+	}
+	if got, err := q.ExpectedLoss(SignedTarget{}, obs); err != nil || !almostEqual(got, 2*5/3.0) {
+		t.Errorf("ExpectedLoss(SignedTarget) = %g, %v; want 2·s² = %g", got, err, 2*5/3.0)
+	}
+	if k := LossCoefficient(&LargerTheBetter{}, 50, 5); k != 50*25 {
+		t.Errorf("LossCoefficient(*LargerTheBetter) = %g, want 50·5² = 1250", k)
+	}
+	if _, err := q.Loss(&FractionDefective{}, 1); err == nil {
+		t.Error("Loss accepted a pointer to a goal without a quadratic loss")
+	}
+}
+
+func TestCompareLoss(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		y := trial.Control["A"] * trial.Control["B"]
+		exp.AddResult(trial, []float64{y, y})
+	}
+
+	q := QualityLoss{K: 3}
+	c, err := exp.CompareLoss(q, map[string]float64{"A": 2, "B": 2})
+	if err != nil {
+		t.Fatalf("CompareLoss: %v", err)
+	}
+	if c.OptimalLevels["A"] != 1 || c.OptimalLevels["B"] != 1 {
+		t.Fatalf("OptimalLevels = %v, want A=1 B=1", c.OptimalLevels)
+	}
+	if c.Savings <= 0 || c.GainDB <= 0 {
+		t.Errorf("Savings = %g, GainDB = %g; want an improvement", c.Savings, c.GainDB)
+	}
+	if want := c.Current * math.Pow(10, -c.GainDB/10); !almostEqual(c.Optimal, want) {
+		t.Errorf("Optimal = %g, want the current loss reduced by the SNR gain (%g)", c.Optimal, want)
+	}
+
+	if _, err := exp.CompareLoss(q, map[string]float64{"A": 2}); err == nil {
+		t.Error("CompareLoss accepted a configuration without B")
+	}
+}
@@ -0,0 +1,30 @@
+package taguchi
+
+// meanAnalysis runs the ANOVA of the raw row means over the rows included in
+// the SNR analysis and classifies the optimizable factors by significance in
+// both tables. It returns nil unless AnalyzeMeans is set.
+func (e *Experiment[P]) meanAnalysis(snrRows oaRowSNR, snrANOVA ANOVAResult) *MeanAnalysis {
+	if !e.AnalyzeMeans {
+		return nil
+	}
+	anova, effects, _ := e.computeANOVA(e.computeRowMeans(snrRows))
+	contributions, errorContribution := computeContributions(anova)
+	ma := &MeanAnalysis{
+		MainEffects:       effects,
+		Contributions:     contributions,
+		ErrorContribution: errorContribution,
+		ANOVA:             anova,
+	}
+	for _, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
+		switch {
+		case snrANOVA.Significant[factor.Name]:
+			ma.DispersionFactors = append(ma.DispersionFactors, factor.Name)
+		case anova.Significant[factor.Name]:
+			ma.LocationFactors = append(ma.LocationFactors, factor.Name)
+		}
+	}
+	return ma
+}
@@ -0,0 +1,139 @@
+package taguchi
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Measurement captures one response of a run of the code under test. Start
+// is called right before the run and Stop right after it; Value then reports
+// the measured quantity.
+type Measurement interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	Value() float64
+}
+
+// Collector is a Measurement recorded as a named secondary response of every
+// measured run (see RunOptions.Collectors and the observations package).
+type Collector interface {
+	Measurement
+	Name() string
+}
+
+// MeasureWith builds a MeasureFunc that runs the code under test between the
+// Start and Stop of m and returns m's value. Together with factor Apply
+// functions and trial hooks (e.g. from the noise package) an experiment is
+// described declaratively:
+//
+//	exp.Run(ctx, taguchi.MeasureWith(&taguchi.WallTime{}, work), opts)
+func MeasureWith(m Measurement, run func(ctx context.Context, trial Trial) error) MeasureFunc {
+	return func(ctx context.Context, trial Trial) (float64, error) {
+		if err := m.Start(ctx); err != nil {
+			return 0, err
+		}
+		runErr := run(ctx, trial)
+		if err := m.Stop(ctx); err != nil {
+			return 0, err
+		}
+		if runErr != nil {
+			return 0, runErr
+		}
+		return m.Value(), nil
+	}
+}
+
+// WallTime measures elapsed wall-clock time.
+// Unit: Unit of the reported value (time.Millisecond when zero).
+type WallTime struct {
+	Unit time.Duration
+
+	start   time.Time
+	elapsed time.Duration
+}
+
+// Start implements Measurement.
+func (w *WallTime) Start(context.Context) error {
+	w.start = time.Now()
+	return nil
+}
+
+// Stop implements Measurement.
+func (w *WallTime) Stop(context.Context) error {
+	w.elapsed = time.Since(w.start)
+	return nil
+}
+
+// Value returns the elapsed time in Unit.
+func (w *WallTime) Value() float64 {
+	return float64(w.elapsed) / float64(unitOrMillisecond(w.Unit))
+}
+
+// CPUTime measures the user plus system CPU time consumed by the whole
+// process, so concurrent work outside the code under test is included. It is
+// only supported on Unix systems.
+// Unit: Unit of the reported value (time.Millisecond when zero).
+type CPUTime struct {
+	Unit time.Duration
+
+	start time.Duration
+	used  time.Duration
+}
+
+// Start implements Measurement.
+func (c *CPUTime) Start(context.Context) error {
+	t, err := processCPUTime()
+	c.start = t
+	return err
+}
+
+// Stop implements Measurement.
+func (c *CPUTime) Stop(context.Context) error {
+	t, err := processCPUTime()
+	c.used = t - c.start
+	return err
+}
+
+// Value returns the CPU time used in Unit.
+func (c *CPUTime) Value() float64 {
+	return float64(c.used) / float64(unitOrMillisecond(c.Unit))
+}
+
+// Counter measures a user-defined count, such as cache misses, retries or
+// bytes written: the code under test calls Add, and the value is the amount
+// added between Start and Stop. It is safe for concurrent use.
+type Counter struct {
+	total atomic.Int64
+	start int64
+	delta int64
+}
+
+// Add increments the counter by n.
+func (c *Counter) Add(n int64) {
+	c.total.Add(n)
+}
+
+// Start implements Measurement.
+func (c *Counter) Start(context.Context) error {
+	c.start = c.total.Load()
+	return nil
+}
+
+// Stop implements Measurement.
+func (c *Counter) Stop(context.Context) error {
+	c.delta = c.total.Load() - c.start
+	return nil
+}
+
+// Value returns the amount added during the run.
+func (c *Counter) Value() float64 {
+	return float64(c.delta)
+}
+
+func unitOrMillisecond(unit time.Duration) time.Duration {
+	if unit <= 0 {
+		return time.Millisecond
+	}
+	return unit
+}
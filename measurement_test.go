@@ -0,0 +1,61 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestMeasureWith(t *testing.T) {
+	factors := []ControlFactor{{Name: "Work", Levels: []float64{1, 3}}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	counter := &Counter{}
+	work := func(_ context.Context, trial Trial) error {
+		counter.Add(int64(trial.Control["Work"]))
+		return nil
+	}
+	if err := exp.Run(context.Background(), MeasureWith(counter, work), RunOptions{Repetitions: 2}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, r := range exp.Results {
+		want := r.Trial.Control["Work"]
+		if r.Observations[0] != want || r.Observations[1] != want {
+			t.Errorf("trial %d: observations %v, want %v per run", r.Trial.ID, r.Observations, want)
+		}
+	}
+
+	boom := errors.New("boom")
+	failing := MeasureWith(&WallTime{}, func(context.Context, Trial) error { return boom })
+	if _, err := failing(context.Background(), Trial{}); !errors.Is(err, boom) {
+		t.Errorf("MeasureWith error = %v, want boom", err)
+	}
+}
+
+func TestWallTimeAndCPUTime(t *testing.T) {
+	wall := &WallTime{Unit: time.Microsecond}
+	measure := MeasureWith(wall, func(context.Context, Trial) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	if v, err := measure(context.Background(), Trial{}); err != nil || v < 20000 {
+		t.Errorf("WallTime = %v µs (%v), want at least 20000", v, err)
+	}
+
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("CPU time is only measured on Unix")
+	}
+	cpu := &CPUTime{}
+	measure = MeasureWith(cpu, func(context.Context, Trial) error {
+		for start := time.Now(); time.Since(start) < 30*time.Millisecond; {
+		}
+		return nil
+	})
+	if v, err := measure(context.Background(), Trial{}); err != nil || v < 10 {
+		t.Errorf("CPUTime = %v ms (%v), want at least 10", v, err)
+	}
+}
@@ -0,0 +1,50 @@
+package taguchi
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Secondary responses recorded per measured run by RunOptions.CaptureMemStats.
+// ResponseAllocBytes: Bytes allocated on the heap during the run.
+// ResponseAllocs: Heap objects allocated during the run.
+// ResponseGCCycles: Completed garbage collection cycles during the run.
+// ResponseGCPauseNs: Total stop-the-world GC pause during the run, in nanoseconds.
+// ResponseHeapBytes: Live heap size at the end of the run.
+const (
+	ResponseAllocBytes = "alloc_bytes"
+	ResponseAllocs     = "allocs"
+	ResponseGCCycles   = "gc_cycles"
+	ResponseGCPauseNs  = "gc_pause_ns"
+	ResponseHeapBytes  = "heap_bytes"
+)
+
+// memStatsDelta returns the memory responses between two MemStats snapshots.
+func memStatsDelta(before, after *runtime.MemStats) map[string]float64 {
+	return map[string]float64{
+		ResponseAllocBytes: float64(after.TotalAlloc - before.TotalAlloc),
+		ResponseAllocs:     float64(after.Mallocs - before.Mallocs),
+		ResponseGCCycles:   float64(after.NumGC - before.NumGC),
+		ResponseGCPauseNs:  float64(after.PauseTotalNs - before.PauseTotalNs),
+		ResponseHeapBytes:  float64(after.HeapAlloc),
+	}
+}
+
+// ResponseExperiment returns a copy of the experiment whose observations are
+// the secondary response name of every result, analyzed under goal. It enables
+// multi-response analysis, e.g. comparing the optimum for speed with the
+// optimum for ResponseAllocBytes under SmallerTheBetter. Every result must
+// carry the response.
+func (e *Experiment[P]) ResponseExperiment(name string, goal OptimizationGoal) (*Experiment[P], error) {
+	c := *e
+	c.Goal = goal
+	c.Results = make([]TrialResult, len(e.Results))
+	for i, r := range e.Results {
+		obs, ok := r.Secondary[name]
+		if !ok {
+			return nil, fmt.Errorf("trial %d has no %q response", r.Trial.ID, name)
+		}
+		c.Results[i] = TrialResult{Trial: r.Trial, Observations: obs, Summary: summarize(obs)}
+	}
+	return &c, nil
+}
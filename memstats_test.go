@@ -0,0 +1,51 @@
+package taguchi
+
+import (
+	"context"
+	"testing"
+)
+
+var memStatsSink [][]byte
+
+// TestRun_CaptureMemStats verifies that allocation deltas are recorded per
+// repetition and can be analyzed as their own response.
+func TestRun_CaptureMemStats(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "Size", Levels: []float64{1 << 10, 1 << 20}},
+	}
+	exp, err := NewExperimentFromFactorsUsingArray(LargerTheBetter{}, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		memStatsSink = append(memStatsSink[:0], make([]byte, int(trial.Control["Size"])))
+		return trial.Control["Size"], nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{Repetitions: 3, CaptureMemStats: true}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, r := range exp.Results {
+		allocs := r.Secondary[ResponseAllocBytes]
+		if len(allocs) != 3 {
+			t.Fatalf("trial %d: %d alloc_bytes values, want 3", r.Trial.ID, len(allocs))
+		}
+		if allocs[0] < r.Trial.Control["Size"] {
+			t.Errorf("trial %d: alloc_bytes = %v, want at least %v", r.Trial.ID, allocs[0], r.Trial.Control["Size"])
+		}
+	}
+
+	memory, err := exp.ResponseExperiment(ResponseAllocBytes, SmallerTheBetter{})
+	if err != nil {
+		t.Fatalf("ResponseExperiment: %v", err)
+	}
+	if got := mustAnalyze(t, memory).OptimalLevels["Size"]; got != 1<<10 {
+		t.Errorf("memory optimum Size = %v, want %v", got, 1<<10)
+	}
+	if got := mustAnalyze(t, exp).OptimalLevels["Size"]; got != 1<<20 {
+		t.Errorf("speed optimum Size = %v, want %v", got, 1<<20)
+	}
+	if _, err := exp.ResponseExperiment("missing", SmallerTheBetter{}); err == nil {
+		t.Error("ResponseExperiment accepted an unknown response")
+	}
+}
@@ -0,0 +1,62 @@
+package taguchi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Merge adds the results of another experiment with the same design, e.g.
+// one run on another machine, as replicates of this experiment's trials, so
+// data collected in several places gets a single analysis. See MergeResults.
+func (e *Experiment[P]) Merge(other *Experiment[P]) error {
+	if other == e {
+		return errors.New("cannot merge an experiment into itself")
+	}
+	results, err := other.portableResults()
+	if err != nil {
+		return err
+	}
+	return e.MergeResults(other.Design(), results)
+}
+
+// MergeResults adds results recorded against the design d, e.g. loaded from
+// a checkpoint, bundle or store of another process, as replicates of this
+// experiment's trials. It returns an error wrapping ErrDesignMismatch when d
+// differs from the experiment's design (see CheckDesign) or a result is not
+// a trial of it, and an error when a result lacks some of its raw
+// observations because only a summary or sample was retained; all results
+// are checked before the first is recorded.
+//
+// The results keep their observations, secondary responses, block and
+// metadata and are numbered after the experiment's own results, as if they
+// had been recorded with AppendResult.
+func (e *Experiment[P]) MergeResults(d Design, results []TrialResult) error {
+	if err := e.CheckDesign(d); err != nil {
+		return err
+	}
+	merged := make([]TrialResult, len(results))
+	for i, r := range results {
+		obs, err := r.RawObservations()
+		if err != nil {
+			return fmt.Errorf("result %d (trial %d): %w", i+1, r.Trial.ID, err)
+		}
+		if len(obs) < r.Summary.Count {
+			return fmt.Errorf("result %d (trial %d): raw observations were not all retained, cannot merge", i+1, r.Trial.ID)
+		}
+		r.Trial = e.resolveTrial(r.Trial)
+		if err := e.validateTrial(r.Trial); err != nil {
+			return fmt.Errorf("%w: result %d (trial %d): %v", ErrDesignMismatch, i+1, r.Trial.ID, err)
+		}
+		if err := validateObservations(obs); err != nil {
+			return fmt.Errorf("result %d (trial %d): %w", i+1, r.Trial.ID, err)
+		}
+		r.Observations, r.SpillFile = obs, ""
+		merged[i] = r
+	}
+	for _, r := range merged {
+		if err := e.recordResult(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	newExp := func() *Experiment[struct{}] {
+		factors := []ControlFactor{{Name: "A", Levels: []float64{1, 2}}, {Name: "B", Levels: []float64{1, 2}}}
+		exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}})
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactors: %v", err)
+		}
+		return exp
+	}
+	run := func(exp *Experiment[struct{}], host string, scale float64) {
+		measure := func(ctx context.Context, trial Trial) (float64, error) {
+			return scale * (1 + trial.Control["A"] + 2*trial.Control["B"] + trial.Noise["N"]), nil
+		}
+		opts := RunOptions{Repetitions: 2, Metadata: map[string]string{MetaHostname: host}}
+		if err := exp.Run(context.Background(), measure, opts); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+	first, second := newExp(), newExp()
+	run(first, "a", 1)
+	second.Retention = RetainSpill
+	run(second, "b", 1.1)
+
+	if err := first.Merge(second); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if len(first.Results) != 16 {
+		t.Fatalf("%d results after merging, want 16", len(first.Results))
+	}
+	for i, r := range first.Results {
+		if r.Sequence != i+1 {
+			t.Errorf("result %d has sequence %d", i, r.Sequence)
+		}
+		if host, _ := r.MetadataValue(MetaHostname); (i < 8) != (host == "a") || len(r.Observations) != 2 {
+			t.Errorf("result %d: host %q, observations %v", i, host, r.Observations)
+		}
+	}
+	// The merged runs are replicates whose spread is pure error.
+	if a := mustAnalyze(t, first).ANOVA; a.PureErrorDF != 4 {
+		t.Errorf("pure error DF = %d, want one per row", a.PureErrorDF)
+	}
+	if err := first.Merge(first); err == nil {
+		t.Error("merging an experiment into itself: expected error")
+	}
+}
+
+func TestMergeResults_Errors(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	other := replicatedExperiment(t, "A", "C")
+	results, _ := other.portableResults()
+	if err := exp.MergeResults(other.Design(), results); !errors.Is(err, ErrDesignMismatch) {
+		t.Errorf("MergeResults(other design) = %v, want ErrDesignMismatch", err)
+	}
+
+	// A result whose levels are not those of its row.
+	same := replicatedExperiment(t, "A", "B")
+	results, _ = same.portableResults()
+	results[3].Trial.Control = map[string]float64{"A": 5, "B": 1}
+	n := len(exp.Results)
+	if err := exp.MergeResults(same.Design(), results); !errors.Is(err, ErrDesignMismatch) {
+		t.Errorf("MergeResults(foreign trial) = %v, want ErrDesignMismatch", err)
+	}
+	if len(exp.Results) != n {
+		t.Errorf("%d results recorded by a failed merge", len(exp.Results)-n)
+	}
+
+	summarized := replicatedExperiment(t, "A", "B")
+	summarized.Results = nil
+	summarized.Retention = RetainSummary
+	for _, trial := range summarized.GenerateTrials() {
+		if err := summarized.AddResult(trial, []float64{1, 2}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	if err := exp.Merge(summarized); err == nil || !strings.Contains(err.Error(), "not all retained") {
+		t.Errorf("Merge(summaries) = %v, want an error about the missing observations", err)
+	}
+}
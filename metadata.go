@@ -0,0 +1,116 @@
+package taguchi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Metadata keys filled in by the runners for every result (see RunOptions.Metadata).
+const (
+	// MetaHostname: Name of the machine the trial was measured on.
+	MetaHostname = "hostname"
+	// MetaTimestamp: When the trial's measurement finished, in RFC 3339 format (UTC).
+	MetaTimestamp = "timestamp"
+)
+
+// MetadataColumnPrefix marks the CSV columns holding metadata, e.g.
+// "meta:hostname", in TrialsCSV and AddResultsCSV.
+const MetadataColumnPrefix = "meta:"
+
+// MetadataValue returns the value of a metadata key of the result, looking at
+// the result's own Metadata first and at its trial's second.
+func (r TrialResult) MetadataValue(key string) (string, bool) {
+	if v, ok := r.Metadata[key]; ok {
+		return v, true
+	}
+	v, ok := r.Trial.Metadata[key]
+	return v, ok
+}
+
+// matchesMetadata reports whether the result has every key of where with the
+// given value.
+func (r TrialResult) matchesMetadata(where map[string]string) bool {
+	for key, want := range where {
+		if v, ok := r.MetadataValue(key); !ok || v != want {
+			return false
+		}
+	}
+	return true
+}
+
+// filterResults returns the results matching where, sharing them with results.
+// It is an error when none match, since such an analysis can only fail.
+func filterResults(results []TrialResult, where map[string]string) ([]TrialResult, error) {
+	if len(where) == 0 {
+		return results, nil
+	}
+	var kept []TrialResult
+	for _, r := range results {
+		if r.matchesMetadata(where) {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no results have metadata %s", formatMetadata(where))
+	}
+	return kept, nil
+}
+
+// measurementMetadata returns the metadata recorded with a result measured by
+// a runner: the hostname and the current time, overridden by opts.Metadata.
+func measurementMetadata(opts RunOptions) map[string]string {
+	md := make(map[string]string, len(opts.Metadata)+2)
+	if host, err := os.Hostname(); err == nil {
+		md[MetaHostname] = host
+	}
+	md[MetaTimestamp] = time.Now().UTC().Format(time.RFC3339)
+	for key, v := range opts.Metadata {
+		md[key] = v
+	}
+	return md
+}
+
+// trialMetadata returns the metadata handed to measurement code through
+// TrialInfo: the trial's annotations overridden by opts.Metadata; nil when
+// there are none.
+func trialMetadata(trial Trial, opts RunOptions) map[string]string {
+	if len(trial.Metadata) == 0 && len(opts.Metadata) == 0 {
+		return nil
+	}
+	md := make(map[string]string, len(trial.Metadata)+len(opts.Metadata))
+	for key, v := range trial.Metadata {
+		md[key] = v
+	}
+	for key, v := range opts.Metadata {
+		md[key] = v
+	}
+	return md
+}
+
+// metadataKeys returns the metadata keys used by any of the results or their
+// trials, in ascending order.
+func metadataKeys(results []TrialResult) []string {
+	seen := map[string]bool{}
+	for _, r := range results {
+		for key := range r.Trial.Metadata {
+			seen[key] = true
+		}
+		for key := range r.Metadata {
+			seen[key] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// formatMetadata formats metadata as "key=value" pairs in key order, e.g.
+// "branch=main, hostname=ci-1".
+func formatMetadata(md map[string]string) string {
+	keys := sortedKeys(md)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + md[key]
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,145 @@
+package taguchi
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordHostResults records every trial of a two-factor experiment as
+// measured on two hosts, with host "b" reporting the opposite optimum for A.
+func recordHostResults(t *testing.T, exp *Experiment[struct{}]) {
+	t.Helper()
+	for _, trial := range exp.GenerateTrials() {
+		a, b := trial.Control["A"], trial.Control["B"]
+		for host, y := range map[string]float64{"a": 10*a + b, "b": 30 - 10*a + b} {
+			r := TrialResult{Trial: trial, Observations: []float64{y, 1.1 * y}, Metadata: map[string]string{MetaHostname: host}}
+			if err := exp.addResult(r, true); err != nil {
+				t.Fatalf("recording trial %d on host %s: %v", trial.ID, host, err)
+			}
+		}
+	}
+}
+
+func TestRun_Metadata(t *testing.T) {
+	exp := newTestExperiment(t, testDesign{})
+
+	var seen []map[string]string
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		info, _ := TrialFromContext(ctx)
+		seen = append(seen, info.Metadata)
+		return trial.Control["A"], nil
+	}
+	opts := RunOptions{Metadata: map[string]string{"commit": "abc123", MetaHostname: "bench-1"}}
+	before := time.Now().UTC().Truncate(time.Second)
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, md := range seen {
+		if md["commit"] != "abc123" {
+			t.Fatalf("trial context metadata = %v, want the run's commit", md)
+		}
+	}
+	for _, r := range exp.Results {
+		if r.Metadata["commit"] != "abc123" || r.Metadata[MetaHostname] != "bench-1" {
+			t.Errorf("trial %d: metadata %v, want the run's commit and hostname", r.Trial.ID, r.Metadata)
+		}
+		ts, err := time.Parse(time.RFC3339, r.Metadata[MetaTimestamp])
+		if err != nil || ts.Before(before) {
+			t.Errorf("trial %d: timestamp %q, want a time after %v", r.Trial.ID, r.Metadata[MetaTimestamp], before)
+		}
+	}
+
+	exp.Results[0].Trial.Metadata = map[string]string{"note": "first", "commit": "def456"}
+	if v, ok := exp.Results[0].MetadataValue("note"); !ok || v != "first" {
+		t.Errorf("MetadataValue(note) = %q, %v; want the trial's note", v, ok)
+	}
+	if v, _ := exp.Results[0].MetadataValue("commit"); v != "abc123" {
+		t.Errorf("MetadataValue(commit) = %q, want the result's value to take precedence", v)
+	}
+}
+
+func TestAnalyzeWith_Where(t *testing.T) {
+	exp := newTestExperiment(t, testDesign{})
+	recordHostResults(t, exp)
+	for host, want := range map[string]float64{"a": 1, "b": 2} {
+		result, err := exp.AnalyzeWith(AnalysisOptions{Where: map[string]string{MetaHostname: host}})
+		if err != nil {
+			t.Fatalf("AnalyzeWith(%s): %v", host, err)
+		}
+		if got := result.OptimalLevels["A"]; got != want {
+			t.Errorf("host %s: OptimalLevels[A] = %g, want %g", host, got, want)
+		}
+		if result.Where[MetaHostname] != host {
+			t.Errorf("host %s: Where = %v", host, result.Where)
+		}
+		var report bytes.Buffer
+		if err := WriteAnalysisReport(&report, result, DefaultReportOptions()); err != nil {
+			t.Fatalf("WriteAnalysisReport: %v", err)
+		}
+		if !strings.Contains(report.String(), "results without metadata hostname="+host) {
+			t.Errorf("host %s: report does not mention the filter:\n%s", host, report.String())
+		}
+	}
+	if len(exp.Results) != 8 {
+		t.Errorf("AnalyzeWith changed the results: %d left", len(exp.Results))
+	}
+	if _, err := exp.AnalyzeWith(AnalysisOptions{Where: map[string]string{MetaHostname: "c"}}); err == nil {
+		t.Error("filter matching no results: want error")
+	}
+}
+
+func TestMetadata_CSVRoundTrip(t *testing.T) {
+	exp := newTestExperiment(t, testDesign{})
+	recordHostResults(t, exp)
+	exp.Results[0].Trial.Metadata = map[string]string{"note": "cold start"}
+	var buf bytes.Buffer
+	if err := exp.TrialsCSV(&buf, CSVOptions{}); err != nil {
+		t.Fatalf("TrialsCSV: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	header := strings.Join(records[0], ",")
+	if !strings.HasSuffix(header, ",SNR,meta:hostname,meta:note") {
+		t.Fatalf("header = %s, want metadata columns after SNR", header)
+	}
+	if got := records[1][len(records[1])-2:]; got[0] == "" || got[1] != "cold start" {
+		t.Errorf("first line metadata = %v, want its hostname and note", got)
+	}
+
+	imported := newTestExperiment(t, testDesign{})
+	data := "A,B,y,meta:hostname\n1,1,3,a\n1,2,4,\n2,1,6,b\n2,2,8,b\n"
+	if err := imported.AddResultsCSV(strings.NewReader(data)); err != nil {
+		t.Fatalf("AddResultsCSV: %v", err)
+	}
+	if imported.Results[0].Metadata[MetaHostname] != "a" || imported.Results[1].Metadata != nil {
+		t.Errorf("imported metadata = %v, %v; want hostname a and none", imported.Results[0].Metadata, imported.Results[1].Metadata)
+	}
+	if len(imported.Results[0].Observations) != 1 {
+		t.Errorf("metadata column read as observation: %v", imported.Results[0].Observations)
+	}
+}
+
+func TestMetadata_JSONRoundTrip(t *testing.T) {
+	exp := newTestExperiment(t, testDesign{})
+	recordHostResults(t, exp)
+	exp.Results[0].Trial.Metadata = map[string]string{"note": "cold start"}
+	var buf bytes.Buffer
+	if err := exp.SaveJSON(&buf); err != nil {
+		t.Fatalf("SaveJSON: %v", err)
+	}
+	loaded, err := LoadExperimentJSON(&buf)
+	if err != nil {
+		t.Fatalf("LoadExperimentJSON: %v", err)
+	}
+	r := loaded.Results[0]
+	if r.Metadata[MetaHostname] == "" || r.Trial.Metadata["note"] != "cold start" {
+		t.Errorf("loaded metadata = %v and trial metadata %v", r.Metadata, r.Trial.Metadata)
+	}
+}
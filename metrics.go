@@ -0,0 +1,223 @@
+package taguchi
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets are the upper bounds in seconds of the trial
+// duration histogram when Metrics.Buckets is not set, from benchmark-sized
+// trials to hour-long hardware runs.
+var DefaultDurationBuckets = []float64{0.01, 0.1, 1, 10, 60, 300, 1800, 3600}
+
+// Metrics exposes the progress of runs as Prometheus metrics, so experiments
+// running in CI or on lab machines can be watched on existing dashboards.
+// Pass it in RunOptions.Metrics (or ParallelRunner.Options) and serve it with
+// ServeHTTP on a scrape endpoint, or write it with Write, e.g. for the node
+// exporter's textfile collector. The exported series are:
+//
+//	taguchi_run_in_progress          gauge, 1 while a run is in progress
+//	taguchi_trials_planned           gauge, trials planned for the current run
+//	taguchi_trials_completed_total   counter, trials measured
+//	taguchi_trials_failed_total      counter, trials whose measurement failed
+//	taguchi_trial_duration_seconds   histogram, time to measure a trial with its warmup and repetitions
+//	taguchi_best_snr_db              gauge, highest row SNR of the current run so far
+//	taguchi_best_row                 gauge, one-based array row with that SNR
+//
+// Counters accumulate over the runs sharing a Metrics; the gauges are reset by
+// every run. The zero value is ready to use.
+// Labels: Constant labels added to every series, e.g. {"experiment": "cache-tuning"}.
+// Buckets: Upper bounds in seconds of the duration histogram (DefaultDurationBuckets when nil).
+type Metrics struct {
+	Labels  map[string]string
+	Buckets []float64
+
+	mu        sync.Mutex
+	running   bool
+	planned   int
+	completed int
+	failed    int
+	counts    []int // per bucket, not cumulative
+	sum       float64
+	n         int
+	rowSNR    map[int]float64
+}
+
+// begin resets the gauges for a run of trials with the given options.
+func (m *Metrics) begin(trials []Trial, opts RunOptions) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = true
+	m.planned = len(trials)
+	if opts.RepetitionBudget > 0 {
+		m.planned += max(opts.RepetitionBudget-len(trials)*max(opts.Repetitions, 1), 0)
+	}
+	m.rowSNR = map[int]float64{}
+}
+
+// trialDone records a trial measured in d.
+func (m *Metrics) trialDone(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed++
+	m.planned = max(m.planned, m.completed)
+	buckets := m.buckets()
+	if m.counts == nil {
+		m.counts = make([]int, len(buckets))
+	}
+	sec := d.Seconds()
+	for i, le := range buckets {
+		if sec <= le {
+			m.counts[i]++
+			break
+		}
+	}
+	m.sum += sec
+	m.n++
+}
+
+// trialFailed records a trial whose measurement failed.
+func (m *Metrics) trialFailed() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed++
+}
+
+// rowMeasured records the SNR of an array row over its results so far.
+func (m *Metrics) rowMeasured(row int, snr float64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rowSNR == nil {
+		m.rowSNR = map[int]float64{}
+	}
+	m.rowSNR[row] = snr
+}
+
+// end marks the run as finished.
+func (m *Metrics) end() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = false
+}
+
+// buckets returns the configured histogram bounds or DefaultDurationBuckets.
+func (m *Metrics) buckets() []float64 {
+	if len(m.Buckets) > 0 {
+		return m.Buckets
+	}
+	return DefaultDurationBuckets
+}
+
+// Write writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := formatLabels(m.Labels, "", "")
+	var b strings.Builder
+	metric := func(name, kind, help string, v float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s%s %s\n", name, help, name, kind, name, labels, formatValue(v))
+	}
+
+	running := 0.0
+	if m.running {
+		running = 1
+	}
+	metric("taguchi_run_in_progress", "gauge", "Whether a run is in progress.", running)
+	metric("taguchi_trials_planned", "gauge", "Trials planned for the current run.", float64(m.planned))
+	metric("taguchi_trials_completed_total", "counter", "Trials measured.", float64(m.completed))
+	metric("taguchi_trials_failed_total", "counter", "Trials whose measurement failed.", float64(m.failed))
+
+	const duration = "taguchi_trial_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Time to measure a trial with its warmup and repetitions.\n# TYPE %s histogram\n", duration, duration)
+	cumulative := 0
+	for i, le := range m.buckets() {
+		if i < len(m.counts) {
+			cumulative += m.counts[i]
+		}
+		fmt.Fprintf(&b, "%s_bucket%s %d\n", duration, formatLabels(m.Labels, "le", formatValue(le)), cumulative)
+	}
+	fmt.Fprintf(&b, "%s_bucket%s %d\n", duration, formatLabels(m.Labels, "le", "+Inf"), m.n)
+	fmt.Fprintf(&b, "%s_sum%s %s\n", duration, labels, formatValue(m.sum))
+	fmt.Fprintf(&b, "%s_count%s %d\n", duration, labels, m.n)
+
+	if len(m.rowSNR) > 0 {
+		bestRow, best := -1, math.Inf(-1)
+		for row, snr := range m.rowSNR {
+			if bestRow < 0 || snr > best || (snr == best && row < bestRow) {
+				bestRow, best = row, snr
+			}
+		}
+		metric("taguchi_best_snr_db", "gauge", "Highest row SNR of the current run so far.", best)
+		metric("taguchi_best_row", "gauge", "One-based array row with the highest SNR.", float64(bestRow+1))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the metrics for a Prometheus scrape.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.Write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// formatLabels formats the constant labels, plus the extra label when its
+// name is not empty, as {name="value",...} in name order; it returns an empty
+// string without labels.
+func formatLabels(constant map[string]string, name, value string) string {
+	labels := make(map[string]string, len(constant)+1)
+	for k, v := range constant {
+		labels[k] = v
+	}
+	if name != "" {
+		labels[name] = value
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := sortedKeys(labels)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + `="` + labelEscaper.Replace(labels[k]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatValue formats a sample value, spelling infinities as the exposition
+// format does.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
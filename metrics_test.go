@@ -0,0 +1,96 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRun_Metrics verifies the exposition of a run: counters, the duration
+// histogram and the best row so far, with escaped constant labels.
+func TestRun_Metrics(t *testing.T) {
+	factors := []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	metrics := &Metrics{Labels: map[string]string{"experiment": `a "b"`}, Buckets: []float64{1e-9, 60}}
+	var mid string
+	calls := 0
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		calls++
+		if calls == 3 { // second trial, after the first was recorded
+			var b strings.Builder
+			metrics.Write(&b)
+			mid = b.String()
+		}
+		return trial.Control["A"] + trial.Noise["N"], nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{Metrics: metrics}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(mid, `taguchi_run_in_progress{experiment="a \"b\""} 1`) ||
+		!strings.Contains(mid, `taguchi_trials_completed_total{experiment="a \"b\""} 2`) {
+		t.Errorf("mid-run metrics:\n%s", mid)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	// Row 1 (A = 1) has the smaller observations, hence the better SNR.
+	best := SmallerTheBetter{}.CalculateSNR([]float64{1, 2})
+	label := `{experiment="a \"b\""}`
+	for _, want := range []string{
+		"# TYPE taguchi_trials_completed_total counter",
+		"taguchi_run_in_progress" + label + " 0",
+		"taguchi_trials_planned" + label + " 4",
+		"taguchi_trials_completed_total" + label + " 4",
+		"taguchi_trials_failed_total" + label + " 0",
+		"# TYPE taguchi_trial_duration_seconds histogram",
+		`taguchi_trial_duration_seconds_bucket{experiment="a \"b\"",le="60"} 4`,
+		`taguchi_trial_duration_seconds_bucket{experiment="a \"b\"",le="+Inf"} 4`,
+		"taguchi_trial_duration_seconds_count" + label + " 4",
+		"taguchi_best_snr_db" + label + " " + strconv.FormatFloat(best, 'g', -1, 64),
+		"taguchi_best_row" + label + " 1",
+	} {
+		if !strings.Contains(rec.Body.String(), want+"\n") {
+			t.Errorf("metrics lack %q:\n%s", want, rec.Body.String())
+		}
+	}
+}
+
+func TestRun_MetricsFailure(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	exp.Results = nil
+	metrics := &Metrics{}
+	failure := errors.New("rig offline")
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		if trial.Row == 1 {
+			return 0, failure
+		}
+		return 1, nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{Metrics: metrics}); !errors.Is(err, failure) {
+		t.Fatalf("Run = %v, want the measurement error", err)
+	}
+	var b strings.Builder
+	if err := metrics.Write(&b); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	for _, want := range []string{
+		"taguchi_trials_completed_total 1\n",
+		"taguchi_trials_failed_total 1\n",
+		"taguchi_trial_duration_seconds_bucket{le=\"0.01\"} 1\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+)
+
+// Response is one response variable of a multi-response optimization.
+// Name: Secondary response name (see TrialResult.Secondary); empty for the primary observations.
+// Goal: Optimization goal of the response.
+// Weight: Relative importance (1 when zero); weights are normalized to sum to 1.
+type Response struct {
+	Name   string
+	Goal   OptimizationGoal
+	Weight float64
+}
+
+// label returns the response name used in results.
+func (r Response) label() string {
+	if r.Name == "" {
+		return "primary"
+	}
+	return r.Name
+}
+
+// MultiResponseMethod selects how per-response SNRs are combined into one score per array row.
+type MultiResponseMethod int
+
+const (
+	// WeightedSNR sums the row SNRs in dB with the response weights.
+	WeightedSNR MultiResponseMethod = iota
+	// Desirability scales each response's row SNRs to [0, 1] (worst to best
+	// row) and combines them by weighted geometric mean, so a row that is worst
+	// on any response scores zero.
+	Desirability
+	// GreyRelational scales the row SNRs to [0, 1] and combines the grey
+	// relational coefficients (distinguishing coefficient 0.5) by weighted mean
+	// into the grey relational grade.
+	GreyRelational
+)
+
+// String returns the method name.
+func (m MultiResponseMethod) String() string {
+	switch m {
+	case WeightedSNR:
+		return "weighted SNR"
+	case Desirability:
+		return "desirability"
+	case GreyRelational:
+		return "grey relational"
+	}
+	return fmt.Sprintf("MultiResponseMethod(%d)", int(m))
+}
+
+// MultiResponseResult holds a multi-response optimization.
+// Method: How the responses were combined.
+// OptimalLevels: Levels maximizing the combined score; observe-only factors are omitted.
+// ResponseOptima: Optimal levels of each response on its own, keyed by response name ("primary" for the observations).
+// RowScores: Combined score of each orthogonal array row.
+// MainEffects: Mean combined score per factor level.
+type MultiResponseResult struct {
+	Method         MultiResponseMethod
+	OptimalLevels  map[string]float64
+	ResponseOptima map[string]map[string]float64
+	RowScores      []float64
+	MainEffects    map[string][]float64
+}
+
+// AddResponses records a trial with its primary observations and further
+// named responses, e.g. latency as the observations and memory use under
+// "heap". The named responses are stored in TrialResult.Secondary. The trial
+// and its observations are validated as by AddResult.
+func (e *Experiment[P]) AddResponses(trial Trial, observations []float64, responses map[string][]float64) error {
+	return e.addResult(TrialResult{Trial: trial, Observations: observations, Secondary: responses}, false)
+}
+
+// AnalyzeMultiResponse optimizes several responses at once. Each response is
+// converted to row SNRs under its own goal, the rows are scored by method, and
+// the optimal levels maximize the main effects of that score. The optimum of
+// each response on its own is reported alongside, to show the tradeoff.
+func (e *Experiment[P]) AnalyzeMultiResponse(responses []Response, method MultiResponseMethod) (MultiResponseResult, error) {
+	if len(responses) == 0 {
+		return MultiResponseResult{}, fmt.Errorf("no responses given")
+	}
+	if method < WeightedSNR || method > GreyRelational {
+		return MultiResponseResult{}, fmt.Errorf("unknown multi-response method %d", int(method))
+	}
+	if err := e.checkComplete(); err != nil {
+		return MultiResponseResult{}, err
+	}
+	weights := make([]float64, len(responses))
+	totalWeight := 0.0
+	for i, r := range responses {
+		weights[i] = r.Weight
+		if weights[i] == 0 {
+			weights[i] = 1
+		}
+		if weights[i] < 0 {
+			return MultiResponseResult{}, fmt.Errorf("response %s: negative weight", r.label())
+		}
+		totalWeight += weights[i]
+	}
+
+	res := MultiResponseResult{Method: method, ResponseOptima: map[string]map[string]float64{}}
+	rowSNRs := make([][]float64, len(responses))
+	included := make([]bool, len(e.OrthogonalArray))
+	for i := range included {
+		included[i] = true
+	}
+	for k, r := range responses {
+		sub, err := e.responseExperiment(r)
+		if err != nil {
+			return MultiResponseResult{}, err
+		}
+		rows := sub.computeOASNR()
+		rowSNRs[k] = rows.values
+		for i, in := range rows.included {
+			included[i] = included[i] && in
+		}
+		_, effects, _ := sub.computeANOVA(rows)
+		res.ResponseOptima[r.label()] = sub.findOptimalLevels(effects)
+	}
+
+	scores := oaRowSNR{
+		values:   make([]float64, len(e.OrthogonalArray)),
+		included: included,
+		infinite: make([]bool, len(e.OrthogonalArray)),
+	}
+	scaled := make([][]float64, len(responses))
+	if method != WeightedSNR {
+		for k := range responses {
+			scaled[k] = scaleRows(rowSNRs[k], included)
+		}
+	}
+	n := 0
+	for i := range scores.values {
+		if !included[i] {
+			continue
+		}
+		score := 0.0
+		if method == Desirability {
+			score = 1
+		}
+		for k := range responses {
+			w := weights[k] / totalWeight
+			switch method {
+			case WeightedSNR:
+				score += w * rowSNRs[k][i]
+			case Desirability:
+				score *= math.Pow(scaled[k][i], w)
+			case GreyRelational:
+				score += w * 0.5 / (1 - scaled[k][i] + 0.5)
+			}
+		}
+		scores.values[i] = score
+		scores.grandMean += score
+		n++
+	}
+	if n > 0 {
+		scores.grandMean /= float64(n)
+	}
+
+	_, effects, _ := e.computeANOVA(scores)
+	res.RowScores = scores.values
+	res.MainEffects = effects
+	res.OptimalLevels = e.findOptimalLevels(effects)
+	return res, nil
+}
+
+// responseExperiment returns the experiment analyzing response r.
+func (e *Experiment[P]) responseExperiment(r Response) (*Experiment[P], error) {
+	if r.Goal == nil {
+		return nil, fmt.Errorf("response %s has no goal", r.label())
+	}
+	if r.Name == "" {
+		c := *e
+		c.Goal = r.Goal
+		return &c, nil
+	}
+	return e.ResponseExperiment(r.Name, r.Goal)
+}
+
+// scaleRows maps the included values linearly onto [0, 1], worst to best.
+// When all rows are equal they all scale to 1.
+func scaleRows(values []float64, included []bool) []float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, v := range values {
+		if included[i] {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	out := make([]float64, len(values))
+	for i, v := range values {
+		if hi > lo {
+			out[i] = (v - lo) / (hi - lo)
+		} else {
+			out[i] = 1
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package taguchi
+
+import (
+	"testing"
+)
+
+// TestAnalyzeMultiResponse trades latency (better at A=1) against memory
+// (better at A=2); B only affects latency.
+func TestAnalyzeMultiResponse(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		a, b := trial.Control["A"], trial.Control["B"]
+		latency := 10 * a * b
+		heap := 100 / (a * a * a)
+		exp.AddResponses(trial, []float64{latency, latency * 1.1}, map[string][]float64{"heap": {heap, heap * 1.05}})
+	}
+
+	latencyFirst := []Response{
+		{Goal: SmallerTheBetter{}, Weight: 4},
+		{Name: "heap", Goal: SmallerTheBetter{}, Weight: 1},
+	}
+	// Desirability vetoes the A=1 rows, which have the worst memory use.
+	wantA := map[MultiResponseMethod]float64{WeightedSNR: 1, Desirability: 2, GreyRelational: 1}
+	for _, method := range []MultiResponseMethod{WeightedSNR, Desirability, GreyRelational} {
+		res, err := exp.AnalyzeMultiResponse(latencyFirst, method)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if res.ResponseOptima["primary"]["A"] != 1 || res.ResponseOptima["heap"]["A"] != 2 {
+			t.Errorf("%s: response optima = %v, want A=1 for latency and A=2 for heap", method, res.ResponseOptima)
+		}
+		if res.OptimalLevels["A"] != wantA[method] || res.OptimalLevels["B"] != 1 {
+			t.Errorf("%s: combined optimum = %v, want A=%v B=1", method, res.OptimalLevels, wantA[method])
+		}
+		if method != WeightedSNR {
+			for i, s := range res.RowScores {
+				if s < 0 || s > 1 {
+					t.Errorf("%s: row %d score %.3f outside [0, 1]", method, i+1, s)
+				}
+			}
+		}
+	}
+
+	memoryFirst := []Response{
+		{Goal: SmallerTheBetter{}, Weight: 1},
+		{Name: "heap", Goal: SmallerTheBetter{}, Weight: 4},
+	}
+	res, err := exp.AnalyzeMultiResponse(memoryFirst, WeightedSNR)
+	if err != nil {
+		t.Fatalf("AnalyzeMultiResponse: %v", err)
+	}
+	if res.OptimalLevels["A"] != 2 {
+		t.Errorf("combined optimum = %v, want A=2 with memory weighted 4:1", res.OptimalLevels)
+	}
+
+	if _, err := exp.AnalyzeMultiResponse([]Response{{Name: "cpu", Goal: SmallerTheBetter{}}}, WeightedSNR); err == nil {
+		t.Error("AnalyzeMultiResponse accepted an unknown response")
+	}
+}
@@ -0,0 +1,151 @@
+package noise
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dutyPeriod is the scheduling period of CPULoad's busy/idle cycle.
+const dutyPeriod = 10 * time.Millisecond
+
+// CPULoad keeps CPUs busy. The level is the busy fraction of each worker,
+// from 0 (idle) to 1 (spinning continuously).
+// Workers: Number of spinning goroutines (runtime.GOMAXPROCS(0) when zero).
+type CPULoad struct {
+	Workers int
+}
+
+// Start implements Injector.
+func (c CPULoad) Start(ctx context.Context, level float64) (func(), error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if level > 1 {
+		return nil, fmt.Errorf("CPU load %v exceeds 1", level)
+	}
+	if level == 0 {
+		return func() {}, nil
+	}
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	busy := time.Duration(level * float64(dutyPeriod))
+	return background(ctx, func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for ctx.Err() == nil {
+					start := time.Now()
+					for time.Since(start) < busy {
+					}
+					if idle := dutyPeriod - busy; idle > 0 {
+						time.Sleep(idle)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}), nil
+}
+
+// pageSize is the stride used to touch allocated memory.
+const pageSize = 4096
+
+// MemoryPressure allocates and keeps live a heap block of level MiB for the
+// duration of the trial, raising GC work and RSS. The block is touched page by
+// page so it is actually backed by memory.
+type MemoryPressure struct{}
+
+// Start implements Injector.
+func (MemoryPressure) Start(_ context.Context, level float64) (func(), error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	block := make([]byte, int(level*(1<<20)))
+	for i := 0; i < len(block); i += pageSize {
+		block[i] = 1
+	}
+	return func() {
+		runtime.KeepAlive(block)
+		block = nil
+	}, nil
+}
+
+// churnTick is how often GoroutineChurn starts a batch of goroutines.
+const churnTick = time.Millisecond
+
+// GoroutineChurn starts level short-lived goroutines per second, each doing a
+// small allocation, stressing the scheduler and the allocator.
+type GoroutineChurn struct{}
+
+// Start implements Injector.
+func (GoroutineChurn) Start(ctx context.Context, level float64) (func(), error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if level == 0 {
+		return func() {}, nil
+	}
+	perTick := level * churnTick.Seconds()
+	return background(ctx, func(ctx context.Context) {
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		ticker := time.NewTicker(churnTick)
+		defer ticker.Stop()
+		pending := 0.0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			for pending += perTick; pending >= 1; pending-- {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					runtime.KeepAlive(make([]byte, 64))
+				}()
+			}
+		}
+	}), nil
+}
+
+// Latency injects an artificial delay of level milliseconds into the code
+// under test, which calls Wait wherever the delay belongs (e.g. in a fake
+// dependency). Wait returns immediately while no trial is running. Share a
+// single *Latency between the Injection and the code under test.
+type Latency struct {
+	delay atomic.Int64
+}
+
+// Start implements Injector.
+func (l *Latency) Start(_ context.Context, level float64) (func(), error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	l.delay.Store(int64(level * float64(time.Millisecond)))
+	return func() { l.delay.Store(0) }, nil
+}
+
+// Wait sleeps for the current delay or until ctx is done.
+func (l *Latency) Wait(ctx context.Context) error {
+	d := time.Duration(l.delay.Load())
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package noise
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// diskTick is how often DiskWrites issues a write and sync.
+const diskTick = 10 * time.Millisecond
+
+// DiskWrites writes level MiB per second to a scratch file in the background,
+// syncing after every write so the load reaches the device rather than only
+// the page cache. The file is removed when the noise stops.
+// Dir: Directory of the scratch file (os.TempDir when empty); use the volume the system under test uses.
+// MaxFileSize: Size in bytes at which writing wraps to the start of the file (64 MiB when zero).
+type DiskWrites struct {
+	Dir         string
+	MaxFileSize int64
+}
+
+// Start implements Injector.
+func (d DiskWrites) Start(ctx context.Context, level float64) (func(), error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if level == 0 {
+		return func() {}, nil
+	}
+	f, err := os.CreateTemp(d.Dir, "taguchi-noise-*")
+	if err != nil {
+		return nil, err
+	}
+	limit := d.MaxFileSize
+	if limit <= 0 {
+		limit = 64 << 20
+	}
+	chunk := make([]byte, max(int(level*(1<<20)*diskTick.Seconds()), 1))
+	for i := range chunk {
+		chunk[i] = byte(i)
+	}
+	stop := background(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(diskTick)
+		defer ticker.Stop()
+		var offset int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if offset+int64(len(chunk)) > limit {
+				offset = 0
+			}
+			if _, err := f.WriteAt(chunk, offset); err != nil {
+				return
+			}
+			offset += int64(len(chunk))
+			_ = f.Sync()
+		}
+	})
+	return func() {
+		stop()
+		f.Close()
+		os.Remove(f.Name())
+	}, nil
+}
+
+// Throttle limits the bandwidth of the connections it wraps to level KiB per
+// second, shared by all of them, using a token bucket. Wrap the connections of
+// the system under test with Conn; while no trial is running, or at level
+// zero, traffic is not limited. Share a single *Throttle between the
+// Injection and the code that creates the connections.
+// Burst: Bucket size in bytes, the largest burst sent at full speed (32 KiB when zero).
+type Throttle struct {
+	Burst int
+
+	mu     sync.Mutex
+	rate   float64 // bytes per second; 0 means unlimited
+	tokens float64
+	last   time.Time
+}
+
+// Start implements Injector.
+func (t *Throttle) Start(_ context.Context, level float64) (func(), error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	t.setRate(level * 1024)
+	return func() { t.setRate(0) }, nil
+}
+
+func (t *Throttle) setRate(rate float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rate = rate
+	t.tokens = float64(t.burst())
+	t.last = time.Now()
+}
+
+func (t *Throttle) burst() int {
+	if t.Burst > 0 {
+		return t.Burst
+	}
+	return 32 << 10
+}
+
+// take blocks until n bytes may be transferred. n must not exceed the burst.
+func (t *Throttle) take(n int) {
+	t.mu.Lock()
+	if t.rate == 0 {
+		t.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	t.tokens = min(t.tokens+now.Sub(t.last).Seconds()*t.rate, float64(t.burst()))
+	t.last = now
+	t.tokens -= float64(n)
+	var wait time.Duration
+	if t.tokens < 0 {
+		wait = time.Duration(-t.tokens / t.rate * float64(time.Second))
+	}
+	t.mu.Unlock()
+	time.Sleep(wait)
+}
+
+// Conn returns c with reads and writes limited by the throttle.
+func (t *Throttle) Conn(c net.Conn) net.Conn {
+	return &throttledConn{Conn: c, t: t}
+}
+
+type throttledConn struct {
+	net.Conn
+	t *Throttle
+}
+
+func (c *throttledConn) Read(p []byte) (int, error) {
+	if len(p) > c.t.burst() {
+		p = p[:c.t.burst()]
+	}
+	n, err := c.Conn.Read(p)
+	c.t.take(n)
+	return n, err
+}
+
+func (c *throttledConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), c.t.burst())
+		c.t.take(n)
+		m, err := c.Conn.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,67 @@
+package noise
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestDiskWrites verifies that background writes reach the scratch file and
+// that the file is removed when the noise stops.
+func TestDiskWrites(t *testing.T) {
+	dir := t.TempDir()
+	stop, err := DiskWrites{Dir: dir, MaxFileSize: 1 << 20}.Start(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("scratch files = %v (%v), want one", entries, err)
+	}
+	if info, err := entries[0].Info(); err != nil || info.Size() == 0 {
+		t.Errorf("scratch file is empty")
+	}
+	stop()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("scratch file left behind: %v", entries)
+	}
+}
+
+// TestThrottle verifies that wrapped connections are limited to the injected
+// bandwidth and unlimited otherwise.
+func TestThrottle(t *testing.T) {
+	throttle := &Throttle{Burst: 1024}
+	transfer := func() time.Duration {
+		client, server := net.Pipe()
+		defer client.Close()
+		go func() {
+			io.Copy(io.Discard, server)
+			server.Close()
+		}()
+		start := time.Now()
+		if _, err := throttle.Conn(client).Write(make([]byte, 11<<10)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		return time.Since(start)
+	}
+
+	if d := transfer(); d > 50*time.Millisecond {
+		t.Errorf("unthrottled transfer took %v", d)
+	}
+	stop, err := throttle.Start(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	// 11 KiB at 100 KiB/s with a 1 KiB burst takes about 100ms.
+	if d := transfer(); d < 80*time.Millisecond || d > 500*time.Millisecond {
+		t.Errorf("throttled transfer took %v, want about 100ms", d)
+	}
+	stop()
+	if d := transfer(); d > 50*time.Millisecond {
+		t.Errorf("transfer after stop took %v", d)
+	}
+}
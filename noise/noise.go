@@ -0,0 +1,137 @@
+// Package noise injects controlled noise conditions (CPU load, memory
+// pressure, goroutine churn, artificial latency, disk writes, network
+// bandwidth limits) into in-process experiments.
+// Injectors are keyed by noise factor name and driven by the factor's level,
+// so every trial runs under exactly the condition the design prescribes:
+//
+//	inj := noise.NewInjection(map[string]noise.Injector{
+//		"CPULoad": noise.CPULoad{},
+//		"Heap":    noise.MemoryPressure{},
+//	})
+//	err := exp.Run(ctx, measure, inj.Options(taguchi.RunOptions{Repetitions: 5}))
+package noise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// Injector applies one kind of noise at the given level. Start returns once
+// the noise is running; the returned stop function ends it and blocks until
+// all of its goroutines have exited. A level of zero means no noise.
+type Injector interface {
+	Start(ctx context.Context, level float64) (stop func(), err error)
+}
+
+// Injection applies injectors around every trial. Noise factors without an
+// injector are ignored, so conditions that are applied by the measurement
+// itself (e.g. a data pattern) can be mixed with injected ones.
+type Injection struct {
+	injectors map[string]Injector
+
+	mu     sync.Mutex
+	active map[int][]func()
+}
+
+// NewInjection returns an Injection for the given injectors, keyed by noise factor name.
+func NewInjection(injectors map[string]Injector) *Injection {
+	return &Injection{injectors: injectors, active: map[int][]func(){}}
+}
+
+// Setup starts the noise of every injected factor at the trial's level. It
+// is a taguchi.TrialHook; if an injector fails, the ones already started are
+// stopped again.
+func (in *Injection) Setup(ctx context.Context, trial taguchi.Trial) error {
+	names := make([]string, 0, len(trial.Noise))
+	for name := range trial.Noise {
+		if in.injectors[name] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var stops []func()
+	for _, name := range names {
+		stop, err := in.injectors[name].Start(ctx, trial.Noise[name])
+		if err != nil {
+			stopAll(stops)
+			return fmt.Errorf("inject %s: %w", name, err)
+		}
+		stops = append(stops, stop)
+	}
+	in.mu.Lock()
+	in.active[trial.ID] = stops
+	in.mu.Unlock()
+	return nil
+}
+
+// Teardown stops the noise started for the trial. It is a taguchi.TrialHook.
+func (in *Injection) Teardown(_ context.Context, trial taguchi.Trial) error {
+	in.mu.Lock()
+	stops, ok := in.active[trial.ID]
+	delete(in.active, trial.ID)
+	in.mu.Unlock()
+	if !ok {
+		return errors.New("no noise was started for this trial")
+	}
+	stopAll(stops)
+	return nil
+}
+
+// Options returns opts with the injection wrapped around its trial hooks:
+// noise starts after opts.SetupTrial and stops before opts.TeardownTrial.
+func (in *Injection) Options(opts taguchi.RunOptions) taguchi.RunOptions {
+	setup, teardown := opts.SetupTrial, opts.TeardownTrial
+	opts.SetupTrial = func(ctx context.Context, trial taguchi.Trial) error {
+		if setup != nil {
+			if err := setup(ctx, trial); err != nil {
+				return err
+			}
+		}
+		return in.Setup(ctx, trial)
+	}
+	opts.TeardownTrial = func(ctx context.Context, trial taguchi.Trial) error {
+		err := in.Teardown(ctx, trial)
+		if teardown != nil {
+			err = errors.Join(err, teardown(ctx, trial))
+		}
+		return err
+	}
+	return opts
+}
+
+// stopAll stops injected noise in reverse start order.
+func stopAll(stops []func()) {
+	for i := len(stops) - 1; i >= 0; i-- {
+		stops[i]()
+	}
+}
+
+// background runs fn in a goroutine until the returned stop function is
+// called or ctx is done; stop waits for fn to return.
+func background(ctx context.Context, fn func(ctx context.Context)) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// checkLevel rejects negative and non-finite levels.
+func checkLevel(level float64) error {
+	if !(level >= 0) || math.IsInf(level, 1) {
+		return fmt.Errorf("invalid noise level %v", level)
+	}
+	return nil
+}
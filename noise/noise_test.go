@@ -0,0 +1,103 @@
+package noise
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// TestInjection_Run verifies that the noise condition of each trial is active
+// while it is measured and stopped afterwards.
+func TestInjection_Run(t *testing.T) {
+	factors := []taguchi.ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	noise := []taguchi.NoiseFactor{
+		{Name: "Delay", Levels: []float64{0, 20}},
+		{Name: "Pattern", Levels: []float64{1}},
+	}
+	exp, err := taguchi.NewExperimentFromFactorsUsingArray(taguchi.SmallerTheBetter{}, factors, [][]int{{1}, {2}}, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	latency := &Latency{}
+	inj := NewInjection(map[string]Injector{"Delay": latency})
+	measure := func(ctx context.Context, trial taguchi.Trial) (float64, error) {
+		start := time.Now()
+		if err := latency.Wait(ctx); err != nil {
+			return 0, err
+		}
+		return float64(time.Since(start).Milliseconds()), nil
+	}
+	if err := exp.Run(context.Background(), measure, inj.Options(taguchi.RunOptions{})); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, r := range exp.Results {
+		delay := r.Trial.Noise["Delay"]
+		if got := r.Observations[0]; got < delay || got > delay+15 {
+			t.Errorf("trial %d: measured %vms under %vms delay", r.Trial.ID, got, delay)
+		}
+	}
+	if err := latency.Wait(context.Background()); err != nil || latency.delay.Load() != 0 {
+		t.Error("latency still injected after the run")
+	}
+}
+
+// TestInjection_SetupFailure verifies that noise already started is stopped
+// when a later injector fails.
+func TestInjection_SetupFailure(t *testing.T) {
+	var running atomic.Bool
+	inj := NewInjection(map[string]Injector{
+		"A": injectorFunc(func(context.Context, float64) (func(), error) {
+			running.Store(true)
+			return func() { running.Store(false) }, nil
+		}),
+		"B": CPULoad{},
+	})
+	trial := taguchi.Trial{ID: 1, Noise: map[string]float64{"A": 1, "B": 2}}
+	if err := inj.Setup(context.Background(), trial); err == nil {
+		t.Fatal("Setup accepted a CPU load above 1")
+	}
+	if running.Load() {
+		t.Error("noise A still running after the failed setup")
+	}
+	if err := inj.Teardown(context.Background(), trial); err == nil {
+		t.Error("Teardown succeeded for a trial without noise")
+	}
+}
+
+// TestInjectors_Stop verifies that the background injectors stop cleanly.
+func TestInjectors_Stop(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for _, c := range []struct {
+		name  string
+		inj   Injector
+		level float64
+	}{
+		{"CPULoad", CPULoad{Workers: 2}, 0.5},
+		{"MemoryPressure", MemoryPressure{}, 1},
+		{"GoroutineChurn", GoroutineChurn{}, 5000},
+	} {
+		stop, err := c.inj.Start(context.Background(), c.level)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+		stop()
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines left running after stop", after-before)
+	}
+	if _, err := (MemoryPressure{}).Start(context.Background(), -1); err == nil {
+		t.Error("MemoryPressure accepted a negative level")
+	}
+}
+
+type injectorFunc func(ctx context.Context, level float64) (func(), error)
+
+func (f injectorFunc) Start(ctx context.Context, level float64) (func(), error) {
+	return f(ctx, level)
+}
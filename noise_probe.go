@@ -0,0 +1,68 @@
+package taguchi
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// NoiseProbe measures the noise level actually realized during a trial, e.g.
+// the CPU utilization achieved by a load generator, so trials where the
+// intended condition was not reached can be flagged.
+type NoiseProbe func(ctx context.Context, trial Trial) (float64, error)
+
+// NoiseDeviation is a trial whose probed noise level missed the intended one
+// by more than the noise factor's Tolerance.
+// TrialID: ID of the trial.
+// Factor: Noise factor concerned.
+// Intended, Realized: The designed level and the probe reading.
+type NoiseDeviation struct {
+	TrialID  int
+	Factor   string
+	Intended float64
+	Realized float64
+}
+
+// probeNoise reads the probes of all noise factors that have one.
+func (e *Experiment[P]) probeNoise(ctx context.Context, trial Trial) (map[string]float64, error) {
+	var readings map[string]float64
+	for _, factor := range e.NoiseFactors {
+		if factor.Probe == nil {
+			continue
+		}
+		v, err := factor.Probe(ctx, trial)
+		if err != nil {
+			return nil, fmt.Errorf("trial %d probe %s: %w", trial.ID, factor.Name, err)
+		}
+		if readings == nil {
+			readings = map[string]float64{}
+		}
+		readings[factor.Name] = v
+	}
+	return readings, nil
+}
+
+// UnrealizedNoise returns the trials whose probe readings deviate from the
+// intended noise level by more than the factor's Tolerance, in result order.
+// Their observations were taken under a different condition than designed.
+func (e *Experiment[P]) UnrealizedNoise() []NoiseDeviation {
+	var deviations []NoiseDeviation
+	for _, r := range e.Results {
+		for _, factor := range e.NoiseFactors {
+			realized, ok := r.NoiseReadings[factor.Name]
+			if !ok || factor.Tolerance <= 0 {
+				continue
+			}
+			intended := r.Trial.Noise[factor.Name]
+			if math.Abs(realized-intended) > factor.Tolerance {
+				deviations = append(deviations, NoiseDeviation{
+					TrialID:  r.Trial.ID,
+					Factor:   factor.Name,
+					Intended: intended,
+					Realized: realized,
+				})
+			}
+		}
+	}
+	return deviations
+}
@@ -0,0 +1,298 @@
+package taguchi
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+)
+
+// NoiseSamplingMethod selects how noise conditions are chosen for each
+// orthogonal array row.
+type NoiseSamplingMethod int
+
+const (
+	// FullNoiseCrossing runs every combination of noise factor levels (the default).
+	FullNoiseCrossing NoiseSamplingMethod = iota
+	// StratifiedNoiseSampling splits the budget evenly across the levels of the
+	// first noise factor and samples the remaining factors without replacement
+	// within each stratum.
+	StratifiedNoiseSampling
+	// LatinHypercubeNoiseSampling builds the sample column by column so that every
+	// level of every noise factor appears as evenly as the budget allows, with
+	// no noise condition sampled twice.
+	LatinHypercubeNoiseSampling
+	// OuterArrayNoise runs the rows of an outer orthogonal array over the noise
+	// factors, giving the classic crossed inner/outer array design (see SetOuterArray).
+	OuterArrayNoise
+)
+
+// NoiseSampling configures sampling of the noise space when full crossing is too expensive.
+// Method: Sampling strategy; FullNoiseCrossing disables sampling.
+// Budget: Number of noise conditions run per orthogonal array row.
+// Seed: Seed for the pseudo-random sampler, making the design reproducible.
+// OuterArray: Outer array for OuterArrayNoise; each of its rows is one noise condition.
+// OuterColumns: Outer array column (zero-based) assigned to each noise factor; nil assigns factor i to column i.
+// The same sampled noise conditions are used for every row so that row SNRs stay comparable.
+type NoiseSampling struct {
+	Method       NoiseSamplingMethod
+	Budget       int
+	Seed         int64
+	OuterArray   [][]int
+	OuterColumns []int
+}
+
+// SetOuterArray assigns the noise factors to the columns of an outer
+// orthogonal array, e.g. L4 for three 2-level noise factors. Every inner
+// array row is then crossed with the outer array's rows instead of with all
+// noise level combinations, and each row SNR aggregates the observations of
+// its outer rows. Noise factors are assigned like control factors (see
+// AssignColumns).
+func (e *Experiment[P]) SetOuterArray(name ArrayType) error {
+	oa, ok := StandardArrays[name]
+	if !ok {
+		return errorf(ErrUnknownArray, "orthogonal array %s not defined", name)
+	}
+	factors := make([]ControlFactor, len(e.NoiseFactors))
+	for i, n := range e.NoiseFactors {
+		factors[i] = ControlFactor{Name: n.Name, Levels: n.Levels}
+	}
+	columns, err := AssignColumns(factors, oa)
+	if err != nil {
+		return fmt.Errorf("outer array %s: %w", name, err)
+	}
+	e.NoiseSampling = NoiseSampling{Method: OuterArrayNoise, OuterArray: oa, OuterColumns: columns}
+	return nil
+}
+
+// noiseSet is the sampled noise conditions of a design, cached with the
+// noise factors and sampling they were drawn for.
+type noiseSet struct {
+	factors    []NoiseFactor
+	sampling   NoiseSampling
+	conditions []Trial
+}
+
+// noiseConditions returns the noise conditions of the design, the full
+// crossing of the noise factors sampled down by sampleNoise. They are drawn
+// once and cached until NoiseFactors or NoiseSampling change. The result is
+// shared and must not be modified; use sampledNoise for trials handed out.
+func (e *Experiment[P]) noiseConditions() []Trial {
+	if e.noise == nil || !e.noise.matches(e.NoiseFactors, e.NoiseSampling) {
+		s := e.NoiseSampling
+		s.OuterArray = slices.Clone(s.OuterArray)
+		s.OuterColumns = slices.Clone(s.OuterColumns)
+		factors := make([]NoiseFactor, len(e.NoiseFactors))
+		for i, f := range e.NoiseFactors {
+			factors[i] = NoiseFactor{Name: f.Name, Levels: slices.Clone(f.Levels)}
+		}
+		e.noise = &noiseSet{factors: factors, sampling: s, conditions: e.sampleNoise(e.generateNoiseCombinations())}
+	}
+	return e.noise.conditions
+}
+
+// sampledNoise returns a copy of noiseConditions whose noise levels the
+// caller may keep in its trials.
+func (e *Experiment[P]) sampledNoise() []Trial {
+	conditions := slices.Clone(e.noiseConditions())
+	for i := range conditions {
+		conditions[i].Noise = maps.Clone(conditions[i].Noise)
+	}
+	return conditions
+}
+
+// matches reports whether the set was drawn for the given noise factors and
+// sampling.
+func (n *noiseSet) matches(factors []NoiseFactor, s NoiseSampling) bool {
+	c := n.sampling
+	if c.Method != s.Method || c.Budget != s.Budget || c.Seed != s.Seed ||
+		!slices.Equal(c.OuterColumns, s.OuterColumns) || !slices.EqualFunc(c.OuterArray, s.OuterArray, slices.Equal[[]int]) {
+		return false
+	}
+	return slices.EqualFunc(n.factors, factors, func(a, b NoiseFactor) bool {
+		return a.Name == b.Name && slices.Equal(a.Levels, b.Levels)
+	})
+}
+
+// sampleNoise reduces the full set of noise combinations to the configured budget.
+func (e *Experiment[P]) sampleNoise(combinations []Trial) []Trial {
+	s := e.NoiseSampling
+	if s.Method == OuterArrayNoise && len(s.OuterArray) > 0 && len(e.NoiseFactors) > 0 {
+		return e.outerArrayNoise()
+	}
+	if s.Method == FullNoiseCrossing || s.Budget <= 0 || s.Budget >= len(combinations) || len(e.NoiseFactors) == 0 {
+		return combinations
+	}
+	rng := rand.New(rand.NewSource(s.Seed))
+
+	var sampled []Trial
+	switch s.Method {
+	case StratifiedNoiseSampling:
+		sampled = e.stratifiedNoise(combinations, s.Budget, rng)
+	case LatinHypercubeNoiseSampling:
+		sampled = e.latinHypercubeNoise(s.Budget, rng)
+	default:
+		return combinations
+	}
+	for i := range sampled {
+		sampled[i].ID = i + 1
+	}
+	return sampled
+}
+
+// stratifiedNoise allocates the budget evenly across the levels of the first
+// noise factor, handing the remainder to randomly chosen strata, and draws
+// combinations without replacement inside each stratum.
+func (e *Experiment[P]) stratifiedNoise(combinations []Trial, budget int, rng *rand.Rand) []Trial {
+	primary := e.NoiseFactors[0]
+	strata := make([][]Trial, len(primary.Levels))
+	for _, c := range combinations {
+		for li, level := range primary.Levels {
+			if c.Noise[primary.Name] == level {
+				strata[li] = append(strata[li], c)
+				break
+			}
+		}
+	}
+
+	quota := make([]int, len(strata))
+	for i := range quota {
+		quota[i] = budget / len(strata)
+	}
+	for _, i := range rng.Perm(len(strata))[:budget%len(strata)] {
+		quota[i]++
+	}
+
+	var sampled []Trial
+	for i, stratum := range strata {
+		n := quota[i]
+		if n > len(stratum) {
+			n = len(stratum)
+		}
+		for _, idx := range rng.Perm(len(stratum))[:n] {
+			sampled = append(sampled, stratum[idx])
+		}
+	}
+	return sampled
+}
+
+// latinHypercubeNoise builds budget distinct noise conditions where each
+// factor's column is a shuffled, balanced repetition of its levels. A row
+// repeating an earlier condition is repaired by swapping one of its levels
+// with another row, which keeps every column balanced; a row no swap can
+// repair is replaced by a random unused combination. The budget is smaller
+// than the number of combinations, so every row can be made distinct.
+func (e *Experiment[P]) latinHypercubeNoise(budget int, rng *rand.Rand) []Trial {
+	factors := e.NoiseFactors
+	rows := make([][]int, budget)
+	for i := range rows {
+		rows[i] = make([]int, len(factors))
+	}
+	for f, factor := range factors {
+		column := make([]int, budget)
+		for i := range column {
+			column[i] = i % len(factor.Levels)
+		}
+		rng.Shuffle(len(column), func(i, j int) {
+			column[i], column[j] = column[j], column[i]
+		})
+		for i, level := range column {
+			rows[i][f] = level
+		}
+	}
+
+	// code numbers a combination of level indices, the last factor fastest.
+	code := func(row []int) int {
+		c := 0
+		for f, level := range row {
+			c = c*len(factors[f].Levels) + level
+		}
+		return c
+	}
+	used := map[int]int{}
+	for _, row := range rows {
+		used[code(row)]++
+	}
+	swapped := func(row []int, f, level int) int {
+		old := row[f]
+		row[f] = level
+		c := code(row)
+		row[f] = old
+		return c
+	}
+	for _, row := range rows {
+		if used[code(row)] == 1 {
+			continue
+		}
+		repaired := false
+		for _, f := range rng.Perm(len(factors)) {
+			for _, j := range rng.Perm(budget) {
+				other := rows[j]
+				if other[f] == row[f] {
+					continue
+				}
+				ci, cj := swapped(row, f, other[f]), swapped(other, f, row[f])
+				if used[ci] > 0 || used[cj] > 0 || ci == cj {
+					continue
+				}
+				used[code(row)]--
+				used[code(other)]--
+				row[f], other[f] = other[f], row[f]
+				used[ci]++
+				used[cj]++
+				repaired = true
+				break
+			}
+			if repaired {
+				break
+			}
+		}
+		if repaired {
+			continue
+		}
+		total := 1
+		for _, factor := range factors {
+			total *= len(factor.Levels)
+		}
+		for _, c := range rng.Perm(total) {
+			if used[c] > 0 {
+				continue
+			}
+			used[code(row)]--
+			used[c]++
+			for f := len(factors) - 1; f >= 0; f-- {
+				row[f] = c % len(factors[f].Levels)
+				c /= len(factors[f].Levels)
+			}
+			break
+		}
+	}
+
+	sampled := make([]Trial, budget)
+	for i, row := range rows {
+		noise := make(map[string]float64, len(factors))
+		for f, factor := range factors {
+			noise[factor.Name] = factor.Levels[row[f]]
+		}
+		sampled[i].Noise = noise
+	}
+	return sampled
+}
+
+// outerArrayNoise returns one noise condition per outer array row.
+func (e *Experiment[P]) outerArrayNoise() []Trial {
+	s := e.NoiseSampling
+	conditions := make([]Trial, len(s.OuterArray))
+	for i, row := range s.OuterArray {
+		noise := make(map[string]float64, len(e.NoiseFactors))
+		for j, factor := range e.NoiseFactors {
+			c := j
+			if s.OuterColumns != nil {
+				c = s.OuterColumns[j]
+			}
+			noise[factor.Name] = factor.Levels[row[c]-1]
+		}
+		conditions[i] = Trial{ID: i + 1, Noise: noise}
+	}
+	return conditions
+}
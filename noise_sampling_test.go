@@ -0,0 +1,190 @@
+package taguchi
+
+import "testing"
+
+// TestGenerateTrials_NoiseSamplingBudget verifies that both sampling methods
+// respect the budget and keep noise levels balanced.
+func TestGenerateTrials_NoiseSamplingBudget(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1}, {2}}
+	noise := []NoiseFactor{
+		{Name: "N1", Levels: []float64{0, 1, 2}},
+		{Name: "N2", Levels: []float64{0, 1, 2}},
+		{Name: "N3", Levels: []float64{0, 1, 2}},
+	}
+
+	for _, method := range []NoiseSamplingMethod{StratifiedNoiseSampling, LatinHypercubeNoiseSampling} {
+		exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, noise)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+		}
+		exp.NoiseSampling = NoiseSampling{Method: method, Budget: 6, Seed: 42}
+
+		trials := exp.GenerateTrials()
+		if len(trials) != 12 {
+			t.Fatalf("method %d: expected 12 trials (2 rows x 6 noise conditions), got %d", method, len(trials))
+		}
+
+		counts := map[float64]int{}
+		for _, trial := range trials[:6] {
+			counts[trial.Noise["N1"]]++
+		}
+		for level, n := range counts {
+			if n != 2 {
+				t.Errorf("method %d: N1 level %v sampled %d times, want 2", method, level, n)
+			}
+		}
+	}
+}
+
+// TestSetOuterArray verifies the crossed inner/outer layout: every inner row
+// runs the same outer array rows, and each row SNR covers all of them.
+func TestSetOuterArray(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	noise := []NoiseFactor{
+		{Name: "N1", Levels: []float64{0, 1}},
+		{Name: "N2", Levels: []float64{0, 1}},
+		{Name: "N3", Levels: []float64{0, 1}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	if err := exp.SetOuterArray(L4); err != nil {
+		t.Fatalf("SetOuterArray: %v", err)
+	}
+
+	trials := exp.GenerateTrials()
+	if len(trials) != 16 {
+		t.Fatalf("got %d trials, want 16 (4 inner x 4 outer rows)", len(trials))
+	}
+	for i, trial := range trials[:4] {
+		for j, factor := range noise {
+			if want := factor.Levels[StandardArrays[L4][i][j]-1]; trial.Noise[factor.Name] != want {
+				t.Errorf("outer row %d: %s = %v, want %v", i+1, factor.Name, trial.Noise[factor.Name], want)
+			}
+		}
+	}
+	for _, trial := range trials {
+		exp.AddResult(trial, []float64{1 + trial.Noise["N1"] + trial.Control["A"]})
+	}
+	rows := exp.computeOASNR()
+	if want := exp.Goal.CalculateSNR([]float64{2, 2, 3, 3}); !almostEqual(rows.values[0], want) {
+		t.Errorf("row 1 SNR = %.4f, want %.4f", rows.values[0], want)
+	}
+
+	if err := exp.SetOuterArray(L9); err == nil {
+		t.Error("SetOuterArray accepted 2-level noise factors on L9")
+	}
+}
+
+// TestLatinHypercubeNoise_Distinct verifies that Latin hypercube sampling
+// never repeats a noise condition, for budgets up to one below the number of
+// combinations, while keeping the levels of every factor balanced.
+func TestLatinHypercubeNoise_Distinct(t *testing.T) {
+	binary := []NoiseFactor{
+		{Name: "N1", Levels: []float64{0, 1}},
+		{Name: "N2", Levels: []float64{0, 1}},
+		{Name: "N3", Levels: []float64{0, 1}},
+	}
+	mixed := []NoiseFactor{
+		{Name: "N1", Levels: []float64{0, 1, 2}},
+		{Name: "N2", Levels: []float64{0, 1}},
+	}
+	designs := []struct {
+		noise   []NoiseFactor
+		budgets []int
+	}{
+		{binary, []int{2, 4, 6, 7}},
+		{mixed, []int{3, 4, 5}},
+	}
+	for _, d := range designs {
+		for _, budget := range d.budgets {
+			for seed := int64(0); seed < 200; seed++ {
+				exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}, [][]int{{1}, {2}}, d.noise)
+				if err != nil {
+					t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+				}
+				exp.NoiseSampling = NoiseSampling{Method: LatinHypercubeNoiseSampling, Budget: budget, Seed: seed}
+				conditions := exp.sampleNoise(exp.generateNoiseCombinations())
+				if len(conditions) != budget {
+					t.Fatalf("budget %d, seed %d: got %d conditions", budget, seed, len(conditions))
+				}
+				seen := map[string]bool{}
+				counts := map[string]map[float64]int{}
+				for _, c := range conditions {
+					key := TrialKey(nil, c.Noise)
+					if seen[key] {
+						t.Errorf("%d factors, budget %d, seed %d: duplicate condition %v", len(d.noise), budget, seed, c.Noise)
+					}
+					seen[key] = true
+					for name, level := range c.Noise {
+						if counts[name] == nil {
+							counts[name] = map[float64]int{}
+						}
+						counts[name][level]++
+					}
+				}
+				for _, f := range d.noise {
+					for _, level := range f.Levels {
+						if n, want := counts[f.Name][level], budget/len(f.Levels); n < want || n > want+1 {
+							t.Errorf("%d factors, budget %d, seed %d: %s level %v sampled %d times", len(d.noise), budget, seed, f.Name, level, n)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestNoiseConditions_Cache verifies that the sampled noise conditions are
+// drawn once for recording results, and drawn again once the noise factors
+// or the sampling change.
+func TestNoiseConditions_Cache(t *testing.T) {
+	noise := []NoiseFactor{
+		{Name: "N1", Levels: []float64{0, 1, 2}},
+		{Name: "N2", Levels: []float64{0, 1, 2}},
+	}
+	exp := newTestExperiment(t, testDesign{Noise: noise})
+	exp.NoiseSampling = NoiseSampling{Method: LatinHypercubeNoiseSampling, Budget: 3, Seed: 1}
+	trials := exp.GenerateTrials()
+	cached := exp.noise
+	for _, trial := range trials[:2] {
+		if err := exp.AddResult(trial, []float64{1}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+	if exp.noise != cached {
+		t.Error("AddResult drew the noise conditions again")
+	}
+	// Trials handed out do not share their noise levels with the cache.
+	trials[0].Noise["N1"] = 99
+	if exp.noiseConditions()[0].Noise["N1"] == 99 {
+		t.Error("modifying a generated trial changed the cached noise conditions")
+	}
+
+	// Another seed samples other conditions.
+	exp.NoiseSampling.Seed = 2
+	resampled := exp.GenerateTrials()
+	if exp.noise == cached {
+		t.Fatal("the noise conditions were not drawn again for the new seed")
+	}
+	for _, trial := range resampled {
+		if err := exp.validateTrial(trial); err != nil {
+			t.Errorf("trial %d of the new sample: %v", trial.ID, err)
+		}
+	}
+	exp.NoiseSampling = NoiseSampling{}
+	exp.NoiseFactors[1].Levels[2] = 5
+	if n := len(exp.GenerateTrials()); n != 4*9 {
+		t.Errorf("%d trials after disabling sampling, want 36", n)
+	}
+	if err := exp.validateTrial(Trial{Row: 0, Control: trials[2].Control, Noise: map[string]float64{"N1": 0, "N2": 5}}); err != nil {
+		t.Errorf("trial with the changed noise level: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+)
+
+// checkNoiseWeights validates the Weights of the noise factors: one finite,
+// non-negative weight per level with a positive total. Weighted SNRs are
+// computed from observation summaries, so the goal must implement SummaryGoal;
+// a nil goal, as for designs that are only estimated, skips that check.
+func checkNoiseWeights(goal OptimizationGoal, factors []NoiseFactor) error {
+	for _, nf := range factors {
+		if nf.Weights == nil {
+			continue
+		}
+		if len(nf.Weights) != len(nf.Levels) {
+			return errorf(ErrLevelMismatch, "noise factor %s has %d levels but %d weights", nf.Name, len(nf.Levels), len(nf.Weights))
+		}
+		total := 0.0
+		for _, w := range nf.Weights {
+			if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+				return fmt.Errorf("noise factor %s: weight %g is not a finite non-negative number", nf.Name, w)
+			}
+			total += w
+		}
+		if total == 0 {
+			return fmt.Errorf("noise factor %s: weights sum to zero", nf.Name)
+		}
+		if _, ok := goal.(SummaryGoal); !ok && goal != nil {
+			return fmt.Errorf("noise factor %s: noise weights are not supported for %s experiments", nf.Name, goal)
+		}
+	}
+	return nil
+}
+
+// noiseWeighted reports whether any noise factor carries weights.
+func noiseWeighted(factors []NoiseFactor) bool {
+	for _, nf := range factors {
+		if nf.Weights != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionWeight returns the probability of a noise condition: the product
+// of the normalized weights of its levels, as the noise factors are taken to
+// vary independently. Unweighted factors and levels that are not levels of
+// their factor weigh as one level of an evenly weighted factor.
+func (e *Experiment[P]) conditionWeight(noise map[string]float64) float64 {
+	p := 1.0
+	for _, nf := range e.NoiseFactors {
+		if nf.Weights == nil || len(nf.Weights) != len(nf.Levels) {
+			continue
+		}
+		total, w := 0.0, 1.0/float64(len(nf.Levels))
+		for _, v := range nf.Weights {
+			total += v
+		}
+		for i, level := range nf.Levels {
+			if level == noise[nf.Name] {
+				w = nf.Weights[i] / total
+				break
+			}
+		}
+		p *= w
+	}
+	return p
+}
+
+// weightedSummary merges the summaries of results so that every noise
+// condition contributes its weight's share of the observations, however many
+// observations it has. Count stays the total number of observations, so the
+// summary-based SNRs see weighted means of the same sample size.
+func (e *Experiment[P]) weightedSummary(results []TrialResult) ObservationSummary {
+	counts := map[string]int{}
+	weights := map[string]float64{}
+	var count int
+	for _, r := range results {
+		label := noiseLabel(r.Trial.Noise)
+		counts[label] += r.Summary.Count
+		weights[label] = e.conditionWeight(r.Trial.Noise)
+		count += r.Summary.Count
+	}
+	total := 0.0
+	for label, w := range weights {
+		if counts[label] > 0 {
+			total += w
+		}
+	}
+	s := ObservationSummary{Count: count}
+	if total == 0 {
+		return s
+	}
+	for _, r := range results {
+		label := noiseLabel(r.Trial.Noise)
+		if counts[label] == 0 {
+			continue
+		}
+		// Each observation stands for its condition's share of the count.
+		f := weights[label] / total * float64(count) / float64(counts[label])
+		s.Sum += f * r.Summary.Sum
+		s.SumSquares += f * r.Summary.SumSquares
+		s.SumInverseSquares += f * r.Summary.SumInverseSquares
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package taguchi
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// weightedDesign is a two-row experiment whose level 1 is fast at Load=0
+// but slow at Load=1 and whose level 2 is moderate under both. Callers set
+// the Load noise factor with its weights.
+var weightedDesign = testDesign{
+	Factors: namedFactors([]float64{1, 2}, "A"),
+	Rows:    [][]int{{1}, {2}},
+	Response: singleResult(func(trial Trial) []float64 {
+		return map[[2]float64][]float64{
+			{1, 0}: {1, 1}, {1, 1}: {9},
+			{2, 0}: {4}, {2, 1}: {4, 4, 4},
+		}[[2]float64{trial.Control["A"], trial.Noise["Load"]}]
+	}),
+}
+
+func TestNoiseWeights_SNR(t *testing.T) {
+	for _, c := range []struct {
+		weights []float64
+		snr1    float64 // SNR of A=1
+		best    float64
+	}{
+		// Unweighted, every observation counts once: mean y² = (1+1+81)/3.
+		{nil, -10 * math.Log10(83.0/3), 2},
+		// Each condition counts by its weight however often it was measured.
+		{[]float64{1, 1}, -10 * math.Log10(0.5*1+0.5*81), 2},
+		{[]float64{0.9, 0.1}, -10 * math.Log10(0.9*1+0.1*81), 1},
+	} {
+		d := weightedDesign
+		d.Noise = []NoiseFactor{{Name: "Load", Levels: []float64{0, 1}, Weights: c.weights}}
+		exp := newTestExperiment(t, d)
+		rows := exp.computeOASNR()
+		if !almostEqual(rows.values[0], c.snr1) {
+			t.Errorf("weights %v: SNR of A=1 is %g, want %g", c.weights, rows.values[0], c.snr1)
+		}
+		if !almostEqual(rows.values[1], -10*math.Log10(16)) {
+			t.Errorf("weights %v: SNR of A=2 is %g, want %g", c.weights, rows.values[1], -10*math.Log10(16))
+		}
+		if got := mustAnalyze(t, exp).OptimalLevels["A"]; got != c.best {
+			t.Errorf("weights %v: optimal A = %g, want %g", c.weights, got, c.best)
+		}
+	}
+}
+
+func TestNoiseWeights_SummaryRetention(t *testing.T) {
+	d := weightedDesign
+	d.Noise = []NoiseFactor{{Name: "Load", Levels: []float64{0, 1}, Weights: []float64{0.9, 0.1}}}
+	exp := newTestExperiment(t, d)
+	want := exp.computeOASNR().values
+	for i := range exp.Results {
+		exp.Results[i].Observations = nil
+	}
+	got := exp.computeOASNR().values
+	for i := range want {
+		if !almostEqual(got[i], want[i]) {
+			t.Errorf("row %d: SNR from summaries %g, want %g", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestNoiseWeights_Validation(t *testing.T) {
+	factors := []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	for _, c := range []struct {
+		goal    OptimizationGoal
+		weights []float64
+		want    string
+	}{
+		{SmallerTheBetter{}, []float64{1}, "2 levels but 1 weights"},
+		{SmallerTheBetter{}, []float64{1, -1}, "not a finite non-negative number"},
+		{SmallerTheBetter{}, []float64{0, 0}, "sum to zero"},
+		{OperatingWindow{}, []float64{1, 2}, "not supported"},
+	} {
+		noise := []NoiseFactor{{Name: "Load", Levels: []float64{0, 1}, Weights: c.weights}}
+		_, err := NewExperimentFromFactors(c.goal, factors, L4, noise)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("weights %v: err = %v, want %q", c.weights, err, c.want)
+		}
+	}
+	noise := []NoiseFactor{{Name: "Load", Levels: []float64{0, 1}, Weights: []float64{1}}}
+	if _, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, noise); !errors.Is(err, ErrLevelMismatch) {
+		t.Errorf("err = %v, want ErrLevelMismatch", err)
+	}
+}
+
+func TestLevelDistribution(t *testing.T) {
+	d := levelDistribution(NoiseFactor{Levels: []float64{0, 10}, Weights: []float64{3, 1}})
+	if !almostEqual(d.Mean, 2.5) || !almostEqual(d.SD, math.Sqrt(18.75)) {
+		t.Errorf("weighted distribution %+v, want mean 2.5 and SD %g", d, math.Sqrt(18.75))
+	}
+	if d := levelDistribution(NoiseFactor{Levels: []float64{0, 10}}); d.Mean != 5 || d.SD != 5 {
+		t.Errorf("unweighted distribution %+v, want 5±5", d)
+	}
+}
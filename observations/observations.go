@@ -0,0 +1,165 @@
+// Package observations provides built-in collectors that record several
+// responses of every measured run without hand-instrumentation: wall time,
+// CPU time, heap allocations and GC pauses. Each collector is recorded as a
+// secondary response under its name and can be analyzed on its own with
+// Experiment.ResponseExperiment:
+//
+//	opts := taguchi.RunOptions{Repetitions: 5, Collectors: observations.Default()}
+//	err := exp.Run(ctx, measure, opts)
+//	...
+//	allocs, err := exp.ResponseExperiment(observations.NameAllocs, taguchi.SmallerTheBetter{})
+//
+// Collectors hold the state of the run in progress, so a collector must not be
+// shared between concurrent runs; the parallel runner does not accept them.
+package observations
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// Response names of the built-in collectors.
+// NameWallTime: Elapsed wall-clock time of the run.
+// NameCPUTime: CPU time used by the process during the run.
+// NameAllocs, NameAllocBytes, NameGCPauseNs: As taguchi.ResponseAllocs, taguchi.ResponseAllocBytes and taguchi.ResponseGCPauseNs.
+const (
+	NameWallTime   = "wall_time"
+	NameCPUTime    = "cpu_time"
+	NameAllocs     = taguchi.ResponseAllocs
+	NameAllocBytes = taguchi.ResponseAllocBytes
+	NameGCPauseNs  = taguchi.ResponseGCPauseNs
+)
+
+// Default returns a fresh set of all built-in collectors, with times in
+// milliseconds. CPU time is left out on platforms that cannot measure it.
+func Default() []taguchi.Collector {
+	collectors := []taguchi.Collector{&WallTime{}}
+	if cpuTimeSupported() {
+		collectors = append(collectors, &CPUTime{})
+	}
+	return append(collectors, &Allocs{}, &AllocBytes{}, &GCPause{})
+}
+
+// WallTime records the elapsed wall-clock time of every run as NameWallTime,
+// in the Unit of the embedded taguchi.WallTime.
+type WallTime struct {
+	taguchi.WallTime
+}
+
+// Name implements taguchi.Collector.
+func (*WallTime) Name() string { return NameWallTime }
+
+// CPUTime records the user plus system CPU time of the process during every
+// run as NameCPUTime. It is read from the operating system rather than from
+// runtime/metrics, whose CPU classes are only updated at garbage collections
+// and so read zero for most runs. Concurrent work outside the code under test
+// is included. The value is in the Unit of the embedded taguchi.CPUTime. It is
+// only supported on Unix systems.
+type CPUTime struct {
+	taguchi.CPUTime
+}
+
+// Name implements taguchi.Collector.
+func (*CPUTime) Name() string { return NameCPUTime }
+
+func cpuTimeSupported() bool {
+	var c CPUTime
+	return c.Start(context.Background()) == nil
+}
+
+// memStatsDelta is the change of one runtime.MemStats counter over a run.
+type memStatsDelta struct {
+	read   func(*runtime.MemStats) uint64
+	start  uint64
+	change uint64
+}
+
+func (d *memStatsDelta) begin() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	d.start = d.read(&ms)
+}
+
+func (d *memStatsDelta) end() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	d.change = d.read(&ms) - d.start
+}
+
+// Allocs records the heap objects allocated during every run, the allocs/op
+// of a benchmark whose operation is one run, as NameAllocs. Allocations
+// by other goroutines are included, since the statistics are process-wide.
+type Allocs struct {
+	d memStatsDelta
+}
+
+// Name implements taguchi.Collector.
+func (*Allocs) Name() string { return NameAllocs }
+
+// Start implements taguchi.Measurement.
+func (a *Allocs) Start(context.Context) error {
+	a.d.read = func(ms *runtime.MemStats) uint64 { return ms.Mallocs }
+	a.d.begin()
+	return nil
+}
+
+// Stop implements taguchi.Measurement.
+func (a *Allocs) Stop(context.Context) error {
+	a.d.end()
+	return nil
+}
+
+// Value returns the number of allocations.
+func (a *Allocs) Value() float64 { return float64(a.d.change) }
+
+// AllocBytes records the bytes allocated on the heap during every run as
+// NameAllocBytes. Like Allocs, it includes other goroutines.
+type AllocBytes struct {
+	d memStatsDelta
+}
+
+// Name implements taguchi.Collector.
+func (*AllocBytes) Name() string { return NameAllocBytes }
+
+// Start implements taguchi.Measurement.
+func (a *AllocBytes) Start(context.Context) error {
+	a.d.read = func(ms *runtime.MemStats) uint64 { return ms.TotalAlloc }
+	a.d.begin()
+	return nil
+}
+
+// Stop implements taguchi.Measurement.
+func (a *AllocBytes) Stop(context.Context) error {
+	a.d.end()
+	return nil
+}
+
+// Value returns the allocated bytes.
+func (a *AllocBytes) Value() float64 { return float64(a.d.change) }
+
+// GCPause records the total stop-the-world garbage collection pause during
+// every run, in nanoseconds, as NameGCPauseNs.
+type GCPause struct {
+	d memStatsDelta
+}
+
+// Name implements taguchi.Collector.
+func (*GCPause) Name() string { return NameGCPauseNs }
+
+// Start implements taguchi.Measurement.
+func (g *GCPause) Start(context.Context) error {
+	g.d.read = func(ms *runtime.MemStats) uint64 { return ms.PauseTotalNs }
+	g.d.begin()
+	return nil
+}
+
+// Stop implements taguchi.Measurement.
+func (g *GCPause) Stop(context.Context) error {
+	g.d.end()
+	return nil
+}
+
+// Value returns the pause time in nanoseconds.
+func (g *GCPause) Value() float64 { return float64(g.d.change) }
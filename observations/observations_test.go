@@ -0,0 +1,104 @@
+package observations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+var sink [][]byte
+
+// TestDefault_RecordsSecondaryResponses verifies that every built-in
+// collector is recorded per repetition and tracks the work of the run.
+func TestDefault_RecordsSecondaryResponses(t *testing.T) {
+	factors := []taguchi.ControlFactor{
+		{Name: "Size", Levels: []float64{1 << 10, 1 << 20}},
+	}
+	exp, err := taguchi.NewExperimentFromFactorsUsingArray(taguchi.LargerTheBetter{}, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	measure := func(_ context.Context, trial taguchi.Trial) (float64, error) {
+		for i := 0; i < 4; i++ {
+			sink = append(sink[:0], make([]byte, int(trial.Control["Size"])))
+		}
+		time.Sleep(time.Millisecond)
+		return trial.Control["Size"], nil
+	}
+	collectors := Default()
+	if err := exp.Run(context.Background(), measure, taguchi.RunOptions{Repetitions: 3, Collectors: collectors}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, r := range exp.Results {
+		for _, c := range collectors {
+			if n := len(r.Secondary[c.Name()]); n != 3 {
+				t.Errorf("trial %d: %d %s values, want 3", r.Trial.ID, n, c.Name())
+			}
+		}
+		if got := r.Secondary[NameAllocBytes][0]; got < 4*r.Trial.Control["Size"] {
+			t.Errorf("trial %d: %s = %g, want at least %g", r.Trial.ID, NameAllocBytes, got, 4*r.Trial.Control["Size"])
+		}
+		if got := r.Secondary[NameAllocs][0]; got < 4 {
+			t.Errorf("trial %d: %s = %g, want at least 4", r.Trial.ID, NameAllocs, got)
+		}
+		if got := r.Secondary[NameWallTime][0]; got < 1 {
+			t.Errorf("trial %d: %s = %g ms, want at least 1", r.Trial.ID, NameWallTime, got)
+		}
+	}
+
+	memory, err := exp.ResponseExperiment(NameAllocBytes, taguchi.SmallerTheBetter{})
+	if err != nil {
+		t.Fatalf("ResponseExperiment: %v", err)
+	}
+	result, err := memory.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if got := result.OptimalLevels["Size"]; got != 1<<10 {
+		t.Errorf("memory optimum Size = %g, want %d", got, 1<<10)
+	}
+}
+
+type failingCollector struct{ taguchi.Counter }
+
+func (*failingCollector) Name() string               { return "failing" }
+func (*failingCollector) Stop(context.Context) error { return errors.New("stop failed") }
+
+// TestCollectors_Errors verifies that collector failures and clashing
+// response names stop the run.
+func TestCollectors_Errors(t *testing.T) {
+	factors := []taguchi.ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	newExp := func() *taguchi.Experiment[struct{}] {
+		exp, err := taguchi.NewExperimentFromFactorsUsingArray(taguchi.LargerTheBetter{}, factors, [][]int{{1}, {2}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exp
+	}
+	measure := func(context.Context, taguchi.Trial) (float64, error) { return 1, nil }
+
+	exp := newExp()
+	if err := exp.Run(context.Background(), measure, taguchi.RunOptions{Collectors: []taguchi.Collector{&failingCollector{}}}); err == nil {
+		t.Error("Run succeeded with a failing collector")
+	}
+	if len(exp.Results) != 0 {
+		t.Errorf("recorded %d results, want none", len(exp.Results))
+	}
+
+	opts := taguchi.RunOptions{Collectors: []taguchi.Collector{&Allocs{}}, CaptureMemStats: true}
+	if err := newExp().Run(context.Background(), measure, opts); err == nil {
+		t.Error("Run accepted a collector named like a memory statistics response")
+	}
+	opts = taguchi.RunOptions{Collectors: []taguchi.Collector{&WallTime{}, &WallTime{}}}
+	if err := newExp().Run(context.Background(), measure, opts); err == nil {
+		t.Error("Run accepted two collectors with the same name")
+	}
+	runner := taguchi.ParallelRunner[struct{}]{Experiment: newExp(), Workers: 2, Options: taguchi.RunOptions{Collectors: Default()}}
+	if err := runner.Run(context.Background(), measure); err == nil {
+		t.Error("ParallelRunner accepted collectors")
+	}
+}
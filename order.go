@@ -0,0 +1,136 @@
+package taguchi
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// RunOrder configures the order in which trials are executed. Running the
+// array in row order confounds drift over time (warm-up, thermal throttling,
+// wear) with the factors assigned to slowly changing columns; randomizing
+// the order turns drift into noise instead.
+// Randomize: Shuffle the order returned by GenerateTrials and executed by Run.
+// KeepRows: With Randomize, shuffle the rows and the noise conditions within each row but run each row's
+// trials together, for hard-to-change control factors (restricted randomization).
+// Seed: Seed of the shuffle, making the order reproducible.
+// Blocks: Number of replicate blocks run by Run (1 when zero). Each block measures every trial once, in its
+// own random order, and its results are marked with TrialResult.Block.
+type RunOrder struct {
+	Randomize bool
+	KeepRows  bool
+	Seed      int64
+	Blocks    int
+}
+
+// blocks returns the number of replicate blocks.
+func (o RunOrder) blocks() int {
+	return max(o.Blocks, 1)
+}
+
+// orderTrials returns trials, given in array order, in the run order of the
+// given replicate block. Every block is shuffled with its own seed.
+func (e *Experiment[P]) orderTrials(trials []Trial, block int) []Trial {
+	o := e.RunOrder
+	if !o.Randomize {
+		return trials
+	}
+	rng := rand.New(rand.NewSource(o.Seed + int64(block)))
+	if !o.KeepRows {
+		rng.Shuffle(len(trials), func(i, j int) { trials[i], trials[j] = trials[j], trials[i] })
+		return trials
+	}
+	rows := groupByRow(trials)
+	rng.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+	ordered := make([]Trial, 0, len(trials))
+	for _, row := range rows {
+		rng.Shuffle(len(row), func(i, j int) { row[i], row[j] = row[j], row[i] })
+		ordered = append(ordered, row...)
+	}
+	return ordered
+}
+
+// groupByRow groups trials by array row, in row order.
+func groupByRow(trials []Trial) [][]Trial {
+	byRow := map[int][]Trial{}
+	var order []int
+	for _, t := range trials {
+		if _, ok := byRow[t.Row]; !ok {
+			order = append(order, t.Row)
+		}
+		byRow[t.Row] = append(byRow[t.Row], t)
+	}
+	sort.Ints(order)
+	rows := make([][]Trial, len(order))
+	for i, r := range order {
+		rows[i] = byRow[r]
+	}
+	return rows
+}
+
+// TimeTrend is a test for drift of the response over the run order. Each
+// result's mean response is taken relative to the mean of its array row, which
+// removes the control factor effects, and the residuals are regressed on
+// TrialResult.Sequence.
+// Slope: Change of the response per run.
+// Correlation: Partial correlation between the residuals and the run order.
+// P: p-value of the slope's t-test.
+// Significant: Whether P is below the experiment's Alpha.
+// Runs: Number of results in the test; rows with a single result carry no information and are left out.
+type TimeTrend struct {
+	Slope       float64
+	Correlation float64
+	P           float64
+	Significant bool
+	Runs        int
+}
+
+// timeTrend tests the results for drift over the run order. It returns nil
+// when there are too few replicated results to test.
+func (e *Experiment[P]) timeTrend() *TimeTrend {
+	byRow := map[int][]TrialResult{}
+	for _, r := range e.Results {
+		if r.Summary.Count > 0 {
+			byRow[r.Trial.Row] = append(byRow[r.Trial.Row], r)
+		}
+	}
+	var xs, ys []float64
+	groups := 0
+	for _, results := range byRow {
+		if len(results) < 2 {
+			continue
+		}
+		groups++
+		mx, my := 0.0, 0.0
+		for _, r := range results {
+			mx += float64(r.Sequence)
+			my += r.Summary.Sum / float64(r.Summary.Count)
+		}
+		mx /= float64(len(results))
+		my /= float64(len(results))
+		for _, r := range results {
+			xs = append(xs, float64(r.Sequence)-mx)
+			ys = append(ys, r.Summary.Sum/float64(r.Summary.Count)-my)
+		}
+	}
+	df := len(xs) - groups - 1
+	if df < 1 {
+		return nil
+	}
+	sxx, syy, sxy := 0.0, 0.0, 0.0
+	for i := range xs {
+		sxx += xs[i] * xs[i]
+		syy += ys[i] * ys[i]
+		sxy += xs[i] * ys[i]
+	}
+	if sxx == 0 || syy == 0 {
+		return nil
+	}
+	r := sxy / math.Sqrt(sxx*syy)
+	t := &TimeTrend{Slope: sxy / sxx, Correlation: r, Runs: len(xs)}
+	if r2 := r * r; r2 < 1 {
+		t.P = fSurvival(r2*float64(df)/(1-r2), 1, df)
+	}
+	t.Significant = t.P < e.alpha()
+	return t
+}
@@ -0,0 +1,75 @@
+package taguchi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateTrials_RunOrder(t *testing.T) {
+	exp := parallelExperiment(t)
+	inOrder := exp.GenerateTrials()
+
+	exp.RunOrder = RunOrder{Randomize: true, Seed: 7}
+	shuffled := exp.GenerateTrials()
+	if reflect.DeepEqual(shuffled, inOrder) {
+		t.Fatal("randomized order equals array order")
+	}
+	if again := exp.GenerateTrials(); !reflect.DeepEqual(again, shuffled) {
+		t.Error("same seed produced a different order")
+	}
+	seen := map[int]bool{}
+	for _, trial := range shuffled {
+		seen[trial.ID] = true
+	}
+	if len(seen) != len(inOrder) {
+		t.Errorf("randomized order has %d distinct trials, want %d", len(seen), len(inOrder))
+	}
+
+	exp.RunOrder.KeepRows = true
+	rows := map[int]bool{}
+	trials := exp.GenerateTrials()
+	for i, trial := range trials {
+		if i > 0 && trial.Row != trials[i-1].Row && rows[trial.Row] {
+			t.Fatalf("row %d split up by KeepRows: %+v", trial.Row, trials)
+		}
+		rows[trial.Row] = true
+	}
+}
+
+func TestRun_BlocksAndTimeTrend(t *testing.T) {
+	exp := parallelExperiment(t)
+	exp.RunOrder = RunOrder{Randomize: true, Seed: 1, Blocks: 2}
+	exp.AnalyzeTimeTrend = true
+
+	// The response creeps up with every run, independent of the factors.
+	calls := 0
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		calls++
+		return 10*trial.Control["A"] + float64(calls), nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(exp.Results) != 16 {
+		t.Fatalf("len(Results) = %d, want 8 trials in each of 2 blocks", len(exp.Results))
+	}
+	perBlock := map[int]int{}
+	for i, r := range exp.Results {
+		perBlock[r.Block]++
+		if r.Sequence != i+1 {
+			t.Errorf("result %d has Sequence %d", i, r.Sequence)
+		}
+	}
+	if perBlock[0] != 8 || perBlock[1] != 8 {
+		t.Errorf("results per block = %v, want 8 each", perBlock)
+	}
+
+	result := mustAnalyze(t, exp)
+	if result.TimeTrend == nil || !result.TimeTrend.Significant || !almostEqual(result.TimeTrend.Slope, 1) {
+		t.Fatalf("TimeTrend = %+v, want a significant slope of 1 per run", result.TimeTrend)
+	}
+	if !result.HasWarning(WarnTimeTrend) {
+		t.Errorf("expected %s warning, got %v", WarnTimeTrend, result.Warnings)
+	}
+}
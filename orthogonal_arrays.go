@@ -1,15 +1,28 @@
 package taguchi
 
+// ArrayType names a standard orthogonal array.
 type ArrayType string
 
 const (
-	L4  ArrayType = "L4"
-	L8  ArrayType = "L8"
-	L9  ArrayType = "L9"
-	L16 ArrayType = "L16"
-	L18 ArrayType = "L18"
+	L4  ArrayType = "L4"  // 2^3
+	L8  ArrayType = "L8"  // 2^7
+	L9  ArrayType = "L9"  // 3^4
+	L12 ArrayType = "L12" // 2^11 (Plackett-Burman)
+	L16 ArrayType = "L16" // 2^15
+	L18 ArrayType = "L18" // 2^1 × 3^7
+	L25 ArrayType = "L25" // 5^6
+	L27 ArrayType = "L27" // 3^13
+	L32 ArrayType = "L32" // 2^31
+	L36 ArrayType = "L36" // 2^11 × 3^12
+	L50 ArrayType = "L50" // 2^1 × 5^11
+	L54 ArrayType = "L54" // 2^1 × 3^25
+	L64 ArrayType = "L64" // 2^63
 )
 
+// StandardArrays holds the catalog of standard orthogonal arrays with 1-based
+// levels. Small arrays are spelled out; larger ones are generated from their
+// Galois-field or difference-scheme construction, which yields Taguchi's
+// standard column order for the prime-power arrays.
 var StandardArrays = map[ArrayType][][]int{
 	L4: {
 		{1, 1, 1},
@@ -38,42 +51,52 @@ var StandardArrays = map[ArrayType][][]int{
 		{3, 2, 1, 3},
 		{3, 3, 2, 1},
 	},
-	L16: {
-		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2},
-		{1, 1, 2, 2, 1, 1, 2, 2, 2, 2, 1, 1, 1, 1, 2},
-		{1, 1, 2, 2, 2, 2, 1, 1, 1, 1, 2, 2, 2, 2, 1},
-		{1, 2, 1, 2, 1, 2, 1, 2, 2, 1, 2, 1, 2, 1, 2},
-		{1, 2, 1, 2, 2, 1, 2, 1, 1, 2, 1, 2, 1, 2, 1},
-		{1, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2, 1},
-		{1, 2, 2, 1, 2, 1, 1, 2, 2, 1, 1, 2, 2, 1, 2},
-		{2, 1, 1, 2, 1, 2, 2, 1, 2, 1, 1, 2, 2, 1, 1},
-		{2, 1, 1, 2, 2, 1, 1, 2, 1, 2, 2, 1, 1, 2, 2},
-		{2, 1, 2, 1, 1, 2, 1, 2, 1, 2, 2, 1, 2, 1, 1},
-		{2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 1, 2, 1, 2, 2},
-		{2, 2, 1, 2, 1, 1, 2, 2, 1, 1, 2, 2, 1, 2, 1},
-		{2, 2, 1, 2, 2, 2, 1, 1, 2, 2, 1, 1, 2, 1, 2},
-		{2, 2, 2, 1, 1, 1, 1, 2, 2, 2, 1, 2, 2, 1, 2},
-		{2, 2, 2, 1, 2, 2, 2, 1, 1, 1, 2, 1, 1, 2, 1},
-	},
-	L18: {
-		{1, 1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 2, 2, 2, 2, 2, 2},
-		{1, 1, 3, 3, 3, 3, 3, 3},
-		{1, 2, 1, 1, 2, 2, 3, 3},
-		{1, 2, 2, 2, 3, 3, 1, 1},
-		{1, 2, 3, 3, 1, 1, 2, 2},
-		{1, 3, 1, 2, 1, 3, 2, 3},
-		{1, 3, 2, 3, 2, 1, 3, 1},
-		{1, 3, 3, 1, 3, 2, 1, 2},
-		{2, 1, 1, 3, 3, 2, 2, 1},
-		{2, 1, 2, 1, 1, 3, 3, 2},
-		{2, 1, 3, 2, 2, 1, 1, 3},
-		{2, 2, 1, 2, 3, 1, 3, 2},
-		{2, 2, 2, 3, 1, 2, 1, 3},
-		{2, 2, 3, 1, 2, 3, 2, 1},
-		{2, 3, 1, 3, 2, 3, 1, 2},
-		{2, 3, 2, 1, 3, 1, 2, 3},
-		{2, 3, 3, 2, 1, 2, 3, 1},
-	},
+	L12: l12Rows,
+	L16: primePowerArray(2, 4),
+	L18: l18Rows,
+	L25: primePowerArray(5, 2),
+	L27: primePowerArray(3, 3),
+	L32: primePowerArray(2, 5),
+	L36: buildL36(),
+	L50: buildL50(),
+	L54: buildL54(),
+	L64: primePowerArray(2, 6),
+}
+
+// l12Rows is the Plackett-Burman design in Taguchi's L12 row order.
+var l12Rows = [][]int{
+	{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	{1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2},
+	{1, 1, 2, 2, 2, 1, 1, 1, 2, 2, 2},
+	{1, 2, 1, 2, 2, 1, 2, 2, 1, 1, 2},
+	{1, 2, 2, 1, 2, 2, 1, 2, 1, 2, 1},
+	{1, 2, 2, 2, 1, 2, 2, 1, 2, 1, 1},
+	{2, 1, 2, 2, 1, 1, 2, 2, 1, 2, 1},
+	{2, 1, 2, 1, 2, 2, 2, 1, 1, 1, 2},
+	{2, 1, 1, 2, 2, 2, 1, 2, 2, 1, 1},
+	{2, 2, 2, 1, 1, 1, 1, 2, 2, 1, 2},
+	{2, 2, 1, 2, 1, 2, 1, 1, 1, 2, 2},
+	{2, 2, 1, 1, 2, 1, 2, 1, 2, 2, 1},
+}
+
+// l18Rows is the mixed-level L18 (2^1 × 3^7) array.
+var l18Rows = [][]int{
+	{1, 1, 1, 1, 1, 1, 1, 1},
+	{1, 1, 2, 2, 2, 2, 2, 2},
+	{1, 1, 3, 3, 3, 3, 3, 3},
+	{1, 2, 1, 1, 2, 2, 3, 3},
+	{1, 2, 2, 2, 3, 3, 1, 1},
+	{1, 2, 3, 3, 1, 1, 2, 2},
+	{1, 3, 1, 2, 1, 3, 2, 3},
+	{1, 3, 2, 3, 2, 1, 3, 1},
+	{1, 3, 3, 1, 3, 2, 1, 2},
+	{2, 1, 1, 3, 3, 2, 2, 1},
+	{2, 1, 2, 1, 1, 3, 3, 2},
+	{2, 1, 3, 2, 2, 1, 1, 3},
+	{2, 2, 1, 2, 3, 1, 3, 2},
+	{2, 2, 2, 3, 1, 2, 1, 3},
+	{2, 2, 3, 1, 2, 3, 2, 1},
+	{2, 3, 1, 3, 2, 3, 1, 2},
+	{2, 3, 2, 1, 3, 1, 2, 3},
+	{2, 3, 3, 2, 1, 2, 3, 1},
 }
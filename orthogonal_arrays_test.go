@@ -0,0 +1,70 @@
+package taguchi
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestStandardArrays_Orthogonal verifies that every pair of columns in every
+// catalog array contains each level combination equally often.
+func TestStandardArrays_Orthogonal(t *testing.T) {
+	for _, info := range StandardArrayInfos() {
+		oa := StandardArrays[info.Name]
+		for a := 0; a < info.MaxFactors; a++ {
+			for b := a + 1; b < info.MaxFactors; b++ {
+				counts := map[[2]int]int{}
+				for _, row := range oa {
+					counts[[2]int{row[a], row[b]}]++
+				}
+				want := info.Runs / (info.Levels[a] * info.Levels[b])
+				if len(counts) != info.Levels[a]*info.Levels[b] {
+					t.Errorf("%s columns %d,%d: %d level combinations, want %d", info.Name, a+1, b+1, len(counts), info.Levels[a]*info.Levels[b])
+					continue
+				}
+				for combo, n := range counts {
+					if n != want {
+						t.Errorf("%s columns %d,%d: combination %v occurs %d times, want %d", info.Name, a+1, b+1, combo, n, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestStandardArrays_Info verifies the level structure reported for the catalog.
+func TestStandardArrays_Info(t *testing.T) {
+	tests := map[ArrayType]string{
+		L4:  "2^3",
+		L12: "2^11",
+		L18: "2^1 × 3^7",
+		L25: "5^6",
+		L27: "3^13",
+		L36: "2^11 × 3^12",
+		L50: "2^1 × 5^11",
+		L54: "2^1 × 3^25",
+		L64: "2^63",
+	}
+	for name, want := range tests {
+		info, ok := LookupArray(name)
+		if !ok {
+			t.Fatalf("LookupArray(%s) not found", name)
+		}
+		if got := info.Notation(); got != want {
+			t.Errorf("%s notation: got %s, want %s", name, got, want)
+		}
+	}
+}
+
+// TestPrimePowerArray_MatchesStandardTables verifies that the generator
+// reproduces the spelled-out standard tables, column order included.
+func TestPrimePowerArray_MatchesStandardTables(t *testing.T) {
+	if got := primePowerArray(2, 2); !reflect.DeepEqual(got, StandardArrays[L4]) {
+		t.Errorf("primePowerArray(2, 2) != L4: %v", got)
+	}
+	if got := primePowerArray(2, 3); !reflect.DeepEqual(got, StandardArrays[L8]) {
+		t.Errorf("primePowerArray(2, 3) != L8: %v", got)
+	}
+	if got := primePowerArray(3, 2); !reflect.DeepEqual(got, StandardArrays[L9]) {
+		t.Errorf("primePowerArray(3, 2) != L9: %v", got)
+	}
+}
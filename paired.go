@@ -0,0 +1,127 @@
+package taguchi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PairedMode selects how two back-to-back measurements are combined into one observation.
+type PairedMode int
+
+const (
+	// PairedDifference records a - b.
+	PairedDifference PairedMode = iota
+	// PairedRatio records a / b.
+	PairedRatio
+)
+
+// String returns the human-readable name for the paired mode.
+func (m PairedMode) String() string {
+	switch m {
+	case PairedDifference:
+		return "difference"
+	case PairedRatio:
+		return "ratio"
+	default:
+		return fmt.Sprintf("PairedMode(%d)", int(m))
+	}
+}
+
+// combine merges a single pair of measurements. A ratio to a zero baseline
+// is undefined and rejected with ErrInvalidObservations.
+func (m PairedMode) combine(a, b float64) (float64, error) {
+	if m == PairedRatio {
+		if b == 0 {
+			return 0, errorf(ErrInvalidObservations, "paired ratio with a zero baseline measurement (a = %g)", a)
+		}
+		return a / b, nil
+	}
+	return a - b, nil
+}
+
+// PairObservations combines two equally long series of measurements taken on
+// the same noise condition into paired observations.
+func PairObservations(a, b []float64, mode PairedMode) ([]float64, error) {
+	if len(a) != len(b) {
+		return nil, errorf(ErrInvalidObservations, "paired observations require equal lengths, got %d and %d", len(a), len(b))
+	}
+	obs := make([]float64, len(a))
+	for i := range a {
+		y, err := mode.combine(a[i], b[i])
+		if err != nil {
+			return nil, fmt.Errorf("pair %d: %w", i+1, err)
+		}
+		obs[i] = y
+	}
+	return obs, nil
+}
+
+// MeasurePaired runs the two variants back to back repetitions times and
+// returns the paired observations. The execution order alternates (AB, BA, ...)
+// so that drift during the run does not favour either variant. It stops at
+// the first measurement that fails, the first pair that cannot be combined
+// and when ctx ends.
+func MeasurePaired(ctx context.Context, repetitions int, mode PairedMode, a, b func(context.Context) (float64, error)) ([]float64, error) {
+	obs := make([]float64, repetitions)
+	for i := range obs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		y, err := measurePair(ctx, mode, a, b, i%2 == 1)
+		if err != nil {
+			return nil, fmt.Errorf("repetition %d: %w", i+1, err)
+		}
+		obs[i] = y
+	}
+	return obs, nil
+}
+
+// PairedMeasureFunc adapts two variants to a MeasureFunc for Run and
+// ParallelRunner: every run of a trial measures both variants back to back
+// and returns their combined observation. The order alternates between the
+// runs of each trial as in MeasurePaired.
+func PairedMeasureFunc(mode PairedMode, a, b MeasureFunc) MeasureFunc {
+	var mu sync.Mutex
+	runs := map[string]int{}
+	return func(ctx context.Context, trial Trial) (float64, error) {
+		mu.Lock()
+		n := runs[trial.Key]
+		runs[trial.Key]++
+		mu.Unlock()
+		bind := func(f MeasureFunc) func(context.Context) (float64, error) {
+			return func(ctx context.Context) (float64, error) { return f(ctx, trial) }
+		}
+		return measurePair(ctx, mode, bind(a), bind(b), n%2 == 1)
+	}
+}
+
+// measurePair measures a and b, b first when reversed, and combines them.
+func measurePair(ctx context.Context, mode PairedMode, a, b func(context.Context) (float64, error), reversed bool) (float64, error) {
+	var va, vb float64
+	var err error
+	if reversed {
+		if vb, err = b(ctx); err != nil {
+			return 0, fmt.Errorf("variant b: %w", err)
+		}
+	}
+	if va, err = a(ctx); err != nil {
+		return 0, fmt.Errorf("variant a: %w", err)
+	}
+	if !reversed {
+		if vb, err = b(ctx); err != nil {
+			return 0, fmt.Errorf("variant b: %w", err)
+		}
+	}
+	return mode.combine(va, vb)
+}
+
+// AddPairedResult records a trial whose observations are the paired
+// difference or ratio of two variants measured on the same noise condition.
+func (e *Experiment[P]) AddPairedResult(trial Trial, a, b []float64, mode PairedMode) error {
+	obs, err := PairObservations(a, b, mode)
+	if err != nil {
+		return &TrialError{Trial: trial.ID, Err: err}
+	}
+	return e.AddResult(trial, obs)
+}
@@ -0,0 +1,113 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestPairObservations verifies both paired modes and that a ratio to a zero
+// baseline or series of different lengths are rejected.
+func TestPairObservations(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		mode PairedMode
+		want []float64
+		err  error
+	}{
+		{"difference", []float64{5, 3}, []float64{2, 4}, PairedDifference, []float64{3, -1}, nil},
+		{"difference with zero baseline", []float64{5}, []float64{0}, PairedDifference, []float64{5}, nil},
+		{"ratio", []float64{6, 3}, []float64{2, 4}, PairedRatio, []float64{3, 0.75}, nil},
+		{"ratio with zero baseline", []float64{6, 3}, []float64{2, 0}, PairedRatio, nil, ErrInvalidObservations},
+		{"unequal lengths", []float64{1, 2}, []float64{1}, PairedDifference, nil, ErrInvalidObservations},
+	}
+	for _, tt := range tests {
+		got, err := PairObservations(tt.a, tt.b, tt.mode)
+		if !errors.Is(err, tt.err) || (tt.err == nil) != (err == nil) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestMeasurePaired verifies the alternating execution order, that a zero
+// baseline measurement fails the ratio instead of producing a huge value and
+// that failed measurements and canceled contexts stop the measurement.
+func TestMeasurePaired(t *testing.T) {
+	var order []string
+	a := func(context.Context) (float64, error) { order = append(order, "a"); return 6, nil }
+	b := func(context.Context) (float64, error) { order = append(order, "b"); return 3, nil }
+	obs, err := MeasurePaired(context.Background(), 3, PairedRatio, a, b)
+	if err != nil || !slices.Equal(obs, []float64{2, 2, 2}) {
+		t.Errorf("MeasurePaired = %v, %v, want [2 2 2]", obs, err)
+	}
+	if want := []string{"a", "b", "b", "a", "a", "b"}; !slices.Equal(order, want) {
+		t.Errorf("execution order %v, want %v", order, want)
+	}
+
+	zero := func(context.Context) (float64, error) { return 0, nil }
+	if _, err := MeasurePaired(context.Background(), 2, PairedRatio, a, zero); !errors.Is(err, ErrInvalidObservations) {
+		t.Errorf("zero baseline: got %v, want ErrInvalidObservations", err)
+	}
+	errDown := errors.New("rig down")
+	failing := func(context.Context) (float64, error) { return 0, errDown }
+	if _, err := MeasurePaired(context.Background(), 2, PairedDifference, a, failing); !errors.Is(err, errDown) || !strings.Contains(err.Error(), "repetition 1: variant b") {
+		t.Errorf("failing variant: got %v, want the error of variant b", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := MeasurePaired(ctx, 2, PairedDifference, a, b); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled context: got %v, want context.Canceled", err)
+	}
+}
+
+// TestPairedMeasureFunc verifies that Run records the paired observations of
+// the adapted variants, alternating their order between the runs of a trial.
+func TestPairedMeasureFunc(t *testing.T) {
+	exp := parallelExperiment(t)
+	orders := map[string][]string{}
+	variant := func(name string, y float64) MeasureFunc {
+		return func(ctx context.Context, trial Trial) (float64, error) {
+			orders[trial.Key] = append(orders[trial.Key], name)
+			return y * trial.Control["A"], nil
+		}
+	}
+	measure := PairedMeasureFunc(PairedRatio, variant("a", 6), variant("b", 3))
+	if err := exp.Run(context.Background(), measure, RunOptions{Repetitions: 2}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, r := range exp.Results {
+		if !slices.Equal(r.Observations, []float64{2, 2}) {
+			t.Errorf("trial %d: observations %v, want [2 2]", r.Trial.ID, r.Observations)
+		}
+		if want := []string{"a", "b", "b", "a"}; !slices.Equal(orders[r.Trial.Key], want) {
+			t.Errorf("trial %d: execution order %v, want %v", r.Trial.ID, orders[r.Trial.Key], want)
+		}
+	}
+}
+
+// TestAddPairedResult_ZeroBaseline verifies that a paired ratio with a zero
+// baseline is rejected as a TrialError and not recorded.
+func TestAddPairedResult_ZeroBaseline(t *testing.T) {
+	exp := parallelExperiment(t)
+	trial := exp.GenerateTrials()[0]
+	err := exp.AddPairedResult(trial, []float64{1, 2}, []float64{1, 0}, PairedRatio)
+	var trialErr *TrialError
+	if !errors.As(err, &trialErr) || trialErr.Trial != trial.ID || !errors.Is(err, ErrInvalidObservations) {
+		t.Errorf("AddPairedResult = %v, want a TrialError matching ErrInvalidObservations", err)
+	}
+	if len(exp.Results) != 0 {
+		t.Errorf("%d results recorded", len(exp.Results))
+	}
+	if err := exp.AddPairedResult(trial, []float64{1, 2}, []float64{1, 4}, PairedRatio); err != nil {
+		t.Fatalf("AddPairedResult: %v", err)
+	}
+	if got := exp.Results[0].Observations; !slices.Equal(got, []float64{1, 0.5}) {
+		t.Errorf("observations %v, want [1 0.5]", got)
+	}
+}
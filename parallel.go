@@ -0,0 +1,138 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ParallelRunner executes independent trials concurrently, for trial
+// functions that are I/O-bound (e.g., benchmarking remote services).
+// Experiment: The experiment whose trials are run and whose results are recorded.
+// Workers: Number of trials measured at the same time (1 when zero).
+// TrialTimeout: Deadline for each trial including its warmup runs; zero means none.
+// Options: Repetitions, warmup and trial hooks as for Run. Row hooks are not
+// supported, since trials of different rows run at the same time.
+type ParallelRunner[P any] struct {
+	Experiment   *Experiment[P]
+	Workers      int
+	TrialTimeout time.Duration
+	Options      RunOptions
+}
+
+// NewParallelRunner returns a runner for e with the given number of workers.
+func NewParallelRunner[P any](e *Experiment[P], workers int) *ParallelRunner[P] {
+	return &ParallelRunner[P]{Experiment: e, Workers: workers}
+}
+
+// Run measures every generated trial with measure using the worker pool.
+// Results are recorded in trial order regardless of completion order, so
+// repeated runs produce the same result layout. On the first error the
+// remaining trials are cancelled; the results of the trials that completed
+// are still recorded and the error is returned. Cancelling ctx stops the run
+// as for Experiment.Run: completed results are flushed to Options.Checkpoint
+// and an *InterruptedError is returned.
+func (r *ParallelRunner[P]) Run(parent context.Context, measure MeasureFunc) error {
+	if r.Options.SetupRow != nil || r.Options.TeardownRow != nil {
+		return errors.New("row hooks are not supported by the parallel runner")
+	}
+	for _, factor := range r.Experiment.ControlFactors {
+		if factor.Apply != nil {
+			return fmt.Errorf("factor %s: Apply functions are not supported by the parallel runner", factor.Name)
+		}
+	}
+	if r.Options.CaptureMemStats {
+		return errors.New("memory statistics are process-wide and cannot be captured by the parallel runner")
+	}
+	if len(r.Options.Collectors) > 0 {
+		return errors.New("collectors are not supported by the parallel runner")
+	}
+	if r.Experiment.RunOrder.blocks() > 1 {
+		return errors.New("replicate blocks are not supported by the parallel runner")
+	}
+	e := r.Experiment
+	trials := e.GenerateTrials()
+	if r.Options.SkipCompleted {
+		trials = e.pendingTrials(trials, 0)
+	}
+	workers := max(r.Workers, 1)
+	r.Options.Progress.begin(trials, r.Options, workers)
+	defer r.Options.Progress.end()
+	r.Options.Metrics.begin(trials, r.Options)
+	defer r.Options.Metrics.end()
+	measured := make([]trialMeasurement, len(trials))
+	errs := make([]error, len(trials))
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				release, err := r.Options.Throttle.acquire(ctx)
+				if err != nil {
+					errs[i] = err
+					cancel()
+					continue
+				}
+				tctx, tcancel := trialContext(ctx, r.Options), context.CancelFunc(func() {})
+				if r.TrialTimeout > 0 {
+					tctx, tcancel = context.WithTimeout(tctx, r.TrialTimeout)
+				}
+				start := time.Now()
+				measured[i], errs[i] = e.measureTrial(e.TrialContext(tctx, trials[i], trialMetadata(trials[i], r.Options)), trials[i], measure, r.Options)
+				tcancel()
+				release()
+				if errs[i] == nil {
+					d := time.Since(start)
+					r.Options.Progress.trialDone(trials[i].Row, r.Options.Warmup+max(r.Options.Repetitions, 1), d)
+					r.Options.Metrics.trialDone(d)
+					errs[i] = sleepContext(ctx, r.Options.Cooldown)
+				} else if ctx.Err() == nil {
+					r.Options.Metrics.trialFailed()
+				}
+				if errs[i] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+feed:
+	for i := range trials {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	// Report the first real failure in trial order rather than the
+	// cancellations it caused in other workers.
+	var err error
+	for i, trial := range trials {
+		if errs[i] != nil {
+			if err == nil || (errors.Is(err, context.Canceled) && !errors.Is(errs[i], context.Canceled)) {
+				err = errs[i]
+			}
+			continue
+		}
+		if measured[i].observations != nil {
+			if aerr := e.addMeasurement(trial, measured[i]); aerr != nil && (err == nil || errors.Is(err, context.Canceled)) {
+				err = aerr
+			} else if aerr == nil {
+				r.Options.Metrics.rowMeasured(trial.Row, e.measuredRowSNR(trial.Row))
+			}
+		}
+	}
+	if err == nil {
+		err = parent.Err()
+	}
+	return e.finishRun(parent, r.Options, err)
+}
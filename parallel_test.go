@@ -0,0 +1,76 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// parallelExperiment returns an L4 experiment with two factors and one
+// two-level noise factor, without results.
+func parallelExperiment(t *testing.T) *Experiment[struct{}] {
+	t.Helper()
+	return newTestExperiment(t, testDesign{Noise: []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}})
+}
+
+// TestParallelRunner_Ordering verifies that trials run concurrently but
+// results are recorded in trial order.
+func TestParallelRunner_Ordering(t *testing.T) {
+	exp := parallelExperiment(t)
+	var running, peak atomic.Int32
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Later trials finish first.
+		time.Sleep(time.Duration(10-trial.ID) * time.Millisecond)
+		running.Add(-1)
+		return float64(trial.ID), nil
+	}
+
+	r := NewParallelRunner(exp, 4)
+	r.Options.Repetitions = 2
+	if err := r.Run(context.Background(), measure); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if peak.Load() < 2 {
+		t.Errorf("peak concurrency %d, want at least 2", peak.Load())
+	}
+	if len(exp.Results) != 8 {
+		t.Fatalf("len(Results) = %d, want 8", len(exp.Results))
+	}
+	for i, res := range exp.Results {
+		if res.Trial.ID != i+1 || len(res.Observations) != 2 {
+			t.Errorf("Results[%d]: trial %d with %d observations, want trial %d with 2", i, res.Trial.ID, len(res.Observations), i+1)
+		}
+	}
+}
+
+// TestParallelRunner_TrialTimeout verifies that a slow trial fails with its
+// deadline and that the failure is reported.
+func TestParallelRunner_TrialTimeout(t *testing.T) {
+	exp := parallelExperiment(t)
+	r := NewParallelRunner(exp, 2)
+	r.TrialTimeout = 20 * time.Millisecond
+	err := r.Run(context.Background(), func(ctx context.Context, trial Trial) (float64, error) {
+		if trial.ID == 3 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 1, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run error = %v, want deadline exceeded", err)
+	}
+	for _, res := range exp.Results {
+		if res.Trial.ID == 3 {
+			t.Error("timed-out trial was recorded")
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package taguchi
+
+import "maps"
+
+// PartialAnalysis is a provisional analysis of an experiment in progress,
+// computed from the array rows measured so far (see AnalyzePartial).
+// MainEffects: Mean SNR per factor level over the measured rows; 0 for levels without measured rows.
+// LevelRows: Number of measured rows per factor level, in level order.
+// OptimalLevels: Provisional best level of each optimizable factor, among its measured levels.
+// Contributions: Percentage contribution of each factor to the total variation over the measured rows.
+// ErrorContribution: Percentage of the variation over the measured rows left unexplained.
+// PendingRows: Zero-based array rows without results.
+// IncompleteRows: Zero-based array rows measured under some but not all noise conditions; their SNR is provisional.
+type PartialAnalysis struct {
+	MainEffects       map[string][]float64
+	LevelRows         map[string][]int
+	OptimalLevels     map[string]float64
+	Contributions     map[string]float64
+	ErrorContribution float64
+	PendingRows       []int
+	IncompleteRows    []int
+}
+
+// Complete reports whether every row has been measured under every noise
+// condition, in which case Analyze gives the final result.
+func (p PartialAnalysis) Complete() bool {
+	return len(p.PendingRows) == 0 && len(p.IncompleteRows) == 0
+}
+
+// AnalyzePartial computes main effects, contributions and provisional optimal
+// levels from the results recorded so far. Unlike Analyze with
+// AllowIncomplete, pending rows are left out rather than imputed, so the
+// effects reflect only what has been measured. It is meant for surfacing
+// intermediate insight during long experiments, e.g. to abort early when one
+// factor clearly dominates.
+func (e *Experiment[P]) AnalyzePartial() (PartialAnalysis, error) {
+	if len(e.Results) == 0 {
+		return PartialAnalysis{}, errorf(ErrMissingResults, "no results recorded")
+	}
+	rows := e.computeOASNR()
+	for i, missing := range rows.missing {
+		if missing {
+			rows.included[i] = false
+			rows.missing[i] = false
+		}
+	}
+	anova, mainEffects, _ := e.computeANOVA(rows)
+
+	p := PartialAnalysis{
+		MainEffects:   mainEffects,
+		LevelRows:     map[string][]int{},
+		OptimalLevels: map[string]float64{},
+	}
+	p.Contributions, p.ErrorContribution = computeContributions(anova)
+	for j, factor := range e.ControlFactors {
+		counts := make([]int, len(factor.Levels))
+		for i := range e.OrthogonalArray {
+			if rows.included[i] {
+				counts[e.levelIndex(i, j)]++
+			}
+		}
+		p.LevelRows[factor.Name] = counts
+		if factor.ObserveOnly {
+			continue
+		}
+		best := -1
+		for l, n := range counts {
+			if n > 0 && (best < 0 || mainEffects[factor.Name][l] > mainEffects[factor.Name][best]) {
+				best = l
+			}
+		}
+		p.OptimalLevels[factor.Name] = factor.Levels[best]
+	}
+
+	conditions := e.noiseConditions()
+	for i := range e.OrthogonalArray {
+		if !rows.included[i] {
+			p.PendingRows = append(p.PendingRows, i)
+			continue
+		}
+		measured := 0
+		for _, c := range conditions {
+			if e.conditionMeasured(i, c.Noise) {
+				measured++
+			}
+		}
+		if measured < len(conditions) {
+			p.IncompleteRows = append(p.IncompleteRows, i)
+		}
+	}
+	return p, nil
+}
+
+// conditionMeasured reports whether row i has a result under the given noise condition.
+func (e *Experiment[P]) conditionMeasured(i int, noise map[string]float64) bool {
+	for _, r := range e.Results {
+		if e.rowMatches(i, r.Trial) && maps.Equal(r.Trial.Noise, noise) {
+			return true
+		}
+	}
+	return false
+}
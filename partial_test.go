@@ -0,0 +1,38 @@
+package taguchi
+
+import "testing"
+
+func TestAnalyzePartial(t *testing.T) {
+	exp := parallelExperiment(t)
+	if _, err := exp.AnalyzePartial(); err == nil {
+		t.Error("AnalyzePartial accepted an experiment without results")
+	}
+	trials := exp.GenerateTrials()
+	// Rows 1 and 3 fully measured, row 2 under one noise condition, row 4 pending.
+	for _, trial := range []Trial{trials[0], trials[1], trials[2], trials[4], trials[5]} {
+		if err := exp.AddResult(trial, []float64{trial.Control["A"] + 10*trial.Control["B"]}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+
+	p, err := exp.AnalyzePartial()
+	if err != nil {
+		t.Fatalf("AnalyzePartial: %v", err)
+	}
+	if p.Complete() || len(p.PendingRows) != 1 || p.PendingRows[0] != 3 {
+		t.Errorf("PendingRows = %v, want [3]", p.PendingRows)
+	}
+	if len(p.IncompleteRows) != 1 || p.IncompleteRows[0] != 1 {
+		t.Errorf("IncompleteRows = %v, want [1]", p.IncompleteRows)
+	}
+	if got := p.LevelRows["A"]; got[0] != 2 || got[1] != 1 {
+		t.Errorf("LevelRows[A] = %v, want [2 1]", got)
+	}
+	// B dominates: level 1 keeps the response small.
+	if p.OptimalLevels["B"] != 1 || p.Contributions["B"] <= p.Contributions["A"] {
+		t.Errorf("provisional optimum %v with contributions %v, want B=1 dominating", p.OptimalLevels, p.Contributions)
+	}
+	if _, err := exp.Analyze(); err == nil {
+		t.Error("Analyze accepted the incomplete experiment")
+	}
+}
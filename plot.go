@@ -0,0 +1,209 @@
+package taguchi
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PlotSeries is one line of a plot.
+// Name: Legend label of the line.
+// Values: Y value at each of the plot's categories; NaN leaves a gap.
+type PlotSeries struct {
+	Name   string
+	Values []float64
+}
+
+// Plot holds the data of a main-effects or interaction plot, ready for
+// dashboards or for rendering with WriteSVG.
+// Title: Panel title, the factor or interaction name.
+// XLabel, YLabel: Axis labels.
+// Categories: X-axis labels, one per factor level.
+// Series: The plotted lines.
+type Plot struct {
+	Title      string
+	XLabel     string
+	YLabel     string
+	Categories []string
+	Series     []PlotSeries
+}
+
+// MainEffectPlots returns one plot per factor, in alphabetical order, with a
+// single line of the mean SNR at each level.
+func (r AnalysisResult) MainEffectPlots() []Plot {
+	var plots []Plot
+	for _, factor := range sortedKeys(r.MainEffects) {
+		values := r.MainEffects[factor]
+		plots = append(plots, Plot{
+			Title:      factor,
+			XLabel:     "Level",
+			YLabel:     "Mean SNR (dB)",
+			Categories: levelLabels(len(values)),
+			Series:     []PlotSeries{{Name: factor, Values: append([]float64(nil), values...)}},
+		})
+	}
+	return plots
+}
+
+// InteractionPlots returns one plot per declared interaction: the levels of
+// A on the X axis and one line per level of B. Non-parallel lines indicate an
+// interaction.
+func (r AnalysisResult) InteractionPlots() []Plot {
+	var plots []Plot
+	for _, in := range r.Interactions {
+		if len(in.CellMeans) == 0 {
+			continue
+		}
+		p := Plot{
+			Title:      InteractionName(in.A, in.B),
+			XLabel:     in.A,
+			YLabel:     "Mean SNR (dB)",
+			Categories: levelLabels(len(in.CellMeans)),
+		}
+		for y := range in.CellMeans[0] {
+			s := PlotSeries{Name: fmt.Sprintf("%s=%d", in.B, y+1)}
+			for x := range in.CellMeans {
+				s.Values = append(s.Values, in.CellMeans[x][y])
+			}
+			p.Series = append(p.Series, s)
+		}
+		plots = append(plots, p)
+	}
+	return plots
+}
+
+// levelLabels returns the one-based level numbers "1".."n".
+func levelLabels(n int) []string {
+	labels := make([]string, n)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i + 1)
+	}
+	return labels
+}
+
+// SVGOptions controls WriteSVG.
+// PanelWidth, Height: Size of each panel and of the image in pixels (240 and 300 when zero).
+// Numbers: Formatting of the Y-axis tick labels (two decimals when zero).
+type SVGOptions struct {
+	PanelWidth int
+	Height     int
+	Numbers    NumberFormat
+}
+
+// plotColors is the palette used for successive series.
+var plotColors = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b"}
+
+// SVG layout margins in pixels.
+const (
+	svgLeft   = 56
+	svgTop    = 28
+	svgBottom = 48
+	svgGap    = 16
+)
+
+// WriteSVG renders plots side by side as panels of one SVG image with a
+// shared Y axis, like the usual main-effects chart. Pass a single plot for an
+// interaction plot; series of more than one line get a legend.
+func WriteSVG(w io.Writer, plots []Plot, opts SVGOptions) error {
+	if len(plots) == 0 {
+		return fmt.Errorf("no plots to render")
+	}
+	panelW, height := opts.PanelWidth, opts.Height
+	if panelW <= 0 {
+		panelW = 240
+	}
+	if height <= 0 {
+		height = 300
+	}
+	nf := opts.Numbers
+	if nf == (NumberFormat{}) {
+		nf = NumberFormat{Decimals: 2, Locale: LocalePlain}
+	}
+
+	lo, hi := plotRange(plots)
+	plotH := float64(height - svgTop - svgBottom)
+	yPos := func(v float64) float64 {
+		return float64(svgTop) + (hi-v)/(hi-lo)*plotH
+	}
+	width := svgLeft + len(plots)*(panelW+svgGap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="11">`+"\n", width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	for k := 0; k <= 4; k++ {
+		v := lo + (hi-lo)*float64(k)/4
+		y := yPos(v)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`+"\n", svgLeft, y, width-svgGap, y)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`+"\n", svgLeft-4, y+4, html.EscapeString(nf.Format(v)))
+	}
+	fmt.Fprintf(&b, `<text x="12" y="%.1f" transform="rotate(-90 12 %.1f)" text-anchor="middle">%s</text>`+"\n",
+		float64(svgTop)+plotH/2, float64(svgTop)+plotH/2, html.EscapeString(plots[0].YLabel))
+
+	for i, p := range plots {
+		x0 := svgLeft + i*(panelW+svgGap)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%.0f" fill="none" stroke="#888"/>`+"\n", x0, svgTop, panelW, plotH)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" font-weight="bold">%s</text>`+"\n", x0+panelW/2, svgTop-10, html.EscapeString(p.Title))
+		xPos := func(c int) float64 {
+			return float64(x0) + float64(panelW)*(float64(c)+0.5)/float64(max(len(p.Categories), 1))
+		}
+		for c, label := range p.Categories {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", xPos(c), float64(svgTop)+plotH+14, html.EscapeString(label))
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="middle">%s</text>`+"\n", x0+panelW/2, float64(svgTop)+plotH+30, html.EscapeString(p.XLabel))
+
+		for s, series := range p.Series {
+			color := plotColors[s%len(plotColors)]
+			var points []string
+			flush := func() {
+				if len(points) > 1 {
+					fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(points, " "), color)
+				}
+				points = points[:0]
+			}
+			for c, v := range series.Values {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					flush()
+					continue
+				}
+				x, y := xPos(c), yPos(v)
+				points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+				fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`+"\n", x, y, color)
+			}
+			flush()
+			if len(p.Series) > 1 {
+				ly := svgTop + 14 + 14*s
+				fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s">%s</text>`+"\n", x0+6, ly, color, html.EscapeString(series.Name))
+			}
+		}
+	}
+	fmt.Fprintln(&b, "</svg>")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// plotRange returns the Y range covering every finite value of plots, padded
+// by 5% on each side.
+func plotRange(plots []Plot) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range plots {
+		for _, s := range p.Series {
+			for _, v := range s.Values {
+				if !math.IsNaN(v) && !math.IsInf(v, 0) {
+					lo, hi = min(lo, v), max(hi, v)
+				}
+			}
+		}
+	}
+	switch {
+	case lo > hi:
+		return 0, 1
+	case lo == hi:
+		return lo - 1, hi + 1
+	}
+	pad := (hi - lo) * 0.05
+	return lo - pad, hi + pad
+}
@@ -0,0 +1,102 @@
+package taguchi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPlots(t *testing.T) {
+	result := AnalysisResult{
+		MainEffects: map[string][]float64{
+			"B": {-3, -1, -2},
+			"A": {-5, -4},
+		},
+		Interactions: []InteractionEffect{
+			{A: "A", B: "B", CellMeans: [][]float64{{1, 2, 3}, {4, 5, 6}}},
+		},
+	}
+
+	main := result.MainEffectPlots()
+	if len(main) != 2 || main[0].Title != "A" || len(main[1].Categories) != 3 {
+		t.Fatalf("MainEffectPlots = %+v", main)
+	}
+	inter := result.InteractionPlots()
+	if len(inter) != 1 || len(inter[0].Series) != 3 || len(inter[0].Categories) != 2 {
+		t.Fatalf("InteractionPlots = %+v", inter)
+	}
+	if s := inter[0].Series[2]; s.Name != "B=3" || s.Values[1] != 6 {
+		t.Errorf("series B=3 = %+v, want values [3 6]", s)
+	}
+
+	main[1].Series[0].Values[1] = math.NaN()
+	var buf bytes.Buffer
+	if err := WriteSVG(&buf, main, SVGOptions{}); err != nil {
+		t.Fatalf("WriteSVG: %v", err)
+	}
+	dec := xml.NewDecoder(strings.NewReader(buf.String()))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, buf.String())
+		}
+	}
+	// A is drawn as one line; B's gap at level 2 leaves only isolated points.
+	if got := strings.Count(buf.String(), "<polyline"); got != 1 {
+		t.Errorf("SVG has %d polylines, want 1", got)
+	}
+	if got := strings.Count(buf.String(), "<circle"); got != 4 {
+		t.Errorf("SVG has %d points, want 4", got)
+	}
+	if err := WriteSVG(&buf, nil, SVGOptions{}); err == nil {
+		t.Error("WriteSVG accepted no plots")
+	}
+}
+
+func TestVegaLite(t *testing.T) {
+	result := AnalysisResult{
+		MainEffects:   map[string][]float64{"A": {-5, math.NaN()}, "B": {-3, -1}},
+		Contributions: map[string]float64{"A": 20, "B": 70, "C": 10},
+	}
+
+	var spec struct {
+		Schema  string `json:"$schema"`
+		HConcat []struct {
+			Data struct {
+				Values []map[string]any `json:"values"`
+			} `json:"data"`
+		} `json:"hconcat"`
+	}
+	b, err := VegaLite(result.MainEffectPlots())
+	if err != nil {
+		t.Fatalf("VegaLite: %v", err)
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, b)
+	}
+	if spec.Schema != VegaLiteSchema || len(spec.HConcat) != 2 {
+		t.Fatalf("spec = %s", b)
+	}
+	if got := len(spec.HConcat[0].Data.Values); got != 1 {
+		t.Errorf("panel A has %d values, want 1 (NaN dropped)", got)
+	}
+	if _, err := VegaLite(nil); err == nil {
+		t.Error("VegaLite accepted no plots")
+	}
+
+	pareto := result.ParetoPlot()
+	if got := pareto.Categories; len(got) != 3 || got[0] != "B" || got[2] != "C" {
+		t.Errorf("Pareto categories = %v, want [B A C]", got)
+	}
+	if got := pareto.Series[1].Values; got[1] != 90 || got[2] != 100 {
+		t.Errorf("cumulative = %v, want [70 90 100]", got)
+	}
+	if b, err = result.ParetoVegaLite(); err != nil || !json.Valid(b) {
+		t.Errorf("ParetoVegaLite = %s, %v", b, err)
+	}
+}
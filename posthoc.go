@@ -0,0 +1,131 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+)
+
+// PostHocMethod selects the pairwise comparison of factor levels run by
+// Analyze for factors with three or more levels (see AnalysisResult.Comparisons).
+type PostHocMethod int
+
+const (
+	// NoPostHoc skips the pairwise comparisons (the default).
+	NoPostHoc PostHocMethod = iota
+	// FisherLSD compares each pair of levels with a t-test on the ANOVA
+	// error (protected least significant difference): a difference is only
+	// significant when the factor's F-test is too. It is the more sensitive
+	// method but does not control the family-wise error rate beyond that.
+	FisherLSD
+	// TukeyHSD compares each pair of levels against the studentized range
+	// distribution (honestly significant difference, Tukey–Kramer for
+	// unequal level counts), holding the family-wise error rate of all of a
+	// factor's comparisons at Alpha.
+	TukeyHSD
+)
+
+// String returns the method's name.
+func (m PostHocMethod) String() string {
+	switch m {
+	case FisherLSD:
+		return "Fisher LSD"
+	case TukeyHSD:
+		return "Tukey HSD"
+	default:
+		return "none"
+	}
+}
+
+// LevelComparison is the pairwise comparison of two levels of a factor.
+// Factor: The control factor.
+// LevelA, LevelB: The compared levels, LevelA first in level order.
+// Diff: Mean SNR of LevelA minus that of LevelB.
+// CriticalDiff: Smallest absolute Diff that is significant at the experiment's Alpha.
+// P: p-value of the difference.
+// Significant: Whether the levels differ significantly.
+type LevelComparison struct {
+	Factor       string
+	LevelA       float64
+	LevelB       float64
+	Diff         float64
+	CriticalDiff float64
+	P            float64
+	Significant  bool
+}
+
+// String describes the comparison, e.g. "Threads 1 vs 4: -3.2 dB (p = 0.004) *".
+func (c LevelComparison) String() string {
+	mark := ""
+	if c.Significant {
+		mark = " *"
+	}
+	return fmt.Sprintf("%s %g vs %g: %.4g dB (p = %.3g)%s", c.Factor, c.LevelA, c.LevelB, c.Diff, c.P, mark)
+}
+
+// compareLevels runs the experiment's PostHoc method on the main effects of
+// every optimizable factor with three or more levels. The standard error of
+// a difference is sqrt(V_e·(1/n₁+1/n₂)), with V_e the ANOVA error mean square
+// and n the number of rows per level. Without an error variance every
+// difference other than zero is significant.
+func (e *Experiment[P]) compareLevels(rows oaRowSNR, anova ANOVAResult, mainEffects map[string][]float64) []LevelComparison {
+	if e.PostHoc == NoPostHoc {
+		return nil
+	}
+	df := float64(anova.ErrorDF)
+	tukey := map[int]float64{} // studentized range critical value per level count
+	var comparisons []LevelComparison
+	for j, factor := range e.ControlFactors {
+		k := len(factor.Levels)
+		if factor.ObserveOnly || k < 3 {
+			continue
+		}
+		counts := make([]int, k)
+		for i := range e.OrthogonalArray {
+			if l := e.levelIndex(i, j); rows.included[i] && l >= 0 && l < k {
+				counts[l]++
+			}
+		}
+		// Critical values in units of the standard error of a difference.
+		var critical float64
+		switch {
+		case anova.ErrorDF < 1:
+			critical = math.NaN()
+		case e.PostHoc == TukeyHSD:
+			if _, ok := tukey[k]; !ok {
+				tukey[k] = studentizedRangeQuantile(1-anova.Alpha, k, df) / math.Sqrt2
+			}
+			critical = tukey[k]
+		default:
+			critical = studentTQuantile(1-anova.Alpha/2, df)
+		}
+		effects := mainEffects[factor.Name]
+		for a := 0; a < k; a++ {
+			for b := a + 1; b < k; b++ {
+				if counts[a] == 0 || counts[b] == 0 {
+					continue
+				}
+				c := LevelComparison{Factor: factor.Name, LevelA: factor.Levels[a], LevelB: factor.Levels[b], Diff: effects[a] - effects[b]}
+				se := math.Sqrt(math.Max(anova.ErrorMS, 0) * (1/float64(counts[a]) + 1/float64(counts[b])))
+				c.CriticalDiff = critical * se
+				switch {
+				case anova.ErrorDF < 1:
+					c.P = math.NaN()
+				case se == 0 && c.Diff == 0:
+					c.P = 1
+				case se == 0:
+					c.P = 0
+				case e.PostHoc == TukeyHSD:
+					c.P = 1 - studentizedRangeCDF(math.Sqrt2*math.Abs(c.Diff)/se, k, df)
+				default:
+					c.P = 2 * (1 - studentTCDF(math.Abs(c.Diff)/se, df))
+				}
+				c.Significant = c.P < anova.Alpha
+				if e.PostHoc == FisherLSD {
+					c.Significant = c.Significant && anova.Significant[factor.Name]
+				}
+				comparisons = append(comparisons, c)
+			}
+		}
+	}
+	return comparisons
+}
@@ -0,0 +1,86 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStudentizedRangeQuantile(t *testing.T) {
+	// Upper 5% points from published studentized range tables.
+	for _, c := range []struct {
+		k    int
+		df   float64
+		want float64
+	}{
+		{2, 5, 3.635},
+		{3, 1, 26.98},
+		{3, 10, 3.877},
+		{4, 20, 3.958},
+		{5, 2, 10.88},
+		{3, math.Inf(1), 3.314},
+	} {
+		if got := studentizedRangeQuantile(0.95, c.k, c.df); math.Abs(got-c.want) > 0.005*c.want {
+			t.Errorf("q(0.95; %d, %g) = %.4f, want %.3f", c.k, c.df, got, c.want)
+		}
+	}
+}
+
+// postHocDesign is an L9 experiment where only A matters: its levels 1 and
+// 2 are nearly equal and level 3 is three times slower. Every trial is
+// replicated, so the saturated design has a pure-error term.
+var postHocDesign = testDesign{
+	Factors: namedFactors([]float64{1, 2, 3}, "A", "B", "C", "D"),
+	Array:   L9,
+	Response: func(trial Trial) [][]float64 {
+		y := map[float64]float64{1: 10, 2: 10.2, 3: 30}[trial.Control["A"]]
+		return [][]float64{{y}, {1.05 * y}}
+	},
+}
+
+func TestPostHoc(t *testing.T) {
+	exp := newTestExperiment(t, postHocDesign)
+	critical := map[PostHocMethod]float64{}
+	for _, method := range []PostHocMethod{FisherLSD, TukeyHSD} {
+		exp.PostHoc = method
+		result := mustAnalyze(t, exp)
+		if len(result.Comparisons) != 12 {
+			t.Fatalf("%s: %d comparisons, want 3 pairs for each of 4 factors", method, len(result.Comparisons))
+		}
+		for _, c := range result.Comparisons {
+			wantSignificant := c.Factor == "A" && c.LevelB == 3
+			if c.Significant != wantSignificant {
+				t.Errorf("%s: %s, significant = %v, want %v", method, c, c.Significant, wantSignificant)
+			}
+			if c.Significant != (math.Abs(c.Diff) > c.CriticalDiff) {
+				t.Errorf("%s: %s disagrees with its critical difference %g", method, c, c.CriticalDiff)
+			}
+			if c.Factor == "A" && c.LevelA == 1 && c.LevelB == 3 {
+				want := -20*math.Log10(10) + 20*math.Log10(30)
+				if math.Abs(c.Diff-want) > 0.01 {
+					t.Errorf("%s: A 1 vs 3 differs by %g dB, want %g", method, c.Diff, want)
+				}
+				critical[method] = c.CriticalDiff
+			}
+		}
+	}
+	if critical[TukeyHSD] <= critical[FisherLSD] {
+		t.Errorf("Tukey critical difference %g is not above the LSD's %g", critical[TukeyHSD], critical[FisherLSD])
+	}
+
+	exp.PostHoc = NoPostHoc
+	if c := mustAnalyze(t, exp).Comparisons; c != nil {
+		t.Errorf("NoPostHoc: comparisons %v, want nil", c)
+	}
+}
+
+// TestPostHoc_TwoLevelsAgree verifies that for two levels Tukey's test
+// reduces to the t-test of Fisher's LSD.
+func TestPostHoc_TwoLevelsAgree(t *testing.T) {
+	q := studentizedRangeQuantile(0.95, 2, 7) / math.Sqrt2
+	if lsd := studentTQuantile(0.975, 7); math.Abs(q-lsd) > 1e-4 {
+		t.Errorf("Tukey critical value %g for two levels, want the t value %g", q, lsd)
+	}
+	if p, want := 1-studentizedRangeCDF(math.Sqrt2*2, 2, 7), 2*(1-studentTCDF(2, 7)); math.Abs(p-want) > 1e-4 {
+		t.Errorf("Tukey p %g for two levels, want the t-test p %g", p, want)
+	}
+}
@@ -0,0 +1,286 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultConfidence is the confidence level used by PredictOptimal.
+const DefaultConfidence = 0.95
+
+// Interval is a closed confidence interval.
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+// Prediction is the expected outcome of a factor configuration under the
+// additive main-effects model, for comparison with a confirmation run.
+// Levels: The configuration the prediction is for.
+// SNR, SNRInterval: Predicted SNR and its confidence interval.
+// Mean, MeanInterval: Predicted mean response and its confidence interval.
+// Confidence: Confidence level of the intervals (e.g., 0.95).
+// EffectiveN: Effective number of replications, N / (1 + sum of the DF of the factors in the model).
+type Prediction struct {
+	Levels       map[string]float64
+	SNR          float64
+	SNRInterval  Interval
+	Mean         float64
+	MeanInterval Interval
+	Confidence   float64
+	EffectiveN   float64
+}
+
+// PredictOptimal predicts the SNR and mean response at the optimal levels
+// found by Analyze, with DefaultConfidence intervals. A confirmation run whose
+// SNR falls outside the interval suggests interactions or effects the
+// additive model does not capture.
+func (e *Experiment[P]) PredictOptimal() (Prediction, error) {
+	result, err := e.analyze()
+	if err != nil {
+		return Prediction{}, err
+	}
+	return e.Predict(result.OptimalLevels, DefaultConfidence)
+}
+
+// Predict predicts the SNR and mean response of an arbitrary configuration.
+// levels maps each optimizable control factor to one of its levels; observe-only
+// factors are left out of the model. The intervals are ±t(ν_e) · sqrt(V_e / n_eff)
+// where V_e and ν_e are the ANOVA error variance and degrees of freedom.
+func (e *Experiment[P]) Predict(levels map[string]float64, confidence float64) (Prediction, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return Prediction{}, fmt.Errorf("confidence must be in (0, 1), got %g", confidence)
+	}
+	if len(e.Results) == 0 {
+		return Prediction{}, errorf(ErrMissingResults, "no results recorded")
+	}
+	if err := e.checkComplete(); err != nil {
+		return Prediction{}, err
+	}
+
+	snrRows := e.computeOASNR()
+	snrANOVA, snrEffects, _ := e.computeANOVA(snrRows)
+	meanRows := e.computeRowMeans(snrRows)
+	meanANOVA, meanEffects, _ := e.computeANOVA(meanRows)
+
+	p := Prediction{
+		Levels:     map[string]float64{},
+		SNR:        snrRows.grandMean,
+		Mean:       meanRows.grandMean,
+		Confidence: confidence,
+	}
+	modelDF := 0
+	for _, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
+		level, ok := levels[factor.Name]
+		if !ok {
+			return Prediction{}, fmt.Errorf("no level given for factor %s", factor.Name)
+		}
+		li := levelPosition(factor, level)
+		if li < 0 {
+			return Prediction{}, errorf(ErrLevelMismatch, "factor %s has no level %g", factor.Name, level)
+		}
+		p.Levels[factor.Name] = level
+		p.SNR += snrEffects[factor.Name][li] - snrRows.grandMean
+		p.Mean += meanEffects[factor.Name][li] - meanRows.grandMean
+		modelDF += len(factor.Levels) - 1
+	}
+
+	n := 0
+	for _, included := range snrRows.included {
+		if included {
+			n++
+		}
+	}
+	p.EffectiveN = float64(n) / float64(1+modelDF)
+	p.SNRInterval = predictionInterval(p.SNR, snrANOVA, p.EffectiveN, confidence)
+	p.MeanInterval = predictionInterval(p.Mean, meanANOVA, p.EffectiveN, confidence)
+	return p, nil
+}
+
+// predictionInterval returns center ± t · sqrt(ErrorMS / effectiveN).
+func predictionInterval(center float64, anova ANOVAResult, effectiveN, confidence float64) Interval {
+	t := studentTQuantile(1-(1-confidence)/2, float64(anova.ErrorDF))
+	half := t * math.Sqrt(math.Max(anova.ErrorMS, 0)/effectiveN)
+	return Interval{Low: center - half, High: center + half}
+}
+
+// computeRowMeans returns the mean response of each orthogonal array row,
+// restricted to the rows included in the SNR analysis. Rows without results
+// are imputed with the mean of the measured rows, as in computeOASNR.
+func (e *Experiment[P]) computeRowMeans(snrRows oaRowSNR) oaRowSNR {
+	rows := oaRowSNR{
+		values:     make([]float64, len(e.OrthogonalArray)),
+		included:   make([]bool, len(e.OrthogonalArray)),
+		infinite:   make([]bool, len(e.OrthogonalArray)),
+		missing:    make([]bool, len(e.OrthogonalArray)),
+		replicates: make([][]float64, len(e.OrthogonalArray)),
+	}
+	sum, n := 0.0, 0
+	for i := range e.OrthogonalArray {
+		var summary ObservationSummary
+		var matched []TrialResult
+		for _, r := range e.Results {
+			if e.rowMatches(i, r.Trial) {
+				summary.Merge(r.Summary)
+				matched = append(matched, r)
+			}
+		}
+		rows.replicates[i] = replicateMeans(matched)
+		if summary.Count > 0 {
+			rows.values[i] = summary.Sum / float64(summary.Count)
+		}
+		rows.included[i] = snrRows.included[i]
+		rows.missing[i] = summary.Count == 0
+		if rows.included[i] && !rows.missing[i] {
+			sum += rows.values[i]
+			n++
+		}
+	}
+	if n > 0 {
+		rows.grandMean = sum / float64(n)
+	}
+	for i, missing := range rows.missing {
+		if missing {
+			rows.values[i] = rows.grandMean
+		}
+	}
+	return rows
+}
+
+// RankedConfigurations returns the n configurations with the highest predicted
+// SNR, best first, each with its prediction and DefaultConfidence intervals.
+// The first entry is the optimum; the others are runner-up options for when
+// the optimum cannot be used in practice.
+func (e *Experiment[P]) RankedConfigurations(n int) ([]Prediction, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("n must be positive, got %d", n)
+	}
+	if err := e.checkComplete(); err != nil {
+		return nil, err
+	}
+	if len(e.Results) == 0 {
+		return nil, errorf(ErrMissingResults, "no results recorded")
+	}
+	_, snrEffects, _ := e.computeANOVA(e.computeOASNR())
+
+	// The predicted SNR is a sum of independent per-factor terms, so the n best
+	// configurations are always extensions of the n best partial ones.
+	type partial struct {
+		levels map[string]float64
+		score  float64
+	}
+	beam := []partial{{levels: map[string]float64{}}}
+	for _, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
+		var next []partial
+		for _, p := range beam {
+			for li, level := range factor.Levels {
+				levels := make(map[string]float64, len(p.levels)+1)
+				for k, v := range p.levels {
+					levels[k] = v
+				}
+				levels[factor.Name] = level
+				next = append(next, partial{levels: levels, score: p.score + snrEffects[factor.Name][li]})
+			}
+		}
+		sort.SliceStable(next, func(a, b int) bool { return next[a].score > next[b].score })
+		if len(next) > n {
+			next = next[:n]
+		}
+		beam = next
+	}
+
+	ranked := make([]Prediction, 0, len(beam))
+	for _, p := range beam {
+		pred, err := e.Predict(p.levels, DefaultConfidence)
+		if err != nil {
+			return nil, err
+		}
+		ranked = append(ranked, pred)
+	}
+	return ranked, nil
+}
+
+// OptimalLevelsSubjectTo recomputes the recommended levels when some factors
+// are pinned to mandated values (e.g., regulation forces a specific material).
+// Pinned factors keep their value; every other optimizable factor gets the
+// level with the highest predicted SNR given the pins. Under the additive
+// model this is its unconstrained optimum, unless it has a declared
+// interaction with a pinned factor, in which case the interaction term at
+// the pinned level is taken into account.
+func (e *Experiment[P]) OptimalLevelsSubjectTo(fixed map[string]float64) (map[string]float64, error) {
+	if err := e.checkComplete(); err != nil {
+		return nil, err
+	}
+	for name, level := range fixed {
+		j := e.factorIndex(name)
+		if j < 0 {
+			return nil, errorf(ErrUnknownFactor, "unknown factor %s", name)
+		}
+		if levelPosition(e.ControlFactors[j], level) < 0 {
+			return nil, errorf(ErrLevelMismatch, "factor %s has no level %g", name, level)
+		}
+	}
+	if len(e.Results) == 0 {
+		return nil, errorf(ErrMissingResults, "no results recorded")
+	}
+
+	rows := e.computeOASNR()
+	_, effects, _ := e.computeANOVA(rows)
+	levels := map[string]float64{}
+	for _, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
+		if level, ok := fixed[factor.Name]; ok {
+			levels[factor.Name] = level
+			continue
+		}
+		scores := append([]float64(nil), effects[factor.Name]...)
+		for _, in := range e.Interactions {
+			partner, own := in.B, 0
+			if in.B == factor.Name {
+				partner, own = in.A, 1
+			} else if in.A != factor.Name {
+				continue
+			}
+			pinned, ok := fixed[partner]
+			if !ok {
+				continue
+			}
+			pl := levelPosition(e.ControlFactors[e.factorIndex(partner)], pinned)
+			cells, _ := e.interactionCells(rows, in)
+			for li := range scores {
+				cell := cells[li][pl]
+				if own == 1 {
+					cell = cells[pl][li]
+				}
+				scores[li] += cell - effects[factor.Name][li] - effects[partner][pl] + rows.grandMean
+			}
+		}
+		best := 0
+		for li, s := range scores {
+			if s > scores[best] {
+				best = li
+			}
+		}
+		levels[factor.Name] = factor.Levels[best]
+	}
+	return levels, nil
+}
+
+// levelPosition returns the index of level among the factor's levels, or -1.
+func levelPosition(factor ControlFactor, level float64) int {
+	for i, l := range factor.Levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
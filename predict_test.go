@@ -0,0 +1,120 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStudentTQuantile(t *testing.T) {
+	cases := []struct {
+		p, df, want float64
+	}{
+		{0.975, 1, 12.7062},
+		{0.975, 10, 2.2281},
+		{0.95, 5, 2.0150},
+		{0.5, 3, 0},
+	}
+	for _, c := range cases {
+		if got := studentTQuantile(c.p, c.df); math.Abs(got-c.want) > 1e-3 {
+			t.Errorf("studentTQuantile(%g, %g) = %.4f, want %.4f", c.p, c.df, got, c.want)
+		}
+	}
+}
+
+// TestPredictOptimal verifies that an additive response is predicted at the
+// optimal configuration and that the interval brackets the prediction.
+func TestPredictOptimal(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+		{Name: "D", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		y := 10 + 2*(trial.Control["A"]-1) + 3*(trial.Control["B"]-1) + 0.1*float64(trial.Row%3)
+		exp.AddResult(trial, []float64{y})
+	}
+
+	p, err := exp.PredictOptimal()
+	if err != nil {
+		t.Fatalf("PredictOptimal: %v", err)
+	}
+	if p.Levels["A"] != 1 || p.Levels["B"] != 1 {
+		t.Errorf("Levels = %v, want A=1 and B=1", p.Levels)
+	}
+	if math.Abs(p.Mean-10) > 0.2 {
+		t.Errorf("Mean = %.4f, want about 10", p.Mean)
+	}
+	if !(p.MeanInterval.Low < p.Mean && p.Mean < p.MeanInterval.High) {
+		t.Errorf("MeanInterval %+v does not bracket %.4f", p.MeanInterval, p.Mean)
+	}
+	if !(p.SNRInterval.Low < p.SNR && p.SNR < p.SNRInterval.High) {
+		t.Errorf("SNRInterval %+v does not bracket %.4f", p.SNRInterval, p.SNR)
+	}
+	if !almostEqual(p.EffectiveN, 1.6) {
+		t.Errorf("EffectiveN = %.4f, want 1.6 (8 rows / (1 + 4 DF))", p.EffectiveN)
+	}
+
+	if _, err := exp.Predict(map[string]float64{"A": 1, "B": 1, "C": 3, "D": 1}, DefaultConfidence); err == nil {
+		t.Error("expected error for an unknown level")
+	}
+}
+
+func TestFSurvival(t *testing.T) {
+	cases := []struct {
+		f      float64
+		d1, d2 int
+		want   float64
+	}{
+		{5.9874, 1, 6, 0.05},  // F(0.95; 1, 6)
+		{3.7083, 3, 10, 0.05}, // F(0.95; 3, 10)
+		{0, 2, 4, 1},
+		{math.Inf(1), 2, 4, 0},
+	}
+	for _, c := range cases {
+		if got := fSurvival(c.f, c.d1, c.d2); math.Abs(got-c.want) > 1e-4 {
+			t.Errorf("fSurvival(%g, %d, %d) = %.5f, want %.5f", c.f, c.d1, c.d2, got, c.want)
+		}
+	}
+}
+
+func TestRankedConfigurations(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+		{Name: "C", Levels: []float64{1, 2}},
+		{Name: "D", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L8, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		y := 10 + 2*(trial.Control["A"]-1) + 3*(trial.Control["B"]-1) + 0.1*float64(trial.Row%3)
+		exp.AddResult(trial, []float64{y})
+	}
+
+	ranked, err := exp.RankedConfigurations(3)
+	if err != nil {
+		t.Fatalf("RankedConfigurations: %v", err)
+	}
+	if len(ranked) != 3 {
+		t.Fatalf("len(ranked) = %d, want 3", len(ranked))
+	}
+	optimal := mustAnalyze(t, exp).OptimalLevels
+	for name, level := range optimal {
+		if ranked[0].Levels[name] != level {
+			t.Errorf("best configuration %v differs from OptimalLevels %v", ranked[0].Levels, optimal)
+			break
+		}
+	}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].SNR > ranked[i-1].SNR {
+			t.Errorf("ranked[%d].SNR = %.4f exceeds ranked[%d].SNR = %.4f", i, ranked[i].SNR, i-1, ranked[i-1].SNR)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package taguchi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunStatus is a snapshot of a run's progress (see Progress).
+// Running: Whether the run is in progress.
+// Started: When the run began; zero before the first run.
+// Elapsed: Time since Started, up to the end of a finished run.
+// TrialsDone, TrialsTotal: Trials measured and planned, including the extra trials of RunOptions.RepetitionBudget.
+// MeanTrialTime: Mean time to measure a trial with its warmup and repetitions, excluding cooldowns.
+// ETA: Estimated time to completion: the remaining warmup and measured runs at the mean time per run so far,
+// plus the remaining cooldowns. Zero before the first trial completes and once the run has ended.
+// Rows: Progress of every array row in the run, in row order.
+type RunStatus struct {
+	Running       bool
+	Started       time.Time
+	Elapsed       time.Duration
+	TrialsDone    int
+	TrialsTotal   int
+	MeanTrialTime time.Duration
+	ETA           time.Duration
+	Rows          []RowStatus
+}
+
+// RowStatus is the progress of one array row.
+// Row: Zero-based array row.
+// TrialsDone, TrialsTotal: Trials of the row measured and planned.
+// Elapsed: Time spent measuring the row's trials.
+// ETA: Estimated measuring time left for the row's trials, excluding cooldowns.
+type RowStatus struct {
+	Row         int
+	TrialsDone  int
+	TrialsTotal int
+	Elapsed     time.Duration
+	ETA         time.Duration
+}
+
+// Progress tracks the trial durations of a run to estimate its time to
+// completion. Pass it in RunOptions.Progress (or ParallelRunner.Options) and
+// poll Status from another goroutine, or serve it with ServeHTTP as a
+// dashboard. A Progress may be reused for consecutive runs; each run resets it.
+type Progress struct {
+	mu sync.Mutex
+	progressState
+}
+
+// progressState is the mutable state of a Progress, reset by every run.
+type progressState struct {
+	running      bool
+	started      time.Time
+	finished     time.Time
+	workers      int
+	runsPerTrial int
+	runsPerExtra int
+	extraTrials  int
+	cooldown     time.Duration
+	busy         time.Duration
+	runs         int
+	trials       int
+	rows         []*rowProgress
+	rowByIndex   map[int]*rowProgress
+}
+
+// rowProgress accumulates the progress of one array row.
+type rowProgress struct {
+	row   int
+	done  int
+	total int
+	busy  time.Duration
+}
+
+// begin resets the progress for a run of trials with the given options.
+func (p *Progress) begin(trials []Trial, opts RunOptions, workers int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reps := max(opts.Repetitions, 1)
+	p.progressState = progressState{
+		running:      true,
+		started:      time.Now(),
+		workers:      max(workers, 1),
+		runsPerTrial: opts.Warmup + reps,
+		runsPerExtra: opts.Warmup + 1,
+		cooldown:     opts.Cooldown,
+		rowByIndex:   map[int]*rowProgress{},
+	}
+	for _, t := range trials {
+		rp, ok := p.rowByIndex[t.Row]
+		if !ok {
+			rp = &rowProgress{row: t.Row}
+			p.rowByIndex[t.Row] = rp
+			p.rows = append(p.rows, rp)
+		}
+		rp.total++
+	}
+	if opts.RepetitionBudget > 0 {
+		p.extraTrials = max(opts.RepetitionBudget-len(trials)*reps, 0)
+	}
+}
+
+// trialDone records that a trial of the given row took d for its runs
+// (warmup plus measured). Trials beyond the row's first pass are extra
+// trials of the repetition budget.
+func (p *Progress) trialDone(row, runs int, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rp, ok := p.rowByIndex[row]
+	if !ok {
+		rp = &rowProgress{row: row}
+		p.rowByIndex[row] = rp
+		p.rows = append(p.rows, rp)
+	}
+	if rp.done >= rp.total {
+		rp.total++
+		p.extraTrials = max(p.extraTrials-1, 0)
+	}
+	rp.done++
+	rp.busy += d
+	p.busy += d
+	p.runs += runs
+	p.trials++
+}
+
+// end marks the run as finished.
+func (p *Progress) end() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running = false
+	p.finished = time.Now()
+	p.extraTrials = 0
+}
+
+// Status returns a snapshot of the run's progress and estimated time to completion.
+func (p *Progress) Status() RunStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := RunStatus{
+		Running:    p.running,
+		Started:    p.started,
+		TrialsDone: p.trials,
+	}
+	switch {
+	case p.running:
+		s.Elapsed = time.Since(p.started)
+	case !p.started.IsZero():
+		s.Elapsed = p.finished.Sub(p.started)
+	}
+	var perRun time.Duration
+	if p.runs > 0 {
+		perRun = p.busy / time.Duration(p.runs)
+		s.MeanTrialTime = p.busy / time.Duration(p.trials)
+	}
+	remainingRuns, remainingTrials := p.extraTrials*p.runsPerExtra, p.extraTrials
+	for _, rp := range p.rows {
+		left := rp.total - rp.done
+		rs := RowStatus{Row: rp.row, TrialsDone: rp.done, TrialsTotal: rp.total, Elapsed: rp.busy}
+		if p.running {
+			rs.ETA = perRun * time.Duration(left*p.runsPerTrial)
+		}
+		s.Rows = append(s.Rows, rs)
+		s.TrialsTotal += rp.total
+		remainingRuns += left * p.runsPerTrial
+		remainingTrials += left
+	}
+	s.TrialsTotal += p.extraTrials
+	if p.running && p.runs > 0 {
+		work := perRun*time.Duration(remainingRuns) + p.cooldown*time.Duration(remainingTrials)
+		s.ETA = work / time.Duration(p.workers)
+	}
+	return s
+}
+
+// ServeHTTP serves the run status as a self-refreshing HTML dashboard, or as
+// JSON when the request accepts application/json or has format=json.
+func (p *Progress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := p.Status()
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, s); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// dashboardTemplate renders RunStatus for ServeHTTP.
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"inc":   func(i int) int { return i + 1 },
+	"round": func(d time.Duration) string { return d.Round(time.Second).String() },
+	"percent": func(done, total int) string {
+		if total == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.0f%%", 100*float64(done)/float64(total))
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8">{{if .Running}}<meta http-equiv="refresh" content="5">{{end}}<title>Taguchi experiment</title></head>
+<body style="font-family: sans-serif">
+<h1>Taguchi experiment</h1>
+<p>{{if .Running}}Running{{else}}Finished{{end}}: {{.TrialsDone}} / {{.TrialsTotal}} trials ({{percent .TrialsDone .TrialsTotal}}), elapsed {{round .Elapsed}}{{if .Running}}, ETA {{round .ETA}}{{end}}</p>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Row</th><th>Trials</th><th>Elapsed</th><th>ETA</th></tr>
+{{range .Rows}}<tr><td>{{inc .Row}}</td><td>{{.TrialsDone}} / {{.TrialsTotal}}</td><td>{{round .Elapsed}}</td><td>{{round .ETA}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// sleepContext pauses for d, returning early with the context's error when
+// ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
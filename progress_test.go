@@ -0,0 +1,86 @@
+package taguchi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRun_Progress verifies that the ETA accounts for the remaining trials,
+// their repetitions and cooldowns while a run is in progress.
+func TestRun_Progress(t *testing.T) {
+	factors := []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	progress := &Progress{}
+	var mid RunStatus
+	calls := 0
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		calls++
+		if calls == 3 { // first run of the second trial
+			mid = progress.Status()
+		}
+		time.Sleep(time.Millisecond)
+		return 1, nil
+	}
+	cooldown := 20 * time.Millisecond
+	opts := RunOptions{Repetitions: 2, Cooldown: cooldown, Progress: progress}
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if !mid.Running || mid.TrialsDone != 1 || mid.TrialsTotal != 4 {
+		t.Fatalf("mid-run status = %+v, want running with 1 of 4 trials done", mid)
+	}
+	// Three trials and their cooldowns are left.
+	if mid.ETA < 3*cooldown || mid.ETA < 3*mid.MeanTrialTime {
+		t.Errorf("ETA = %v, want at least three trials plus cooldowns", mid.ETA)
+	}
+	if len(mid.Rows) != 2 || mid.Rows[0].TrialsDone != 1 || mid.Rows[1].ETA <= 0 {
+		t.Errorf("mid-run rows = %+v", mid.Rows)
+	}
+
+	done := progress.Status()
+	if done.Running || done.TrialsDone != 4 || done.ETA != 0 || done.Elapsed < 4*cooldown {
+		t.Errorf("final status = %+v", done)
+	}
+
+	rec := httptest.NewRecorder()
+	progress.ServeHTTP(rec, httptest.NewRequest("GET", "/?format=json", nil))
+	var served RunStatus
+	if err := json.NewDecoder(rec.Body).Decode(&served); err != nil || served.TrialsDone != 4 {
+		t.Errorf("JSON status = %+v, %v", served, err)
+	}
+	rec = httptest.NewRecorder()
+	progress.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "4 / 4 trials") {
+		t.Errorf("dashboard does not show the trial count:\n%s", body)
+	}
+}
+
+func TestProgress_RepetitionBudget(t *testing.T) {
+	p := &Progress{}
+	trials := []Trial{{ID: 1, Row: 0}, {ID: 2, Row: 1}}
+	p.begin(trials, RunOptions{Repetitions: 2, RepetitionBudget: 6}, 1)
+	if got := p.Status().TrialsTotal; got != 4 {
+		t.Fatalf("TrialsTotal = %d, want 2 trials plus 2 extra", got)
+	}
+	p.trialDone(0, 2, time.Second)
+	p.trialDone(1, 2, time.Second)
+	p.trialDone(1, 1, time.Second)
+	s := p.Status()
+	if s.TrialsTotal != 4 || s.Rows[1].TrialsTotal != 2 {
+		t.Errorf("status after an extra trial = %+v", s)
+	}
+	// One extra trial of one run is left at 0.6s per run.
+	if want := 600 * time.Millisecond; s.ETA != want {
+		t.Errorf("ETA = %v, want %v", s.ETA, want)
+	}
+}
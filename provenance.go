@@ -0,0 +1,115 @@
+package taguchi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrResultsTampered is returned by ReadResults when the archived results do
+// not match their checksum or HMAC signature.
+var ErrResultsTampered = errors.New("results do not match their checksum or signature")
+
+// ErrResultsUnsigned is returned by ReadResults when a key is given but the
+// archive carries no HMAC signature.
+var ErrResultsUnsigned = errors.New("results archive is not signed")
+
+// resultArchive is the serialized form written by WriteResults. The checksum
+// and signature cover the compact JSON encoding of Results, so re-indenting
+// the archive does not invalidate it.
+type resultArchive struct {
+	Results json.RawMessage `json:"results"`
+	SHA256  string          `json:"sha256"`
+	HMAC    string          `json:"hmac,omitempty"`
+}
+
+// WriteResults serializes the experiment's results as JSON together with a
+// SHA-256 checksum and, when key is non-empty, an HMAC-SHA256 signature, so
+// archived data used in compliance reports can be shown to be untampered.
+// Spilled observations are read back so the archive is self-contained.
+func (e *Experiment[P]) WriteResults(w io.Writer, key []byte) error {
+	results, err := e.portableResults()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	archive := resultArchive{Results: data, SHA256: checksum(data)}
+	if len(key) > 0 {
+		archive.HMAC = sign(data, key)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(archive)
+}
+
+// ReadResults reads results written by WriteResults and verifies their
+// checksum. When key is non-empty the HMAC signature is verified as well and
+// unsigned archives are rejected with ErrResultsUnsigned.
+func ReadResults(r io.Reader, key []byte) ([]TrialResult, error) {
+	var archive resultArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("decoding results archive: %w", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, archive.Results); err != nil {
+		return nil, fmt.Errorf("decoding results archive: %w", err)
+	}
+	data := compact.Bytes()
+	if checksum(data) != archive.SHA256 {
+		return nil, ErrResultsTampered
+	}
+	if len(key) > 0 {
+		if archive.HMAC == "" {
+			return nil, ErrResultsUnsigned
+		}
+		want, err := hex.DecodeString(archive.HMAC)
+		if err != nil || !hmac.Equal(want, signBytes(data, key)) {
+			return nil, ErrResultsTampered
+		}
+	}
+
+	var results []TrialResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("decoding results: %w", err)
+	}
+	return results, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func sign(data, key []byte) string {
+	return hex.EncodeToString(signBytes(data, key))
+}
+
+func signBytes(data, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// portableResults returns a copy of the results with spilled observations
+// read back into memory, suitable for serialization.
+func (e *Experiment[P]) portableResults() ([]TrialResult, error) {
+	results := make([]TrialResult, len(e.Results))
+	for i, r := range e.Results {
+		obs, err := r.RawObservations()
+		if err != nil {
+			return nil, fmt.Errorf("result %d: %w", i, err)
+		}
+		r.Observations, r.SpillFile = obs, ""
+		results[i] = r
+	}
+	return results, nil
+}
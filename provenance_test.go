@@ -0,0 +1,49 @@
+package taguchi
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteResults_RoundTripAndTamper(t *testing.T) {
+	exp := retentionExperiment(t, SmallerTheBetter{}, RetainSpill, t.TempDir())
+	key := []byte("secret")
+
+	var buf bytes.Buffer
+	if err := exp.WriteResults(&buf, key); err != nil {
+		t.Fatalf("WriteResults: %v", err)
+	}
+	archived := buf.String()
+
+	results, err := ReadResults(strings.NewReader(archived), key)
+	if err != nil {
+		t.Fatalf("ReadResults: %v", err)
+	}
+	if len(results) != len(exp.Results) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(exp.Results))
+	}
+	if got := results[0].Observations; len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Errorf("results[0].Observations = %v, want spilled observations [2 4]", got)
+	}
+
+	if _, err := ReadResults(strings.NewReader(archived), []byte("other")); !errors.Is(err, ErrResultsTampered) {
+		t.Errorf("wrong key: err = %v, want ErrResultsTampered", err)
+	}
+	tampered := strings.Replace(archived, "\"Sum\": 6", "\"Sum\": 5", 1)
+	if tampered == archived {
+		t.Fatal("test setup: observations not found in archive")
+	}
+	if _, err := ReadResults(strings.NewReader(tampered), nil); !errors.Is(err, ErrResultsTampered) {
+		t.Errorf("modified observations: err = %v, want ErrResultsTampered", err)
+	}
+
+	buf.Reset()
+	if err := exp.WriteResults(&buf, nil); err != nil {
+		t.Fatalf("WriteResults unsigned: %v", err)
+	}
+	if _, err := ReadResults(&buf, key); !errors.Is(err, ErrResultsUnsigned) {
+		t.Errorf("unsigned archive: err = %v, want ErrResultsUnsigned", err)
+	}
+}
@@ -0,0 +1,86 @@
+package taguchi
+
+import "math"
+
+// replicateSets splits the results of an array row into replicates: the k-th
+// replicate holds the k-th result recorded for every noise condition of the
+// row. Only complete replicates, with a result for every condition, are
+// returned.
+func replicateSets(results []TrialResult) [][]TrialResult {
+	byCondition := map[string][]TrialResult{}
+	for _, r := range results {
+		label := noiseLabel(r.Trial.Noise)
+		byCondition[label] = append(byCondition[label], r)
+	}
+	n := -1
+	for _, rs := range byCondition {
+		if n < 0 || len(rs) < n {
+			n = len(rs)
+		}
+	}
+	sets := make([][]TrialResult, max(n, 0))
+	for _, label := range sortedKeys(byCondition) {
+		for k := range sets {
+			sets[k] = append(sets[k], byCondition[label][k])
+		}
+	}
+	return sets
+}
+
+// replicateSNRs returns the SNR of every complete replicate of a row, with
+// infinite SNRs capped at the SNR ceiling, or nil for fewer than two
+// replicates.
+func (e *Experiment[P]) replicateSNRs(results []TrialResult) []float64 {
+	sets := replicateSets(results)
+	if len(sets) < 2 {
+		return nil
+	}
+	values := make([]float64, len(sets))
+	for k, set := range sets {
+		values[k] = e.resultsSNR(set)
+		if math.IsInf(values[k], 0) {
+			values[k] = math.Copysign(e.snrCeiling(), values[k])
+		}
+	}
+	return values
+}
+
+// replicateMeans returns the mean observation of every complete replicate of
+// a row, or nil for fewer than two replicates.
+func replicateMeans(results []TrialResult) []float64 {
+	sets := replicateSets(results)
+	if len(sets) < 2 {
+		return nil
+	}
+	values := make([]float64, len(sets))
+	for k, set := range sets {
+		var s ObservationSummary
+		for _, r := range set {
+			s.Merge(r.Summary)
+		}
+		values[k] = s.Sum / float64(s.Count)
+	}
+	return values
+}
+
+// pureError returns the pure-error sum of squares and degrees of freedom of
+// the included rows with replicates: the squared deviations of the replicate
+// values from their row mean, divided by the row's replicate count so that
+// the mean square estimates the variance of a row value, the scale of the
+// factor sums of squares.
+func (rows oaRowSNR) pureError() (float64, int) {
+	ss, df := 0.0, 0
+	for i, values := range rows.replicates {
+		if len(values) < 2 || !rows.included[i] || rows.missing[i] {
+			continue
+		}
+		mean := meanOf(values)
+		dev := 0.0
+		for _, v := range values {
+			dev += (v - mean) * (v - mean)
+		}
+		ss += dev / float64(len(values))
+		df += len(values) - 1
+	}
+	return ss, df
+}
@@ -0,0 +1,95 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+// replicatedExperiment returns an L4 experiment with the given factors whose
+// trials are recorded twice, the second time 10% slower.
+func replicatedExperiment(t *testing.T, names ...string) *Experiment[struct{}] {
+	t.Helper()
+	return newTestExperiment(t, testDesign{
+		Factors: namedFactors([]float64{1, 2}, names...),
+		Response: func(trial Trial) [][]float64 {
+			y := 2 + 3*trial.Control["A"] + trial.Control["B"]
+			return [][]float64{{y}, {1.1 * y}}
+		},
+	})
+}
+
+// TestANOVA_PureError verifies that a saturated design with replicated
+// trials gets its error term from the replicate spread instead of clamping.
+func TestANOVA_PureError(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B", "C")
+	result := mustAnalyze(t, exp)
+	a := result.ANOVA
+
+	// Each row's replicate SNRs differ by 20·log10(1.1) dB; with two
+	// replicates the scaled squared deviations add up to d²/4 per row.
+	d := 20 * math.Log10(1.1)
+	if want := 4 * d * d / 4; a.PureErrorDF != 4 || !almostEqual(a.PureErrorSS, want) {
+		t.Errorf("pure error SS %g with %d DF, want %g with 4 DF", a.PureErrorSS, a.PureErrorDF, want)
+	}
+	if a.ErrorDF != 4 || !almostEqual(a.ErrorSS, a.PureErrorSS) || !almostEqual(a.ErrorMS, a.PureErrorMS) {
+		t.Errorf("error SS %g, DF %d, MS %g; want the pure error", a.ErrorSS, a.ErrorDF, a.ErrorMS)
+	}
+	if a.LackOfFitF != 0 {
+		t.Errorf("lack of fit F = %g for a saturated design, want 0", a.LackOfFitF)
+	}
+	if !a.Significant["A"] {
+		t.Errorf("A with p = %g is not significant against the pure error", a.FactorP["A"])
+	}
+	if a.Significant["C"] {
+		t.Errorf("C, which has no effect, is significant with p = %g", a.FactorP["C"])
+	}
+	for _, w := range result.Warnings {
+		if w.Code == WarnSaturatedDesign {
+			t.Errorf("unexpected warning %s", w)
+		}
+	}
+}
+
+// TestANOVA_LackOfFit verifies that with residual degrees of freedom the
+// error pools the residual and the pure error and the residual is tested
+// against the pure error.
+func TestANOVA_LackOfFit(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	exp.AnalyzeMeans = true
+	result := mustAnalyze(t, exp)
+	a := result.ANOVA
+	if a.PureErrorDF != 4 || a.ErrorDF != 5 {
+		t.Errorf("pure error DF %d and error DF %d, want 4 and 5", a.PureErrorDF, a.ErrorDF)
+	}
+	residual := a.ErrorSS - a.PureErrorSS
+	if want := residual / a.PureErrorMS; !almostEqual(a.LackOfFitF, want) || a.LackOfFitP <= 0 || a.LackOfFitP > 1 {
+		t.Errorf("lack of fit F %g, p %g; want F %g and a probability", a.LackOfFitF, a.LackOfFitP, want)
+	}
+
+	// The means are additive, so the mean ANOVA has no lack of fit; its pure
+	// error comes from the replicate means.
+	m := result.MeanAnalysis.ANOVA
+	if m.PureErrorDF != 4 || m.PureErrorSS <= 0 {
+		t.Errorf("mean ANOVA pure error SS %g with %d DF, want positive with 4 DF", m.PureErrorSS, m.PureErrorDF)
+	}
+	if m.LackOfFitF > 1e-9 {
+		t.Errorf("mean ANOVA lack of fit F = %g, want 0 for additive means", m.LackOfFitF)
+	}
+}
+
+func TestReplicateSets(t *testing.T) {
+	result := func(load float64, y float64) TrialResult {
+		return TrialResult{Trial: Trial{Noise: map[string]float64{"Load": load}}, Observations: []float64{y}, Summary: summarize([]float64{y})}
+	}
+	// Load=0 has three results and Load=1 two: only two replicates are complete.
+	sets := replicateSets([]TrialResult{result(0, 1), result(1, 2), result(0, 3), result(1, 4), result(0, 5)})
+	if len(sets) != 2 {
+		t.Fatalf("%d replicates, want 2", len(sets))
+	}
+	if got := replicateMeans([]TrialResult{result(0, 1), result(1, 2), result(0, 3), result(1, 4)}); got[0] != 1.5 || got[1] != 3.5 {
+		t.Errorf("replicate means %v, want [1.5 3.5]", got)
+	}
+	if got := replicateMeans([]TrialResult{result(0, 1), result(1, 2)}); got != nil {
+		t.Errorf("replicate means of a single replicate = %v, want nil", got)
+	}
+}
@@ -0,0 +1,306 @@
+package taguchi
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// RangeExperiment is an experiment over continuous factor ranges. Its runs
+// are points of a space-filling design (LatinHypercube or Sobol) rather than
+// rows of an orthogonal array, so level-based main effects do not apply; the
+// SNR of each point is analyzed by regression instead (see Analyze).
+// Factors: The continuous control factors.
+// NoiseFactors: Uncontrollable environmental factors, crossed with every point like in Experiment.
+// Goal: Optimization goal.
+// Points: Factor settings of each run, in factor order; set by LatinHypercube or Sobol.
+// Results: Collection of TrialResults; a trial's Row is the index of its point.
+// Seed: Seed of the random Latin hypercube, making it reproducible.
+type RangeExperiment struct {
+	Factors      []RangeFactor
+	NoiseFactors []NoiseFactor
+	Goal         OptimizationGoal
+	Points       [][]float64
+	Results      []TrialResult
+	Seed         int64
+}
+
+// NewRangeExperiment initializes an experiment over continuous factor ranges.
+// Generate its points with LatinHypercube or Sobol before GenerateTrials.
+func NewRangeExperiment(goal OptimizationGoal, factors []RangeFactor, noiseFactors []NoiseFactor) (*RangeExperiment, error) {
+	if len(factors) == 0 {
+		return nil, fmt.Errorf("no factors")
+	}
+	seen := map[string]bool{}
+	for _, f := range factors {
+		if seen[f.Name] {
+			return nil, fmt.Errorf("duplicate factor %s", f.Name)
+		}
+		seen[f.Name] = true
+		if !(f.Min < f.Max) {
+			return nil, fmt.Errorf("factor %s: Min %g must be below Max %g", f.Name, f.Min, f.Max)
+		}
+	}
+	return &RangeExperiment{Factors: factors, NoiseFactors: noiseFactors, Goal: goal}, nil
+}
+
+// LatinHypercube sets the design to n random points that split every factor's
+// range into n equal strata with exactly one point in each, so every factor is
+// explored evenly whatever the number of factors.
+func (r *RangeExperiment) LatinHypercube(n int) error {
+	if err := r.checkPoints(n); err != nil {
+		return err
+	}
+	r.setPoints(latinHypercubePoints(n, len(r.Factors), rand.New(rand.NewSource(r.Seed))))
+	return nil
+}
+
+// Sobol sets the design to the first n points of the Sobol low-discrepancy
+// sequence, which fills the factor space more evenly than random points and
+// is deterministic. Powers of two give the most uniform coverage.
+func (r *RangeExperiment) Sobol(n int) error {
+	if err := r.checkPoints(n); err != nil {
+		return err
+	}
+	points, err := sobolPoints(n, len(r.Factors))
+	if err != nil {
+		return err
+	}
+	r.setPoints(points)
+	return nil
+}
+
+// checkPoints rejects designs too small for the regression in Analyze.
+func (r *RangeExperiment) checkPoints(n int) error {
+	if len(r.Results) > 0 {
+		return fmt.Errorf("results already recorded for the current design")
+	}
+	if need := len(r.Factors) + 2; n < need {
+		return fmt.Errorf("%d points cannot estimate %d factors; use at least %d", n, len(r.Factors), need)
+	}
+	return nil
+}
+
+// setPoints maps points from the unit cube onto the factor ranges.
+func (r *RangeExperiment) setPoints(unit [][]float64) {
+	r.Points = make([][]float64, len(unit))
+	for i, u := range unit {
+		r.Points[i] = make([]float64, len(r.Factors))
+		for j, f := range r.Factors {
+			r.Points[i][j] = f.value(u[j])
+		}
+	}
+}
+
+// noiseConditions returns every combination of the noise factor levels.
+func (r *RangeExperiment) noiseConditions() []Trial {
+	return (&Experiment[struct{}]{NoiseFactors: r.NoiseFactors}).generateNoiseCombinations()
+}
+
+// GenerateTrials crosses every design point with every noise condition.
+func (r *RangeExperiment) GenerateTrials() []Trial {
+	conditions := r.noiseConditions()
+	var trials []Trial
+	for i, point := range r.Points {
+		for _, c := range conditions {
+			control := make(map[string]float64, len(r.Factors))
+			for j, f := range r.Factors {
+				control[f.Name] = point[j]
+			}
+			trials = append(trials, Trial{ID: len(trials) + 1, Row: i, Control: control, Noise: c.Noise, Key: TrialKey(control, c.Noise)})
+		}
+	}
+	return trials
+}
+
+// AddResult records the observations of a trial generated by GenerateTrials.
+// Like Experiment.AddResult it rejects trials that are not part of the design
+// and empty or non-finite observations; repeated results for a trial are
+// analyzed as replicates.
+func (r *RangeExperiment) AddResult(trial Trial, observations []float64) error {
+	if trial.Row < 0 || trial.Row >= len(r.Points) {
+		return &TrialError{Trial: trial.ID, Err: errorf(ErrTrialNotInDesign, "invalid point index %d", trial.Row)}
+	}
+	for j, f := range r.Factors {
+		if v, ok := trial.Control[f.Name]; !ok || v != r.Points[trial.Row][j] {
+			return &TrialError{Trial: trial.ID, Err: errorf(ErrLevelMismatch, "control factor %s does not match point %d", f.Name, trial.Row+1)}
+		}
+	}
+	known := false
+	for _, c := range r.noiseConditions() {
+		known = known || maps.Equal(c.Noise, trial.Noise)
+	}
+	if !known {
+		return &TrialError{Trial: trial.ID, Err: errorf(ErrTrialNotInDesign, "noise condition %v is not part of the design", trial.Noise)}
+	}
+	if err := validateObservations(observations); err != nil {
+		return &TrialError{Trial: trial.ID, Err: err}
+	}
+	r.Results = append(r.Results, TrialResult{Trial: trial, Observations: observations, Summary: summarize(observations)})
+	return nil
+}
+
+// RangeEffect is the fitted effect of a continuous factor, in coded units
+// where the factor's range maps onto [-1, 1].
+// Factor: Name of the factor.
+// Linear, Quadratic: Coefficients of the coded setting and its square; Quadratic is 0 in a linear model.
+// SS: Extra sum of squares of the factor's terms over the model without them.
+// Contribution: SS as a percentage of the total SS.
+// F, P: F-ratio and p-value of the factor's terms.
+// Significant: Whether P is below DefaultAlpha.
+// Optimum: Setting in the factor's range that maximizes the fitted SNR.
+type RangeEffect struct {
+	Factor       string
+	Linear       float64
+	Quadratic    float64
+	SS           float64
+	Contribution float64
+	F            float64
+	P            float64
+	Significant  bool
+	Optimum      float64
+}
+
+// RangeAnalysis is the regression analysis of a RangeExperiment.
+// Effects: Fitted effect of each factor, in factor order.
+// OptimalSettings: Optimum of each factor; the model has no interactions, so the factors are optimized independently.
+// PredictedSNR: Fitted SNR at OptimalSettings.
+// Intercept: Fitted SNR at the center of the ranges.
+// Quadratic: Whether the model has squared terms, which needs at least 2k+2 points for k factors.
+// RSquared: Share of the SNR variation explained by the model.
+// ErrorDF, ErrorMS: Residual degrees of freedom and mean square.
+// ErrorContribution: Residual SS as a percentage of the total SS.
+type RangeAnalysis struct {
+	Effects           []RangeEffect
+	OptimalSettings   map[string]float64
+	PredictedSNR      float64
+	Intercept         float64
+	Quadratic         bool
+	RSquared          float64
+	ErrorDF           int
+	ErrorMS           float64
+	ErrorContribution float64
+}
+
+// Analyze computes the SNR of every design point over all its observations
+// and fits it by least squares with a linear and, given enough points, a
+// squared term per factor. Each factor's significance is the extra sum of
+// squares of its terms; since space-filling designs are only nearly
+// orthogonal, the contributions need not add up to exactly 100%. Every point
+// needs results. Infinite SNRs are capped at ±DefaultSNRCeiling.
+func (r *RangeExperiment) Analyze() (RangeAnalysis, error) {
+	k, n := len(r.Factors), len(r.Points)
+	if n == 0 {
+		return RangeAnalysis{}, fmt.Errorf("no design points; call LatinHypercube or Sobol")
+	}
+	byPoint := make([][]TrialResult, n)
+	for _, res := range r.Results {
+		byPoint[res.Trial.Row] = append(byPoint[res.Trial.Row], res)
+	}
+	snr := make([]float64, n)
+	goal := &Experiment[struct{}]{Goal: r.Goal}
+	var missing []int
+	for i, results := range byPoint {
+		if len(results) == 0 {
+			missing = append(missing, i+1)
+			continue
+		}
+		snr[i] = goal.resultsSNR(results)
+		if math.IsInf(snr[i], 0) {
+			snr[i] = math.Copysign(DefaultSNRCeiling, snr[i])
+		}
+	}
+	if len(missing) > 0 {
+		return RangeAnalysis{}, errorf(ErrMissingResults, "design points %v have no results", missing)
+	}
+
+	quadratic := n >= 2*k+2
+	terms := 1
+	if quadratic {
+		terms = 2
+	}
+	// design returns the model matrix, leaving out the terms of factor skip.
+	design := func(skip int) [][]float64 {
+		x := make([][]float64, n)
+		for i, point := range r.Points {
+			row := []float64{1}
+			for j, f := range r.Factors {
+				if j == skip {
+					continue
+				}
+				c := f.coded(point[j])
+				row = append(row, c)
+				if quadratic {
+					row = append(row, c*c)
+				}
+			}
+			x[i] = row
+		}
+		return x
+	}
+	coef, rss, err := leastSquares(design(-1), snr)
+	if err != nil {
+		return RangeAnalysis{}, err
+	}
+
+	mean := 0.0
+	for _, y := range snr {
+		mean += y / float64(n)
+	}
+	totalSS := 0.0
+	for _, y := range snr {
+		totalSS += (y - mean) * (y - mean)
+	}
+	a := RangeAnalysis{
+		OptimalSettings: map[string]float64{},
+		Intercept:       coef[0],
+		PredictedSNR:    coef[0],
+		Quadratic:       quadratic,
+		ErrorDF:         n - 1 - k*terms,
+	}
+	a.ErrorMS = rss / float64(a.ErrorDF)
+	if totalSS > 0 {
+		a.RSquared = 1 - rss/totalSS
+		a.ErrorContribution = rss / totalSS * 100
+	}
+	for j, f := range r.Factors {
+		e := RangeEffect{Factor: f.Name, Linear: coef[1+j*terms]}
+		if quadratic {
+			e.Quadratic = coef[2+j*terms]
+		}
+		_, reduced, err := leastSquares(design(j), snr)
+		if err != nil {
+			return RangeAnalysis{}, err
+		}
+		e.SS = math.Max(reduced-rss, 0)
+		if totalSS > 0 {
+			e.Contribution = e.SS / totalSS * 100
+		}
+		if a.ErrorMS > 0 {
+			e.F = e.SS / float64(terms) / a.ErrorMS
+			e.P = fSurvival(e.F, terms, a.ErrorDF)
+			e.Significant = e.P < DefaultAlpha
+		}
+		best := bestCoded(e.Linear, e.Quadratic)
+		e.Optimum = f.uncoded(best)
+		a.OptimalSettings[f.Name] = e.Optimum
+		a.PredictedSNR += e.Linear*best + e.Quadratic*best*best
+		a.Effects = append(a.Effects, e)
+	}
+	return a, nil
+}
+
+// bestCoded returns the c in [-1, 1] that maximizes linear·c + quadratic·c².
+func bestCoded(linear, quadratic float64) float64 {
+	f := func(c float64) float64 { return linear*c + quadratic*c*c }
+	candidates := []float64{-1, 1}
+	if quadratic < 0 {
+		if v := -linear / (2 * quadratic); v > -1 && v < 1 {
+			candidates = append(candidates, v)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return f(candidates[i]) > f(candidates[j]) })
+	return candidates[0]
+}
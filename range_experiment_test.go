@@ -0,0 +1,103 @@
+package taguchi
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestSobolPoints_Stratified verifies the (0, m, 2)-net property of the first
+// two Sobol dimensions and the one-point-per-stratum property of every dimension.
+func TestSobolPoints_Stratified(t *testing.T) {
+	const n = 64
+	points, err := sobolPoints(n, MaxSobolDimensions)
+	if err != nil {
+		t.Fatalf("sobolPoints: %v", err)
+	}
+	for d := 0; d < MaxSobolDimensions; d++ {
+		seen := make([]bool, n)
+		for _, p := range points {
+			seen[int(p[d]*n)] = true
+		}
+		for s, ok := range seen {
+			if !ok {
+				t.Errorf("dimension %d: no point in stratum %d", d+1, s)
+				break
+			}
+		}
+	}
+	// Every 8×8 box of the first two dimensions holds exactly one point.
+	boxes := map[[2]int]int{}
+	for _, p := range points {
+		boxes[[2]int{int(p[0] * 8), int(p[1] * 8)}]++
+	}
+	if len(boxes) != n {
+		t.Errorf("first two dimensions fill %d of %d boxes", len(boxes), n)
+	}
+	if _, err := sobolPoints(n, MaxSobolDimensions+1); err == nil {
+		t.Error("sobolPoints accepted too many dimensions")
+	}
+}
+
+func TestLatinHypercubePoints_Stratified(t *testing.T) {
+	points := latinHypercubePoints(10, 3, rand.New(rand.NewSource(1)))
+	for d := 0; d < 3; d++ {
+		seen := map[int]bool{}
+		for _, p := range points {
+			seen[int(p[d]*10)] = true
+		}
+		if len(seen) != 10 {
+			t.Errorf("dimension %d covers %d of 10 strata", d+1, len(seen))
+		}
+	}
+}
+
+func TestRangeExperiment_Analyze(t *testing.T) {
+	factors := []RangeFactor{
+		{Name: "Temp", Min: 100, Max: 200},
+		{Name: "Speed", Min: 1, Max: 5},
+		{Name: "Idle", Min: 0, Max: 1},
+	}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	exp, err := NewRangeExperiment(LargerTheBetter{}, factors, noise)
+	if err != nil {
+		t.Fatalf("NewRangeExperiment: %v", err)
+	}
+	if err := exp.Sobol(32); err != nil {
+		t.Fatalf("Sobol: %v", err)
+	}
+	jitter := rand.New(rand.NewSource(7))
+	for _, trial := range exp.GenerateTrials() {
+		// Yield peaks at Temp = 160 and grows with Speed; Idle does nothing.
+		d := trial.Control["Temp"] - 160
+		y := 100 - d*d/100 + 4*trial.Control["Speed"] + trial.Noise["N"] + 3*jitter.NormFloat64()
+		if err := exp.AddResult(trial, []float64{y}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+
+	a, err := exp.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if !a.Quadratic || a.RSquared < 0.9 {
+		t.Errorf("Quadratic = %v, R² = %.3f; want a well-fitting quadratic model", a.Quadratic, a.RSquared)
+	}
+	if got := a.OptimalSettings["Temp"]; math.Abs(got-160) > 5 {
+		t.Errorf("optimal Temp = %.1f, want about 160", got)
+	}
+	if got := a.OptimalSettings["Speed"]; got != 5 {
+		t.Errorf("optimal Speed = %g, want the upper bound 5", got)
+	}
+	if !a.Effects[0].Significant || !a.Effects[1].Significant || a.Effects[2].Significant {
+		t.Errorf("significance Temp=%v Speed=%v Idle=%v, want Temp and Speed only",
+			a.Effects[0].Significant, a.Effects[1].Significant, a.Effects[2].Significant)
+	}
+
+	if err := exp.AddResult(Trial{Row: 0, Control: map[string]float64{"Temp": 1}}, []float64{1}); err == nil {
+		t.Error("AddResult accepted a trial off the design")
+	}
+	if err := exp.LatinHypercube(32); err == nil {
+		t.Error("LatinHypercube replaced a design with recorded results")
+	}
+}
@@ -0,0 +1,77 @@
+package taguchi
+
+import (
+	"context"
+	"math"
+)
+
+// rebalance spends the measured runs left in opts.RepetitionBudget after the
+// first pass over rows. Each round adds one repetition to every trial of the
+// row with the highest rowUncertainty and stops when the next round would
+// exceed the budget.
+func (e *Experiment[P]) rebalance(ctx context.Context, rows [][]Trial, measure MeasureFunc, opts RunOptions, applied map[string]float64) error {
+	spent := 0
+	for _, row := range rows {
+		spent += len(row) * opts.Repetitions
+	}
+	extra := opts
+	extra.Repetitions = 1
+	for {
+		best, bestScore := -1, 0.0
+		for r, row := range rows {
+			if spent+len(row) > opts.RepetitionBudget {
+				continue
+			}
+			if score := e.rowUncertainty(row); score > bestScore {
+				best, bestScore = r, score
+			}
+		}
+		if best < 0 {
+			return nil
+		}
+		row := rows[best]
+		if err := e.applyLevels(ctx, row[0].Control, applied); err != nil {
+			return err
+		}
+		if err := e.runRow(ctx, row, measure, extra); err != nil {
+			return err
+		}
+		spent += len(row)
+	}
+}
+
+// rowUncertainty scores how uncertain the mean response of a row's trials is:
+// the pooled repetition variance of its trials relative to the squared mean,
+// divided by the repetitions per trial. It is scale-free, so rows with large
+// responses are not favored, and it shrinks as repetitions are added.
+func (e *Experiment[P]) rowUncertainty(row []Trial) float64 {
+	byID := map[int]*ObservationSummary{}
+	for _, t := range row {
+		byID[t.ID] = &ObservationSummary{}
+	}
+	for _, r := range e.Results {
+		if s, ok := byID[r.Trial.ID]; ok {
+			s.Merge(r.Summary)
+		}
+	}
+	var total ObservationSummary
+	ss, df, minCount := 0.0, 0, math.MaxInt
+	for _, s := range byID {
+		total.Merge(*s)
+		minCount = min(minCount, s.Count)
+		if s.Count < 2 {
+			continue
+		}
+		ss += sampleVariance(*s) * float64(s.Count-1)
+		df += s.Count - 1
+	}
+	if df == 0 || total.Count == 0 {
+		return 0
+	}
+	mean := total.Sum / float64(total.Count)
+	variance := ss / float64(df)
+	if mean == 0 {
+		return variance / float64(minCount)
+	}
+	return variance / (mean * mean) / float64(minCount)
+}
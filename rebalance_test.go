@@ -0,0 +1,50 @@
+package taguchi
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+// TestRun_RepetitionBudget verifies that spare runs go to the noisy row,
+// equally to each of its noise conditions, without exceeding the budget.
+func TestRun_RepetitionBudget(t *testing.T) {
+	factors := []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	calls := 0
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		calls++
+		spread := 0.01
+		if trial.Control["A"] == 2 {
+			spread = 3
+		}
+		return 10 + trial.Noise["N"] + spread*rng.NormFloat64(), nil
+	}
+	opts := RunOptions{Repetitions: 2, RepetitionBudget: 17}
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if calls != 16 {
+		t.Errorf("measured %d runs, want 16 (8 initial + 4 rounds of 2 within the budget of 17)", calls)
+	}
+
+	counts := map[int]int{}
+	for _, r := range exp.Results {
+		counts[r.Trial.ID] += r.Summary.Count
+	}
+	if counts[1] != 2 || counts[2] != 2 {
+		t.Errorf("quiet row got %d and %d repetitions, want 2 each", counts[1], counts[2])
+	}
+	if counts[3] != 6 || counts[4] != 6 {
+		t.Errorf("noisy row got %d and %d repetitions, want 6 each", counts[3], counts[4])
+	}
+
+	if err := exp.Run(context.Background(), measure, RunOptions{Repetitions: 1, RepetitionBudget: 10}); err == nil {
+		t.Error("Run accepted a repetition budget with a single repetition")
+	}
+}
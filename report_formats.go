@@ -0,0 +1,309 @@
+package taguchi
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// reportTable is one table of the Markdown and Excel reports.
+type reportTable struct {
+	title  string
+	header []string
+	rows   [][]reportCell
+}
+
+// reportCell is a table cell: text, or a number rendered with the report's
+// NumberFormat.
+type reportCell struct {
+	text    string
+	value   float64
+	number  bool
+	percent bool
+}
+
+func textCell(s string) reportCell                    { return reportCell{text: s} }
+func numberCell(v float64) reportCell                 { return reportCell{value: v, number: true} }
+func percentCell(v float64) reportCell                { return reportCell{value: v, number: true, percent: true} }
+func intCell(n int) reportCell                        { return reportCell{text: strconv.Itoa(n)} }
+func levelCell(nf NumberFormat, v float64) reportCell { return textCell(nf.FormatLevel(v)) }
+
+// format renders the cell as text.
+func (c reportCell) format(nf NumberFormat) string {
+	switch {
+	case !c.number:
+		return c.text
+	case c.percent:
+		return nf.FormatPercent(c.value)
+	default:
+		return nf.Format(c.value)
+	}
+}
+
+// reportTables collects the tables shared by the Markdown and Excel reports:
+// optimal levels, main effects, contributions and the ANOVA.
+func reportTables(result AnalysisResult, nf NumberFormat) []reportTable {
+	optimal := reportTable{title: "Optimal Factor Levels", header: []string{"Factor", "Level"}}
+	for _, factor := range sortedKeys(result.OptimalLevels) {
+		optimal.rows = append(optimal.rows, []reportCell{textCell(factor), levelCell(nf, result.OptimalLevels[factor])})
+	}
+	for _, opt := range result.Interpolated {
+		optimal.rows = append(optimal.rows, []reportCell{textCell(opt.Factor + " (interpolated)"), levelCell(nf, opt.Level)})
+	}
+
+	width := 0
+	for _, effects := range result.MainEffects {
+		width = max(width, len(effects))
+	}
+	effects := reportTable{title: "Main Effects (Average SNR per Level)", header: []string{"Factor"}}
+	for i := 1; i <= width; i++ {
+		effects.header = append(effects.header, "Level "+strconv.Itoa(i))
+	}
+	for _, factor := range sortedKeys(result.MainEffects) {
+		row := []reportCell{textCell(factor)}
+		for i := 0; i < width; i++ {
+			if i < len(result.MainEffects[factor]) {
+				row = append(row, numberCell(result.MainEffects[factor][i]))
+			} else {
+				row = append(row, textCell(""))
+			}
+		}
+		effects.rows = append(effects.rows, row)
+	}
+
+	contributions := reportTable{title: "Contribution of Each Factor", header: []string{"Factor", "Contribution"}}
+	for _, factor := range sortedKeys(result.Contributions) {
+		contributions.rows = append(contributions.rows, []reportCell{textCell(factor), percentCell(result.Contributions[factor])})
+	}
+	contributions.rows = append(contributions.rows, []reportCell{textCell("Error"), percentCell(result.ErrorContribution)})
+
+	a := result.ANOVA
+	anova := reportTable{title: "ANOVA", header: []string{"Source", "SS", "DF", "F-ratio", "p-value", "Significant"}}
+	for _, factor := range sortedKeys(a.FactorSS) {
+		significant := ""
+		if a.Significant[factor] {
+			significant = "*"
+		}
+		anova.rows = append(anova.rows, []reportCell{
+			textCell(factor), numberCell(a.FactorSS[factor]), intCell(a.FactorDF[factor]),
+			numberCell(a.FactorF[factor]), numberCell(a.FactorP[factor]), textCell(significant),
+		})
+	}
+	errorRow := func(name string, ss float64, df int) []reportCell {
+		return []reportCell{textCell(name), numberCell(ss), intCell(df), textCell(""), textCell(""), textCell("")}
+	}
+	anova.rows = append(anova.rows, errorRow("Error", a.ErrorSS, a.ErrorDF))
+	if a.ColumnErrorDF > 0 {
+		anova.rows = append(anova.rows, errorRow("Error columns", a.ColumnErrorSS, a.ColumnErrorDF))
+	}
+	if a.PureErrorDF > 0 {
+		anova.rows = append(anova.rows, errorRow("Pure error", a.PureErrorSS, a.PureErrorDF))
+	}
+	if a.UnassignedDF > 0 {
+		anova.rows = append(anova.rows, errorRow("Unassigned", a.UnassignedSS, a.UnassignedDF))
+	}
+
+	tables := []reportTable{optimal, effects, contributions, anova}
+	if len(result.Warnings) > 0 {
+		warnings := reportTable{title: "Warnings", header: []string{"Warning"}}
+		for _, w := range result.Warnings {
+			warnings.rows = append(warnings.rows, []reportCell{textCell(w.String())})
+		}
+		tables = append(tables, warnings)
+	}
+	return tables
+}
+
+// WriteMarkdownReport writes the optimal levels, main effects,
+// contributions, ANOVA and warnings of the analysis as Markdown tables, with
+// every number formatted by opts.Numbers like WriteAnalysisReport.
+func WriteMarkdownReport(w io.Writer, result AnalysisResult, opts ReportOptions) error {
+	nf := opts.Numbers
+	var b strings.Builder
+	fmt.Fprintln(&b, "# Taguchi Analysis Report")
+	for _, t := range reportTables(result, nf) {
+		fmt.Fprintf(&b, "\n## %s\n\n", t.title)
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(t.header, " | "))
+		fmt.Fprintf(&b, "|%s\n", strings.Repeat(" --- |", len(t.header)))
+		for _, row := range t.rows {
+			cells := make([]string, len(row))
+			for i, c := range row {
+				cells[i] = strings.ReplaceAll(c.format(nf), "|", `\|`)
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteExcelReport writes the tables of WriteMarkdownReport as an Excel
+// workbook (.xlsx), one worksheet per table. Numbers stay numeric cells so
+// they can be charted and summed: they are rounded as opts.Numbers renders
+// them and displayed with its decimals and thousands grouping. The
+// separators themselves follow the locale of the spreadsheet application.
+func WriteExcelReport(w io.Writer, result AnalysisResult, opts ReportOptions) error {
+	nf := opts.Numbers
+	tables := reportTables(result, nf)
+	z := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes(len(tables))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(tables)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(tables))},
+		{"xl/styles.xml", xlsxStyles(nf)},
+	}
+	for i, t := range tables {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheet(t, nf)})
+	}
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func xlsxContentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func xlsxWorkbook(tables []reportTable) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, t := range tables {
+		// Sheet names are limited to 31 characters.
+		name := t.title
+		if cut, _, ok := strings.Cut(name, " ("); ok {
+			name = cut
+		}
+		if len(name) > 31 {
+			name = name[:31]
+		}
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func xlsxWorkbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// xlsxStyles defines the cell styles: 0 for text, 1 for numbers and 2 for
+// percentages, with the number format code of nf.
+func xlsxStyles(nf NumberFormat) string {
+	code := excelNumberFormat(nf)
+	return xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		fmt.Sprintf(`<numFmts count="2"><numFmt numFmtId="164" formatCode="%s"/><numFmt numFmtId="165" formatCode="%s&quot;%%&quot;"/></numFmts>`, xmlEscape(code), xmlEscape(code)) +
+		`<fonts count="1"><font/></fonts><fills count="1"><fill/></fills><borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf/><xf numFmtId="164" applyNumberFormat="1"/><xf numFmtId="165" applyNumberFormat="1"/></cellXfs>` +
+		`</styleSheet>`
+}
+
+// excelNumberFormat returns the Excel format code matching nf: its decimals
+// and thousands grouping, or General for significant digits, whose
+// decimals vary with the magnitude.
+func excelNumberFormat(nf NumberFormat) string {
+	if nf.SignificantDigits > 0 {
+		return "General"
+	}
+	code := "0"
+	if nf.Locale.Thousands != "" {
+		code = "#,##0"
+	}
+	if nf.Decimals > 0 {
+		code += "." + strings.Repeat("0", nf.Decimals)
+	}
+	return code
+}
+
+func xlsxSheet(t reportTable, nf NumberFormat) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	header := make([]reportCell, len(t.header))
+	for i, h := range t.header {
+		header[i] = textCell(h)
+	}
+	for r, row := range append([][]reportCell{header}, t.rows...) {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := excelColumn(c) + strconv.Itoa(r+1)
+			v, ok := cell.rounded(nf)
+			switch {
+			case ok && cell.percent:
+				fmt.Fprintf(&b, `<c r="%s" s="2"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'g', -1, 64))
+			case ok:
+				fmt.Fprintf(&b, `<c r="%s" s="1"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'g', -1, 64))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, xmlEscape(cell.format(nf)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// rounded returns the value of a finite number cell rounded as nf.Format
+// renders it; non-finite numbers are written as text.
+func (c reportCell) rounded(nf NumberFormat) (float64, bool) {
+	if !c.number || math.IsNaN(c.value) || math.IsInf(c.value, 0) {
+		return 0, false
+	}
+	plain := nf
+	plain.Locale = LocalePlain
+	v, err := strconv.ParseFloat(plain.Format(c.value), 64)
+	return v, err == nil
+}
+
+// excelColumn returns the letters of the zero-based column c, e.g. "AA" for 26.
+func excelColumn(c int) string {
+	name := ""
+	for c++; c > 0; c = (c - 1) / 26 {
+		name = string(rune('A'+(c-1)%26)) + name
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
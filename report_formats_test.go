@@ -0,0 +1,130 @@
+package taguchi
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestWriteMarkdownReport verifies that the Markdown report formats every
+// number with the report's NumberFormat.
+func TestWriteMarkdownReport(t *testing.T) {
+	result := mustAnalyze(t, replicatedExperiment(t, "A", "B"))
+	nf := NumberFormat{Decimals: 2, Locale: LocaleGerman}
+	var buf bytes.Buffer
+	if err := WriteMarkdownReport(&buf, result, ReportOptions{Numbers: nf}); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+
+	effects := result.MainEffects["A"]
+	want := "| A | " + nf.Format(effects[0]) + " | " + nf.Format(effects[1]) + " |"
+	if !strings.Contains(md, want) {
+		t.Errorf("main effects row %q missing from report:\n%s", want, md)
+	}
+	if !strings.Contains(md, "| A | "+nf.FormatPercent(result.Contributions["A"])+" |") {
+		t.Errorf("contribution of A not formatted with %+v:\n%s", nf, md)
+	}
+	if strings.Contains(md, strconv.FormatFloat(effects[0], 'f', 4, 64)) {
+		t.Errorf("report contains the unformatted value %v:\n%s", effects[0], md)
+	}
+	if !strings.Contains(md, "## ANOVA\n\n| Source | SS | DF | F-ratio | p-value | Significant |\n| --- | --- | --- | --- | --- | --- |\n") {
+		t.Errorf("ANOVA table header missing:\n%s", md)
+	}
+}
+
+// TestWriteExcelReport verifies that the Excel report stores numbers as
+// numeric cells rounded to the report's decimals and displayed with its
+// number format.
+func TestWriteExcelReport(t *testing.T) {
+	result := mustAnalyze(t, replicatedExperiment(t, "A", "B"))
+	nf := NumberFormat{Decimals: 2, Locale: LocaleGerman}
+	var buf bytes.Buffer
+	if err := WriteExcelReport(&buf, result, ReportOptions{Numbers: nf}); err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("report is not a zip archive: %v", err)
+	}
+	read := func(name string) string {
+		t.Helper()
+		f, err := z.Open(name)
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if styles := read("xl/styles.xml"); !strings.Contains(styles, `formatCode="#,##0.00"`) {
+		t.Errorf("styles lack the number format of %+v:\n%s", nf, styles)
+	}
+	if workbook := read("xl/workbook.xml"); !strings.Contains(workbook, `<sheet name="Main Effects" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("workbook lacks the main effects sheet:\n%s", workbook)
+	}
+	effects := read("xl/worksheets/sheet2.xml")
+	a := math.Round(result.MainEffects["A"][0]*100) / 100
+	want := `<c r="B2" s="1"><v>` + strconv.FormatFloat(a, 'g', -1, 64) + `</v></c>`
+	if !strings.Contains(effects, want) {
+		t.Errorf("main effects sheet lacks %s:\n%s", want, effects)
+	}
+	if !strings.Contains(effects, `<c r="A2" t="inlineStr"><is><t>A</t></is></c>`) {
+		t.Errorf("main effects sheet lacks the factor name:\n%s", effects)
+	}
+}
+
+// TestExcelNumberFormat verifies the Excel format codes of number formats.
+func TestExcelNumberFormat(t *testing.T) {
+	tests := []struct {
+		format NumberFormat
+		want   string
+	}{
+		{NumberFormat{Decimals: 3, Locale: LocalePlain}, "0.000"},
+		{NumberFormat{Decimals: 1, Locale: LocaleSwiss}, "#,##0.0"},
+		{NumberFormat{Locale: LocaleEnglish}, "#,##0"},
+		{NumberFormat{SignificantDigits: 3, Locale: LocaleEnglish}, "General"},
+	}
+	for _, tt := range tests {
+		if got := excelNumberFormat(tt.format); got != tt.want {
+			t.Errorf("excelNumberFormat(%+v) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+// TestWriteHTMLReport verifies that the HTML report formats every number
+// with the report's NumberFormat.
+func TestWriteHTMLReport(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	result := mustAnalyze(t, exp)
+	nf := NumberFormat{SignificantDigits: 3, Locale: LocaleGerman}
+	var buf bytes.Buffer
+	if err := exp.WriteHTMLReport(&buf, result, ReportOptions{Numbers: nf}); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	effects := result.MainEffects["A"]
+	level := exp.ControlFactors[0].Levels[0]
+	want := "<td>" + nf.Format(effects[0]) + " (" + nf.FormatLevel(level) + ")</td>"
+	if !strings.Contains(page, want) {
+		t.Errorf("main effects cell %q missing from report", want)
+	}
+	if !strings.Contains(page, "<td>"+nf.FormatPercent(result.Contributions["A"])+"</td>") {
+		t.Errorf("contribution of A not formatted with %+v", nf)
+	}
+	if !strings.Contains(page, "alpha = "+nf.Format(result.ANOVA.Alpha)) {
+		t.Errorf("alpha not formatted with %+v", nf)
+	}
+	if !strings.Contains(page, "<svg") || !strings.Contains(page, "TAGUCHI ANALYSIS REPORT") {
+		t.Error("report lacks the plot or the text report")
+	}
+}
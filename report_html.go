@@ -0,0 +1,171 @@
+package taguchi
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// htmlRow is a table row of the HTML report.
+type htmlRow struct {
+	Cells       []string
+	Significant bool
+}
+
+// htmlReport is the data rendered by htmlReportTemplate.
+type htmlReport struct {
+	Goal     string
+	Runs     int
+	Results  int
+	Optimal  []htmlRow
+	Plot     template.HTML
+	Levels   []string
+	Effects  []htmlRow
+	ANOVA    []htmlRow
+	Alpha    string
+	Warnings []string
+	Text     string
+}
+
+// WriteHTMLReport writes the analysis of the experiment as a standalone HTML
+// page: optimal levels, a main-effects plot, the SNR per level, the ANOVA
+// table with contributions, warnings and the full text report, with every
+// number formatted by opts.Numbers like WriteAnalysisReport.
+func (e *Experiment[P]) WriteHTMLReport(w io.Writer, result AnalysisResult, opts ReportOptions) error {
+	nf := opts.Numbers
+	r := htmlReport{
+		Goal:    e.Goal.String(),
+		Runs:    len(e.OrthogonalArray),
+		Results: len(e.Results),
+		Alpha:   nf.Format(result.ANOVA.Alpha),
+	}
+	for _, factor := range e.ControlFactors {
+		if level, ok := result.OptimalLevels[factor.Name]; ok {
+			r.Optimal = append(r.Optimal, htmlRow{Cells: []string{factor.Name, nf.FormatLevel(level)}})
+		}
+	}
+	for _, opt := range result.Interpolated {
+		r.Optimal = append(r.Optimal, htmlRow{Cells: []string{opt.Factor + " (interpolated)", nf.FormatLevel(opt.Level)}})
+	}
+
+	var svg strings.Builder
+	if err := WriteSVG(&svg, result.MainEffectPlots(), SVGOptions{Numbers: nf}); err != nil {
+		return err
+	}
+	r.Plot = template.HTML(svg.String())
+
+	width := 0
+	for _, factor := range e.ControlFactors {
+		width = max(width, len(factor.Levels))
+	}
+	for i := 1; i <= width; i++ {
+		r.Levels = append(r.Levels, "Level "+strconv.Itoa(i))
+	}
+	for _, factor := range e.ControlFactors {
+		cells := []string{factor.Name}
+		for i := 0; i < width; i++ {
+			cell := ""
+			if effects := result.MainEffects[factor.Name]; i < len(factor.Levels) && i < len(effects) {
+				cell = nf.Format(effects[i]) + " (" + nf.FormatLevel(factor.Levels[i]) + ")"
+			}
+			cells = append(cells, cell)
+		}
+		r.Effects = append(r.Effects, htmlRow{Cells: cells})
+	}
+
+	sources := make([]string, 0, len(result.ANOVA.FactorSS))
+	for source := range result.ANOVA.FactorSS {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return result.Contributions[sources[i]] > result.Contributions[sources[j]]
+	})
+	a := result.ANOVA
+	for _, source := range sources {
+		r.ANOVA = append(r.ANOVA, htmlRow{
+			Cells: []string{
+				source,
+				nf.Format(a.FactorSS[source]),
+				strconv.Itoa(a.FactorDF[source]),
+				nf.Format(a.FactorMS[source]),
+				nf.Format(a.FactorF[source]),
+				nf.Format(a.FactorP[source]),
+				nf.FormatPercent(result.Contributions[source]),
+			},
+			Significant: a.Significant[source],
+		})
+	}
+	r.ANOVA = append(r.ANOVA, htmlRow{Cells: []string{
+		"Error", nf.Format(a.ErrorSS), strconv.Itoa(a.ErrorDF), nf.Format(a.ErrorMS), "", "", nf.FormatPercent(result.ErrorContribution),
+	}})
+	if a.PureErrorDF > 0 {
+		r.ANOVA = append(r.ANOVA, htmlRow{Cells: []string{
+			"Pure error", nf.Format(a.PureErrorSS), strconv.Itoa(a.PureErrorDF), nf.Format(a.PureErrorMS), "", "", "",
+		}})
+	}
+	for _, warning := range result.Warnings {
+		r.Warnings = append(r.Warnings, warning.String())
+	}
+
+	var text strings.Builder
+	if err := WriteAnalysisReport(&text, result, opts); err != nil {
+		return err
+	}
+	r.Text = text.String()
+	return htmlReportTemplate.Execute(w, r)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Taguchi analysis report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { background: #f3f3f3; }
+tr.significant td { font-weight: bold; }
+pre { background: #f8f8f8; padding: 1em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>Taguchi analysis report</h1>
+<p>Goal: {{.Goal}} &middot; {{.Runs}} array rows &middot; {{.Results}} trial results</p>
+
+<h2>Optimal levels</h2>
+<table>
+<tr><th>Factor</th><th>Level</th></tr>
+{{range .Optimal}}<tr>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Main effects</h2>
+{{.Plot}}
+<table>
+<tr><th>Factor</th>{{range .Levels}}<th>{{.}}</th>{{end}}</tr>
+{{range .Effects}}<tr>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+<p>Mean SNR (dB) per level, with the level value in parentheses. Higher is better.</p>
+
+<h2>ANOVA</h2>
+<table>
+<tr><th>Source</th><th>SS</th><th>DF</th><th>MS</th><th>F</th><th>p</th><th>Contribution</th></tr>
+{{range .ANOVA}}<tr{{if .Significant}} class="significant"{{end}}>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+<p>Sources in bold are significant at alpha = {{.Alpha}}.</p>
+{{if .Warnings}}
+<h2>Warnings</h2>
+<ul>
+{{range .Warnings}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}
+<details>
+<summary>Full text report</summary>
+<pre>{{.Text}}</pre>
+</details>
+</body>
+</html>
+`))
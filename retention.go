@@ -0,0 +1,229 @@
+package taguchi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+)
+
+// RetentionPolicy selects how the raw observations passed to AddResult are kept.
+type RetentionPolicy int
+
+const (
+	// RetainAll keeps every raw observation in memory (the default).
+	RetainAll RetentionPolicy = iota
+	// RetainSummary keeps only an ObservationSummary per trial result. It requires
+	// a goal implementing SummaryGoal; other goals fall back to RetainAll.
+	RetainSummary
+	// RetainSpill writes raw observations to one file per trial result in
+	// Experiment.SpillDir and reads them back on demand during analysis.
+	RetainSpill
+	// RetainSample keeps a uniform random sample of at most
+	// Experiment.SampleSize raw observations per trial result, drawn by
+	// reservoir sampling, alongside the full ObservationSummary. The SNR is
+	// computed from the summary, so it requires a goal implementing
+	// SummaryGoal; other goals fall back to RetainAll.
+	RetainSample
+)
+
+// DefaultSampleSize is the number of raw observations kept per trial result
+// under RetainSample when Experiment.SampleSize is zero.
+const DefaultSampleSize = 1000
+
+// String returns the human-readable name for the retention policy.
+func (p RetentionPolicy) String() string {
+	switch p {
+	case RetainAll:
+		return "all"
+	case RetainSummary:
+		return "summary"
+	case RetainSpill:
+		return "spill"
+	case RetainSample:
+		return "sample"
+	default:
+		return fmt.Sprintf("RetentionPolicy(%d)", int(p))
+	}
+}
+
+// ObservationSummary accumulates the sufficient statistics of a set of
+// observations, from which the built-in goals can compute their SNR.
+// Count: Number of observations.
+// Sum: Sum of observations.
+// SumSquares: Sum of squared observations.
+// SumInverseSquares: Sum of 1/y² (zero observations are treated as 1e-10, matching LargerTheBetter).
+type ObservationSummary struct {
+	Count             int
+	Sum               float64
+	SumSquares        float64
+	SumInverseSquares float64
+}
+
+// Add accumulates a single observation into the summary.
+func (s *ObservationSummary) Add(y float64) {
+	s.Count++
+	s.Sum += y
+	s.SumSquares += y * y
+	if y == 0 {
+		y = 1e-10
+	}
+	s.SumInverseSquares += 1 / (y * y)
+}
+
+// Merge accumulates another summary into s.
+func (s *ObservationSummary) Merge(other ObservationSummary) {
+	s.Count += other.Count
+	s.Sum += other.Sum
+	s.SumSquares += other.SumSquares
+	s.SumInverseSquares += other.SumInverseSquares
+}
+
+// summarize builds an ObservationSummary from raw observations.
+func summarize(obs []float64) ObservationSummary {
+	var s ObservationSummary
+	for _, y := range obs {
+		s.Add(y)
+	}
+	return s
+}
+
+// RawObservations returns the raw observations of the result, reading them back
+// from disk when they were spilled. It returns nil when only a summary was
+// retained and a sample under RetainSample; compare the length with
+// Summary.Count to tell whether the observations are complete.
+func (r TrialResult) RawObservations() ([]float64, error) {
+	if r.SpillFile == "" {
+		return r.Observations, nil
+	}
+	data, err := os.ReadFile(r.SpillFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading spilled observations for trial %d: %w", r.Trial.ID, err)
+	}
+	if len(data)%8 != 0 {
+		return nil, fmt.Errorf("spill file %s is truncated", r.SpillFile)
+	}
+	obs := make([]float64, len(data)/8)
+	for i := range obs {
+		obs[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+	}
+	return obs, nil
+}
+
+// retain applies the experiment's retention policy to a freshly recorded result.
+func (e *Experiment[P]) retain(r *TrialResult) error {
+	switch e.Retention {
+	case RetainSummary:
+		if _, ok := e.Goal.(SummaryGoal); ok {
+			r.Observations = nil
+		}
+	case RetainSpill:
+		path, err := e.spill(r.Trial.ID, r.Observations)
+		if err != nil {
+			return err
+		}
+		r.SpillFile = path
+		r.Observations = nil
+	case RetainSample:
+		if _, ok := e.Goal.(SummaryGoal); ok {
+			size := e.SampleSize
+			if size <= 0 {
+				size = DefaultSampleSize
+			}
+			rng := rand.New(rand.NewSource(int64(len(e.Results))))
+			r.Observations = reservoirSample(r.Observations, size, rng)
+		}
+	}
+	return nil
+}
+
+// reservoirSample returns a uniform random sample of at most size
+// observations in their recorded order, using Algorithm R: the first size
+// observations fill the reservoir and the i-th one after them replaces a
+// random entry with probability size/i. The memory used is bounded by size
+// however many observations are streamed through it.
+func reservoirSample(obs []float64, size int, rng *rand.Rand) []float64 {
+	if len(obs) <= size {
+		return obs
+	}
+	picked := make([]int, size)
+	for i := range picked {
+		picked[i] = i
+	}
+	for i := size; i < len(obs); i++ {
+		if j := rng.Intn(i + 1); j < size {
+			picked[j] = i
+		}
+	}
+	slices.Sort(picked)
+	sample := make([]float64, size)
+	for k, i := range picked {
+		sample[k] = obs[i]
+	}
+	return sample
+}
+
+// spill writes observations to a new file in SpillDir as little-endian
+// float64 values. Without a SpillDir the files go to a directory created for
+// the experiment on first use, so experiments never share spill files.
+func (e *Experiment[P]) spill(trialID int, obs []float64) (string, error) {
+	dir := e.SpillDir
+	if dir == "" {
+		if e.spillDir == "" {
+			tmp, err := os.MkdirTemp("", "taguchi-spill-*")
+			if err != nil {
+				return "", err
+			}
+			e.spillDir = tmp
+		}
+		dir = e.spillDir
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	data := make([]byte, 8*len(obs))
+	for i, y := range obs {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(y))
+	}
+	f, err := os.CreateTemp(dir, fmt.Sprintf("trial-%d-*.obs", trialID))
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// RemoveSpillFiles deletes the files of the spilled results, and the
+// directory created for them when SpillDir is empty. The raw observations of
+// those results cannot be read back afterwards, so call it once the
+// experiment is no longer needed or has been saved with SaveJSON, which
+// stores them inline.
+func (e *Experiment[P]) RemoveSpillFiles() error {
+	var errs []error
+	for _, r := range e.Results {
+		if r.SpillFile == "" {
+			continue
+		}
+		if err := os.Remove(r.SpillFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if e.spillDir != "" {
+		if err := os.RemoveAll(e.spillDir); err != nil {
+			errs = append(errs, err)
+		}
+		e.spillDir = ""
+	}
+	return errors.Join(errs...)
+}
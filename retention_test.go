@@ -0,0 +1,183 @@
+package taguchi
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestAnalyze_RetentionPoliciesAgree verifies that summary-only and spilled
+// retention produce the same SNR as keeping all raw observations in memory.
+func TestAnalyze_RetentionPoliciesAgree(t *testing.T) {
+	goals := []OptimizationGoal{SmallerTheBetter{}, LargerTheBetter{}, NominalTheBest{Target: 5}}
+	policies := []RetentionPolicy{RetainSummary, RetainSpill}
+
+	for _, goal := range goals {
+		reference := mustAnalyze(t, retentionExperiment(t, goal, RetainAll, ""))
+		for _, policy := range policies {
+			exp := retentionExperiment(t, goal, policy, t.TempDir())
+			for _, r := range exp.Results {
+				if r.Observations != nil {
+					t.Errorf("%s/%s: raw observations retained in memory", goal, policy)
+				}
+			}
+			result := mustAnalyze(t, exp)
+			for level, want := range reference.SNR["A"] {
+				if got := result.SNR["A"][level]; !almostEqual(got, want) {
+					t.Errorf("%s/%s: SNR[A][%d] = %.4f, want %.4f", goal, policy, level, got, want)
+				}
+			}
+		}
+	}
+}
+
+// retentionExperiment returns a two-row experiment with one noise factor
+// whose results are kept according to policy, spilling to dir.
+func retentionExperiment(t *testing.T, goal OptimizationGoal, policy RetentionPolicy, dir string) *Experiment[struct{}] {
+	t.Helper()
+	exp := newTestExperiment(t, testDesign{
+		Goal:    goal,
+		Factors: namedFactors([]float64{1, 2}, "A"),
+		Rows:    [][]int{{1}, {2}},
+		Noise:   []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}},
+	})
+	exp.Retention = policy
+	exp.SpillDir = dir
+	recordResults(t, exp, singleResult(func(trial Trial) []float64 {
+		return map[[2]float64][]float64{
+			{1, 0}: {2, 4}, {1, 1}: {6, 8},
+			{2, 0}: {4, 5}, {2, 1}: {6, 5.5},
+		}[[2]float64{trial.Control["A"], trial.Noise["N"]}]
+	}))
+	return exp
+}
+
+// TestRetainSample_BoundsMemory verifies that RetainSample keeps at most
+// SampleSize observations, in recorded order, while the SNR still uses all of them.
+func TestRetainSample_BoundsMemory(t *testing.T) {
+	build := func(policy RetentionPolicy) *Experiment[struct{}] {
+		exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}, [][]int{{1}, {2}}, nil)
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+		}
+		exp.Retention = policy
+		exp.SampleSize = 50
+		for i, trial := range exp.GenerateTrials() {
+			obs := make([]float64, 5000)
+			for j := range obs {
+				obs[j] = float64(i+1) + float64(j)/1000
+			}
+			if err := exp.AddResult(trial, obs); err != nil {
+				t.Fatalf("AddResult: %v", err)
+			}
+		}
+		return exp
+	}
+
+	exp := build(RetainSample)
+	for _, r := range exp.Results {
+		if len(r.Observations) != 50 || r.Summary.Count != 5000 {
+			t.Fatalf("trial %d: kept %d of %d observations, want 50 of 5000", r.Trial.ID, len(r.Observations), r.Summary.Count)
+		}
+		if !slices.IsSorted(r.Observations) {
+			t.Errorf("trial %d: sample is not in recorded order", r.Trial.ID)
+		}
+	}
+	want := mustAnalyze(t, build(RetainAll))
+	got := mustAnalyze(t, exp)
+	for level, snr := range want.SNR["A"] {
+		if !almostEqual(got.SNR["A"][level], snr) {
+			t.Errorf("SNR[A][%d] = %.4f, want %.4f", level, got.SNR["A"][level], snr)
+		}
+	}
+	if _, err := exp.AnalyzeWith(AnalysisOptions{Exclude: []Exclusion{{Trial: 1, Observation: 3}}}); err == nil {
+		t.Error("excluding a single observation from a sample succeeded")
+	}
+}
+
+func TestReservoirSample_Uniform(t *testing.T) {
+	obs := make([]float64, 100)
+	for i := range obs {
+		obs[i] = float64(i)
+	}
+	rng := rand.New(rand.NewSource(1))
+	counts := make([]int, len(obs))
+	const draws = 2000
+	for d := 0; d < draws; d++ {
+		for _, y := range reservoirSample(obs, 10, rng) {
+			counts[int(y)]++
+		}
+	}
+	// Every observation is kept with probability 10/100, i.e. 200 times.
+	for _, quarter := range [][2]int{{0, 25}, {25, 50}, {50, 75}, {75, 100}} {
+		sum := 0
+		for _, c := range counts[quarter[0]:quarter[1]] {
+			sum += c
+		}
+		if sum < 4500 || sum > 5500 {
+			t.Errorf("observations %d-%d kept %d times, want about 5000", quarter[0], quarter[1]-1, sum)
+		}
+	}
+}
+
+// TestRetainSpill_DefaultDir verifies that experiments without a SpillDir
+// spill into directories of their own, so identical trials never share a
+// file, and that RemoveSpillFiles deletes them.
+func TestRetainSpill_DefaultDir(t *testing.T) {
+	first := retentionExperiment(t, SmallerTheBetter{}, RetainSpill, "")
+	second := retentionExperiment(t, SmallerTheBetter{}, RetainSpill, "")
+	if first.spillDir == "" || first.spillDir == second.spillDir {
+		t.Fatalf("spill directories %q and %q, want distinct directories", first.spillDir, second.spillDir)
+	}
+	for i, r := range first.Results {
+		if r.SpillFile == second.Results[i].SpillFile {
+			t.Errorf("result %d: both experiments spilled to %s", i, r.SpillFile)
+		}
+		obs, err := r.RawObservations()
+		if err != nil || len(obs) != 2 {
+			t.Errorf("result %d: RawObservations = %v, %v", i, obs, err)
+		}
+	}
+
+	dir := first.spillDir
+	if err := first.RemoveSpillFiles(); err != nil {
+		t.Fatalf("RemoveSpillFiles: %v", err)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("spill directory %s still exists: %v", dir, err)
+	}
+	if _, err := second.Results[0].RawObservations(); err != nil {
+		t.Errorf("other experiment lost its spill files: %v", err)
+	}
+	if err := second.RemoveSpillFiles(); err != nil {
+		t.Fatalf("RemoveSpillFiles: %v", err)
+	}
+}
+
+// TestRetainSpill_Error verifies that a result whose observations cannot be
+// spilled is rejected instead of silently kept in memory.
+func TestRetainSpill_Error(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	exp.Retention = RetainSpill
+	exp.SpillDir = file
+	err = exp.AddResult(exp.GenerateTrials()[0], []float64{1, 2})
+	var trialErr *TrialError
+	if !errors.As(err, &trialErr) || !strings.Contains(err.Error(), "spilling observations") {
+		t.Errorf("AddResult with an unusable SpillDir = %v, want a spilling error", err)
+	}
+	if len(exp.Results) != 0 {
+		t.Errorf("%d results recorded after a failed spill", len(exp.Results))
+	}
+}
@@ -0,0 +1,198 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RobustSNRPolicy selects how outlier observations within a trial are treated
+// before the SNR is computed. Benchmarks in particular pick up the odd
+// observation slowed down by a GC cycle or an OS hiccup, and a single such
+// value can dominate a trial's mean squared deviation.
+type RobustSNRPolicy int
+
+const (
+	// NoRobustSNR uses every observation as recorded (the default).
+	NoRobustSNR RobustSNRPolicy = iota
+	// ExcludeOutliers drops the outlier observations of a trial.
+	ExcludeOutliers
+	// WinsorizeOutliers clamps the outlier observations of a trial to the
+	// outlier threshold, keeping the observation count.
+	WinsorizeOutliers
+)
+
+// String returns the policy name, e.g. "exclude".
+func (p RobustSNRPolicy) String() string {
+	switch p {
+	case NoRobustSNR:
+		return "none"
+	case ExcludeOutliers:
+		return "exclude"
+	case WinsorizeOutliers:
+		return "winsorize"
+	default:
+		return fmt.Sprintf("RobustSNRPolicy(%d)", int(p))
+	}
+}
+
+// DefaultOutlierThreshold is the modified z-score above which an observation
+// is an outlier when Experiment.OutlierThreshold is not set.
+const DefaultOutlierThreshold = 3.5
+
+// OutlierObservation is an observation treated as an outlier by the
+// experiment's RobustSNR policy.
+// Trial: ID of the trial the observation belongs to.
+// Row: Zero-based orthogonal array row of the trial.
+// Observation: One-based index of the observation, counted across the trial's results in recording
+// order as in Exclusion.
+// Value: The recorded observation.
+// Score: Its modified z-score, 0.6745·(Value − median)/MAD.
+// Replacement: The value used instead under WinsorizeOutliers; NaN when the observation was excluded.
+type OutlierObservation struct {
+	Trial       int
+	Row         int
+	Observation int
+	Value       float64
+	Score       float64
+	Replacement float64
+}
+
+// String describes the outlier, e.g. "trial 4, observation 7: 93.1 (z = 12.4), excluded".
+func (o OutlierObservation) String() string {
+	s := fmt.Sprintf("trial %d, observation %d: %g (z = %.3g)", o.Trial, o.Observation, o.Value, o.Score)
+	if math.IsNaN(o.Replacement) {
+		return s + ", excluded"
+	}
+	return s + fmt.Sprintf(", winsorized to %g", o.Replacement)
+}
+
+// outlierThreshold returns the configured threshold or DefaultOutlierThreshold.
+func (e *Experiment[P]) outlierThreshold() float64 {
+	if e.OutlierThreshold > 0 {
+		return e.OutlierThreshold
+	}
+	return DefaultOutlierThreshold
+}
+
+// robustResults applies the RobustSNR policy to results, grouping them by
+// trial so that repetitions recorded as separate results are judged together.
+// Trials whose raw observations were not all retained are passed through
+// unchanged. The results of cleaned trials are replaced one for one by fresh
+// results carrying only Trial, Observations and Summary; the others are
+// shared with results.
+func (e *Experiment[P]) robustResults(results []TrialResult) ([]TrialResult, []OutlierObservation) {
+	if e.RobustSNR == NoRobustSNR {
+		return results, nil
+	}
+	var order []int
+	byTrial := map[int][]TrialResult{}
+	for _, r := range results {
+		if _, ok := byTrial[r.Trial.ID]; !ok {
+			order = append(order, r.Trial.ID)
+		}
+		byTrial[r.Trial.ID] = append(byTrial[r.Trial.ID], r)
+	}
+
+	var cleaned []TrialResult
+	var outliers []OutlierObservation
+	for _, id := range order {
+		group := byTrial[id]
+		var obs []float64
+		raw := true
+		for _, r := range group {
+			o, err := r.RawObservations()
+			if err != nil || len(o) < r.Summary.Count {
+				raw = false
+				break
+			}
+			obs = append(obs, o...)
+		}
+		found := e.findOutliers(obs)
+		if !raw || len(found) == 0 {
+			cleaned = append(cleaned, group...)
+			continue
+		}
+
+		trial := group[0].Trial
+		next, i := 0, 0
+		for _, r := range group {
+			var kept []float64
+			for end := i + r.Summary.Count; i < end; i++ {
+				y := obs[i]
+				if next < len(found) && found[next].Observation == i+1 {
+					o := found[next]
+					o.Trial, o.Row = trial.ID, trial.Row
+					outliers = append(outliers, o)
+					next++
+					if math.IsNaN(o.Replacement) {
+						continue
+					}
+					y = o.Replacement
+				}
+				kept = append(kept, y)
+			}
+			cleaned = append(cleaned, TrialResult{Trial: r.Trial, Observations: kept, Summary: summarize(kept)})
+		}
+	}
+	return cleaned, outliers
+}
+
+// findOutliers returns the observations whose modified z-score exceeds the
+// outlier threshold, in observation order, with Trial and Row left unset.
+// The scale is the median absolute deviation (MAD), or 1.2533 times the mean
+// absolute deviation from the median when more than half the observations
+// are identical; with no spread at all nothing is an outlier. At least three
+// observations are needed.
+func (e *Experiment[P]) findOutliers(obs []float64) []OutlierObservation {
+	if len(obs) < 3 {
+		return nil
+	}
+	med := median(obs)
+	dev := make([]float64, len(obs))
+	for i, y := range obs {
+		dev[i] = math.Abs(y - med)
+	}
+	// The 0.6745 factor makes the MAD a consistent estimate of σ for normal data.
+	scale := median(dev) / 0.6745
+	if scale == 0 {
+		scale = 1.2533 * meanOf(dev)
+	}
+	if scale == 0 || math.IsNaN(scale) {
+		return nil
+	}
+
+	threshold := e.outlierThreshold()
+	var found []OutlierObservation
+	for i, y := range obs {
+		score := (y - med) / scale
+		if math.Abs(score) <= threshold {
+			continue
+		}
+		replacement := math.NaN()
+		if e.RobustSNR == WinsorizeOutliers {
+			replacement = med + math.Copysign(threshold*scale, score)
+		}
+		found = append(found, OutlierObservation{Observation: i + 1, Value: y, Score: score, Replacement: replacement})
+	}
+	return found
+}
+
+// outlierObservations returns every observation treated as an outlier by the
+// RobustSNR policy, ordered by trial ID.
+func (e *Experiment[P]) outlierObservations() []OutlierObservation {
+	_, outliers := e.robustResults(e.Results)
+	sort.SliceStable(outliers, func(i, j int) bool { return outliers[i].Trial < outliers[j].Trial })
+	return outliers
+}
+
+// median returns the median of values without modifying them.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
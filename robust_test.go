@@ -0,0 +1,98 @@
+package taguchi
+
+import (
+	"math"
+	"testing"
+)
+
+// robustDesign is an L4 experiment with two factors whose first trial has a
+// single spike among otherwise steady observations. The first trial's
+// observations are recorded as two results.
+var robustDesign = testDesign{
+	Response: func(trial Trial) [][]float64 {
+		y := 10 * trial.Control["A"] * trial.Control["B"]
+		if trial.Row == 0 {
+			return [][]float64{{y, 1.01 * y, 0.99 * y}, {1.02 * y, 10 * y, 0.98 * y}}
+		}
+		return [][]float64{{y, 1.01 * y, 0.99 * y, 1.02 * y, 0.98 * y}}
+	},
+}
+
+func TestRobustSNR_Exclude(t *testing.T) {
+	exp := newTestExperiment(t, robustDesign)
+	exp.RobustSNR = ExcludeOutliers
+	result := mustAnalyze(t, exp)
+	if len(result.Outliers) != 1 {
+		t.Fatalf("outliers = %v, want the spike only", result.Outliers)
+	}
+	o := result.Outliers[0]
+	if o.Trial != exp.Results[0].Trial.ID || o.Row != 0 || o.Observation != 5 || o.Value != 100 || !math.IsNaN(o.Replacement) {
+		t.Errorf("outlier = %+v, want observation 5 of row 0 excluded", o)
+	}
+	if !result.HasWarning(WarnOutliers) {
+		t.Error("missing outliers warning")
+	}
+	want := SmallerTheBetter{}.CalculateSNR([]float64{10, 10.1, 9.9, 10.2, 9.8})
+	if got := exp.computeOASNR().values[0]; !almostEqual(got, want) {
+		t.Errorf("row 0 SNR = %g, want %g without the spike", got, want)
+	}
+}
+
+func TestRobustSNR_Winsorize(t *testing.T) {
+	exp := newTestExperiment(t, robustDesign)
+	exp.RobustSNR = WinsorizeOutliers
+	exp.OutlierThreshold = 5
+	result := mustAnalyze(t, exp)
+	if len(result.Outliers) != 1 {
+		t.Fatalf("outliers = %v, want the spike only", result.Outliers)
+	}
+	o := result.Outliers[0]
+	// The median is 10.05 and the MAD 0.15, so the clamp is 10.05 + 5·0.15/0.6745.
+	if want := 10.05 + 5*0.15/0.6745; !almostEqual(o.Replacement, want) {
+		t.Errorf("replacement = %g, want %g", o.Replacement, want)
+	}
+	want := SmallerTheBetter{}.CalculateSNR([]float64{10, 10.1, 9.9, 10.2, o.Replacement, 9.8})
+	if got := exp.computeOASNR().values[0]; !almostEqual(got, want) {
+		t.Errorf("row 0 SNR = %g, want %g with the spike winsorized", got, want)
+	}
+}
+
+func TestRobustSNR_Off(t *testing.T) {
+	exp := newTestExperiment(t, robustDesign)
+	result := mustAnalyze(t, exp)
+	if result.Outliers != nil || result.HasWarning(WarnOutliers) {
+		t.Errorf("outliers = %v without a policy, want none", result.Outliers)
+	}
+	want := SmallerTheBetter{}.CalculateSNR([]float64{10, 10.1, 9.9, 10.2, 100, 9.8})
+	if got := exp.computeOASNR().values[0]; !almostEqual(got, want) {
+		t.Errorf("row 0 SNR = %g, want %g", got, want)
+	}
+}
+
+func TestFindOutliers(t *testing.T) {
+	exp := &Experiment[struct{}]{RobustSNR: ExcludeOutliers}
+	tests := []struct {
+		name string
+		obs  []float64
+		want []int
+	}{
+		{"too few", []float64{1, 100}, nil},
+		{"no spread", []float64{3, 3, 3, 3}, nil},
+		{"steady", []float64{1, 1.1, 0.9, 1.05, 0.95}, nil},
+		{"low and high", []float64{-50, 1, 1.1, 0.9, 1.05, 0.95, 80}, []int{1, 7}},
+		{"identical majority", []float64{1, 1, 1, 1, 5}, []int{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := exp.findOutliers(tt.obs)
+			if len(found) != len(tt.want) {
+				t.Fatalf("found %v, want observations %v", found, tt.want)
+			}
+			for i, o := range found {
+				if o.Observation != tt.want[i] {
+					t.Errorf("outlier %d is observation %d, want %d", i, o.Observation, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// MeasureFunc runs a single trial and returns its observation. The context
+// carries the trial (see TrialFromContext).
+type MeasureFunc func(ctx context.Context, trial Trial) (float64, error)
+
+// RowHook is invoked once per orthogonal array row with the row's control
+// configuration, e.g. to rebuild a binary or reflash firmware.
+type RowHook func(ctx context.Context, row int, control map[string]float64) error
+
+// TrialHook is invoked around every individual trial, e.g. to apply a noise condition.
+type TrialHook func(ctx context.Context, trial Trial) error
+
+// LevelFunc puts a single factor level into effect (see ControlFactor.Apply).
+type LevelFunc func(ctx context.Context, level float64) error
+
+// RunOptions configures Run.
+// Repetitions: Measured runs per trial, recorded as the trial's observations (1 when zero).
+// Warmup: Discarded runs per trial before the measured ones, e.g. to warm caches or the JIT.
+// SkipCompleted: Skip trials that already have a result, matched by trial Key as in PendingTrials, e.g. when resuming from a checkpoint.
+// CaptureMemStats: Record runtime.MemStats deltas of every measured run as secondary responses (see ResponseAllocBytes).
+// Collectors: Measurements taken around every measured run and recorded as secondary responses under their names.
+// Metadata: Annotations recorded with every result, e.g. a git SHA; MetaHostname and MetaTimestamp are added unless set here.
+// RepetitionBudget: Total measured runs allowed; runs left after the first pass go to the noisiest rows (see Run). Zero disables rebalancing.
+// Cooldown: Pause after each trial, e.g. to let clocks and temperatures settle.
+// Throttle: Limits the trial rate, concurrency and times of day on shared infrastructure; nil means no limits.
+// Progress: Tracks trial durations and the estimated time to completion; nil disables tracking.
+// Metrics: Exposes the run's progress, trial durations and best SNR as Prometheus metrics; nil disables them.
+// Checkpoint: File the experiment is saved to (see SaveCheckpoint) after every array row and when the run stops.
+// FinishInFlight: On cancellation, complete and record the trial being measured instead of aborting it.
+// SetupRow, TeardownRow: Called before the first and after the last trial of each array row.
+// SetupTrial, TeardownTrial: Called before and after each trial, around its warmup and measured runs.
+// All hooks are optional. Teardown hooks run whenever the matching setup succeeded,
+// with a context that is not cancelled so they can clean up after an interruption.
+type RunOptions struct {
+	Repetitions      int
+	Warmup           int
+	SkipCompleted    bool
+	CaptureMemStats  bool
+	Collectors       []Collector
+	Metadata         map[string]string
+	RepetitionBudget int
+	Cooldown         time.Duration
+	Throttle         *Throttle
+	Progress         *Progress
+	Metrics          *Metrics
+	Checkpoint       string
+	FinishInFlight   bool
+	SetupRow         RowHook
+	TeardownRow      RowHook
+	SetupTrial       TrialHook
+	TeardownTrial    TrialHook
+	block            int // replicate block being run, set by Run
+}
+
+// Run executes every generated trial with measure and records the
+// observations with AddResult, replacing the usual hand-written loop. Each
+// trial is measured opts.Warmup times without recording and then
+// opts.Repetitions times. Trials are grouped by array row so the
+// expensive control configuration changes once per row while the cheap noise
+// conditions vary within it. At the start of each row, the Apply function of
+// every control factor whose level changed is called before opts.SetupRow.
+//
+// With opts.RepetitionBudget, the runs left after this first pass are spent
+// adaptively: the row whose mean is least certain (highest relative
+// repetition variance per run) gets one more repetition of each of its
+// trials, until the budget is used up. Every noise condition of a row is
+// repeated equally, so row SNRs stay balanced over the noise conditions and
+// Analyze needs no adjustment for the unequal replication between rows.
+//
+// With opts.Throttle, each trial waits until the throttle lets it start.
+//
+// Trials are run in the experiment's RunOrder. With RunOrder.Blocks, the
+// whole design is run once per replicate block, each block in its own order,
+// and the results of every block are recorded as replicates.
+//
+// Run stops at the first error or when ctx is cancelled; results recorded up
+// to that point are kept and flushed to opts.Checkpoint. A cancelled run
+// returns an *InterruptedError; a trial cut short is discarded, or completed
+// first with opts.FinishInFlight. Resume with opts.SkipCompleted.
+func (e *Experiment[P]) Run(ctx context.Context, measure MeasureFunc, opts RunOptions) error {
+	if opts.RepetitionBudget > 0 && opts.Repetitions < 2 {
+		return errors.New("repetition rebalancing needs at least 2 repetitions to estimate variance")
+	}
+	blocks := e.RunOrder.blocks()
+	if opts.RepetitionBudget > 0 && blocks > 1 {
+		return errors.New("repetition rebalancing cannot be combined with replicate blocks")
+	}
+	if err := checkCollectors(opts); err != nil {
+		return err
+	}
+	passes := make([][]Trial, blocks)
+	var all []Trial
+	for b := range passes {
+		passes[b] = e.orderTrials(e.designTrials(), b)
+		if opts.SkipCompleted {
+			passes[b] = e.pendingTrials(passes[b], b)
+		}
+		all = append(all, passes[b]...)
+	}
+	opts.Progress.begin(all, opts, 1)
+	defer opts.Progress.end()
+	opts.Metrics.begin(all, opts)
+	defer opts.Metrics.end()
+	return e.finishRun(ctx, opts, e.runPasses(ctx, passes, measure, opts))
+}
+
+// runPasses runs the trials of every replicate block, one array row at a time
+// and checkpointing after each row, and then spends the repetition budget.
+func (e *Experiment[P]) runPasses(ctx context.Context, passes [][]Trial, measure MeasureFunc, opts RunOptions) error {
+	applied := map[string]float64{}
+	for b, trials := range passes {
+		opts.block = b
+		for start := 0; start < len(trials); {
+			end := start
+			for end < len(trials) && trials[end].Row == trials[start].Row {
+				end++
+			}
+			row := trials[start:end]
+			start = end
+			if err := e.applyLevels(ctx, row[0].Control, applied); err != nil {
+				return err
+			}
+			if err := e.runRow(ctx, row, measure, opts); err != nil {
+				return err
+			}
+			if opts.Checkpoint != "" {
+				if err := e.SaveCheckpoint(opts.Checkpoint); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if opts.RepetitionBudget > 0 {
+		return e.rebalance(ctx, groupByRow(passes[0]), measure, opts, applied)
+	}
+	return nil
+}
+
+// applyLevels calls the Apply function of every control factor whose level
+// differs from the one last applied, recording the new levels in applied.
+func (e *Experiment[P]) applyLevels(ctx context.Context, control map[string]float64, applied map[string]float64) error {
+	for _, factor := range e.ControlFactors {
+		if factor.Apply == nil {
+			continue
+		}
+		level := control[factor.Name]
+		if prev, ok := applied[factor.Name]; ok && prev == level {
+			continue
+		}
+		if err := factor.Apply(ctx, level); err != nil {
+			return fmt.Errorf("apply %s=%g: %w", factor.Name, level, err)
+		}
+		applied[factor.Name] = level
+	}
+	return nil
+}
+
+// SetApply attaches a LevelFunc to the named control factor, e.g. for
+// experiments built from a factors struct.
+func (e *Experiment[P]) SetApply(name string, apply LevelFunc) error {
+	j := e.factorIndex(name)
+	if j < 0 {
+		return fmt.Errorf("unknown control factor %s", name)
+	}
+	e.ControlFactors[j].Apply = apply
+	return nil
+}
+
+// runRow runs the trials of a single array row between its row hooks.
+func (e *Experiment[P]) runRow(ctx context.Context, trials []Trial, measure MeasureFunc, opts RunOptions) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	row, control := trials[0].Row, trials[0].Control
+	if opts.SetupRow != nil {
+		if err := opts.SetupRow(ctx, row, control); err != nil {
+			return fmt.Errorf("setup row %d: %w", row+1, err)
+		}
+	}
+	if opts.TeardownRow != nil {
+		defer func() {
+			if terr := opts.TeardownRow(context.WithoutCancel(ctx), row, control); terr != nil {
+				err = errors.Join(err, fmt.Errorf("teardown row %d: %w", row+1, terr))
+			}
+		}()
+	}
+
+	for _, trial := range trials {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		release, err := opts.Throttle.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		err = e.runTrial(e.TrialContext(trialContext(ctx, opts), trial, trialMetadata(trial, opts)), trial, measure, opts)
+		release()
+		if err != nil {
+			return err
+		}
+		if err := sleepContext(ctx, opts.Cooldown); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runTrial measures a single trial and records its observations.
+// The trial's duration is reported to opts.Progress and opts.Metrics.
+func (e *Experiment[P]) runTrial(ctx context.Context, trial Trial, measure MeasureFunc, opts RunOptions) error {
+	start := time.Now()
+	m, err := e.measureTrial(ctx, trial, measure, opts)
+	if err != nil {
+		if ctx.Err() == nil {
+			opts.Metrics.trialFailed()
+		}
+		return err
+	}
+	if err := e.addMeasurement(trial, m); err != nil {
+		return err
+	}
+	d := time.Since(start)
+	opts.Progress.trialDone(trial.Row, opts.Warmup+max(opts.Repetitions, 1), d)
+	opts.Metrics.trialDone(d)
+	opts.Metrics.rowMeasured(trial.Row, e.measuredRowSNR(trial.Row))
+	return nil
+}
+
+// measuredRowSNR returns the SNR of array row i over the results recorded so
+// far, which may cover only some of its noise conditions.
+func (e *Experiment[P]) measuredRowSNR(i int) float64 {
+	var matched []TrialResult
+	for _, r := range e.Results {
+		if r.Trial.Row == i {
+			matched = append(matched, r)
+		}
+	}
+	return e.resultsSNR(matched)
+}
+
+// trialMeasurement is everything recorded for one trial by the runners.
+type trialMeasurement struct {
+	observations  []float64
+	secondary     map[string][]float64
+	noiseReadings map[string]float64
+	block         int
+	metadata      map[string]string
+}
+
+// addMeasurement records a trial measured by a runner. Measuring a trial
+// again adds replicates, as repetition rebalancing does.
+func (e *Experiment[P]) addMeasurement(trial Trial, m trialMeasurement) error {
+	return e.addResult(TrialResult{
+		Trial:         trial,
+		Observations:  m.observations,
+		Secondary:     m.secondary,
+		NoiseReadings: m.noiseReadings,
+		Block:         m.block,
+		Metadata:      m.metadata,
+	}, true)
+}
+
+// measureTrial runs the warmup and measured repetitions of a trial between its
+// trial hooks, followed by the noise probes, and returns what was measured.
+func (e *Experiment[P]) measureTrial(ctx context.Context, trial Trial, measure MeasureFunc, opts RunOptions) (m trialMeasurement, err error) {
+	if opts.SetupTrial != nil {
+		if err := opts.SetupTrial(ctx, trial); err != nil {
+			return m, fmt.Errorf("setup trial %d: %w", trial.ID, err)
+		}
+	}
+	if opts.TeardownTrial != nil {
+		defer func() {
+			if terr := opts.TeardownTrial(context.WithoutCancel(ctx), trial); terr != nil {
+				err = errors.Join(err, fmt.Errorf("teardown trial %d: %w", trial.ID, terr))
+			}
+		}()
+	}
+
+	for i := 0; i < opts.Warmup; i++ {
+		if _, err := measure(ctx, trial); err != nil {
+			return m, fmt.Errorf("trial %d warmup %d: %w", trial.ID, i+1, err)
+		}
+	}
+	reps := max(opts.Repetitions, 1)
+	m.observations = make([]float64, 0, reps)
+	m.block = opts.block
+	for i := 0; i < reps; i++ {
+		if err := ctx.Err(); err != nil {
+			return m, err
+		}
+		var before runtime.MemStats
+		if opts.CaptureMemStats {
+			runtime.ReadMemStats(&before)
+		}
+		for _, c := range opts.Collectors {
+			if err := c.Start(ctx); err != nil {
+				return m, fmt.Errorf("trial %d repetition %d: start %s: %w", trial.ID, i+1, c.Name(), err)
+			}
+		}
+		v, err := measure(ctx, trial)
+		// Collectors stop in reverse order, so the first is the outermost.
+		for j := len(opts.Collectors) - 1; j >= 0; j-- {
+			c := opts.Collectors[j]
+			if serr := c.Stop(ctx); serr != nil && err == nil {
+				err = fmt.Errorf("stop %s: %w", c.Name(), serr)
+			}
+		}
+		if err != nil {
+			return m, fmt.Errorf("trial %d repetition %d: %w", trial.ID, i+1, err)
+		}
+		m.observations = append(m.observations, v)
+		for _, c := range opts.Collectors {
+			if m.secondary == nil {
+				m.secondary = map[string][]float64{}
+			}
+			m.secondary[c.Name()] = append(m.secondary[c.Name()], c.Value())
+		}
+		if opts.CaptureMemStats {
+			var after runtime.MemStats
+			runtime.ReadMemStats(&after)
+			if m.secondary == nil {
+				m.secondary = map[string][]float64{}
+			}
+			for name, d := range memStatsDelta(&before, &after) {
+				m.secondary[name] = append(m.secondary[name], d)
+			}
+		}
+	}
+	m.noiseReadings, err = e.probeNoise(ctx, trial)
+	m.metadata = measurementMetadata(opts)
+	return m, err
+}
+
+// checkCollectors rejects collectors whose responses would be mixed up: two
+// with the same name, or one named like a memory statistics response while
+// those are captured.
+func checkCollectors(opts RunOptions) error {
+	names := map[string]bool{}
+	if opts.CaptureMemStats {
+		for name := range memStatsDelta(&runtime.MemStats{}, &runtime.MemStats{}) {
+			names[name] = true
+		}
+	}
+	for _, c := range opts.Collectors {
+		if names[c.Name()] {
+			return fmt.Errorf("collector %s: response name already recorded", c.Name())
+		}
+		names[c.Name()] = true
+	}
+	return nil
+}
+
+// PendingTrials returns the trials of GenerateTrials without a recorded
+// result, in run order. Results are matched by trial Key, as by ResultsByKey,
+// so results recorded by another process count whatever their trial IDs.
+// With replicate blocks (see RunOrder), only the first block is considered.
+func (e *Experiment[P]) PendingTrials() []Trial {
+	return e.pendingTrials(e.GenerateTrials(), 0)
+}
+
+// pendingTrials returns the trials without a recorded result in the given
+// replicate block, matched by Key.
+func (e *Experiment[P]) pendingTrials(trials []Trial, block int) []Trial {
+	done := map[string]bool{}
+	for _, r := range e.Results {
+		if r.Block == block {
+			done[r.Trial.key()] = true
+		}
+	}
+	var pending []Trial
+	for _, t := range trials {
+		if !done[t.key()] {
+			pending = append(pending, t)
+		}
+	}
+	return pending
+}
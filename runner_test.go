@@ -0,0 +1,252 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestRun_RowHooks verifies that row hooks run once per array row around the
+// trials of that row while trial hooks wrap every trial.
+func TestRun_RowHooks(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+	}
+	noise := []NoiseFactor{
+		{Name: "N", Levels: []float64{0, 1, 2}},
+	}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	var log []string
+	opts := RunOptions{
+		SetupRow: func(_ context.Context, row int, control map[string]float64) error {
+			log = append(log, fmt.Sprintf("setup%d", row))
+			return nil
+		},
+		TeardownRow: func(_ context.Context, row int, _ map[string]float64) error {
+			log = append(log, fmt.Sprintf("teardown%d", row))
+			return nil
+		},
+		SetupTrial: func(context.Context, Trial) error {
+			log = append(log, "t")
+			return nil
+		},
+	}
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		if info, ok := TrialFromContext(ctx); !ok || info.Trial.ID != trial.ID {
+			t.Errorf("trial %d: context does not carry the trial", trial.ID)
+		}
+		return trial.Control["A"], nil
+	}
+
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := "setup0 t t t teardown0 setup1 t t t teardown1"
+	if got := strings.Join(log, " "); got != want {
+		t.Errorf("hook order:\n got %s\nwant %s", got, want)
+	}
+	if len(exp.Results) != 6 {
+		t.Errorf("len(Results) = %d, want 6", len(exp.Results))
+	}
+}
+
+// TestRun_TeardownOnError verifies that the row teardown still runs when a
+// measurement fails and that the measurement error is returned.
+func TestRun_TeardownOnError(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	boom := errors.New("boom")
+	tornDown := false
+	opts := RunOptions{
+		TeardownRow: func(context.Context, int, map[string]float64) error {
+			tornDown = true
+			return nil
+		},
+	}
+	err = exp.Run(context.Background(), func(context.Context, Trial) (float64, error) {
+		return 0, boom
+	}, opts)
+	if !errors.Is(err, boom) {
+		t.Errorf("Run error = %v, want %v", err, boom)
+	}
+	if !tornDown {
+		t.Error("row teardown did not run after a failed trial")
+	}
+}
+
+// TestRun_RepetitionsAndWarmup verifies that warmup runs are discarded and
+// every repetition becomes an observation of the trial.
+func TestRun_RepetitionsAndWarmup(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	calls := map[int]int{}
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		calls[trial.ID]++
+		return float64(calls[trial.ID]), nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{Repetitions: 3, Warmup: 2}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, r := range exp.Results {
+		if calls[r.Trial.ID] != 5 {
+			t.Errorf("trial %d: %d calls, want 5", r.Trial.ID, calls[r.Trial.ID])
+		}
+		if len(r.Observations) != 3 || r.Observations[0] != 3 {
+			t.Errorf("trial %d: observations %v, want [3 4 5]", r.Trial.ID, r.Observations)
+		}
+	}
+}
+
+// TestRun_NoiseProbe verifies that probe readings are stored with each trial
+// and that trials missing their intended noise level are flagged.
+func TestRun_NoiseProbe(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+	}
+	noise := []NoiseFactor{{
+		Name:      "CPU",
+		Levels:    []float64{0, 0.8},
+		Tolerance: 0.1,
+		Probe: func(_ context.Context, trial Trial) (float64, error) {
+			// The load generator saturates at 50% on this machine.
+			return min(trial.Noise["CPU"], 0.5), nil
+		},
+	}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		return trial.Control["A"] + trial.Noise["CPU"], nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, r := range exp.Results {
+		if _, ok := r.NoiseReadings["CPU"]; !ok {
+			t.Errorf("trial %d: no CPU reading stored", r.Trial.ID)
+		}
+	}
+	deviations := exp.UnrealizedNoise()
+	if len(deviations) != 2 {
+		t.Fatalf("UnrealizedNoise = %+v, want the two 0.8 trials", deviations)
+	}
+	if d := deviations[0]; d.Intended != 0.8 || d.Realized != 0.5 {
+		t.Errorf("deviation = %+v, want intended 0.8, realized 0.5", d)
+	}
+	if !mustAnalyze(t, exp).HasWarning(WarnNoiseNotRealized) {
+		t.Errorf("expected %s warning", WarnNoiseNotRealized)
+	}
+}
+
+// TestRun_FactorApply verifies that factor Apply functions run only when the
+// factor's level changes, before the row hooks.
+func TestRun_FactorApply(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{10, 20}},
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactors: %v", err)
+	}
+	var log []string
+	for _, name := range []string{"A", "B"} {
+		name := name
+		if err := exp.SetApply(name, func(_ context.Context, level float64) error {
+			log = append(log, fmt.Sprintf("%s=%g", name, level))
+			return nil
+		}); err != nil {
+			t.Fatalf("SetApply: %v", err)
+		}
+	}
+	if err := exp.SetApply("C", nil); err == nil {
+		t.Error("SetApply accepted an unknown factor")
+	}
+
+	opts := RunOptions{
+		SetupRow: func(_ context.Context, row int, _ map[string]float64) error {
+			log = append(log, fmt.Sprintf("row%d", row))
+			return nil
+		},
+	}
+	measure := func(context.Context, Trial) (float64, error) { return 1, nil }
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := "A=1 B=10 row0 B=20 row1 A=2 B=10 row2 B=20 row3"
+	if got := strings.Join(log, " "); got != want {
+		t.Errorf("call order = %q, want %q", got, want)
+	}
+
+	if err := NewParallelRunner(exp, 2).Run(context.Background(), measure); err == nil {
+		t.Error("parallel runner accepted factors with Apply functions")
+	}
+}
+
+// TestRun_TrialContext verifies that the runners pass measurement code a
+// context carrying the trial, its array row and the run metadata, which
+// nested code can read with TrialFromContext.
+func TestRun_TrialContext(t *testing.T) {
+	// tag stands for measurement code deep below the MeasureFunc.
+	tag := func(ctx context.Context) (TrialInfo, error) {
+		info, ok := TrialFromContext(ctx)
+		if !ok {
+			return TrialInfo{}, errors.New("no trial in context")
+		}
+		return info, nil
+	}
+	check := func(ctx context.Context, runner string, trial Trial) (float64, error) {
+		info, err := tag(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if info.Trial.ID != trial.ID || info.Trial.Key != trial.Key || info.Row != trial.Row {
+			t.Errorf("%s: trial %d (row %d) sees trial %d (row %d)", runner, trial.ID, trial.Row, info.Trial.ID, info.Row)
+		}
+		if info.Metadata["commit"] != "abc123" {
+			t.Errorf("%s: trial %d: metadata %v, want commit abc123", runner, trial.ID, info.Metadata)
+		}
+		return 1 + trial.Control["A"], nil
+	}
+	opts := RunOptions{Metadata: map[string]string{"commit": "abc123"}}
+
+	exp := parallelExperiment(t)
+	if err := exp.Run(context.Background(), func(ctx context.Context, trial Trial) (float64, error) {
+		return check(ctx, "Run", trial)
+	}, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	exp = parallelExperiment(t)
+	r := NewParallelRunner(exp, 3)
+	r.Options = opts
+	if err := r.Run(context.Background(), func(ctx context.Context, trial Trial) (float64, error) {
+		return check(ctx, "ParallelRunner", trial)
+	}); err != nil {
+		t.Fatalf("ParallelRunner.Run: %v", err)
+	}
+
+	if _, ok := TrialFromContext(context.Background()); ok {
+		t.Error("TrialFromContext on a plain context reported a trial")
+	}
+}
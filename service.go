@@ -0,0 +1,105 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LoadFunc drives load against the service under test for the given duration
+// and returns the chosen metric (e.g., p99 latency or throughput).
+type LoadFunc func(ctx context.Context, trial Trial, duration time.Duration) (float64, error)
+
+// ServiceHarness packages the service-tuning loop: apply a configuration to an
+// external service, wait until it is ready, drive load and record a metric.
+// Apply: Applies a row's control configuration to the service (restart, admin API, config file).
+// Ready: Health/readiness check, polled until it succeeds after each Apply.
+// ReadyTimeout: Maximum wait for Ready (30s when zero).
+// ReadyInterval: Delay between Ready polls (500ms when zero).
+// Load: Load generator producing one observation per call.
+// Duration: How long each Load call runs.
+type ServiceHarness struct {
+	Apply         RowHook
+	Ready         func(ctx context.Context) error
+	ReadyTimeout  time.Duration
+	ReadyInterval time.Duration
+	Load          LoadFunc
+	Duration      time.Duration
+}
+
+// RunService runs the experiment against an external service. The
+// configuration is applied once per array row, after any opts.SetupRow hook,
+// and the readiness check must pass before the row's trials are measured
+// with h.Load. Repetitions, warmup and the other hooks work as in Run.
+func (e *Experiment[P]) RunService(ctx context.Context, h ServiceHarness, opts RunOptions) error {
+	if h.Load == nil {
+		return errors.New("service harness needs a Load function")
+	}
+	setup := opts.SetupRow
+	opts.SetupRow = func(ctx context.Context, row int, control map[string]float64) error {
+		if setup != nil {
+			if err := setup(ctx, row, control); err != nil {
+				return err
+			}
+		}
+		if h.Apply != nil {
+			if err := h.Apply(ctx, row, control); err != nil {
+				return fmt.Errorf("apply: %w", err)
+			}
+		}
+		return h.waitReady(ctx)
+	}
+	measure := func(ctx context.Context, trial Trial) (float64, error) {
+		return h.Load(ctx, trial, h.Duration)
+	}
+	return e.Run(ctx, measure, opts)
+}
+
+// waitReady polls the readiness check until it succeeds or the timeout expires.
+func (h ServiceHarness) waitReady(ctx context.Context) error {
+	if h.Ready == nil {
+		return nil
+	}
+	timeout, interval := h.ReadyTimeout, h.ReadyInterval
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := h.Ready(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("service not ready after %v: %w", timeout, err)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// HTTPReady returns a readiness check that succeeds when a GET of url
+// answers with a 2xx status.
+func HTTPReady(url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("readiness check %s: status %s", url, resp.Status)
+		}
+		return nil
+	}
+}
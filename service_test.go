@@ -0,0 +1,60 @@
+package taguchi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRunService verifies that the configuration is applied once per row and
+// that trials wait until the service reports ready.
+func TestRunService(t *testing.T) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Not ready on the first poll after each apply.
+		if polls.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+	}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	applied := 0
+	h := ServiceHarness{
+		Apply: func(context.Context, int, map[string]float64) error {
+			applied++
+			return nil
+		},
+		Ready:         HTTPReady(srv.URL),
+		ReadyInterval: time.Millisecond,
+		Load: func(_ context.Context, trial Trial, d time.Duration) (float64, error) {
+			if d != 10*time.Millisecond {
+				t.Errorf("load duration = %v, want 10ms", d)
+			}
+			return trial.Control["A"], nil
+		},
+		Duration: 10 * time.Millisecond,
+	}
+	if err := exp.RunService(context.Background(), h, RunOptions{Repetitions: 2}); err != nil {
+		t.Fatalf("RunService: %v", err)
+	}
+	if applied != 2 {
+		t.Errorf("Apply called %d times, want 2", applied)
+	}
+	if polls.Load() != 4 {
+		t.Errorf("readiness polled %d times, want 4", polls.Load())
+	}
+	if len(exp.Results) != 2 || len(exp.Results[0].Observations) != 2 {
+		t.Errorf("results = %+v, want 2 trials with 2 observations", exp.Results)
+	}
+}
@@ -0,0 +1,197 @@
+// Package simulate runs experiments against synthetic response models, to
+// check before spending real measurement time whether a design can find the
+// optimum for the expected effect sizes and noise.
+package simulate
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// Model is a synthetic additive response: the response of a configuration is
+// Baseline plus the effect of each factor's level, and every observation adds
+// Gaussian noise with standard deviation NoiseSD.
+// Goal: Optimization goal used to analyze the simulated experiments.
+// Factors: Control factors of the model; observe-only factors are ignored when scoring picks.
+// Baseline: Response with all effects zero.
+// Effects: Additive effect of each level, keyed by factor name; missing factors have no effect.
+// NoiseSD: Standard deviation of the observation noise.
+type Model struct {
+	Goal     taguchi.OptimizationGoal
+	Factors  []taguchi.ControlFactor
+	Baseline float64
+	Effects  map[string][]float64
+	NoiseSD  float64
+}
+
+// Mean returns the noise-free response of a configuration.
+func (m Model) Mean(control map[string]float64) float64 {
+	y := m.Baseline
+	for _, f := range m.Factors {
+		effects := m.Effects[f.Name]
+		for i, level := range f.Levels {
+			if level == control[f.Name] && i < len(effects) {
+				y += effects[i]
+			}
+		}
+	}
+	return y
+}
+
+// ExpectedSNR returns the SNR of a configuration in the long run, computed
+// from the two observations one standard deviation either side of the mean;
+// this gives the exact expected mean squared deviation for the
+// smaller-the-better and nominal-the-best goals.
+func (m Model) ExpectedSNR(control map[string]float64) float64 {
+	y := m.Mean(control)
+	return m.Goal.CalculateSNR([]float64{y - m.NoiseSD, y + m.NoiseSD})
+}
+
+// TrueOptimum returns the configuration with the highest ExpectedSNR over
+// the full factorial, the ground truth the designs are scored against.
+func (m Model) TrueOptimum() map[string]float64 {
+	var best map[string]float64
+	bestSNR := math.Inf(-1)
+	for _, row := range fullFactorial(m.Factors) {
+		control := m.control(row)
+		if snr := m.ExpectedSNR(control); best == nil || snr > bestSNR {
+			best, bestSNR = control, snr
+		}
+	}
+	return best
+}
+
+// control converts a one-based level row into a configuration.
+func (m Model) control(row []int) map[string]float64 {
+	control := make(map[string]float64, len(m.Factors))
+	for j, f := range m.Factors {
+		control[f.Name] = f.Levels[row[j]-1]
+	}
+	return control
+}
+
+// Options configures Validate.
+// Replicates: Simulated experiments per design (100 when zero).
+// Repetitions: Observations per trial (3 when zero).
+// Seed: Seed of the noise generator, making validations reproducible.
+type Options struct {
+	Replicates  int
+	Repetitions int
+	Seed        int64
+}
+
+// Validation reports how well a design recovered the true optimum.
+// Design: Array name, or "full factorial".
+// Runs: Configurations per simulated experiment.
+// Replicates: Number of simulated experiments.
+// Hits: Experiments whose optimal levels had the best expected SNR.
+// HitRate: Hits / Replicates.
+// MeanShortfall: Average expected-SNR gap in dB between the true optimum and the picked configuration.
+type Validation struct {
+	Design        string
+	Runs          int
+	Replicates    int
+	Hits          int
+	HitRate       float64
+	MeanShortfall float64
+}
+
+// Validate simulates each array, and the full factorial as a reference, on
+// the model and reports how often each design picks the true optimum, e.g.
+// to choose between L9 and L27 for the expected effect sizes and noise. The
+// last validation is the full factorial's.
+func Validate(m Model, arrays []taguchi.ArrayType, opts Options) ([]Validation, error) {
+	if m.Goal == nil {
+		return nil, fmt.Errorf("model has no goal")
+	}
+	replicates, reps := opts.Replicates, opts.Repetitions
+	if replicates <= 0 {
+		replicates = 100
+	}
+	if reps <= 0 {
+		reps = 3
+	}
+	best := m.ExpectedSNR(m.TrueOptimum())
+
+	designs := make([][][]int, 0, len(arrays)+1)
+	names := make([]string, 0, len(arrays)+1)
+	for _, name := range arrays {
+		oa, ok := taguchi.StandardArrays[name]
+		if !ok {
+			return nil, fmt.Errorf("orthogonal array %s not defined", name)
+		}
+		designs = append(designs, oa)
+		names = append(names, string(name))
+	}
+	designs = append(designs, fullFactorial(m.Factors))
+	names = append(names, "full factorial")
+
+	var out []Validation
+	for d, oa := range designs {
+		rng := rand.New(rand.NewSource(opts.Seed + int64(d)))
+		v := Validation{Design: names[d], Runs: len(oa), Replicates: replicates}
+		for r := 0; r < replicates; r++ {
+			picked, err := m.simulate(oa, reps, rng)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", names[d], err)
+			}
+			shortfall := best - m.ExpectedSNR(picked)
+			if shortfall <= 1e-9 {
+				v.Hits++
+			}
+			v.MeanShortfall += math.Max(shortfall, 0)
+		}
+		v.HitRate = float64(v.Hits) / float64(replicates)
+		v.MeanShortfall /= float64(replicates)
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// simulate runs one experiment on oa with simulated observations and returns
+// the picked configuration. Observe-only factors keep the level of the true
+// optimum so they do not count against the design.
+func (m Model) simulate(oa [][]int, reps int, rng *rand.Rand) (map[string]float64, error) {
+	exp, err := taguchi.NewExperimentFromFactorsUsingArray(m.Goal, m.Factors, oa, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, trial := range exp.GenerateTrials() {
+		y := m.Mean(trial.Control)
+		obs := make([]float64, reps)
+		for i := range obs {
+			obs[i] = y + m.NoiseSD*rng.NormFloat64()
+		}
+		if err := exp.AddResult(trial, obs); err != nil {
+			return nil, err
+		}
+	}
+	result, err := exp.Analyze()
+	if err != nil {
+		return nil, err
+	}
+	picked := m.TrueOptimum()
+	for name, level := range result.OptimalLevels {
+		picked[name] = level
+	}
+	return picked, nil
+}
+
+// fullFactorial returns every level combination of the factors as a
+// one-based array, the first factor changing slowest.
+func fullFactorial(factors []taguchi.ControlFactor) [][]int {
+	rows := [][]int{{}}
+	for _, f := range factors {
+		var next [][]int
+		for _, row := range rows {
+			for l := 1; l <= len(f.Levels); l++ {
+				next = append(next, append(append([]int(nil), row...), l))
+			}
+		}
+		rows = next
+	}
+	return rows
+}
@@ -0,0 +1,65 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+func threeLevelModel(noiseSD float64) Model {
+	levels := []float64{1, 2, 3}
+	return Model{
+		Goal: taguchi.SmallerTheBetter{},
+		Factors: []taguchi.ControlFactor{
+			{Name: "A", Levels: levels},
+			{Name: "B", Levels: levels},
+			{Name: "C", Levels: levels},
+			{Name: "D", Levels: levels},
+		},
+		Baseline: 20,
+		Effects: map[string][]float64{
+			"A": {4, 0, -4},
+			"B": {-3, 0, 3},
+			"C": {1, -1, 0},
+		},
+		NoiseSD: noiseSD,
+	}
+}
+
+func TestModel_TrueOptimum(t *testing.T) {
+	m := threeLevelModel(1)
+	opt := m.TrueOptimum()
+	if opt["A"] != 3 || opt["B"] != 1 || opt["C"] != 2 {
+		t.Errorf("TrueOptimum = %v, want A=3 B=1 C=2", opt)
+	}
+	if got := m.Mean(opt); got != 12 {
+		t.Errorf("Mean(optimum) = %v, want 12", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	vs, err := Validate(threeLevelModel(0.1), []taguchi.ArrayType{taguchi.L9, taguchi.L27}, Options{Replicates: 20, Seed: 1})
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(vs) != 3 || vs[2].Design != "full factorial" || vs[2].Runs != 81 {
+		t.Fatalf("validations = %+v, want L9, L27 and an 81-run full factorial", vs)
+	}
+	for _, v := range vs {
+		if v.HitRate != 1 || v.MeanShortfall != 0 {
+			t.Errorf("%s: hit rate %.2f, shortfall %.3f dB; want every pick optimal with little noise", v.Design, v.HitRate, v.MeanShortfall)
+		}
+	}
+
+	noisy, err := Validate(threeLevelModel(8), []taguchi.ArrayType{taguchi.L9}, Options{Replicates: 50, Seed: 1})
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if l9 := noisy[0]; l9.HitRate >= 1 || l9.MeanShortfall <= 0 {
+		t.Errorf("L9 under heavy noise: hit rate %.2f, shortfall %.3f dB; want misses", l9.HitRate, l9.MeanShortfall)
+	}
+
+	if _, err := Validate(threeLevelModel(1), []taguchi.ArrayType{taguchi.L8}, Options{Replicates: 1}); err == nil {
+		t.Error("Validate accepted 3-level factors on L8")
+	}
+}
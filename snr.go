@@ -69,3 +69,192 @@ func (n NominalTheBest) CalculateSNR(obs []float64) float64 {
 func (n NominalTheBest) String() string {
 	return "Nominal-the-Best"
 }
+
+// CalculateSNR computes the nominal-the-best type I SNR.
+// Formula: 10 * log10(ȳ² / s²), with s² the sample variance.
+// Fewer than two observations carry no variance information and yield 0.
+func (n NominalTheBestTypeI) CalculateSNR(obs []float64) float64 {
+	var sum ObservationSummary
+	for _, y := range obs {
+		sum.Add(y)
+	}
+	return n.SNRFromSummary(sum)
+}
+
+// String returns the human-readable name for the NominalTheBestTypeI goal.
+func (n NominalTheBestTypeI) String() string {
+	return "Nominal-the-Best-I"
+}
+
+// CalculateSNR computes the signed-target SNR, which depends only on the
+// spread of the observations.
+// Formula: -10 * log10(s²), with s² the sample variance.
+// Fewer than two observations carry no variance information and yield 0.
+func (SignedTarget) CalculateSNR(obs []float64) float64 {
+	var sum ObservationSummary
+	for _, y := range obs {
+		sum.Add(y)
+	}
+	return SignedTarget{}.SNRFromSummary(sum)
+}
+
+// String returns the human-readable name for the SignedTarget goal.
+func (SignedTarget) String() string {
+	return "Signed-Target"
+}
+
+// CalculateSNR computes the operating-window SNR from (lower, upper)
+// threshold pairs; a trailing unpaired observation is ignored (AddResult
+// rejects results with one).
+// Formula: -10 * log10(mean(x_i^2) * mean(1/y_i^2)), x lower and y upper thresholds.
+func (OperatingWindow) CalculateSNR(obs []float64) float64 {
+	pairs := len(obs) / 2
+	if pairs == 0 {
+		return 0
+	}
+	lower, upper := 0.0, 0.0
+	for i := 0; i < pairs; i++ {
+		x, y := obs[2*i], obs[2*i+1]
+		if y == 0 {
+			y = 1e-10 // avoid division by zero
+		}
+		lower += x * x
+		upper += 1 / (y * y)
+	}
+	msd := lower / float64(pairs) * upper / float64(pairs)
+	if msd == 0 {
+		return math.Inf(1)
+	}
+	return -10 * math.Log10(msd)
+}
+
+// String returns the human-readable name for the OperatingWindow goal.
+func (OperatingWindow) String() string {
+	return "Operating-Window"
+}
+
+// CalculateSNR computes the fraction-defective SNR from the defect rate p,
+// the mean of the observations.
+// Formula: -10 * log10(p / (1 - p))
+func (FractionDefective) CalculateSNR(obs []float64) float64 {
+	var sum ObservationSummary
+	for _, y := range obs {
+		sum.Add(y)
+	}
+	return FractionDefective{}.SNRFromSummary(sum)
+}
+
+// String returns the human-readable name for the FractionDefective goal.
+func (FractionDefective) String() string {
+	return "Fraction-Defective"
+}
+
+// CalculateSNR calls the goal's SNR function.
+func (g FuncGoal) CalculateSNR(obs []float64) float64 {
+	return g.SNR(obs)
+}
+
+// String returns the goal's Name.
+func (g FuncGoal) String() string {
+	return g.Name
+}
+
+// pairedGoal reports whether goal reads its observations as consecutive
+// (x, y) pairs rather than independent measurements, so that every result
+// must hold whole pairs.
+func pairedGoal(goal OptimizationGoal) bool {
+	switch goal.(type) {
+	case OperatingWindow, *OperatingWindow, Dynamic, *Dynamic:
+		return true
+	}
+	return false
+}
+
+// SummaryGoal is implemented by goals whose SNR can be computed from an
+// ObservationSummary alone, which allows experiments to use RetainSummary.
+type SummaryGoal interface {
+	OptimizationGoal
+	SNRFromSummary(s ObservationSummary) float64
+}
+
+// SNRFromSummary computes the smaller-the-better SNR from accumulated statistics.
+func (s SmallerTheBetter) SNRFromSummary(sum ObservationSummary) float64 {
+	if sum.Count == 0 {
+		return 0
+	}
+	msd := sum.SumSquares / float64(sum.Count)
+	if msd == 0 {
+		return math.Inf(1)
+	}
+	return -10 * math.Log10(msd)
+}
+
+// SNRFromSummary computes the larger-the-better SNR from accumulated statistics.
+func (l LargerTheBetter) SNRFromSummary(sum ObservationSummary) float64 {
+	if sum.Count == 0 {
+		return 0
+	}
+	return -10 * math.Log10(sum.SumInverseSquares/float64(sum.Count))
+}
+
+// SNRFromSummary computes the nominal-the-best SNR from accumulated statistics,
+// expanding mean((y - Target)²) into its sums.
+func (n NominalTheBest) SNRFromSummary(sum ObservationSummary) float64 {
+	if sum.Count == 0 {
+		return 0
+	}
+	count := float64(sum.Count)
+	msd := (sum.SumSquares - 2*n.Target*sum.Sum + count*n.Target*n.Target) / count
+	if msd <= 0 {
+		return math.Inf(1)
+	}
+	return -10 * math.Log10(msd)
+}
+
+// SNRFromSummary computes the nominal-the-best type I SNR from accumulated statistics.
+func (n NominalTheBestTypeI) SNRFromSummary(sum ObservationSummary) float64 {
+	if sum.Count < 2 {
+		return 0
+	}
+	mean := sum.Sum / float64(sum.Count)
+	variance := sampleVariance(sum)
+	if variance <= 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(mean*mean/variance)
+}
+
+// SNRFromSummary computes the signed-target SNR from accumulated statistics.
+func (SignedTarget) SNRFromSummary(sum ObservationSummary) float64 {
+	if sum.Count < 2 {
+		return 0
+	}
+	variance := sampleVariance(sum)
+	if variance <= 0 {
+		return math.Inf(1)
+	}
+	return -10 * math.Log10(variance)
+}
+
+// sampleVariance returns the sample variance of the summarized observations;
+// sum.Count must be at least 2.
+func sampleVariance(sum ObservationSummary) float64 {
+	count := float64(sum.Count)
+	return (sum.SumSquares - sum.Sum*sum.Sum/count) / (count - 1)
+}
+
+// SNRFromSummary computes the fraction-defective SNR from accumulated
+// statistics. A defect rate of 0 gives +Inf and a rate of 1 gives -Inf.
+func (FractionDefective) SNRFromSummary(sum ObservationSummary) float64 {
+	if sum.Count == 0 {
+		return 0
+	}
+	p := sum.Sum / float64(sum.Count)
+	switch {
+	case p <= 0:
+		return math.Inf(1)
+	case p >= 1:
+		return math.Inf(-1)
+	}
+	return -10 * math.Log10(p/(1-p))
+}
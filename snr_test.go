@@ -0,0 +1,111 @@
+package taguchi
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestSNRVariants(t *testing.T) {
+	tests := []struct {
+		goal OptimizationGoal
+		obs  []float64
+		want float64
+	}{
+		// Mean 10, sample variance 2.
+		{NominalTheBestTypeI{Target: 10}, []float64{9, 11}, 10 * math.Log10(50)},
+		// Sample variance of {-1, 1} is 2.
+		{SignedTarget{}, []float64{-1, 1}, -10 * math.Log10(2)},
+		{SignedTarget{}, []float64{-3, -3}, math.Inf(1)},
+		{SignedTarget{}, []float64{5}, 0},
+		// mean(x²) = (1+4)/2, mean(1/y²) = (1/100+1/400)/2.
+		{OperatingWindow{}, []float64{1, 10, 2, 20, 99}, -10 * math.Log10(2.5*0.00625)},
+		// Two defects in eight units: p/(1-p) = 1/3.
+		{FractionDefective{}, []float64{1, 0, 0, 0, 1, 0, 0, 0}, 10 * math.Log10(3)},
+		{FractionDefective{}, []float64{0, 0}, math.Inf(1)},
+		{FractionDefective{}, []float64{1}, math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		if got := tt.goal.CalculateSNR(tt.obs); !almostEqual(got, tt.want) && got != tt.want {
+			t.Errorf("%s SNR(%v) = %.4f, want %.4f", tt.goal, tt.obs, got, tt.want)
+		}
+	}
+
+	for _, name := range []string{"NTB-I", "Signed-Target", "operating-window", "FractionDefective"} {
+		goal, err := GoalSpec{Name: name}.Goal()
+		if err != nil {
+			t.Errorf("GoalSpec %q: %v", name, err)
+			continue
+		}
+		if spec := NewGoalSpec(goal); spec.Name != goal.String() {
+			t.Errorf("NewGoalSpec(%s) = %+v", goal, spec)
+		}
+	}
+}
+
+// TestFuncGoal plugs a percentile-based latency SNR into an experiment.
+func TestFuncGoal(t *testing.T) {
+	p90 := FuncGoal{
+		Name: "p90-latency",
+		SNR: func(obs []float64) float64 {
+			sorted := append([]float64(nil), obs...)
+			sort.Float64s(sorted)
+			return -20 * math.Log10(sorted[(len(sorted)*9)/10])
+		},
+	}
+	factors := []ControlFactor{{Name: "A", Levels: []float64{1, 2}}}
+	exp, err := NewExperimentFromFactorsUsingArray(p90, factors, [][]int{{1}, {2}}, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	trials := exp.GenerateTrials()
+	// A=1 has the lower mean but a long tail.
+	exp.AddResult(trials[0], []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 50})
+	exp.AddResult(trials[1], []float64{3, 3, 3, 3, 3, 3, 3, 3, 3, 3})
+	if got := mustAnalyze(t, exp).OptimalLevels["A"]; got != 2 {
+		t.Errorf("OptimalLevels[A] = %v, want 2 (best p90)", got)
+	}
+	if spec := NewGoalSpec(p90); spec.Name != "p90-latency" {
+		t.Errorf("NewGoalSpec = %+v", spec)
+	}
+}
+
+// TestOperatingWindow_Pairs verifies that results with an unpaired threshold
+// are rejected and that replicates are pooled pair by pair, so a stray value
+// in a result loaded from elsewhere never shifts the pairs of the next one.
+func TestOperatingWindow_Pairs(t *testing.T) {
+	exp := newTestExperiment(t, testDesign{
+		Goal:    OperatingWindow{},
+		Factors: namedFactors([]float64{1, 2}, "A"),
+		Rows:    [][]int{{1}, {2}},
+	})
+	trial := exp.GenerateTrials()[0]
+	if err := exp.AddResult(trial, []float64{1, 10, 5}); !errors.Is(err, ErrInvalidObservations) {
+		t.Errorf("AddResult with an odd count: err = %v, want ErrInvalidObservations", err)
+	}
+	if len(exp.Results) != 0 {
+		t.Fatalf("rejected result was recorded")
+	}
+	for _, goal := range []OptimizationGoal{&OperatingWindow{}, &Dynamic{}} {
+		ptr := newTestExperiment(t, testDesign{Goal: goal, Factors: namedFactors([]float64{1, 2}, "A"), Rows: [][]int{{1}, {2}}})
+		if err := ptr.AddResult(ptr.GenerateTrials()[0], []float64{1, 10, 5}); !errors.Is(err, ErrInvalidObservations) {
+			t.Errorf("%T goal: AddResult with an odd count: err = %v, want ErrInvalidObservations", goal, err)
+		}
+		ptr.AnalyzeMeans = true
+		if _, err := ptr.Analyze(); !errors.Is(err, ErrUnsupportedGoal) {
+			t.Errorf("%T goal: Analyze with AnalyzeMeans: err = %v, want ErrUnsupportedGoal", goal, err)
+		}
+	}
+
+	// Bypass validation, as results restored from a store do.
+	for _, obs := range [][]float64{{1, 10, 5}, {2, 20}} {
+		if err := exp.recordResult(TrialResult{Trial: trial, Observations: obs}); err != nil {
+			t.Fatalf("recordResult: %v", err)
+		}
+	}
+	want := OperatingWindow{}.CalculateSNR([]float64{1, 10, 2, 20})
+	if got := exp.resultsSNR(exp.Results); !almostEqual(got, want) {
+		t.Errorf("pooled SNR = %.4f, want %.4f from the pairs (1, 10) and (2, 20)", got, want)
+	}
+}
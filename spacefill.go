@@ -0,0 +1,120 @@
+package taguchi
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// RangeFactor is a control factor varied over a continuous range instead of
+// a few discrete levels, for space-filling designs (see RangeExperiment).
+// Name: Identifier for the factor.
+// Min, Max: Bounds of the range; Min must be below Max.
+type RangeFactor struct {
+	Name string
+	Min  float64
+	Max  float64
+}
+
+// value maps u in [0, 1) onto the factor's range.
+func (f RangeFactor) value(u float64) float64 {
+	return f.Min + u*(f.Max-f.Min)
+}
+
+// coded maps a setting onto [-1, 1].
+func (f RangeFactor) coded(x float64) float64 {
+	return 2*(x-f.Min)/(f.Max-f.Min) - 1
+}
+
+// uncoded maps a coded value in [-1, 1] back onto the factor's range.
+func (f RangeFactor) uncoded(c float64) float64 {
+	return f.Min + (c+1)/2*(f.Max-f.Min)
+}
+
+// latinHypercubePoints returns n points in [0, 1)^dims such that every
+// dimension has exactly one point in each of its n equal strata.
+func latinHypercubePoints(n, dims int, rng *rand.Rand) [][]float64 {
+	points := make([][]float64, n)
+	for i := range points {
+		points[i] = make([]float64, dims)
+	}
+	for d := 0; d < dims; d++ {
+		for i, stratum := range rng.Perm(n) {
+			points[i][d] = (float64(stratum) + rng.Float64()) / float64(n)
+		}
+	}
+	return points
+}
+
+// sobolDirections holds the degree s, polynomial coefficients a and initial
+// direction numbers m of Sobol dimensions 2 and up (Joe and Kuo, 2008).
+var sobolDirections = []struct {
+	s, a int
+	m    []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+}
+
+// MaxSobolDimensions is the largest number of factors supported by Sobol designs.
+const MaxSobolDimensions = 10
+
+// sobolPoints returns the first n points of the Sobol sequence in [0, 1)^dims,
+// starting with the origin. For n a power of two every dimension has one point
+// in each of n equal strata, like a Latin hypercube, and low-dimensional
+// projections are evenly filled as well.
+func sobolPoints(n, dims int) ([][]float64, error) {
+	if dims > MaxSobolDimensions {
+		return nil, fmt.Errorf("Sobol designs support at most %d factors, got %d", MaxSobolDimensions, dims)
+	}
+	const bits = 32
+	directions := make([][bits + 1]uint32, dims)
+	for d := range directions {
+		v := &directions[d]
+		if d == 0 {
+			for i := 1; i <= bits; i++ {
+				v[i] = 1 << (bits - i)
+			}
+			continue
+		}
+		dir := sobolDirections[d-1]
+		for i := 1; i <= bits; i++ {
+			if i <= dir.s {
+				v[i] = dir.m[i-1] << (bits - i)
+				continue
+			}
+			v[i] = v[i-dir.s] ^ (v[i-dir.s] >> dir.s)
+			for k := 1; k < dir.s; k++ {
+				if (dir.a>>(dir.s-1-k))&1 == 1 {
+					v[i] ^= v[i-k]
+				}
+			}
+		}
+	}
+
+	points := make([][]float64, n)
+	x := make([]uint32, dims)
+	for i := range points {
+		if i > 0 {
+			// Gray code order: flip the direction of the lowest zero bit of i-1.
+			c := 1
+			for j := i - 1; j&1 == 1; j >>= 1 {
+				c++
+			}
+			for d := range x {
+				x[d] ^= directions[d][c]
+			}
+		}
+		points[i] = make([]float64, dims)
+		for d, xd := range x {
+			points[i][d] = float64(xd) / (1 << bits)
+		}
+	}
+	return points, nil
+}
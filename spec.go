@@ -0,0 +1,158 @@
+package taguchi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Spec is a declarative experiment definition for config-driven pipelines,
+// read from YAML or JSON by ParseSpec and LoadSpec:
+//
+//	goal: smaller-the-better   # or larger-the-better, nominal-the-best, ...
+//	target: 5                  # for the nominal-the-best goals
+//	array: L9                  # optional; the smallest standard array that fits
+//	repetitions: 5
+//	factors:
+//	  - name: Threads
+//	    levels: [1, 2, 4]
+//	  - {name: Cache, levels: [64, 128, 256], continuous: true}
+//	noise:
+//	  - name: Load
+//	    levels: [10, 100]
+//	    weights: [0.8, 0.2]       # optional; likelihood of each level
+//
+// Goal: Built-in goal name or abbreviation as accepted by GoalSpec.Goal.
+// Target: Target value for the nominal-the-best goals.
+// Array: Standard orthogonal array; when empty, the smallest standard array that fits the factors.
+// Alpha: Significance level for ANOVA significance flags (DefaultAlpha when zero).
+// Repetitions: Measured runs per trial, as RunOptions.Repetitions.
+// Factors, Noise: Control and noise factors.
+type Spec struct {
+	Goal        string       `json:"goal"`
+	Target      float64      `json:"target"`
+	Array       ArrayType    `json:"array"`
+	Alpha       float64      `json:"alpha"`
+	Repetitions int          `json:"repetitions"`
+	Factors     []FactorSpec `json:"factors"`
+	Noise       []FactorSpec `json:"noise"`
+}
+
+// FactorSpec is a control or noise factor of a Spec. Continuous and
+// ObserveOnly apply to control factors only, Weights to noise factors only.
+type FactorSpec struct {
+	Name        string    `json:"name"`
+	Levels      []float64 `json:"levels"`
+	Continuous  bool      `json:"continuous"`
+	ObserveOnly bool      `json:"observe_only"`
+	Weights     []float64 `json:"weights"`
+}
+
+// ParseSpec reads a spec from YAML, or from JSON when the input starts with
+// "{". YAML specs use block and flow collections, plain and quoted scalars
+// and comments; anchors, tags and multi-line strings are not supported.
+// Unknown keys are rejected so typos do not silently fall back to defaults.
+func ParseSpec(r io.Reader) (Spec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Spec{}, err
+	}
+	raw := data
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		tree, err := parseYAML(string(data))
+		if err != nil {
+			return Spec{}, err
+		}
+		// Round-trip through JSON to reuse its struct decoding and type checks.
+		if raw, err = json.Marshal(tree); err != nil {
+			return Spec{}, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return Spec{}, fmt.Errorf("spec: %w", err)
+	}
+	if s.Goal == "" {
+		return Spec{}, fmt.Errorf("spec: no goal")
+	}
+	if len(s.Factors) == 0 {
+		return Spec{}, fmt.Errorf("spec: no factors")
+	}
+	if s.Repetitions < 0 {
+		return Spec{}, fmt.Errorf("spec: repetitions must not be negative, got %d", s.Repetitions)
+	}
+	return s, nil
+}
+
+// Experiment constructs the experiment of the spec. Without an Array, the
+// standard arrays are tried from the fewest runs up and the first that fits
+// the factors is used.
+func (s Spec) Experiment() (*Experiment[struct{}], error) {
+	goal, err := GoalSpec{Name: s.Goal, Target: s.Target}.Goal()
+	if err != nil {
+		return nil, err
+	}
+	var factors []ControlFactor
+	for _, f := range s.Factors {
+		if f.Weights != nil {
+			return nil, fmt.Errorf("spec: factor %s: weights apply to noise factors only", f.Name)
+		}
+		factors = append(factors, ControlFactor{Name: f.Name, Levels: f.Levels, Continuous: f.Continuous, ObserveOnly: f.ObserveOnly})
+	}
+	var noise []NoiseFactor
+	for _, f := range s.Noise {
+		noise = append(noise, NoiseFactor{Name: f.Name, Levels: f.Levels, Weights: f.Weights})
+	}
+
+	var e *Experiment[struct{}]
+	if s.Array != "" {
+		e, err = NewExperimentFromFactors(goal, factors, s.Array, noise)
+	} else if e, err = smallestExperiment(goal, factors, noise); errors.Is(err, ErrArrayTooSmall) {
+		err = errorf(ErrArrayTooSmall, "no standard array fits the factors; set array in the spec")
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Alpha = s.Alpha
+	return e, nil
+}
+
+// smallestExperiment constructs the experiment on the first standard array,
+// from the fewest runs up, that fits the factors.
+func smallestExperiment(goal OptimizationGoal, factors []ControlFactor, noise []NoiseFactor) (*Experiment[struct{}], error) {
+	for _, info := range StandardArrayInfos() {
+		e, err := NewExperimentFromFactors(goal, factors, info.Name, noise)
+		if !errors.Is(err, ErrArrayTooSmall) {
+			return e, err
+		}
+	}
+	return nil, errorf(ErrArrayTooSmall, "no standard array fits the factors")
+}
+
+// RunOptions returns the run options set by the spec, to be completed with
+// hooks and the other options of the run.
+func (s Spec) RunOptions() RunOptions {
+	return RunOptions{Repetitions: s.Repetitions}
+}
+
+// LoadSpec reads a YAML or JSON spec (see ParseSpec) and returns its
+// experiment together with the run options it sets:
+//
+//	exp, opts, err := taguchi.LoadSpec(f)
+//	...
+//	err = exp.Run(ctx, measure, opts)
+func LoadSpec(r io.Reader) (*Experiment[struct{}], RunOptions, error) {
+	s, err := ParseSpec(r)
+	if err != nil {
+		return nil, RunOptions{}, err
+	}
+	e, err := s.Experiment()
+	if err != nil {
+		return nil, RunOptions{}, err
+	}
+	return e, s.RunOptions(), nil
+}
@@ -0,0 +1,99 @@
+package taguchi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSpec = `
+# Sort tuning
+goal: NTB
+target: 5
+repetitions: 3
+factors:
+  - name: Threads   # worker goroutines
+    levels: [1, 2, 4]
+  - {name: Chunk, levels: [64, 128, 256], continuous: true}
+  - name: "Algo"
+    levels:
+      - 1
+      - 2
+      - 3
+noise:
+- name: Size
+  levels: [1000, 100000]
+  weights: [0.7, 0.3]
+`
+
+func TestParseSpec(t *testing.T) {
+	s, err := ParseSpec(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatalf("ParseSpec: %v", err)
+	}
+	want := Spec{
+		Goal:        "NTB",
+		Target:      5,
+		Repetitions: 3,
+		Factors: []FactorSpec{
+			{Name: "Threads", Levels: []float64{1, 2, 4}},
+			{Name: "Chunk", Levels: []float64{64, 128, 256}, Continuous: true},
+			{Name: "Algo", Levels: []float64{1, 2, 3}},
+		},
+		Noise: []FactorSpec{{Name: "Size", Levels: []float64{1000, 100000}, Weights: []float64{0.7, 0.3}}},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("ParseSpec = %+v, want %+v", s, want)
+	}
+
+	for _, bad := range []string{
+		"goal: STB\nfactors:\n  - name: A\n    lvls: [1, 2]\n",
+		"goal: STB\nfactors:\n  - name: A\n    levels: [1, 2\n",
+		"goal: STB\nfactors: []\n",
+		`{"goal": "STB", "factors": [{"name": "A", "levels": [1, 2]}], "repetitons": 2}`,
+	} {
+		if _, err := ParseSpec(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseSpec(%q) succeeded", bad)
+		}
+	}
+}
+
+// TestLoadSpec verifies that YAML and JSON specs build the same experiment,
+// on the smallest standard array that fits, with the spec's repetitions.
+func TestLoadSpec(t *testing.T) {
+	const jsonSpec = `{
+  "goal": "nominal-the-best", "target": 5, "repetitions": 3,
+  "factors": [
+    {"name": "Threads", "levels": [1, 2, 4]},
+    {"name": "Chunk", "levels": [64, 128, 256], "continuous": true},
+    {"name": "Algo", "levels": [1, 2, 3]}
+  ],
+  "noise": [{"name": "Size", "levels": [1000, 100000], "weights": [0.7, 0.3]}]
+}`
+	fromYAML, yamlOpts, err := LoadSpec(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatalf("LoadSpec(YAML): %v", err)
+	}
+	fromJSON, jsonOpts, err := LoadSpec(strings.NewReader(jsonSpec))
+	if err != nil {
+		t.Fatalf("LoadSpec(JSON): %v", err)
+	}
+	if len(fromYAML.OrthogonalArray) != 9 {
+		t.Errorf("picked an array of %d rows, want L9", len(fromYAML.OrthogonalArray))
+	}
+	if yamlOpts.Repetitions != 3 || jsonOpts.Repetitions != 3 {
+		t.Errorf("Repetitions = %d and %d, want 3", yamlOpts.Repetitions, jsonOpts.Repetitions)
+	}
+	if err := fromYAML.CheckDesign(fromJSON.Design()); err != nil {
+		t.Errorf("YAML and JSON specs differ: %v", err)
+	}
+	if fromJSON.Goal != (NominalTheBest{Target: 5}) {
+		t.Errorf("Goal = %v, want nominal-the-best with target 5", fromJSON.Goal)
+	}
+	if w := fromYAML.NoiseFactors[0].Weights; !reflect.DeepEqual(w, []float64{0.7, 0.3}) {
+		t.Errorf("noise weights = %v, want [0.7 0.3]", w)
+	}
+	if _, _, err := LoadSpec(strings.NewReader("goal: STB\nfactors:\n  - {name: A, levels: [1, 2], weights: [1, 1]}\n")); err == nil {
+		t.Error("LoadSpec accepted weights on a control factor")
+	}
+}
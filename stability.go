@@ -0,0 +1,162 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// FactorStability describes how consistently a control factor's levels rank
+// across the individual noise conditions.
+// Factor: Name of the control factor.
+// KendallW: Kendall's coefficient of concordance of the level rankings across
+// noise conditions, from 0 (no agreement) to 1 (identical rankings).
+// BestLevels: Best level (highest SNR) under each noise condition, keyed by the condition label (e.g., "Load=1, Pattern=2").
+// Flips: True when the best level differs between noise conditions.
+type FactorStability struct {
+	Factor     string
+	KendallW   float64
+	BestLevels map[string]float64
+	Flips      bool
+}
+
+// computeStability ranks each control factor's levels by mean SNR separately
+// under every noise condition and measures the agreement between the
+// rankings. It returns nil when there are fewer than two noise conditions.
+func (e *Experiment[P]) computeStability() []FactorStability {
+	conditions := map[string][]TrialResult{}
+	for _, r := range e.Results {
+		label := noiseLabel(r.Trial.Noise)
+		conditions[label] = append(conditions[label], r)
+	}
+	if len(conditions) < 2 {
+		return nil
+	}
+
+	// SNR of every (row, condition) cell, computed per condition.
+	labels := sortedKeys(conditions)
+	cellSNR := make([][]float64, len(labels))
+	for c, label := range labels {
+		cellSNR[c] = make([]float64, len(e.OrthogonalArray))
+		for i := range e.OrthogonalArray {
+			var matched []TrialResult
+			for _, r := range conditions[label] {
+				if e.rowMatches(i, r.Trial) {
+					matched = append(matched, r)
+				}
+			}
+			if len(matched) == 0 {
+				cellSNR[c][i] = math.NaN()
+				continue
+			}
+			sn := e.resultsSNR(matched)
+			if math.IsInf(sn, 0) {
+				sn = math.Copysign(e.snrCeiling(), sn)
+			}
+			cellSNR[c][i] = sn
+		}
+	}
+
+	var stability []FactorStability
+	for j, factor := range e.ControlFactors {
+		fs := FactorStability{Factor: factor.Name, BestLevels: map[string]float64{}}
+		var rankings [][]float64
+		for c, label := range labels {
+			means := make([]float64, len(factor.Levels))
+			counts := make([]int, len(factor.Levels))
+			for i := range e.OrthogonalArray {
+				if li := e.levelIndex(i, j); !math.IsNaN(cellSNR[c][i]) && li >= 0 && li < len(means) {
+					means[li] += cellSNR[c][i]
+					counts[li]++
+				}
+			}
+			complete := true
+			for li := range means {
+				if counts[li] == 0 {
+					complete = false
+					break
+				}
+				means[li] /= float64(counts[li])
+			}
+			if !complete {
+				continue
+			}
+			best := 0
+			for li, m := range means {
+				if m > means[best] {
+					best = li
+				}
+			}
+			fs.BestLevels[label] = factor.Levels[best]
+			rankings = append(rankings, rankDescending(means))
+		}
+		fs.KendallW = kendallW(rankings)
+		for _, level := range fs.BestLevels {
+			for _, other := range fs.BestLevels {
+				if level != other {
+					fs.Flips = true
+				}
+			}
+		}
+		stability = append(stability, fs)
+	}
+	return stability
+}
+
+// noiseLabel formats a noise configuration as "Name=value, ..." in name order.
+func noiseLabel(noise map[string]float64) string {
+	parts := make([]string, 0, len(noise))
+	for _, name := range sortedKeys(noise) {
+		parts = append(parts, fmt.Sprintf("%s=%g", name, noise[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rankDescending returns the rank (1 = largest) of each value, averaging ties.
+func rankDescending(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] > values[order[b]] })
+	ranks := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+		avg := float64(start+end+1) / 2
+		for k := start; k < end; k++ {
+			ranks[order[k]] = avg
+		}
+		start = end
+	}
+	return ranks
+}
+
+// kendallW computes Kendall's coefficient of concordance of m rankings of n
+// objects: W = 12 S / (m² (n³ - n)), where S is the sum of squared deviations
+// of the objects' rank sums from their mean. It returns 1 for fewer than two rankings.
+func kendallW(rankings [][]float64) float64 {
+	m := len(rankings)
+	if m < 2 {
+		return 1
+	}
+	n := len(rankings[0])
+	if n < 2 {
+		return 1
+	}
+	sums := make([]float64, n)
+	for _, r := range rankings {
+		for i, rank := range r {
+			sums[i] += rank
+		}
+	}
+	mean := float64(m*(n+1)) / 2
+	s := 0.0
+	for _, sum := range sums {
+		s += (sum - mean) * (sum - mean)
+	}
+	return 12 * s / (float64(m*m) * float64(n*n*n-n))
+}
@@ -0,0 +1,165 @@
+package taguchi
+
+import "math"
+
+// regIncBeta returns the regularized incomplete beta function I_x(a, b),
+// evaluated with Lentz's continued fraction.
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	// The continued fraction converges quickly for x < (a+1)/(a+b+2); use the
+	// symmetry I_x(a, b) = 1 - I_{1-x}(b, a) otherwise.
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+	}
+	return front * betaContinuedFraction(a, b, x) / a
+}
+
+func betaContinuedFraction(a, b, x float64) float64 {
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	f := d
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+		for _, num := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			f *= c * d
+		}
+		if math.Abs(c*d-1) < 1e-14 {
+			break
+		}
+	}
+	return f
+}
+
+// studentTCDF returns P(T <= t) for Student's t distribution with df degrees of freedom.
+func studentTCDF(t, df float64) float64 {
+	tail := 0.5 * regIncBeta(df/2, 0.5, df/(df+t*t))
+	if t >= 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// studentTQuantile returns the t value with P(T <= t) = p, found by bisection.
+func studentTQuantile(p, df float64) float64 {
+	lo, hi := -1e3, 1e3
+	for i := 0; i < 200 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if studentTCDF(mid, df) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// fSurvival returns P(F > f) for the F distribution with d1 and d2 degrees of freedom.
+func fSurvival(f float64, d1, d2 int) float64 {
+	switch {
+	case math.IsNaN(f):
+		return math.NaN()
+	case f <= 0:
+		return 1
+	case math.IsInf(f, 1):
+		return 0
+	}
+	a, b := float64(d1), float64(d2)
+	return regIncBeta(b/2, a/2, b/(b+a*f))
+}
+
+// studentizedRangeCDF returns P(Q <= q) for the studentized range of k
+// normal means with df error degrees of freedom: the range distribution of k
+// standard normals averaged over the chi distribution of the standard error
+// estimate, both integrated with Simpson's rule.
+func studentizedRangeCDF(q float64, k int, df float64) float64 {
+	if q <= 0 {
+		return 0
+	}
+	if df > 2000 {
+		return normalRangeCDF(q, k)
+	}
+	// s = sqrt(χ²_df/df) has log-density
+	// (df/2)·log(df/2) − logΓ(df/2) + log 2 + (df−1)·log s − df·s²/2.
+	// Integrating over u = log s resolves the small s that dominate at low df.
+	lg, _ := math.Lgamma(df / 2)
+	logC := df/2*math.Log(df/2) - lg + math.Ln2
+	spread := 8 / math.Sqrt(2*df)
+	lo := math.Log(math.Max(1e-8, 1-spread))
+	hi := math.Log(1 + spread*math.Max(1, 4/math.Sqrt(df)))
+	return simpson(func(u float64) float64 {
+		s := math.Exp(u)
+		return math.Exp(logC+df*u-df*s*s/2) * normalRangeCDF(q*s, k)
+	}, lo, hi, 300)
+}
+
+// normalRangeCDF returns P(R <= w) for the range R of k standard normals.
+func normalRangeCDF(w float64, k int) float64 {
+	if w <= 0 {
+		return 0
+	}
+	if k == 2 {
+		return math.Erf(w / 2)
+	}
+	p := simpson(func(z float64) float64 {
+		d := normalCDF(z) - normalCDF(z-w)
+		return math.Exp(-z*z/2) / math.Sqrt(2*math.Pi) * math.Pow(d, float64(k-1))
+	}, -8, 8+w, 240)
+	return math.Min(1, float64(k)*p)
+}
+
+// studentizedRangeQuantile returns the q with P(Q <= q) = p, found by bisection.
+func studentizedRangeQuantile(p float64, k int, df float64) float64 {
+	lo, hi := 0.0, 100.0
+	for i := 0; i < 60 && hi-lo > 1e-7; i++ {
+		mid := (lo + hi) / 2
+		if studentizedRangeCDF(mid, k, df) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// simpson integrates f over [a, b] with Simpson's rule on n (even) intervals.
+func simpson(f func(float64) float64, a, b float64, n int) float64 {
+	h := (b - a) / float64(n)
+	sum := f(a) + f(b)
+	for i := 1; i < n; i++ {
+		if i%2 == 1 {
+			sum += 4 * f(a+float64(i)*h)
+		} else {
+			sum += 2 * f(a+float64(i)*h)
+		}
+	}
+	return sum * h / 3
+}
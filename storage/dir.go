@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// Files of an experiment in a Dir store.
+const (
+	dirDesign  = "design.json"
+	dirResults = "results.jsonl"
+)
+
+// Dir stores each experiment in a subdirectory named after its ID, holding
+// its design as JSON and its results as a journal with one JSON result per
+// line. Every result is appended and synced to disk before it is recorded,
+// and a last line cut short by a crash is dropped when loading.
+type Dir struct {
+	path string
+}
+
+// NewDir returns a store keeping its experiments under path, which is
+// created if needed.
+func NewDir(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+	return &Dir{path: path}, nil
+}
+
+// Experiment returns the handle of the experiment with the given ID.
+func (d *Dir) Experiment(id string) taguchi.Store {
+	return dirExperiment{dir: filepath.Join(d.path, id), id: id}
+}
+
+// IDs lists the IDs of the saved experiments in ascending order.
+func (d *Dir) IDs() ([]string, error) {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(d.path, entry.Name(), dirDesign)); err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Close does nothing; files are closed after every write.
+func (d *Dir) Close() error {
+	return nil
+}
+
+// dirExperiment is the taguchi.Store of one experiment in a Dir.
+type dirExperiment struct {
+	dir string
+	id  string
+}
+
+// SaveExperiment replaces the design and journal of the experiment. Each file
+// is written to a temporary file that then replaces it.
+func (x dirExperiment) SaveExperiment(d taguchi.Design, results []taguchi.TrialResult) error {
+	if err := checkID(x.id); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(x.dir, 0o755); err != nil {
+		return err
+	}
+	design, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	var journal bytes.Buffer
+	for _, r := range results {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		journal.Write(line)
+		journal.WriteByte('\n')
+	}
+	if err := writeFileAtomic(filepath.Join(x.dir, dirResults), journal.Bytes()); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(x.dir, dirDesign), design)
+}
+
+// AddResult appends the result to the journal and syncs it to disk.
+func (x dirExperiment) AddResult(r taguchi.TrialResult) error {
+	if err := checkID(x.id); err != nil {
+		return err
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(x.dir, dirResults), os.O_WRONLY|os.O_APPEND, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("experiment %s: %w", x.id, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads the design and the journal of the experiment.
+func (x dirExperiment) Load() (taguchi.Design, []taguchi.TrialResult, error) {
+	var d taguchi.Design
+	if err := checkID(x.id); err != nil {
+		return d, nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(x.dir, dirDesign))
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil, ErrNotFound
+	}
+	if err != nil {
+		return d, nil, err
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return d, nil, fmt.Errorf("%s: %w", dirDesign, err)
+	}
+
+	path := filepath.Join(x.dir, dirResults)
+	journal, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return d, nil, err
+	}
+	// A last line without its newline was cut short by a crash mid-write;
+	// drop it so that the next result starts on a line of its own.
+	if end := bytes.LastIndexByte(journal, '\n') + 1; end < len(journal) {
+		if err := os.Truncate(path, int64(end)); err != nil {
+			return d, nil, err
+		}
+		journal = journal[:end]
+	}
+	var results []taguchi.TrialResult
+	for n, line := range bytes.Split(bytes.TrimSuffix(journal, []byte("\n")), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var r taguchi.TrialResult
+		if err := json.Unmarshal(line, &r); err != nil {
+			return d, nil, fmt.Errorf("%s line %d: %w", dirResults, n+1, err)
+		}
+		results = append(results, r)
+	}
+	return d, results, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory that
+// then replaces path, so an interruption never leaves a truncated file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
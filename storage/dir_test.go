@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/internal/testexp"
+)
+
+func measure(_ context.Context, trial taguchi.Trial) (float64, error) {
+	return trial.Control["A"]*trial.Control["B"] + trial.Noise["N"], nil
+}
+
+func TestDir_RunAndReopen(t *testing.T) {
+	store, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	exp := testexp.New(t, testexp.Design{})
+	exp.Retention = taguchi.RetainSummary
+	if err := exp.AttachStore(store.Experiment("tuning")); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	opts := taguchi.RunOptions{Repetitions: 3, Metadata: map[string]string{"commit": "abc123"}}
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	reopened, err := Open(store, "tuning")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(reopened.Results) != len(exp.Results) {
+		t.Fatalf("reopened %d results, want %d", len(reopened.Results), len(exp.Results))
+	}
+	for i, r := range reopened.Results {
+		want := exp.Results[i]
+		if r.Trial.ID != want.Trial.ID || r.Sequence != want.Sequence || r.Summary != want.Summary {
+			t.Errorf("result %d: %+v, want %+v", i, r, want)
+		}
+		// The store keeps raw observations even though the experiment only
+		// retained summaries.
+		if len(r.Observations) != 3 || r.Metadata["commit"] != "abc123" {
+			t.Errorf("result %d: observations %v, metadata %v", i, r.Observations, r.Metadata)
+		}
+	}
+	before, _ := exp.Analyze()
+	after, err := reopened.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if !slices.Equal(before.MainEffects["A"], after.MainEffects["A"]) {
+		t.Errorf("main effects of A %v after reopening, want %v", after.MainEffects["A"], before.MainEffects["A"])
+	}
+
+	// The reopened experiment stays attached.
+	trial := reopened.GenerateTrials()[0]
+	if err := reopened.AppendResult(trial, []float64{42}); err != nil {
+		t.Fatalf("AppendResult: %v", err)
+	}
+	again, err := Open(store, "tuning")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if n := len(again.Results); n != len(exp.Results)+1 || again.Results[n-1].Observations[0] != 42 {
+		t.Errorf("after AppendResult: %d results, want %d ending with 42", n, len(exp.Results)+1)
+	}
+
+	ids, err := store.IDs()
+	if err != nil || !slices.Equal(ids, []string{"tuning"}) {
+		t.Errorf("IDs = %v, %v; want [tuning]", ids, err)
+	}
+}
+
+func TestDir_AttachExistingResults(t *testing.T) {
+	store, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	exp := testexp.New(t, testexp.Design{})
+	trials := exp.GenerateTrials()
+	if err := exp.AddResult(trials[0], []float64{1, 2}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	if err := exp.AttachStore(store.Experiment("e")); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	// Attaching again replaces the stored state instead of duplicating it.
+	if err := exp.AttachStore(store.Experiment("e")); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	if err := exp.AddResult(trials[1], []float64{3}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	reopened, err := Open(store, "e")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(reopened.Results) != 2 || reopened.Results[1].Sequence != 2 {
+		t.Errorf("reopened results %+v, want the two recorded ones", reopened.Results)
+	}
+}
+
+func TestDir_TruncatedJournal(t *testing.T) {
+	path := t.TempDir()
+	store, err := NewDir(path)
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	exp := testexp.New(t, testexp.Design{})
+	if err := exp.AttachStore(store.Experiment("e")); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	trials := exp.GenerateTrials()
+	if err := exp.AddResult(trials[0], []float64{1}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	// Simulate a crash in the middle of writing the second result.
+	f, err := os.OpenFile(filepath.Join(path, "e", dirResults), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"Trial":{"ID":2`)
+	f.Close()
+
+	reopened, err := Open(store, "e")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(reopened.Results) != 1 {
+		t.Fatalf("reopened %d results, want the complete one", len(reopened.Results))
+	}
+	if err := reopened.AddResult(trials[1], []float64{2}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	again, err := Open(store, "e")
+	if err != nil {
+		t.Fatalf("Open after appending: %v", err)
+	}
+	if len(again.Results) != 2 {
+		t.Errorf("%d results after appending, want 2", len(again.Results))
+	}
+}
+
+func TestDir_Errors(t *testing.T) {
+	store, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	if _, err := Open(store, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open(missing) = %v, want ErrNotFound", err)
+	}
+	if err := store.Experiment("missing").AddResult(taguchi.TrialResult{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AddResult to a missing experiment = %v, want ErrNotFound", err)
+	}
+	if err := testexp.New(t, testexp.Design{}).AttachStore(store.Experiment("../escape")); err == nil {
+		t.Error("AttachStore with an ID outside the store: want error")
+	}
+}
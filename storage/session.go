@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// Session ties an experiment constructed in code to its journal in a Store,
+// so that a crashed benchmark run restarts where it stopped instead of
+// repeating completed work:
+//
+//	sess, err := storage.Resume(store, "cache-tuning", newExperiment())
+//	for _, trial := range sess.Pending() {
+//		err = sess.AddResult(trial, benchmark(trial))
+//	}
+//
+// Every result is written to the journal before it is recorded.
+type Session[P any] struct {
+	exp *taguchi.Experiment[P]
+	id  string
+}
+
+// SessionProgress is a snapshot of a session.
+// Completed, Pending: Trials of the design with and without a result.
+// Results: Results recorded, including replicates of completed trials.
+type SessionProgress struct {
+	Completed int
+	Pending   int
+	Results   int
+}
+
+// Resume starts a session for exp under id in s. When s holds the
+// experiment already, its results are loaded into exp with the checks of
+// Experiment.RestoreStore, so a design that changed since the journal was
+// written is rejected with taguchi.ErrDesignMismatch. Otherwise the
+// experiment is saved with the results it has.
+func Resume[P any](s Store, id string, exp *taguchi.Experiment[P]) (*Session[P], error) {
+	x := s.Experiment(id)
+	err := exp.RestoreStore(x)
+	if errors.Is(err, ErrNotFound) {
+		err = exp.AttachStore(x)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("experiment %s: %w", id, err)
+	}
+	return &Session[P]{exp: exp, id: id}, nil
+}
+
+// Experiment returns the experiment of the session, e.g. to analyze it.
+func (s *Session[P]) Experiment() *taguchi.Experiment[P] {
+	return s.exp
+}
+
+// ID returns the experiment ID of the session in its store.
+func (s *Session[P]) ID() string {
+	return s.id
+}
+
+// Pending returns the trials of GenerateTrials that have no result yet, in
+// run order.
+func (s *Session[P]) Pending() []taguchi.Trial {
+	return s.exp.PendingTrials()
+}
+
+// AddResult journals and records the observations of a trial like
+// Experiment.AddResult.
+func (s *Session[P]) AddResult(trial taguchi.Trial, observations []float64) error {
+	return s.exp.AddResult(trial, observations)
+}
+
+// Run measures the pending trials like Experiment.Run with SkipCompleted.
+func (s *Session[P]) Run(ctx context.Context, measure taguchi.MeasureFunc, opts taguchi.RunOptions) error {
+	opts.SkipCompleted = true
+	return s.exp.Run(ctx, measure, opts)
+}
+
+// Progress counts the trials with and without a result.
+func (s *Session[P]) Progress() SessionProgress {
+	trials := s.exp.GenerateTrials()
+	pending := len(s.Pending())
+	return SessionProgress{
+		Completed: len(trials) - pending,
+		Pending:   pending,
+		Results:   len(s.exp.Results),
+	}
+}
+
+// Done reports whether every trial of the design has a result.
+func (s *Session[P]) Done() bool {
+	return len(s.Pending()) == 0
+}
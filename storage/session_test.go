@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/internal/testexp"
+)
+
+func TestSession_Resume(t *testing.T) {
+	store, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	sess, err := Resume(store, "bench", testexp.New(t, testexp.Design{}))
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	trials := sess.Pending()
+	if len(trials) != 8 || sess.Done() {
+		t.Fatalf("new session has %d pending trials, want 8", len(trials))
+	}
+	// The run crashes after three trials.
+	for _, trial := range trials[:3] {
+		y, _ := measure(context.Background(), trial)
+		if err := sess.AddResult(trial, []float64{y}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+
+	sess, err = Resume(store, "bench", testexp.New(t, testexp.Design{}))
+	if err != nil {
+		t.Fatalf("Resume after restart: %v", err)
+	}
+	if p := sess.Progress(); p != (SessionProgress{Completed: 3, Pending: 5, Results: 3}) {
+		t.Errorf("Progress = %+v, want 3 completed and 5 pending", p)
+	}
+	pending := sess.Pending()
+	if len(pending) != 5 || pending[0].Key != trials[3].Key {
+		t.Fatalf("pending after restart = %d trials, want the last 5", len(pending))
+	}
+	var measured int
+	counting := func(ctx context.Context, trial taguchi.Trial) (float64, error) {
+		measured++
+		return measure(ctx, trial)
+	}
+	if err := sess.Run(context.Background(), counting, taguchi.RunOptions{}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if measured != 5 || !sess.Done() {
+		t.Errorf("Run measured %d trials, want only the 5 pending", measured)
+	}
+	reopened, err := Open(store, "bench")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(reopened.Results) != 8 {
+		t.Errorf("journal holds %d results, want 8", len(reopened.Results))
+	}
+}
+
+func TestSession_DesignChanged(t *testing.T) {
+	store, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	if _, err := Resume(store, "bench", testexp.New(t, testexp.Design{})); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	factors := []taguchi.ControlFactor{
+		{Name: "A", Levels: []float64{1, 3}},
+		{Name: "B", Levels: []float64{10, 20}},
+	}
+	changed, err := taguchi.NewExperimentFromFactors(taguchi.SmallerTheBetter{}, factors, taguchi.L4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resume(store, "bench", changed); !errors.Is(err, taguchi.ErrDesignMismatch) {
+		t.Errorf("Resume with a changed design = %v, want ErrDesignMismatch", err)
+	}
+}
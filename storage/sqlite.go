@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// sqliteSchema creates the tables of a SQLite store. Results keep their
+// trial configuration and the maps of secondary data as JSON, while
+// observations get a table of their own so they can be queried directly.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS experiments (
+	id     TEXT PRIMARY KEY,
+	design TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	experiment          TEXT NOT NULL REFERENCES experiments(id),
+	sequence            INTEGER NOT NULL,
+	trial               INTEGER NOT NULL,
+	array_row           INTEGER NOT NULL,
+	control             TEXT NOT NULL,
+	noise               TEXT NOT NULL,
+	trial_metadata      TEXT NOT NULL,
+	count               INTEGER NOT NULL,
+	sum                 REAL NOT NULL,
+	sum_squares         REAL NOT NULL,
+	sum_inverse_squares REAL NOT NULL,
+	secondary           TEXT NOT NULL,
+	noise_readings      TEXT NOT NULL,
+	block               INTEGER NOT NULL,
+	metadata            TEXT NOT NULL,
+	PRIMARY KEY (experiment, sequence)
+);
+CREATE TABLE IF NOT EXISTS observations (
+	experiment TEXT NOT NULL,
+	sequence   INTEGER NOT NULL,
+	position   INTEGER NOT NULL,
+	value      REAL NOT NULL,
+	PRIMARY KEY (experiment, sequence, position)
+);
+`
+
+// SQLite stores experiments in a SQLite database in three tables:
+// experiments (ID and design as JSON), results (one row per trial result)
+// and observations (one row per raw observation). Every result is written in
+// a transaction of its own.
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a store in db, creating its tables if needed. The
+// database must be opened with a SQLite driver, e.g. modernc.org/sqlite or
+// github.com/mattn/go-sqlite3, which this package leaves to the caller.
+func NewSQLite(db *sql.DB) (*SQLite, error) {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("creating tables: %w", err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+// Experiment returns the handle of the experiment with the given ID.
+func (s *SQLite) Experiment(id string) taguchi.Store {
+	return sqliteExperiment{db: s.db, id: id}
+}
+
+// IDs lists the IDs of the saved experiments in ascending order.
+func (s *SQLite) IDs() ([]string, error) {
+	rows, err := s.db.Query(`SELECT id FROM experiments ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Close closes the database.
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// sqliteExperiment is the taguchi.Store of one experiment in a SQLite store.
+type sqliteExperiment struct {
+	db *sql.DB
+	id string
+}
+
+// SaveExperiment replaces the design and results of the experiment in one
+// transaction.
+func (x sqliteExperiment) SaveExperiment(d taguchi.Design, results []taguchi.TrialResult) error {
+	if err := checkID(x.id); err != nil {
+		return err
+	}
+	design, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return x.inTx(func(tx *sql.Tx) error {
+		for _, stmt := range []string{
+			`DELETE FROM observations WHERE experiment = ?`,
+			`DELETE FROM results WHERE experiment = ?`,
+			`DELETE FROM experiments WHERE id = ?`,
+		} {
+			if _, err := tx.Exec(stmt, x.id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO experiments (id, design) VALUES (?, ?)`, x.id, string(design)); err != nil {
+			return err
+		}
+		for _, r := range results {
+			if err := x.insertResult(tx, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddResult inserts the result and its observations in one transaction.
+func (x sqliteExperiment) AddResult(r taguchi.TrialResult) error {
+	if err := checkID(x.id); err != nil {
+		return err
+	}
+	return x.inTx(func(tx *sql.Tx) error {
+		var n int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM experiments WHERE id = ?`, x.id).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("experiment %s: %w", x.id, ErrNotFound)
+		}
+		return x.insertResult(tx, r)
+	})
+}
+
+// insertResult writes a result row and its observation rows.
+func (x sqliteExperiment) insertResult(tx *sql.Tx, r taguchi.TrialResult) error {
+	var cols [6]string
+	for i, v := range []any{r.Trial.Control, r.Trial.Noise, r.Trial.Metadata, r.Secondary, r.NoiseReadings, r.Metadata} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("trial %d: %w", r.Trial.ID, err)
+		}
+		cols[i] = string(data)
+	}
+	s := r.Summary
+	if _, err := tx.Exec(`INSERT INTO results (experiment, sequence, trial, array_row, control, noise, trial_metadata,
+		count, sum, sum_squares, sum_inverse_squares, secondary, noise_readings, block, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		x.id, r.Sequence, r.Trial.ID, r.Trial.Row, cols[0], cols[1], cols[2],
+		s.Count, s.Sum, s.SumSquares, s.SumInverseSquares, cols[3], cols[4], r.Block, cols[5]); err != nil {
+		return err
+	}
+	for i, y := range r.Observations {
+		if _, err := tx.Exec(`INSERT INTO observations (experiment, sequence, position, value) VALUES (?, ?, ?, ?)`,
+			x.id, r.Sequence, i, y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads the design, results and observations of the experiment.
+func (x sqliteExperiment) Load() (taguchi.Design, []taguchi.TrialResult, error) {
+	var d taguchi.Design
+	if err := checkID(x.id); err != nil {
+		return d, nil, err
+	}
+	var design string
+	err := x.db.QueryRow(`SELECT design FROM experiments WHERE id = ?`, x.id).Scan(&design)
+	if errors.Is(err, sql.ErrNoRows) {
+		return d, nil, ErrNotFound
+	}
+	if err != nil {
+		return d, nil, err
+	}
+	if err := json.Unmarshal([]byte(design), &d); err != nil {
+		return d, nil, fmt.Errorf("design: %w", err)
+	}
+
+	results, err := x.loadResults()
+	if err != nil {
+		return d, nil, err
+	}
+	bySequence := make(map[int]*taguchi.TrialResult, len(results))
+	for i := range results {
+		bySequence[results[i].Sequence] = &results[i]
+	}
+	rows, err := x.db.Query(`SELECT sequence, value FROM observations WHERE experiment = ? ORDER BY sequence, position`, x.id)
+	if err != nil {
+		return d, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seq int
+		var y float64
+		if err := rows.Scan(&seq, &y); err != nil {
+			return d, nil, err
+		}
+		if r := bySequence[seq]; r != nil {
+			r.Observations = append(r.Observations, y)
+		}
+	}
+	return d, results, rows.Err()
+}
+
+// loadResults reads the result rows of the experiment in recording order,
+// without their observations.
+func (x sqliteExperiment) loadResults() ([]taguchi.TrialResult, error) {
+	rows, err := x.db.Query(`SELECT sequence, trial, array_row, control, noise, trial_metadata,
+		count, sum, sum_squares, sum_inverse_squares, secondary, noise_readings, block, metadata
+		FROM results WHERE experiment = ? ORDER BY sequence`, x.id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []taguchi.TrialResult
+	for rows.Next() {
+		var r taguchi.TrialResult
+		var cols [6]string
+		s := &r.Summary
+		if err := rows.Scan(&r.Sequence, &r.Trial.ID, &r.Trial.Row, &cols[0], &cols[1], &cols[2],
+			&s.Count, &s.Sum, &s.SumSquares, &s.SumInverseSquares, &cols[3], &cols[4], &r.Block, &cols[5]); err != nil {
+			return nil, err
+		}
+		for i, v := range []any{&r.Trial.Control, &r.Trial.Noise, &r.Trial.Metadata, &r.Secondary, &r.NoiseReadings, &r.Metadata} {
+			if err := json.Unmarshal([]byte(cols[i]), v); err != nil {
+				return nil, fmt.Errorf("result %d: %w", r.Sequence, err)
+			}
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// inTx runs f in a transaction, committing when it succeeds.
+func (x sqliteExperiment) inTx(f func(*sql.Tx) error) error {
+	tx, err := x.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
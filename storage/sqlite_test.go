@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+	"github.com/marijaaleksic/taguchi/internal/testexp"
+)
+
+// memDB is an in-memory database/sql driver that understands exactly the
+// statements of SQLite, so the store can be tested without a SQLite driver.
+// Every table keeps its rows as the inserted values, with the experiment ID
+// first. Statements containing fail return an error.
+type memDB struct {
+	mu     sync.Mutex
+	tables map[string][][]driver.Value
+	saved  map[string][][]driver.Value
+	fail   string
+}
+
+func newMemSQLite(t *testing.T) (*SQLite, *memDB) {
+	t.Helper()
+	m := &memDB{tables: map[string][][]driver.Value{}}
+	s, err := NewSQLite(sql.OpenDB(m))
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, m
+}
+
+func (m *memDB) Connect(context.Context) (driver.Conn, error) { return memConn{m}, nil }
+func (m *memDB) Driver() driver.Driver                        { return nil }
+
+func (m *memDB) fails(query string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fail != "" && strings.Contains(query, m.fail)
+}
+
+// copyTables returns a copy of the tables, as saved when a transaction begins.
+func (m *memDB) copyTables() map[string][][]driver.Value {
+	tables := make(map[string][][]driver.Value, len(m.tables))
+	for name, rows := range m.tables {
+		tables[name] = slices.Clone(rows)
+	}
+	return tables
+}
+
+// exec runs a statement on the tables.
+func (m *memDB) exec(query string, args []driver.Value) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fields := strings.Fields(query)
+	switch {
+	case fields[0] == "CREATE":
+	case fields[0] == "DELETE":
+		m.tables[fields[2]] = slices.DeleteFunc(m.tables[fields[2]], func(row []driver.Value) bool { return row[0] == args[0] })
+	case fields[0] == "INSERT":
+		m.tables[fields[2]] = append(m.tables[fields[2]], slices.Clone(args))
+	default:
+		return errors.New("unsupported statement: " + query)
+	}
+	return nil
+}
+
+// query runs a query on the tables, returning the selected columns of the
+// matching rows in order.
+func (m *memDB) query(query string, args []driver.Value) ([][]driver.Value, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out [][]driver.Value
+	switch {
+	case strings.HasPrefix(query, "SELECT id FROM experiments"):
+		for _, row := range m.tables["experiments"] {
+			out = append(out, row[:1])
+		}
+		slices.SortFunc(out, func(a, b []driver.Value) int { return strings.Compare(a[0].(string), b[0].(string)) })
+	case strings.HasPrefix(query, "SELECT COUNT(*) FROM experiments"):
+		var n int64
+		for _, row := range m.tables["experiments"] {
+			if row[0] == args[0] {
+				n++
+			}
+		}
+		out = append(out, []driver.Value{n})
+	case strings.HasPrefix(query, "SELECT design FROM experiments"):
+		for _, row := range m.tables["experiments"] {
+			if row[0] == args[0] {
+				out = append(out, row[1:])
+			}
+		}
+	case strings.HasPrefix(query, "SELECT sequence, trial"):
+		for _, row := range m.tables["results"] {
+			if row[0] == args[0] {
+				out = append(out, row[1:])
+			}
+		}
+		slices.SortFunc(out, func(a, b []driver.Value) int { return int(a[0].(int64) - b[0].(int64)) })
+	case strings.HasPrefix(query, "SELECT sequence, value"):
+		var rows [][]driver.Value
+		for _, row := range m.tables["observations"] {
+			if row[0] == args[0] {
+				rows = append(rows, row)
+			}
+		}
+		slices.SortFunc(rows, func(a, b []driver.Value) int {
+			if c := int(a[1].(int64) - b[1].(int64)); c != 0 {
+				return c
+			}
+			return int(a[2].(int64) - b[2].(int64))
+		})
+		for _, row := range rows {
+			out = append(out, []driver.Value{row[1], row[3]})
+		}
+	default:
+		return nil, errors.New("unsupported query: " + query)
+	}
+	return out, nil
+}
+
+type memConn struct{ m *memDB }
+
+func (c memConn) Prepare(query string) (driver.Stmt, error) {
+	return memStmt{c.m, strings.TrimSpace(query)}, nil
+}
+
+func (c memConn) Close() error { return nil }
+
+func (c memConn) Begin() (driver.Tx, error) {
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+	c.m.saved = c.m.copyTables()
+	return memTx{c.m}, nil
+}
+
+type memTx struct{ m *memDB }
+
+func (tx memTx) Commit() error { return nil }
+
+func (tx memTx) Rollback() error {
+	tx.m.mu.Lock()
+	defer tx.m.mu.Unlock()
+	tx.m.tables = tx.m.saved
+	return nil
+}
+
+type memStmt struct {
+	m     *memDB
+	query string
+}
+
+func (s memStmt) Close() error  { return nil }
+func (s memStmt) NumInput() int { return -1 }
+
+func (s memStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.m.fails(s.query) {
+		return nil, errors.New("disk I/O error")
+	}
+	return driver.RowsAffected(0), s.m.exec(s.query, args)
+}
+
+func (s memStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.m.fails(s.query) {
+		return nil, errors.New("disk I/O error")
+	}
+	rows, err := s.m.query(s.query, args)
+	return &memRows{rows: rows}, err
+}
+
+type memRows struct{ rows [][]driver.Value }
+
+func (r *memRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *memRows) Close() error { return nil }
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLite_RunAndReopen(t *testing.T) {
+	store, _ := newMemSQLite(t)
+	exp := testexp.New(t, testexp.Design{})
+	exp.Retention = taguchi.RetainSummary
+	if err := exp.AttachStore(store.Experiment("tuning")); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	opts := taguchi.RunOptions{Repetitions: 3, Metadata: map[string]string{"commit": "abc123"}}
+	if err := exp.Run(context.Background(), measure, opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	reopened, err := Open(store, "tuning")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(reopened.Results) != len(exp.Results) {
+		t.Fatalf("reopened %d results, want %d", len(reopened.Results), len(exp.Results))
+	}
+	for i, r := range reopened.Results {
+		want := exp.Results[i]
+		if r.Trial.ID != want.Trial.ID || r.Trial.Row != want.Trial.Row || r.Sequence != want.Sequence || r.Summary != want.Summary {
+			t.Errorf("result %d: %+v, want %+v", i, r, want)
+		}
+		if r.Trial.Control["A"] != want.Trial.Control["A"] || r.Trial.Noise["N"] != want.Trial.Noise["N"] {
+			t.Errorf("result %d: trial %v %v, want %v %v", i, r.Trial.Control, r.Trial.Noise, want.Trial.Control, want.Trial.Noise)
+		}
+		if len(r.Observations) != 3 || r.Metadata["commit"] != "abc123" {
+			t.Errorf("result %d: observations %v, metadata %v", i, r.Observations, r.Metadata)
+		}
+	}
+	before, _ := exp.Analyze()
+	after, err := reopened.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if !slices.Equal(before.MainEffects["A"], after.MainEffects["A"]) {
+		t.Errorf("main effects of A %v after reopening, want %v", after.MainEffects["A"], before.MainEffects["A"])
+	}
+
+	// Saving again replaces the stored state instead of duplicating it.
+	if err := exp.AttachStore(store.Experiment("tuning")); err != nil {
+		t.Fatalf("AttachStore again: %v", err)
+	}
+	if err := exp.AttachStore(store.Experiment("other")); err != nil {
+		t.Fatalf("AttachStore other: %v", err)
+	}
+	again, err := Open(store, "tuning")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(again.Results) != len(exp.Results) {
+		t.Errorf("%d results after saving twice, want %d", len(again.Results), len(exp.Results))
+	}
+	ids, err := store.IDs()
+	if err != nil || !slices.Equal(ids, []string{"other", "tuning"}) {
+		t.Errorf("IDs = %v, %v; want [other tuning]", ids, err)
+	}
+}
+
+func TestSQLite_Resume(t *testing.T) {
+	store, _ := newMemSQLite(t)
+	sess, err := Resume(store, "bench", testexp.New(t, testexp.Design{}))
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	trials := sess.Pending()
+	for _, trial := range trials[:3] {
+		y, _ := measure(context.Background(), trial)
+		if err := sess.AddResult(trial, []float64{y, y + 1}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+
+	sess, err = Resume(store, "bench", testexp.New(t, testexp.Design{}))
+	if err != nil {
+		t.Fatalf("Resume after restart: %v", err)
+	}
+	if p := sess.Progress(); p != (SessionProgress{Completed: 3, Pending: len(trials) - 3, Results: 3}) {
+		t.Errorf("Progress = %+v, want 3 completed", p)
+	}
+	y, _ := measure(context.Background(), trials[2])
+	if r := sess.Experiment().Results[2]; !slices.Equal(r.Observations, []float64{y, y + 1}) {
+		t.Errorf("restored observations %v, want [%g %g]", r.Observations, y, y+1)
+	}
+	if _, err := Resume(store, "bench", testexp.New(t, testexp.Design{Array: taguchi.L8})); !errors.Is(err, taguchi.ErrDesignMismatch) {
+		t.Errorf("Resume with another design = %v, want ErrDesignMismatch", err)
+	}
+}
+
+func TestSQLite_Errors(t *testing.T) {
+	store, m := newMemSQLite(t)
+	if _, err := Open(store, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open(missing) = %v, want ErrNotFound", err)
+	}
+	if err := store.Experiment("missing").AddResult(taguchi.TrialResult{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AddResult to a missing experiment = %v, want ErrNotFound", err)
+	}
+	if err := testexp.New(t, testexp.Design{}).AttachStore(store.Experiment("")); err == nil {
+		t.Error("AttachStore with an empty ID: want error")
+	}
+
+	exp := testexp.New(t, testexp.Design{})
+	if err := exp.AttachStore(store.Experiment("e")); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	trials := exp.GenerateTrials()
+	if err := exp.AddResult(trials[0], []float64{1}); err != nil {
+		t.Fatalf("AddResult: %v", err)
+	}
+	// A failure writing the observations rolls back the result row, and the
+	// experiment does not record the result.
+	m.fail = "INSERT INTO observations"
+	if err := exp.AddResult(trials[1], []float64{2, 3}); err == nil {
+		t.Fatal("AddResult with a failing store: want error")
+	}
+	if len(exp.Results) != 1 {
+		t.Errorf("experiment holds %d results after the failed write, want 1", len(exp.Results))
+	}
+	m.fail = ""
+	reopened, err := Open(store, "e")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(reopened.Results) != 1 || !slices.Equal(reopened.Results[0].Observations, []float64{1}) {
+		t.Errorf("stored results %+v, want only the first", reopened.Results)
+	}
+
+	m.fail = "FROM observations"
+	if _, err := Open(store, "e"); err == nil {
+		t.Error("Open with a failing query: want error")
+	}
+	m.fail = ""
+	m.tables["experiments"][0][1] = "{"
+	if _, err := Open(store, "e"); err == nil || !strings.Contains(err.Error(), "design") {
+		t.Errorf("Open with a corrupt design = %v, want design error", err)
+	}
+
+	failing := &memDB{tables: map[string][][]driver.Value{}, fail: "CREATE TABLE"}
+	if _, err := NewSQLite(sql.OpenDB(failing)); err == nil {
+		t.Error("NewSQLite with failing schema: want error")
+	}
+}
@@ -0,0 +1,58 @@
+// Package storage persists experiments, their trials and their observations
+// so that long runs survive crashes and experiments can be reopened by ID
+// months later. A Store holds any number of experiments; attach the handle of
+// one of them to an experiment and every recorded result is written through:
+//
+//	db, err := sql.Open("sqlite", "experiments.db") // any SQLite driver
+//	store, err := storage.NewSQLite(db)
+//	err = exp.AttachStore(store.Experiment("cache-tuning"))
+//	err = exp.Run(ctx, measure, opts)
+//	...
+//	exp, err := storage.Open(store, "cache-tuning")
+//
+// The package has no dependencies beyond the standard library: NewSQLite
+// takes a database opened with the SQLite driver of the caller's choice, and
+// Dir keeps experiments in plain files.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// ErrNotFound is returned when loading an experiment ID that was never saved.
+var ErrNotFound = errors.New("experiment not found")
+
+// Store holds experiments by ID.
+type Store interface {
+	// Experiment returns the handle of the experiment with the given ID, to
+	// be attached with Experiment.AttachStore or reopened with Open. The
+	// experiment need not exist yet.
+	Experiment(id string) taguchi.Store
+	// IDs lists the IDs of the saved experiments in ascending order.
+	IDs() ([]string, error)
+	// Close releases the store's resources.
+	Close() error
+}
+
+// Open reopens the experiment saved in s under id, with s attached so that
+// further results are stored as well.
+func Open(s Store, id string) (*taguchi.Experiment[struct{}], error) {
+	exp, err := taguchi.OpenExperiment(s.Experiment(id))
+	if err != nil {
+		return nil, fmt.Errorf("experiment %s: %w", id, err)
+	}
+	return exp, nil
+}
+
+// checkID rejects empty IDs and, since Dir uses IDs as directory names, IDs
+// that are not valid single path elements.
+func checkID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid experiment ID %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package taguchi
+
+import "fmt"
+
+// Store durably persists a single experiment as it is run, e.g. in a
+// database, so that no result is lost in a crash and the experiment can be
+// reopened later with OpenExperiment. The storage package provides stores
+// holding many experiments by ID.
+type Store interface {
+	// SaveExperiment replaces everything stored for the experiment with its
+	// design and results.
+	SaveExperiment(d Design, results []TrialResult) error
+	// AddResult appends a result, with all its raw observations, to the
+	// stored results.
+	AddResult(r TrialResult) error
+	// Load returns the stored design and results in recording order.
+	Load() (Design, []TrialResult, error)
+}
+
+// AttachStore writes the experiment's design and the results recorded so far
+// to s and then writes every further result to s as it is recorded, by
+// AddResult, AppendResult, the runners and the other recording methods. A
+// result that cannot be stored is not recorded either, and the recording
+// method returns the error. Raw observations are stored in full whatever the
+// Retention policy. Settings outside the Design, such as Retention, are not
+// stored.
+func (e *Experiment[P]) AttachStore(s Store) error {
+	results, err := e.portableResults()
+	if err != nil {
+		return err
+	}
+	if err := s.SaveExperiment(e.Design(), results); err != nil {
+		return fmt.Errorf("saving experiment: %w", err)
+	}
+	e.store = s
+	return nil
+}
+
+// RestoreStore loads the results saved in s into an experiment constructed
+// in code, with the same checks as RestoreJSON, and attaches s so that further
+// results are stored as well. Unlike AttachStore it leaves the stored results
+// as they are. It returns the store's error when s holds no experiment yet.
+func (e *Experiment[P]) RestoreStore(s Store) error {
+	d, results, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := e.CheckDesign(d); err != nil {
+		return err
+	}
+	if err := e.checkResults(results); err != nil {
+		return err
+	}
+	e.Results = results
+	e.store = s
+	return nil
+}
+
+// DetachStore stops writing results to the attached store, if any.
+func (e *Experiment[P]) DetachStore() {
+	e.store = nil
+}
+
+// OpenExperiment reopens an experiment saved in s, rebuilding it from the
+// stored design like LoadExperimentJSON, and attaches s so that further
+// results are stored as well. Results that are not trials of the stored
+// design are rejected with ErrDesignMismatch.
+func OpenExperiment(s Store) (*Experiment[struct{}], error) {
+	d, results, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	e, err := d.Experiment()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkResults(results); err != nil {
+		return nil, err
+	}
+	e.Results = results
+	e.store = s
+	return e, nil
+}
@@ -0,0 +1,105 @@
+package taguchi
+
+import (
+	"errors"
+	"testing"
+)
+
+// memoryStore is a Store keeping everything in memory, failing AddResult
+// with err when set.
+type memoryStore struct {
+	design  Design
+	results []TrialResult
+	err     error
+}
+
+func (s *memoryStore) SaveExperiment(d Design, results []TrialResult) error {
+	s.design, s.results = d, append([]TrialResult(nil), results...)
+	return nil
+}
+
+func (s *memoryStore) AddResult(r TrialResult) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.results = append(s.results, r)
+	return nil
+}
+
+func (s *memoryStore) Load() (Design, []TrialResult, error) {
+	return s.design, s.results, nil
+}
+
+func TestAttachStore(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	exp.Retention = RetainSummary
+	store := &memoryStore{}
+	if err := exp.AttachStore(store); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+	trial := exp.GenerateTrials()[0]
+	if err := exp.AppendResult(trial, []float64{1, 2, 3}); err != nil {
+		t.Fatalf("AppendResult: %v", err)
+	}
+	if n := len(store.results); n != len(exp.Results) {
+		t.Fatalf("store holds %d results, want %d", n, len(exp.Results))
+	}
+	if last := store.results[len(store.results)-1]; len(last.Observations) != 3 || last.Sequence != exp.Results[len(exp.Results)-1].Sequence {
+		t.Errorf("stored result %+v, want all observations with the recorded sequence", last)
+	}
+
+	store.err = errors.New("disk full")
+	n := len(exp.Results)
+	if err := exp.AppendResult(trial, []float64{4}); !errors.Is(err, store.err) {
+		t.Errorf("AppendResult with a failing store = %v, want the store's error", err)
+	}
+	if len(exp.Results) != n {
+		t.Errorf("a result that could not be stored was recorded")
+	}
+
+	store.err = nil
+	reopened, err := OpenExperiment(store)
+	if err != nil {
+		t.Fatalf("OpenExperiment: %v", err)
+	}
+	if len(reopened.Results) != n {
+		t.Errorf("reopened %d results, want %d", len(reopened.Results), n)
+	}
+	store.results = append(store.results, TrialResult{Trial: Trial{ID: 99, Row: 7}})
+	if _, err := OpenExperiment(store); !errors.Is(err, ErrDesignMismatch) {
+		t.Errorf("OpenExperiment with a foreign result = %v, want ErrDesignMismatch", err)
+	}
+}
+
+func TestRestoreStore(t *testing.T) {
+	exp := replicatedExperiment(t, "A", "B")
+	store := &memoryStore{}
+	if err := exp.AttachStore(store); err != nil {
+		t.Fatalf("AttachStore: %v", err)
+	}
+
+	restored := replicatedExperiment(t, "A", "B")
+	restored.Results = nil
+	if err := restored.RestoreStore(store); err != nil {
+		t.Fatalf("RestoreStore: %v", err)
+	}
+	if len(restored.Results) != len(exp.Results) {
+		t.Fatalf("restored %d results, want %d", len(restored.Results), len(exp.Results))
+	}
+	n := len(store.results)
+	if err := restored.AppendResult(restored.GenerateTrials()[0], []float64{1}); err != nil {
+		t.Fatalf("AppendResult: %v", err)
+	}
+	if len(store.results) != n+1 {
+		t.Errorf("store holds %d results after AppendResult, want %d", len(store.results), n+1)
+	}
+
+	other := replicatedExperiment(t, "A", "C")
+	other.Results = nil
+	if err := other.RestoreStore(store); !errors.Is(err, ErrDesignMismatch) {
+		t.Errorf("RestoreStore into another design = %v, want ErrDesignMismatch", err)
+	}
+	if len(other.Results) != 0 {
+		t.Errorf("RestoreStore into another design restored %d results", len(other.Results))
+	}
+}
@@ -0,0 +1,377 @@
+package taguchi
+
+import (
+	"maps"
+	"math"
+	"math/rand"
+)
+
+// SuggestCandidates is the number of random configurations, per optimizable
+// factor, scored by expected improvement for each suggestion of SuggestNext.
+const SuggestCandidates = 256
+
+// SuggestNext proposes k new configurations by Bayesian optimization, for
+// refining the optimum beyond the discrete levels of the array. A Gaussian
+// process with a squared-exponential (RBF) kernel is fitted to the SNR of
+// every measured configuration: the array rows and the rows past the array,
+// such as earlier suggestions. Each configuration is then chosen to maximize
+// the expected improvement over the best SNR so far. After each choice the
+// process is refitted with the configuration's predicted SNR, so the k
+// suggestions spread out instead of piling up at one point.
+//
+// Continuous factors (see ControlFactor.Continuous) may take any value
+// between their lowest and highest level; other factors keep to their
+// levels. Observe-only factors are held at their value in the best measured
+// configuration. Every suggestion is crossed with the experiment's noise
+// conditions like AugmentationTrials, with rows and trial IDs continuing after
+// those recorded so far. Record the observations with AddResult and call
+// SuggestNext again for the next round. It returns nil when fewer than two
+// configurations have been measured.
+func (e *Experiment[P]) SuggestNext(k int) []Trial {
+	var dims []int
+	for j, factor := range e.ControlFactors {
+		if !factor.ObserveOnly {
+			dims = append(dims, j)
+		}
+	}
+	if k <= 0 || len(dims) == 0 {
+		return nil
+	}
+	configs, snr := e.measuredConfigurations()
+	if len(configs) < 2 {
+		return nil
+	}
+
+	x := make([][]float64, len(configs))
+	best := 0
+	for i, config := range configs {
+		x[i] = e.unitPoint(dims, config)
+		if snr[i] > snr[best] {
+			best = i
+		}
+	}
+	gp := fitGaussianProcess(x, snr)
+	rng := rand.New(rand.NewSource(int64(len(configs))))
+
+	row := len(e.OrthogonalArray)
+	for _, r := range e.Results {
+		row = max(row, r.Trial.Row+1)
+	}
+	noiseTrials := e.sampledNoise()
+	id := row*len(noiseTrials) + 1
+	var trials []Trial
+	for i := 0; i < k; i++ {
+		u := e.maximizeImprovement(dims, gp, x[best], snr[best], rng)
+		control := maps.Clone(configs[best])
+		for d, j := range dims {
+			control[e.ControlFactors[j].Name] = e.fromUnit(j, u[d])
+		}
+		for _, noiseTrial := range noiseTrials {
+			trials = append(trials, Trial{
+				ID:      id,
+				Row:     row + i,
+				Control: maps.Clone(control),
+				Noise:   noiseTrial.Noise,
+				Key:     TrialKey(control, noiseTrial.Noise),
+			})
+			id++
+		}
+		mean, _ := gp.predict(u)
+		gp = gp.with(u, mean)
+	}
+	return trials
+}
+
+// measuredConfigurations returns the control configuration and SNR of every
+// array row and every row past the array with results. Infinite SNRs are
+// capped at the SNR ceiling.
+func (e *Experiment[P]) measuredConfigurations() ([]map[string]float64, []float64) {
+	var configs []map[string]float64
+	var snr []float64
+	rows := e.computeOASNR()
+	for i := range e.OrthogonalArray {
+		if rows.missing[i] || !rows.included[i] {
+			continue
+		}
+		configs = append(configs, e.levelConfig(e.rowLevels(i)))
+		snr = append(snr, rows.values[i])
+	}
+	extra := map[int][]TrialResult{}
+	var order []int
+	for _, r := range e.Results {
+		if row := r.Trial.Row; row >= len(e.OrthogonalArray) {
+			if extra[row] == nil {
+				order = append(order, row)
+			}
+			extra[row] = append(extra[row], r)
+		}
+	}
+	for _, row := range order {
+		results := extra[row]
+		v := e.resultsSNR(results)
+		if math.IsInf(v, 0) {
+			v = math.Copysign(e.snrCeiling(), v)
+		}
+		configs = append(configs, maps.Clone(results[0].Trial.Control))
+		snr = append(snr, v)
+	}
+	return configs, snr
+}
+
+// levelRange returns the lowest and highest level of a factor.
+func levelRange(factor ControlFactor) (float64, float64) {
+	lo, hi := factor.Levels[0], factor.Levels[0]
+	for _, l := range factor.Levels {
+		lo, hi = math.Min(lo, l), math.Max(hi, l)
+	}
+	return lo, hi
+}
+
+// unitPoint maps the settings of the factors dims onto [0, 1], scaling each
+// factor's range of levels.
+func (e *Experiment[P]) unitPoint(dims []int, config map[string]float64) []float64 {
+	u := make([]float64, len(dims))
+	for d, j := range dims {
+		factor := e.ControlFactors[j]
+		if lo, hi := levelRange(factor); hi > lo {
+			u[d] = (config[factor.Name] - lo) / (hi - lo)
+		}
+	}
+	return u
+}
+
+// fromUnit maps u in [0, 1] back onto the range of factor j, snapping
+// factors that are not continuous to their nearest level.
+func (e *Experiment[P]) fromUnit(j int, u float64) float64 {
+	factor := e.ControlFactors[j]
+	lo, hi := levelRange(factor)
+	v := lo + u*(hi-lo)
+	if factor.Continuous {
+		return v
+	}
+	nearest := factor.Levels[0]
+	for _, l := range factor.Levels {
+		if math.Abs(l-v) < math.Abs(nearest-v) {
+			nearest = l
+		}
+	}
+	return nearest
+}
+
+// maximizeImprovement returns the candidate point with the largest expected
+// improvement over incumbent. Candidates are drawn uniformly from the unit
+// cube and around the best measured point, then snapped to the levels of
+// factors that are not continuous.
+func (e *Experiment[P]) maximizeImprovement(dims []int, gp gaussianProcess, best []float64, incumbent float64, rng *rand.Rand) []float64 {
+	var bestPoint []float64
+	bestEI := math.Inf(-1)
+	n := SuggestCandidates * len(dims)
+	for c := 0; c < n; c++ {
+		u := make([]float64, len(dims))
+		for d := range u {
+			if c%4 == 0 {
+				// A quarter of the candidates refine the region of the best point.
+				u[d] = math.Min(math.Max(best[d]+0.1*rng.NormFloat64(), 0), 1)
+			} else {
+				u[d] = rng.Float64()
+			}
+		}
+		for d, j := range dims {
+			if !e.ControlFactors[j].Continuous {
+				lo, hi := levelRange(e.ControlFactors[j])
+				if hi > lo {
+					u[d] = (e.fromUnit(j, u[d]) - lo) / (hi - lo)
+				}
+			}
+		}
+		mean, sd := gp.predict(u)
+		if ei := expectedImprovement(mean, sd, incumbent); ei > bestEI {
+			bestEI, bestPoint = ei, u
+		}
+	}
+	return bestPoint
+}
+
+// expectedImprovement returns E[max(Y - incumbent, 0)] for Y ~ N(mean, sd²).
+func expectedImprovement(mean, sd, incumbent float64) float64 {
+	if sd <= 0 {
+		return math.Max(mean-incumbent, 0)
+	}
+	z := (mean - incumbent) / sd
+	cdf := 0.5 * math.Erfc(-z/math.Sqrt2)
+	pdf := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+	return (mean-incumbent)*cdf + sd*pdf
+}
+
+// gaussianProcess is a Gaussian process regression of y on points x in the
+// unit cube, with a squared-exponential kernel of the given length scale on
+// the standardized responses and a noise variance relative to the signal.
+type gaussianProcess struct {
+	x      [][]float64
+	y      []float64
+	length float64
+	noise  float64
+	mean   float64
+	scale  float64
+	chol   [][]float64
+	alpha  []float64
+}
+
+// gaussianLengths and gaussianNoises are the hyperparameters searched by
+// fitGaussianProcess.
+var (
+	gaussianLengths = []float64{0.1, 0.2, 0.3, 0.5, 0.8, 1.2, 2}
+	gaussianNoises  = []float64{1e-6, 1e-3, 0.01, 0.1, 0.3}
+)
+
+// fitGaussianProcess fits a Gaussian process to the points, choosing the
+// length scale and noise variance that maximize the marginal likelihood.
+func fitGaussianProcess(x [][]float64, y []float64) gaussianProcess {
+	var best gaussianProcess
+	bestLL := math.Inf(-1)
+	for _, length := range gaussianLengths {
+		for _, noise := range gaussianNoises {
+			gp, ok := newGaussianProcess(x, y, length, noise)
+			if !ok {
+				continue
+			}
+			if ll := gp.logLikelihood(); ll > bestLL {
+				best, bestLL = gp, ll
+			}
+		}
+	}
+	return best
+}
+
+// newGaussianProcess conditions a Gaussian process with fixed hyperparameters
+// on the points. It fails when the kernel matrix is not positive definite.
+func newGaussianProcess(x [][]float64, y []float64, length, noise float64) (gaussianProcess, bool) {
+	gp := gaussianProcess{x: x, y: y, length: length, noise: noise}
+	for _, v := range y {
+		gp.mean += v / float64(len(y))
+	}
+	for _, v := range y {
+		gp.scale += (v - gp.mean) * (v - gp.mean) / float64(len(y))
+	}
+	gp.scale = math.Sqrt(gp.scale)
+	if gp.scale == 0 {
+		gp.scale = 1
+	}
+	n := len(x)
+	k := make([][]float64, n)
+	for i := range k {
+		k[i] = make([]float64, n)
+		for j := range k[i] {
+			k[i][j] = gp.kernel(x[i], x[j])
+		}
+		k[i][i] += noise
+	}
+	chol, ok := cholesky(k)
+	if !ok {
+		return gaussianProcess{}, false
+	}
+	gp.chol = chol
+	z := make([]float64, n)
+	for i, v := range y {
+		z[i] = (v - gp.mean) / gp.scale
+	}
+	gp.alpha = choleskySolve(chol, z)
+	return gp, true
+}
+
+// with returns the process conditioned on one more point, keeping the
+// hyperparameters.
+func (gp gaussianProcess) with(u []float64, y float64) gaussianProcess {
+	x := append(append([][]float64(nil), gp.x...), u)
+	ys := append(append([]float64(nil), gp.y...), y)
+	next, ok := newGaussianProcess(x, ys, gp.length, gp.noise)
+	if !ok {
+		return gp
+	}
+	return next
+}
+
+// kernel returns the squared-exponential covariance of two points.
+func (gp gaussianProcess) kernel(a, b []float64) float64 {
+	d2 := 0.0
+	for i := range a {
+		d2 += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Exp(-d2 / (2 * gp.length * gp.length))
+}
+
+// predict returns the posterior mean and standard deviation of the response
+// at u, excluding the noise variance.
+func (gp gaussianProcess) predict(u []float64) (float64, float64) {
+	ks := make([]float64, len(gp.x))
+	for i, xi := range gp.x {
+		ks[i] = gp.kernel(u, xi)
+	}
+	mean := dot(ks, gp.alpha)
+	v := forwardSubstitute(gp.chol, ks)
+	variance := math.Max(1-dot(v, v), 0)
+	return gp.mean + gp.scale*mean, gp.scale * math.Sqrt(variance)
+}
+
+// logLikelihood returns the log marginal likelihood of the standardized
+// responses, up to a constant.
+func (gp gaussianProcess) logLikelihood() float64 {
+	ll := 0.0
+	for i, v := range gp.y {
+		ll -= 0.5 * (v - gp.mean) / gp.scale * gp.alpha[i]
+		ll -= math.Log(gp.chol[i][i])
+	}
+	return ll
+}
+
+// cholesky returns the lower-triangular L with L·Lᵀ = a, or false when a is
+// not positive definite.
+func cholesky(a [][]float64) ([][]float64, bool) {
+	n := len(a)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				if s <= 0 {
+					return nil, false
+				}
+				l[i][i] = math.Sqrt(s)
+			} else {
+				l[i][j] = s / l[j][j]
+			}
+		}
+	}
+	return l, true
+}
+
+// forwardSubstitute solves L·v = b for lower-triangular L.
+func forwardSubstitute(l [][]float64, b []float64) []float64 {
+	v := make([]float64, len(b))
+	for i := range b {
+		s := b[i]
+		for k := 0; k < i; k++ {
+			s -= l[i][k] * v[k]
+		}
+		v[i] = s / l[i][i]
+	}
+	return v
+}
+
+// choleskySolve solves L·Lᵀ·x = b.
+func choleskySolve(l [][]float64, b []float64) []float64 {
+	v := forwardSubstitute(l, b)
+	x := make([]float64, len(v))
+	for i := len(v) - 1; i >= 0; i-- {
+		s := v[i]
+		for k := i + 1; k < len(v); k++ {
+			s -= l[k][i] * x[k]
+		}
+		x[i] = s / l[i][i]
+	}
+	return x
+}
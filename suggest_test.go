@@ -0,0 +1,93 @@
+package taguchi
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestSuggestNext_RefinesBetweenLevels runs a few rounds of suggestions on a
+// response peaking between the tested levels of a continuous factor.
+func TestSuggestNext_RefinesBetweenLevels(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "Temp", Levels: []float64{0, 5, 10}, Continuous: true},
+		{Name: "Mode", Levels: []float64{1, 2, 3}},
+	}
+	oa, err := FullFactorial(factors)
+	if err != nil {
+		t.Fatalf("FullFactorial: %v", err)
+	}
+	noise := []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}}
+	exp, err := NewExperimentFromFactorsUsingArray(LargerTheBetter{}, factors, oa, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	response := func(trial Trial) float64 {
+		d := trial.Control["Temp"] - 3
+		return 40 - d*d/4 + trial.Control["Mode"] + trial.Noise["N"]/10
+	}
+	for _, trial := range exp.GenerateTrials() {
+		if err := exp.AddResult(trial, []float64{response(trial)}); err != nil {
+			t.Fatalf("AddResult: %v", err)
+		}
+	}
+
+	if got := (&Experiment[struct{}]{}).SuggestNext(1); got != nil {
+		t.Errorf("SuggestNext without results = %v, want nil", got)
+	}
+	nextRow, nextID := len(oa), len(oa)*2+1
+	for round := 0; round < 4; round++ {
+		trials := exp.SuggestNext(2)
+		if len(trials) != 4 {
+			t.Fatalf("round %d: got %d trials, want 2 configurations × 2 noise conditions", round, len(trials))
+		}
+		for _, trial := range trials {
+			if trial.Row != nextRow+(trial.ID-nextID)/2 {
+				t.Errorf("trial %d: row %d does not continue the numbering", trial.ID, trial.Row)
+			}
+			if !slices.Contains(factors[1].Levels, trial.Control["Mode"]) {
+				t.Errorf("trial %d: Mode %g is not one of its levels", trial.ID, trial.Control["Mode"])
+			}
+			if err := exp.AddResult(trial, []float64{response(trial)}); err != nil {
+				t.Fatalf("round %d: AddResult: %v", round, err)
+			}
+		}
+		nextRow, nextID = nextRow+2, nextID+4
+	}
+
+	configs, snr := exp.measuredConfigurations()
+	best := 0
+	for i := range snr {
+		if snr[i] > snr[best] {
+			best = i
+		}
+	}
+	if temp := configs[best]["Temp"]; math.Abs(temp-3) > 1 || configs[best]["Mode"] != 3 {
+		t.Errorf("best configuration %v, want Temp near 3 and Mode 3", configs[best])
+	}
+	// Suggestions past the array leave the analysis of the array untouched.
+	if got := mustAnalyze(t, exp).OptimalLevels; got["Temp"] != 5 || got["Mode"] != 3 {
+		t.Errorf("OptimalLevels = %v, want Temp 5 and Mode 3", got)
+	}
+}
+
+func TestGaussianProcess_Interpolates(t *testing.T) {
+	x := [][]float64{{0}, {0.25}, {0.5}, {0.75}, {1}}
+	y := []float64{0, 1, 0, -1, 0}
+	gp, ok := newGaussianProcess(x, y, 0.2, 1e-6)
+	if !ok {
+		t.Fatal("kernel matrix not positive definite")
+	}
+	for i, xi := range x {
+		mean, sd := gp.predict(xi)
+		if math.Abs(mean-y[i]) > 1e-3 || sd > 1e-2 {
+			t.Errorf("predict(%v) = %.4f ± %.4f, want %g ± 0", xi, mean, sd, y[i])
+		}
+	}
+	if _, sd := gp.predict([]float64{3}); sd < 0.5 {
+		t.Errorf("standard deviation far from the data = %.3f, want close to the prior", sd)
+	}
+	if ei := expectedImprovement(1, 1, 1); !almostEqual(ei, 1/math.Sqrt(2*math.Pi)) {
+		t.Errorf("expectedImprovement at the incumbent = %.4f, want φ(0)", ei)
+	}
+}
@@ -1,60 +1,242 @@
 package taguchi
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ReportOptions controls how analysis reports are rendered.
+// Numbers: Formatting applied to every numeric value in the report (SNR, SS, F-ratios, percentages).
+type ReportOptions struct {
+	Numbers NumberFormat
+}
+
+// DefaultReportOptions returns the options used by PrintAnalysisReport.
+func DefaultReportOptions() ReportOptions {
+	return ReportOptions{Numbers: DefaultNumberFormat}
+}
 
 // PrintAnalysisReport prints a detailed, human-readable Taguchi analysis report.
 func PrintAnalysisReport(result AnalysisResult) {
-	fmt.Println("========================================")
-	fmt.Println("        TAGUCHI ANALYSIS REPORT")
-	fmt.Println("========================================")
+	_ = WriteAnalysisReport(os.Stdout, result, DefaultReportOptions())
+}
+
+// WriteAnalysisReport writes a detailed, human-readable Taguchi analysis report
+// to w, formatting all numbers consistently according to opts.
+func WriteAnalysisReport(w io.Writer, result AnalysisResult, opts ReportOptions) error {
+	nf := opts.Numbers
+	var b strings.Builder
+
+	fmt.Fprintln(&b, "========================================")
+	fmt.Fprintln(&b, "        TAGUCHI ANALYSIS REPORT")
+	fmt.Fprintln(&b, "========================================")
 
 	// 1. Optimal Factor Levels
-	fmt.Println("1. Optimal Factor Levels")
-	fmt.Println("------------------------")
-	fmt.Println("These are the factor levels that maximize the performance metric (SNR):")
-	for factor, level := range result.OptimalLevels {
-		fmt.Printf("  - %s: %v\n", factor, level)
+	fmt.Fprintln(&b, "1. Optimal Factor Levels")
+	fmt.Fprintln(&b, "------------------------")
+	fmt.Fprintln(&b, "These are the factor levels that maximize the performance metric (SNR):")
+	for _, factor := range sortedKeys(result.OptimalLevels) {
+		fmt.Fprintf(&b, "  - %s: %s\n", factor, nf.FormatLevel(result.OptimalLevels[factor]))
+	}
+	if len(result.ObservedFactors) > 0 {
+		fmt.Fprintf(&b, "  Observe-only (not optimized): %s\n", strings.Join(result.ObservedFactors, ", "))
+	}
+	for _, opt := range result.Interpolated {
+		note := "confirm before adopting"
+		if opt.Caution {
+			note = "curvature not significant, treat with caution"
+		}
+		fmt.Fprintf(&b, "  Interpolated %s: %s (between tested levels; %s)\n", opt.Factor, nf.FormatLevel(opt.Level), note)
 	}
-	fmt.Println()
+	fmt.Fprintln(&b)
 
 	// 2. Main Effects
-	fmt.Println("2. Main Effects (Average SNR per Factor Level)")
-	fmt.Println("-----------------------------------------------")
-	fmt.Println("This shows how each factor level affects the response variable.")
-	for factor, effects := range result.MainEffects {
-		fmt.Printf("  %s:\n", factor)
-		for i, val := range effects {
-			fmt.Printf("    Level %d: %.4f\n", i+1, val)
+	fmt.Fprintln(&b, "2. Main Effects (Average SNR per Factor Level)")
+	fmt.Fprintln(&b, "-----------------------------------------------")
+	fmt.Fprintln(&b, "This shows how each factor level affects the response variable.")
+	for _, factor := range sortedKeys(result.MainEffects) {
+		fmt.Fprintf(&b, "  %s:\n", factor)
+		for i, val := range result.MainEffects[factor] {
+			fmt.Fprintf(&b, "    Level %d: %s\n", i+1, nf.Format(val))
+		}
+		fmt.Fprintln(&b, "    => Higher values indicate a better effect on performance.")
+	}
+	for _, in := range result.Interactions {
+		fmt.Fprintf(&b, "  %s (average SNR per level combination):\n", InteractionName(in.A, in.B))
+		for x, row := range in.CellMeans {
+			cells := make([]string, len(row))
+			for y, v := range row {
+				cells[y] = fmt.Sprintf("%s%d=%s", in.B, y+1, nf.Format(v))
+			}
+			fmt.Fprintf(&b, "    %s%d: %s\n", in.A, x+1, strings.Join(cells, "  "))
 		}
-		fmt.Println("    => Higher values indicate a better effect on performance.")
+		fmt.Fprintln(&b, "    => Differences that change across levels indicate an interaction.")
+	}
+	if len(result.Stability) > 0 {
+		fmt.Fprintln(&b, "  Ranking stability across noise conditions (Kendall's W):")
+		for _, fs := range result.Stability {
+			note := ""
+			if fs.Flips {
+				note = "  (best level flips)"
+			}
+			fmt.Fprintf(&b, "    %s: %s%s\n", fs.Factor, nf.Format(fs.KendallW), note)
+		}
+		fmt.Fprintln(&b, "    => Values near 1 mean the level ranking holds in every environment.")
 	}
 
 	// 3. Contributions of Each Factor
-	fmt.Println("3. Contribution of Each Factor")
-	fmt.Println("-------------------------------")
-	fmt.Println("This tells us how much each factor contributes to the total variation:")
-	for factor, contrib := range result.Contributions {
-		fmt.Printf("  - %s: %.2f%%\n", factor, contrib)
+	fmt.Fprintln(&b, "3. Contribution of Each Factor")
+	fmt.Fprintln(&b, "-------------------------------")
+	fmt.Fprintln(&b, "This tells us how much each factor contributes to the total variation:")
+	for _, factor := range sortedKeys(result.Contributions) {
+		fmt.Fprintf(&b, "  - %s: %s\n", factor, nf.FormatPercent(result.Contributions[factor]))
+	}
+	fmt.Fprintf(&b, "  - Error: %s\n", nf.FormatPercent(result.ErrorContribution))
+	fmt.Fprintln(&b, "  => Factors with higher percentages are more influential.")
+	if len(result.GroupContributions) > 0 {
+		fmt.Fprintln(&b, "  By factor group:")
+		for _, group := range sortedKeys(result.GroupContributions) {
+			fmt.Fprintf(&b, "  - %s: %s (%s)\n", group,
+				nf.FormatPercent(result.GroupContributions[group]),
+				strings.Join(result.Groups[group], ", "))
+		}
+	}
+	if len(result.CostBenefit) > 0 {
+		fmt.Fprintln(&b, "  Per unit of change cost, best first:")
+		for _, cb := range result.CostBenefit {
+			mark := ""
+			if cb.Significant {
+				mark = " *"
+			}
+			fmt.Fprintf(&b, "  - %s: %s (%s at cost %s)%s\n", cb.Factor,
+				nf.FormatPercent(cb.PerUnitCost), nf.FormatPercent(cb.Contribution), nf.Format(cb.Cost), mark)
+		}
 	}
-	fmt.Println("  => Factors with higher percentages are more influential.")
 
 	// 4. ANOVA Results
-	fmt.Println("4. ANOVA (Analysis of Variance) Table")
-	fmt.Println("------------------------------------")
-	fmt.Println("ANOVA helps determine which factors significantly affect the response.")
-	fmt.Printf("%-15s %-12s %-8s %-10s\n", "Factor", "SS", "DF", "F-ratio")
-	for factor := range result.ANOVA.FactorSS {
-		fmt.Printf("%-15s %-12.4f %-8d %-10.4f\n",
+	fmt.Fprintln(&b, "4. ANOVA (Analysis of Variance) Table")
+	fmt.Fprintln(&b, "------------------------------------")
+	fmt.Fprintln(&b, "ANOVA helps determine which factors significantly affect the response.")
+	fmt.Fprintf(&b, "%-15s %-12s %-8s %-10s %-10s\n", "Factor", "SS", "DF", "F-ratio", "p-value")
+	for _, factor := range sortedKeys(result.ANOVA.FactorSS) {
+		mark := ""
+		if result.ANOVA.Significant[factor] {
+			mark = " *"
+		}
+		fmt.Fprintf(&b, "%-15s %-12s %-8d %-10s %-10s\n",
 			factor,
-			result.ANOVA.FactorSS[factor],
+			nf.Format(result.ANOVA.FactorSS[factor]),
 			result.ANOVA.FactorDF[factor],
-			result.ANOVA.FactorF[factor],
+			nf.Format(result.ANOVA.FactorF[factor]),
+			nf.Format(result.ANOVA.FactorP[factor])+mark,
 		)
 	}
-	fmt.Printf("%-15s %-12.4f %-8d\n",
+	fmt.Fprintf(&b, "%-15s %-12s %-8d\n",
 		"Error",
-		result.ANOVA.ErrorSS,
+		nf.Format(result.ANOVA.ErrorSS),
 		result.ANOVA.ErrorDF,
 	)
-	fmt.Println("  => Factors with higher F-ratio are more statistically significant.")
+	if a := result.ANOVA; a.ColumnErrorDF > 0 {
+		fmt.Fprintf(&b, "%-15s %-12s %-8d\n", "  Error columns", nf.Format(a.ColumnErrorSS), a.ColumnErrorDF)
+	}
+	if a := result.ANOVA; a.PureErrorDF > 0 {
+		fmt.Fprintf(&b, "%-15s %-12s %-8d\n", "  Pure error", nf.Format(a.PureErrorSS), a.PureErrorDF)
+		if a.LackOfFitF > 0 {
+			fmt.Fprintf(&b, "  => Lack of fit against pure error: F = %s, p = %s.\n", nf.Format(a.LackOfFitF), nf.Format(a.LackOfFitP))
+		}
+	}
+	if a := result.ANOVA; a.UnassignedDF > 0 {
+		fmt.Fprintf(&b, "%-15s %-12s %-8d\n", "Unassigned", nf.Format(a.UnassignedSS), a.UnassignedDF)
+	}
+	fmt.Fprintln(&b, "  => Factors with higher F-ratio are more statistically significant.")
+	if result.ANOVA.Alpha > 0 {
+		fmt.Fprintf(&b, "  => * marks factors significant at alpha = %s.\n", nf.Format(result.ANOVA.Alpha))
+	}
+	if len(result.Comparisons) > 0 {
+		fmt.Fprintln(&b, "Pairwise level comparisons (* significant):")
+		for _, c := range result.Comparisons {
+			mark := ""
+			if c.Significant {
+				mark = " *"
+			}
+			fmt.Fprintf(&b, "  - %s %s vs %s: %s dB (critical %s, p = %s)%s\n", c.Factor,
+				nf.FormatLevel(c.LevelA), nf.FormatLevel(c.LevelB), nf.Format(c.Diff), nf.Format(c.CriticalDiff), nf.Format(c.P), mark)
+		}
+	}
+
+	section := 5
+	if ma := result.MeanAnalysis; ma != nil {
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "%d. Analysis of Means\n", section)
+		fmt.Fprintln(&b, "----------------------")
+		fmt.Fprintln(&b, "Main effects and ANOVA of the raw row means (location) next to the SNR (dispersion).")
+		for _, factor := range sortedKeys(ma.MainEffects) {
+			cells := make([]string, len(ma.MainEffects[factor]))
+			for i, v := range ma.MainEffects[factor] {
+				cells[i] = fmt.Sprintf("L%d=%s", i+1, nf.Format(v))
+			}
+			mark := ""
+			if ma.ANOVA.Significant[factor] {
+				mark = " *"
+			}
+			fmt.Fprintf(&b, "  %s: %s  (F=%s, p=%s%s)\n", factor, strings.Join(cells, "  "),
+				nf.Format(ma.ANOVA.FactorF[factor]), nf.Format(ma.ANOVA.FactorP[factor]), mark)
+		}
+		if len(ma.DispersionFactors) > 0 {
+			fmt.Fprintf(&b, "  => Dispersion factors (set for the best SNR): %s\n", strings.Join(ma.DispersionFactors, ", "))
+		}
+		if len(ma.LocationFactors) > 0 {
+			fmt.Fprintf(&b, "  => Location factors (adjust the mean): %s\n", strings.Join(ma.LocationFactors, ", "))
+		}
+		section++
+	}
+
+	if len(result.Exclusions) > 0 || len(result.Where) > 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "%d. Excluded Data\n", section)
+		fmt.Fprintln(&b, "----------------")
+		for _, x := range result.Exclusions {
+			fmt.Fprintf(&b, "  - %s\n", x)
+		}
+		if len(result.Where) > 0 {
+			fmt.Fprintf(&b, "  - results without metadata %s\n", formatMetadata(result.Where))
+		}
+		section++
+	}
+
+	if len(result.Outliers) > 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "%d. Outlier Observations\n", section)
+		fmt.Fprintln(&b, "-----------------------")
+		for _, o := range result.Outliers {
+			fmt.Fprintf(&b, "  - %s\n", o)
+		}
+		section++
+	}
+
+	// Warnings
+	if len(result.Warnings) > 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "%d. Warnings\n", section)
+		fmt.Fprintln(&b, "-----------")
+		for _, w := range result.Warnings {
+			fmt.Fprintf(&b, "  - %s\n", w)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sortedKeys returns the keys of m in ascending order so that reports are deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
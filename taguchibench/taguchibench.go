@@ -0,0 +1,110 @@
+// Package taguchibench runs Taguchi experiments as Go benchmarks, so code
+// parameters such as GOMAXPROCS or buffer sizes can be tuned inside
+// go test -bench . -v:
+//
+//	func BenchmarkEncode(b *testing.B) {
+//		exp, _ := taguchi.NewExperimentFromFactors(taguchi.SmallerTheBetter{}, factors, taguchi.L9, nil)
+//		taguchibench.RunBenchmark(b, exp, func(b *testing.B, trial taguchi.Trial) {
+//			buf := make([]byte, int(trial.Control["Buffer"]))
+//			for i := 0; i < b.N; i++ {
+//				encode(buf)
+//			}
+//		})
+//	}
+package taguchibench
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// TrialFunc runs b.N iterations of the code under test configured for trial.
+// It may call b.StopTimer and b.StartTimer around setup, as in any benchmark.
+type TrialFunc func(b *testing.B, trial taguchi.Trial)
+
+// Options configures RunBenchmark.
+// Repetitions: Sub-benchmarks per trial, each recorded as one observation (1 when zero).
+type Options struct {
+	Repetitions int
+}
+
+// RunBenchmark runs every trial of exp as a sub-benchmark of b, named after
+// its control and noise levels, e.g. "Workers=4,Buffer=1024". The time per
+// iteration of each sub-benchmark, in nanoseconds, is recorded as an
+// observation of its trial, so goals such as SmallerTheBetter apply directly.
+// Trials are run in the experiment's RunOrder and recorded with
+// AppendResult, so running the benchmark again with -count adds replicates
+// to an experiment kept across runs.
+//
+// The analysis is returned and written to the benchmark log, which go test
+// shows for benchmarks with sub-benchmarks only with -v. When trials
+// were skipped, e.g. because -bench selected only some sub-benchmarks, the
+// analysis is skipped and a zero AnalysisResult is returned unless the
+// experiment allows incomplete designs. Other failures stop the benchmark
+// with b.Fatal.
+func RunBenchmark[P any](b *testing.B, exp *taguchi.Experiment[P], trial TrialFunc, opts ...Options) taguchi.AnalysisResult {
+	b.Helper()
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	for _, t := range exp.GenerateTrials() {
+		name := Name(exp, t)
+		for rep := 0; rep < max(o.Repetitions, 1); rep++ {
+			var nsPerOp float64
+			measured := false
+			b.Run(name, func(b *testing.B) {
+				trial(b, t)
+				// The sub-benchmark runs with growing b.N; the last run,
+				// with the largest b.N, is the one reported.
+				nsPerOp = float64(b.Elapsed().Nanoseconds()) / float64(b.N)
+				measured = true
+			})
+			if !measured {
+				continue
+			}
+			if err := exp.AppendResult(t, []float64{nsPerOp}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	result, err := exp.Analyze()
+	var missing *taguchi.MissingResultsError
+	if errors.As(err, &missing) {
+		b.Logf("analysis skipped: %v", err)
+		return taguchi.AnalysisResult{}
+	}
+	if err != nil {
+		b.Fatal(err)
+	}
+	var report strings.Builder
+	if err := taguchi.WriteAnalysisReport(&report, result, taguchi.DefaultReportOptions()); err != nil {
+		b.Fatal(err)
+	}
+	b.Log("\n" + report.String())
+	return result
+}
+
+// Name returns the sub-benchmark name RunBenchmark gives trial: its control
+// levels in factor order followed by its noise levels in name order.
+func Name[P any](exp *taguchi.Experiment[P], trial taguchi.Trial) string {
+	var parts []string
+	for _, f := range exp.ControlFactors {
+		parts = append(parts, fmt.Sprintf("%s=%g", f.Name, trial.Control[f.Name]))
+	}
+	noise := make([]string, 0, len(trial.Noise))
+	for name := range trial.Noise {
+		noise = append(noise, name)
+	}
+	sort.Strings(noise)
+	for _, name := range noise {
+		parts = append(parts, fmt.Sprintf("%s=%g", name, trial.Noise[name]))
+	}
+	return strings.Join(parts, ",")
+}
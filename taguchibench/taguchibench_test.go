@@ -0,0 +1,61 @@
+package taguchibench
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+var sink int
+
+// spin does work proportional to n.
+func spin(n int) {
+	for i := 0; i < n; i++ {
+		sink += i ^ sink
+	}
+}
+
+// TestRunBenchmark verifies that every trial becomes a sub-benchmark whose
+// time per iteration is recorded, and that the analysis finds the cheaper
+// level of the factor that drives the cost.
+func TestRunBenchmark(t *testing.T) {
+	benchtime := flag.Lookup("test.benchtime")
+	prev := benchtime.Value.String()
+	if err := benchtime.Value.Set("50x"); err != nil {
+		t.Fatal(err)
+	}
+	defer benchtime.Value.Set(prev)
+
+	factors := []taguchi.ControlFactor{
+		{Name: "Work", Levels: []float64{20000, 200000}},
+		{Name: "Idle", Levels: []float64{1, 2}},
+	}
+	exp, err := taguchi.NewExperimentFromFactors(taguchi.SmallerTheBetter{}, factors, taguchi.L4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result taguchi.AnalysisResult
+	testing.Benchmark(func(b *testing.B) {
+		result = RunBenchmark(b, exp, func(b *testing.B, trial taguchi.Trial) {
+			for i := 0; i < b.N; i++ {
+				spin(int(trial.Control["Work"]))
+			}
+		}, Options{Repetitions: 2})
+	})
+
+	if len(exp.Results) != 8 {
+		t.Fatalf("recorded %d results, want 8 (4 trials, 2 repetitions)", len(exp.Results))
+	}
+	for _, r := range exp.Results {
+		if r.Observations[0] <= 0 {
+			t.Errorf("trial %d: observation %g, want a positive ns/op", r.Trial.ID, r.Observations[0])
+		}
+	}
+	if got := Name(exp, exp.Results[0].Trial); got != "Work=20000,Idle=1" {
+		t.Errorf("Name = %q, want Work=20000,Idle=1", got)
+	}
+	if result.OptimalLevels["Work"] != 20000 {
+		t.Errorf("optimal Work = %g, want 20000", result.OptimalLevels["Work"])
+	}
+}
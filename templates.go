@@ -0,0 +1,118 @@
+package taguchi
+
+// Template is a pre-defined experiment for a common tuning scenario. Its
+// factors carry sensible default levels that can be overridden per user.
+// Name: Short identifier of the scenario.
+// Description: What the template tunes and which response it expects.
+// Goal: Optimization goal for the expected response.
+// Array: Standard orthogonal array sized for the template's factors, leaving columns free for the error term.
+// ControlFactors: Default control factors and levels.
+// NoiseFactors: Default noise factors and levels.
+type Template struct {
+	Name           string
+	Description    string
+	Goal           OptimizationGoal
+	Array          ArrayType
+	ControlFactors []ControlFactor
+	NoiseFactors   []NoiseFactor
+}
+
+// New instantiates the template as an experiment. levels replaces the default
+// levels of the named control or noise factors; naming an unknown factor is an error.
+func (t Template) New(levels map[string][]float64) (*Experiment[struct{}], error) {
+	control := make([]ControlFactor, len(t.ControlFactors))
+	copy(control, t.ControlFactors)
+	noise := make([]NoiseFactor, len(t.NoiseFactors))
+	copy(noise, t.NoiseFactors)
+
+	for name, lv := range levels {
+		found := false
+		for i := range control {
+			if control[i].Name == name {
+				control[i].Levels, found = lv, true
+			}
+		}
+		for i := range noise {
+			if noise[i].Name == name {
+				noise[i].Levels, found = lv, true
+			}
+		}
+		if !found {
+			return nil, errorf(ErrUnknownFactor, "template %s has no factor %s", t.Name, name)
+		}
+	}
+	return NewExperimentFromFactors(t.Goal, control, t.Array, noise)
+}
+
+// Templates returns all built-in templates.
+func Templates() []Template {
+	return []Template{
+		HTTPLatencyTemplate(),
+		GCTuningTemplate(),
+		DBPoolTemplate(),
+	}
+}
+
+// HTTPLatencyTemplate tunes an HTTP service for request latency under varying
+// client concurrency and payload size. Its four three-level factors would
+// saturate an L9, so it uses an L18.
+func HTTPLatencyTemplate() Template {
+	return Template{
+		Name:        "http-latency",
+		Description: "HTTP service latency tuning; record request latency in milliseconds.",
+		Goal:        SmallerTheBetter{},
+		Array:       L18,
+		ControlFactors: []ControlFactor{
+			{Name: "MaxConnsPerHost", Levels: []float64{16, 64, 256}, Group: "client"},
+			{Name: "IdleTimeoutSeconds", Levels: []float64{30, 90, 300}, Group: "client"},
+			{Name: "ReadBufferKB", Levels: []float64{4, 16, 64}, Group: "server"},
+			{Name: "WorkerPoolSize", Levels: []float64{8, 32, 128}, Group: "server"},
+		},
+		NoiseFactors: []NoiseFactor{
+			{Name: "Concurrency", Levels: []float64{10, 100}},
+			{Name: "PayloadKB", Levels: []float64{1, 64}},
+		},
+	}
+}
+
+// GCTuningTemplate tunes the Go garbage collector and scheduler for pause or
+// latency impact under different allocation rates.
+func GCTuningTemplate() Template {
+	return Template{
+		Name:        "gc-tuning",
+		Description: "Go GC tuning; record p99 latency or total GC pause time.",
+		Goal:        SmallerTheBetter{},
+		Array:       L9,
+		ControlFactors: []ControlFactor{
+			{Name: "GOGC", Levels: []float64{50, 100, 200}},
+			{Name: "GOMEMLIMITMiB", Levels: []float64{512, 1024, 2048}},
+			{Name: "GOMAXPROCS", Levels: []float64{2, 4, 8}},
+		},
+		NoiseFactors: []NoiseFactor{
+			{Name: "AllocRateMiBps", Levels: []float64{50, 500}},
+			{Name: "LiveHeapMiB", Levels: []float64{64, 256}},
+		},
+	}
+}
+
+// DBPoolTemplate tunes a database/sql connection pool for query latency under
+// different client counts and read/write mixes. Like HTTPLatencyTemplate it
+// uses an L18 for its four three-level factors.
+func DBPoolTemplate() Template {
+	return Template{
+		Name:        "db-pool",
+		Description: "Database connection pool tuning; record query latency in milliseconds.",
+		Goal:        SmallerTheBetter{},
+		Array:       L18,
+		ControlFactors: []ControlFactor{
+			{Name: "MaxOpenConns", Levels: []float64{10, 25, 50}},
+			{Name: "MaxIdleConns", Levels: []float64{2, 10, 25}},
+			{Name: "ConnMaxLifetimeSeconds", Levels: []float64{60, 300, 1800}},
+			{Name: "ConnMaxIdleTimeSeconds", Levels: []float64{30, 120, 600}},
+		},
+		NoiseFactors: []NoiseFactor{
+			{Name: "ConcurrentClients", Levels: []float64{8, 64}},
+			{Name: "WriteFraction", Levels: []float64{0.1, 0.5}},
+		},
+	}
+}
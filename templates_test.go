@@ -0,0 +1,73 @@
+package taguchi
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestTemplates_Analyze verifies that every template leaves error degrees of
+// freedom and can be run and analyzed end to end, finding the levels that
+// minimize a synthetic response.
+func TestTemplates_Analyze(t *testing.T) {
+	for _, tmpl := range Templates() {
+		exp, err := tmpl.New(nil)
+		if err != nil {
+			t.Fatalf("%s: New: %v", tmpl.Name, err)
+		}
+		// Each factor adds its level index times its weight; the noise
+		// factors scale the response.
+		weights := map[string]float64{}
+		for i, f := range tmpl.ControlFactors {
+			weights[f.Name] = float64(len(tmpl.ControlFactors) - i)
+		}
+		for _, trial := range exp.GenerateTrials() {
+			y := 10.0
+			for j, f := range tmpl.ControlFactors {
+				for k, level := range f.Levels {
+					if trial.Control[f.Name] == level {
+						y += weights[f.Name] * float64(k) * float64(j+1)
+					}
+				}
+			}
+			for _, n := range tmpl.NoiseFactors {
+				if trial.Noise[n.Name] == n.Levels[1] {
+					y *= 1.2
+				}
+			}
+			if err := exp.AddResult(trial, []float64{y, 1.05 * y}); err != nil {
+				t.Fatalf("%s: AddResult: %v", tmpl.Name, err)
+			}
+		}
+
+		result := mustAnalyze(t, exp)
+		if result.ANOVA.ErrorDF < 1 || result.HasWarning(WarnSaturatedDesign) {
+			t.Errorf("%s: error DF %d on %s, want free columns for the error term", tmpl.Name, result.ANOVA.ErrorDF, tmpl.Array)
+		}
+		for _, f := range tmpl.ControlFactors {
+			if got := result.OptimalLevels[f.Name]; got != f.Levels[0] {
+				t.Errorf("%s: optimal %s = %g, want %g", tmpl.Name, f.Name, got, f.Levels[0])
+			}
+			if _, ok := result.ANOVA.FactorP[f.Name]; !ok {
+				t.Errorf("%s: no p-value for %s", tmpl.Name, f.Name)
+			}
+		}
+	}
+}
+
+// TestTemplate_NewOverridesLevels verifies that New replaces the levels of
+// named factors and rejects unknown names.
+func TestTemplate_NewOverridesLevels(t *testing.T) {
+	exp, err := GCTuningTemplate().New(map[string][]float64{"GOGC": {25, 100, 400}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := exp.ControlFactors[0].Levels; len(got) != 3 || got[0] != 25 || got[2] != 400 {
+		t.Errorf("GOGC levels = %v, want [25 100 400]", got)
+	}
+	if GCTuningTemplate().ControlFactors[0].Levels[0] != 50 {
+		t.Error("New modified the template's default levels")
+	}
+	if _, err := GCTuningTemplate().New(map[string][]float64{"Missing": {1, 2}}); !errors.Is(err, ErrUnknownFactor) {
+		t.Errorf("unknown factor: got %v, want ErrUnknownFactor", err)
+	}
+}
@@ -0,0 +1,155 @@
+package taguchi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Throttle limits when and how fast trials are run, so experiments can run
+// unattended against infrastructure shared with other teams, such as a
+// staging cluster. Set it as RunOptions.Throttle; one Throttle may be shared
+// by the runners of several experiments in a process to limit their combined
+// load. The limits apply to trial starts: a trial started near the end of a
+// window runs to completion.
+// TrialsPerMinute: Trials started per minute at most, spaced evenly; zero means no limit.
+// MaxConcurrent: Trials measured at the same time at most, over every runner sharing the Throttle; zero means no limit.
+// Windows: Times of day in which trials may start, in the local time zone; none means any time.
+type Throttle struct {
+	TrialsPerMinute float64
+	MaxConcurrent   int
+	Windows         []Window
+
+	mu    sync.Mutex
+	next  time.Time     // earliest start of the next trial
+	slots chan struct{} // one element per running trial, created on first use
+}
+
+// Window is a daily span of time. Start and End are offsets from midnight;
+// a window whose End is not after its Start spans midnight, e.g. 22:00–06:00,
+// and one whose End equals its Start spans the whole day.
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseWindow parses a window written as "HH:MM-HH:MM", e.g. "22:00-06:00".
+// An en dash may separate the times.
+func ParseWindow(s string) (Window, error) {
+	start, end, ok := strings.Cut(strings.ReplaceAll(s, "–", "-"), "-")
+	if !ok {
+		return Window{}, fmt.Errorf("window %q: want HH:MM-HH:MM", s)
+	}
+	var w Window
+	for _, p := range []struct {
+		text string
+		into *time.Duration
+	}{{start, &w.Start}, {end, &w.End}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(p.text))
+		if err != nil {
+			return Window{}, fmt.Errorf("window %q: want HH:MM-HH:MM", s)
+		}
+		*p.into = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return w, nil
+}
+
+func (w Window) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+// contains reports whether t falls inside the window.
+func (w Window) contains(t time.Time) bool {
+	off := sinceMidnight(t)
+	switch {
+	case w.Start < w.End:
+		return off >= w.Start && off < w.End
+	case w.Start > w.End:
+		return off >= w.Start || off < w.End
+	}
+	return true
+}
+
+// opening returns the first time the window opens after t.
+func (w Window) opening(t time.Time) time.Time {
+	y, m, d := t.Date()
+	open := time.Date(y, m, d, 0, 0, 0, 0, t.Location()).Add(w.Start)
+	if !open.After(t) {
+		open = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location()).Add(w.Start)
+	}
+	return open
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// earliest returns the first time at or after t inside one of the windows.
+func (th *Throttle) earliest(t time.Time) time.Time {
+	if len(th.Windows) == 0 {
+		return t
+	}
+	var first time.Time
+	for _, w := range th.Windows {
+		if w.contains(t) {
+			return t
+		}
+		if open := w.opening(t); first.IsZero() || open.Before(first) {
+			first = open
+		}
+	}
+	return first
+}
+
+// acquire waits until a trial may start and returns the function that
+// releases its concurrency slot once it is done. A nil Throttle never waits.
+func (th *Throttle) acquire(ctx context.Context) (func(), error) {
+	if th == nil {
+		return func() {}, nil
+	}
+	release := func() {}
+	if th.MaxConcurrent > 0 {
+		th.mu.Lock()
+		if th.slots == nil {
+			th.slots = make(chan struct{}, th.MaxConcurrent)
+		}
+		slots := th.slots
+		th.mu.Unlock()
+		select {
+		case slots <- struct{}{}:
+			release = func() { <-slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	now := time.Now()
+	if err := sleepContext(ctx, th.reserve(now).Sub(now)); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// reserve returns the start time of a trial that is ready at now: the first
+// time inside a window that keeps TrialsPerMinute. The time is reserved so
+// concurrent trials are spaced apart; a reservation abandoned by
+// cancellation is not handed back.
+func (th *Throttle) reserve(now time.Time) time.Time {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	start := th.earliest(now)
+	if th.TrialsPerMinute > 0 {
+		if start.Before(th.next) {
+			start = th.earliest(th.next)
+		}
+		th.next = start.Add(time.Duration(float64(time.Minute) / th.TrialsPerMinute))
+	}
+	return start
+}
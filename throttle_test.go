@@ -0,0 +1,105 @@
+package taguchi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	for _, s := range []string{"22:00-06:00", "22:00–06:00", " 22:00 - 06:00 "} {
+		w, err := ParseWindow(s)
+		if err != nil {
+			t.Fatalf("ParseWindow(%q): %v", s, err)
+		}
+		if w != (Window{Start: 22 * time.Hour, End: 6 * time.Hour}) || w.String() != "22:00-06:00" {
+			t.Errorf("ParseWindow(%q) = %+v (%s), want 22:00-06:00", s, w, w)
+		}
+	}
+	for _, s := range []string{"22:00", "22-06", "25:00-06:00"} {
+		if _, err := ParseWindow(s); err == nil {
+			t.Errorf("ParseWindow(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+// TestThrottle_Reserve verifies that trials are spaced by TrialsPerMinute
+// and only start inside the windows, including one spanning midnight.
+func TestThrottle_Reserve(t *testing.T) {
+	at := func(day, hour, min, sec int) time.Time {
+		return time.Date(2024, 3, day, hour, min, sec, 0, time.UTC)
+	}
+	th := &Throttle{
+		TrialsPerMinute: 2,
+		Windows:         []Window{{Start: 22 * time.Hour, End: 6 * time.Hour}, {Start: 12 * time.Hour, End: 13 * time.Hour}},
+	}
+	steps := []struct {
+		now, want time.Time
+	}{
+		{at(1, 9, 0, 0), at(1, 12, 0, 0)},
+		{at(1, 12, 0, 0), at(1, 12, 0, 30)},
+		{at(1, 12, 59, 50), at(1, 12, 59, 50)},
+		{at(1, 12, 59, 55), at(1, 22, 0, 0)},
+		{at(2, 5, 59, 45), at(2, 5, 59, 45)},
+		{at(2, 5, 59, 50), at(2, 12, 0, 0)},
+	}
+	for _, s := range steps {
+		if got := th.reserve(s.now); !got.Equal(s.want) {
+			t.Errorf("reserve(%v) = %v, want %v", s.now, got, s.want)
+		}
+	}
+}
+
+// TestThrottle_ParallelRunner verifies that MaxConcurrent bounds the trials
+// measured at once by the parallel runner and TrialsPerMinute spaces them.
+func TestThrottle_ParallelRunner(t *testing.T) {
+	exp := parallelExperiment(t)
+	var running, peak atomic.Int32
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		running.Add(-1)
+		return float64(trial.ID), nil
+	}
+
+	r := NewParallelRunner(exp, 4)
+	r.Options.Throttle = &Throttle{TrialsPerMinute: 6000, MaxConcurrent: 2}
+	start := time.Now()
+	if err := r.Run(context.Background(), measure); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrency %d, want at most 2", peak.Load())
+	}
+	// 8 trials 10ms apart.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("run took %v, want at least 70ms", elapsed)
+	}
+	if len(exp.Results) != 8 {
+		t.Errorf("len(Results) = %d, want 8", len(exp.Results))
+	}
+}
+
+// TestThrottle_CancelWhileWaiting verifies that a run waiting for its window
+// stops when the context is cancelled.
+func TestThrottle_CancelWhileWaiting(t *testing.T) {
+	exp := parallelExperiment(t)
+	now := sinceMidnight(time.Now())
+	closed := Window{Start: (now + 2*time.Hour) % (24 * time.Hour), End: (now + 3*time.Hour) % (24 * time.Hour)}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	measure := func(context.Context, Trial) (float64, error) { return 1, nil }
+	err := exp.Run(ctx, measure, RunOptions{Throttle: &Throttle{Windows: []Window{closed}}})
+	var ie *InterruptedError
+	if !errors.As(err, &ie) || ie.Completed != 0 {
+		t.Fatalf("Run = %v, want an interruption before any trial", err)
+	}
+}
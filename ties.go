@@ -0,0 +1,122 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TiePolicy selects the optimal level of a factor when several of its levels
+// have statistically indistinguishable SNRs. Every tie is reported in
+// AnalysisResult.Ties and as a WarnTiedLevels warning, whatever the policy.
+type TiePolicy int
+
+const (
+	// TieReport keeps the level with the highest mean SNR (the default). The
+	// other tied levels are only reported, leaving the choice to the user.
+	TieReport TiePolicy = iota
+	// TiePreferBaseline chooses the factor's first level, taken to be the
+	// current setting, when it is among the tied levels, so that a change is
+	// only recommended when it is a significant improvement.
+	TiePreferBaseline
+	// TiePreferCheaper chooses the tied level with the lowest ControlFactor.Costs
+	// entry. Factors without costs fall back to TieReport.
+	TiePreferCheaper
+)
+
+// LevelTie lists the levels of a factor whose SNR cannot be told apart from
+// the best level's at the experiment's Alpha.
+// Factor: The control factor.
+// Levels: The tied levels, including the best, in level order.
+// Best: The level with the highest mean SNR.
+// Chosen: The level put into OptimalLevels by the TiePolicy.
+type LevelTie struct {
+	Factor string
+	Levels []float64
+	Best   float64
+	Chosen float64
+}
+
+// resolveTies finds the levels tied with each factor's optimal level and
+// replaces the optimum according to the TiePolicy. Two levels are tied when
+// their mean SNRs differ by less than the least significant difference
+// t(1-α/2, ν_e)·sqrt(V_e·(1/n₁+1/n₂)), with n the number of rows per level;
+// without an error variance only exactly equal means are tied.
+func (e *Experiment[P]) resolveTies(rows oaRowSNR, anova ANOVAResult, mainEffects map[string][]float64, optimal map[string]float64) []LevelTie {
+	t := studentTQuantile(1-anova.Alpha/2, float64(anova.ErrorDF))
+	var ties []LevelTie
+	for j, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
+		counts := make([]int, len(factor.Levels))
+		for i := range e.OrthogonalArray {
+			if l := e.levelIndex(i, j); rows.included[i] && l >= 0 && l < len(counts) {
+				counts[l]++
+			}
+		}
+		effects := mainEffects[factor.Name]
+		best := levelPosition(factor, optimal[factor.Name])
+		if best < 0 || counts[best] == 0 {
+			continue
+		}
+		isTied := func(l int) bool {
+			if l == best {
+				return true
+			}
+			if counts[l] == 0 {
+				return false
+			}
+			diff := effects[best] - effects[l]
+			if anova.ErrorMS <= 0 {
+				return diff == 0
+			}
+			return diff < t*math.Sqrt(anova.ErrorMS*(1/float64(counts[l])+1/float64(counts[best])))
+		}
+		var tied []int
+		for l := range factor.Levels {
+			if isTied(l) {
+				tied = append(tied, l)
+			}
+		}
+		if len(tied) < 2 {
+			continue
+		}
+
+		chosen := best
+		switch e.TiePolicy {
+		case TiePreferBaseline:
+			if tied[0] == 0 {
+				chosen = 0
+			}
+		case TiePreferCheaper:
+			if len(factor.Costs) == len(factor.Levels) {
+				for _, l := range tied {
+					if factor.Costs[l] < factor.Costs[chosen] {
+						chosen = l
+					}
+				}
+			}
+		}
+		tie := LevelTie{Factor: factor.Name, Best: factor.Levels[best], Chosen: factor.Levels[chosen]}
+		for _, l := range tied {
+			tie.Levels = append(tie.Levels, factor.Levels[l])
+		}
+		optimal[factor.Name] = tie.Chosen
+		ties = append(ties, tie)
+	}
+	return ties
+}
+
+// tieWarning describes a tie for the analysis warnings.
+func tieWarning(tie LevelTie) Warning {
+	levels := make([]string, len(tie.Levels))
+	for i, l := range tie.Levels {
+		levels[i] = fmt.Sprintf("%g", l)
+	}
+	msg := fmt.Sprintf("levels %s of %s are statistically indistinguishable", strings.Join(levels, ", "), tie.Factor)
+	if tie.Chosen != tie.Best {
+		msg += fmt.Sprintf("; chose %g over the highest-SNR level %g", tie.Chosen, tie.Best)
+	}
+	return Warning{Code: WarnTiedLevels, Factor: tie.Factor, Row: -1, Message: msg}
+}
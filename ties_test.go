@@ -0,0 +1,45 @@
+package taguchi
+
+import "testing"
+
+// TestAnalyze_TiePolicy verifies that a level whose SNR advantage is within
+// the least significant difference is reported as a tie and resolved by the
+// experiment's TiePolicy, while a clear optimum is left alone.
+func TestAnalyze_TiePolicy(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}, Costs: []float64{1, 3}},
+	}
+	oa := [][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}}
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, nil)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	// A=1 is far better; B=2 is marginally better.
+	responses := map[[2]float64]float64{{1, 1}: 10, {1, 2}: 9.5, {2, 1}: 20.3, {2, 2}: 19.7}
+	for _, trial := range exp.GenerateTrials() {
+		exp.AddResult(trial, []float64{responses[[2]float64{trial.Control["A"], trial.Control["B"]}]})
+	}
+
+	tests := []struct {
+		policy TiePolicy
+		wantB  float64
+	}{
+		{TieReport, 2},
+		{TiePreferBaseline, 1},
+		{TiePreferCheaper, 1},
+	}
+	for _, tt := range tests {
+		exp.TiePolicy = tt.policy
+		result := mustAnalyze(t, exp)
+		if result.OptimalLevels["A"] != 1 || result.OptimalLevels["B"] != tt.wantB {
+			t.Errorf("policy %d: OptimalLevels = %v, want A=1 B=%g", tt.policy, result.OptimalLevels, tt.wantB)
+		}
+		if len(result.Ties) != 1 || result.Ties[0].Factor != "B" || result.Ties[0].Best != 2 || len(result.Ties[0].Levels) != 2 {
+			t.Errorf("policy %d: Ties = %+v, want B tied at levels 1 and 2", tt.policy, result.Ties)
+		}
+		if !result.HasWarning(WarnTiedLevels) {
+			t.Errorf("policy %d: expected %s warning, got %v", tt.policy, WarnTiedLevels, result.Warnings)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package taguchi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// TrialKey returns the content key of a trial configuration: a hash of its
+// control and noise levels in factor name order, as 16 hexadecimal digits.
+// Unlike the positional trial ID it depends neither on the order in which
+// the factors were declared nor on the trial's place in the design, so a
+// configuration has the same key in every process and every saved run.
+//
+// An array with more rows than its factors tell apart, e.g. L8 with three
+// factors, sets the same levels in several rows. The trials of such a
+// repeated row are keyed apart from the first one by their repeat count, so
+// every trial of the design still has its own Key.
+func TrialKey(control, noise map[string]float64) string {
+	h := sha256.New()
+	for _, part := range []struct {
+		kind   string
+		levels map[string]float64
+	}{{"control", control}, {"noise", noise}} {
+		for _, name := range sortedKeys(part.levels) {
+			level := part.levels[name]
+			if level == 0 {
+				level = 0 // -0 and 0 are the same level
+			}
+			fmt.Fprintf(h, "%s\x00%s\x00%s\n", part.kind, name, strconv.FormatFloat(level, 'g', -1, 64))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// key returns the trial's Key, computing it for trials built without one.
+func (t Trial) key() string {
+	if t.Key != "" {
+		return t.Key
+	}
+	return TrialKey(t.Control, t.Noise)
+}
+
+// withKey returns the trial with its Key set.
+func (t Trial) withKey() Trial {
+	t.Key = t.key()
+	return t
+}
+
+// TrialByKey returns the trial of the design with the given key, or else the
+// trial of a recorded result with that key, e.g. a follow-up run past the
+// array.
+func (e *Experiment[P]) TrialByKey(key string) (Trial, bool) {
+	for _, t := range e.designTrials() {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	for _, r := range e.Results {
+		if r.Trial.key() == key {
+			return r.Trial, true
+		}
+	}
+	return Trial{}, false
+}
+
+// ResultsByKey returns the recorded results of the trial with the given key,
+// in recording order.
+func (e *Experiment[P]) ResultsByKey(key string) []TrialResult {
+	var results []TrialResult
+	for _, r := range e.Results {
+		if r.Trial.key() == key {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// AddResultByKey records the observations of the design trial with the given
+// key like AddResult, e.g. for results reported by key from another process.
+func (e *Experiment[P]) AddResultByKey(key string, observations []float64) error {
+	trial, ok := e.TrialByKey(key)
+	if !ok {
+		return errorf(ErrTrialNotInDesign, "no trial with key %s", key)
+	}
+	return e.AddResult(trial, observations)
+}
+
+// resolveTrial keys a trial by its levels, which take precedence over a
+// stale Key. A trial whose levels are those of its Row gets that row's key,
+// which counts the repeats of its levels in earlier rows. A keyed trial whose
+// levels are those of another trial of the design than its Row says, e.g.
+// one generated by a process that declared the factors in another order,
+// takes the ID, Row and Key of the design trial with that Key, or else of
+// the first with its levels. Trials built without a Key are validated
+// against their Row as given.
+func (e *Experiment[P]) resolveTrial(trial Trial) Trial {
+	stated := trial.Key
+	key := TrialKey(trial.Control, trial.Noise)
+	trial.Key = key
+	if trial.Row >= 0 && trial.Row < len(e.OrthogonalArray) {
+		if control := e.getControlConfig(e.OrthogonalArray[trial.Row]); TrialKey(control, trial.Noise) == key {
+			trial.Key = repeatKey(control, trial.Noise, e.rowRepeats()[trial.Row])
+			return trial
+		}
+	}
+	if stated == "" {
+		return trial
+	}
+	var match *Trial
+	for _, t := range e.designTrials() {
+		if TrialKey(t.Control, t.Noise) != key {
+			continue
+		}
+		if t.Key == stated {
+			match = &t
+			break
+		}
+		if match == nil && stated == key {
+			match = &t
+		}
+	}
+	if match != nil {
+		trial.ID, trial.Row, trial.Key = match.ID, match.Row, match.Key
+	}
+	return trial
+}
+
+// rowRepeats returns, for every array row, how many earlier rows set the
+// same control levels.
+func (e *Experiment[P]) rowRepeats() []int {
+	seen := map[string]int{}
+	repeats := make([]int, len(e.OrthogonalArray))
+	for i, row := range e.OrthogonalArray {
+		key := TrialKey(e.getControlConfig(row), nil)
+		repeats[i] = seen[key]
+		seen[key]++
+	}
+	return repeats
+}
+
+// repeatKey returns the key of a design trial whose control levels were
+// already set by repeat earlier array rows: its TrialKey for the first
+// occurrence, and a key derived from it and the repeat count otherwise.
+func repeatKey(control, noise map[string]float64, repeat int) string {
+	key := TrialKey(control, noise)
+	if repeat == 0 {
+		return key
+	}
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s\x00repeat\x00%d", key, repeat)))
+	return hex.EncodeToString(h[:8])
+}
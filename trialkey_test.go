@@ -0,0 +1,152 @@
+package taguchi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestTrialKey(t *testing.T) {
+	control := map[string]float64{"A": 1, "B": 0}
+	key := TrialKey(control, map[string]float64{"N": 0})
+	if len(key) != 16 {
+		t.Errorf("key %q, want 16 hex digits", key)
+	}
+	if k := TrialKey(map[string]float64{"B": math.Copysign(0, -1), "A": 1}, map[string]float64{"N": 0}); k != key {
+		t.Errorf("key %s with -0, want %s", k, key)
+	}
+	if k := TrialKey(control, map[string]float64{"N": 1}); k == key {
+		t.Error("different noise levels share a key")
+	}
+	// A control factor is not the same as a noise factor of the same name.
+	if TrialKey(map[string]float64{"A": 1}, nil) == TrialKey(nil, map[string]float64{"A": 1}) {
+		t.Error("control and noise levels share a key")
+	}
+}
+
+// TestAddResult_ByKey records the trials of an experiment declaring the
+// factors in the opposite order, after a round trip through JSON as between
+// processes. Their rows differ, but the keys put every result on the row
+// with the same levels.
+func TestAddResult_ByKey(t *testing.T) {
+	newExp := func(names ...string) *Experiment[struct{}] {
+		var factors []ControlFactor
+		for _, name := range names {
+			factors = append(factors, ControlFactor{Name: name, Levels: []float64{1, 2}})
+		}
+		exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, []NoiseFactor{{Name: "N", Levels: []float64{0, 1}}})
+		if err != nil {
+			t.Fatalf("NewExperimentFromFactors: %v", err)
+		}
+		return exp
+	}
+	local, remote := newExp("A", "B"), newExp("B", "A")
+	response := func(trial Trial) []float64 {
+		return []float64{1 + trial.Control["A"] + 2*trial.Control["B"] + trial.Noise["N"]}
+	}
+
+	data, err := json.Marshal(remote.GenerateTrials())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trials []Trial
+	if err := json.Unmarshal(data, &trials); err != nil {
+		t.Fatal(err)
+	}
+	for _, trial := range trials {
+		if err := local.AddResult(trial, response(trial)); err != nil {
+			t.Fatalf("AddResult(%+v): %v", trial, err)
+		}
+	}
+	for _, r := range local.Results {
+		want, ok := local.TrialByKey(r.Trial.Key)
+		if !ok || r.Trial.Row != want.Row || r.Trial.ID != want.ID {
+			t.Errorf("result of %v recorded as trial %d row %d, want trial %d row %d", r.Trial.Control, r.Trial.ID, r.Trial.Row, want.ID, want.Row)
+		}
+	}
+
+	direct := newExp("A", "B")
+	for _, trial := range direct.GenerateTrials() {
+		if err := direct.AddResultByKey(trial.Key, response(trial)); err != nil {
+			t.Fatalf("AddResultByKey: %v", err)
+		}
+	}
+	got, want := mustAnalyze(t, local), mustAnalyze(t, direct)
+	for _, name := range []string{"A", "B"} {
+		for i := range want.MainEffects[name] {
+			if !almostEqual(got.MainEffects[name][i], want.MainEffects[name][i]) {
+				t.Errorf("main effects of %s %v, want %v", name, got.MainEffects[name], want.MainEffects[name])
+			}
+		}
+	}
+
+	key := trials[0].Key
+	if rs := local.ResultsByKey(key); len(rs) != 1 || rs[0].Trial.Key != key {
+		t.Errorf("ResultsByKey = %+v, want the result of trial %s", rs, key)
+	}
+	if err := local.AddResultByKey("0123456789abcdef", []float64{1}); !errors.Is(err, ErrTrialNotInDesign) {
+		t.Errorf("AddResultByKey(unknown) = %v, want ErrTrialNotInDesign", err)
+	}
+}
+
+// TestTrialKey_RepeatedRows verifies that the rows of an array repeating the
+// same levels get distinct keys, that lookups and recording by key resolve
+// to the right row, and that completed trials are found by key even when the
+// recorded results carry other trial IDs.
+func TestTrialKey_RepeatedRows(t *testing.T) {
+	// Columns 1-3 of L8 set every configuration of A, B and C twice.
+	exp := newTestExperiment(t, testDesign{Factors: namedFactors([]float64{1, 2}, "A", "B", "C"), Array: L8})
+	trials := exp.GenerateTrials()
+	keys := map[string]int{}
+	for _, trial := range trials {
+		if prev, ok := keys[trial.Key]; ok {
+			t.Fatalf("rows %d and %d share key %s", prev, trial.Row, trial.Key)
+		}
+		keys[trial.Key] = trial.Row
+	}
+	repeat := trials[1]
+	if repeat.Row != 1 || !maps.Equal(repeat.Control, trials[0].Control) {
+		t.Fatalf("row 1 = %v, want the levels of row 0 %v", repeat.Control, trials[0].Control)
+	}
+	if got, ok := exp.TrialByKey(repeat.Key); !ok || got.Row != 1 {
+		t.Errorf("TrialByKey(row 1) = %+v, %v, want row 1", got, ok)
+	}
+	if err := exp.AddResultByKey(repeat.Key, []float64{1}); err != nil {
+		t.Fatalf("AddResultByKey: %v", err)
+	}
+	if err := exp.AddResult(trials[0], []float64{2}); err != nil {
+		t.Fatalf("AddResult of row 0 after row 1: %v", err)
+	}
+	if r := exp.ResultsByKey(repeat.Key); len(r) != 1 || r[0].Trial.Row != 1 || r[0].Observations[0] != 1 {
+		t.Errorf("ResultsByKey(row 1) = %+v, want the result recorded on row 1", r)
+	}
+
+	// Results from another process may carry other IDs.
+	for i := range exp.Results {
+		exp.Results[i].Trial.ID += 100
+	}
+	pending := exp.PendingTrials()
+	if len(pending) != len(trials)-2 {
+		t.Fatalf("%d pending trials, want %d", len(pending), len(trials)-2)
+	}
+	for _, trial := range pending {
+		if trial.Row < 2 {
+			t.Errorf("trial of row %d is pending although it has a result", trial.Row)
+		}
+	}
+	var measured []int
+	measure := func(_ context.Context, trial Trial) (float64, error) {
+		measured = append(measured, trial.Row)
+		return 1, nil
+	}
+	if err := exp.Run(context.Background(), measure, RunOptions{SkipCompleted: true}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(measured) != len(trials)-2 || slices.Contains(measured, 0) || slices.Contains(measured, 1) {
+		t.Errorf("Run measured rows %v, want every row but 0 and 1", measured)
+	}
+}
@@ -1,9 +1,15 @@
 package taguchi
 
-// GenerateTrials produces all possible trial configurations for the experiment.
+// GenerateTrials produces all possible trial configurations for the experiment,
+// in the experiment's RunOrder (array order unless randomized).
 func (e *Experiment[P]) GenerateTrials() []Trial {
-	// Step 1: Generate all noise combinations
-	noiseTrials := e.generateNoiseCombinations()
+	return e.orderTrials(e.designTrials(), 0)
+}
+
+// designTrials produces all trial configurations in array order.
+func (e *Experiment[P]) designTrials() []Trial {
+	// Step 1: Generate all noise combinations, sampled down if a budget is configured
+	noiseTrials := e.sampledNoise()
 
 	// Step 2: Combine noise with orthogonal array control configurations
 	finalTrials := e.combineControlAndNoise(noiseTrials)
@@ -49,15 +55,18 @@ func (e *Experiment[P]) generateNoiseCombinations() []Trial {
 func (e *Experiment[P]) combineControlAndNoise(noiseTrials []Trial) []Trial {
 	var finalTrials []Trial
 	id := 1 // reset ID for full trial list
+	repeats := e.rowRepeats()
 
-	for _, row := range e.OrthogonalArray {
+	for rowIdx, row := range e.OrthogonalArray {
 		controlConfig := e.getControlConfig(row)
 
 		for _, noiseTrial := range noiseTrials {
 			t := Trial{
 				ID:      id,
+				Row:     rowIdx,
 				Control: controlConfig,
 				Noise:   noiseTrial.Noise,
+				Key:     repeatKey(controlConfig, noiseTrial.Noise, repeats[rowIdx]),
 			}
 			finalTrials = append(finalTrials, t)
 			id++
@@ -71,7 +80,7 @@ func (e *Experiment[P]) combineControlAndNoise(noiseTrials []Trial) []Trial {
 func (e *Experiment[P]) getControlConfig(row []int) map[string]float64 {
 	controlConfig := make(map[string]float64, len(e.ControlFactors))
 	for j, factor := range e.ControlFactors {
-		levelIndex := row[j] - 1 // orthogonal array indices are 1-based
+		levelIndex := row[e.column(j)] - 1 // orthogonal array indices are 1-based
 		controlConfig[factor.Name] = factor.Levels[levelIndex]
 	}
 	return controlConfig
@@ -0,0 +1,131 @@
+package taguchi
+
+import (
+	"context"
+	"math/rand"
+)
+
+// Trials returns the trials of GenerateTrials, in the same order and with the
+// same IDs, as a sequence generated lazily. Without noise sampling the full
+// crossing of the noise factors is never materialized, so designs too large
+// for GenerateTrials can be streamed to a runner; a randomized RunOrder keeps
+// only a permutation of trial indices in memory. Iteration stops early when
+// ctx is cancelled, which the caller detects with ctx.Err().
+//
+// The sequence has the signature of iter.Seq[Trial], so from Go 1.23 it can
+// be ranged over directly:
+//
+//	for trial := range exp.Trials(ctx) { ... }
+//
+// and with older toolchains it is called with the loop body:
+//
+//	exp.Trials(ctx)(func(trial Trial) bool { ...; return true })
+func (e *Experiment[P]) Trials(ctx context.Context) func(yield func(Trial) bool) {
+	return func(yield func(Trial) bool) {
+		e.eachTrial(ctx, 0, yield)
+	}
+}
+
+// TrialCount returns the number of trials of GenerateTrials without
+// generating them.
+func (e *Experiment[P]) TrialCount() int {
+	n, _ := e.noiseSettings()
+	return len(e.OrthogonalArray) * n
+}
+
+// eachTrial passes the trials of orderTrials(designTrials(), block) to yield
+// until yield returns false or ctx is cancelled. Randomized orders replay the
+// shuffles of orderTrials on trial indices, drawing the same random numbers.
+func (e *Experiment[P]) eachTrial(ctx context.Context, block int, yield func(Trial) bool) {
+	n, noise := e.noiseSettings()
+	rows := len(e.OrthogonalArray)
+	repeats := e.rowRepeats()
+	var control map[string]float64
+	controlRow := -1
+	emit := func(i int) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		row, k := i/n, i%n
+		if row != controlRow {
+			control, controlRow = e.getControlConfig(e.OrthogonalArray[row]), row
+		}
+		settings := noise(k)
+		return yield(Trial{
+			ID:      i + 1,
+			Row:     row,
+			Control: control,
+			Noise:   settings,
+			Key:     repeatKey(control, settings, repeats[row]),
+		})
+	}
+
+	o := e.RunOrder
+	if !o.Randomize {
+		for i := 0; i < rows*n; i++ {
+			if !emit(i) {
+				return
+			}
+		}
+		return
+	}
+	rng := rand.New(rand.NewSource(o.Seed + int64(block)))
+	if !o.KeepRows {
+		perm := indices(rows * n)
+		rng.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+		for _, i := range perm {
+			if !emit(i) {
+				return
+			}
+		}
+		return
+	}
+	rowOrder := indices(rows)
+	rng.Shuffle(rows, func(i, j int) { rowOrder[i], rowOrder[j] = rowOrder[j], rowOrder[i] })
+	for _, row := range rowOrder {
+		within := indices(n)
+		rng.Shuffle(n, func(i, j int) { within[i], within[j] = within[j], within[i] })
+		for _, k := range within {
+			if !emit(row*n + k) {
+				return
+			}
+		}
+	}
+}
+
+// noiseSettings returns the number of noise conditions of the design and a
+// function returning the levels of the k-th, zero-based, in the order of
+// sampleNoise. The full crossing is decoded from k like a mixed-radix number,
+// the last noise factor varying fastest; sampled conditions are generated up
+// front.
+func (e *Experiment[P]) noiseSettings() (int, func(k int) map[string]float64) {
+	total := 1
+	for _, f := range e.NoiseFactors {
+		total *= len(f.Levels)
+	}
+	s := e.NoiseSampling
+	sampled := len(e.NoiseFactors) > 0 && (s.Method == OuterArrayNoise && len(s.OuterArray) > 0 ||
+		s.Method != FullNoiseCrossing && s.Budget > 0 && s.Budget < total)
+	if sampled {
+		conditions := e.sampledNoise()
+		return len(conditions), func(k int) map[string]float64 { return conditions[k].Noise }
+	}
+	return total, func(k int) map[string]float64 {
+		settings := make(map[string]float64, len(e.NoiseFactors))
+		for j := len(e.NoiseFactors) - 1; j >= 0; j-- {
+			f := e.NoiseFactors[j]
+			settings[f.Name] = f.Levels[k%len(f.Levels)]
+			k /= len(f.Levels)
+		}
+		return settings
+	}
+}
+
+// indices returns the indices 0 to n-1.
+func indices(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
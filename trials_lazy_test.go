@@ -0,0 +1,106 @@
+package taguchi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// collect gathers the trials of a sequence.
+func collect(seq func(yield func(Trial) bool)) []Trial {
+	var trials []Trial
+	seq(func(trial Trial) bool {
+		trials = append(trials, trial)
+		return true
+	})
+	return trials
+}
+
+// TestTrials_MatchesGenerateTrials verifies that the lazy sequence yields the
+// trials of GenerateTrials, in the same order, for every run order and noise
+// sampling method.
+func TestTrials_MatchesGenerateTrials(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2, 3}},
+		{Name: "B", Levels: []float64{10, 20, 30}},
+	}
+	noise := []NoiseFactor{
+		{Name: "N1", Levels: []float64{0, 1, 2}},
+		{Name: "N2", Levels: []float64{0, 1}},
+		{Name: "N3", Levels: []float64{5, 6, 7}},
+	}
+	orders := map[string]RunOrder{
+		"array":     {},
+		"random":    {Randomize: true, Seed: 7},
+		"keep rows": {Randomize: true, KeepRows: true, Seed: 3},
+	}
+	samplings := map[string]NoiseSampling{
+		"full":       {},
+		"stratified": {Method: StratifiedNoiseSampling, Budget: 6, Seed: 42},
+		"latin":      {Method: LatinHypercubeNoiseSampling, Budget: 5, Seed: 1},
+		"outer":      {Method: OuterArrayNoise, OuterArray: [][]int{{1, 1, 1}, {2, 2, 3}, {3, 1, 2}}},
+	}
+	for orderName, order := range orders {
+		for samplingName, sampling := range samplings {
+			exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L9, noise)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exp.RunOrder = order
+			exp.NoiseSampling = sampling
+			want := exp.GenerateTrials()
+			got := collect(exp.Trials(context.Background()))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s order, %s noise: Trials differs from GenerateTrials", orderName, samplingName)
+			}
+			if n := exp.TrialCount(); n != len(want) {
+				t.Errorf("%s order, %s noise: TrialCount = %d, want %d", orderName, samplingName, n, len(want))
+			}
+		}
+	}
+}
+
+// TestTrials_Lazy verifies that a design far too large to materialize can
+// be streamed, stopped by the caller and cancelled.
+func TestTrials_Lazy(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	var noise []NoiseFactor
+	for _, name := range []string{"N1", "N2", "N3", "N4", "N5", "N6", "N7", "N8", "N9", "N10", "N11", "N12", "N13", "N14", "N15", "N16"} {
+		noise = append(noise, NoiseFactor{Name: name, Levels: []float64{1, 2, 3, 4}})
+	}
+	exp, err := NewExperimentFromFactors(SmallerTheBetter{}, factors, L4, noise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := exp.TrialCount(); n != 4<<32 {
+		t.Fatalf("TrialCount = %d, want %d", n, 4<<32)
+	}
+
+	var first []Trial
+	exp.Trials(context.Background())(func(trial Trial) bool {
+		first = append(first, trial)
+		return len(first) < 5
+	})
+	if len(first) != 5 {
+		t.Fatalf("got %d trials after stopping at 5", len(first))
+	}
+	last := first[4]
+	if last.ID != 5 || last.Row != 0 || last.Noise["N16"] != 1 || last.Noise["N15"] != 2 || last.Key != TrialKey(last.Control, last.Noise) {
+		t.Errorf("fifth trial = %+v", last)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var n int
+	exp.Trials(ctx)(func(trial Trial) bool {
+		if n++; n == 3 {
+			cancel()
+		}
+		return true
+	})
+	if n != 3 || ctx.Err() == nil {
+		t.Errorf("got %d trials, want iteration to stop at the cancellation after 3", n)
+	}
+}
@@ -0,0 +1,274 @@
+// Package tuner tunes the Go runtime settings of a workload with a Taguchi
+// experiment. Each Knob is a control factor; every trial puts its levels into
+// effect in the running process, times the workload, and Tune recommends the
+// settings under which it ran fastest:
+//
+//	var batch int
+//	t := &tuner.Tuner{
+//		Knobs: []tuner.Knob{
+//			tuner.GOMAXPROCS(2, 4, 8),
+//			tuner.GOGC(50, 100, 400),
+//			tuner.GOMEMLIMIT(512<<20, 1<<30, math.MaxInt64),
+//			tuner.PoolSize("Batch", &batch, 64, 256, 1024),
+//		},
+//		Workload:    func(ctx context.Context) error { return process(ctx, batch) },
+//		Repetitions: 5,
+//	}
+//	rec, err := t.Tune(ctx)
+//	fmt.Println(rec.Env()) // [GOMAXPROCS=4 GOGC=400 GOMEMLIMIT=1073741824]
+//
+// The settings in effect before Tune are restored when it returns.
+package tuner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/marijaaleksic/taguchi"
+)
+
+// Knob is a runtime setting tuned as a control factor.
+// Name: Factor name, e.g. "GOGC"; the runtime knobs are named after their environment variables.
+// Levels: Settings to try.
+// Set: Puts a level into effect and returns the setting it replaced, so Tune can restore it.
+type Knob struct {
+	Name   string
+	Levels []float64
+	Set    func(level float64) (previous float64)
+}
+
+// GOMAXPROCS returns a knob setting runtime.GOMAXPROCS.
+func GOMAXPROCS(levels ...int) Knob {
+	return Knob{Name: "GOMAXPROCS", Levels: floats(levels), Set: func(level float64) float64 {
+		return float64(runtime.GOMAXPROCS(int(level)))
+	}}
+}
+
+// GOGC returns a knob setting the garbage collection target percentage with
+// debug.SetGCPercent; the level -1 turns the collector off.
+func GOGC(levels ...int) Knob {
+	return Knob{Name: "GOGC", Levels: floats(levels), Set: func(level float64) float64 {
+		return float64(debug.SetGCPercent(int(level)))
+	}}
+}
+
+// GOMEMLIMIT returns a knob setting the soft memory limit in bytes with
+// debug.SetMemoryLimit; the level math.MaxInt64 means no limit.
+func GOMEMLIMIT(levels ...int64) Knob {
+	f := make([]float64, len(levels))
+	for i, l := range levels {
+		f[i] = float64(l)
+	}
+	return Knob{Name: "GOMEMLIMIT", Levels: f, Set: func(level float64) float64 {
+		return float64(debug.SetMemoryLimit(bytes(level)))
+	}}
+}
+
+// PoolSize returns a knob storing each level in *size, for an integer the
+// workload reads, such as the buffer size of a sync.Pool, a batch size or
+// the number of workers in a pool.
+func PoolSize(name string, size *int, levels ...int) Knob {
+	return Knob{Name: name, Levels: floats(levels), Set: func(level float64) float64 {
+		previous := *size
+		*size = int(level)
+		return float64(previous)
+	}}
+}
+
+// DefaultRepetitions is the number of timed runs per trial when
+// Tuner.Repetitions is zero.
+const DefaultRepetitions = 3
+
+// Tuner finds the knob settings under which a workload runs fastest. The
+// knobs change the whole process, so nothing else should run in it while
+// Tune is running.
+// Knobs: Settings to tune.
+// Workload: One run of the code to tune; its wall time is the observation.
+// Repetitions: Timed runs per trial (DefaultRepetitions when zero).
+// Warmup: Untimed runs per trial before the timed ones, e.g. to fill caches and pools.
+// Array: Orthogonal array for the knobs; the smallest standard array that fits when empty.
+type Tuner struct {
+	Knobs       []Knob
+	Workload    func(ctx context.Context) error
+	Repetitions int
+	Warmup      int
+	Array       taguchi.ArrayType
+}
+
+// Recommendation is the outcome of Tune.
+// Settings: Recommended level of every knob.
+// Predicted: Predicted run time of the workload at the recommended settings.
+// Baseline: Mean run time measured under the settings in effect before Tune.
+// Analysis: The analysis of the experiment, e.g. for a report with taguchi.WriteAnalysisReport.
+// Experiment: The experiment with all timings, in seconds.
+type Recommendation struct {
+	Settings   map[string]float64
+	Predicted  time.Duration
+	Baseline   time.Duration
+	Analysis   taguchi.AnalysisResult
+	Experiment *taguchi.Experiment[struct{}]
+	knobs      []Knob
+}
+
+// Tune measures the workload under the settings in effect and then under
+// every trial of the experiment, and returns the recommended settings. It
+// stops at the first error of the workload or when ctx is cancelled.
+func (t *Tuner) Tune(ctx context.Context) (Recommendation, error) {
+	if t.Workload == nil {
+		return Recommendation{}, errors.New("tuner: no workload")
+	}
+	if len(t.Knobs) == 0 {
+		return Recommendation{}, errors.New("tuner: no knobs")
+	}
+	b := taguchi.NewBuilder().Goal(taguchi.SmallerTheBetter{})
+	if t.Array != "" {
+		b.Array(t.Array)
+	}
+	// Previous settings are saved when a knob is first set and restored in
+	// reverse order.
+	var restore []func()
+	defer func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+	}()
+	for _, k := range t.Knobs {
+		k := k
+		if k.Set == nil {
+			return Recommendation{}, fmt.Errorf("tuner: knob %q has no Set function", k.Name)
+		}
+		saved := false
+		b.ControlFactor(taguchi.ControlFactor{Name: k.Name, Levels: k.Levels, Apply: func(_ context.Context, level float64) error {
+			previous := k.Set(level)
+			if !saved {
+				saved = true
+				restore = append(restore, func() { k.Set(previous) })
+			}
+			return nil
+		}})
+	}
+	exp, _, err := b.Build()
+	if err != nil {
+		return Recommendation{}, fmt.Errorf("tuner: %w", err)
+	}
+
+	repetitions := t.Repetitions
+	if repetitions <= 0 {
+		repetitions = DefaultRepetitions
+	}
+	measure := func(ctx context.Context, _ taguchi.Trial) (float64, error) {
+		start := time.Now()
+		if err := t.Workload(ctx); err != nil {
+			return 0, err
+		}
+		return time.Since(start).Seconds(), nil
+	}
+	var baseline float64
+	for i := 0; i < t.Warmup+repetitions; i++ {
+		s, err := measure(ctx, taguchi.Trial{})
+		if err != nil {
+			return Recommendation{}, fmt.Errorf("tuner: baseline: %w", err)
+		}
+		if i >= t.Warmup {
+			baseline += s / float64(repetitions)
+		}
+	}
+
+	opts := taguchi.RunOptions{
+		Repetitions: repetitions,
+		Warmup:      t.Warmup,
+		// Start every trial from a collected heap, so garbage left by the
+		// previous trial is not charged to this one.
+		SetupTrial: func(context.Context, taguchi.Trial) error {
+			runtime.GC()
+			return nil
+		},
+	}
+	if err := exp.Run(ctx, measure, opts); err != nil {
+		return Recommendation{}, fmt.Errorf("tuner: %w", err)
+	}
+	analysis, err := exp.Analyze()
+	if err != nil {
+		return Recommendation{}, fmt.Errorf("tuner: %w", err)
+	}
+	prediction, err := exp.PredictOptimal()
+	if err != nil {
+		return Recommendation{}, fmt.Errorf("tuner: %w", err)
+	}
+	return Recommendation{
+		Settings:   analysis.OptimalLevels,
+		Predicted:  seconds(prediction.Mean),
+		Baseline:   seconds(baseline),
+		Analysis:   analysis,
+		Experiment: exp,
+		knobs:      t.Knobs,
+	}, nil
+}
+
+// Apply puts the recommended settings into effect in the running process.
+func (r Recommendation) Apply() {
+	for _, k := range r.knobs {
+		if level, ok := r.Settings[k.Name]; ok {
+			k.Set(level)
+		}
+	}
+}
+
+// Env returns the recommended settings of the GOMAXPROCS, GOGC and
+// GOMEMLIMIT knobs as environment variables for deployment, in knob order,
+// e.g. "GOGC=off".
+func (r Recommendation) Env() []string {
+	var env []string
+	for _, k := range r.knobs {
+		level, ok := r.Settings[k.Name]
+		if !ok {
+			continue
+		}
+		switch k.Name {
+		case "GOMAXPROCS":
+			env = append(env, "GOMAXPROCS="+strconv.Itoa(int(level)))
+		case "GOGC":
+			value := strconv.Itoa(int(level))
+			if level < 0 {
+				value = "off"
+			}
+			env = append(env, "GOGC="+value)
+		case "GOMEMLIMIT":
+			value := strconv.FormatInt(bytes(level), 10)
+			if bytes(level) == math.MaxInt64 {
+				value = "off"
+			}
+			env = append(env, "GOMEMLIMIT="+value)
+		}
+	}
+	return env
+}
+
+// floats converts integer levels to factor levels.
+func floats(levels []int) []float64 {
+	f := make([]float64, len(levels))
+	for i, l := range levels {
+		f[i] = float64(l)
+	}
+	return f
+}
+
+// bytes converts a memory limit level back to bytes; float64(math.MaxInt64)
+// rounds up past the int64 range and is clamped to it.
+func bytes(level float64) int64 {
+	if level >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(level)
+}
+
+// seconds converts an observation in seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
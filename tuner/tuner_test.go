@@ -0,0 +1,114 @@
+package tuner
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTune(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+	gc := debug.SetGCPercent(100)
+	debug.SetGCPercent(gc)
+	batch := 3
+	var runs int
+	tuner := &Tuner{
+		Knobs: []Knob{
+			GOMAXPROCS(1, 2),
+			GOGC(-1, 200),
+			PoolSize("Batch", &batch, 1, 8),
+		},
+		Workload: func(ctx context.Context) error {
+			runs++
+			time.Sleep(time.Duration(batch) * time.Millisecond)
+			return nil
+		},
+		Repetitions: 2,
+		Warmup:      1,
+	}
+	rec, err := tuner.Tune(context.Background())
+	if err != nil {
+		t.Fatalf("Tune: %v", err)
+	}
+	// 3 baseline runs and 4 L4 trials of 3 runs each.
+	if runs != 3+4*3 {
+		t.Errorf("workload ran %d times, want 15", runs)
+	}
+	if rec.Settings["Batch"] != 1 {
+		t.Errorf("Settings = %v, want Batch=1", rec.Settings)
+	}
+	if rec.Baseline < 3*time.Millisecond || rec.Predicted <= 0 || rec.Predicted > rec.Baseline {
+		t.Errorf("Baseline = %v, Predicted = %v, want a prediction below the 3ms baseline", rec.Baseline, rec.Predicted)
+	}
+	if got := runtime.GOMAXPROCS(0); got != procs {
+		t.Errorf("GOMAXPROCS after Tune = %d, want %d restored", got, procs)
+	}
+	if got := debug.SetGCPercent(gc); got != gc {
+		t.Errorf("GOGC after Tune = %d, want %d restored", got, gc)
+	}
+	if batch != 3 {
+		t.Errorf("Batch after Tune = %d, want 3 restored", batch)
+	}
+
+	env := rec.Env()
+	if len(env) != 2 || !slices.Contains(env, "GOGC="+map[bool]string{true: "off", false: "200"}[rec.Settings["GOGC"] < 0]) {
+		t.Errorf("Env() = %q, want GOMAXPROCS and GOGC only", env)
+	}
+	rec.Apply()
+	defer runtime.GOMAXPROCS(procs)
+	defer debug.SetGCPercent(gc)
+	if batch != 1 || runtime.GOMAXPROCS(0) != int(rec.Settings["GOMAXPROCS"]) {
+		t.Errorf("Apply left Batch=%d GOMAXPROCS=%d, want %v", batch, runtime.GOMAXPROCS(0), rec.Settings)
+	}
+}
+
+func TestTune_Errors(t *testing.T) {
+	failure := errors.New("connection refused")
+	var n int
+	size := 0
+	tuner := &Tuner{
+		Knobs: []Knob{PoolSize("Workers", &size, 1, 2)},
+		Workload: func(ctx context.Context) error {
+			if n++; n > 4 {
+				return failure
+			}
+			return nil
+		},
+	}
+	if _, err := tuner.Tune(context.Background()); !errors.Is(err, failure) {
+		t.Errorf("Tune with a failing workload = %v, want its error", err)
+	}
+	if size != 0 {
+		t.Errorf("Workers after a failed Tune = %d, want 0 restored", size)
+	}
+
+	tuner.Knobs = append(tuner.Knobs, PoolSize("Workers", &size, 4, 8))
+	if _, err := tuner.Tune(context.Background()); err == nil {
+		t.Error("Tune with duplicate knobs succeeded")
+	}
+	if _, err := (&Tuner{Knobs: tuner.Knobs[:1]}).Tune(context.Background()); err == nil {
+		t.Error("Tune without a workload succeeded")
+	}
+}
+
+func TestGOMEMLIMIT(t *testing.T) {
+	k := GOMEMLIMIT(1<<30, math.MaxInt64)
+	previous := k.Set(k.Levels[0])
+	defer k.Set(previous)
+	if got := debug.SetMemoryLimit(-1); got != 1<<30 {
+		t.Errorf("memory limit = %d, want %d", got, 1<<30)
+	}
+	k.Set(k.Levels[1])
+	if got := debug.SetMemoryLimit(-1); got != math.MaxInt64 {
+		t.Errorf("memory limit = %d, want no limit", got)
+	}
+	rec := Recommendation{Settings: map[string]float64{"GOMEMLIMIT": k.Levels[1]}, knobs: []Knob{k}}
+	if env := rec.Env(); !slices.Equal(env, []string{"GOMEMLIMIT=off"}) {
+		t.Errorf("Env() = %q", env)
+	}
+}
@@ -0,0 +1,113 @@
+package taguchi
+
+import (
+	"fmt"
+	"maps"
+	"math"
+)
+
+// TwoStepResult is Taguchi's two-step optimization for nominal-the-best
+// responses: first reduce variation by setting the factors that drive the SNR,
+// then move the mean onto target with factors that shift the mean without
+// affecting the SNR.
+// VarianceFactors: Factors with a significant effect on the SNR, set to their SNR-optimal levels.
+// AdjustmentFactors: Factors with a significant effect on the mean but not on the SNR.
+// Levels: Recommended level of every optimizable factor.
+// MeanEffects: Mean response per factor level.
+// MeanANOVA: ANOVA of the row mean responses, the basis of the adjustment step.
+// Target: The target mean.
+// PredictedMean: Mean predicted by the additive model at Levels.
+// Scaling: Target / PredictedMean, the remaining correction for a continuous adjustment factor to which the response is proportional; 1 when the target is met.
+type TwoStepResult struct {
+	VarianceFactors   []string
+	AdjustmentFactors []string
+	Levels            map[string]float64
+	MeanEffects       map[string][]float64
+	MeanANOVA         ANOVAResult
+	Target            float64
+	PredictedMean     float64
+	Scaling           float64
+}
+
+// maxAdjustmentCombinations bounds the search over adjustment factor levels.
+const maxAdjustmentCombinations = 1 << 16
+
+// TwoStepOptimization classifies the factors by significance (p < Alpha) in
+// the SNR and in the mean-response ANOVA. Every optimizable factor starts at
+// its SNR-optimal level; the adjustment factors are then set to the level
+// combination whose predicted mean is closest to target. It is meant for
+// NominalTheBestTypeI and SignedTarget experiments, whose SNR ignores the mean.
+func (e *Experiment[P]) TwoStepOptimization(target float64) (TwoStepResult, error) {
+	if len(e.Results) == 0 {
+		return TwoStepResult{}, errorf(ErrMissingResults, "no results recorded")
+	}
+	if err := e.checkComplete(); err != nil {
+		return TwoStepResult{}, err
+	}
+	snrRows := e.computeOASNR()
+	snrANOVA, snrEffects, _ := e.computeANOVA(snrRows)
+	meanRows := e.computeRowMeans(snrRows)
+	meanANOVA, meanEffects, _ := e.computeANOVA(meanRows)
+	alpha := e.alpha()
+
+	res := TwoStepResult{
+		Levels:      e.findOptimalLevels(snrEffects),
+		MeanEffects: meanEffects,
+		MeanANOVA:   meanANOVA,
+		Target:      target,
+	}
+	var adjust []ControlFactor
+	for _, factor := range e.ControlFactors {
+		if factor.ObserveOnly {
+			continue
+		}
+		switch {
+		case snrANOVA.FactorP[factor.Name] < alpha:
+			res.VarianceFactors = append(res.VarianceFactors, factor.Name)
+		case meanANOVA.FactorP[factor.Name] < alpha:
+			res.AdjustmentFactors = append(res.AdjustmentFactors, factor.Name)
+			adjust = append(adjust, factor)
+		}
+	}
+
+	predict := func(levels map[string]float64) float64 {
+		mean := meanRows.grandMean
+		for _, factor := range e.ControlFactors {
+			if level, ok := levels[factor.Name]; ok {
+				mean += meanEffects[factor.Name][levelPosition(factor, level)] - meanRows.grandMean
+			}
+		}
+		return mean
+	}
+
+	combinations := 1
+	for _, factor := range adjust {
+		if combinations *= len(factor.Levels); combinations > maxAdjustmentCombinations {
+			return TwoStepResult{}, fmt.Errorf("too many adjustment factor combinations")
+		}
+	}
+	best, bestLevels := math.Inf(1), map[string]float64{}
+	pick := make([]int, len(adjust))
+	for c := 0; c < combinations; c++ {
+		levels := maps.Clone(res.Levels)
+		for k, factor := range adjust {
+			levels[factor.Name] = factor.Levels[pick[k]]
+		}
+		if d := math.Abs(predict(levels) - target); d < best {
+			best, bestLevels = d, levels
+		}
+		for k := range pick {
+			if pick[k]++; pick[k] < len(adjust[k].Levels) {
+				break
+			}
+			pick[k] = 0
+		}
+	}
+	res.Levels = bestLevels
+	res.PredictedMean = predict(res.Levels)
+	res.Scaling = 1
+	if res.PredictedMean != 0 {
+		res.Scaling = target / res.PredictedMean
+	}
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package taguchi
+
+import (
+	"slices"
+	"testing"
+)
+
+// locationDispersionDesign records a response whose spread depends on A and
+// whose mean is proportional to B, the textbook split into a variance
+// (dispersion) factor and an adjustment (location) factor.
+var locationDispersionDesign = testDesign{
+	Goal: NominalTheBestTypeI{Target: 18},
+	Factors: []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{10, 20}},
+		{Name: "C", Levels: []float64{1, 2}},
+		{Name: "D", Levels: []float64{1, 2}},
+	},
+	Array: L8,
+	Noise: []NoiseFactor{{Name: "N", Levels: []float64{-1, 1}}},
+	Response: singleResult(func(trial Trial) []float64 {
+		spread := 0.5
+		if trial.Control["A"] == 2 {
+			spread = 0.05
+		}
+		// A small C effect keeps the error variance away from zero.
+		spread *= 1 + 0.01*trial.Control["C"]*float64(trial.Row%3)
+		return []float64{trial.Control["B"] * (1 + spread*trial.Noise["N"])}
+	}),
+}
+
+func TestTwoStepOptimization(t *testing.T) {
+	exp := newTestExperiment(t, locationDispersionDesign)
+	res, err := exp.TwoStepOptimization(18)
+	if err != nil {
+		t.Fatalf("TwoStepOptimization: %v", err)
+	}
+	if !slices.Equal(res.VarianceFactors, []string{"A"}) {
+		t.Errorf("VarianceFactors = %v, want [A]", res.VarianceFactors)
+	}
+	if !slices.Equal(res.AdjustmentFactors, []string{"B"}) {
+		t.Errorf("AdjustmentFactors = %v, want [B]", res.AdjustmentFactors)
+	}
+	if res.Levels["A"] != 2 || res.Levels["B"] != 20 {
+		t.Errorf("Levels = %v, want A=2 and B=20", res.Levels)
+	}
+	if !almostEqual(res.PredictedMean, 20) || !almostEqual(res.Scaling, 0.9) {
+		t.Errorf("PredictedMean = %.4f, Scaling = %.4f; want 20 and 0.9", res.PredictedMean, res.Scaling)
+	}
+}
+
+func TestAnalyze_MeanAnalysis(t *testing.T) {
+	exp := newTestExperiment(t, locationDispersionDesign)
+	if mustAnalyze(t, exp).MeanAnalysis != nil {
+		t.Fatal("MeanAnalysis set without AnalyzeMeans")
+	}
+	exp.AnalyzeMeans = true
+	ma := mustAnalyze(t, exp).MeanAnalysis
+	if ma == nil {
+		t.Fatal("MeanAnalysis is nil with AnalyzeMeans")
+	}
+	if !slices.Equal(ma.DispersionFactors, []string{"A"}) || !slices.Equal(ma.LocationFactors, []string{"B"}) {
+		t.Errorf("DispersionFactors = %v, LocationFactors = %v; want [A] and [B]", ma.DispersionFactors, ma.LocationFactors)
+	}
+	if b := ma.MainEffects["B"]; !almostEqual(b[0], 10) || !almostEqual(b[1], 20) {
+		t.Errorf("MainEffects[B] = %v, want [10 20]", b)
+	}
+	if ma.Contributions["B"] < 99 {
+		t.Errorf("Contributions[B] = %.2f, want nearly all of the mean variation", ma.Contributions["B"])
+	}
+}
@@ -7,6 +7,16 @@ type OptimizationGoal interface {
 	String() string
 }
 
+// FuncGoal adapts a function to OptimizationGoal, so domain-specific quality
+// metrics (e.g. an SNR built on a latency percentile) can be used without
+// implementing the interface on a new type.
+// Name: Name returned by String and recorded in designs.
+// SNR: Computes the SNR of a set of observations; higher must be better.
+type FuncGoal struct {
+	Name string
+	SNR  func(observations []float64) float64
+}
+
 // SmallerTheBetter means the goal is to minimize the response variable.
 type SmallerTheBetter struct{}
 
@@ -18,52 +28,176 @@ type NominalTheBest struct {
 	Target float64
 }
 
+// NominalTheBestTypeI is Taguchi's classic nominal-the-best formulation for
+// non-negative responses whose spread grows with the mean. Its SNR rewards a
+// small coefficient of variation; the mean is then moved onto Target with an
+// adjustment factor (see TwoStepOptimization).
+type NominalTheBestTypeI struct {
+	Target float64
+}
+
+// SignedTarget is the nominal-the-best variant for responses that can be
+// negative or zero (e.g. an offset or a clock skew): its SNR only penalizes
+// variance, and the mean is brought onto target afterwards with an adjustment factor.
+type SignedTarget struct{}
+
+// OperatingWindow is for responses with two failure thresholds that should be
+// pushed apart, e.g. the lowest load at which requests time out and the
+// highest at which the cache thrashes. Observations are pairs: the lower
+// threshold (smaller is better) followed by the upper threshold (larger is
+// better), so every result must record whole pairs (see AddResult).
+type OperatingWindow struct{}
+
+// Dynamic is the dynamic-characteristic goal for systems whose response should
+// follow an input signal, such as measurement systems and controllers. The
+// observations are (signal, response) pairs (see AddSignalResponses), and the
+// SNR rewards a steep, noise-free ideal function y = βM.
+// Linear: Fit y = α + βM instead of the zero-point proportional y = βM.
+type Dynamic struct {
+	Linear bool
+}
+
+// FractionDefective is for pass/fail responses. Each observation is 1 for a
+// defective (failed) unit and 0 for a good one; fractions in between may be
+// recorded directly as per-trial defect rates.
+type FractionDefective struct{}
+
 // ControlFactor represents a controllable input variable in the experiment.
 // Name: Identifier for the factor (e.g., "NumThreads").
 // Levels: A slice of possible numeric values that this factor can take.
+// Group: Optional group label (e.g., "compiler flags") used to aggregate results in reports.
+// ObserveOnly: Estimated in ANOVA but excluded from OptimalLevels, for factors that cannot be set in production (e.g., supplier batch).
+// Continuous: The levels are points on a continuous scale, so settings between them are meaningful (see AnalysisResult.Interpolated).
+// Costs: Optional relative cost of each level, in level order, used by TiePreferCheaper.
+// ChangeCost: Optional engineering cost of changing the factor in production (e.g. engineer-days), used to rank factors by contribution per unit cost (see AnalysisResult.CostBenefit).
+// Apply: Optional function that puts a level into effect (set an env var, write a config file, call an admin API), called by Run whenever the factor's level changes.
 type ControlFactor struct {
-	Name   string
-	Levels []float64
+	Name        string
+	Levels      []float64
+	Group       string
+	ObserveOnly bool
+	Continuous  bool
+	Costs       []float64
+	ChangeCost  float64
+	Apply       LevelFunc `json:"-"`
 }
 
 // NoiseFactor represents an uncontrollable input variable (noise) in the experiment.
 // Name: Identifier for the noise factor (e.g., "CPU Load").
 // Levels: A slice of numeric levels representing different environmental conditions.
+// Probe: Optional measurement of the level actually realized (e.g. CPU utilization), taken by Run after each trial's measured runs.
+// Tolerance: Largest accepted absolute difference between a probe reading and the intended level; zero disables the check.
+// Weights: Relative real-world likelihood of each level, e.g. {0.8, 0.2} for a load that is usually low; nil weighs the levels equally.
 type NoiseFactor struct {
-	Name   string
-	Levels []float64
+	Name      string
+	Levels    []float64
+	Probe     NoiseProbe `json:"-"`
+	Tolerance float64
+	Weights   []float64
 }
 
 // Trial represents a single experimental run combining a specific control and noise configuration.
 // ID: Unique identifier for the trial.
+// Row: Zero-based index of the orthogonal array row that produced the control configuration.
 // Control: Mapping from factor names to their selected levels for this trial.
 // Noise: Mapping from noise factor names to their levels during the trial.
+// Metadata: Free-form annotations of the trial set by the user, e.g. notes; nil for generated trials.
+// Key: Content key of the control and noise levels (see TrialKey), stable across processes and factor order; set on generated and recorded trials.
 type Trial struct {
-	ID      int
-	Control map[string]float64
-	Noise   map[string]float64
+	ID       int
+	Row      int
+	Control  map[string]float64
+	Noise    map[string]float64
+	Metadata map[string]string
+	Key      string
 }
 
 // TrialResult stores the observed outcomes from a trial.
 // Trial: The trial configuration that produced these observations.
-// Observations: Measured results for this trial (e.g., latency measurements).
+// Observations: Measured results for this trial (e.g., latency measurements); nil when not retained in memory, a sample under RetainSample.
+// Summary: Sufficient statistics of the observations, always populated by AddResult.
+// SpillFile: Path of the file holding the raw observations under RetainSpill.
+// Secondary: Additional responses per repetition keyed by name, e.g. the memory statistics recorded by RunOptions.CaptureMemStats.
+// NoiseReadings: Realized noise levels measured by the noise factors' probes, keyed by noise factor name.
+// Sequence: One-based position of the result in recording order, i.e. the executed run order when results are recorded as trials complete.
+// Block: Zero-based replicate block the result was measured in (see RunOrder.Blocks).
+// Metadata: Free-form annotations of the measurement, e.g. hostname, git SHA and timestamp (see RunOptions.Metadata).
 type TrialResult struct {
-	Trial        Trial
-	Observations []float64
+	Trial         Trial
+	Observations  []float64
+	Summary       ObservationSummary
+	SpillFile     string
+	Secondary     map[string][]float64
+	NoiseReadings map[string]float64
+	Sequence      int
+	Block         int
+	Metadata      map[string]string
 }
 
 // AnalysisResult stores the results of analyzing all experimental trials.
-// OptimalLevels: Maps each control factor to its best-performing level.
+// OptimalLevels: Maps each control factor to its best-performing level; observe-only factors are omitted.
+// Ties: Factors whose optimal level is statistically indistinguishable from other levels, resolved by Experiment.TiePolicy.
+// Comparisons: Pairwise level comparisons of the factors with three or more levels by Experiment.PostHoc; nil without a method.
+// Interpolated: Optima of continuous three-level factors interpolated between the tested levels.
+// ObservedFactors: Observe-only factors, which appear in the ANOVA but not in OptimalLevels.
 // SNR: Signal-to-noise ratios for each factor's levels.
 // MainEffects: Average SNR per factor level, showing the effect of each factor.
-// Contributions: Percentage contribution of each factor to overall variability.
-// ANOVA: Detailed ANOVA statistics including SS, DF, MS, and F-ratio for factors.
+// Contributions: Percentage contribution of each factor and declared interaction to the total variation.
+// ErrorContribution: Percentage of the total variation left unexplained (residual); factors and error sum to 100.
+// ANOVA: Detailed ANOVA statistics including SS, DF, MS, and F-ratio for factors and declared interactions.
+// Interactions: Statistics and cell means of each declared interaction; nil when none are declared.
+// Groups: Factor names per group label; nil when no factor carries a group.
+// GroupContributions: Summed percentage contribution per group; nil when no factor carries a group.
+// CostBenefit: Factors with a ChangeCost ranked by contribution per unit cost, best first; nil when no factor carries one.
+// BoundaryOptima: Factors with three or more levels, significant or contributing at least BoundaryMinContribution percent, whose optimal level is at the edge of the explored range.
+// Stability: Per-factor agreement of level rankings across noise conditions; nil with fewer than two conditions.
+// Outliers: Observations excluded or winsorized by Experiment.RobustSNR before computing SNRs; nil without a policy.
+// Warnings: Health checks raised during analysis, with machine-readable codes.
+// MeanAnalysis: Analysis of the raw row means; nil unless Experiment.AnalyzeMeans is set.
+// TimeTrend: Test for drift over the run order; nil unless Experiment.AnalyzeTimeTrend is set and enough results are replicated.
+// Exclusions: Data left out of the analysis by AnalyzeWith; nil for Analyze.
+// Where: Metadata the results analyzed by AnalyzeWith had to match; nil for Analyze.
 type AnalysisResult struct {
-	OptimalLevels map[string]float64
-	SNR           map[string][]float64
-	MainEffects   map[string][]float64
-	Contributions map[string]float64
-	ANOVA         ANOVAResult
+	OptimalLevels      map[string]float64
+	Ties               []LevelTie
+	Comparisons        []LevelComparison
+	Interpolated       []InterpolatedOptimum
+	ObservedFactors    []string
+	SNR                map[string][]float64
+	MainEffects        map[string][]float64
+	Contributions      map[string]float64
+	ErrorContribution  float64
+	ANOVA              ANOVAResult
+	Interactions       []InteractionEffect
+	Groups             map[string][]string
+	GroupContributions map[string]float64
+	CostBenefit        []CostBenefit
+	BoundaryOptima     []BoundaryOptimum
+	Stability          []FactorStability
+	Outliers           []OutlierObservation
+	Warnings           []Warning
+	MeanAnalysis       *MeanAnalysis
+	TimeTrend          *TimeTrend
+	Exclusions         []Exclusion
+	Where              map[string]string
+}
+
+// MeanAnalysis is the second table of the standard Taguchi analysis: main
+// effects and ANOVA of the raw row means next to those of the SNR, separating
+// factors that move the response from factors that change its spread.
+// MainEffects: Mean response per factor level.
+// Contributions: Percentage contribution of each factor to the variation of the row means.
+// ErrorContribution: Percentage of the variation of the row means left unexplained.
+// ANOVA: ANOVA of the row means.
+// DispersionFactors: Factors with a significant effect on the SNR.
+// LocationFactors: Factors with a significant effect on the mean but not on the SNR, usable to adjust the mean.
+type MeanAnalysis struct {
+	MainEffects       map[string][]float64
+	Contributions     map[string]float64
+	ErrorContribution float64
+	ANOVA             ANOVAResult
+	DispersionFactors []string
+	LocationFactors   []string
 }
 
 // ANOVAResult stores detailed ANOVA calculations for the experiment.
@@ -71,19 +205,38 @@ type AnalysisResult struct {
 // FactorDF: Degrees of freedom for each factor.
 // FactorMS: Mean square values for each factor.
 // FactorF: F-ratio for each factor.
-// ErrorSS: Sum of squares for residual/error.
+// ErrorSS: Sum of squares for residual/error, including the pure error when trials were replicated.
 // ErrorDF: Degrees of freedom for residual/error.
 // ErrorMS: Mean square error.
+// FactorP: p-value of each factor's F-ratio from the F distribution.
+// Significant: Whether each factor's p-value is below Alpha.
+// Alpha: Significance level used for Significant.
 // PooledFactors: List of factors that were pooled together during analysis (optional).
+// PureErrorSS, PureErrorDF, PureErrorMS: Pure error from the spread of replicated trials (see Analyze); zero DF without replicates.
+// ColumnErrorSS, ColumnErrorDF: Sum of squares and degrees of freedom of the experiment's ErrorColumns, which make up the error with the pure error; zero DF without error columns.
+// UnassignedSS, UnassignedDF: Variation in the free columns not reserved for error, e.g. from undeclared interactions, left out of the error when ErrorColumns are set.
+// LackOfFitF, LackOfFitP: F-ratio and p-value of the residual against the pure error; zero when either has no degrees of freedom.
 type ANOVAResult struct {
 	FactorSS      map[string]float64
 	FactorDF      map[string]int
 	FactorMS      map[string]float64
 	FactorF       map[string]float64
+	FactorP       map[string]float64
+	Significant   map[string]bool
+	Alpha         float64
 	ErrorSS       float64
 	ErrorDF       int
 	ErrorMS       float64
 	PooledFactors []string
+	PureErrorSS   float64
+	PureErrorDF   int
+	PureErrorMS   float64
+	ColumnErrorSS float64
+	ColumnErrorDF int
+	UnassignedSS  float64
+	UnassignedDF  int
+	LackOfFitF    float64
+	LackOfFitP    float64
 }
 
 // Experiment encapsulates all the configuration and results for a Taguchi experiment.
@@ -92,12 +245,53 @@ type ANOVAResult struct {
 // NoiseFactors: Uncontrollable environmental factors.
 // Goal: Optimization goal (Smaller, Larger, or Nominal).
 // OrthogonalArray: Predefined L4/L8/L9/etc. orthogonal array for trial combinations.
+// Columns: Array column (zero-based) assigned to each control factor; nil assigns factor i to column i.
+// Interactions: Two-factor interactions declared with AddInteraction.
+// ErrorColumns: Array columns (zero-based) reserved with ReserveErrorColumns, kept free of factors and interactions.
 // Results: Collection of TrialResults after experiments.
+// NoiseSampling: Optional sampling of the noise space instead of full crossing.
+// InfiniteSNR: How rows with an infinite SNR are handled (capped by default).
+// SNRCeiling: Ceiling in dB for CapInfiniteSNR (DefaultSNRCeiling when zero).
+// RobustSNR: How outlier observations within a trial are treated before computing SNRs (NoRobustSNR by default).
+// OutlierThreshold: Modified z-score above which RobustSNR treats an observation as an outlier (DefaultOutlierThreshold when zero).
+// Alpha: Significance level for ANOVA significance flags (DefaultAlpha when zero).
+// Retention: How raw observations are kept once recorded (RetainAll by default).
+// SpillDir: Directory for spilled observations under RetainSpill (a directory of its own under os.TempDir when empty; see RemoveSpillFiles).
+// SampleSize: Raw observations kept per trial result under RetainSample (DefaultSampleSize when zero).
+// AnalyzeMeans: Also analyze the raw row means in AnalysisResult.MeanAnalysis.
+// AllowIncomplete: Analyze experiments with unmeasured array rows by imputing their SNR (see Analyze).
+// RunOrder: Order in which trials are generated and run, and the number of replicate blocks.
+// AnalyzeTimeTrend: Also test the results for drift over the run order in AnalysisResult.TimeTrend.
+// TiePolicy: How Analyze picks the optimal level among statistically indistinguishable levels (TieReport by default).
+// PostHoc: Pairwise comparison of the levels of factors with three or more levels in AnalysisResult.Comparisons (NoPostHoc by default).
+// Events: Receives the experiment's milestones, e.g. to notify a chat channel (see EventBus); nil publishes nothing.
 type Experiment[P any] struct {
-	ControlFactors  []ControlFactor
-	NoiseFactors    []NoiseFactor
-	Goal            OptimizationGoal
-	OrthogonalArray [][]int
-	Results         []TrialResult
-	controlAs       func(Trial) P
+	ControlFactors   []ControlFactor
+	NoiseFactors     []NoiseFactor
+	Goal             OptimizationGoal
+	OrthogonalArray  [][]int
+	Columns          []int
+	Interactions     []Interaction
+	ErrorColumns     []int
+	Results          []TrialResult
+	NoiseSampling    NoiseSampling
+	InfiniteSNR      InfiniteSNRPolicy
+	SNRCeiling       float64
+	RobustSNR        RobustSNRPolicy
+	OutlierThreshold float64
+	Alpha            float64
+	Retention        RetentionPolicy
+	SpillDir         string
+	SampleSize       int
+	AnalyzeMeans     bool
+	AllowIncomplete  bool
+	RunOrder         RunOrder
+	AnalyzeTimeTrend bool
+	TiePolicy        TiePolicy
+	PostHoc          PostHocMethod
+	Events           *EventBus
+	controlAs        func(Trial) (P, error)
+	store            Store
+	spillDir         string
+	noise            *noiseSet
 }
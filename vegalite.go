@@ -0,0 +1,122 @@
+package taguchi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// VegaLiteSchema is the $schema of the specs emitted by VegaLite and ParetoVegaLite.
+const VegaLiteSchema = "https://vega.github.io/schema/vega-lite/v5.json"
+
+// ParetoPlot returns the factor contributions as a Pareto chart: factors
+// (and interactions) sorted by decreasing contribution, followed by the
+// error, with the percentage of each and the cumulative percentage as the
+// two series.
+func (r AnalysisResult) ParetoPlot() Plot {
+	names := sortedKeys(r.Contributions)
+	sort.SliceStable(names, func(i, j int) bool {
+		return r.Contributions[names[i]] > r.Contributions[names[j]]
+	})
+	p := Plot{
+		Title:      "Contribution",
+		XLabel:     "Factor",
+		YLabel:     "Contribution (%)",
+		Categories: names,
+		Series:     []PlotSeries{{Name: "Contribution"}, {Name: "Cumulative"}},
+	}
+	total := 0.0
+	for _, name := range names {
+		total += r.Contributions[name]
+		p.Series[0].Values = append(p.Series[0].Values, r.Contributions[name])
+		p.Series[1].Values = append(p.Series[1].Values, total)
+	}
+	if r.ErrorContribution > 0 {
+		p.Categories = append(p.Categories, "Error")
+		p.Series[0].Values = append(p.Series[0].Values, r.ErrorContribution)
+		p.Series[1].Values = append(p.Series[1].Values, total+r.ErrorContribution)
+	}
+	return p
+}
+
+// VegaLite returns a Vega-Lite spec that draws plots side by side as line
+// charts with a shared Y axis, the interactive counterpart of WriteSVG for web
+// frontends and notebooks. Series become the color legend; NaN values are
+// left out.
+func VegaLite(plots []Plot) ([]byte, error) {
+	if len(plots) == 0 {
+		return nil, fmt.Errorf("no plots to render")
+	}
+	panels := make([]map[string]any, len(plots))
+	for i, p := range plots {
+		panels[i] = map[string]any{
+			"title": p.Title,
+			"data":  map[string]any{"values": vegaValues(p)},
+			"mark":  map[string]any{"type": "line", "point": true},
+			"encoding": map[string]any{
+				"x":     map[string]any{"field": "category", "type": "ordinal", "title": p.XLabel, "sort": p.Categories},
+				"y":     map[string]any{"field": "value", "type": "quantitative", "title": p.YLabel, "scale": map[string]any{"zero": false}},
+				"color": map[string]any{"field": "series", "type": "nominal", "title": nil, "legend": vegaLegend(p)},
+			},
+		}
+	}
+	if len(panels) == 1 {
+		panels[0]["$schema"] = VegaLiteSchema
+		return json.Marshal(panels[0])
+	}
+	return json.Marshal(map[string]any{
+		"$schema": VegaLiteSchema,
+		"hconcat": panels,
+		"resolve": map[string]any{"scale": map[string]any{"y": "shared"}},
+	})
+}
+
+// ParetoVegaLite returns a Vega-Lite spec of ParetoPlot: bars for each
+// contribution and a line for the cumulative percentage.
+func (r AnalysisResult) ParetoVegaLite() ([]byte, error) {
+	p := r.ParetoPlot()
+	x := map[string]any{"field": "category", "type": "nominal", "title": p.XLabel, "sort": p.Categories}
+	y := map[string]any{"field": "value", "type": "quantitative", "title": p.YLabel, "scale": map[string]any{"domain": []float64{0, 100}}}
+	return json.Marshal(map[string]any{
+		"$schema": VegaLiteSchema,
+		"title":   p.Title,
+		"data":    map[string]any{"values": vegaValues(p)},
+		"encoding": map[string]any{
+			"x": x,
+			"y": y,
+		},
+		"layer": []map[string]any{
+			{
+				"transform": []map[string]any{{"filter": map[string]any{"field": "series", "equal": "Contribution"}}},
+				"mark":      "bar",
+			},
+			{
+				"transform": []map[string]any{{"filter": map[string]any{"field": "series", "equal": "Cumulative"}}},
+				"mark":      map[string]any{"type": "line", "point": true, "color": plotColors[1]},
+			},
+		},
+	})
+}
+
+// vegaValues flattens a plot into one data row per series and category.
+func vegaValues(p Plot) []map[string]any {
+	var values []map[string]any
+	for _, s := range p.Series {
+		for i, v := range s.Values {
+			if i >= len(p.Categories) || math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			values = append(values, map[string]any{"series": s.Name, "category": p.Categories[i], "value": v})
+		}
+	}
+	return values
+}
+
+// vegaLegend hides the legend of single-line plots, like WriteSVG.
+func vegaLegend(p Plot) any {
+	if len(p.Series) > 1 {
+		return map[string]any{}
+	}
+	return nil
+}
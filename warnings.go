@@ -0,0 +1,195 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+)
+
+// WarningCode is a stable, machine-readable identifier for an analysis warning.
+type WarningCode string
+
+const (
+	// WarnMissingRow: an orthogonal array row has no results.
+	WarnMissingRow WarningCode = "missing-row"
+	// WarnSaturatedDesign: the design is saturated and leaves no error DF, so F-ratios and p-values are NaN.
+	WarnSaturatedDesign WarningCode = "saturated-design"
+	// WarnBoundaryOptimum: a factor's optimum is at the edge of the explored range.
+	WarnBoundaryOptimum WarningCode = "boundary-optimum"
+	// WarnZeroErrorVariance: the error variance is (near) zero, making F-ratios unreliable.
+	WarnZeroErrorVariance WarningCode = "zero-error-variance"
+	// WarnInfiniteSNR: a row produced an infinite SNR (zero mean squared deviation).
+	WarnInfiniteSNR WarningCode = "infinite-snr"
+	// WarnUnstableEffect: a factor's best level differs between noise conditions.
+	WarnUnstableEffect WarningCode = "unstable-effect"
+	// WarnNoiseNotRealized: probe readings show a noise factor missed its intended level.
+	WarnNoiseNotRealized WarningCode = "noise-not-realized"
+	// WarnTimeTrend: the response drifts significantly over the run order.
+	WarnTimeTrend WarningCode = "time-trend"
+	// WarnTiedLevels: several levels of a factor are statistically indistinguishable from its optimum.
+	WarnTiedLevels WarningCode = "tied-levels"
+	// WarnOutliers: the RobustSNR policy excluded or winsorized observations of a trial.
+	WarnOutliers WarningCode = "outliers"
+)
+
+// Warning describes a condition that may compromise the analysis.
+// Code: Machine-readable identifier for automated gating.
+// Factor: Control (or, for noise warnings, noise) factor concerned, empty when not factor specific.
+// Row: Zero-based orthogonal array row concerned, -1 when not row specific.
+// Message: Human-readable explanation.
+type Warning struct {
+	Code    WarningCode
+	Factor  string
+	Row     int
+	Message string
+}
+
+// String formats the warning with its code.
+func (w Warning) String() string {
+	return fmt.Sprintf("[%s] %s", w.Code, w.Message)
+}
+
+// HasWarning reports whether the result carries at least one warning with the given code.
+func (r AnalysisResult) HasWarning(code WarningCode) bool {
+	for _, w := range r.Warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// zeroErrorVarianceTolerance is the fraction of the total SS below which the
+// error SS is considered zero.
+const zeroErrorVarianceTolerance = 1e-9
+
+// collectWarnings runs all health checks on an analysis.
+func (e *Experiment[P]) collectWarnings(rows oaRowSNR, anova ANOVAResult, boundary []BoundaryOptimum, stability []FactorStability, ties []LevelTie, outliers []OutlierObservation, trend *TimeTrend) []Warning {
+	var warnings []Warning
+
+	for i := range e.OrthogonalArray {
+		if !e.rowHasResults(i) {
+			warnings = append(warnings, Warning{
+				Code:    WarnMissingRow,
+				Row:     i,
+				Message: fmt.Sprintf("orthogonal array row %d has no results; its SNR was imputed with the mean of the measured rows", i+1),
+			})
+		}
+	}
+	for i, inf := range rows.infinite {
+		if !inf {
+			continue
+		}
+		action := fmt.Sprintf("capped at ±%g dB", e.snrCeiling())
+		if !rows.included[i] {
+			action = "excluded from the analysis"
+		}
+		warnings = append(warnings, Warning{
+			Code:    WarnInfiniteSNR,
+			Row:     i,
+			Message: fmt.Sprintf("orthogonal array row %d has infinite SNR; %s", i+1, action),
+		})
+	}
+
+	factorDF := 0
+	for _, df := range anova.FactorDF {
+		factorDF += df
+	}
+	includedRows := 0
+	for i, in := range rows.included {
+		if in && !rows.missing[i] {
+			includedRows++
+		}
+	}
+	if anova.ErrorDF < 1 {
+		warnings = append(warnings, Warning{
+			Code:    WarnSaturatedDesign,
+			Row:     -1,
+			Message: fmt.Sprintf("design is saturated (%d factor DF for %d rows) and leaves no error DF; F-ratios and p-values are undefined, so replicate the trials or use a larger array", factorDF, includedRows),
+		})
+	}
+
+	totalSS := anova.ErrorSS
+	for _, ss := range anova.FactorSS {
+		totalSS += ss
+	}
+	if anova.ErrorDF > 0 && math.Abs(anova.ErrorSS) <= zeroErrorVarianceTolerance*math.Abs(totalSS) {
+		warnings = append(warnings, Warning{
+			Code:    WarnZeroErrorVariance,
+			Row:     -1,
+			Message: "error variance is near zero; F-ratios are not meaningful",
+		})
+	}
+
+	for _, bo := range boundary {
+		warnings = append(warnings, Warning{
+			Code:    WarnBoundaryOptimum,
+			Factor:  bo.Factor,
+			Row:     -1,
+			Message: fmt.Sprintf("optimum of %s is at the %s edge of the explored range; consider extending it", bo.Factor, bo.Edge()),
+		})
+	}
+
+	for _, fs := range stability {
+		if fs.Flips {
+			warnings = append(warnings, Warning{
+				Code:    WarnUnstableEffect,
+				Factor:  fs.Factor,
+				Row:     -1,
+				Message: fmt.Sprintf("best level of %s changes between noise conditions (Kendall's W = %.2f)", fs.Factor, fs.KendallW),
+			})
+		}
+	}
+
+	for _, tie := range ties {
+		warnings = append(warnings, tieWarning(tie))
+	}
+
+	missed := map[string][]NoiseDeviation{}
+	for _, d := range e.UnrealizedNoise() {
+		missed[d.Factor] = append(missed[d.Factor], d)
+	}
+	for _, factor := range e.NoiseFactors {
+		ds := missed[factor.Name]
+		if len(ds) == 0 {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Code:   WarnNoiseNotRealized,
+			Factor: factor.Name,
+			Row:    -1,
+			Message: fmt.Sprintf("noise factor %s missed its intended level in %d trial(s), e.g. trial %d: intended %g, measured %g",
+				factor.Name, len(ds), ds[0].TrialID, ds[0].Intended, ds[0].Realized),
+		})
+	}
+
+	for start := 0; start < len(outliers); {
+		end := start
+		for end < len(outliers) && outliers[end].Trial == outliers[start].Trial {
+			end++
+		}
+		o := outliers[start]
+		warnings = append(warnings, Warning{
+			Code:    WarnOutliers,
+			Row:     o.Row,
+			Message: fmt.Sprintf("trial %d has %d outlier observation(s), e.g. observation %d = %g; %s before computing the SNR", o.Trial, end-start, o.Observation, o.Value, outlierAction(e.RobustSNR)),
+		})
+		start = end
+	}
+
+	if trend != nil && trend.Significant {
+		warnings = append(warnings, Warning{
+			Code:    WarnTimeTrend,
+			Row:     -1,
+			Message: fmt.Sprintf("response drifts by %.4g per run over the run order (p = %.3g); randomize the run order or add blocks", trend.Slope, trend.P),
+		})
+	}
+	return warnings
+}
+
+// outlierAction describes what the RobustSNR policy does to an outlier.
+func outlierAction(p RobustSNRPolicy) string {
+	if p == WinsorizeOutliers {
+		return "winsorized"
+	}
+	return "excluded"
+}
@@ -0,0 +1,94 @@
+package taguchi
+
+import "testing"
+
+// TestAnalyze_Warnings verifies that missing rows and saturated designs are
+// reported with their machine-readable codes, and that incomplete experiments
+// are only analyzed with AllowIncomplete.
+func TestAnalyze_Warnings(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}}
+	noise := []NoiseFactor{
+		{Name: "N", Levels: []float64{0}},
+	}
+
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+
+	trials := exp.GenerateTrials()
+	exp.AddResult(trials[0], []float64{2})
+	exp.AddResult(trials[1], []float64{4})
+	exp.AddResult(trials[2], []float64{6})
+
+	if _, err := exp.Analyze(); err == nil {
+		t.Fatal("Analyze accepted an experiment with a missing row")
+	}
+	exp.AllowIncomplete = true
+	result := mustAnalyze(t, exp)
+
+	if !result.HasWarning(WarnMissingRow) {
+		t.Errorf("expected %s warning, got %v", WarnMissingRow, result.Warnings)
+	}
+	for _, w := range result.Warnings {
+		if w.Code == WarnMissingRow && w.Row != 3 {
+			t.Errorf("missing-row warning for row %d, want 3", w.Row)
+		}
+	}
+	// The imputed row carries no information, leaving 3 rows for 2 factors.
+	if !result.HasWarning(WarnSaturatedDesign) {
+		t.Errorf("expected %s warning for 2 factors in 3 measured rows", WarnSaturatedDesign)
+	}
+	// Row 4 is imputed with the mean SNR of the measured rows.
+	goal := SmallerTheBetter{}
+	snr := []float64{goal.CalculateSNR([]float64{2}), goal.CalculateSNR([]float64{4}), goal.CalculateSNR([]float64{6})}
+	imputed := (snr[0] + snr[1] + snr[2]) / 3
+	if got := result.MainEffects["A"][1]; !almostEqual(got, (snr[2]+imputed)/2) {
+		t.Errorf("MainEffects[A][1] = %.4f, want %.4f", got, (snr[2]+imputed)/2)
+	}
+}
+
+// TestAnalyze_UnstableEffect verifies that a factor whose best level flips
+// between noise conditions is flagged while a consistent factor is not.
+func TestAnalyze_UnstableEffect(t *testing.T) {
+	factors := []ControlFactor{
+		{Name: "A", Levels: []float64{1, 2}},
+		{Name: "B", Levels: []float64{1, 2}},
+	}
+	oa := [][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}}
+	noise := []NoiseFactor{
+		{Name: "N", Levels: []float64{0, 1}},
+	}
+
+	exp, err := NewExperimentFromFactorsUsingArray(SmallerTheBetter{}, factors, oa, noise)
+	if err != nil {
+		t.Fatalf("NewExperimentFromFactorsUsingArray: %v", err)
+	}
+	for _, trial := range exp.GenerateTrials() {
+		// B=1 is always better; A=1 is better under N=0 and worse under N=1.
+		y := 10 * trial.Control["B"]
+		if (trial.Control["A"] == 1) == (trial.Noise["N"] == 0) {
+			y -= 5
+		}
+		exp.AddResult(trial, []float64{y})
+	}
+
+	result := mustAnalyze(t, exp)
+	if len(result.Stability) != 2 {
+		t.Fatalf("len(Stability) = %d, want 2", len(result.Stability))
+	}
+	a, b := result.Stability[0], result.Stability[1]
+	if !a.Flips || !almostEqual(a.KendallW, 0) {
+		t.Errorf("A: Flips = %v, W = %.4f; want flips with W = 0", a.Flips, a.KendallW)
+	}
+	if b.Flips || !almostEqual(b.KendallW, 1) {
+		t.Errorf("B: Flips = %v, W = %.4f; want stable with W = 1", b.Flips, b.KendallW)
+	}
+	if !result.HasWarning(WarnUnstableEffect) {
+		t.Errorf("expected %s warning, got %v", WarnUnstableEffect, result.Warnings)
+	}
+}
@@ -0,0 +1,308 @@
+package taguchi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a non-blank line of a YAML document with its indentation.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML parses the subset of YAML used by factor specs: block mappings
+// and sequences nested by indentation, flow sequences and mappings ([a, b]
+// and {k: v}), quoted and plain scalars, and # comments. Anchors, tags,
+// multi-line strings and multiple documents are not supported. Numbers
+// become float64, true and false become bool, null and ~ become nil.
+func parseYAML(data string) (any, error) {
+	var lines []yamlLine
+	for n, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		text := strings.TrimRight(stripComment(raw), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", n+1)
+		}
+		lines = append(lines, yamlLine{number: n + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	p := &yamlParser{lines: lines}
+	v, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return v, nil
+}
+
+// stripComment removes a # comment that starts a line or follows a space,
+// outside quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block parses the mapping or sequence whose entries start at indent.
+func (p *yamlParser) block(indent int) (any, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// sequence parses "- item" entries at indent.
+func (p *yamlParser) sequence(indent int) (any, error) {
+	list := []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		item := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		switch {
+		case item == "":
+			p.pos++
+			v, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		case isSequenceItem(item) || isMappingEntry(item):
+			// The item is a block collection starting on the dash line;
+			// reparse that line as its first entry.
+			offset := len(line.text) - len(item)
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + offset, text: item}
+			v, err := p.block(indent + offset)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		default:
+			v, err := parseFlow(item, line.number)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+			p.pos++
+		}
+	}
+	return list, nil
+}
+
+// mapping parses "key: value" entries at indent.
+func (p *yamlParser) mapping(indent int) (any, error) {
+	m := map[string]any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if !isMappingEntry(line.text) {
+			return nil, fmt.Errorf("line %d: expected \"key: value\", got %q", line.number, line.text)
+		}
+		key, rest := splitMappingEntry(line.text)
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		p.pos++
+		if rest != "" {
+			v, err := parseFlow(rest, line.number)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+		// A sequence may sit at the same indentation as its key.
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+			v, err := p.sequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+		v, err := p.nested(indent)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// nested parses the block indented deeper than indent, or returns nil when
+// there is none.
+func (p *yamlParser) nested(indent int) (any, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+		return nil, nil
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+// isMappingEntry reports whether text starts with a key followed by ": " or
+// a trailing colon, outside quotes and flow collections.
+func isMappingEntry(text string) bool {
+	if text == "" || strings.ContainsRune("[{\"'", rune(text[0])) {
+		return false
+	}
+	i := strings.Index(text, ":")
+	return i > 0 && (i == len(text)-1 || text[i+1] == ' ')
+}
+
+func splitMappingEntry(text string) (string, string) {
+	i := strings.Index(text, ":")
+	return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+}
+
+// parseFlow parses a scalar or a flow collection that makes up a whole value.
+func parseFlow(text string, line int) (any, error) {
+	f := &flowParser{text: text, line: line}
+	v, err := f.value()
+	if err != nil {
+		return nil, err
+	}
+	f.skipSpace()
+	if f.pos < len(f.text) {
+		return nil, fmt.Errorf("line %d: unexpected %q", line, f.text[f.pos:])
+	}
+	return v, nil
+}
+
+type flowParser struct {
+	text string
+	pos  int
+	line int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.pos < len(f.text) && f.text[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *flowParser) value() (any, error) {
+	f.skipSpace()
+	if f.pos >= len(f.text) {
+		return nil, fmt.Errorf("line %d: missing value", f.line)
+	}
+	switch f.text[f.pos] {
+	case '[':
+		return f.collection(']')
+	case '{':
+		return f.collection('}')
+	case '"', '\'':
+		return f.quoted()
+	}
+	start := f.pos
+	for f.pos < len(f.text) && !strings.ContainsRune(",]}", rune(f.text[f.pos])) {
+		if f.text[f.pos] == ':' && f.pos+1 < len(f.text) && f.text[f.pos+1] == ' ' {
+			break
+		}
+		f.pos++
+	}
+	return scalar(strings.TrimSpace(f.text[start:f.pos])), nil
+}
+
+// collection parses a flow sequence or mapping up to its closing bracket.
+func (f *flowParser) collection(closing byte) (any, error) {
+	f.pos++
+	var list []any
+	m := map[string]any{}
+	for {
+		f.skipSpace()
+		if f.pos < len(f.text) && f.text[f.pos] == closing {
+			f.pos++
+			if closing == '}' {
+				return m, nil
+			}
+			if list == nil {
+				list = []any{}
+			}
+			return list, nil
+		}
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		if closing == '}' {
+			f.skipSpace()
+			if f.pos >= len(f.text) || f.text[f.pos] != ':' {
+				return nil, fmt.Errorf("line %d: expected \":\" after key %v", f.line, v)
+			}
+			f.pos++
+			item, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(v)] = item
+		} else {
+			list = append(list, v)
+		}
+		f.skipSpace()
+		switch {
+		case f.pos < len(f.text) && f.text[f.pos] == ',':
+			f.pos++
+		case f.pos < len(f.text) && f.text[f.pos] == closing:
+		default:
+			return nil, fmt.Errorf("line %d: unterminated %q", f.line, rune(closing))
+		}
+	}
+}
+
+func (f *flowParser) quoted() (any, error) {
+	quote := f.text[f.pos]
+	end := strings.IndexByte(f.text[f.pos+1:], quote)
+	if end < 0 {
+		return nil, fmt.Errorf("line %d: unterminated string", f.line)
+	}
+	s := f.text[f.pos+1 : f.pos+1+end]
+	f.pos += end + 2
+	if quote == '"' {
+		return strconv.Unquote(`"` + s + `"`)
+	}
+	return s, nil
+}
+
+// scalar converts a plain scalar to a number, bool, nil or string.
+func scalar(s string) any {
+	switch s {
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case "null", "Null", "NULL", "~", "":
+		return nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(v, 0) && !math.IsNaN(v) {
+		return v
+	}
+	return s
+}